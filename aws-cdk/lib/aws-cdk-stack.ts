@@ -266,7 +266,7 @@ export class AwsCdkStack extends cdk.Stack {
 		// =========================
 		// Step Functions: Daily Batch Orchestration
 		// =========================
-		// RunTask.sync で Fargate タスクを直列実行（JOB=reset → update-rp → transfer-bq）
+		// RunTask.sync で Fargate タスクを直列実行（JOB=daily-organize → update-rp → transfer-bq）
 		const runTaskCommon: sfn_tasks.EcsRunTaskProps = {
 			cluster: cluster,
 			taskDefinition: taskDefinition,
@@ -279,15 +279,16 @@ export class AwsCdkStack extends cdk.Stack {
 			integrationPattern: sfn.IntegrationPattern.RUN_JOB,
 		}
 
-		const resetDailyTotalTask = new sfn_tasks.EcsRunTask(
+		// daily-organize: 累計作業時間のリセット → 前日の作業履歴の補完 → 期限切れの退室取り消し記録の削除（DailyOrganizeDB）
+		const dailyOrganizeTask = new sfn_tasks.EcsRunTask(
 			this,
-			'reset-daily-total',
+			'daily-organize',
 			{
 				...runTaskCommon,
 				containerOverrides: [
 					{
 						containerDefinition: batchContainer,
-						environment: [{ name: 'JOB', value: 'reset-daily-total' }],
+						environment: [{ name: 'JOB', value: 'daily-organize' }],
 					},
 				],
 			},
@@ -376,10 +377,10 @@ export class AwsCdkStack extends cdk.Stack {
 
 		// 手動実行用は別グラフになるため、各グラフ専用のSNS通知ステートを定義して接続する
 
-		// Execute all three sequentially but continue on failure (each task has local catch → notify → continue)
+		// Execute all tasks sequentially but continue on failure (each task has local catch → notify → continue)
 		const definition = sfn.Chain.start(wait15s)
 			.next(
-				resetDailyTotalTask.addCatch(notifyOnFailure, {
+				dailyOrganizeTask.addCatch(notifyOnFailure, {
 					resultPath: sfn.JsonPath.DISCARD,
 				}),
 			)
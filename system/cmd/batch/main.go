@@ -43,10 +43,18 @@ func main() {
 	switch job {
 	case "all":
 		runErr = runAll(ctx, app, clientOption)
+	case "daily-organize":
+		if err := doDailyOrganize(ctx, app); err != nil {
+			runErr = fmt.Errorf("daily-organize: %w", err)
+		}
 	case "reset-daily-total":
 		if err := doResetDailyTotal(ctx, app); err != nil {
 			runErr = fmt.Errorf("reset-daily-total: %w", err)
 		}
+	case "backfill-daily-history":
+		if err := doBackfillDailyHistory(ctx, app); err != nil {
+			runErr = fmt.Errorf("backfill-daily-history: %w", err)
+		}
 	case "update-rp":
 		if err := doUpdateRP(ctx, app); err != nil {
 			runErr = fmt.Errorf("update-rp: %w", err)
@@ -68,8 +76,8 @@ func main() {
 }
 
 func runAll(ctx context.Context, app *workspaceapp.WorkspaceApp, clientOption option.ClientOption) error {
-	if err := doResetDailyTotal(ctx, app); err != nil {
-		return fmt.Errorf("reset-daily-total: %w", err)
+	if err := doDailyOrganize(ctx, app); err != nil {
+		return fmt.Errorf("daily-organize: %w", err)
 	}
 	if err := doUpdateRP(ctx, app); err != nil {
 		return fmt.Errorf("update-rp: %w", err)
@@ -80,6 +88,14 @@ func runAll(ctx context.Context, app *workspaceapp.WorkspaceApp, clientOption op
 	return nil
 }
 
+// doDailyOrganize 累計作業時間のリセットなど、日付が変わった直後に行うDBの整理をまとめて実行する
+func doDailyOrganize(ctx context.Context, app *workspaceapp.WorkspaceApp) error {
+	if err := app.DailyOrganizeDB(ctx); err != nil {
+		return fmt.Errorf("DailyOrganizeDB: %w", err)
+	}
+	return nil
+}
+
 func doResetDailyTotal(ctx context.Context, app *workspaceapp.WorkspaceApp) error {
 	count, err := app.ResetDailyTotalStudyTime(ctx)
 	if err != nil {
@@ -89,6 +105,16 @@ func doResetDailyTotal(ctx context.Context, app *workspaceapp.WorkspaceApp) erro
 	return nil
 }
 
+// doBackfillDailyHistory 前日分の日ごとの作業履歴を作業セグメントから補完する
+func doBackfillDailyHistory(ctx context.Context, app *workspaceapp.WorkspaceApp) error {
+	count, err := app.BackfillDailyUserWorkHistory(ctx, timeutil.JstNow().AddDate(0, 0, -1))
+	if err != nil {
+		return fmt.Errorf("BackfillDailyUserWorkHistory: %w", err)
+	}
+	app.MessageToOwner(ctx, "backfill-daily-history finished. user_count="+strconv.Itoa(count))
+	return nil
+}
+
 func doUpdateRP(ctx context.Context, app *workspaceapp.WorkspaceApp) error {
 	userIDs, err := app.GetUserIDsToProcessRP(ctx)
 	if err != nil {
@@ -5,14 +5,18 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
-	"math"
 	"os"
-	"strconv"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"app.modules/core/chat"
+	"app.modules/core/repository"
+	"app.modules/core/twitchbot"
 	"app.modules/core/workspaceapp"
 
-	"github.com/kr/pretty"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	_ "modernc.org/sqlite"
 
 	"app.modules/core/wordsreader"
 	"app.modules/core/youtubebot"
@@ -24,10 +28,8 @@ import (
 	"app.modules/core/utils"
 )
 
-const (
-	MaxRetryIntervalSeconds      = 300
-	RetryIntervalCalculationBase = 1.2
-)
+// LiveChatQueueShutdownTimeout 終了時にライブチャットへの投稿キューの残りを送り切るまで待つ時間
+const LiveChatQueueShutdownTimeout = 30 * time.Second
 
 func Init() (option.ClientOption, context.Context, error) {
 	utils.LoadEnv(".env")
@@ -54,30 +56,74 @@ func Init() (option.ClientOption, context.Context, error) {
 	return clientOption, ctx, nil
 }
 
-func CheckLongTimeSitting(ctx context.Context, clientOption option.ClientOption) {
-	app, err := workspaceapp.NewWorkspaceApp(ctx, false, clientOption)
+// newWorkspaceApp DATABASE_DRIVERとDATABASE_URLが設定されていればSQLデータベース（ステージング用）を、なければFirestoreを使う。
+// DATABASE_DRIVERは"sqlite"か"pgx"。
+func newWorkspaceApp(ctx context.Context, interactive bool, clientOption option.ClientOption) (*workspaceapp.WorkspaceApp, error) {
+	driverName := os.Getenv("DATABASE_DRIVER")
+	dataSourceName := os.Getenv("DATABASE_URL")
+	if driverName == "" || dataSourceName == "" {
+		return workspaceapp.NewWorkspaceApp(ctx, interactive, clientOption)
+	}
+
+	slog.InfoContext(ctx, "opening sql database...", "driver", driverName)
+	repo, err := repository.OpenSQLRepository(ctx, driverName, dataSourceName)
+	if err != nil {
+		return nil, fmt.Errorf("in OpenSQLRepository(): %w", err)
+	}
+	app, err := workspaceapp.NewWorkspaceAppWithRepository(ctx, interactive, repo)
+	if err != nil {
+		_ = repo.Close()
+		return nil, fmt.Errorf("in NewWorkspaceAppWithRepository(): %w", err)
+	}
+	return app, nil
+}
+
+// CheckLongTimeSitting 居座り防止処理を別のWorkspaceAppで実行する。
+// ライブチャットへの投稿とYouTube Data APIの使用量は、Botと同じ送信キューとQuotaMeterを使う。
+func CheckLongTimeSitting(ctx context.Context, clientOption option.ClientOption,
+	liveChatBot youtubebot.LiveChatBot, quotaMeter *youtubebot.QuotaMeter,
+) {
+	app, err := newWorkspaceApp(ctx, false, clientOption)
 	if err != nil {
 		slog.ErrorContext(ctx, "failed core.NewWorkspaceApp()", "error", err)
 		return
 	}
+	app.LiveChatBot = liveChatBot
+	app.AddChatSource(youtubebot.NewChatSource(liveChatBot))
+	app.QuotaMeter = quotaMeter
 
 	app.MessageToOwner(ctx, "居座り防止プログラムが起動しました。")
 
 	app.GoroutineCheckLongTimeSitting(ctx)
 }
 
-func CalculateRetryIntervalSec(base float64, numContinuousFailed int) float64 {
-	return math.Min(MaxRetryIntervalSeconds, math.Pow(base, float64(numContinuousFailed)))
-}
-
 func Bot(ctx context.Context, clientOption option.ClientOption) {
-	app, err := workspaceapp.NewWorkspaceApp(ctx, true, clientOption)
+	app, err := newWorkspaceApp(ctx, true, clientOption)
 	if err != nil {
 		slog.ErrorContext(ctx, "failed core.NewWorkspaceApp()", "error", err)
 		return
 	}
 	defer app.CloseFirestoreClient()
 
+	if err := app.StartSeatCache(ctx); err != nil {
+		app.MessageToOwnerWithError(ctx, "failed app.StartSeatCache()", err)
+		return
+	}
+	app.StartConstantsWatcher(ctx)
+
+	// ライブチャットへの投稿はキューを通し、短い返信をまとめて間隔を空けて送る。
+	// 終了時に残りを送り切れるよう、送信はctxのキャンセルでは止めずにShutdownで止める
+	liveChatQueue := youtubebot.NewQueuedLiveChatBot(app.LiveChatBot, app.MessageToOwnerWithError)
+	liveChatQueue.SetMinInterval(time.Duration(app.Configs.Constants.LiveChatPostIntervalMilli) * time.Millisecond)
+	app.LiveChatBot = liveChatQueue
+	app.AddChatSource(youtubebot.NewChatSource(liveChatQueue))
+	go liveChatQueue.Run(context.WithoutCancel(ctx))
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), LiveChatQueueShutdownTimeout)
+		defer cancel()
+		liveChatQueue.Shutdown(shutdownCtx)
+	}()
+
 	ngWordConfig, err := loadNGWordConfig(ctx, clientOption, app.Configs.Constants.BotConfigSpreadsheetID)
 	if err != nil {
 		app.MessageToOwnerWithError(ctx, "failed loadNGWordConfig()", err)
@@ -85,28 +131,88 @@ func Bot(ctx context.Context, clientOption option.ClientOption) {
 	}
 
 	app.MessageToOwner(ctx, fmt.Sprintf("Botが起動しました。\n全規制ワード数: %d", ngWordConfig.Count()))
-	defer func() { // when error occurred
+	defer func() {
+		if ctx.Err() != nil { // SIGINT/SIGTERMで停止した
+			app.MessageToOwner(context.WithoutCancel(ctx), "app stopped by signal.")
+			return
+		}
+		// when error occurred
 		app.MessageToLiveChat(ctx, "エラーが起きたため終了します。お手数ですが管理者に連絡してください。")
 		app.MessageToOwner(ctx, "app stopped!!")
 	}()
 
-	go CheckLongTimeSitting(ctx, clientOption) // 居座り防止処理を並行実行
-
-	checkDesiredMaxSeatsIntervalSec := app.Configs.Constants.CheckDesiredMaxSeatsIntervalSec
+	go CheckLongTimeSitting(ctx, clientOption, liveChatQueue, app.QuotaMeter) // 居座り防止処理を並行実行
 
 	lastCheckedDesiredMaxSeats := timeutil.JstNow()
+	var lastFlushedQuota time.Time
+
+	// チャットの受信は別goroutineで行い、受信したページをチャネル経由で処理する
+	receiver := youtubebot.NewLiveChatReceiver(app.LiveChatBot, app.Repository, app.MessageToOwnerWithError)
+	receiver.SetMinPollingInterval(time.Duration(app.Configs.Constants.SleepIntervalMilli) * time.Millisecond)
+	pages := make(chan youtubebot.ChatPage)
+	receiverStopped := make(chan error, 1)
+	go func() {
+		receiverStopped <- receiver.Run(ctx, pages)
+	}()
+
+	// Twitchのチャットも受信する場合。設定されていなければchatMessagesはnilのまま
+	var chatMessages chan chat.ChatMessage
+	if twitchConfig, ok := twitchbot.ConfigFromEnv(); ok {
+		slog.InfoContext(ctx, "receiving twitch chat", "channel", twitchConfig.Channel)
+		twitchChat := twitchbot.NewTwitchChat(twitchConfig, app.MessageToOwnerWithError)
+		// 返信はYouTubeと同じく、キューを通してまとめて間隔を空けて送る
+		twitchQueue := chat.NewQueuedChatSource(twitchChat, twitchbot.MaxMessageLength, twitchbot.IsTransientPostError,
+			app.MessageToOwnerWithError)
+		twitchQueue.SetMinInterval(twitchbot.MinPostInterval)
+		go twitchQueue.Run(context.WithoutCancel(ctx))
+		defer func() {
+			shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), LiveChatQueueShutdownTimeout)
+			defer cancel()
+			twitchQueue.Shutdown(shutdownCtx)
+		}()
+		app.AddChatSource(twitchQueue)
+		chatMessages = make(chan chat.ChatMessage)
+		go func() {
+			if err := twitchChat.Receive(ctx, chatMessages); err != nil && ctx.Err() == nil {
+				app.MessageToOwnerWithError(ctx, "twitch chat receiver stopped", err)
+			}
+		}()
+	}
 
-	const MinimumTryTimesToNotify = 2
-	numContinuousRetrieveNextPageTokenFailed := 0
-	numContinuousListMessagesFailed := 0
-	var lastChatFetched time.Time
-	var waitAtLeastMilliSec1 float64
-	var waitAtLeastMilliSec2 float64
-	var sleepInterval time.Duration
+	// チャットがなくても定期的な処理を行うためのティッカー
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
 
 	for {
+		select {
+		case page := <-pages:
+			processChatPage(ctx, app, ngWordConfig, page)
+			page.Done()
+		case message := <-chatMessages:
+			addLiveChatHistory(ctx, app, message)
+			processChatMessage(ctx, app, ngWordConfig, message)
+		case err := <-receiverStopped:
+			if ctx.Err() == nil {
+				app.MessageToOwnerWithError(ctx, "live chat receiver stopped", err)
+			}
+			return
+		case <-ticker.C:
+		}
+
+		// config/constantsの変更を反映
+		app.ApplyConstantsUpdates(ctx)
+
+		// YouTube Data APIの使用量を保存し、予算を超えそうならポーリングの間隔を延ばす
+		if time.Since(lastFlushedQuota) >= workspaceapp.YoutubeAPIQuotaFlushInterval {
+			app.FlushYoutubeAPIQuota(ctx)
+			lastFlushedQuota = time.Now()
+		}
+		receiver.SetMinPollingInterval(max(time.Duration(app.Configs.Constants.SleepIntervalMilli)*time.Millisecond,
+			app.ThrottledPollingInterval()))
+		liveChatQueue.SetMinInterval(time.Duration(app.Configs.Constants.LiveChatPostIntervalMilli) * time.Millisecond)
+
 		// max_seatsを変えるか確認
-		if timeutil.JstNow().After(lastCheckedDesiredMaxSeats.Add(time.Duration(checkDesiredMaxSeatsIntervalSec) * time.Second)) {
+		if timeutil.JstNow().After(lastCheckedDesiredMaxSeats.Add(time.Duration(app.Configs.Constants.CheckDesiredMaxSeatsIntervalSec) * time.Second)) {
 			slog.Info("checking desired max seats")
 			constants, err := app.Repository.ReadSystemConstantsConfig(ctx, nil)
 			if err != nil {
@@ -119,97 +225,46 @@ func Bot(ctx context.Context, clientOption option.ClientOption) {
 				}
 			}
 			lastCheckedDesiredMaxSeats = timeutil.JstNow()
-		}
-
-		// page token取得
-		pageToken, err := app.GetNextPageToken(ctx, nil)
-		if err != nil {
-			numContinuousRetrieveNextPageTokenFailed += 1
-			if numContinuousRetrieveNextPageTokenFailed >= MinimumTryTimesToNotify {
-				app.MessageToOwnerWithError(ctx, "（"+strconv.Itoa(numContinuousRetrieveNextPageTokenFailed)+"回目） failed to retrieve next page token", err)
-			}
-			waitSeconds := CalculateRetryIntervalSec(RetryIntervalCalculationBase, numContinuousRetrieveNextPageTokenFailed)
-			time.Sleep(time.Duration(waitSeconds) * time.Second)
-			continue
-		} else {
-			numContinuousRetrieveNextPageTokenFailed = 0
-		}
 
-		// fetch chat messages
-		chatMessages, nextPageToken, pollingIntervalMillis, err := app.ListLiveChatMessages(ctx, pageToken)
-		if err != nil {
-			numContinuousListMessagesFailed += 1
-			if numContinuousListMessagesFailed >= MinimumTryTimesToNotify {
-				app.MessageToOwnerWithError(ctx, "（"+strconv.Itoa(numContinuousListMessagesFailed)+
-					"回目） failed to retrieve chat messages", err)
+			if stats, ok := app.SeatCacheStats(); ok {
+				slog.Info("seat cache stats", "hits", stats.Hits, "misses", stats.Misses)
 			}
-			waitSeconds := CalculateRetryIntervalSec(RetryIntervalCalculationBase, numContinuousListMessagesFailed)
-			time.Sleep(time.Duration(waitSeconds) * time.Second)
-			continue
-		} else {
-			numContinuousListMessagesFailed = 0
 		}
-		lastChatFetched = timeutil.JstNow()
-
-		// save nextPageToken
-		if err := app.SaveNextPageToken(ctx, nextPageToken); err != nil {
-			app.MessageToOwnerWithError(ctx, "(1回目) failed to save next page token", err)
-			// 少し待ってから再試行
-			time.Sleep(3 * time.Second)
-			err2 := app.SaveNextPageToken(ctx, nextPageToken)
-			if err2 != nil {
-				app.MessageToOwnerWithError(ctx, "(2回目) failed to save next page token", err2)
-				// pass
-			}
-		}
-
-		// chatMessagesを保存
-		for _, chatMessage := range chatMessages {
-			// only if chatMessage has a text message
-			if !youtubebot.HasTextMessageByAuthor(chatMessage) {
-				continue
-			}
+	}
+}
 
-			if err = app.AddLiveChatHistoryDoc(ctx, chatMessage); err != nil {
-				app.MessageToOwnerWithError(ctx, "(1回目) failed to add live chat history", err)
-				time.Sleep(2 * time.Second)
-				if err2 := app.AddLiveChatHistoryDoc(ctx, chatMessage); err2 != nil {
-					app.MessageToOwnerWithError(ctx, "(2回目) failed to add live chat history", err2)
-					// pass
-				}
-			}
+// processChatPage 支援のイベントを処理し、受信したメッセージを履歴に保存してから、コマンドとして処理する
+func processChatPage(ctx context.Context, app *workspaceapp.WorkspaceApp, ngWordConfig workspaceapp.NGWordConfig, page youtubebot.ChatPage) {
+	for _, event := range page.FanFundingEvents {
+		if err := app.ProcessFanFundingEvent(ctx, event); err != nil {
+			app.MessageToOwnerWithError(ctx, "failed to ProcessFanFundingEvent", err)
 		}
+	}
+	for _, message := range page.Messages {
+		addLiveChatHistory(ctx, app, message)
+	}
+	for _, message := range page.Messages {
+		processChatMessage(ctx, app, ngWordConfig, message)
+	}
+}
 
-		// process the command (includes not command)
-		for _, chatMessage := range chatMessages {
-			if youtubebot.IsFanFundingEvent(chatMessage) {
-				app.MessageToOwner(ctx, fmt.Sprintf("Fan funding event:\n```%# v```", pretty.Formatter(chatMessage)))
-			}
-
-			// only if chatMessage has text message content
-			if !youtubebot.HasTextMessageByAuthor(chatMessage) {
-				continue
-			}
-
-			message := youtubebot.ExtractTextMessageByAuthor(chatMessage)
-			channelID := youtubebot.ExtractAuthorChannelID(chatMessage)
-			displayName := youtubebot.ExtractAuthorDisplayName(chatMessage)
-			profileImageURL := youtubebot.ExtractAuthorProfileImageURL(chatMessage)
-			isModerator := youtubebot.IsChatMessageByModerator(chatMessage)
-			isOwner := youtubebot.IsChatMessageByOwner(chatMessage)
-			isMember := isOwner || youtubebot.IsChatMessageByMember(chatMessage)
-			slog.Info(chatMessage.AuthorDetails.ChannelId + " (" + chatMessage.AuthorDetails.DisplayName + "): " + message)
-			if err := app.ProcessMessage(ctx, ngWordConfig, message, channelID, displayName, profileImageURL, isModerator, isOwner, isMember); err != nil {
-				app.MessageToOwnerWithError(ctx, "error in ProcessMessage()", err)
-			}
+// addLiveChatHistory メッセージを履歴に保存する。失敗したら1回だけ再試行し、それでも失敗したら諦める
+func addLiveChatHistory(ctx context.Context, app *workspaceapp.WorkspaceApp, message chat.ChatMessage) {
+	if err := app.AddLiveChatHistoryDoc(ctx, message); err != nil {
+		app.MessageToOwnerWithError(ctx, "(1回目) failed to add live chat history", err)
+		time.Sleep(2 * time.Second)
+		if err2 := app.AddLiveChatHistoryDoc(ctx, message); err2 != nil {
+			app.MessageToOwnerWithError(ctx, "(2回目) failed to add live chat history", err2)
+			// pass
 		}
+	}
+}
 
-		waitAtLeastMilliSec1 = math.Max(float64((time.Duration(pollingIntervalMillis)*time.Millisecond - timeutil.
-			JstNow().Sub(lastChatFetched)).Milliseconds()), 0)
-		waitAtLeastMilliSec2 = math.Max(float64((time.Duration(app.Configs.Constants.SleepIntervalMilli)*time.Millisecond - timeutil.JstNow().Sub(lastChatFetched)).Milliseconds()), 0)
-		sleepInterval = time.Duration(math.Max(waitAtLeastMilliSec1, waitAtLeastMilliSec2)) * time.Millisecond
-		slog.Info(fmt.Sprintf("waiting for %.2f seconds...\n\n", sleepInterval.Seconds()))
-		time.Sleep(sleepInterval)
+// processChatMessage 配信サービスに依存しないチャットのメッセージをコマンドとして処理する
+func processChatMessage(ctx context.Context, app *workspaceapp.WorkspaceApp, ngWordConfig workspaceapp.NGWordConfig, message chat.ChatMessage) {
+	slog.Info(message.Author.ID + " (" + message.Author.DisplayName + "): " + message.Text)
+	if err := app.ProcessChatMessage(ctx, ngWordConfig, message); err != nil {
+		app.MessageToOwnerWithError(ctx, "error in ProcessChatMessage()", err)
 	}
 }
 
@@ -218,6 +273,12 @@ func loadNGWordConfig(
 	clientOption option.ClientOption,
 	spreadsheetID string,
 ) (workspaceapp.NGWordConfig, error) {
+	if spreadsheetID == "" {
+		// シミュレーターとエミュレーターでオフラインで動かす場合など
+		slog.WarnContext(ctx, "BotConfigSpreadsheetID is empty; starting without NG words")
+		return workspaceapp.NewNGWordConfig(nil, nil, nil, nil), nil
+	}
+
 	slog.InfoContext(ctx, "initializing spreadsheet reader...")
 
 	wordsReader, err := wordsreader.NewSpreadsheetReader(ctx, clientOption, spreadsheetID, "01", "02")
@@ -227,7 +288,7 @@ func loadNGWordConfig(
 
 	slog.InfoContext(ctx, "reading block regexes...")
 
-	blockRegexesForChatMessage, blockRegexesForChannelName, err := wordsReader.ReadBlockRegexes(ctx)
+	blockRegexesForChatMessage, blockRegexesForChannelName, timeoutMinByRegex, err := wordsReader.ReadBlockRegexes(ctx)
 	if err != nil {
 		return workspaceapp.NGWordConfig{}, fmt.Errorf("in ReadBlockRegexes(): %w", err)
 	}
@@ -239,12 +300,17 @@ func loadNGWordConfig(
 		return workspaceapp.NGWordConfig{}, fmt.Errorf("in ReadNotificationRegexes(): %w", err)
 	}
 
+	blockTimeouts := make(map[string]time.Duration, len(timeoutMinByRegex))
+	for regex, timeoutMin := range timeoutMinByRegex {
+		blockTimeouts[regex] = time.Duration(timeoutMin) * time.Minute
+	}
+
 	return workspaceapp.NewNGWordConfig(
 		blockRegexesForChatMessage,
 		blockRegexesForChannelName,
 		notificationRegexesForChatMessage,
 		notificationRegexesForChannelName,
-	), nil
+	).WithBlockTimeouts(blockTimeouts), nil
 }
 
 func main() {
@@ -252,6 +318,8 @@ func main() {
 	if err != nil {
 		panic(err)
 	}
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	Bot(ctx, clientOption)
 }
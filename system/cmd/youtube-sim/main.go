@@ -0,0 +1,33 @@
+// youtube-sim はYouTube Data APIのシミュレーターを起動する。
+// youtube-botを YOUTUBE_API_ENDPOINT=http://localhost:8089/ で起動すると、本物の配信なしで動かせる。
+package main
+
+import (
+	"flag"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"app.modules/internal/youtubesim"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:8089", "listen address")
+	liveChatID := flag.String("live-chat-id", "sim-live-chat-1", "initial live chat id (config/credentials の youtube-live-chat-id と合わせる)")
+	botChannelID := flag.String("bot-channel-id", "sim-bot", "channel id of the bot (config/credentials の youtube-bot-channel-id と合わせる)")
+	pollingIntervalMillis := flag.Int64("polling-interval-millis", 1000, "pollingIntervalMillis returned by liveChatMessages.list")
+	flag.Parse()
+
+	server := youtubesim.NewServer(youtubesim.Config{
+		LiveChatID:            *liveChatID,
+		BotChannelID:          *botChannelID,
+		PollingIntervalMillis: *pollingIntervalMillis,
+	})
+	slog.Info("youtube simulator is listening.", "addr", *addr, "liveChatID", *liveChatID)
+	httpServer := &http.Server{Addr: *addr, Handler: server, ReadHeaderTimeout: 10 * time.Second}
+	if err := httpServer.ListenAndServe(); err != nil {
+		slog.Error("failed to serve", "err", err)
+		os.Exit(1)
+	}
+}
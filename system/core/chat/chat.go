@@ -0,0 +1,122 @@
+// Package chat はYouTubeやTwitchなど、配信サービスに依存しないチャットの型。
+// 複数の配信サービスのチャットを同じルームで扱えるように、ユーザーIDは配信サービスごとの名前空間をつける。
+package chat
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+type Platform string
+
+const (
+	YouTube Platform = "youtube"
+	Twitch  Platform = "twitch"
+)
+
+// idSeparator 名前空間とIDの区切り。YouTubeのチャンネルIDには含まれない
+const idSeparator = ":"
+
+// ID 配信サービスのユーザーIDやメッセージIDに名前空間をつける。
+// YouTubeは既存のユーザーのドキュメントと互換性を保つため、名前空間をつけずにそのまま使う。
+func ID(platform Platform, platformID string) string {
+	if platform == YouTube {
+		return platformID
+	}
+	return string(platform) + idSeparator + platformID
+}
+
+// SplitID IDで名前空間をつけたIDを、配信サービスとその配信サービスでのIDに分ける。
+func SplitID(id string) (Platform, string) {
+	for _, platform := range []Platform{Twitch} {
+		if platformID, ok := strings.CutPrefix(id, string(platform)+idSeparator); ok {
+			return platform, platformID
+		}
+	}
+	return YouTube, id
+}
+
+// ChannelURL モデレーター向けのログに載せる、ユーザーのチャンネルのURL。
+// TwitchのURLはユーザーIDからは作れないため、ユーザーIDを返す。
+func ChannelURL(userID string) string {
+	platform, platformID := SplitID(userID)
+	switch platform {
+	case YouTube:
+		return "https://youtube.com/channel/" + platformID
+	default:
+		return string(platform) + " user-id " + platformID
+	}
+}
+
+// Author メッセージの投稿者
+type Author struct {
+	ID              string // IDで名前空間をつけたユーザーID
+	DisplayName     string
+	ProfileImageURL string
+	IsModerator     bool
+	IsOwner         bool // 配信者
+	IsMember        bool // YouTubeのメンバー、Twitchのサブスクライバー。配信者も含む
+}
+
+// ChatMessage コマンドとして処理するテキストのメッセージ
+type ChatMessage struct {
+	ID          string // IDで名前空間をつけたメッセージID
+	Platform    Platform
+	ChatID      string // 投稿されたチャット。YouTubeはライブチャットID、Twitchはチャンネル
+	Type        string // 配信サービスでのメッセージの種類。YouTubeはsnippet.type、TwitchはIRCのコマンド
+	Author      Author
+	Text        string
+	PublishedAt time.Time // 配信サービスから取得できなければゼロ値
+}
+
+// ChatSource 配信サービスのチャット。ライブチャットへの投稿とモデレーションは、配信サービスによらずこれを通す。
+// YouTubeはyoutubebot.ChatSourceでLiveChatBotを包む。
+type ChatSource interface {
+	Platform() Platform
+	PostMessage(ctx context.Context, message string) error
+	// BanUser platformUserIDのユーザーを無期限でブロックし、UnbanUserで解除するときに使うブロックのIDを返す
+	BanUser(ctx context.Context, platformUserID string) (string, error)
+	// TimeOut platformUserIDのユーザーをdurationの間だけブロックし、ブロックのIDを返す
+	TimeOut(ctx context.Context, platformUserID string, duration time.Duration) (string, error)
+	UnbanUser(ctx context.Context, banID string) error
+}
+
+// ChatReceiver 受信したメッセージをそのまま流せるChatSource。
+// YouTubeはpage tokenの保存や支援のイベントの処理があるため、youtubebot.LiveChatReceiverでページごとに受信する。
+type ChatReceiver interface {
+	ChatSource
+	// Receive ctxがキャンセルされるまでチャットを受信してoutに送る。切断されたら再接続する。
+	Receive(ctx context.Context, out chan<- ChatMessage) error
+}
+
+// QueuedChatSource PostMessageをOutboundQueueに積むChatSource。PostMessage以外はそのまま内側のChatSourceを呼ぶ。
+type QueuedChatSource struct {
+	ChatSource
+	queue *OutboundQueue
+}
+
+func NewQueuedChatSource(source ChatSource, maxMessageLength int, isTransientError func(err error) bool,
+	notify func(ctx context.Context, message string, err error),
+) *QueuedChatSource {
+	return &QueuedChatSource{
+		ChatSource: source,
+		queue:      NewOutboundQueue(source, maxMessageLength, isTransientError, notify),
+	}
+}
+
+func (s *QueuedChatSource) PostMessage(ctx context.Context, message string) error {
+	return s.queue.PostMessage(ctx, message)
+}
+
+func (s *QueuedChatSource) SetMinInterval(interval time.Duration) {
+	s.queue.SetMinInterval(interval)
+}
+
+func (s *QueuedChatSource) Run(ctx context.Context) {
+	s.queue.Run(ctx)
+}
+
+func (s *QueuedChatSource) Shutdown(ctx context.Context) {
+	s.queue.Shutdown(ctx)
+}
@@ -0,0 +1,33 @@
+package chat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestID(t *testing.T) {
+	testCases := []struct {
+		name       string
+		platform   Platform
+		platformID string
+		id         string
+	}{
+		{name: "YouTubeはそのまま", platform: YouTube, platformID: "UCxxxxxxxxxxxxxxxxxxxxxx", id: "UCxxxxxxxxxxxxxxxxxxxxxx"},
+		{name: "Twitchは名前空間つき", platform: Twitch, platformID: "123456", id: "twitch:123456"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			id := ID(tc.platform, tc.platformID)
+			assert.Equal(t, tc.id, id)
+			platform, platformID := SplitID(id)
+			assert.Equal(t, tc.platform, platform)
+			assert.Equal(t, tc.platformID, platformID)
+		})
+	}
+}
+
+func TestChannelURL(t *testing.T) {
+	assert.Equal(t, "https://youtube.com/channel/UCxxxxxxxxxxxxxxxxxxxxxx", ChannelURL("UCxxxxxxxxxxxxxxxxxxxxxx"))
+	assert.Equal(t, "twitch user-id 123456", ChannelURL("twitch:123456"))
+}
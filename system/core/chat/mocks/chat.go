@@ -0,0 +1,225 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ../chat.go
+//
+// Generated by this command:
+//
+//	mockgen -source ../chat.go -destination ./chat.go -package mock_chat
+//
+
+// Package mock_chat is a generated GoMock package.
+package mock_chat
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	chat "app.modules/core/chat"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockChatSource is a mock of ChatSource interface.
+type MockChatSource struct {
+	ctrl     *gomock.Controller
+	recorder *MockChatSourceMockRecorder
+	isgomock struct{}
+}
+
+// MockChatSourceMockRecorder is the mock recorder for MockChatSource.
+type MockChatSourceMockRecorder struct {
+	mock *MockChatSource
+}
+
+// NewMockChatSource creates a new mock instance.
+func NewMockChatSource(ctrl *gomock.Controller) *MockChatSource {
+	mock := &MockChatSource{ctrl: ctrl}
+	mock.recorder = &MockChatSourceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockChatSource) EXPECT() *MockChatSourceMockRecorder {
+	return m.recorder
+}
+
+// BanUser mocks base method.
+func (m *MockChatSource) BanUser(ctx context.Context, platformUserID string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BanUser", ctx, platformUserID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BanUser indicates an expected call of BanUser.
+func (mr *MockChatSourceMockRecorder) BanUser(ctx, platformUserID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BanUser", reflect.TypeOf((*MockChatSource)(nil).BanUser), ctx, platformUserID)
+}
+
+// Platform mocks base method.
+func (m *MockChatSource) Platform() chat.Platform {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Platform")
+	ret0, _ := ret[0].(chat.Platform)
+	return ret0
+}
+
+// Platform indicates an expected call of Platform.
+func (mr *MockChatSourceMockRecorder) Platform() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Platform", reflect.TypeOf((*MockChatSource)(nil).Platform))
+}
+
+// PostMessage mocks base method.
+func (m *MockChatSource) PostMessage(ctx context.Context, message string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PostMessage", ctx, message)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PostMessage indicates an expected call of PostMessage.
+func (mr *MockChatSourceMockRecorder) PostMessage(ctx, message any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostMessage", reflect.TypeOf((*MockChatSource)(nil).PostMessage), ctx, message)
+}
+
+// TimeOut mocks base method.
+func (m *MockChatSource) TimeOut(ctx context.Context, platformUserID string, duration time.Duration) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TimeOut", ctx, platformUserID, duration)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TimeOut indicates an expected call of TimeOut.
+func (mr *MockChatSourceMockRecorder) TimeOut(ctx, platformUserID, duration any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TimeOut", reflect.TypeOf((*MockChatSource)(nil).TimeOut), ctx, platformUserID, duration)
+}
+
+// UnbanUser mocks base method.
+func (m *MockChatSource) UnbanUser(ctx context.Context, banID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnbanUser", ctx, banID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnbanUser indicates an expected call of UnbanUser.
+func (mr *MockChatSourceMockRecorder) UnbanUser(ctx, banID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnbanUser", reflect.TypeOf((*MockChatSource)(nil).UnbanUser), ctx, banID)
+}
+
+// MockChatReceiver is a mock of ChatReceiver interface.
+type MockChatReceiver struct {
+	ctrl     *gomock.Controller
+	recorder *MockChatReceiverMockRecorder
+	isgomock struct{}
+}
+
+// MockChatReceiverMockRecorder is the mock recorder for MockChatReceiver.
+type MockChatReceiverMockRecorder struct {
+	mock *MockChatReceiver
+}
+
+// NewMockChatReceiver creates a new mock instance.
+func NewMockChatReceiver(ctrl *gomock.Controller) *MockChatReceiver {
+	mock := &MockChatReceiver{ctrl: ctrl}
+	mock.recorder = &MockChatReceiverMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockChatReceiver) EXPECT() *MockChatReceiverMockRecorder {
+	return m.recorder
+}
+
+// BanUser mocks base method.
+func (m *MockChatReceiver) BanUser(ctx context.Context, platformUserID string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BanUser", ctx, platformUserID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BanUser indicates an expected call of BanUser.
+func (mr *MockChatReceiverMockRecorder) BanUser(ctx, platformUserID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BanUser", reflect.TypeOf((*MockChatReceiver)(nil).BanUser), ctx, platformUserID)
+}
+
+// Platform mocks base method.
+func (m *MockChatReceiver) Platform() chat.Platform {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Platform")
+	ret0, _ := ret[0].(chat.Platform)
+	return ret0
+}
+
+// Platform indicates an expected call of Platform.
+func (mr *MockChatReceiverMockRecorder) Platform() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Platform", reflect.TypeOf((*MockChatReceiver)(nil).Platform))
+}
+
+// PostMessage mocks base method.
+func (m *MockChatReceiver) PostMessage(ctx context.Context, message string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PostMessage", ctx, message)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PostMessage indicates an expected call of PostMessage.
+func (mr *MockChatReceiverMockRecorder) PostMessage(ctx, message any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostMessage", reflect.TypeOf((*MockChatReceiver)(nil).PostMessage), ctx, message)
+}
+
+// Receive mocks base method.
+func (m *MockChatReceiver) Receive(ctx context.Context, out chan<- chat.ChatMessage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Receive", ctx, out)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Receive indicates an expected call of Receive.
+func (mr *MockChatReceiverMockRecorder) Receive(ctx, out any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Receive", reflect.TypeOf((*MockChatReceiver)(nil).Receive), ctx, out)
+}
+
+// TimeOut mocks base method.
+func (m *MockChatReceiver) TimeOut(ctx context.Context, platformUserID string, duration time.Duration) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TimeOut", ctx, platformUserID, duration)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TimeOut indicates an expected call of TimeOut.
+func (mr *MockChatReceiverMockRecorder) TimeOut(ctx, platformUserID, duration any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TimeOut", reflect.TypeOf((*MockChatReceiver)(nil).TimeOut), ctx, platformUserID, duration)
+}
+
+// UnbanUser mocks base method.
+func (m *MockChatReceiver) UnbanUser(ctx context.Context, banID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnbanUser", ctx, banID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnbanUser indicates an expected call of UnbanUser.
+func (mr *MockChatReceiverMockRecorder) UnbanUser(ctx, banID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnbanUser", reflect.TypeOf((*MockChatReceiver)(nil).UnbanUser), ctx, banID)
+}
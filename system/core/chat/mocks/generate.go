@@ -0,0 +1,3 @@
+package mock_chat
+
+//go:generate go run go.uber.org/mock/mockgen -source ../chat.go -destination ./chat.go -package mock_chat
@@ -0,0 +1,236 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// outboundMessageSeparator まとめて送るときの返信どうしの区切り
+	outboundMessageSeparator = "　"
+
+	maxPostAttempts = 3
+)
+
+// Poster チャットにメッセージを投稿できるもの
+type Poster interface {
+	PostMessage(ctx context.Context, message string) error
+}
+
+// OutboundQueue PostMessageをキューに積んで返し、Runのgoroutineから送信する。配信サービスごとに1つ使う。
+// 上限の文字数を超える返信は分割して積み、短い返信は上限に収まる範囲で1つにまとめ、送信の間隔は SetMinInterval で制限する。
+// isTransientErrorがtrueを返すエラーは間隔を空けて再試行し、送れなかったメッセージはnotifyでownerに知らせる。
+type OutboundQueue struct {
+	poster           Poster
+	maxMessageLength int
+	isTransientError func(err error) bool
+	notify           func(ctx context.Context, message string, err error)
+
+	mu       sync.Mutex
+	pending  []string
+	sending  string // 送信中のメッセージ
+	shutdown bool   // Shutdownが呼ばれた後はキューに積まずに直接送る
+
+	wake     chan struct{}
+	stop     chan struct{}
+	done     chan struct{}
+	flushCtx context.Context // Shutdownから渡される、残りを送り切るまでの期限
+
+	minIntervalMilli atomic.Int64
+	lastSentAt       time.Time
+
+	retryInterval func(attempt int) time.Duration
+}
+
+func NewOutboundQueue(poster Poster, maxMessageLength int, isTransientError func(err error) bool,
+	notify func(ctx context.Context, message string, err error),
+) *OutboundQueue {
+	return &OutboundQueue{
+		poster:           poster,
+		maxMessageLength: maxMessageLength,
+		isTransientError: isTransientError,
+		notify:           notify,
+		wake:             make(chan struct{}, 1),
+		stop:             make(chan struct{}),
+		done:             make(chan struct{}),
+		retryInterval: func(attempt int) time.Duration {
+			return time.Duration(attempt) * time.Second
+		},
+	}
+}
+
+// SetMinInterval 送信の最小の間隔を設定する。動作中に変更してよい。
+func (q *OutboundQueue) SetMinInterval(interval time.Duration) {
+	q.minIntervalMilli.Store(interval.Milliseconds())
+}
+
+// PostMessage messageをキューに積む。送信の結果は待たない。
+func (q *OutboundQueue) PostMessage(ctx context.Context, message string) error {
+	q.mu.Lock()
+	if q.shutdown {
+		q.mu.Unlock()
+		return q.poster.PostMessage(ctx, message)
+	}
+	q.pending = append(q.pending, SplitMessage(message, q.maxMessageLength)...)
+	q.mu.Unlock()
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Run キューに積まれたメッセージを送信し続ける。Shutdownが呼ばれると残りを送ってから返る。
+func (q *OutboundQueue) Run(ctx context.Context) {
+	defer close(q.done)
+	for {
+		select {
+		case <-q.wake:
+			q.sendPending(ctx)
+		case <-q.stop:
+			q.sendPending(q.flushCtx)
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Shutdown 以後のPostMessageは直接送るようにし、キューに残っているメッセージをctxの期限まで送る。
+// 送れなかったメッセージはownerに知らせる。
+func (q *OutboundQueue) Shutdown(ctx context.Context) {
+	q.mu.Lock()
+	alreadyShutdown := q.shutdown
+	q.shutdown = true
+	q.mu.Unlock()
+	if alreadyShutdown {
+		return
+	}
+
+	q.flushCtx = ctx
+	close(q.stop)
+	select {
+	case <-q.done:
+	case <-ctx.Done():
+	}
+
+	q.mu.Lock()
+	undelivered := q.pending
+	if q.sending != "" {
+		undelivered = append([]string{q.sending}, undelivered...)
+	}
+	q.pending = nil
+	q.mu.Unlock()
+	if len(undelivered) > 0 {
+		q.notify(context.WithoutCancel(ctx), "送信できなかったライブチャットのメッセージがあります。\n"+strings.Join(undelivered, "\n"),
+			errors.New("live chat queue was shut down"))
+	}
+}
+
+// sendPending キューが空になるまで、間隔を空けながらまとめて送る。ctxが終わった時点で送っていないものはキューに残す
+func (q *OutboundQueue) sendPending(ctx context.Context) {
+	for {
+		wait := time.Until(q.lastSentAt.Add(time.Duration(q.minIntervalMilli.Load()) * time.Millisecond))
+		if err := sleepContext(ctx, max(wait, 0)); err != nil {
+			return
+		}
+
+		q.mu.Lock()
+		message, n := coalesceMessages(q.pending, q.maxMessageLength)
+		q.pending = q.pending[n:]
+		q.sending = message
+		q.mu.Unlock()
+		if n == 0 {
+			return
+		}
+
+		err := q.postWithRetry(ctx, message)
+		q.lastSentAt = time.Now()
+		q.mu.Lock()
+		q.sending = ""
+		if err != nil && ctx.Err() != nil {
+			// 送り切れなかったので、Shutdownで報告できるように戻しておく
+			q.pending = append([]string{message}, q.pending...)
+			q.mu.Unlock()
+			return
+		}
+		q.mu.Unlock()
+		if err != nil {
+			q.notify(ctx, "failed to send live chat message \""+message+"\"\n", err)
+		}
+	}
+}
+
+func (q *OutboundQueue) postWithRetry(ctx context.Context, message string) error {
+	var err error
+	for attempt := 1; attempt <= maxPostAttempts; attempt++ {
+		err = q.poster.PostMessage(ctx, message)
+		if err == nil || attempt == maxPostAttempts || !q.retryable(err) {
+			return err
+		}
+		slog.Warn("failed to post a queued message; retrying", "err", err, "attempt", attempt)
+		if sleepErr := sleepContext(ctx, q.retryInterval(attempt)); sleepErr != nil {
+			return errors.Join(err, sleepErr)
+		}
+	}
+	return err
+}
+
+func (q *OutboundQueue) retryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	return q.isTransientError(err)
+}
+
+// coalesceMessages 先頭からmaxLength文字に収まるだけ返信をつなげ、使った件数と一緒に返す。
+func coalesceMessages(pending []string, maxLength int) (string, int) {
+	if len(pending) == 0 {
+		return "", 0
+	}
+	message := pending[0]
+	length := utf8.RuneCountInString(message)
+	n := 1
+	for ; n < len(pending); n++ {
+		next := utf8.RuneCountInString(outboundMessageSeparator) + utf8.RuneCountInString(pending[n])
+		if length+next > maxLength {
+			break
+		}
+		message += outboundMessageSeparator + pending[n]
+		length += next
+	}
+	return message, n
+}
+
+// SplitMessage messageをmaxLength文字以内に分割する。
+func SplitMessage(message string, maxLength int) []string {
+	var messages []string
+	for utf8.RuneCountInString(message) > maxLength {
+		p := 0 // maxLength文字目の次のバイト位置
+		for range maxLength {
+			_, size := utf8.DecodeRuneInString(message[p:])
+			p += size
+		}
+		messages = append(messages, message[:p])
+		message = message[p:]
+	}
+	return append(messages, message)
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
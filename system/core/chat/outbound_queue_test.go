@@ -0,0 +1,172 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingPoster 投稿を記録する。errsがあれば先頭から順にPostMessageの結果として使う（nilなら成功）
+type recordingPoster struct {
+	mu      sync.Mutex
+	posts   []string
+	postAt  []time.Time
+	errs    []error
+	blocked chan struct{} // nilでなければ閉じられるまでPostMessageを返さない
+}
+
+func (p *recordingPoster) PostMessage(ctx context.Context, message string) error {
+	if p.blocked != nil {
+		select {
+		case <-p.blocked:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.errs) > 0 {
+		err := p.errs[0]
+		p.errs = p.errs[1:]
+		if err != nil {
+			return err
+		}
+	}
+	p.posts = append(p.posts, message)
+	p.postAt = append(p.postAt, time.Now())
+	return nil
+}
+
+func (p *recordingPoster) recorded() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.posts...)
+}
+
+type notification struct {
+	message string
+	err     error
+}
+
+var errTransient = errors.New("temporarily unavailable")
+
+const testMaxMessageLength = 50
+
+func newTestQueue(poster Poster) (*OutboundQueue, func() []notification) {
+	var mu sync.Mutex
+	var notified []notification
+	queue := NewOutboundQueue(poster, testMaxMessageLength,
+		func(err error) bool { return errors.Is(err, errTransient) },
+		func(_ context.Context, message string, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			notified = append(notified, notification{message, err})
+		})
+	queue.retryInterval = func(int) time.Duration { return time.Millisecond }
+	return queue, func() []notification {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]notification(nil), notified...)
+	}
+}
+
+func TestOutboundQueue_CoalescesShortReplies(t *testing.T) {
+	poster := &recordingPoster{}
+	queue, notified := newTestQueue(poster)
+	ctx := context.Background()
+
+	long := strings.Repeat("あ", testMaxMessageLength-10)
+	for _, message := range []string{"@a さん、入室しました", "@b さん、退室しました", long, "@c さん、休憩します"} {
+		require.NoError(t, queue.PostMessage(ctx, message))
+	}
+	go queue.Run(ctx)
+	queue.Shutdown(ctx)
+
+	// 上限の文字数を超える組み合わせはまとめない
+	assert.Equal(t, []string{"@a さん、入室しました　@b さん、退室しました", long, "@c さん、休憩します"}, poster.recorded())
+	assert.Empty(t, notified())
+}
+
+func TestOutboundQueue_SplitsLongReplies(t *testing.T) {
+	poster := &recordingPoster{}
+	queue, _ := newTestQueue(poster)
+	ctx := context.Background()
+
+	require.NoError(t, queue.PostMessage(ctx, strings.Repeat("あ", testMaxMessageLength)+strings.Repeat("い", 10)))
+	require.NoError(t, queue.PostMessage(ctx, "@a さん、入室しました"))
+	go queue.Run(ctx)
+	queue.Shutdown(ctx)
+
+	// 分割した残りは後続の返信とまとめる
+	assert.Equal(t, []string{strings.Repeat("あ", testMaxMessageLength), strings.Repeat("い", 10) + "　@a さん、入室しました"},
+		poster.recorded())
+}
+
+func TestOutboundQueue_RespectsMinInterval(t *testing.T) {
+	poster := &recordingPoster{}
+	queue, _ := newTestQueue(poster)
+	queue.SetMinInterval(50 * time.Millisecond)
+	ctx := context.Background()
+	go queue.Run(ctx)
+
+	require.NoError(t, queue.PostMessage(ctx, "first"))
+	require.Eventually(t, func() bool { return len(poster.recorded()) == 1 }, time.Second, time.Millisecond)
+	require.NoError(t, queue.PostMessage(ctx, "second"))
+	require.NoError(t, queue.PostMessage(ctx, "third"))
+	queue.Shutdown(ctx)
+
+	// 間隔を待っている間に積まれた返信はまとめて送られる
+	require.Equal(t, []string{"first", "second　third"}, poster.recorded())
+	assert.GreaterOrEqual(t, poster.postAt[1].Sub(poster.postAt[0]), 50*time.Millisecond)
+}
+
+func TestOutboundQueue_RetriesOnlyTransientErrors(t *testing.T) {
+	poster := &recordingPoster{errs: []error{
+		errTransient,
+		nil,
+		errors.New("forbidden"),
+	}}
+	queue, notified := newTestQueue(poster)
+	ctx := context.Background()
+	go queue.Run(ctx)
+
+	require.NoError(t, queue.PostMessage(ctx, "retried"))
+	require.Eventually(t, func() bool { return len(poster.recorded()) == 1 }, time.Second, time.Millisecond)
+	require.NoError(t, queue.PostMessage(ctx, "rejected"))
+	queue.Shutdown(ctx)
+
+	assert.Equal(t, []string{"retried"}, poster.recorded())
+	require.Len(t, notified(), 1)
+	assert.Contains(t, notified()[0].message, "rejected")
+}
+
+func TestOutboundQueue_ShutdownReportsUndelivered(t *testing.T) {
+	poster := &recordingPoster{blocked: make(chan struct{})}
+	queue, notified := newTestQueue(poster)
+	go queue.Run(context.Background())
+
+	require.NoError(t, queue.PostMessage(context.Background(), "@a さん、入室しました"))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	queue.Shutdown(ctx)
+
+	require.Len(t, notified(), 1)
+	assert.Contains(t, notified()[0].message, "@a さん、入室しました")
+
+	// Shutdownの後は直接送る
+	close(poster.blocked)
+	require.NoError(t, queue.PostMessage(context.Background(), "direct"))
+	assert.Contains(t, poster.recorded(), "direct")
+}
+
+func TestSplitMessage(t *testing.T) {
+	assert.Equal(t, []string{"あいう"}, SplitMessage("あいう", 3))
+	assert.Equal(t, []string{"あい", "うえ", "お"}, SplitMessage("あいうえお", 2))
+	assert.Equal(t, []string{""}, SplitMessage("", 2))
+}
@@ -6,7 +6,6 @@ import (
 	"log/slog"
 
 	"golang.org/x/oauth2"
-	"google.golang.org/api/option"
 	"google.golang.org/api/youtube/v3"
 
 	"app.modules/core/moderatorbot"
@@ -18,17 +17,23 @@ type LiveStreamChecker struct {
 	YoutubeLiveChatBot  youtubebot.LiveChatBot
 	alertOwnerBot       moderatorbot.MessageBot
 	FirestoreController repository.Repository
+	endpoint            string // 空なら本物のYouTube Data API
+	quotaMeter          *youtubebot.QuotaMeter
 }
 
 func NewLiveStreamChecker(
 	controller repository.Repository,
 	youtubeLiveChatBot youtubebot.LiveChatBot,
 	messageBot moderatorbot.MessageBot,
+	endpoint string,
+	quotaMeter *youtubebot.QuotaMeter,
 ) *LiveStreamChecker {
 	return &LiveStreamChecker{
 		YoutubeLiveChatBot:  youtubeLiveChatBot,
 		alertOwnerBot:       messageBot,
 		FirestoreController: controller,
+		endpoint:            endpoint,
+		quotaMeter:          quotaMeter,
 	}
 }
 
@@ -48,13 +53,13 @@ func (checker *LiveStreamChecker) Check(ctx context.Context) error {
 		TokenType:    "Bearer",
 		RefreshToken: credentials.YoutubeChannelRefreshToken,
 	}
-	channelClientOption := option.WithTokenSource(config.TokenSource(ctx, channelOauthToken))
-	service, err := youtube.NewService(ctx, channelClientOption)
+	service, _, err := youtubebot.NewYoutubeService(ctx, checker.endpoint, config.TokenSource(ctx, channelOauthToken))
 	if err != nil {
-		return fmt.Errorf("in youtube.NewService: %w", err)
+		return fmt.Errorf("in NewYoutubeService: %w", err)
 	}
 
 	broadcastsService := youtube.NewLiveBroadcastsService(service)
+	checker.quotaMeter.Record(youtubebot.QuotaListBroadcasts)
 	broadcastsListResponse, err := broadcastsService.List([]string{"snippet", "contentDetails"}).BroadcastStatus("active").Do()
 	if err != nil {
 		return fmt.Errorf("broadcasts.List: %w", err)
@@ -70,6 +75,7 @@ func (checker *LiveStreamChecker) Check(ctx context.Context) error {
 	}
 
 	streamsService := youtube.NewLiveStreamsService(service)
+	checker.quotaMeter.Record(youtubebot.QuotaListStreams)
 	liveStreamListResponse, err := streamsService.List([]string{"status"}).Mine(true).Do()
 	if err != nil {
 		return fmt.Errorf("in streamsService.List: %w", err)
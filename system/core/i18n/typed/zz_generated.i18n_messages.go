@@ -191,6 +191,11 @@ func CommandUserInfoRegisterDate(value string) string {
 	return engine.TranslateDefault("command-user-info:register-date", value)
 }
 
+// CommandUserInfoDailyGoal: key "command-user-info:daily-goal"
+func CommandUserInfoDailyGoal(dailyMin int, goalMin int, percent int) string {
+	return engine.TranslateDefault("command-user-info:daily-goal", dailyMin, goalMin, percent)
+}
+
 // CommandSeatInfoBreakUntil: key "command-seat-info:break-until"
 func CommandSeatInfoBreakUntil(minutes int) string {
 	return engine.TranslateDefault("command-seat-info:break-until", minutes)
@@ -236,6 +241,11 @@ func CommandBlockBlock(username string, seat string, targetUser string) string {
 	return engine.TranslateDefault("command-block:block", username, seat, targetUser)
 }
 
+// CommandTimeoutTimeout: key "command-timeout:timeout"
+func CommandTimeoutTimeout(username string, seat string, targetUser string, durationMin int) string {
+	return engine.TranslateDefault("command-timeout:timeout", username, seat, targetUser, durationMin)
+}
+
 // CommandMyAlreadyRank: key "command-my:already-rank"
 func CommandMyAlreadyRank(value string) string {
 	return engine.TranslateDefault("command-my:already-rank", value)
@@ -271,6 +281,16 @@ func CommandMyAlertFavoriteColor(hour int) string {
 	return engine.TranslateDefault("command-my:alert-favorite-color", hour)
 }
 
+// CommandMyResetDailyGoal: key "command-my:reset-daily-goal"
+func CommandMyResetDailyGoal() string {
+	return engine.TranslateDefault("command-my:reset-daily-goal")
+}
+
+// CommandMySetDailyGoal: key "command-my:set-daily-goal"
+func CommandMySetDailyGoal(goalMin int) string {
+	return engine.TranslateDefault("command-my:set-daily-goal", goalMin)
+}
+
 // CommandChangeUpdateWork: key "command-change:update-work"
 func CommandChangeUpdateWork(workName string, seat string) string {
 	return engine.TranslateDefault("command-change:update-work", workName, seat)
@@ -371,11 +391,306 @@ func CommandOrderCleared(username string) string {
 	return engine.TranslateDefault("command-order:cleared", username)
 }
 
+// CommandPomodoroSetting: key "command-pomodoro:setting"
+func CommandPomodoroSetting(workMin int, breakMin int) string {
+	return engine.TranslateDefault("command-pomodoro:setting", workMin, breakMin)
+}
+
+// CommandPomodoroCleared: key "command-pomodoro:cleared"
+func CommandPomodoroCleared() string {
+	return engine.TranslateDefault("command-pomodoro:cleared")
+}
+
+// CommandPomodoroBreak: key "command-pomodoro:break"
+func CommandPomodoroBreak(username string, breakMin int, seat string) string {
+	return engine.TranslateDefault("command-pomodoro:break", username, breakMin, seat)
+}
+
+// CommandPomodoroWork: key "command-pomodoro:work"
+func CommandPomodoroWork(username string, workMin int, seat string) string {
+	return engine.TranslateDefault("command-pomodoro:work", username, workMin, seat)
+}
+
+// CommandHistoryNoHistory: key "command-history:no-history"
+func CommandHistoryNoHistory(username string, days int) string {
+	return engine.TranslateDefault("command-history:no-history", username, days)
+}
+
+// CommandHistoryHeader: key "command-history:header"
+func CommandHistoryHeader(username string, count int) string {
+	return engine.TranslateDefault("command-history:header", username, count)
+}
+
+// CommandHistorySession: key "command-history:session"
+func CommandHistorySession(month int, day int, seat string, workTime string, breakTime string, workNames string) string {
+	return engine.TranslateDefault("command-history:session", month, day, seat, workTime, breakTime, workNames)
+}
+
+// CommandHistoryWorkName: key "command-history:work-name"
+func CommandHistoryWorkName(workName string, duration string) string {
+	return engine.TranslateDefault("command-history:work-name", workName, duration)
+}
+
+// CommandHistoryNoWorkName: key "command-history:no-work-name"
+func CommandHistoryNoWorkName() string {
+	return engine.TranslateDefault("command-history:no-work-name")
+}
+
+// CommandHistoryWorkNameSeparator: key "command-history:work-name-separator"
+func CommandHistoryWorkNameSeparator() string {
+	return engine.TranslateDefault("command-history:work-name-separator")
+}
+
+// CommandStreakStreak: key "command-streak:streak"
+func CommandStreakStreak(username string, currentDays int, bestDays int) string {
+	return engine.TranslateDefault("command-streak:streak", username, currentDays, bestDays)
+}
+
+// CommandStreakMilestone: key "command-streak:milestone"
+func CommandStreakMilestone(username string, days int) string {
+	return engine.TranslateDefault("command-streak:milestone", username, days)
+}
+
+// CommandUndoRestored: key "command-undo:restored"
+func CommandUndoRestored(username string, seat string) string {
+	return engine.TranslateDefault("command-undo:restored", username, seat)
+}
+
+// CommandUndoNothing: key "command-undo:nothing"
+func CommandUndoNothing(username string) string {
+	return engine.TranslateDefault("command-undo:nothing", username)
+}
+
+// CommandUndoExpired: key "command-undo:expired"
+func CommandUndoExpired(username string, graceMin int) string {
+	return engine.TranslateDefault("command-undo:expired", username, graceMin)
+}
+
+// CommandUndoAlreadyIn: key "command-undo:already-in"
+func CommandUndoAlreadyIn(username string) string {
+	return engine.TranslateDefault("command-undo:already-in", username)
+}
+
+// CommandUndoSeatTaken: key "command-undo:seat-taken"
+func CommandUndoSeatTaken(username string, seat string, inCommand string) string {
+	return engine.TranslateDefault("command-undo:seat-taken", username, seat, inCommand)
+}
+
+// CommandReserveReserved: key "command-reserve:reserved"
+func CommandReserveReserved(username string, seat string, startAt string, durationMin int) string {
+	return engine.TranslateDefault("command-reserve:reserved", username, seat, startAt, durationMin)
+}
+
+// CommandReserveDisabled: key "command-reserve:disabled"
+func CommandReserveDisabled(username string) string {
+	return engine.TranslateDefault("command-reserve:disabled", username)
+}
+
+// CommandReserveNoSeat: key "command-reserve:no-seat"
+func CommandReserveNoSeat(username string, seat string) string {
+	return engine.TranslateDefault("command-reserve:no-seat", username, seat)
+}
+
+// CommandReserveLimit: key "command-reserve:limit"
+func CommandReserveLimit(username string, maxCount int) string {
+	return engine.TranslateDefault("command-reserve:limit", username, maxCount)
+}
+
+// CommandReserveConflict: key "command-reserve:conflict"
+func CommandReserveConflict(username string, seat string) string {
+	return engine.TranslateDefault("command-reserve:conflict", username, seat)
+}
+
+// CommandReserveSeatReserved: key "command-reserve:seat-reserved"
+func CommandReserveSeatReserved(username string, seat string) string {
+	return engine.TranslateDefault("command-reserve:seat-reserved", username, seat)
+}
+
+// CommandReserveNoShow: key "command-reserve:no-show"
+func CommandReserveNoShow(username string, seat string) string {
+	return engine.TranslateDefault("command-reserve:no-show", username, seat)
+}
+
+// CommandReserveCanceled: key "command-reserve:canceled"
+func CommandReserveCanceled(username string, count int) string {
+	return engine.TranslateDefault("command-reserve:canceled", username, count)
+}
+
+// CommandReserveNoReservation: key "command-reserve:no-reservation"
+func CommandReserveNoReservation(username string) string {
+	return engine.TranslateDefault("command-reserve:no-reservation", username)
+}
+
+// CommandHelpList: key "command-help:list"
+func CommandHelpList(username string, commands string) string {
+	return engine.TranslateDefault("command-help:list", username, commands)
+}
+
+// CommandHelpUnknownTopic: key "command-help:unknown-topic"
+func CommandHelpUnknownTopic(username string, topic string) string {
+	return engine.TranslateDefault("command-help:unknown-topic", username, topic)
+}
+
+// CommandHelpIn: key "command-help:in"
+func CommandHelpIn() string {
+	return engine.TranslateDefault("command-help:in")
+}
+
+// CommandHelpOut: key "command-help:out"
+func CommandHelpOut() string {
+	return engine.TranslateDefault("command-help:out")
+}
+
+// CommandHelpInfo: key "command-help:info"
+func CommandHelpInfo() string {
+	return engine.TranslateDefault("command-help:info")
+}
+
+// CommandHelpMy: key "command-help:my"
+func CommandHelpMy() string {
+	return engine.TranslateDefault("command-help:my")
+}
+
+// CommandHelpChange: key "command-help:change"
+func CommandHelpChange() string {
+	return engine.TranslateDefault("command-help:change")
+}
+
+// CommandHelpSeat: key "command-help:seat"
+func CommandHelpSeat() string {
+	return engine.TranslateDefault("command-help:seat")
+}
+
+// CommandHelpReport: key "command-help:report"
+func CommandHelpReport() string {
+	return engine.TranslateDefault("command-help:report")
+}
+
+// CommandHelpKick: key "command-help:kick"
+func CommandHelpKick() string {
+	return engine.TranslateDefault("command-help:kick")
+}
+
+// CommandHelpCheck: key "command-help:check"
+func CommandHelpCheck() string {
+	return engine.TranslateDefault("command-help:check")
+}
+
+// CommandHelpBlock: key "command-help:block"
+func CommandHelpBlock() string {
+	return engine.TranslateDefault("command-help:block")
+}
+
+// CommandHelpMore: key "command-help:more"
+func CommandHelpMore() string {
+	return engine.TranslateDefault("command-help:more")
+}
+
+// CommandHelpBreak: key "command-help:break"
+func CommandHelpBreak() string {
+	return engine.TranslateDefault("command-help:break")
+}
+
+// CommandHelpResume: key "command-help:resume"
+func CommandHelpResume() string {
+	return engine.TranslateDefault("command-help:resume")
+}
+
+// CommandHelpRank: key "command-help:rank"
+func CommandHelpRank() string {
+	return engine.TranslateDefault("command-help:rank")
+}
+
+// CommandHelpOrder: key "command-help:order"
+func CommandHelpOrder() string {
+	return engine.TranslateDefault("command-help:order")
+}
+
+// CommandHelpClear: key "command-help:clear"
+func CommandHelpClear() string {
+	return engine.TranslateDefault("command-help:clear")
+}
+
+// CommandHelpHistory: key "command-help:history"
+func CommandHelpHistory() string {
+	return engine.TranslateDefault("command-help:history")
+}
+
+// CommandHelpHelp: key "command-help:help"
+func CommandHelpHelp() string {
+	return engine.TranslateDefault("command-help:help")
+}
+
+// CommandHelpUndo: key "command-help:undo"
+func CommandHelpUndo() string {
+	return engine.TranslateDefault("command-help:undo")
+}
+
+// CommandHelpStreak: key "command-help:streak"
+func CommandHelpStreak() string {
+	return engine.TranslateDefault("command-help:streak")
+}
+
+// CommandHelpReserve: key "command-help:reserve"
+func CommandHelpReserve() string {
+	return engine.TranslateDefault("command-help:reserve")
+}
+
+// CommandHelpQuota: key "command-help:quota"
+func CommandHelpQuota() string {
+	return engine.TranslateDefault("command-help:quota")
+}
+
+// CommandHelpTimeout: key "command-help:timeout"
+func CommandHelpTimeout() string {
+	return engine.TranslateDefault("command-help:timeout")
+}
+
+// CommandHelpOptionWork: key "command-help:option-work"
+func CommandHelpOptionWork() string {
+	return engine.TranslateDefault("command-help:option-work")
+}
+
+// CommandHelpOptionMin: key "command-help:option-min"
+func CommandHelpOptionMin() string {
+	return engine.TranslateDefault("command-help:option-min")
+}
+
+// CommandHelpOptionOrder: key "command-help:option-order"
+func CommandHelpOptionOrder() string {
+	return engine.TranslateDefault("command-help:option-order")
+}
+
+// CommandHelpOptionPomo: key "command-help:option-pomo"
+func CommandHelpOptionPomo() string {
+	return engine.TranslateDefault("command-help:option-pomo")
+}
+
+// CommandHelpOptionColor: key "command-help:option-color"
+func CommandHelpOptionColor() string {
+	return engine.TranslateDefault("command-help:option-color")
+}
+
+// CommandHelpOptionGoal: key "command-help:option-goal"
+func CommandHelpOptionGoal() string {
+	return engine.TranslateDefault("command-help:option-goal")
+}
+
+// CommandHelpOptionRank: key "command-help:option-rank"
+func CommandHelpOptionRank() string {
+	return engine.TranslateDefault("command-help:option-rank")
+}
+
 // OthersForceMove: key "others:force-move"
 func OthersForceMove(username string, seat string) string {
 	return engine.TranslateDefault("others:force-move", username, seat)
 }
 
+// OthersReservedSeatMove: key "others:reserved-seat-move"
+func OthersReservedSeatMove(username string, seat string) string {
+	return engine.TranslateDefault("others:reserved-seat-move", username, seat)
+}
+
 // OthersClearWork: key "others:clear-work"
 func OthersClearWork(username string, seat string) string {
 	return engine.TranslateDefault("others:clear-work", username, seat)
@@ -386,6 +701,46 @@ func OthersClearBreak(username string, seat string) string {
 	return engine.TranslateDefault("others:clear-break", username, seat)
 }
 
+// OthersDailyGoalAchieved: key "others:daily-goal-achieved"
+func OthersDailyGoalAchieved(username string, goalMin int) string {
+	return engine.TranslateDefault("others:daily-goal-achieved", username, goalMin)
+}
+
+// FanFundingSuperChat: key "fan-funding:super-chat"
+func FanFundingSuperChat(amount string) string {
+	return engine.TranslateDefault("fan-funding:super-chat", amount)
+}
+
+// FanFundingSuperSticker: key "fan-funding:super-sticker"
+func FanFundingSuperSticker(amount string) string {
+	return engine.TranslateDefault("fan-funding:super-sticker", amount)
+}
+
+// FanFundingNewMembership: key "fan-funding:new-membership"
+func FanFundingNewMembership() string {
+	return engine.TranslateDefault("fan-funding:new-membership")
+}
+
+// FanFundingMembershipGift: key "fan-funding:membership-gift"
+func FanFundingMembershipGift(count int) string {
+	return engine.TranslateDefault("fan-funding:membership-gift", count)
+}
+
+// FanFundingRewardRp: key "fan-funding:reward-rp"
+func FanFundingRewardRp(rp int) string {
+	return engine.TranslateDefault("fan-funding:reward-rp", rp)
+}
+
+// FanFundingRewardExtend: key "fan-funding:reward-extend"
+func FanFundingRewardExtend(extendedMin int) string {
+	return engine.TranslateDefault("fan-funding:reward-extend", extendedMin)
+}
+
+// FanFundingRewardAppearance: key "fan-funding:reward-appearance"
+func FanFundingRewardAppearance() string {
+	return engine.TranslateDefault("fan-funding:reward-appearance")
+}
+
 // ParseInvalidSeatId: key "parse:invalid-seat-id"
 func ParseInvalidSeatId() string {
 	return engine.TranslateDefault("parse:invalid-seat-id")
@@ -426,6 +781,21 @@ func ParseMissingTimeOption(timeOptionPrefix string) string {
 	return engine.TranslateDefault("parse:missing-time-option", timeOptionPrefix)
 }
 
+// ParseMemberOnlyCommand: key "parse:member-only-command"
+func ParseMemberOnlyCommand(command string) string {
+	return engine.TranslateDefault("parse:member-only-command", command)
+}
+
+// ParseInvalidReserveTime: key "parse:invalid-reserve-time"
+func ParseInvalidReserveTime() string {
+	return engine.TranslateDefault("parse:invalid-reserve-time")
+}
+
+// ParseMissingTimeoutDuration: key "parse:missing-timeout-duration"
+func ParseMissingTimeoutDuration() string {
+	return engine.TranslateDefault("parse:missing-timeout-duration")
+}
+
 // ValidateInvalidWorkTimeRange: key "validate:invalid-work-time-range"
 func ValidateInvalidWorkTimeRange(minMin int, maxMin int) string {
 	return engine.TranslateDefault("validate:invalid-work-time-range", minMin, maxMin)
@@ -461,7 +831,22 @@ func ValidateNonOneOrMoreExtendedTime() string {
 	return engine.TranslateDefault("validate:non-one-or-more-extended-time")
 }
 
+// ValidateInvalidDailyGoalRange: key "validate:invalid-daily-goal-range"
+func ValidateInvalidDailyGoalRange(maxMin int) string {
+	return engine.TranslateDefault("validate:invalid-daily-goal-range", maxMin)
+}
+
+// ValidateInvalidHistoryCount: key "validate:invalid-history-count"
+func ValidateInvalidHistoryCount(maxCount int) string {
+	return engine.TranslateDefault("validate:invalid-history-count", maxCount)
+}
+
 // ValidateInvalidMenuNumberRange: key "validate:invalid-menu-number-range"
 func ValidateInvalidMenuNumberRange(maxMenuNumber int) string {
 	return engine.TranslateDefault("validate:invalid-menu-number-range", maxMenuNumber)
 }
+
+// ValidateInvalidTimeoutRange: key "validate:invalid-timeout-range"
+func ValidateInvalidTimeoutRange(maxMin int) string {
+	return engine.TranslateDefault("validate:invalid-timeout-range", maxMin)
+}
@@ -0,0 +1,255 @@
+// Package migrations はFirestoreのドキュメントのスキーマ移行（フィールドの追加・補完など）をバージョン管理して適用する。
+// 適用済みのバージョンと実行中の進捗はconfig/migrationsに記録され、中断しても続きから再開できる。
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"app.modules/core/repository"
+)
+
+// Migration 1つのスキーマ移行。
+type Migration struct {
+	Version     int // 1以上で一意。小さい順に適用される
+	Description string
+	Collections []string // 対象のコレクション。この順に処理される
+
+	// Migrate はドキュメントのデータから、書き込むフィールドと値を返す。変更が不要なら空を返す。
+	// 中断後の再実行で同じドキュメントを再び処理することがあるため、適用済みのドキュメントには空を返すこと。
+	Migrate func(data map[string]any) (map[string]any, error)
+}
+
+// state config/migrationsのドキュメント
+type state struct {
+	AppliedVersions []int `firestore:"applied-versions"`
+
+	// 実行中のマイグレーションの進捗。処理済みの最後のドキュメントIDの次から再開する
+	InProgressVersion    int    `firestore:"in-progress-version"`
+	InProgressCollection string `firestore:"in-progress-collection"`
+	InProgressLastDocID  string `firestore:"in-progress-last-doc-id"`
+}
+
+type Runner struct {
+	client     *firestore.Client
+	migrations []Migration
+	batchSize  int // 1トランザクションで処理するドキュメント数。進捗の書き込み分を空けておく
+	out        io.Writer
+}
+
+func NewRunner(client *firestore.Client, migrations []Migration, out io.Writer) (*Runner, error) {
+	sorted := slices.Clone(migrations)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+	for i, m := range sorted {
+		if m.Version < 1 {
+			return nil, fmt.Errorf("invalid migration version: %d", m.Version)
+		}
+		if i > 0 && sorted[i-1].Version == m.Version {
+			return nil, fmt.Errorf("duplicate migration version: %d", m.Version)
+		}
+		if len(m.Collections) == 0 || m.Migrate == nil {
+			return nil, fmt.Errorf("migration %d must have collections and a migrate function", m.Version)
+		}
+	}
+	return &Runner{
+		client:     client,
+		migrations: sorted,
+		batchSize:  repository.FirestoreWritesLimitPerRequest - 1,
+		out:        out,
+	}, nil
+}
+
+func (r *Runner) stateRef() *firestore.DocumentRef {
+	return r.client.Collection(repository.CONFIG).Doc(repository.MigrationsConfigDocName)
+}
+
+func (r *Runner) readState(ctx context.Context) (state, error) {
+	doc, err := r.stateRef().Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return state{}, nil
+	}
+	if err != nil {
+		return state{}, fmt.Errorf("get migration state: %w", err)
+	}
+	var s state
+	if err := doc.DataTo(&s); err != nil {
+		return state{}, fmt.Errorf("in doc.DataTo: %w", err)
+	}
+	return s, nil
+}
+
+// Pending 未適用のマイグレーションをバージョン順に返す。
+func (r *Runner) Pending(ctx context.Context) ([]Migration, error) {
+	s, err := r.readState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, m := range r.migrations {
+		if !slices.Contains(s.AppliedVersions, m.Version) {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+// Run 未適用のマイグレーションをバージョン順に適用する。dryRunなら書き込まずに、変更されるドキュメント数を出力する。
+func (r *Runner) Run(ctx context.Context, dryRun bool) error {
+	s, err := r.readState(ctx)
+	if err != nil {
+		return err
+	}
+	for _, m := range r.migrations {
+		if slices.Contains(s.AppliedVersions, m.Version) {
+			continue
+		}
+		if dryRun {
+			if err := r.dryRun(ctx, m); err != nil {
+				return fmt.Errorf("dry run migration %d: %w", m.Version, err)
+			}
+			continue
+		}
+		if err := r.apply(ctx, m, s); err != nil {
+			return fmt.Errorf("apply migration %d: %w", m.Version, err)
+		}
+	}
+	return nil
+}
+
+func (r *Runner) dryRun(ctx context.Context, m Migration) error {
+	for _, collection := range m.Collections {
+		scanned, changed := 0, 0
+		iter := r.client.Collection(collection).OrderBy(firestore.DocumentID, firestore.Asc).Documents(ctx)
+		for {
+			doc, err := iter.Next()
+			if errors.Is(err, iterator.Done) {
+				break
+			}
+			if err != nil {
+				iter.Stop()
+				return fmt.Errorf("in iter.Next(): %w", err)
+			}
+			updates, err := m.Migrate(doc.Data())
+			if err != nil {
+				iter.Stop()
+				return fmt.Errorf("migrate %s: %w", doc.Ref.Path, err)
+			}
+			scanned++
+			if len(updates) > 0 {
+				changed++
+			}
+		}
+		iter.Stop()
+		_, _ = fmt.Fprintf(r.out, "[dry-run] v%d %s: %s: %d/%d documents would change\n", m.Version, m.Description, collection, changed, scanned)
+	}
+	return nil
+}
+
+func (r *Runner) apply(ctx context.Context, m Migration, s state) error {
+	startIndex := 0
+	if s.InProgressVersion == m.Version {
+		if i := slices.Index(m.Collections, s.InProgressCollection); i >= 0 {
+			startIndex = i
+		}
+	}
+	for i := startIndex; i < len(m.Collections); i++ {
+		collection := m.Collections[i]
+		lastDocID := ""
+		if s.InProgressVersion == m.Version && s.InProgressCollection == collection {
+			lastDocID = s.InProgressLastDocID
+			_, _ = fmt.Fprintf(r.out, "v%d: %s: resuming after %q\n", m.Version, collection, lastDocID)
+		}
+		if err := r.applyCollection(ctx, m, collection, lastDocID); err != nil {
+			return err
+		}
+	}
+
+	_, err := r.stateRef().Set(ctx, map[string]any{
+		"applied-versions":        firestore.ArrayUnion(m.Version),
+		"in-progress-version":     0,
+		"in-progress-collection":  "",
+		"in-progress-last-doc-id": "",
+	}, firestore.MergeAll)
+	if err != nil {
+		return fmt.Errorf("record applied version: %w", err)
+	}
+	_, _ = fmt.Fprintf(r.out, "v%d %s: applied\n", m.Version, m.Description)
+	return nil
+}
+
+// applyCollection はドキュメントID順にbatchSize件ずつ、書き込みと進捗の記録を同じトランザクションで行う。
+func (r *Runner) applyCollection(ctx context.Context, m Migration, collection string, lastDocID string) error {
+	scanned, changed := 0, 0
+	for {
+		var batchScanned, batchChanged int
+		var batchLastDocID string
+		err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+			batchScanned, batchChanged, batchLastDocID = 0, 0, lastDocID
+
+			query := r.client.Collection(collection).OrderBy(firestore.DocumentID, firestore.Asc).Limit(r.batchSize)
+			if lastDocID != "" {
+				query = query.StartAfter(lastDocID)
+			}
+			docs, err := tx.Documents(query).GetAll()
+			if err != nil {
+				return fmt.Errorf("get documents: %w", err)
+			}
+			for _, doc := range docs {
+				updates, err := m.Migrate(doc.Data())
+				if err != nil {
+					return fmt.Errorf("migrate %s: %w", doc.Ref.Path, err)
+				}
+				if len(updates) > 0 {
+					if err := tx.Update(doc.Ref, toFirestoreUpdates(updates)); err != nil {
+						return fmt.Errorf("update %s: %w", doc.Ref.Path, err)
+					}
+					batchChanged++
+				}
+				batchScanned++
+				batchLastDocID = doc.Ref.ID
+			}
+			if batchScanned == 0 {
+				return nil
+			}
+			return tx.Set(r.stateRef(), map[string]any{
+				"in-progress-version":     m.Version,
+				"in-progress-collection":  collection,
+				"in-progress-last-doc-id": batchLastDocID,
+			}, firestore.MergeAll)
+		})
+		if err != nil {
+			return fmt.Errorf("migrate %s after %q: %w", collection, lastDocID, err)
+		}
+		scanned += batchScanned
+		changed += batchChanged
+		lastDocID = batchLastDocID
+		if batchScanned > 0 {
+			_, _ = fmt.Fprintf(r.out, "v%d: %s: %d documents changed so far (%d scanned)\n", m.Version, collection, changed, scanned)
+		}
+		if batchScanned < r.batchSize {
+			return nil
+		}
+	}
+}
+
+func toFirestoreUpdates(updates map[string]any) []firestore.Update {
+	paths := make([]string, 0, len(updates))
+	for path := range updates {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	result := make([]firestore.Update, 0, len(paths))
+	for _, path := range paths {
+		result = append(result, firestore.Update{FieldPath: firestore.FieldPath{path}, Value: updates[path]})
+	}
+	return result
+}
@@ -0,0 +1,82 @@
+//go:build integration
+
+package migrations
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app.modules/core/repository"
+	"app.modules/internal/integrationtest"
+)
+
+func TestRunner_DryRunApplyAndResume(t *testing.T) {
+	integrationtest.ResetFirestore(t)
+	client := integrationtest.NewFirestoreController(t).FirestoreClient()
+	ctx := context.Background()
+
+	for i := 1; i <= 5; i++ {
+		data := map[string]any{"name": fmt.Sprintf("user%d", i)}
+		if i == 3 {
+			data["migrated"] = true
+		}
+		_, err := client.Collection("migration-test").Doc(fmt.Sprintf("doc%d", i)).Set(ctx, data)
+		require.NoError(t, err)
+	}
+	migrated := 0
+	migration := Migration{
+		Version:     1,
+		Description: "mark migrated",
+		Collections: []string{"migration-test"},
+		Migrate: func(data map[string]any) (map[string]any, error) {
+			if _, ok := data["migrated"]; ok {
+				return nil, nil
+			}
+			migrated++
+			return map[string]any{"migrated": true}, nil
+		},
+	}
+
+	out := &bytes.Buffer{}
+	runner, err := NewRunner(client, []Migration{migration}, out)
+	require.NoError(t, err)
+	runner.batchSize = 2
+
+	// dry-runでは書き込まない
+	require.NoError(t, runner.Run(ctx, true))
+	assert.Contains(t, out.String(), "4/5 documents would change")
+	pending, err := runner.Pending(ctx)
+	require.NoError(t, err)
+	assert.Len(t, pending, 1)
+
+	// doc2まで処理済みの状態から再開する
+	_, err = client.Collection(repository.CONFIG).Doc(repository.MigrationsConfigDocName).Set(ctx, map[string]any{
+		"in-progress-version":     1,
+		"in-progress-collection":  "migration-test",
+		"in-progress-last-doc-id": "doc2",
+	})
+	require.NoError(t, err)
+	migrated = 0
+	require.NoError(t, runner.Run(ctx, false))
+	assert.Equal(t, 2, migrated)
+	for i, want := range []bool{false, false, true, true, true} {
+		doc, err := client.Collection("migration-test").Doc(fmt.Sprintf("doc%d", i+1)).Get(ctx)
+		require.NoError(t, err)
+		_, ok := doc.Data()["migrated"]
+		assert.Equal(t, want, ok, "doc%d", i+1)
+	}
+
+	pending, err = runner.Pending(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+
+	// 適用済みのバージョンは再実行しない
+	migrated = 0
+	require.NoError(t, runner.Run(ctx, false))
+	assert.Equal(t, 0, migrated)
+}
@@ -0,0 +1,35 @@
+package migrations
+
+import (
+	"time"
+
+	"app.modules/core/repository"
+	"app.modules/core/utils"
+)
+
+// All 適用するマイグレーションの一覧。追加したものは変更・削除しないこと。
+func All() []Migration {
+	return []Migration{
+		{
+			Version:     1,
+			Description: "backfill seat session-id and current-segment-started-at",
+			Collections: []string{repository.SEATS, repository.MemberSeats},
+			Migrate:     backfillSeatSessionFields,
+		},
+	}
+}
+
+// backfillSeatSessionFields セッションの導入前に入室した座席に、セッションIDと現在のセグメントの開始時刻を補う。
+// セグメントの開始時刻は現在の状態の開始時刻とする。
+func backfillSeatSessionFields(data map[string]any) (map[string]any, error) {
+	updates := make(map[string]any)
+	if sessionID, _ := data[repository.SessionIDDocProperty].(string); sessionID == "" {
+		updates[repository.SessionIDDocProperty] = utils.GenerateSessionID()
+	}
+	if segmentStartedAt, _ := data[repository.CurrentSegmentStartedAtDocProperty].(time.Time); segmentStartedAt.IsZero() {
+		if stateStartedAt, ok := data[repository.CurrentStateStartedAtDocProperty].(time.Time); ok && !stateStartedAt.IsZero() {
+			updates[repository.CurrentSegmentStartedAtDocProperty] = stateStartedAt
+		}
+	}
+	return updates, nil
+}
@@ -0,0 +1,67 @@
+package migrations
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app.modules/core/repository"
+)
+
+func TestAll_IsValid(t *testing.T) {
+	_, err := NewRunner(nil, All(), &bytes.Buffer{})
+	require.NoError(t, err)
+}
+
+func TestNewRunner_RejectsInvalidVersions(t *testing.T) {
+	noop := func(map[string]any) (map[string]any, error) { return nil, nil }
+	tests := []struct {
+		name       string
+		migrations []Migration
+	}{
+		{
+			name: "duplicate_version",
+			migrations: []Migration{
+				{Version: 1, Collections: []string{"users"}, Migrate: noop},
+				{Version: 1, Collections: []string{"seats"}, Migrate: noop},
+			},
+		},
+		{
+			name:       "zero_version",
+			migrations: []Migration{{Version: 0, Collections: []string{"users"}, Migrate: noop}},
+		},
+		{
+			name:       "no_collections",
+			migrations: []Migration{{Version: 1, Migrate: noop}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRunner(nil, tt.migrations, &bytes.Buffer{})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestBackfillSeatSessionFields(t *testing.T) {
+	stateStartedAt := time.Date(2026, 8, 2, 9, 0, 0, 0, time.UTC)
+
+	updates, err := backfillSeatSessionFields(map[string]any{
+		repository.CurrentStateStartedAtDocProperty: stateStartedAt,
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, updates[repository.SessionIDDocProperty])
+	assert.Equal(t, stateStartedAt, updates[repository.CurrentSegmentStartedAtDocProperty])
+
+	// 適用済みなら何もしない
+	updates, err = backfillSeatSessionFields(map[string]any{
+		repository.SessionIDDocProperty:               "session",
+		repository.CurrentStateStartedAtDocProperty:   stateStartedAt,
+		repository.CurrentSegmentStartedAtDocProperty: stateStartedAt.Add(time.Minute),
+	})
+	require.NoError(t, err)
+	assert.Empty(t, updates)
+}
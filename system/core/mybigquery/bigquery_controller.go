@@ -116,6 +116,11 @@ func (c *BigqueryController) ReadCollectionsFromGcs(ctx context.Context,
 				TemporaryTableName + "` WHERE FORMAT_TIMESTAMP('%F %T', ordered_at, '+09:00') " +
 				"BETWEEN '" + yesterdayStart.Format("2006-01-02 15:04:05") + "' AND '" +
 				yesterdayEnd.Format("2006-01-02 15:04:05") + "'")
+		case repository.ModerationActions:
+			query = c.Client.Query("SELECT * FROM `" + c.Client.Project() + "." + DatasetName + "." +
+				TemporaryTableName + "` WHERE FORMAT_TIMESTAMP('%F %T', taken_at, '+09:00') " +
+				"BETWEEN '" + yesterdayStart.Format("2006-01-02 15:04:05") + "' AND '" +
+				yesterdayEnd.Format("2006-01-02 15:04:05") + "'")
 		}
 		query.Location = c.WorkingRegion
 		query.WriteDisposition = bigquery.WriteAppend // 追加
@@ -126,6 +131,8 @@ func (c *BigqueryController) ReadCollectionsFromGcs(ctx context.Context,
 			query.Dst = dataset.Table(UserActivityHistoryMainTableName)
 		case repository.OrderHistory:
 			query.Dst = dataset.Table(OrderHistoryMainTableName)
+		case repository.ModerationActions:
+			query.Dst = dataset.Table(ModerationActionMainTableName)
 		}
 		job, err = query.Run(ctx)
 		if err != nil {
@@ -6,4 +6,5 @@ const (
 	LiveChatHistoryMainTableName     = "live-chat-history"
 	UserActivityHistoryMainTableName = "user-activity-history"
 	OrderHistoryMainTableName        = "order-history"
+	ModerationActionMainTableName    = "moderation-actions"
 )
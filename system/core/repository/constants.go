@@ -8,6 +8,10 @@ const ( // TODO: ~~CollectionName, DocProperty~~に変更
 	LiveChatHistory           = "live-chat-history"
 	UserActivities            = "user-activities"
 	WorkSegments              = "work-segments"
+	DailyUserWorkHistory      = "daily-user-work-history"
+	UndoableExits             = "undoable-exits"
+	SeatReservations          = "seat-reservations"
+	MemberSeatReservations    = "member-seat-reservations"
 	MENU                      = "menu"
 	OrderHistory              = "order-history"
 	SeatLimitsBlackList       = "seat-limits-black-list"
@@ -15,10 +19,15 @@ const ( // TODO: ~~CollectionName, DocProperty~~に変更
 	MemberSeatLimitsBlackList = "member-seat-limits-black-list"
 	MemberSeatLimitsWhiteList = "member-seat-limits-white-list"
 	WorkNameTrend             = "work-name-trend"
+	ModerationActions         = "moderation-actions"
+	YoutubeAPIQuotaUsage      = "youtube-api-quota-usage"
+	FanFundingHistory         = "fan-funding-history"
+	ProcessedLiveChatMessages = "processed-live-chat-messages"
 
 	CredentialsConfigDocName     = "credentials"
 	SystemConstantsConfigDocName = "constants"
 	WorkNameTrendDocName         = "work-name-trend"
+	MigrationsConfigDocName      = "migrations"
 
 	PublishedAtDocProperty = "published-at"
 	TakenAtDocProperty     = "taken-at"
@@ -30,13 +39,21 @@ const ( // TODO: ~~CollectionName, DocProperty~~に変更
 	RankingDocProperty  = "ranking"
 	RankedAtDocProperty = "ranked-at"
 
-	StateDocProperty             = "state"
-	CurrentStateUntilDocProperty = "current-state-until"
+	StateDocProperty                   = "state"
+	CurrentStateUntilDocProperty       = "current-state-until"
+	CurrentStateStartedAtDocProperty   = "current-state-started-at"
+	CurrentSegmentStartedAtDocProperty = "current-segment-started-at"
 
 	ActivityTypeDocProperty = "activity-type"
 
 	SessionIDDocProperty   = "session-id"
 	SegmentTypeDocProperty = "segment-type"
+	StartedAtDocProperty   = "started-at"
+	StartAtDocProperty     = "start-at"
+
+	DateDocProperty          = "date"
+	TotalBreakSecDocProperty = "total-break-sec"
+	TimezoneNameDocProperty  = "timezone-name"
 
 	DesiredMaxSeatsDocProperty                       = "desired-max-seats"
 	DesiredMemberMaxSeatsDocProperty                 = "desired-member-max-seats"
@@ -66,9 +83,19 @@ const ( // TODO: ~~CollectionName, DocProperty~~に変更
 	CurrentActivityStateStartedDocProperty = "current-activity-state-started"
 	LastPenaltyImposedDaysDocProperty      = "last-penalty-imposed-days"
 	IsMemberSeatDocProperty                = "is-member-seat"
+	DailyGoalMinDocProperty                = "daily-goal-min"
+	DailyGoalAchievedDocProperty           = "daily-goal-achieved"
+	BestStreakDaysDocProperty              = "best-streak-days"
+	SupporterAppearanceUntilDocProperty    = "supporter-appearance-until"
 
 	OrderedAtDocProperty = "ordered-at"
 	CodeDocProperty      = "code"
+	ExitedAtDocProperty  = "exited-at"
+
+	TargetUserIDDocProperty = "target-user-id"
+
+	UnitsDocProperty = "units"
+	CallsDocProperty = "calls"
 
 	FirestoreWritesLimitPerRequest = 500 // Firestoreの仕様として決まっている
 )
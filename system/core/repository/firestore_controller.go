@@ -4,12 +4,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"strconv"
 	"time"
 
 	"cloud.google.com/go/firestore"
 	"cloud.google.com/go/firestore/apiv1/firestorepb"
 
+	"app.modules/core/timeutil"
+
 	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
 	"google.golang.org/grpc/codes"
@@ -17,7 +20,7 @@ import (
 )
 
 type FirestoreControllerImplements struct {
-	firestoreClient DBClient
+	firestoreClient *firestore.Client
 }
 
 func NewFirestoreController(ctx context.Context, clientOption option.ClientOption) (*FirestoreControllerImplements, error) {
@@ -31,13 +34,68 @@ func NewFirestoreController(ctx context.Context, clientOption option.ClientOptio
 	}, nil
 }
 
-func (c *FirestoreControllerImplements) FirestoreClient() DBClient {
+// FirestoreClient Repositoryを介さずにドキュメントを直接用意したい場合（テストのシードなど）に使う。
+func (c *FirestoreControllerImplements) FirestoreClient() *firestore.Client {
 	return c.firestoreClient
 }
 
-func (c *FirestoreControllerImplements) get(ctx context.Context, tx *firestore.Transaction, ref *firestore.DocumentRef) (*firestore.DocumentSnapshot, error) {
-	if tx != nil {
-		doc, err := tx.Get(ref)
+// firestoreTransaction *firestore.TransactionをTransactionとして渡すためのラッパー
+type firestoreTransaction struct {
+	tx *firestore.Transaction
+}
+
+func (*firestoreTransaction) transaction() {}
+
+// firestoreTx txがnilなら（トランザクション外なら）nilを返す。
+func firestoreTx(tx Transaction) (*firestore.Transaction, error) {
+	if tx == nil {
+		return nil, nil
+	}
+	t, ok := tx.(*firestoreTransaction)
+	if !ok {
+		return nil, fmt.Errorf("unexpected transaction type: %T", tx)
+	}
+	return t.tx, nil
+}
+
+func (c *FirestoreControllerImplements) RunTransaction(ctx context.Context, f func(ctx context.Context, tx Transaction) error) error {
+	return c.firestoreClient.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		return f(ctx, &firestoreTransaction{tx: tx})
+	})
+}
+
+func (c *FirestoreControllerImplements) Close() error {
+	return c.firestoreClient.Close()
+}
+
+// firestoreDocumentIterator *firestore.DocumentIteratorをDocumentIteratorとして返すためのラッパー
+type firestoreDocumentIterator struct {
+	iter *firestore.DocumentIterator
+}
+
+func newFirestoreDocumentIterator(iter *firestore.DocumentIterator) DocumentIterator {
+	return &firestoreDocumentIterator{iter: iter}
+}
+
+func (it *firestoreDocumentIterator) Next() (DocumentRef, error) {
+	doc, err := it.iter.Next()
+	if err != nil {
+		return DocumentRef{}, err
+	}
+	return DocumentRef{Collection: doc.Ref.Parent.ID, ID: doc.Ref.ID}, nil
+}
+
+func (it *firestoreDocumentIterator) Stop() {
+	it.iter.Stop()
+}
+
+func (c *FirestoreControllerImplements) get(ctx context.Context, tx Transaction, ref *firestore.DocumentRef) (*firestore.DocumentSnapshot, error) {
+	fsTx, err := firestoreTx(tx)
+	if err != nil {
+		return nil, err
+	}
+	if fsTx != nil {
+		doc, err := fsTx.Get(ref)
 		if err != nil {
 			return nil, fmt.Errorf("get document in transaction: %w", err)
 		}
@@ -50,9 +108,25 @@ func (c *FirestoreControllerImplements) get(ctx context.Context, tx *firestore.T
 	return doc, nil
 }
 
-func (c *FirestoreControllerImplements) create(ctx context.Context, tx *firestore.Transaction, ref *firestore.DocumentRef, data interface{}) error {
-	if tx != nil {
-		if err := tx.Create(ref, data); err != nil {
+// documents はtxがnilならトランザクション外で、そうでなければtxの中でクエリを実行する。
+func (c *FirestoreControllerImplements) documents(ctx context.Context, tx Transaction, query firestore.Query) (*firestore.DocumentIterator, error) {
+	fsTx, err := firestoreTx(tx)
+	if err != nil {
+		return nil, err
+	}
+	if fsTx != nil {
+		return fsTx.Documents(query), nil
+	}
+	return query.Documents(ctx), nil
+}
+
+func (c *FirestoreControllerImplements) create(ctx context.Context, tx Transaction, ref *firestore.DocumentRef, data interface{}) error {
+	fsTx, err := firestoreTx(tx)
+	if err != nil {
+		return err
+	}
+	if fsTx != nil {
+		if err := fsTx.Create(ref, data); err != nil {
 			return fmt.Errorf("create document in transaction: %w", err)
 		}
 		return nil
@@ -63,9 +137,13 @@ func (c *FirestoreControllerImplements) create(ctx context.Context, tx *firestor
 	return nil
 }
 
-func (c *FirestoreControllerImplements) set(ctx context.Context, tx *firestore.Transaction, ref *firestore.DocumentRef, data interface{}, opts ...firestore.SetOption) error {
-	if tx != nil {
-		if err := tx.Set(ref, data, opts...); err != nil {
+func (c *FirestoreControllerImplements) set(ctx context.Context, tx Transaction, ref *firestore.DocumentRef, data interface{}, opts ...firestore.SetOption) error {
+	fsTx, err := firestoreTx(tx)
+	if err != nil {
+		return err
+	}
+	if fsTx != nil {
+		if err := fsTx.Set(ref, data, opts...); err != nil {
 			return fmt.Errorf("set document in transaction: %w", err)
 		}
 		return nil
@@ -76,7 +154,7 @@ func (c *FirestoreControllerImplements) set(ctx context.Context, tx *firestore.T
 	return nil
 }
 
-func (c *FirestoreControllerImplements) update(ctx context.Context, tx *firestore.Transaction, ref *firestore.DocumentRef, data []firestore.Update, opts ...firestore.Precondition) error {
+func (c *FirestoreControllerImplements) update(ctx context.Context, tx Transaction, ref *firestore.DocumentRef, data []firestore.Update, opts ...firestore.Precondition) error {
 	if tx != nil {
 		return updateInTransaction(tx, ref, data, opts...)
 	}
@@ -86,17 +164,28 @@ func (c *FirestoreControllerImplements) update(ctx context.Context, tx *firestor
 	return nil
 }
 
-func updateInTransaction(tx *firestore.Transaction, ref *firestore.DocumentRef, data []firestore.Update, opts ...firestore.Precondition) error {
-	if err := tx.Update(ref, data, opts...); err != nil {
+func updateInTransaction(tx Transaction, ref *firestore.DocumentRef, data []firestore.Update, opts ...firestore.Precondition) error {
+	fsTx, err := firestoreTx(tx)
+	if err != nil {
+		return err
+	}
+	if fsTx == nil {
+		return errors.New("transaction is required")
+	}
+	if err := fsTx.Update(ref, data, opts...); err != nil {
 		return fmt.Errorf("update document in transaction: %w", err)
 	}
 	return nil
 }
 
 // delete deletes the document. If the document doesn't exist, it does nothing and returns no error.
-func (c *FirestoreControllerImplements) delete(ctx context.Context, tx *firestore.Transaction, ref *firestore.DocumentRef, opts ...firestore.Precondition) error {
-	if tx != nil {
-		if err := tx.Delete(ref, opts...); err != nil {
+func (c *FirestoreControllerImplements) delete(ctx context.Context, tx Transaction, ref *firestore.DocumentRef, opts ...firestore.Precondition) error {
+	fsTx, err := firestoreTx(tx)
+	if err != nil {
+		return err
+	}
+	if fsTx != nil {
+		if err := fsTx.Delete(ref, opts...); err != nil {
 			return fmt.Errorf("delete document in transaction: %w", err)
 		}
 		return nil
@@ -131,6 +220,18 @@ func (c *FirestoreControllerImplements) orderHistoryCollection() *firestore.Coll
 	return c.firestoreClient.Collection(OrderHistory)
 }
 
+func (c *FirestoreControllerImplements) moderationActionsCollection() *firestore.CollectionRef {
+	return c.firestoreClient.Collection(ModerationActions)
+}
+
+func (c *FirestoreControllerImplements) fanFundingHistoryCollection() *firestore.CollectionRef {
+	return c.firestoreClient.Collection(FanFundingHistory)
+}
+
+func (c *FirestoreControllerImplements) processedLiveChatMessagesCollection() *firestore.CollectionRef {
+	return c.firestoreClient.Collection(ProcessedLiveChatMessages)
+}
+
 func (c *FirestoreControllerImplements) generalSeatsCollection() *firestore.CollectionRef {
 	return c.firestoreClient.Collection(SEATS)
 }
@@ -151,6 +252,21 @@ func (c *FirestoreControllerImplements) workSegmentsCollection() *firestore.Coll
 	return c.firestoreClient.Collection(WorkSegments)
 }
 
+func (c *FirestoreControllerImplements) dailyUserWorkHistoryCollection() *firestore.CollectionRef {
+	return c.firestoreClient.Collection(DailyUserWorkHistory)
+}
+
+func (c *FirestoreControllerImplements) undoableExitsCollection() *firestore.CollectionRef {
+	return c.firestoreClient.Collection(UndoableExits)
+}
+
+func (c *FirestoreControllerImplements) seatReservationsCollection(isMemberSeat bool) *firestore.CollectionRef {
+	if isMemberSeat {
+		return c.firestoreClient.Collection(MemberSeatReservations)
+	}
+	return c.firestoreClient.Collection(SeatReservations)
+}
+
 func (c *FirestoreControllerImplements) generalSeatLimitsBLACKListCollection() *firestore.CollectionRef {
 	return c.firestoreClient.Collection(SeatLimitsBlackList)
 }
@@ -171,13 +287,17 @@ func (c *FirestoreControllerImplements) workNameTrendCollection() *firestore.Col
 	return c.firestoreClient.Collection(WorkNameTrend)
 }
 
-func (c *FirestoreControllerImplements) DeleteDocRef(ctx context.Context, tx *firestore.Transaction,
-	ref *firestore.DocumentRef,
+func (c *FirestoreControllerImplements) youtubeAPIQuotaUsageCollection() *firestore.CollectionRef {
+	return c.firestoreClient.Collection(YoutubeAPIQuotaUsage)
+}
+
+func (c *FirestoreControllerImplements) DeleteDocRef(ctx context.Context, tx Transaction,
+	ref DocumentRef,
 ) error {
-	return c.delete(ctx, tx, ref)
+	return c.delete(ctx, tx, c.firestoreClient.Collection(ref.Collection).Doc(ref.ID))
 }
 
-func (c *FirestoreControllerImplements) ReadCredentialsConfig(ctx context.Context, tx *firestore.Transaction) (CredentialsConfigDoc, error) {
+func (c *FirestoreControllerImplements) ReadCredentialsConfig(ctx context.Context, tx Transaction) (CredentialsConfigDoc, error) {
 	ref := c.configCollection().Doc(CredentialsConfigDocName)
 	doc, err := c.get(ctx, tx, ref)
 	if err != nil {
@@ -190,7 +310,7 @@ func (c *FirestoreControllerImplements) ReadCredentialsConfig(ctx context.Contex
 	return credentialsData, nil
 }
 
-func (c *FirestoreControllerImplements) ReadSystemConstantsConfig(ctx context.Context, tx *firestore.Transaction) (ConstantsConfigDoc, error) {
+func (c *FirestoreControllerImplements) ReadSystemConstantsConfig(ctx context.Context, tx Transaction) (ConstantsConfigDoc, error) {
 	ref := c.configCollection().Doc(SystemConstantsConfigDocName)
 	doc, err := c.get(ctx, tx, ref)
 	if err != nil {
@@ -203,7 +323,7 @@ func (c *FirestoreControllerImplements) ReadSystemConstantsConfig(ctx context.Co
 	return constantsConfig, nil
 }
 
-func (c *FirestoreControllerImplements) ReadLiveChatID(ctx context.Context, tx *firestore.Transaction) (string, error) {
+func (c *FirestoreControllerImplements) ReadLiveChatID(ctx context.Context, tx Transaction) (string, error) {
 	credentialsDoc, err := c.ReadCredentialsConfig(ctx, tx)
 	if err != nil {
 		return "", fmt.Errorf("in ReadCredentialsConfig: %w", err)
@@ -211,7 +331,7 @@ func (c *FirestoreControllerImplements) ReadLiveChatID(ctx context.Context, tx *
 	return credentialsDoc.YoutubeLiveChatID, nil
 }
 
-func (c *FirestoreControllerImplements) ReadNextPageToken(ctx context.Context, tx *firestore.Transaction) (string, error) {
+func (c *FirestoreControllerImplements) ReadNextPageToken(ctx context.Context, tx Transaction) (string, error) {
 	credentialsDoc, err := c.ReadCredentialsConfig(ctx, tx)
 	if err != nil {
 		return "", fmt.Errorf("in ReadCredentialsConfig: %w", err)
@@ -250,7 +370,12 @@ func (c *FirestoreControllerImplements) ReadSeatsExpiredBreakUntil(ctx context.C
 	return getDocDataFromIterator[SeatDoc](iter)
 }
 
-func (c *FirestoreControllerImplements) ReadSeat(ctx context.Context, tx *firestore.Transaction, seatID int, isMemberSeat bool) (SeatDoc, error) {
+func (c *FirestoreControllerImplements) ReadSeatsExpiredWorkUntil(ctx context.Context, thresholdTime time.Time, isMemberSeat bool) ([]SeatDoc, error) {
+	iter := c.seatsCollection(isMemberSeat).Where(StateDocProperty, "==", WorkState).Where(CurrentStateUntilDocProperty, "<", thresholdTime).Documents(ctx)
+	return getDocDataFromIterator[SeatDoc](iter)
+}
+
+func (c *FirestoreControllerImplements) ReadSeat(ctx context.Context, tx Transaction, seatID int, isMemberSeat bool) (SeatDoc, error) {
 	ref := c.seatsCollection(isMemberSeat).Doc(strconv.Itoa(seatID))
 	doc, err := c.get(ctx, tx, ref)
 	if err != nil {
@@ -286,21 +411,21 @@ func (c *FirestoreControllerImplements) ReadActiveWorkNameSeats(ctx context.Cont
 	return getDocDataFromIterator[SeatDoc](iter)
 }
 
-func (c *FirestoreControllerImplements) UpdateUserLastEnteredDate(tx *firestore.Transaction, userID string, enteredDate time.Time) error {
+func (c *FirestoreControllerImplements) UpdateUserLastEnteredDate(tx Transaction, userID string, enteredDate time.Time) error {
 	ref := c.usersCollection().Doc(userID)
 	return updateInTransaction(tx, ref, []firestore.Update{
 		{Path: LastEnteredDocProperty, Value: enteredDate},
 	})
 }
 
-func (c *FirestoreControllerImplements) UpdateUserLastExitedDate(tx *firestore.Transaction, userID string, exitedDate time.Time) error {
+func (c *FirestoreControllerImplements) UpdateUserLastExitedDate(tx Transaction, userID string, exitedDate time.Time) error {
 	ref := c.usersCollection().Doc(userID)
 	return updateInTransaction(tx, ref, []firestore.Update{
 		{Path: LastExitedDocProperty, Value: exitedDate},
 	})
 }
 
-func (c *FirestoreControllerImplements) UpdateUserRankVisible(tx *firestore.Transaction, userID string,
+func (c *FirestoreControllerImplements) UpdateUserRankVisible(tx Transaction, userID string,
 	rankVisible bool,
 ) error {
 	ref := c.usersCollection().Doc(userID)
@@ -309,21 +434,37 @@ func (c *FirestoreControllerImplements) UpdateUserRankVisible(tx *firestore.Tran
 	})
 }
 
-func (c *FirestoreControllerImplements) UpdateUserDefaultStudyMin(tx *firestore.Transaction, userID string, defaultStudyMin int) error {
+func (c *FirestoreControllerImplements) UpdateUserDefaultStudyMin(tx Transaction, userID string, defaultStudyMin int) error {
 	ref := c.usersCollection().Doc(userID)
 	return updateInTransaction(tx, ref, []firestore.Update{
 		{Path: DefaultStudyMinDocProperty, Value: defaultStudyMin},
 	})
 }
 
-func (c *FirestoreControllerImplements) UpdateUserFavoriteColor(tx *firestore.Transaction, userID string, colorCode string) error {
+func (c *FirestoreControllerImplements) UpdateUserFavoriteColor(tx Transaction, userID string, colorCode string) error {
 	ref := c.usersCollection().Doc(userID)
 	return updateInTransaction(tx, ref, []firestore.Update{
 		{Path: FavoriteColorDocProperty, Value: colorCode},
 	})
 }
 
-func (c *FirestoreControllerImplements) ReadUser(ctx context.Context, tx *firestore.Transaction, userID string) (UserDoc, error) {
+// UpdateUserDailyGoalMin は1日の目標作業時間を更新する。目標が変わるので達成フラグもリセットする。
+func (c *FirestoreControllerImplements) UpdateUserDailyGoalMin(tx Transaction, userID string, dailyGoalMin int) error {
+	ref := c.usersCollection().Doc(userID)
+	return updateInTransaction(tx, ref, []firestore.Update{
+		{Path: DailyGoalMinDocProperty, Value: dailyGoalMin},
+		{Path: DailyGoalAchievedDocProperty, Value: false},
+	})
+}
+
+func (c *FirestoreControllerImplements) UpdateUserDailyGoalAchieved(tx Transaction, userID string, achieved bool) error {
+	ref := c.usersCollection().Doc(userID)
+	return updateInTransaction(tx, ref, []firestore.Update{
+		{Path: DailyGoalAchievedDocProperty, Value: achieved},
+	})
+}
+
+func (c *FirestoreControllerImplements) ReadUser(ctx context.Context, tx Transaction, userID string) (UserDoc, error) {
 	ref := c.usersCollection().Doc(userID)
 	doc, err := c.get(ctx, tx, ref)
 	if err != nil {
@@ -337,7 +478,7 @@ func (c *FirestoreControllerImplements) ReadUser(ctx context.Context, tx *firest
 }
 
 func (c *FirestoreControllerImplements) UpdateUserTotalTime(
-	tx *firestore.Transaction,
+	tx Transaction,
 	userID string,
 	newTotalTimeSec int,
 	newDailyTotalTimeSec int,
@@ -349,51 +490,83 @@ func (c *FirestoreControllerImplements) UpdateUserTotalTime(
 	})
 }
 
-func (c *FirestoreControllerImplements) UpdateUserRankPoint(tx *firestore.Transaction, userID string, rp int) error {
+func (c *FirestoreControllerImplements) UpdateUserSupporterAppearanceUntil(tx Transaction, userID string, until time.Time) error {
+	ref := c.usersCollection().Doc(userID)
+	return updateInTransaction(tx, ref, []firestore.Update{
+		{Path: SupporterAppearanceUntilDocProperty, Value: until},
+	})
+}
+
+func (c *FirestoreControllerImplements) UpdateUserRankPoint(tx Transaction, userID string, rp int) error {
 	ref := c.usersCollection().Doc(userID)
 	return updateInTransaction(tx, ref, []firestore.Update{
 		{Path: RankPointDocProperty, Value: rp},
 	})
 }
 
-func (c *FirestoreControllerImplements) UpdateUserLastRPProcessed(tx *firestore.Transaction, userID string, date time.Time) error {
+func (c *FirestoreControllerImplements) UpdateUserLastRPProcessed(tx Transaction, userID string, date time.Time) error {
 	ref := c.usersCollection().Doc(userID)
 	return updateInTransaction(tx, ref, []firestore.Update{
 		{Path: LastRPProcessedDocProperty, Value: date},
 	})
 }
 
-func (c *FirestoreControllerImplements) UpdateLiveChatID(ctx context.Context, tx *firestore.Transaction, liveChatID string) error {
+func (c *FirestoreControllerImplements) UpdateLiveChatID(ctx context.Context, tx Transaction, liveChatID string) error {
 	ref := c.configCollection().Doc(CredentialsConfigDocName)
 	return c.update(ctx, tx, ref, []firestore.Update{
 		{Path: LiveChatIDDocProperty, Value: liveChatID},
 	})
 }
 
-func (c *FirestoreControllerImplements) CreateUser(ctx context.Context, tx *firestore.Transaction, userID string, userData UserDoc) error {
+func (c *FirestoreControllerImplements) CreateUser(ctx context.Context, tx Transaction, userID string, userData UserDoc) error {
 	ref := c.usersCollection().Doc(userID)
 	return c.create(ctx, tx, ref, userData)
 }
 
-func (c *FirestoreControllerImplements) UpdateWorkNameTrend(ctx context.Context, tx *firestore.Transaction, workNameTrend WorkNameTrendDoc) error {
+func (c *FirestoreControllerImplements) UpdateWorkNameTrend(ctx context.Context, tx Transaction, workNameTrend WorkNameTrendDoc) error {
 	ref := c.workNameTrendCollection().Doc(WorkNameTrendDocName)
 	return c.set(ctx, tx, ref, workNameTrend)
 }
 
-func (c *FirestoreControllerImplements) GetAllUserDocRefs(ctx context.Context) ([]*firestore.DocumentRef, error) {
+func (c *FirestoreControllerImplements) GetAllUserDocRefs(ctx context.Context) ([]DocumentRef, error) {
 	refs, err := c.usersCollection().DocumentRefs(ctx).GetAll()
 	if err != nil {
 		return nil, fmt.Errorf("get all user document references: %w", err)
 	}
-	return refs, nil
+	docRefs := make([]DocumentRef, 0, len(refs))
+	for _, ref := range refs {
+		docRefs = append(docRefs, DocumentRef{Collection: USERS, ID: ref.ID})
+	}
+	return docRefs, nil
 }
 
-func (c *FirestoreControllerImplements) GetAllNonDailyZeroUserDocs(ctx context.Context) *firestore.DocumentIterator {
-	return c.usersCollection().Where(DailyTotalStudySecDocProperty, "!=", 0).Documents(ctx)
+// ReadUsersPage startAfterUserIDより後のユーザーをドキュメントID順に最大limit件取得。startAfterUserIDが空なら先頭から
+func (c *FirestoreControllerImplements) ReadUsersPage(ctx context.Context, startAfterUserID string, limit int) ([]UserDocWithID, error) {
+	query := c.usersCollection().OrderBy(firestore.DocumentID, firestore.Asc).Limit(limit)
+	if startAfterUserID != "" {
+		query = query.StartAfter(startAfterUserID)
+	}
+	docs, err := query.Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("get users after %q: %w", startAfterUserID, err)
+	}
+	users := make([]UserDocWithID, 0, len(docs))
+	for _, doc := range docs {
+		var user UserDoc
+		if err := doc.DataTo(&user); err != nil {
+			return nil, fmt.Errorf("in doc.DataTo: %w", err)
+		}
+		users = append(users, UserDocWithID{UserID: doc.Ref.ID, UserDoc: user})
+	}
+	return users, nil
+}
+
+func (c *FirestoreControllerImplements) GetAllNonDailyZeroUserDocs(ctx context.Context) DocumentIterator {
+	return newFirestoreDocumentIterator(c.usersCollection().Where(DailyTotalStudySecDocProperty, "!=", 0).Documents(ctx))
 }
 
-func (c *FirestoreControllerImplements) ResetDailyTotalStudyTime(ctx context.Context, userRef *firestore.DocumentRef) error {
-	_, err := userRef.Update(ctx, []firestore.Update{
+func (c *FirestoreControllerImplements) ResetDailyTotalStudyTime(ctx context.Context, userID string) error {
+	_, err := c.usersCollection().Doc(userID).Update(ctx, []firestore.Update{
 		{Path: DailyTotalStudySecDocProperty, Value: 0},
 	})
 	if err != nil {
@@ -402,6 +575,20 @@ func (c *FirestoreControllerImplements) ResetDailyTotalStudyTime(ctx context.Con
 	return nil
 }
 
+func (c *FirestoreControllerImplements) GetAllDailyGoalAchievedUserDocs(ctx context.Context) DocumentIterator {
+	return newFirestoreDocumentIterator(c.usersCollection().Where(DailyGoalAchievedDocProperty, "==", true).Documents(ctx))
+}
+
+func (c *FirestoreControllerImplements) ResetDailyGoalAchieved(ctx context.Context, userID string) error {
+	_, err := c.usersCollection().Doc(userID).Update(ctx, []firestore.Update{
+		{Path: DailyGoalAchievedDocProperty, Value: false},
+	})
+	if err != nil {
+		return fmt.Errorf("reset daily goal achieved: %w", err)
+	}
+	return nil
+}
+
 func (c *FirestoreControllerImplements) UpdateLastResetDailyTotalStudyTime(ctx context.Context, timestamp time.Time) error {
 	ref := c.configCollection().Doc(SystemConstantsConfigDocName)
 	_, err := ref.Update(ctx, []firestore.Update{
@@ -437,7 +624,7 @@ func (c *FirestoreControllerImplements) UpdateLastTransferCollectionHistoryBigqu
 	return nil
 }
 
-func (c *FirestoreControllerImplements) UpdateDesiredMaxSeats(ctx context.Context, tx *firestore.Transaction,
+func (c *FirestoreControllerImplements) UpdateDesiredMaxSeats(ctx context.Context, tx Transaction,
 	desiredMaxSeats int,
 ) error {
 	ref := c.configCollection().Doc(SystemConstantsConfigDocName)
@@ -446,7 +633,7 @@ func (c *FirestoreControllerImplements) UpdateDesiredMaxSeats(ctx context.Contex
 	})
 }
 
-func (c *FirestoreControllerImplements) UpdateDesiredMemberMaxSeats(ctx context.Context, tx *firestore.Transaction,
+func (c *FirestoreControllerImplements) UpdateDesiredMemberMaxSeats(ctx context.Context, tx Transaction,
 	desiredMemberMaxSeats int,
 ) error {
 	ref := c.configCollection().Doc(SystemConstantsConfigDocName)
@@ -455,21 +642,21 @@ func (c *FirestoreControllerImplements) UpdateDesiredMemberMaxSeats(ctx context.
 	})
 }
 
-func (c *FirestoreControllerImplements) UpdateMaxSeats(ctx context.Context, tx *firestore.Transaction, maxSeats int) error {
+func (c *FirestoreControllerImplements) UpdateMaxSeats(ctx context.Context, tx Transaction, maxSeats int) error {
 	ref := c.configCollection().Doc(SystemConstantsConfigDocName)
 	return c.update(ctx, tx, ref, []firestore.Update{
 		{Path: MaxSeatsDocProperty, Value: maxSeats},
 	})
 }
 
-func (c *FirestoreControllerImplements) UpdateMemberMaxSeats(ctx context.Context, tx *firestore.Transaction, memberMaxSeats int) error {
+func (c *FirestoreControllerImplements) UpdateMemberMaxSeats(ctx context.Context, tx Transaction, memberMaxSeats int) error {
 	ref := c.configCollection().Doc(SystemConstantsConfigDocName)
 	return c.update(ctx, tx, ref, []firestore.Update{
 		{Path: MemberMaxSeatsDocProperty, Value: memberMaxSeats},
 	})
 }
 
-func (c *FirestoreControllerImplements) UpdateAccessTokenOfChannelCredential(ctx context.Context, tx *firestore.Transaction, accessToken string, expireDate time.Time) error {
+func (c *FirestoreControllerImplements) UpdateAccessTokenOfChannelCredential(ctx context.Context, tx Transaction, accessToken string, expireDate time.Time) error {
 	ref := c.configCollection().Doc(CredentialsConfigDocName)
 	return c.update(ctx, tx, ref, []firestore.Update{
 		{Path: YoutubeChannelAccessTokenDocProperty, Value: accessToken},
@@ -477,7 +664,7 @@ func (c *FirestoreControllerImplements) UpdateAccessTokenOfChannelCredential(ctx
 	})
 }
 
-func (c *FirestoreControllerImplements) UpdateAccessTokenOfBotCredential(ctx context.Context, tx *firestore.Transaction, accessToken string, expireDate time.Time) error {
+func (c *FirestoreControllerImplements) UpdateAccessTokenOfBotCredential(ctx context.Context, tx Transaction, accessToken string, expireDate time.Time) error {
 	ref := c.configCollection().Doc(CredentialsConfigDocName)
 	return c.update(ctx, tx, ref, []firestore.Update{
 		{Path: YoutubeBotAccessTokenDocProperty, Value: accessToken},
@@ -485,25 +672,32 @@ func (c *FirestoreControllerImplements) UpdateAccessTokenOfBotCredential(ctx con
 	})
 }
 
-func (c *FirestoreControllerImplements) CreateSeat(tx *firestore.Transaction, seat SeatDoc, isMemberSeat bool) error {
+func (c *FirestoreControllerImplements) CreateSeat(tx Transaction, seat SeatDoc, isMemberSeat bool) error {
 	ref := c.seatsCollection(isMemberSeat).Doc(strconv.Itoa(seat.SeatID))
-	if err := tx.Create(ref, seat); err != nil {
+	fsTx, err := firestoreTx(tx)
+	if err != nil {
+		return err
+	}
+	if fsTx == nil {
+		return errors.New("transaction is required")
+	}
+	if err := fsTx.Create(ref, seat); err != nil {
 		return fmt.Errorf("create seat in transaction: %w", err)
 	}
 	return nil
 }
 
-func (c *FirestoreControllerImplements) UpdateSeat(ctx context.Context, tx *firestore.Transaction, seat SeatDoc, isMemberSeat bool) error {
+func (c *FirestoreControllerImplements) UpdateSeat(ctx context.Context, tx Transaction, seat SeatDoc, isMemberSeat bool) error {
 	ref := c.seatsCollection(isMemberSeat).Doc(strconv.Itoa(seat.SeatID))
 	return c.set(ctx, tx, ref, seat)
 }
 
-func (c *FirestoreControllerImplements) DeleteSeat(ctx context.Context, tx *firestore.Transaction, seatID int, isMemberSeat bool) error {
+func (c *FirestoreControllerImplements) DeleteSeat(ctx context.Context, tx Transaction, seatID int, isMemberSeat bool) error {
 	ref := c.seatsCollection(isMemberSeat).Doc(strconv.Itoa(seatID))
 	return c.delete(ctx, tx, ref)
 }
 
-func (c *FirestoreControllerImplements) CreateLiveChatHistoryDoc(ctx context.Context, tx *firestore.Transaction,
+func (c *FirestoreControllerImplements) CreateLiveChatHistoryDoc(ctx context.Context, tx Transaction,
 	liveChatHistoryDoc LiveChatHistoryDoc,
 ) error {
 	ref := c.liveChatHistoryCollection().NewDoc()
@@ -512,31 +706,31 @@ func (c *FirestoreControllerImplements) CreateLiveChatHistoryDoc(ctx context.Con
 
 func (c *FirestoreControllerImplements) Get500LiveChatHistoryDocIDsBeforeDate(ctx context.Context,
 	date time.Time,
-) *firestore.DocumentIterator {
-	return c.liveChatHistoryCollection().Where(PublishedAtDocProperty, "<",
-		date).Limit(FirestoreWritesLimitPerRequest).Documents(ctx)
+) DocumentIterator {
+	return newFirestoreDocumentIterator(c.liveChatHistoryCollection().Where(PublishedAtDocProperty, "<",
+		date).Limit(FirestoreWritesLimitPerRequest).Documents(ctx))
 }
 
-func (c *FirestoreControllerImplements) CreateUserActivityDoc(ctx context.Context, tx *firestore.Transaction, activity UserActivityDoc) error {
+func (c *FirestoreControllerImplements) CreateUserActivityDoc(ctx context.Context, tx Transaction, activity UserActivityDoc) error {
 	ref := c.userActivitiesCollection().NewDoc()
 	return c.create(ctx, tx, ref, activity)
 }
 
 func (c *FirestoreControllerImplements) Get500UserActivityDocIDsBeforeDate(ctx context.Context, date time.Time,
-) *firestore.DocumentIterator {
-	return c.userActivitiesCollection().Where(TakenAtDocProperty, "<",
-		date).Limit(FirestoreWritesLimitPerRequest).Documents(ctx)
+) DocumentIterator {
+	return newFirestoreDocumentIterator(c.userActivitiesCollection().Where(TakenAtDocProperty, "<",
+		date).Limit(FirestoreWritesLimitPerRequest).Documents(ctx))
 }
 
 func (c *FirestoreControllerImplements) Get500OrderHistoryDocIDsBeforeDate(ctx context.Context, date time.Time,
-) *firestore.DocumentIterator {
-	return c.orderHistoryCollection().Where(OrderedAtDocProperty, "<",
-		date).Limit(FirestoreWritesLimitPerRequest).Documents(ctx)
+) DocumentIterator {
+	return newFirestoreDocumentIterator(c.orderHistoryCollection().Where(OrderedAtDocProperty, "<",
+		date).Limit(FirestoreWritesLimitPerRequest).Documents(ctx))
 }
 
 func (c *FirestoreControllerImplements) GetAllUserActivityDocIDsAfterDate(ctx context.Context, date time.Time,
-) *firestore.DocumentIterator {
-	return c.userActivitiesCollection().Where(TakenAtDocProperty, ">=", date).Documents(ctx)
+) DocumentIterator {
+	return newFirestoreDocumentIterator(c.userActivitiesCollection().Where(TakenAtDocProperty, ">=", date).Documents(ctx))
 }
 
 func (c *FirestoreControllerImplements) GetAllUserActivityDocIDsAfterDateForUserAndSeat(ctx context.Context,
@@ -570,11 +764,11 @@ func (c *FirestoreControllerImplements) GetExitRoomUserActivityDocIDsAfterDateFo
 }
 
 // GetUsersActiveAfterDate date以後に入室したことのあるuserを全て取得
-func (c *FirestoreControllerImplements) GetUsersActiveAfterDate(ctx context.Context, date time.Time) *firestore.DocumentIterator {
-	return c.usersCollection().Where(LastEnteredDocProperty, ">=", date).Documents(ctx)
+func (c *FirestoreControllerImplements) GetUsersActiveAfterDate(ctx context.Context, date time.Time) DocumentIterator {
+	return newFirestoreDocumentIterator(c.usersCollection().Where(LastEnteredDocProperty, ">=", date).Documents(ctx))
 }
 
-func (c *FirestoreControllerImplements) CreateWorkSegmentDoc(ctx context.Context, tx *firestore.Transaction, workSegment WorkSegmentDoc) error {
+func (c *FirestoreControllerImplements) CreateWorkSegmentDoc(ctx context.Context, tx Transaction, workSegment WorkSegmentDoc) error {
 	ref := c.workSegmentsCollection().NewDoc()
 	return c.create(ctx, tx, ref, workSegment)
 }
@@ -588,8 +782,155 @@ func (c *FirestoreControllerImplements) ReadWorkStateSegmentsBySessionID(ctx con
 	return getDocDataFromIterator[WorkSegmentDoc](iter)
 }
 
+// ReadWorkSegmentsByUserIDAndTimeRange returns up to limit (0 for no limit) of the user's segments (both work and break)
+// started in [from, to), newest first.
+func (c *FirestoreControllerImplements) ReadWorkSegmentsByUserIDAndTimeRange(ctx context.Context, userID string, from time.Time, to time.Time, limit int) ([]WorkSegmentDoc, error) {
+	query := c.workSegmentsCollection().
+		Where(UserIDDocProperty, "==", userID).
+		Where(StartedAtDocProperty, ">=", from).
+		Where(StartedAtDocProperty, "<", to).
+		OrderBy(StartedAtDocProperty, firestore.Desc)
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	return getDocDataFromIterator[WorkSegmentDoc](query.Documents(ctx))
+}
+
+// ReadWorkSegmentsPageByTimeRange returns up to limit segments of all users started in [from, to), ordered by
+// started-at and document ID. Reading starts after startAfter, or from the beginning if startAfter.SegmentID is empty.
+func (c *FirestoreControllerImplements) ReadWorkSegmentsPageByTimeRange(ctx context.Context, from time.Time, to time.Time, startAfter WorkSegmentDocWithID, limit int) ([]WorkSegmentDocWithID, error) {
+	query := c.workSegmentsCollection().
+		Where(StartedAtDocProperty, ">=", from).
+		Where(StartedAtDocProperty, "<", to).
+		OrderBy(StartedAtDocProperty, firestore.Asc).
+		OrderBy(firestore.DocumentID, firestore.Asc).
+		Limit(limit)
+	if startAfter.SegmentID != "" {
+		query = query.StartAfter(startAfter.StartedAt, startAfter.SegmentID)
+	}
+	docs, err := query.Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("get work segments after %q: %w", startAfter.SegmentID, err)
+	}
+	segments := make([]WorkSegmentDocWithID, 0, len(docs))
+	for _, doc := range docs {
+		var segment WorkSegmentDoc
+		if err := doc.DataTo(&segment); err != nil {
+			return nil, fmt.Errorf("in doc.DataTo: %w", err)
+		}
+		segments = append(segments, WorkSegmentDocWithID{SegmentID: doc.Ref.ID, WorkSegmentDoc: segment})
+	}
+	return segments, nil
+}
+
+// dailyUserWorkHistoryRef ユーザー・JSTの日付ごとに1つのドキュメントとなるようにIDを決める。
+func (c *FirestoreControllerImplements) dailyUserWorkHistoryRef(userID string, date time.Time) *firestore.DocumentRef {
+	return c.dailyUserWorkHistoryCollection().Doc(userID + "_" + date.In(timeutil.JapanLocation()).Format("2006-01-02"))
+}
+
+func (c *FirestoreControllerImplements) ReadDailyUserWorkHistory(ctx context.Context, tx Transaction, userID string, date time.Time) (DailyUserWorkHistoryDoc, error) {
+	ref := c.dailyUserWorkHistoryRef(userID, date)
+	doc, err := c.get(ctx, tx, ref)
+	if err != nil {
+		return DailyUserWorkHistoryDoc{}, fmt.Errorf("get daily user work history: %w", err)
+	}
+	var history DailyUserWorkHistoryDoc
+	if err := doc.DataTo(&history); err != nil {
+		return DailyUserWorkHistoryDoc{}, fmt.Errorf("parse daily user work history: %w", err)
+	}
+	return history, nil
+}
+
+// AddDailyUserWorkHistory はその日のドキュメントに作業時間・休憩時間を加算する。ドキュメントがなければ作成する。
+func (c *FirestoreControllerImplements) AddDailyUserWorkHistory(ctx context.Context, tx Transaction, userID string, date time.Time, studySec int, breakSec int) error {
+	ref := c.dailyUserWorkHistoryRef(userID, date)
+	return c.set(ctx, tx, ref, map[string]interface{}{
+		UserIDDocProperty:        userID,
+		DateDocProperty:          timeutil.StartOfDayJST(date),
+		TotalStudySecDocProperty: firestore.Increment(studySec),
+		TotalBreakSecDocProperty: firestore.Increment(breakSec),
+		TimezoneNameDocProperty:  timeutil.JapanLocation().String(),
+	}, firestore.MergeAll)
+}
+
+// SetDailyUserWorkHistory はその日のドキュメントを上書きする。
+func (c *FirestoreControllerImplements) SetDailyUserWorkHistory(ctx context.Context, tx Transaction, history DailyUserWorkHistoryDoc) error {
+	ref := c.dailyUserWorkHistoryRef(history.UserID, history.Date)
+	return c.set(ctx, tx, ref, history)
+}
+
+func (c *FirestoreControllerImplements) ReadUndoableExit(ctx context.Context, tx Transaction, userID string) (UndoableExitDoc, error) {
+	ref := c.undoableExitsCollection().Doc(userID)
+	doc, err := c.get(ctx, tx, ref)
+	if err != nil {
+		return UndoableExitDoc{}, err
+	}
+	var undoableExit UndoableExitDoc
+	if err := doc.DataTo(&undoableExit); err != nil {
+		return UndoableExitDoc{}, fmt.Errorf("in doc.DataTo: %w", err)
+	}
+	return undoableExit, nil
+}
+
+// SetUndoableExit はユーザーの取り消し可能な退室を上書きする。
+func (c *FirestoreControllerImplements) SetUndoableExit(ctx context.Context, tx Transaction, undoableExit UndoableExitDoc) error {
+	ref := c.undoableExitsCollection().Doc(undoableExit.UserID)
+	return c.set(ctx, tx, ref, undoableExit)
+}
+
+func (c *FirestoreControllerImplements) DeleteUndoableExit(ctx context.Context, tx Transaction, userID string) error {
+	ref := c.undoableExitsCollection().Doc(userID)
+	return c.delete(ctx, tx, ref)
+}
+
+func (c *FirestoreControllerImplements) Get500UndoableExitDocIDsBeforeDate(ctx context.Context, date time.Time,
+) DocumentIterator {
+	return newFirestoreDocumentIterator(c.undoableExitsCollection().Where(ExitedAtDocProperty, "<",
+		date).Limit(FirestoreWritesLimitPerRequest).Documents(ctx))
+}
+
+func (c *FirestoreControllerImplements) ReadSeatReservationsWithUserID(ctx context.Context, tx Transaction, userID string, isMemberSeat bool) ([]SeatReservationDoc, error) {
+	iter, err := c.documents(ctx, tx, c.seatReservationsCollection(isMemberSeat).Where(UserIDDocProperty, "==", userID))
+	if err != nil {
+		return nil, err
+	}
+	return getDocDataFromIterator[SeatReservationDoc](iter)
+}
+
+func (c *FirestoreControllerImplements) ReadSeatReservationsWithSeatID(ctx context.Context, tx Transaction, seatID int, isMemberSeat bool) ([]SeatReservationDoc, error) {
+	iter, err := c.documents(ctx, tx, c.seatReservationsCollection(isMemberSeat).Where(SeatIDDocProperty, "==", seatID))
+	if err != nil {
+		return nil, err
+	}
+	return getDocDataFromIterator[SeatReservationDoc](iter)
+}
+
+// ReadSeatReservationsStartBefore returns up to limit reservations (no limit if 0) whose start time is before thresholdTime,
+// including ones already started, ordered by start time.
+func (c *FirestoreControllerImplements) ReadSeatReservationsStartBefore(ctx context.Context, thresholdTime time.Time, isMemberSeat bool, limit int) ([]SeatReservationDoc, error) {
+	query := c.seatReservationsCollection(isMemberSeat).
+		Where(StartAtDocProperty, "<", thresholdTime).
+		OrderBy(StartAtDocProperty, firestore.Asc)
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	return getDocDataFromIterator[SeatReservationDoc](query.Documents(ctx))
+}
+
+// CreateSeatReservation は予約を作成する。ReservationIDはここで採番する。
+func (c *FirestoreControllerImplements) CreateSeatReservation(ctx context.Context, tx Transaction, reservation SeatReservationDoc, isMemberSeat bool) error {
+	ref := c.seatReservationsCollection(isMemberSeat).NewDoc()
+	reservation.ReservationID = ref.ID
+	return c.create(ctx, tx, ref, reservation)
+}
+
+func (c *FirestoreControllerImplements) DeleteSeatReservation(ctx context.Context, tx Transaction, reservationID string, isMemberSeat bool) error {
+	ref := c.seatReservationsCollection(isMemberSeat).Doc(reservationID)
+	return c.delete(ctx, tx, ref)
+}
+
 func (c *FirestoreControllerImplements) UpdateUserIsContinuousActiveAndCurrentActivityStateStarted(
-	ctx context.Context, tx *firestore.Transaction, userID string, isContinuousActive bool, currentActivityStateStarted time.Time,
+	ctx context.Context, tx Transaction, userID string, isContinuousActive bool, currentActivityStateStarted time.Time,
 ) error {
 	ref := c.usersCollection().Doc(userID)
 	return c.update(ctx, tx, ref, []firestore.Update{
@@ -598,14 +939,21 @@ func (c *FirestoreControllerImplements) UpdateUserIsContinuousActiveAndCurrentAc
 	})
 }
 
-func (c *FirestoreControllerImplements) UpdateUserLastPenaltyImposedDays(ctx context.Context, tx *firestore.Transaction, userID string, lastPenaltyImposedDays int) error {
+func (c *FirestoreControllerImplements) UpdateUserLastPenaltyImposedDays(ctx context.Context, tx Transaction, userID string, lastPenaltyImposedDays int) error {
 	ref := c.usersCollection().Doc(userID)
 	return c.update(ctx, tx, ref, []firestore.Update{
 		{Path: LastPenaltyImposedDaysDocProperty, Value: lastPenaltyImposedDays},
 	})
 }
 
-func (c *FirestoreControllerImplements) UpdateUserRPAndLastPenaltyImposedDays(ctx context.Context, tx *firestore.Transaction, userID string,
+func (c *FirestoreControllerImplements) UpdateUserBestStreakDays(ctx context.Context, tx Transaction, userID string, bestStreakDays int) error {
+	ref := c.usersCollection().Doc(userID)
+	return c.update(ctx, tx, ref, []firestore.Update{
+		{Path: BestStreakDaysDocProperty, Value: bestStreakDays},
+	})
+}
+
+func (c *FirestoreControllerImplements) UpdateUserRPAndLastPenaltyImposedDays(ctx context.Context, tx Transaction, userID string,
 	newRP int, newLastPenaltyImposedDays int,
 ) error {
 	ref := c.usersCollection().Doc(userID)
@@ -668,25 +1016,25 @@ func (c *FirestoreControllerImplements) createSeatLimit(ctx context.Context, ref
 }
 
 // Get500SeatLimitsAfterUntilInWHITEList returns all seat limit docs whose `until` is after `thresholdTime`.
-func (c *FirestoreControllerImplements) Get500SeatLimitsAfterUntilInWHITEList(ctx context.Context, thresholdTime time.Time, isMemberSeat bool) *firestore.DocumentIterator {
+func (c *FirestoreControllerImplements) Get500SeatLimitsAfterUntilInWHITEList(ctx context.Context, thresholdTime time.Time, isMemberSeat bool) DocumentIterator {
 	var collection *firestore.CollectionRef
 	if isMemberSeat {
 		collection = c.memberSeatLimitsWHITEListCollection()
 	} else {
 		collection = c.generalSeatLimitsWHITEListCollection()
 	}
-	return collection.Where(UntilDocProperty, "<", thresholdTime).Limit(FirestoreWritesLimitPerRequest).Documents(ctx)
+	return newFirestoreDocumentIterator(collection.Where(UntilDocProperty, "<", thresholdTime).Limit(FirestoreWritesLimitPerRequest).Documents(ctx))
 }
 
 // Get500SeatLimitsAfterUntilInBLACKList returns all seat limit docs whose `until` is after `thresholdTime`.
-func (c *FirestoreControllerImplements) Get500SeatLimitsAfterUntilInBLACKList(ctx context.Context, thresholdTime time.Time, isMemberSeat bool) *firestore.DocumentIterator {
+func (c *FirestoreControllerImplements) Get500SeatLimitsAfterUntilInBLACKList(ctx context.Context, thresholdTime time.Time, isMemberSeat bool) DocumentIterator {
 	var collection *firestore.CollectionRef
 	if isMemberSeat {
 		collection = c.memberSeatLimitsBLACKListCollection()
 	} else {
 		collection = c.generalSeatLimitsBLACKListCollection()
 	}
-	return collection.Where(UntilDocProperty, "<", thresholdTime).Limit(FirestoreWritesLimitPerRequest).Documents(ctx)
+	return newFirestoreDocumentIterator(collection.Where(UntilDocProperty, "<", thresholdTime).Limit(FirestoreWritesLimitPerRequest).Documents(ctx))
 }
 
 func (c *FirestoreControllerImplements) DeleteSeatLimitInWHITEList(ctx context.Context, docID string, isMemberSeat bool) error {
@@ -742,11 +1090,79 @@ func (c *FirestoreControllerImplements) CountUserOrdersOfTheDay(ctx context.Cont
 	return countValue.GetIntegerValue(), nil
 }
 
-func (c *FirestoreControllerImplements) CreateOrderHistoryDoc(ctx context.Context, tx *firestore.Transaction, orderHistoryDoc OrderHistoryDoc) error {
+func (c *FirestoreControllerImplements) CreateOrderHistoryDoc(ctx context.Context, tx Transaction, orderHistoryDoc OrderHistoryDoc) error {
 	ref := c.orderHistoryCollection().NewDoc()
 	return c.create(ctx, tx, ref, orderHistoryDoc)
 }
 
+func (c *FirestoreControllerImplements) CreateModerationActionDoc(ctx context.Context, tx Transaction, action ModerationActionDoc) error {
+	ref := c.moderationActionsCollection().NewDoc()
+	return c.create(ctx, tx, ref, action)
+}
+
+// ReadModerationActionsByTargetUserID userIDのユーザーに対するモデレーションの記録を古い順に取得
+func (c *FirestoreControllerImplements) ReadModerationActionsByTargetUserID(ctx context.Context, userID string) ([]ModerationActionDoc, error) {
+	iter := c.moderationActionsCollection().Where(TargetUserIDDocProperty, "==", userID).
+		OrderBy(TakenAtDocProperty, firestore.Asc).Documents(ctx)
+	return getDocDataFromIterator[ModerationActionDoc](iter)
+}
+
+func (c *FirestoreControllerImplements) CreateFanFundingHistoryDoc(ctx context.Context, tx Transaction, messageID string, history FanFundingHistoryDoc) error {
+	ref := c.fanFundingHistoryCollection().Doc(messageID)
+	return c.create(ctx, tx, ref, history)
+}
+
+// ReadFanFundingHistoryByUserID userIDのユーザーの支援の記録を古い順に取得
+func (c *FirestoreControllerImplements) ReadFanFundingHistoryByUserID(ctx context.Context, userID string) ([]FanFundingHistoryDoc, error) {
+	iter := c.fanFundingHistoryCollection().Where(UserIDDocProperty, "==", userID).
+		OrderBy(PublishedAtDocProperty, firestore.Asc).Documents(ctx)
+	return getDocDataFromIterator[FanFundingHistoryDoc](iter)
+}
+
+func (c *FirestoreControllerImplements) ReadProcessedLiveChatMessage(ctx context.Context, tx Transaction, messageID string) (ProcessedLiveChatMessageDoc, error) {
+	doc, err := c.get(ctx, tx, c.processedLiveChatMessagesCollection().Doc(messageID))
+	if err != nil {
+		return ProcessedLiveChatMessageDoc{}, err
+	}
+	var processed ProcessedLiveChatMessageDoc
+	if err := doc.DataTo(&processed); err != nil {
+		return ProcessedLiveChatMessageDoc{}, fmt.Errorf("in doc.DataTo: %w", err)
+	}
+	return processed, nil
+}
+
+func (c *FirestoreControllerImplements) CreateProcessedLiveChatMessage(ctx context.Context, tx Transaction, messageID string, processed ProcessedLiveChatMessageDoc) error {
+	ref := c.processedLiveChatMessagesCollection().Doc(messageID)
+	return c.create(ctx, tx, ref, processed)
+}
+
+// AddYoutubeAPIQuotaUsage 複数のプロセスから同時に加算しても失われないように、firestore.Incrementで加算する
+func (c *FirestoreControllerImplements) AddYoutubeAPIQuotaUsage(ctx context.Context, date string, calls map[string]int, units int) error {
+	callIncrements := make(map[string]interface{}, len(calls))
+	for method, n := range calls {
+		callIncrements[method] = firestore.Increment(n)
+	}
+	ref := c.youtubeAPIQuotaUsageCollection().Doc(date)
+	return c.set(ctx, nil, ref, map[string]interface{}{
+		DateDocProperty:  date,
+		UnitsDocProperty: firestore.Increment(units),
+		CallsDocProperty: callIncrements,
+	}, firestore.MergeAll)
+}
+
+func (c *FirestoreControllerImplements) ReadYoutubeAPIQuotaUsage(ctx context.Context, date string) (YoutubeAPIQuotaUsageDoc, error) {
+	ref := c.youtubeAPIQuotaUsageCollection().Doc(date)
+	doc, err := c.get(ctx, nil, ref)
+	if err != nil {
+		return YoutubeAPIQuotaUsageDoc{}, err
+	}
+	var usage YoutubeAPIQuotaUsageDoc
+	if err := doc.DataTo(&usage); err != nil {
+		return YoutubeAPIQuotaUsageDoc{}, fmt.Errorf("in doc.DataTo: %w", err)
+	}
+	return usage, nil
+}
+
 func getDocDataFromIterator[T any](iter *firestore.DocumentIterator) ([]T, error) {
 	docs := make([]T, 0) // jsonになったときにnullとならないように。
 	for {
@@ -765,3 +1181,95 @@ func getDocDataFromIterator[T any](iter *firestore.DocumentIterator) ([]T, error
 	}
 	return docs, nil
 }
+
+// seatSnapshotRetryInterval 購読が切れてから再開するまでの待ち時間
+const seatSnapshotRetryInterval = 5 * time.Second
+
+// ListenSeatSnapshots 一般席・メンバー席・constantsのスナップショットリスナーを開始する。
+// 購読が切れた場合はキャッシュを無効にして再開を試み、ctxがキャンセルされると終了する。
+func (c *FirestoreControllerImplements) ListenSeatSnapshots(ctx context.Context, cache *SeatCache) error {
+	go c.listenSeats(ctx, cache, false)
+	go c.listenSeats(ctx, cache, true)
+	go c.listenConstants(ctx, func(constants *ConstantsConfigDoc) {
+		if constants == nil {
+			cache.InvalidateConstants()
+			return
+		}
+		cache.UpdateConstants(*constants, time.Now())
+	}, cache.InvalidateConstants)
+	return nil
+}
+
+func (c *FirestoreControllerImplements) listenSeats(ctx context.Context, cache *SeatCache, isMemberSeat bool) {
+	for {
+		err := c.consumeSeatSnapshots(ctx, cache, isMemberSeat)
+		cache.InvalidateSeats(isMemberSeat)
+		if ctx.Err() != nil {
+			return
+		}
+		slog.WarnContext(ctx, "seat snapshot listener stopped", "isMemberSeat", isMemberSeat, "error", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(seatSnapshotRetryInterval):
+		}
+	}
+}
+
+func (c *FirestoreControllerImplements) consumeSeatSnapshots(ctx context.Context, cache *SeatCache, isMemberSeat bool) error {
+	iter := c.seatsCollection(isMemberSeat).Snapshots(ctx)
+	defer iter.Stop()
+	for {
+		snapshot, err := iter.Next()
+		if err != nil {
+			return fmt.Errorf("in iter.Next(): %w", err)
+		}
+		seats, err := getDocDataFromIterator[SeatDoc](snapshot.Documents)
+		if err != nil {
+			return err
+		}
+		cache.UpdateSeats(isMemberSeat, seats, time.Now())
+	}
+}
+
+// listenConstants config/constantsのスナップショットをonSnapshotに渡し続ける。ドキュメントが存在しなければconstantsはnil。
+// 購読が切れるたびにonStopを呼ぶ。
+func (c *FirestoreControllerImplements) listenConstants(ctx context.Context,
+	onSnapshot func(constants *ConstantsConfigDoc), onStop func(),
+) {
+	for {
+		err := c.consumeConstantsSnapshots(ctx, onSnapshot)
+		onStop()
+		if ctx.Err() != nil {
+			return
+		}
+		slog.WarnContext(ctx, "constants snapshot listener stopped", "error", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(seatSnapshotRetryInterval):
+		}
+	}
+}
+
+func (c *FirestoreControllerImplements) consumeConstantsSnapshots(ctx context.Context,
+	onSnapshot func(constants *ConstantsConfigDoc),
+) error {
+	iter := c.configCollection().Doc(SystemConstantsConfigDocName).Snapshots(ctx)
+	defer iter.Stop()
+	for {
+		doc, err := iter.Next()
+		if err != nil {
+			return fmt.Errorf("in iter.Next(): %w", err)
+		}
+		if !doc.Exists() {
+			onSnapshot(nil)
+			continue
+		}
+		var constants ConstantsConfigDoc
+		if err := doc.DataTo(&constants); err != nil {
+			return fmt.Errorf("in doc.DataTo: %w", err)
+		}
+		onSnapshot(&constants)
+	}
+}
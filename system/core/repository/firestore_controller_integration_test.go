@@ -8,7 +8,6 @@ import (
 	"testing"
 	"time"
 
-	"cloud.google.com/go/firestore"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/codes"
@@ -17,6 +16,7 @@ import (
 	"app.modules/core/repository"
 	"app.modules/core/timeutil"
 	"app.modules/internal/integrationtest"
+	"app.modules/internal/repositorytest"
 )
 
 func newTestRepository(t *testing.T) *repository.FirestoreControllerImplements {
@@ -24,9 +24,9 @@ func newTestRepository(t *testing.T) *repository.FirestoreControllerImplements {
 	return integrationtest.NewFirestoreController(t)
 }
 
-func runTransaction(t *testing.T, controller *repository.FirestoreControllerImplements, fn func(context.Context, *firestore.Transaction) error) {
+func runTransaction(t *testing.T, controller *repository.FirestoreControllerImplements, fn func(context.Context, repository.Transaction) error) {
 	t.Helper()
-	err := controller.FirestoreClient().RunTransaction(context.Background(), fn)
+	err := controller.RunTransaction(context.Background(), fn)
 	require.NoError(t, err)
 }
 
@@ -63,7 +63,7 @@ func TestFirestoreRepository_SeatCreateAndRead(t *testing.T) {
 	controller := newTestRepository(t)
 	want := newSeatDoc(3, "seat-create-user", "session-create")
 
-	runTransaction(t, controller, func(_ context.Context, tx *firestore.Transaction) error {
+	runTransaction(t, controller, func(_ context.Context, tx repository.Transaction) error {
 		return controller.CreateSeat(tx, want, false)
 	})
 
@@ -96,7 +96,7 @@ func TestFirestoreRepository_SeatCollectionsAreSeparated(t *testing.T) {
 	memberSeat.WorkName = "メンバー作業"
 	memberSeat.Appearance.ColorCode1 = "#abcdef"
 
-	runTransaction(t, controller, func(_ context.Context, tx *firestore.Transaction) error {
+	runTransaction(t, controller, func(_ context.Context, tx repository.Transaction) error {
 		if err := controller.CreateSeat(tx, generalSeat, false); err != nil {
 			return err
 		}
@@ -125,7 +125,7 @@ func TestFirestoreRepository_UpdateSeat(t *testing.T) {
 	controller := newTestRepository(t)
 	original := newSeatDoc(7, "seat-update-user", "session-update")
 
-	runTransaction(t, controller, func(_ context.Context, tx *firestore.Transaction) error {
+	runTransaction(t, controller, func(_ context.Context, tx repository.Transaction) error {
 		return controller.CreateSeat(tx, original, false)
 	})
 
@@ -140,7 +140,7 @@ func TestFirestoreRepository_UpdateSeat(t *testing.T) {
 	updated.DailyCumulativeWorkSec = 5400
 	updated.MenuCode = "menu-updated"
 
-	runTransaction(t, controller, func(ctx context.Context, tx *firestore.Transaction) error {
+	runTransaction(t, controller, func(ctx context.Context, tx repository.Transaction) error {
 		return controller.UpdateSeat(ctx, tx, updated, false)
 	})
 
@@ -170,7 +170,7 @@ func TestFirestoreRepository_DeleteSeat(t *testing.T) {
 	controller := newTestRepository(t)
 	seat := newSeatDoc(9, "seat-delete-user", "session-delete")
 
-	runTransaction(t, controller, func(_ context.Context, tx *firestore.Transaction) error {
+	runTransaction(t, controller, func(_ context.Context, tx repository.Transaction) error {
 		return controller.CreateSeat(tx, seat, false)
 	})
 
@@ -249,7 +249,7 @@ func TestFirestoreRepository_UserUpdates(t *testing.T) {
 
 	updatedEntered := time.Date(2026, 8, 2, 9, 0, 0, 0, time.UTC)
 	updatedExited := time.Date(2026, 8, 1, 18, 0, 0, 0, time.UTC)
-	runTransaction(t, controller, func(_ context.Context, tx *firestore.Transaction) error {
+	runTransaction(t, controller, func(_ context.Context, tx repository.Transaction) error {
 		if err := controller.UpdateUserTotalTime(tx, userID, 7200, 3600); err != nil {
 			return err
 		}
@@ -412,6 +412,200 @@ func TestFirestoreRepository_WorkSegmentQuery(t *testing.T) {
 	}, got)
 }
 
+func TestFirestoreRepository_WorkSegmentsByUserIDAndTimeRange(t *testing.T) {
+	integrationtest.ResetFirestore(t)
+	controller := newTestRepository(t)
+	jst := timeutil.JapanLocation()
+	from := time.Date(2026, 8, 1, 0, 0, 0, 0, jst)
+	to := time.Date(2026, 8, 3, 0, 0, 0, 0, jst)
+	targetUserID := "history-user"
+	segments := []repository.WorkSegmentDoc{
+		{
+			UserID:      targetUserID,
+			SessionID:   "history-session-1",
+			SegmentType: repository.WorkState,
+			StartedAt:   from.Add(9 * time.Hour),
+			EndedAt:     from.Add(10 * time.Hour),
+			DurationSec: 3600,
+		},
+		{
+			UserID:      targetUserID,
+			SessionID:   "history-session-2",
+			SegmentType: repository.BreakState,
+			StartedAt:   from.Add(33 * time.Hour),
+			EndedAt:     from.Add(34 * time.Hour),
+			DurationSec: 3600,
+		},
+		{
+			// 範囲外（前）
+			UserID:      targetUserID,
+			SessionID:   "history-session-old",
+			SegmentType: repository.WorkState,
+			StartedAt:   from.Add(-time.Hour),
+			EndedAt:     from,
+			DurationSec: 3600,
+		},
+		{
+			// 範囲外（後）
+			UserID:      targetUserID,
+			SessionID:   "history-session-new",
+			SegmentType: repository.WorkState,
+			StartedAt:   to,
+			EndedAt:     to.Add(time.Hour),
+			DurationSec: 3600,
+		},
+		{
+			UserID:      "other-history-user",
+			SessionID:   "other-history-session",
+			SegmentType: repository.WorkState,
+			StartedAt:   from.Add(9 * time.Hour),
+			EndedAt:     from.Add(10 * time.Hour),
+			DurationSec: 3600,
+		},
+	}
+	for _, segment := range segments {
+		require.NoError(t, controller.CreateWorkSegmentDoc(context.Background(), nil, segment))
+	}
+
+	got, err := controller.ReadWorkSegmentsByUserIDAndTimeRange(context.Background(), targetUserID, from, to, 0)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	// 新しい順
+	assert.Equal(t, "history-session-2", got[0].SessionID)
+	assert.Equal(t, repository.BreakState, got[0].SegmentType)
+	assert.Equal(t, "history-session-1", got[1].SessionID)
+	assert.Equal(t, segments[0].StartedAt.UTC(), got[1].StartedAt)
+
+	got, err = controller.ReadWorkSegmentsByUserIDAndTimeRange(context.Background(), targetUserID, from, to, 1)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, "history-session-2", got[0].SessionID)
+}
+
+func TestFirestoreRepository_DailyUserWorkHistory(t *testing.T) {
+	integrationtest.ResetFirestore(t)
+	controller := newTestRepository(t)
+	ctx := context.Background()
+	jst := timeutil.JapanLocation()
+	userID := "daily-history-user"
+	day := time.Date(2026, 8, 2, 0, 0, 0, 0, jst)
+
+	// 同じ日への加算はドキュメントを作成してから積み上げる
+	require.NoError(t, controller.AddDailyUserWorkHistory(ctx, nil, userID, day.Add(10*time.Hour), 600, 60))
+	require.NoError(t, controller.AddDailyUserWorkHistory(ctx, nil, userID, day.Add(20*time.Hour), 300, 0))
+	got, err := controller.ReadDailyUserWorkHistory(ctx, nil, userID, day)
+	require.NoError(t, err)
+	assert.Equal(t, userID, got.UserID)
+	assert.True(t, day.Equal(got.Date))
+	assert.Equal(t, 900, got.TotalStudySec)
+	assert.Equal(t, 60, got.TotalBreakSec)
+	assert.Equal(t, "Asia/Tokyo", got.TimezoneName)
+
+	// 上書き
+	require.NoError(t, controller.SetDailyUserWorkHistory(ctx, nil, repository.DailyUserWorkHistoryDoc{
+		UserID:        userID,
+		Date:          day,
+		TotalStudySec: 1200,
+		TotalBreakSec: 120,
+		TimezoneName:  "Asia/Tokyo",
+	}))
+	got, err = controller.ReadDailyUserWorkHistory(ctx, nil, userID, day.Add(23*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1200, got.TotalStudySec)
+	assert.Equal(t, 120, got.TotalBreakSec)
+
+	// 翌日は別ドキュメント
+	_, err = controller.ReadDailyUserWorkHistory(ctx, nil, userID, day.AddDate(0, 0, 1))
+	require.Error(t, err)
+}
+
+func TestFirestoreRepository_UndoableExit(t *testing.T) {
+	integrationtest.ResetFirestore(t)
+	controller := newTestRepository(t)
+	ctx := context.Background()
+	userID := "undoable-exit-user"
+	exitedAt := time.Date(2026, 8, 2, 12, 0, 0, 0, time.UTC)
+
+	_, err := controller.ReadUndoableExit(ctx, nil, userID)
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	undoableExit := repository.UndoableExitDoc{
+		UserID:       userID,
+		Seat:         newSeatDoc(5, userID, "undoable-exit-session"),
+		IsMemberSeat: true,
+		ExitedAt:     exitedAt,
+		AddedWorkSec: 600,
+		AddedRP:      10,
+		AddedDailyHistory: []repository.UndoableDailyWorkHistory{
+			{Date: exitedAt.Truncate(24 * time.Hour), WorkSec: 600, BreakSec: 60},
+		},
+	}
+	require.NoError(t, controller.SetUndoableExit(ctx, nil, undoableExit))
+	got, err := controller.ReadUndoableExit(ctx, nil, userID)
+	require.NoError(t, err)
+	assert.Equal(t, "undoable-exit-session", got.Seat.SessionID)
+	assert.True(t, got.IsMemberSeat)
+	assert.True(t, exitedAt.Equal(got.ExitedAt))
+	assert.Equal(t, 600, got.AddedWorkSec)
+	require.Len(t, got.AddedDailyHistory, 1)
+	assert.Equal(t, 60, got.AddedDailyHistory[0].BreakSec)
+
+	// 後の退室で上書きされる
+	undoableExit.AddedWorkSec = 1200
+	require.NoError(t, controller.SetUndoableExit(ctx, nil, undoableExit))
+	got, err = controller.ReadUndoableExit(ctx, nil, userID)
+	require.NoError(t, err)
+	assert.Equal(t, 1200, got.AddedWorkSec)
+
+	require.NoError(t, controller.DeleteUndoableExit(ctx, nil, userID))
+	_, err = controller.ReadUndoableExit(ctx, nil, userID)
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestFirestoreRepository_SeatReservation(t *testing.T) {
+	integrationtest.ResetFirestore(t)
+	controller := newTestRepository(t)
+	ctx := context.Background()
+	userID := "seat-reservation-user"
+	startAt := time.Date(2026, 8, 2, 12, 0, 0, 0, time.UTC)
+
+	reservation := repository.SeatReservationDoc{
+		UserID:  userID,
+		SeatID:  5,
+		StartAt: startAt,
+		Until:   startAt.Add(time.Hour),
+	}
+	require.NoError(t, controller.CreateSeatReservation(ctx, nil, reservation, true))
+	// 一般席の予約は別のコレクション
+	require.NoError(t, controller.CreateSeatReservation(ctx, nil, reservation, false))
+
+	got, err := controller.ReadSeatReservationsWithUserID(ctx, nil, userID, true)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.NotEmpty(t, got[0].ReservationID)
+	assert.Equal(t, 5, got[0].SeatID)
+	assert.True(t, startAt.Equal(got[0].StartAt))
+
+	got, err = controller.ReadSeatReservationsWithSeatID(ctx, nil, 5, true)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+
+	got, err = controller.ReadSeatReservationsStartBefore(ctx, startAt, true, 0)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+	got, err = controller.ReadSeatReservationsStartBefore(ctx, startAt.Add(time.Minute), true, 1)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+
+	require.NoError(t, controller.DeleteSeatReservation(ctx, nil, got[0].ReservationID, true))
+	got, err = controller.ReadSeatReservationsWithUserID(ctx, nil, userID, true)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+	got, err = controller.ReadSeatReservationsWithUserID(ctx, nil, userID, false)
+	require.NoError(t, err)
+	assert.Len(t, got, 1)
+}
+
 func TestFirestoreRepository_TransactionAtomicitySuccess(t *testing.T) {
 	integrationtest.ResetFirestore(t)
 	controller := newTestRepository(t)
@@ -427,7 +621,7 @@ func TestFirestoreRepository_TransactionAtomicitySuccess(t *testing.T) {
 	}
 	require.NoError(t, controller.CreateUser(ctx, nil, userID, repository.UserDoc{}))
 
-	runTransaction(t, controller, func(ctx context.Context, tx *firestore.Transaction) error {
+	runTransaction(t, controller, func(ctx context.Context, tx repository.Transaction) error {
 		if err := controller.CreateSeat(tx, seat, false); err != nil {
 			return err
 		}
@@ -473,7 +667,7 @@ func TestFirestoreRepository_TransactionAtomicityRollback(t *testing.T) {
 	require.NoError(t, controller.CreateUser(ctx, nil, userID, originalUser))
 	sentinelErr := errors.New("rollback transaction sentinel")
 
-	err := controller.FirestoreClient().RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+	err := controller.RunTransaction(ctx, func(ctx context.Context, tx repository.Transaction) error {
 		if err := controller.CreateSeat(tx, seat, false); err != nil {
 			return err
 		}
@@ -503,3 +697,27 @@ func TestFirestoreRepository_TransactionAtomicityRollback(t *testing.T) {
 	assert.Equal(t, originalUser.TotalStudySec, gotUser.TotalStudySec)
 	assert.Equal(t, originalUser.DailyTotalStudySec, gotUser.DailyTotalStudySec)
 }
+
+func TestFirestoreRepository_Conformance(t *testing.T) {
+	repositorytest.RunConformance(t, func(t *testing.T) repositorytest.Fixture {
+		integrationtest.ResetFirestore(t)
+		controller := newTestRepository(t)
+		return repositorytest.Fixture{
+			Repository: controller,
+			SeedConfigs: func(t *testing.T, constants repository.ConstantsConfigDoc, credentials repository.CredentialsConfigDoc) {
+				ctx := context.Background()
+				configs := controller.FirestoreClient().Collection(repository.CONFIG)
+				_, err := configs.Doc(repository.SystemConstantsConfigDocName).Set(ctx, constants)
+				require.NoError(t, err)
+				_, err = configs.Doc(repository.CredentialsConfigDocName).Set(ctx, credentials)
+				require.NoError(t, err)
+			},
+			SeedMenus: func(t *testing.T, menus []repository.MenuDoc) {
+				for _, menu := range menus {
+					_, err := controller.FirestoreClient().Collection(repository.MENU).Doc(menu.Code).Set(context.Background(), menu)
+					require.NoError(t, err)
+				}
+			},
+		}
+	})
+}
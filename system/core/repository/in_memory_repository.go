@@ -0,0 +1,1032 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"maps"
+	"math/big"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"app.modules/core/timeutil"
+)
+
+const (
+	inMemoryTransactionMaxAttempts = 5 // firestore.DefaultTransactionMaxAttemptsと同じ
+	docIDLength                    = 20
+	docIDAlphabet                  = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+)
+
+var errInMemoryReadAfterWrite = errors.New("in-memory repository: read after write in transaction")
+
+// InMemoryRepository はRepositoryをメモリ上で実装したもの。エミュレーターを立てずにテストやローカル実行をするために使う。
+// トランザクションはFirestoreと同じく、書き込みをコミットまで保留し、読み取ったドキュメントがコミットまでに更新されていればやり直す。
+type InMemoryRepository struct {
+	mu       sync.Mutex
+	docs     map[string]map[string]any // コレクション名 -> ドキュメントID -> ドキュメント
+	versions map[string]int64          // ドキュメントのパス -> 書き込み回数。トランザクションの競合検出に使う。コレクションへの書き込み回数はcollectionVersionKeyに数える
+}
+
+type inMemoryTransaction struct {
+	readVersions   map[string]int64
+	writes         []inMemoryWrite
+	readAfterWrite bool
+	finished       bool
+}
+
+func (*inMemoryTransaction) transaction() {}
+
+// inMemoryWrite は1ドキュメントへの書き込み。applyは現在の値から新しい値を求め、keepがfalseならドキュメントを削除する。
+type inMemoryWrite struct {
+	collection string
+	id         string
+	apply      func(current any, exists bool) (next any, keep bool, err error)
+}
+
+type inMemoryEntry struct {
+	id  string
+	doc any
+}
+
+func NewInMemoryRepository() *InMemoryRepository {
+	return &InMemoryRepository{
+		docs:     make(map[string]map[string]any),
+		versions: make(map[string]int64),
+	}
+}
+
+// SetCredentialsConfig はcredentialsの設定ドキュメントを上書きする。Repositoryには作成する操作がないため、初期データの投入用。
+func (r *InMemoryRepository) SetCredentialsConfig(doc CredentialsConfigDoc) error {
+	return r.write(nil, setWrite(CONFIG, CredentialsConfigDocName, doc))
+}
+
+// SetSystemConstantsConfig はconstantsの設定ドキュメントを上書きする。初期データの投入用。
+func (r *InMemoryRepository) SetSystemConstantsConfig(doc ConstantsConfigDoc) error {
+	return r.write(nil, setWrite(CONFIG, SystemConstantsConfigDocName, doc))
+}
+
+// SetMenuDoc はメニューのドキュメントを上書きする。初期データの投入用。
+func (r *InMemoryRepository) SetMenuDoc(menu MenuDoc) error {
+	return r.write(nil, setWrite(MENU, menu.Code, menu))
+}
+
+// RunTransaction はfが成功したら書き込みをまとめて反映する。fがエラーを返したら何も反映しない。
+func (r *InMemoryRepository) RunTransaction(ctx context.Context, f func(ctx context.Context, tx Transaction) error) error {
+	for attempt := 0; attempt < inMemoryTransactionMaxAttempts; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		state := &inMemoryTransaction{readVersions: make(map[string]int64)}
+
+		err := f(ctx, state)
+
+		r.mu.Lock()
+		state.finished = true
+		if state.readAfterWrite {
+			r.mu.Unlock()
+			return errInMemoryReadAfterWrite
+		}
+		if err != nil {
+			r.mu.Unlock()
+			return err
+		}
+		conflicted := false
+		for path, version := range state.readVersions {
+			if r.versions[path] != version {
+				conflicted = true
+				break
+			}
+		}
+		if conflicted {
+			r.mu.Unlock()
+			continue
+		}
+		err = r.applyWritesLocked(state.writes)
+		r.mu.Unlock()
+		return err
+	}
+	return fmt.Errorf("in-memory repository: transaction failed after %d attempts due to conflicts", inMemoryTransactionMaxAttempts)
+}
+
+func (r *InMemoryRepository) Close() error {
+	return nil
+}
+
+func docPath(collection string, id string) string {
+	return collection + "/" + id
+}
+
+// collectionVersionKey トランザクション内のクエリの後に、同じコレクションへドキュメントが追加・削除されたことを検出するために使う。
+func collectionVersionKey(collection string) string {
+	return docPath(collection, "")
+}
+
+func newDocID() string {
+	// newDocID Firestoreの自動IDと同じ形式のIDを生成する。
+	b := make([]byte, docIDLength)
+	max := big.NewInt(int64(len(docIDAlphabet)))
+	for i := range b {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			panic(fmt.Errorf("in rand.Int(): %w", err))
+		}
+		b[i] = docIDAlphabet[n.Int64()]
+	}
+	return string(b)
+}
+
+func notFoundError(collection string, id string) error {
+	return status.Errorf(codes.NotFound, "%q not found", docPath(collection, id))
+}
+
+// cloneDoc 呼び出し元が値を書き換えても保存済みのドキュメントに影響しないように、スライスも含めてコピーする。
+func cloneDoc(doc any) any {
+	switch d := doc.(type) {
+	case UndoableExitDoc:
+		d.AddedDailyHistory = append([]UndoableDailyWorkHistory(nil), d.AddedDailyHistory...)
+		return d
+	case YoutubeAPIQuotaUsageDoc:
+		d.Calls = maps.Clone(d.Calls)
+		return d
+	case WorkNameTrendDoc:
+		ranking := make([]WorkNameTrendRanking, len(d.Ranking))
+		for i, item := range d.Ranking {
+			item.Examples = append([]string(nil), item.Examples...)
+			ranking[i] = item
+		}
+		d.Ranking = ranking
+		return d
+	default:
+		return doc
+	}
+}
+
+func (r *InMemoryRepository) transaction(tx Transaction) (*inMemoryTransaction, error) {
+	state, ok := tx.(*inMemoryTransaction)
+	if !ok {
+		return nil, fmt.Errorf("in-memory repository: unexpected transaction type: %T", tx)
+	}
+	if state.finished {
+		return nil, errors.New("in-memory repository: finished transaction")
+	}
+	return state, nil
+}
+
+func (r *InMemoryRepository) get(tx Transaction, collection string, id string) (any, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	path := docPath(collection, id)
+	if tx != nil {
+		state, err := r.transaction(tx)
+		if err != nil {
+			return nil, err
+		}
+		if len(state.writes) > 0 {
+			state.readAfterWrite = true
+			return nil, errInMemoryReadAfterWrite
+		}
+		state.readVersions[path] = r.versions[path]
+	}
+	doc, ok := r.docs[collection][id]
+	if !ok {
+		return nil, notFoundError(collection, id)
+	}
+	return cloneDoc(doc), nil
+}
+
+func getTyped[T any](r *InMemoryRepository, tx Transaction, collection string, id string) (T, error) {
+	var zero T
+	doc, err := r.get(tx, collection, id)
+	if err != nil {
+		return zero, err
+	}
+	typed, ok := doc.(T)
+	if !ok {
+		return zero, fmt.Errorf("unexpected document type %T in %s", doc, docPath(collection, id))
+	}
+	return typed, nil
+}
+
+// write はtxがnilならすぐに反映し、そうでなければコミットまで保留する。
+func (r *InMemoryRepository) write(tx Transaction, writes ...inMemoryWrite) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if tx == nil {
+		return r.applyWritesLocked(writes)
+	}
+	state, err := r.transaction(tx)
+	if err != nil {
+		return err
+	}
+	state.writes = append(state.writes, writes...)
+	return nil
+}
+
+// applyWritesLocked は全ての書き込みが成功する場合のみまとめて反映する。r.muを取得した状態で呼ぶこと。
+func (r *InMemoryRepository) applyWritesLocked(writes []inMemoryWrite) error {
+	type staged struct {
+		collection string
+		id         string
+		doc        any
+		keep       bool
+	}
+	stagedDocs := make(map[string]*staged)
+	var order []string
+	for _, w := range writes {
+		path := docPath(w.collection, w.id)
+		current, exists := r.docs[w.collection][w.id]
+		if s, ok := stagedDocs[path]; ok {
+			current, exists = s.doc, s.keep
+		}
+		next, keep, err := w.apply(cloneDoc(current), exists)
+		if err != nil {
+			return err
+		}
+		if _, ok := stagedDocs[path]; !ok {
+			order = append(order, path)
+		}
+		stagedDocs[path] = &staged{collection: w.collection, id: w.id, doc: next, keep: keep}
+	}
+	for _, path := range order {
+		s := stagedDocs[path]
+		if s.keep {
+			if r.docs[s.collection] == nil {
+				r.docs[s.collection] = make(map[string]any)
+			}
+			r.docs[s.collection][s.id] = cloneDoc(s.doc)
+		} else {
+			delete(r.docs[s.collection], s.id)
+		}
+		r.versions[path]++
+		r.versions[collectionVersionKey(s.collection)]++
+	}
+	return nil
+}
+
+func createWrite(collection string, id string, doc any) inMemoryWrite {
+	return inMemoryWrite{collection: collection, id: id, apply: func(_ any, exists bool) (any, bool, error) {
+		if exists {
+			return nil, false, status.Errorf(codes.AlreadyExists, "%q already exists", docPath(collection, id))
+		}
+		return doc, true, nil
+	}}
+}
+
+func setWrite(collection string, id string, doc any) inMemoryWrite {
+	return inMemoryWrite{collection: collection, id: id, apply: func(any, bool) (any, bool, error) {
+		return doc, true, nil
+	}}
+}
+
+// updateWrite はFirestoreのUpdateと同じく、ドキュメントが存在しなければNotFoundとする。
+func updateWrite[T any](collection string, id string, update func(doc *T)) inMemoryWrite {
+	return inMemoryWrite{collection: collection, id: id, apply: func(current any, exists bool) (any, bool, error) {
+		if !exists {
+			return nil, false, notFoundError(collection, id)
+		}
+		doc, ok := current.(T)
+		if !ok {
+			return nil, false, fmt.Errorf("unexpected document type %T in %s", current, docPath(collection, id))
+		}
+		update(&doc)
+		return doc, true, nil
+	}}
+}
+
+func deleteWrite(collection string, id string) inMemoryWrite {
+	return inMemoryWrite{collection: collection, id: id, apply: func(any, bool) (any, bool, error) {
+		return nil, false, nil
+	}}
+}
+
+// query はコレクション内でmatchを満たすドキュメントをドキュメントID順に返す。トランザクション外の読み取りとして扱う。
+func (r *InMemoryRepository) query(collection string, match func(doc any) bool) []inMemoryEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.queryLocked(collection, match)
+}
+
+// queryInTransaction はqueryをtxの中で行う。コミットまでにコレクションへ書き込みがあればやり直す。
+func (r *InMemoryRepository) queryInTransaction(tx Transaction, collection string, match func(doc any) bool) ([]inMemoryEntry, error) {
+	if tx == nil {
+		return r.query(collection, match), nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	state, err := r.transaction(tx)
+	if err != nil {
+		return nil, err
+	}
+	if len(state.writes) > 0 {
+		state.readAfterWrite = true
+		return nil, errInMemoryReadAfterWrite
+	}
+	key := collectionVersionKey(collection)
+	state.readVersions[key] = r.versions[key]
+	return r.queryLocked(collection, match), nil
+}
+
+// queryLocked r.muを取得した状態で呼ぶこと。
+func (r *InMemoryRepository) queryLocked(collection string, match func(doc any) bool) []inMemoryEntry {
+	entries := make([]inMemoryEntry, 0)
+	for id, doc := range r.docs[collection] {
+		if match(doc) {
+			entries = append(entries, inMemoryEntry{id: id, doc: cloneDoc(doc)})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].id < entries[j].id })
+	return entries
+}
+
+func queryTyped[T any](r *InMemoryRepository, collection string, match func(doc T) bool) []T {
+	docs, _ := queryTypedInTransaction(r, nil, collection, match)
+	return docs
+}
+
+func queryTypedInTransaction[T any](r *InMemoryRepository, tx Transaction, collection string, match func(doc T) bool) ([]T, error) {
+	entries, err := r.queryInTransaction(tx, collection, func(doc any) bool {
+		typed, ok := doc.(T)
+		return ok && match(typed)
+	})
+	if err != nil {
+		return nil, err
+	}
+	docs := make([]T, 0, len(entries)) // jsonになったときにnullとならないように。
+	for _, entry := range entries {
+		docs = append(docs, entry.doc.(T))
+	}
+	return docs, nil
+}
+
+// queryIterator はmatchを満たすドキュメントを最大limit件（0なら無制限）返すイテレーターを作る。
+func queryIterator[T any](r *InMemoryRepository, collection string, limit int, match func(doc T) bool) DocumentIterator {
+	entries := r.query(collection, func(doc any) bool {
+		typed, ok := doc.(T)
+		return ok && match(typed)
+	})
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	refs := make([]DocumentRef, 0, len(entries))
+	for _, entry := range entries {
+		refs = append(refs, DocumentRef{Collection: collection, ID: entry.id})
+	}
+	return &documentRefIterator{refs: refs}
+}
+
+// documentRefIterator 取得済みのDocumentRefを順に返す。Firestore以外の実装で共通に使う。
+type documentRefIterator struct {
+	refs []DocumentRef
+	next int
+	err  error // 取得に失敗した場合、Nextで返す
+}
+
+func (it *documentRefIterator) Next() (DocumentRef, error) {
+	if it.err != nil {
+		return DocumentRef{}, it.err
+	}
+	if it.next >= len(it.refs) {
+		return DocumentRef{}, iterator.Done
+	}
+	ref := it.refs[it.next]
+	it.next++
+	return ref, nil
+}
+
+func (it *documentRefIterator) Stop() {
+	it.next = len(it.refs)
+}
+
+func seatsCollectionName(isMemberSeat bool) string {
+	if isMemberSeat {
+		return MemberSeats
+	}
+	return SEATS
+}
+
+func seatReservationsCollectionName(isMemberSeat bool) string {
+	if isMemberSeat {
+		return MemberSeatReservations
+	}
+	return SeatReservations
+}
+
+func seatLimitsWHITEListCollectionName(isMemberSeat bool) string {
+	if isMemberSeat {
+		return MemberSeatLimitsWhiteList
+	}
+	return SeatLimitsWhiteList
+}
+
+func seatLimitsBLACKListCollectionName(isMemberSeat bool) string {
+	if isMemberSeat {
+		return MemberSeatLimitsBlackList
+	}
+	return SeatLimitsBlackList
+}
+
+func (r *InMemoryRepository) DeleteDocRef(_ context.Context, tx Transaction, ref DocumentRef) error {
+	return r.write(tx, deleteWrite(ref.Collection, ref.ID))
+}
+
+func (r *InMemoryRepository) ReadCredentialsConfig(_ context.Context, tx Transaction) (CredentialsConfigDoc, error) {
+	return getTyped[CredentialsConfigDoc](r, tx, CONFIG, CredentialsConfigDocName)
+}
+
+func (r *InMemoryRepository) ReadSystemConstantsConfig(_ context.Context, tx Transaction) (ConstantsConfigDoc, error) {
+	return getTyped[ConstantsConfigDoc](r, tx, CONFIG, SystemConstantsConfigDocName)
+}
+
+func (r *InMemoryRepository) ReadLiveChatID(ctx context.Context, tx Transaction) (string, error) {
+	credentialsDoc, err := r.ReadCredentialsConfig(ctx, tx)
+	if err != nil {
+		return "", fmt.Errorf("in ReadCredentialsConfig: %w", err)
+	}
+	return credentialsDoc.YoutubeLiveChatID, nil
+}
+
+func (r *InMemoryRepository) ReadNextPageToken(ctx context.Context, tx Transaction) (string, error) {
+	credentialsDoc, err := r.ReadCredentialsConfig(ctx, tx)
+	if err != nil {
+		return "", fmt.Errorf("in ReadCredentialsConfig: %w", err)
+	}
+	return credentialsDoc.YoutubeLiveChatNextPageToken, nil
+}
+
+func (r *InMemoryRepository) UpdateNextPageToken(_ context.Context, nextPageToken string) error {
+	return r.write(nil, updateWrite(CONFIG, CredentialsConfigDocName, func(doc *CredentialsConfigDoc) {
+		doc.YoutubeLiveChatNextPageToken = nextPageToken
+	}))
+}
+
+func (r *InMemoryRepository) ReadGeneralSeats(_ context.Context) ([]SeatDoc, error) {
+	return queryTyped(r, SEATS, func(SeatDoc) bool { return true }), nil
+}
+
+func (r *InMemoryRepository) ReadMemberSeats(_ context.Context) ([]SeatDoc, error) {
+	return queryTyped(r, MemberSeats, func(SeatDoc) bool { return true }), nil
+}
+
+func (r *InMemoryRepository) ReadSeatsExpiredUntil(_ context.Context, thresholdTime time.Time, isMemberSeat bool) ([]SeatDoc, error) {
+	return queryTyped(r, seatsCollectionName(isMemberSeat), func(seat SeatDoc) bool {
+		return seat.Until.Before(thresholdTime)
+	}), nil
+}
+
+func (r *InMemoryRepository) ReadSeatsExpiredBreakUntil(_ context.Context, thresholdTime time.Time, isMemberSeat bool) ([]SeatDoc, error) {
+	return queryTyped(r, seatsCollectionName(isMemberSeat), func(seat SeatDoc) bool {
+		return seat.State == BreakState && seat.CurrentStateUntil.Before(thresholdTime)
+	}), nil
+}
+
+func (r *InMemoryRepository) ReadSeatsExpiredWorkUntil(_ context.Context, thresholdTime time.Time, isMemberSeat bool) ([]SeatDoc, error) {
+	return queryTyped(r, seatsCollectionName(isMemberSeat), func(seat SeatDoc) bool {
+		return seat.State == WorkState && seat.CurrentStateUntil.Before(thresholdTime)
+	}), nil
+}
+
+func (r *InMemoryRepository) ReadSeat(_ context.Context, tx Transaction, seatID int, isMemberSeat bool) (SeatDoc, error) {
+	return getTyped[SeatDoc](r, tx, seatsCollectionName(isMemberSeat), strconv.Itoa(seatID))
+}
+
+func (r *InMemoryRepository) ReadSeatWithUserID(_ context.Context, userID string, isMemberSeat bool) (SeatDoc, error) {
+	seats := queryTyped(r, seatsCollectionName(isMemberSeat), func(seat SeatDoc) bool {
+		return seat.UserID == userID
+	})
+	if len(seats) >= 2 {
+		return SeatDoc{}, errors.New("There are more than two seats with the user id = " + userID + " !!")
+	}
+	if len(seats) == 1 {
+		return seats[0], nil
+	}
+	return SeatDoc{}, status.Errorf(codes.NotFound, "%s not found", "the document with user id = "+userID)
+}
+
+func (r *InMemoryRepository) ReadActiveWorkNameSeats(_ context.Context, isMemberSeat bool) ([]SeatDoc, error) {
+	return queryTyped(r, seatsCollectionName(isMemberSeat), func(seat SeatDoc) bool {
+		return seat.WorkName != ""
+	}), nil
+}
+
+func (r *InMemoryRepository) CreateSeat(tx Transaction, seat SeatDoc, isMemberSeat bool) error {
+	return r.write(tx, createWrite(seatsCollectionName(isMemberSeat), strconv.Itoa(seat.SeatID), seat))
+}
+
+func (r *InMemoryRepository) UpdateSeat(_ context.Context, tx Transaction, seat SeatDoc, isMemberSeat bool) error {
+	return r.write(tx, setWrite(seatsCollectionName(isMemberSeat), strconv.Itoa(seat.SeatID), seat))
+}
+
+func (r *InMemoryRepository) DeleteSeat(_ context.Context, tx Transaction, seatID int, isMemberSeat bool) error {
+	return r.write(tx, deleteWrite(seatsCollectionName(isMemberSeat), strconv.Itoa(seatID)))
+}
+
+func (r *InMemoryRepository) ReadUser(_ context.Context, tx Transaction, userID string) (UserDoc, error) {
+	return getTyped[UserDoc](r, tx, USERS, userID)
+}
+
+func (r *InMemoryRepository) CreateUser(_ context.Context, tx Transaction, userID string, userData UserDoc) error {
+	return r.write(tx, createWrite(USERS, userID, userData))
+}
+
+func (r *InMemoryRepository) updateUser(tx Transaction, userID string, update func(user *UserDoc)) error {
+	return r.write(tx, updateWrite(USERS, userID, update))
+}
+
+func (r *InMemoryRepository) UpdateUserLastEnteredDate(tx Transaction, userID string, enteredDate time.Time) error {
+	return r.updateUser(tx, userID, func(user *UserDoc) { user.LastEntered = enteredDate })
+}
+
+func (r *InMemoryRepository) UpdateUserLastExitedDate(tx Transaction, userID string, exitedDate time.Time) error {
+	return r.updateUser(tx, userID, func(user *UserDoc) { user.LastExited = exitedDate })
+}
+
+func (r *InMemoryRepository) UpdateUserRankVisible(tx Transaction, userID string, rankVisible bool) error {
+	return r.updateUser(tx, userID, func(user *UserDoc) { user.RankVisible = rankVisible })
+}
+
+func (r *InMemoryRepository) UpdateUserDefaultStudyMin(tx Transaction, userID string, defaultStudyMin int) error {
+	return r.updateUser(tx, userID, func(user *UserDoc) { user.DefaultStudyMin = defaultStudyMin })
+}
+
+func (r *InMemoryRepository) UpdateUserFavoriteColor(tx Transaction, userID string, colorCode string) error {
+	return r.updateUser(tx, userID, func(user *UserDoc) { user.FavoriteColor = colorCode })
+}
+
+// UpdateUserDailyGoalMin はFirestoreの実装と同じく達成フラグもリセットする。
+func (r *InMemoryRepository) UpdateUserDailyGoalMin(tx Transaction, userID string, dailyGoalMin int) error {
+	return r.updateUser(tx, userID, func(user *UserDoc) {
+		user.DailyGoalMin = dailyGoalMin
+		user.DailyGoalAchieved = false
+	})
+}
+
+func (r *InMemoryRepository) UpdateUserDailyGoalAchieved(tx Transaction, userID string, achieved bool) error {
+	return r.updateUser(tx, userID, func(user *UserDoc) { user.DailyGoalAchieved = achieved })
+}
+
+func (r *InMemoryRepository) UpdateUserTotalTime(tx Transaction, userID string, newTotalTimeSec int, newDailyTotalTimeSec int) error {
+	return r.updateUser(tx, userID, func(user *UserDoc) {
+		user.DailyTotalStudySec = newDailyTotalTimeSec
+		user.TotalStudySec = newTotalTimeSec
+	})
+}
+
+func (r *InMemoryRepository) UpdateUserSupporterAppearanceUntil(tx Transaction, userID string, until time.Time) error {
+	return r.updateUser(tx, userID, func(user *UserDoc) { user.SupporterAppearanceUntil = until })
+}
+
+func (r *InMemoryRepository) UpdateUserRankPoint(tx Transaction, userID string, rp int) error {
+	return r.updateUser(tx, userID, func(user *UserDoc) { user.RankPoint = rp })
+}
+
+func (r *InMemoryRepository) UpdateUserLastRPProcessed(tx Transaction, userID string, date time.Time) error {
+	return r.updateUser(tx, userID, func(user *UserDoc) { user.LastRPProcessed = date })
+}
+
+func (r *InMemoryRepository) UpdateUserRPAndLastPenaltyImposedDays(_ context.Context, tx Transaction, userID string, newRP int, newLastPenaltyImposedDays int) error {
+	return r.updateUser(tx, userID, func(user *UserDoc) {
+		user.RankPoint = newRP
+		user.LastPenaltyImposedDays = newLastPenaltyImposedDays
+	})
+}
+
+func (r *InMemoryRepository) UpdateUserIsContinuousActiveAndCurrentActivityStateStarted(_ context.Context, tx Transaction, userID string, isContinuousActive bool, currentActivityStateStarted time.Time) error {
+	return r.updateUser(tx, userID, func(user *UserDoc) {
+		user.IsContinuousActive = isContinuousActive
+		user.CurrentActivityStateStarted = currentActivityStateStarted
+	})
+}
+
+func (r *InMemoryRepository) UpdateUserLastPenaltyImposedDays(_ context.Context, tx Transaction, userID string, lastPenaltyImposedDays int) error {
+	return r.updateUser(tx, userID, func(user *UserDoc) { user.LastPenaltyImposedDays = lastPenaltyImposedDays })
+}
+
+func (r *InMemoryRepository) UpdateUserBestStreakDays(_ context.Context, tx Transaction, userID string, bestStreakDays int) error {
+	return r.updateUser(tx, userID, func(user *UserDoc) { user.BestStreakDays = bestStreakDays })
+}
+
+func (r *InMemoryRepository) UpdateLiveChatID(_ context.Context, tx Transaction, liveChatID string) error {
+	return r.write(tx, updateWrite(CONFIG, CredentialsConfigDocName, func(doc *CredentialsConfigDoc) {
+		doc.YoutubeLiveChatID = liveChatID
+	}))
+}
+
+func (r *InMemoryRepository) CreateLiveChatHistoryDoc(_ context.Context, tx Transaction, liveChatHistoryDoc LiveChatHistoryDoc) error {
+	return r.write(tx, createWrite(LiveChatHistory, newDocID(), liveChatHistoryDoc))
+}
+
+func (r *InMemoryRepository) Get500LiveChatHistoryDocIDsBeforeDate(_ context.Context, date time.Time) DocumentIterator {
+	return queryIterator(r, LiveChatHistory, FirestoreWritesLimitPerRequest, func(doc LiveChatHistoryDoc) bool {
+		return doc.PublishedAt.Before(date)
+	})
+}
+
+func (r *InMemoryRepository) CreateUserActivityDoc(_ context.Context, tx Transaction, activity UserActivityDoc) error {
+	return r.write(tx, createWrite(UserActivities, newDocID(), activity))
+}
+
+func (r *InMemoryRepository) Get500UserActivityDocIDsBeforeDate(_ context.Context, date time.Time) DocumentIterator {
+	return queryIterator(r, UserActivities, FirestoreWritesLimitPerRequest, func(doc UserActivityDoc) bool {
+		return doc.TakenAt.Before(date)
+	})
+}
+
+func (r *InMemoryRepository) GetAllUserActivityDocIDsAfterDate(_ context.Context, date time.Time) DocumentIterator {
+	return queryIterator(r, UserActivities, 0, func(doc UserActivityDoc) bool {
+		return !doc.TakenAt.Before(date)
+	})
+}
+
+func (r *InMemoryRepository) Get500OrderHistoryDocIDsBeforeDate(_ context.Context, date time.Time) DocumentIterator {
+	return queryIterator(r, OrderHistory, FirestoreWritesLimitPerRequest, func(doc OrderHistoryDoc) bool {
+		return doc.OrderedAt.Before(date)
+	})
+}
+
+func (r *InMemoryRepository) readUserActivitiesAfterDateForUserAndSeat(date time.Time, userID string, seatID int, isMemberSeat bool, activityType UserActivityType) []UserActivityDoc {
+	activities := queryTyped(r, UserActivities, func(doc UserActivityDoc) bool {
+		return !doc.TakenAt.Before(date) && doc.UserID == userID && doc.SeatID == seatID &&
+			doc.ActivityType == activityType && doc.IsMemberSeat == isMemberSeat
+	})
+	sort.SliceStable(activities, func(i, j int) bool { return activities[i].TakenAt.Before(activities[j].TakenAt) })
+	return activities
+}
+
+func (r *InMemoryRepository) GetEnterRoomUserActivityDocIDsAfterDateForUserAndSeat(_ context.Context, date time.Time, userID string, seatID int, isMemberSeat bool) ([]UserActivityDoc, error) {
+	return r.readUserActivitiesAfterDateForUserAndSeat(date, userID, seatID, isMemberSeat, EnterRoomActivity), nil
+}
+
+func (r *InMemoryRepository) GetExitRoomUserActivityDocIDsAfterDateForUserAndSeat(_ context.Context, date time.Time, userID string, seatID int, isMemberSeat bool) ([]UserActivityDoc, error) {
+	return r.readUserActivitiesAfterDateForUserAndSeat(date, userID, seatID, isMemberSeat, ExitRoomActivity), nil
+}
+
+// GetUsersActiveAfterDate date以後に入室したことのあるuserを全て取得
+func (r *InMemoryRepository) GetUsersActiveAfterDate(_ context.Context, date time.Time) DocumentIterator {
+	return queryIterator(r, USERS, 0, func(user UserDoc) bool {
+		return !user.LastEntered.Before(date)
+	})
+}
+
+func (r *InMemoryRepository) CreateWorkSegmentDoc(_ context.Context, tx Transaction, workSegment WorkSegmentDoc) error {
+	return r.write(tx, createWrite(WorkSegments, newDocID(), workSegment))
+}
+
+func (r *InMemoryRepository) ReadWorkStateSegmentsBySessionID(_ context.Context, sessionID string) ([]WorkSegmentDoc, error) {
+	return queryTyped(r, WorkSegments, func(segment WorkSegmentDoc) bool {
+		return segment.SessionID == sessionID && segment.SegmentType == WorkState
+	}), nil
+}
+
+func (r *InMemoryRepository) ReadWorkSegmentsByUserIDAndTimeRange(_ context.Context, userID string, from time.Time, to time.Time, limit int) ([]WorkSegmentDoc, error) {
+	segments := queryTyped(r, WorkSegments, func(segment WorkSegmentDoc) bool {
+		return segment.UserID == userID && !segment.StartedAt.Before(from) && segment.StartedAt.Before(to)
+	})
+	sort.SliceStable(segments, func(i, j int) bool { return segments[i].StartedAt.After(segments[j].StartedAt) })
+	if limit > 0 && len(segments) > limit {
+		segments = segments[:limit]
+	}
+	return segments, nil
+}
+
+func (r *InMemoryRepository) ReadWorkSegmentsPageByTimeRange(_ context.Context, from time.Time, to time.Time, startAfter WorkSegmentDocWithID, limit int) ([]WorkSegmentDocWithID, error) {
+	entries := r.query(WorkSegments, func(doc any) bool {
+		segment, ok := doc.(WorkSegmentDoc)
+		return ok && !segment.StartedAt.Before(from) && segment.StartedAt.Before(to)
+	})
+	candidates := make([]WorkSegmentDocWithID, 0, len(entries))
+	for _, entry := range entries {
+		candidates = append(candidates, WorkSegmentDocWithID{SegmentID: entry.id, WorkSegmentDoc: entry.doc.(WorkSegmentDoc)})
+	}
+	// entriesはID順なので、開始日時で安定ソートすればFirestoreと同じ（開始日時, ID）順になる
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].StartedAt.Before(candidates[j].StartedAt) })
+	segments := make([]WorkSegmentDocWithID, 0)
+	for _, segment := range candidates {
+		if startAfter.SegmentID != "" && (segment.StartedAt.Before(startAfter.StartedAt) ||
+			(segment.StartedAt.Equal(startAfter.StartedAt) && segment.SegmentID <= startAfter.SegmentID)) {
+			continue
+		}
+		if len(segments) == limit {
+			break
+		}
+		segments = append(segments, segment)
+	}
+	return segments, nil
+}
+
+func dailyUserWorkHistoryDocID(userID string, date time.Time) string {
+	return userID + "_" + date.In(timeutil.JapanLocation()).Format("2006-01-02")
+}
+
+func (r *InMemoryRepository) ReadDailyUserWorkHistory(_ context.Context, tx Transaction, userID string, date time.Time) (DailyUserWorkHistoryDoc, error) {
+	history, err := getTyped[DailyUserWorkHistoryDoc](r, tx, DailyUserWorkHistory, dailyUserWorkHistoryDocID(userID, date))
+	if err != nil {
+		return DailyUserWorkHistoryDoc{}, fmt.Errorf("get daily user work history: %w", err)
+	}
+	return history, nil
+}
+
+// AddDailyUserWorkHistory はFirestoreのIncrementと同じく、ドキュメントがなければ0に加算したものとして作成する。
+func (r *InMemoryRepository) AddDailyUserWorkHistory(_ context.Context, tx Transaction, userID string, date time.Time, studySec int, breakSec int) error {
+	return r.write(tx, inMemoryWrite{
+		collection: DailyUserWorkHistory,
+		id:         dailyUserWorkHistoryDocID(userID, date),
+		apply: func(current any, exists bool) (any, bool, error) {
+			var history DailyUserWorkHistoryDoc
+			if exists {
+				typed, ok := current.(DailyUserWorkHistoryDoc)
+				if !ok {
+					return nil, false, fmt.Errorf("unexpected document type %T in %s", current, DailyUserWorkHistory)
+				}
+				history = typed
+			}
+			history.UserID = userID
+			history.Date = timeutil.StartOfDayJST(date)
+			history.TotalStudySec += studySec
+			history.TotalBreakSec += breakSec
+			history.TimezoneName = timeutil.JapanLocation().String()
+			return history, true, nil
+		},
+	})
+}
+
+func (r *InMemoryRepository) SetDailyUserWorkHistory(_ context.Context, tx Transaction, history DailyUserWorkHistoryDoc) error {
+	return r.write(tx, setWrite(DailyUserWorkHistory, dailyUserWorkHistoryDocID(history.UserID, history.Date), history))
+}
+
+func (r *InMemoryRepository) ReadUndoableExit(_ context.Context, tx Transaction, userID string) (UndoableExitDoc, error) {
+	return getTyped[UndoableExitDoc](r, tx, UndoableExits, userID)
+}
+
+func (r *InMemoryRepository) SetUndoableExit(_ context.Context, tx Transaction, undoableExit UndoableExitDoc) error {
+	return r.write(tx, setWrite(UndoableExits, undoableExit.UserID, undoableExit))
+}
+
+func (r *InMemoryRepository) DeleteUndoableExit(_ context.Context, tx Transaction, userID string) error {
+	return r.write(tx, deleteWrite(UndoableExits, userID))
+}
+
+func (r *InMemoryRepository) Get500UndoableExitDocIDsBeforeDate(_ context.Context, date time.Time) DocumentIterator {
+	return queryIterator(r, UndoableExits, FirestoreWritesLimitPerRequest, func(doc UndoableExitDoc) bool {
+		return doc.ExitedAt.Before(date)
+	})
+}
+
+func (r *InMemoryRepository) ReadSeatReservationsWithUserID(_ context.Context, tx Transaction, userID string, isMemberSeat bool) ([]SeatReservationDoc, error) {
+	return queryTypedInTransaction(r, tx, seatReservationsCollectionName(isMemberSeat), func(reservation SeatReservationDoc) bool {
+		return reservation.UserID == userID
+	})
+}
+
+func (r *InMemoryRepository) ReadSeatReservationsWithSeatID(_ context.Context, tx Transaction, seatID int, isMemberSeat bool) ([]SeatReservationDoc, error) {
+	return queryTypedInTransaction(r, tx, seatReservationsCollectionName(isMemberSeat), func(reservation SeatReservationDoc) bool {
+		return reservation.SeatID == seatID
+	})
+}
+
+func (r *InMemoryRepository) ReadSeatReservationsStartBefore(_ context.Context, thresholdTime time.Time, isMemberSeat bool, limit int) ([]SeatReservationDoc, error) {
+	reservations := queryTyped(r, seatReservationsCollectionName(isMemberSeat), func(reservation SeatReservationDoc) bool {
+		return reservation.StartAt.Before(thresholdTime)
+	})
+	sort.SliceStable(reservations, func(i, j int) bool { return reservations[i].StartAt.Before(reservations[j].StartAt) })
+	if limit > 0 && len(reservations) > limit {
+		reservations = reservations[:limit]
+	}
+	return reservations, nil
+}
+
+// CreateSeatReservation は予約を作成する。ReservationIDはここで採番する。
+func (r *InMemoryRepository) CreateSeatReservation(_ context.Context, tx Transaction, reservation SeatReservationDoc, isMemberSeat bool) error {
+	reservation.ReservationID = newDocID()
+	return r.write(tx, createWrite(seatReservationsCollectionName(isMemberSeat), reservation.ReservationID, reservation))
+}
+
+func (r *InMemoryRepository) DeleteSeatReservation(_ context.Context, tx Transaction, reservationID string, isMemberSeat bool) error {
+	return r.write(tx, deleteWrite(seatReservationsCollectionName(isMemberSeat), reservationID))
+}
+
+func (r *InMemoryRepository) ReadSeatLimitsWHITEListWithSeatIDAndUserID(_ context.Context, seatID int, userID string, isMemberSeat bool) ([]SeatLimitDoc, error) {
+	return queryTyped(r, seatLimitsWHITEListCollectionName(isMemberSeat), func(limit SeatLimitDoc) bool {
+		return limit.SeatID == seatID && limit.UserID == userID
+	}), nil
+}
+
+func (r *InMemoryRepository) ReadSeatLimitsBLACKListWithSeatIDAndUserID(_ context.Context, seatID int, userID string, isMemberSeat bool) ([]SeatLimitDoc, error) {
+	return queryTyped(r, seatLimitsBLACKListCollectionName(isMemberSeat), func(limit SeatLimitDoc) bool {
+		return limit.SeatID == seatID && limit.UserID == userID
+	}), nil
+}
+
+func (r *InMemoryRepository) CreateSeatLimitInWHITEList(_ context.Context, seatID int, userID string, createdAt, until time.Time, isMemberSeat bool) error {
+	return r.write(nil, createWrite(seatLimitsWHITEListCollectionName(isMemberSeat), newDocID(), SeatLimitDoc{
+		SeatID:    seatID,
+		UserID:    userID,
+		CreatedAt: createdAt,
+		Until:     until,
+	}))
+}
+
+func (r *InMemoryRepository) CreateSeatLimitInBLACKList(_ context.Context, seatID int, userID string, createdAt, until time.Time, isMemberSeat bool) error {
+	return r.write(nil, createWrite(seatLimitsBLACKListCollectionName(isMemberSeat), newDocID(), SeatLimitDoc{
+		SeatID:    seatID,
+		UserID:    userID,
+		CreatedAt: createdAt,
+		Until:     until,
+	}))
+}
+
+// Get500SeatLimitsAfterUntilInWHITEList returns all seat limit docs whose `until` is before `thresholdTime`, same as the Firestore implementation.
+func (r *InMemoryRepository) Get500SeatLimitsAfterUntilInWHITEList(_ context.Context, thresholdTime time.Time, isMemberSeat bool) DocumentIterator {
+	return queryIterator(r, seatLimitsWHITEListCollectionName(isMemberSeat), FirestoreWritesLimitPerRequest, func(limit SeatLimitDoc) bool {
+		return limit.Until.Before(thresholdTime)
+	})
+}
+
+// Get500SeatLimitsAfterUntilInBLACKList returns all seat limit docs whose `until` is before `thresholdTime`, same as the Firestore implementation.
+func (r *InMemoryRepository) Get500SeatLimitsAfterUntilInBLACKList(_ context.Context, thresholdTime time.Time, isMemberSeat bool) DocumentIterator {
+	return queryIterator(r, seatLimitsBLACKListCollectionName(isMemberSeat), FirestoreWritesLimitPerRequest, func(limit SeatLimitDoc) bool {
+		return limit.Until.Before(thresholdTime)
+	})
+}
+
+func (r *InMemoryRepository) DeleteSeatLimitInWHITEList(_ context.Context, docID string, isMemberSeat bool) error {
+	return r.write(nil, deleteWrite(seatLimitsWHITEListCollectionName(isMemberSeat), docID))
+}
+
+func (r *InMemoryRepository) DeleteSeatLimitInBLACKList(_ context.Context, docID string, isMemberSeat bool) error {
+	return r.write(nil, deleteWrite(seatLimitsBLACKListCollectionName(isMemberSeat), docID))
+}
+
+func (r *InMemoryRepository) ReadAllMenuDocsOrderByCode(_ context.Context) ([]MenuDoc, error) {
+	menus := queryTyped(r, MENU, func(MenuDoc) bool { return true })
+	sort.SliceStable(menus, func(i, j int) bool { return menus[i].Code < menus[j].Code })
+	return menus, nil
+}
+
+func (r *InMemoryRepository) CountUserOrdersOfTheDay(_ context.Context, userID string, date time.Time) (int64, error) {
+	start := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.Local)
+	end := start.AddDate(0, 0, 1)
+	orders := queryTyped(r, OrderHistory, func(order OrderHistoryDoc) bool {
+		return order.UserID == userID && !order.OrderedAt.Before(start) && order.OrderedAt.Before(end)
+	})
+	return int64(len(orders)), nil
+}
+
+func (r *InMemoryRepository) CreateOrderHistoryDoc(_ context.Context, tx Transaction, orderHistoryDoc OrderHistoryDoc) error {
+	return r.write(tx, createWrite(OrderHistory, newDocID(), orderHistoryDoc))
+}
+
+func (r *InMemoryRepository) CreateModerationActionDoc(_ context.Context, tx Transaction, action ModerationActionDoc) error {
+	return r.write(tx, createWrite(ModerationActions, newDocID(), action))
+}
+
+func (r *InMemoryRepository) ReadModerationActionsByTargetUserID(_ context.Context, userID string) ([]ModerationActionDoc, error) {
+	actions := queryTyped(r, ModerationActions, func(action ModerationActionDoc) bool {
+		return action.TargetUserID == userID
+	})
+	sort.SliceStable(actions, func(i, j int) bool { return actions[i].TakenAt.Before(actions[j].TakenAt) })
+	return actions, nil
+}
+
+func (r *InMemoryRepository) CreateFanFundingHistoryDoc(_ context.Context, tx Transaction, messageID string, history FanFundingHistoryDoc) error {
+	return r.write(tx, createWrite(FanFundingHistory, messageID, history))
+}
+
+func (r *InMemoryRepository) ReadFanFundingHistoryByUserID(_ context.Context, userID string) ([]FanFundingHistoryDoc, error) {
+	histories := queryTyped(r, FanFundingHistory, func(history FanFundingHistoryDoc) bool {
+		return history.UserID == userID
+	})
+	sort.SliceStable(histories, func(i, j int) bool { return histories[i].PublishedAt.Before(histories[j].PublishedAt) })
+	return histories, nil
+}
+
+func (r *InMemoryRepository) ReadProcessedLiveChatMessage(_ context.Context, tx Transaction, messageID string) (ProcessedLiveChatMessageDoc, error) {
+	return getTyped[ProcessedLiveChatMessageDoc](r, tx, ProcessedLiveChatMessages, messageID)
+}
+
+func (r *InMemoryRepository) CreateProcessedLiveChatMessage(_ context.Context, tx Transaction, messageID string, processed ProcessedLiveChatMessageDoc) error {
+	return r.write(tx, createWrite(ProcessedLiveChatMessages, messageID, processed))
+}
+
+func (r *InMemoryRepository) AddYoutubeAPIQuotaUsage(_ context.Context, date string, calls map[string]int, units int) error {
+	return r.write(nil, inMemoryWrite{collection: YoutubeAPIQuotaUsage, id: date, apply: func(current any, exists bool) (any, bool, error) {
+		usage := YoutubeAPIQuotaUsageDoc{Date: date}
+		if exists {
+			var ok bool
+			if usage, ok = current.(YoutubeAPIQuotaUsageDoc); !ok {
+				return nil, false, fmt.Errorf("unexpected document type %T in %s", current, docPath(YoutubeAPIQuotaUsage, date))
+			}
+		}
+		usage.add(calls, units)
+		return usage, true, nil
+	}})
+}
+
+func (r *InMemoryRepository) ReadYoutubeAPIQuotaUsage(_ context.Context, date string) (YoutubeAPIQuotaUsageDoc, error) {
+	return getTyped[YoutubeAPIQuotaUsageDoc](r, nil, YoutubeAPIQuotaUsage, date)
+}
+
+func (r *InMemoryRepository) UpdateWorkNameTrend(_ context.Context, tx Transaction, workNameTrend WorkNameTrendDoc) error {
+	return r.write(tx, setWrite(WorkNameTrend, WorkNameTrendDocName, workNameTrend))
+}
+
+func (r *InMemoryRepository) GetAllUserDocRefs(_ context.Context) ([]DocumentRef, error) {
+	entries := r.query(USERS, func(any) bool { return true })
+	refs := make([]DocumentRef, 0, len(entries))
+	for _, entry := range entries {
+		refs = append(refs, DocumentRef{Collection: USERS, ID: entry.id})
+	}
+	return refs, nil
+}
+
+func (r *InMemoryRepository) ReadUsersPage(_ context.Context, startAfterUserID string, limit int) ([]UserDocWithID, error) {
+	entries := r.query(USERS, func(doc any) bool {
+		_, ok := doc.(UserDoc)
+		return ok
+	})
+	users := make([]UserDocWithID, 0)
+	for _, entry := range entries {
+		if entry.id <= startAfterUserID {
+			continue
+		}
+		if len(users) == limit {
+			break
+		}
+		users = append(users, UserDocWithID{UserID: entry.id, UserDoc: entry.doc.(UserDoc)})
+	}
+	return users, nil
+}
+
+func (r *InMemoryRepository) GetAllNonDailyZeroUserDocs(_ context.Context) DocumentIterator {
+	return queryIterator(r, USERS, 0, func(user UserDoc) bool {
+		return user.DailyTotalStudySec != 0
+	})
+}
+
+func (r *InMemoryRepository) ResetDailyTotalStudyTime(_ context.Context, userID string) error {
+	return r.updateUser(nil, userID, func(user *UserDoc) { user.DailyTotalStudySec = 0 })
+}
+
+func (r *InMemoryRepository) GetAllDailyGoalAchievedUserDocs(_ context.Context) DocumentIterator {
+	return queryIterator(r, USERS, 0, func(user UserDoc) bool {
+		return user.DailyGoalAchieved
+	})
+}
+
+func (r *InMemoryRepository) ResetDailyGoalAchieved(_ context.Context, userID string) error {
+	return r.updateUser(nil, userID, func(user *UserDoc) { user.DailyGoalAchieved = false })
+}
+
+func (r *InMemoryRepository) updateConstants(tx Transaction, update func(doc *ConstantsConfigDoc)) error {
+	return r.write(tx, updateWrite(CONFIG, SystemConstantsConfigDocName, update))
+}
+
+func (r *InMemoryRepository) UpdateLastResetDailyTotalStudyTime(_ context.Context, timestamp time.Time) error {
+	return r.updateConstants(nil, func(doc *ConstantsConfigDoc) { doc.LastResetDailyTotalStudySec = timestamp })
+}
+
+func (r *InMemoryRepository) UpdateLastLongTimeSittingChecked(_ context.Context, _ time.Time) error {
+	// ConstantsConfigDocにフィールドがないため、Firestoreと同じく読み出せる値はない
+	return r.updateConstants(nil, func(*ConstantsConfigDoc) {})
+}
+
+func (r *InMemoryRepository) UpdateLastTransferCollectionHistoryBigquery(_ context.Context, timestamp time.Time) error {
+	return r.updateConstants(nil, func(doc *ConstantsConfigDoc) { doc.LastTransferCollectionHistoryBigquery = timestamp })
+}
+
+func (r *InMemoryRepository) UpdateDesiredMaxSeats(_ context.Context, tx Transaction, desiredMaxSeats int) error {
+	return r.updateConstants(tx, func(doc *ConstantsConfigDoc) { doc.DesiredMaxSeats = desiredMaxSeats })
+}
+
+func (r *InMemoryRepository) UpdateDesiredMemberMaxSeats(_ context.Context, tx Transaction, desiredMemberMaxSeats int) error {
+	return r.updateConstants(tx, func(doc *ConstantsConfigDoc) { doc.DesiredMemberMaxSeats = desiredMemberMaxSeats })
+}
+
+func (r *InMemoryRepository) UpdateMaxSeats(_ context.Context, tx Transaction, maxSeats int) error {
+	return r.updateConstants(tx, func(doc *ConstantsConfigDoc) { doc.MaxSeats = maxSeats })
+}
+
+func (r *InMemoryRepository) UpdateMemberMaxSeats(_ context.Context, tx Transaction, memberMaxSeats int) error {
+	return r.updateConstants(tx, func(doc *ConstantsConfigDoc) { doc.MemberMaxSeats = memberMaxSeats })
+}
+
+func (r *InMemoryRepository) updateCredentials(tx Transaction, update func(doc *CredentialsConfigDoc)) error {
+	return r.write(tx, updateWrite(CONFIG, CredentialsConfigDocName, update))
+}
+
+// UpdateAccessTokenOfChannelCredential アクセストークンはCredentialsConfigDocに含まれないため、ドキュメントの存在のみ確認する。
+func (r *InMemoryRepository) UpdateAccessTokenOfChannelCredential(_ context.Context, tx Transaction, _ string, _ time.Time) error {
+	return r.updateCredentials(tx, func(*CredentialsConfigDoc) {})
+}
+
+// UpdateAccessTokenOfBotCredential アクセストークンはCredentialsConfigDocに含まれないため、ドキュメントの存在のみ確認する。
+func (r *InMemoryRepository) UpdateAccessTokenOfBotCredential(_ context.Context, tx Transaction, _ string, _ time.Time) error {
+	return r.updateCredentials(tx, func(*CredentialsConfigDoc) {})
+}
@@ -0,0 +1,108 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app.modules/core/repository"
+	"app.modules/internal/repositorytest"
+)
+
+func TestInMemoryRepository_Conformance(t *testing.T) {
+	repositorytest.RunConformance(t, func(t *testing.T) repositorytest.Fixture {
+		repo := repository.NewInMemoryRepository()
+		return repositorytest.Fixture{
+			Repository: repo,
+			SeedConfigs: func(t *testing.T, constants repository.ConstantsConfigDoc, credentials repository.CredentialsConfigDoc) {
+				require.NoError(t, repo.SetSystemConstantsConfig(constants))
+				require.NoError(t, repo.SetCredentialsConfig(credentials))
+			},
+			SeedMenus: func(t *testing.T, menus []repository.MenuDoc) {
+				for _, menu := range menus {
+					require.NoError(t, repo.SetMenuDoc(menu))
+				}
+			},
+		}
+	})
+}
+
+func TestInMemoryRepository_TransactionRetriesOnConflict(t *testing.T) {
+	repo := repository.NewInMemoryRepository()
+	ctx := context.Background()
+	userID := "conflict-user"
+	require.NoError(t, repo.CreateUser(ctx, nil, userID, repository.UserDoc{TotalStudySec: 100}))
+
+	attempts := 0
+	err := repo.RunTransaction(ctx, func(ctx context.Context, tx repository.Transaction) error {
+		attempts++
+		user, err := repo.ReadUser(ctx, tx, userID)
+		if err != nil {
+			return err
+		}
+		if attempts == 1 {
+			// 読み取り後、コミット前に他から更新される
+			if err := repo.ResetDailyTotalStudyTime(ctx, userID); err != nil {
+				return err
+			}
+		}
+		// 以下書き込みのみ
+		return repo.UpdateUserTotalTime(tx, userID, user.TotalStudySec+60, 60)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+
+	got, err := repo.ReadUser(ctx, nil, userID)
+	require.NoError(t, err)
+	assert.Equal(t, 160, got.TotalStudySec)
+}
+
+func TestInMemoryRepository_TransactionRetriesOnQueryConflict(t *testing.T) {
+	repo := repository.NewInMemoryRepository()
+	ctx := context.Background()
+	reservation := repository.SeatReservationDoc{UserID: "conflict-user", SeatID: 1}
+
+	attempts := 0
+	err := repo.RunTransaction(ctx, func(ctx context.Context, tx repository.Transaction) error {
+		attempts++
+		reservations, err := repo.ReadSeatReservationsWithSeatID(ctx, tx, reservation.SeatID, false)
+		if err != nil {
+			return err
+		}
+		if attempts == 1 {
+			// 読み取り後、コミット前に他から同じ席が予約される
+			if err := repo.CreateSeatReservation(ctx, nil, reservation, false); err != nil {
+				return err
+			}
+		}
+		if len(reservations) > 0 {
+			return nil
+		}
+		// 以下書き込みのみ
+		return repo.CreateSeatReservation(ctx, tx, reservation, false)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+
+	got, err := repo.ReadSeatReservationsWithSeatID(ctx, nil, reservation.SeatID, false)
+	require.NoError(t, err)
+	assert.Len(t, got, 1)
+}
+
+func TestInMemoryRepository_FinishedTransaction(t *testing.T) {
+	repo := repository.NewInMemoryRepository()
+	ctx := context.Background()
+
+	var finished repository.Transaction
+	require.NoError(t, repo.RunTransaction(ctx, func(_ context.Context, tx repository.Transaction) error {
+		finished = tx
+		return nil
+	}))
+
+	err := repo.CreateSeat(finished, repository.SeatDoc{SeatID: 1}, false)
+	assert.Error(t, err)
+	_, err = repo.ReadSeat(ctx, nil, 1, false)
+	assert.Error(t, err)
+}
@@ -3,29 +3,39 @@ package repository
 import (
 	"context"
 	"time"
-
-	"cloud.google.com/go/firestore"
 )
 
-// DBClient テストでfirestore.Clientをmockできるように定義
-type DBClient interface {
-	Collection(path string) *firestore.CollectionRef
-	Doc(path string) *firestore.DocumentRef
-	RunTransaction(ctx context.Context, f func(context.Context, *firestore.Transaction) error, opts ...firestore.TransactionOption) (err error)
-	Close() error
+// Transaction RunTransactionのコールバックに渡される、実装ごとのトランザクション。
+// 各操作にnilを渡した場合はトランザクション外で実行される。
+type Transaction interface {
+	transaction()
+}
+
+// DocumentRef クエリ結果のドキュメントの位置。Collectionはコレクション名（SQL実装ではテーブルに対応）
+type DocumentRef struct {
+	Collection string
+	ID         string
+}
+
+// DocumentIterator クエリ結果を1件ずつ返す。終端ではiterator.Doneを返す。
+type DocumentIterator interface {
+	Next() (DocumentRef, error)
+	Stop()
 }
 
 type Repository interface {
-	FirestoreClient() DBClient
+	// RunTransaction fがエラーを返さなければtxで行った書き込みをまとめて反映する。競合した場合はfが再実行されることがある。
+	RunTransaction(ctx context.Context, f func(ctx context.Context, tx Transaction) error) error
+	Close() error
 
 	// Document Operations
-	DeleteDocRef(ctx context.Context, tx *firestore.Transaction, ref *firestore.DocumentRef) error
+	DeleteDocRef(ctx context.Context, tx Transaction, ref DocumentRef) error
 
 	// Credential Operations
-	ReadCredentialsConfig(ctx context.Context, tx *firestore.Transaction) (CredentialsConfigDoc, error)
-	ReadSystemConstantsConfig(ctx context.Context, tx *firestore.Transaction) (ConstantsConfigDoc, error)
-	ReadLiveChatID(ctx context.Context, tx *firestore.Transaction) (string, error)
-	ReadNextPageToken(ctx context.Context, tx *firestore.Transaction) (string, error)
+	ReadCredentialsConfig(ctx context.Context, tx Transaction) (CredentialsConfigDoc, error)
+	ReadSystemConstantsConfig(ctx context.Context, tx Transaction) (ConstantsConfigDoc, error)
+	ReadLiveChatID(ctx context.Context, tx Transaction) (string, error)
+	ReadNextPageToken(ctx context.Context, tx Transaction) (string, error)
 	UpdateNextPageToken(ctx context.Context, nextPageToken string) error
 
 	// Seat Operations
@@ -33,53 +43,78 @@ type Repository interface {
 	ReadMemberSeats(ctx context.Context) ([]SeatDoc, error)
 	ReadSeatsExpiredUntil(ctx context.Context, thresholdTime time.Time, isMemberSeat bool) ([]SeatDoc, error)
 	ReadSeatsExpiredBreakUntil(ctx context.Context, thresholdTime time.Time, isMemberSeat bool) ([]SeatDoc, error)
-	ReadSeat(ctx context.Context, tx *firestore.Transaction, seatID int, isMemberSeat bool) (SeatDoc, error)
+	ReadSeatsExpiredWorkUntil(ctx context.Context, thresholdTime time.Time, isMemberSeat bool) ([]SeatDoc, error)
+	ReadSeat(ctx context.Context, tx Transaction, seatID int, isMemberSeat bool) (SeatDoc, error)
 	ReadSeatWithUserID(ctx context.Context, userID string, isMemberSeat bool) (SeatDoc, error)
 	ReadActiveWorkNameSeats(ctx context.Context, isMemberSeat bool) ([]SeatDoc, error)
-	CreateSeat(tx *firestore.Transaction, seat SeatDoc, isMemberSeat bool) error
-	UpdateSeat(ctx context.Context, tx *firestore.Transaction, seat SeatDoc, isMemberSeat bool) error
-	DeleteSeat(ctx context.Context, tx *firestore.Transaction, seatID int, isMemberSeat bool) error
+	CreateSeat(tx Transaction, seat SeatDoc, isMemberSeat bool) error
+	UpdateSeat(ctx context.Context, tx Transaction, seat SeatDoc, isMemberSeat bool) error
+	DeleteSeat(ctx context.Context, tx Transaction, seatID int, isMemberSeat bool) error
 
 	// User Operations
-	ReadUser(ctx context.Context, tx *firestore.Transaction, userID string) (UserDoc, error)
-	CreateUser(ctx context.Context, tx *firestore.Transaction, userID string, userData UserDoc) error
-	UpdateUserLastEnteredDate(tx *firestore.Transaction, userID string, enteredDate time.Time) error
-	UpdateUserLastExitedDate(tx *firestore.Transaction, userID string, exitedDate time.Time) error
-	UpdateUserRankVisible(tx *firestore.Transaction, userID string, rankVisible bool) error
-	UpdateUserDefaultStudyMin(tx *firestore.Transaction, userID string, defaultStudyMin int) error
-	UpdateUserFavoriteColor(tx *firestore.Transaction, userID string, colorCode string) error
-	UpdateUserTotalTime(tx *firestore.Transaction, userID string, newTotalTimeSec int, newDailyTotalTimeSec int) error
-	UpdateUserRankPoint(tx *firestore.Transaction, userID string, rp int) error
-	UpdateUserLastRPProcessed(tx *firestore.Transaction, userID string, date time.Time) error
-	UpdateUserRPAndLastPenaltyImposedDays(ctx context.Context, tx *firestore.Transaction, userID string, newRP int, newLastPenaltyImposedDays int) error
-	UpdateUserIsContinuousActiveAndCurrentActivityStateStarted(ctx context.Context, tx *firestore.Transaction, userID string, isContinuousActive bool, currentActivityStateStarted time.Time) error
-	UpdateUserLastPenaltyImposedDays(ctx context.Context, tx *firestore.Transaction, userID string, lastPenaltyImposedDays int) error
+	ReadUser(ctx context.Context, tx Transaction, userID string) (UserDoc, error)
+	CreateUser(ctx context.Context, tx Transaction, userID string, userData UserDoc) error
+	UpdateUserLastEnteredDate(tx Transaction, userID string, enteredDate time.Time) error
+	UpdateUserLastExitedDate(tx Transaction, userID string, exitedDate time.Time) error
+	UpdateUserRankVisible(tx Transaction, userID string, rankVisible bool) error
+	UpdateUserDefaultStudyMin(tx Transaction, userID string, defaultStudyMin int) error
+	UpdateUserFavoriteColor(tx Transaction, userID string, colorCode string) error
+	UpdateUserDailyGoalMin(tx Transaction, userID string, dailyGoalMin int) error
+	UpdateUserDailyGoalAchieved(tx Transaction, userID string, achieved bool) error
+	UpdateUserTotalTime(tx Transaction, userID string, newTotalTimeSec int, newDailyTotalTimeSec int) error
+	UpdateUserRankPoint(tx Transaction, userID string, rp int) error
+	UpdateUserLastRPProcessed(tx Transaction, userID string, date time.Time) error
+	UpdateUserRPAndLastPenaltyImposedDays(ctx context.Context, tx Transaction, userID string, newRP int, newLastPenaltyImposedDays int) error
+	UpdateUserIsContinuousActiveAndCurrentActivityStateStarted(ctx context.Context, tx Transaction, userID string, isContinuousActive bool, currentActivityStateStarted time.Time) error
+	UpdateUserLastPenaltyImposedDays(ctx context.Context, tx Transaction, userID string, lastPenaltyImposedDays int) error
+	UpdateUserBestStreakDays(ctx context.Context, tx Transaction, userID string, bestStreakDays int) error
+	UpdateUserSupporterAppearanceUntil(tx Transaction, userID string, until time.Time) error
 
 	// Live Chat Operations
-	UpdateLiveChatID(ctx context.Context, tx *firestore.Transaction, liveChatID string) error
-	CreateLiveChatHistoryDoc(ctx context.Context, tx *firestore.Transaction, liveChatHistoryDoc LiveChatHistoryDoc) error
-	Get500LiveChatHistoryDocIDsBeforeDate(ctx context.Context, date time.Time) *firestore.DocumentIterator
+	UpdateLiveChatID(ctx context.Context, tx Transaction, liveChatID string) error
+	CreateLiveChatHistoryDoc(ctx context.Context, tx Transaction, liveChatHistoryDoc LiveChatHistoryDoc) error
+	Get500LiveChatHistoryDocIDsBeforeDate(ctx context.Context, date time.Time) DocumentIterator
 
 	// User Activity Operations
-	CreateUserActivityDoc(ctx context.Context, tx *firestore.Transaction, activity UserActivityDoc) error
-	Get500UserActivityDocIDsBeforeDate(ctx context.Context, date time.Time) *firestore.DocumentIterator
-	GetAllUserActivityDocIDsAfterDate(ctx context.Context, date time.Time) *firestore.DocumentIterator
-	Get500OrderHistoryDocIDsBeforeDate(ctx context.Context, date time.Time) *firestore.DocumentIterator
+	CreateUserActivityDoc(ctx context.Context, tx Transaction, activity UserActivityDoc) error
+	Get500UserActivityDocIDsBeforeDate(ctx context.Context, date time.Time) DocumentIterator
+	GetAllUserActivityDocIDsAfterDate(ctx context.Context, date time.Time) DocumentIterator
+	Get500OrderHistoryDocIDsBeforeDate(ctx context.Context, date time.Time) DocumentIterator
 	GetEnterRoomUserActivityDocIDsAfterDateForUserAndSeat(ctx context.Context, date time.Time, userID string, seatID int, isMemberSeat bool) ([]UserActivityDoc, error)
 	GetExitRoomUserActivityDocIDsAfterDateForUserAndSeat(ctx context.Context, date time.Time, userID string, seatID int, isMemberSeat bool) ([]UserActivityDoc, error)
-	GetUsersActiveAfterDate(ctx context.Context, date time.Time) *firestore.DocumentIterator
+	GetUsersActiveAfterDate(ctx context.Context, date time.Time) DocumentIterator
 
 	// Work Segment Operations
-	CreateWorkSegmentDoc(ctx context.Context, tx *firestore.Transaction, workSegment WorkSegmentDoc) error
+	CreateWorkSegmentDoc(ctx context.Context, tx Transaction, workSegment WorkSegmentDoc) error
 	ReadWorkStateSegmentsBySessionID(ctx context.Context, sessionID string) ([]WorkSegmentDoc, error)
+	ReadWorkSegmentsByUserIDAndTimeRange(ctx context.Context, userID string, from time.Time, to time.Time, limit int) ([]WorkSegmentDoc, error)
+	ReadWorkSegmentsPageByTimeRange(ctx context.Context, from time.Time, to time.Time, startAfter WorkSegmentDocWithID, limit int) ([]WorkSegmentDocWithID, error)
+
+	// Daily User Work History Operations
+	ReadDailyUserWorkHistory(ctx context.Context, tx Transaction, userID string, date time.Time) (DailyUserWorkHistoryDoc, error)
+	AddDailyUserWorkHistory(ctx context.Context, tx Transaction, userID string, date time.Time, studySec int, breakSec int) error
+	SetDailyUserWorkHistory(ctx context.Context, tx Transaction, history DailyUserWorkHistoryDoc) error
+
+	// Undoable Exit Operations
+	ReadUndoableExit(ctx context.Context, tx Transaction, userID string) (UndoableExitDoc, error)
+	SetUndoableExit(ctx context.Context, tx Transaction, undoableExit UndoableExitDoc) error
+	DeleteUndoableExit(ctx context.Context, tx Transaction, userID string) error
+	Get500UndoableExitDocIDsBeforeDate(ctx context.Context, date time.Time) DocumentIterator
+
+	// Seat Reservation Operations
+	ReadSeatReservationsWithUserID(ctx context.Context, tx Transaction, userID string, isMemberSeat bool) ([]SeatReservationDoc, error)
+	ReadSeatReservationsWithSeatID(ctx context.Context, tx Transaction, seatID int, isMemberSeat bool) ([]SeatReservationDoc, error)
+	ReadSeatReservationsStartBefore(ctx context.Context, thresholdTime time.Time, isMemberSeat bool, limit int) ([]SeatReservationDoc, error)
+	CreateSeatReservation(ctx context.Context, tx Transaction, reservation SeatReservationDoc, isMemberSeat bool) error
+	DeleteSeatReservation(ctx context.Context, tx Transaction, reservationID string, isMemberSeat bool) error
 
 	// Seat Limit Operations
 	ReadSeatLimitsWHITEListWithSeatIDAndUserID(ctx context.Context, seatID int, userID string, isMemberSeat bool) ([]SeatLimitDoc, error)
 	ReadSeatLimitsBLACKListWithSeatIDAndUserID(ctx context.Context, seatID int, userID string, isMemberSeat bool) ([]SeatLimitDoc, error)
 	CreateSeatLimitInWHITEList(ctx context.Context, seatID int, userID string, createdAt, until time.Time, isMemberSeat bool) error
 	CreateSeatLimitInBLACKList(ctx context.Context, seatID int, userID string, createdAt, until time.Time, isMemberSeat bool) error
-	Get500SeatLimitsAfterUntilInWHITEList(ctx context.Context, thresholdTime time.Time, isMemberSeat bool) *firestore.DocumentIterator
-	Get500SeatLimitsAfterUntilInBLACKList(ctx context.Context, thresholdTime time.Time, isMemberSeat bool) *firestore.DocumentIterator
+	Get500SeatLimitsAfterUntilInWHITEList(ctx context.Context, thresholdTime time.Time, isMemberSeat bool) DocumentIterator
+	Get500SeatLimitsAfterUntilInBLACKList(ctx context.Context, thresholdTime time.Time, isMemberSeat bool) DocumentIterator
 	DeleteSeatLimitInWHITEList(ctx context.Context, docID string, isMemberSeat bool) error
 	DeleteSeatLimitInBLACKList(ctx context.Context, docID string, isMemberSeat bool) error
 
@@ -88,22 +123,45 @@ type Repository interface {
 
 	// Order History Operations
 	CountUserOrdersOfTheDay(ctx context.Context, userID string, date time.Time) (int64, error)
-	CreateOrderHistoryDoc(ctx context.Context, tx *firestore.Transaction, orderHistoryDoc OrderHistoryDoc) error
+	CreateOrderHistoryDoc(ctx context.Context, tx Transaction, orderHistoryDoc OrderHistoryDoc) error
+
+	// Moderation Action Operations
+	CreateModerationActionDoc(ctx context.Context, tx Transaction, action ModerationActionDoc) error
+	ReadModerationActionsByTargetUserID(ctx context.Context, userID string) ([]ModerationActionDoc, error)
+
+	// Fan Funding History Operations
+	// CreateFanFundingHistoryDoc messageIDのドキュメントが既にあればAlreadyExistsを返す
+	CreateFanFundingHistoryDoc(ctx context.Context, tx Transaction, messageID string, history FanFundingHistoryDoc) error
+	ReadFanFundingHistoryByUserID(ctx context.Context, userID string) ([]FanFundingHistoryDoc, error)
+
+	// Processed Live Chat Message Operations
+	// ReadProcessedLiveChatMessage 処理済みでなければNotFoundを返す
+	ReadProcessedLiveChatMessage(ctx context.Context, tx Transaction, messageID string) (ProcessedLiveChatMessageDoc, error)
+	// CreateProcessedLiveChatMessage messageIDのドキュメントが既にあればAlreadyExistsを返す
+	CreateProcessedLiveChatMessage(ctx context.Context, tx Transaction, messageID string, processed ProcessedLiveChatMessageDoc) error
+
+	// YouTube API Quota Operations
+	// AddYoutubeAPIQuotaUsage dateの使用量にcallsとunitsを加算する。ドキュメントがなければ作成する
+	AddYoutubeAPIQuotaUsage(ctx context.Context, date string, calls map[string]int, units int) error
+	ReadYoutubeAPIQuotaUsage(ctx context.Context, date string) (YoutubeAPIQuotaUsageDoc, error)
 
 	// Work Name Trend Operations
-	UpdateWorkNameTrend(ctx context.Context, tx *firestore.Transaction, workNameTrend WorkNameTrendDoc) error
+	UpdateWorkNameTrend(ctx context.Context, tx Transaction, workNameTrend WorkNameTrendDoc) error
 
 	// General Operations
-	GetAllUserDocRefs(ctx context.Context) ([]*firestore.DocumentRef, error)
-	GetAllNonDailyZeroUserDocs(ctx context.Context) *firestore.DocumentIterator
-	ResetDailyTotalStudyTime(ctx context.Context, userRef *firestore.DocumentRef) error
+	GetAllUserDocRefs(ctx context.Context) ([]DocumentRef, error)
+	ReadUsersPage(ctx context.Context, startAfterUserID string, limit int) ([]UserDocWithID, error)
+	GetAllNonDailyZeroUserDocs(ctx context.Context) DocumentIterator
+	ResetDailyTotalStudyTime(ctx context.Context, userID string) error
+	GetAllDailyGoalAchievedUserDocs(ctx context.Context) DocumentIterator
+	ResetDailyGoalAchieved(ctx context.Context, userID string) error
 	UpdateLastResetDailyTotalStudyTime(ctx context.Context, timestamp time.Time) error
 	UpdateLastLongTimeSittingChecked(ctx context.Context, timestamp time.Time) error
 	UpdateLastTransferCollectionHistoryBigquery(ctx context.Context, timestamp time.Time) error
-	UpdateDesiredMaxSeats(ctx context.Context, tx *firestore.Transaction, desiredMaxSeats int) error
-	UpdateDesiredMemberMaxSeats(ctx context.Context, tx *firestore.Transaction, desiredMemberMaxSeats int) error
-	UpdateMaxSeats(ctx context.Context, tx *firestore.Transaction, maxSeats int) error
-	UpdateMemberMaxSeats(ctx context.Context, tx *firestore.Transaction, memberMaxSeats int) error
-	UpdateAccessTokenOfChannelCredential(ctx context.Context, tx *firestore.Transaction, accessToken string, expireDate time.Time) error
-	UpdateAccessTokenOfBotCredential(ctx context.Context, tx *firestore.Transaction, accessToken string, expireDate time.Time) error
+	UpdateDesiredMaxSeats(ctx context.Context, tx Transaction, desiredMaxSeats int) error
+	UpdateDesiredMemberMaxSeats(ctx context.Context, tx Transaction, desiredMemberMaxSeats int) error
+	UpdateMaxSeats(ctx context.Context, tx Transaction, maxSeats int) error
+	UpdateMemberMaxSeats(ctx context.Context, tx Transaction, memberMaxSeats int) error
+	UpdateAccessTokenOfChannelCredential(ctx context.Context, tx Transaction, accessToken string, expireDate time.Time) error
+	UpdateAccessTokenOfBotCredential(ctx context.Context, tx Transaction, accessToken string, expireDate time.Time) error
 }
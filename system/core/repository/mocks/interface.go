@@ -15,93 +15,94 @@ import (
 	time "time"
 
 	repository "app.modules/core/repository"
-	firestore "cloud.google.com/go/firestore"
 	gomock "go.uber.org/mock/gomock"
 )
 
-// MockDBClient is a mock of DBClient interface.
-type MockDBClient struct {
+// MockTransaction is a mock of Transaction interface.
+type MockTransaction struct {
 	ctrl     *gomock.Controller
-	recorder *MockDBClientMockRecorder
+	recorder *MockTransactionMockRecorder
 	isgomock struct{}
 }
 
-// MockDBClientMockRecorder is the mock recorder for MockDBClient.
-type MockDBClientMockRecorder struct {
-	mock *MockDBClient
+// MockTransactionMockRecorder is the mock recorder for MockTransaction.
+type MockTransactionMockRecorder struct {
+	mock *MockTransaction
 }
 
-// NewMockDBClient creates a new mock instance.
-func NewMockDBClient(ctrl *gomock.Controller) *MockDBClient {
-	mock := &MockDBClient{ctrl: ctrl}
-	mock.recorder = &MockDBClientMockRecorder{mock}
+// NewMockTransaction creates a new mock instance.
+func NewMockTransaction(ctrl *gomock.Controller) *MockTransaction {
+	mock := &MockTransaction{ctrl: ctrl}
+	mock.recorder = &MockTransactionMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockDBClient) EXPECT() *MockDBClientMockRecorder {
+func (m *MockTransaction) EXPECT() *MockTransactionMockRecorder {
 	return m.recorder
 }
 
-// Close mocks base method.
-func (m *MockDBClient) Close() error {
+// transaction mocks base method.
+func (m *MockTransaction) transaction() {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Close")
-	ret0, _ := ret[0].(error)
-	return ret0
+	m.ctrl.Call(m, "transaction")
 }
 
-// Close indicates an expected call of Close.
-func (mr *MockDBClientMockRecorder) Close() *gomock.Call {
+// transaction indicates an expected call of transaction.
+func (mr *MockTransactionMockRecorder) transaction() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockDBClient)(nil).Close))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "transaction", reflect.TypeOf((*MockTransaction)(nil).transaction))
 }
 
-// Collection mocks base method.
-func (m *MockDBClient) Collection(path string) *firestore.CollectionRef {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Collection", path)
-	ret0, _ := ret[0].(*firestore.CollectionRef)
-	return ret0
+// MockDocumentIterator is a mock of DocumentIterator interface.
+type MockDocumentIterator struct {
+	ctrl     *gomock.Controller
+	recorder *MockDocumentIteratorMockRecorder
+	isgomock struct{}
 }
 
-// Collection indicates an expected call of Collection.
-func (mr *MockDBClientMockRecorder) Collection(path any) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Collection", reflect.TypeOf((*MockDBClient)(nil).Collection), path)
+// MockDocumentIteratorMockRecorder is the mock recorder for MockDocumentIterator.
+type MockDocumentIteratorMockRecorder struct {
+	mock *MockDocumentIterator
 }
 
-// Doc mocks base method.
-func (m *MockDBClient) Doc(path string) *firestore.DocumentRef {
+// NewMockDocumentIterator creates a new mock instance.
+func NewMockDocumentIterator(ctrl *gomock.Controller) *MockDocumentIterator {
+	mock := &MockDocumentIterator{ctrl: ctrl}
+	mock.recorder = &MockDocumentIteratorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDocumentIterator) EXPECT() *MockDocumentIteratorMockRecorder {
+	return m.recorder
+}
+
+// Next mocks base method.
+func (m *MockDocumentIterator) Next() (repository.DocumentRef, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Doc", path)
-	ret0, _ := ret[0].(*firestore.DocumentRef)
-	return ret0
+	ret := m.ctrl.Call(m, "Next")
+	ret0, _ := ret[0].(repository.DocumentRef)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// Doc indicates an expected call of Doc.
-func (mr *MockDBClientMockRecorder) Doc(path any) *gomock.Call {
+// Next indicates an expected call of Next.
+func (mr *MockDocumentIteratorMockRecorder) Next() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Doc", reflect.TypeOf((*MockDBClient)(nil).Doc), path)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Next", reflect.TypeOf((*MockDocumentIterator)(nil).Next))
 }
 
-// RunTransaction mocks base method.
-func (m *MockDBClient) RunTransaction(ctx context.Context, f func(context.Context, *firestore.Transaction) error, opts ...firestore.TransactionOption) error {
+// Stop mocks base method.
+func (m *MockDocumentIterator) Stop() {
 	m.ctrl.T.Helper()
-	varargs := []any{ctx, f}
-	for _, a := range opts {
-		varargs = append(varargs, a)
-	}
-	ret := m.ctrl.Call(m, "RunTransaction", varargs...)
-	ret0, _ := ret[0].(error)
-	return ret0
+	m.ctrl.Call(m, "Stop")
 }
 
-// RunTransaction indicates an expected call of RunTransaction.
-func (mr *MockDBClientMockRecorder) RunTransaction(ctx, f any, opts ...any) *gomock.Call {
+// Stop indicates an expected call of Stop.
+func (mr *MockDocumentIteratorMockRecorder) Stop() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	varargs := append([]any{ctx, f}, opts...)
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunTransaction", reflect.TypeOf((*MockDBClient)(nil).RunTransaction), varargs...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stop", reflect.TypeOf((*MockDocumentIterator)(nil).Stop))
 }
 
 // MockRepository is a mock of Repository interface.
@@ -128,6 +129,48 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 	return m.recorder
 }
 
+// AddDailyUserWorkHistory mocks base method.
+func (m *MockRepository) AddDailyUserWorkHistory(ctx context.Context, tx repository.Transaction, userID string, date time.Time, studySec, breakSec int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddDailyUserWorkHistory", ctx, tx, userID, date, studySec, breakSec)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddDailyUserWorkHistory indicates an expected call of AddDailyUserWorkHistory.
+func (mr *MockRepositoryMockRecorder) AddDailyUserWorkHistory(ctx, tx, userID, date, studySec, breakSec any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddDailyUserWorkHistory", reflect.TypeOf((*MockRepository)(nil).AddDailyUserWorkHistory), ctx, tx, userID, date, studySec, breakSec)
+}
+
+// AddYoutubeAPIQuotaUsage mocks base method.
+func (m *MockRepository) AddYoutubeAPIQuotaUsage(ctx context.Context, date string, calls map[string]int, units int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddYoutubeAPIQuotaUsage", ctx, date, calls, units)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddYoutubeAPIQuotaUsage indicates an expected call of AddYoutubeAPIQuotaUsage.
+func (mr *MockRepositoryMockRecorder) AddYoutubeAPIQuotaUsage(ctx, date, calls, units any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddYoutubeAPIQuotaUsage", reflect.TypeOf((*MockRepository)(nil).AddYoutubeAPIQuotaUsage), ctx, date, calls, units)
+}
+
+// Close mocks base method.
+func (m *MockRepository) Close() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockRepositoryMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockRepository)(nil).Close))
+}
+
 // CountUserOrdersOfTheDay mocks base method.
 func (m *MockRepository) CountUserOrdersOfTheDay(ctx context.Context, userID string, date time.Time) (int64, error) {
 	m.ctrl.T.Helper()
@@ -143,8 +186,22 @@ func (mr *MockRepositoryMockRecorder) CountUserOrdersOfTheDay(ctx, userID, date
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUserOrdersOfTheDay", reflect.TypeOf((*MockRepository)(nil).CountUserOrdersOfTheDay), ctx, userID, date)
 }
 
+// CreateFanFundingHistoryDoc mocks base method.
+func (m *MockRepository) CreateFanFundingHistoryDoc(ctx context.Context, tx repository.Transaction, messageID string, history repository.FanFundingHistoryDoc) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFanFundingHistoryDoc", ctx, tx, messageID, history)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateFanFundingHistoryDoc indicates an expected call of CreateFanFundingHistoryDoc.
+func (mr *MockRepositoryMockRecorder) CreateFanFundingHistoryDoc(ctx, tx, messageID, history any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFanFundingHistoryDoc", reflect.TypeOf((*MockRepository)(nil).CreateFanFundingHistoryDoc), ctx, tx, messageID, history)
+}
+
 // CreateLiveChatHistoryDoc mocks base method.
-func (m *MockRepository) CreateLiveChatHistoryDoc(ctx context.Context, tx *firestore.Transaction, liveChatHistoryDoc repository.LiveChatHistoryDoc) error {
+func (m *MockRepository) CreateLiveChatHistoryDoc(ctx context.Context, tx repository.Transaction, liveChatHistoryDoc repository.LiveChatHistoryDoc) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CreateLiveChatHistoryDoc", ctx, tx, liveChatHistoryDoc)
 	ret0, _ := ret[0].(error)
@@ -157,8 +214,22 @@ func (mr *MockRepositoryMockRecorder) CreateLiveChatHistoryDoc(ctx, tx, liveChat
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLiveChatHistoryDoc", reflect.TypeOf((*MockRepository)(nil).CreateLiveChatHistoryDoc), ctx, tx, liveChatHistoryDoc)
 }
 
+// CreateModerationActionDoc mocks base method.
+func (m *MockRepository) CreateModerationActionDoc(ctx context.Context, tx repository.Transaction, action repository.ModerationActionDoc) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateModerationActionDoc", ctx, tx, action)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateModerationActionDoc indicates an expected call of CreateModerationActionDoc.
+func (mr *MockRepositoryMockRecorder) CreateModerationActionDoc(ctx, tx, action any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateModerationActionDoc", reflect.TypeOf((*MockRepository)(nil).CreateModerationActionDoc), ctx, tx, action)
+}
+
 // CreateOrderHistoryDoc mocks base method.
-func (m *MockRepository) CreateOrderHistoryDoc(ctx context.Context, tx *firestore.Transaction, orderHistoryDoc repository.OrderHistoryDoc) error {
+func (m *MockRepository) CreateOrderHistoryDoc(ctx context.Context, tx repository.Transaction, orderHistoryDoc repository.OrderHistoryDoc) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CreateOrderHistoryDoc", ctx, tx, orderHistoryDoc)
 	ret0, _ := ret[0].(error)
@@ -171,8 +242,22 @@ func (mr *MockRepositoryMockRecorder) CreateOrderHistoryDoc(ctx, tx, orderHistor
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrderHistoryDoc", reflect.TypeOf((*MockRepository)(nil).CreateOrderHistoryDoc), ctx, tx, orderHistoryDoc)
 }
 
+// CreateProcessedLiveChatMessage mocks base method.
+func (m *MockRepository) CreateProcessedLiveChatMessage(ctx context.Context, tx repository.Transaction, messageID string, processed repository.ProcessedLiveChatMessageDoc) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateProcessedLiveChatMessage", ctx, tx, messageID, processed)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateProcessedLiveChatMessage indicates an expected call of CreateProcessedLiveChatMessage.
+func (mr *MockRepositoryMockRecorder) CreateProcessedLiveChatMessage(ctx, tx, messageID, processed any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateProcessedLiveChatMessage", reflect.TypeOf((*MockRepository)(nil).CreateProcessedLiveChatMessage), ctx, tx, messageID, processed)
+}
+
 // CreateSeat mocks base method.
-func (m *MockRepository) CreateSeat(tx *firestore.Transaction, seat repository.SeatDoc, isMemberSeat bool) error {
+func (m *MockRepository) CreateSeat(tx repository.Transaction, seat repository.SeatDoc, isMemberSeat bool) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CreateSeat", tx, seat, isMemberSeat)
 	ret0, _ := ret[0].(error)
@@ -213,8 +298,22 @@ func (mr *MockRepositoryMockRecorder) CreateSeatLimitInWHITEList(ctx, seatID, us
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSeatLimitInWHITEList", reflect.TypeOf((*MockRepository)(nil).CreateSeatLimitInWHITEList), ctx, seatID, userID, createdAt, until, isMemberSeat)
 }
 
+// CreateSeatReservation mocks base method.
+func (m *MockRepository) CreateSeatReservation(ctx context.Context, tx repository.Transaction, reservation repository.SeatReservationDoc, isMemberSeat bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSeatReservation", ctx, tx, reservation, isMemberSeat)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateSeatReservation indicates an expected call of CreateSeatReservation.
+func (mr *MockRepositoryMockRecorder) CreateSeatReservation(ctx, tx, reservation, isMemberSeat any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSeatReservation", reflect.TypeOf((*MockRepository)(nil).CreateSeatReservation), ctx, tx, reservation, isMemberSeat)
+}
+
 // CreateUser mocks base method.
-func (m *MockRepository) CreateUser(ctx context.Context, tx *firestore.Transaction, userID string, userData repository.UserDoc) error {
+func (m *MockRepository) CreateUser(ctx context.Context, tx repository.Transaction, userID string, userData repository.UserDoc) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CreateUser", ctx, tx, userID, userData)
 	ret0, _ := ret[0].(error)
@@ -228,7 +327,7 @@ func (mr *MockRepositoryMockRecorder) CreateUser(ctx, tx, userID, userData any)
 }
 
 // CreateUserActivityDoc mocks base method.
-func (m *MockRepository) CreateUserActivityDoc(ctx context.Context, tx *firestore.Transaction, activity repository.UserActivityDoc) error {
+func (m *MockRepository) CreateUserActivityDoc(ctx context.Context, tx repository.Transaction, activity repository.UserActivityDoc) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CreateUserActivityDoc", ctx, tx, activity)
 	ret0, _ := ret[0].(error)
@@ -242,7 +341,7 @@ func (mr *MockRepositoryMockRecorder) CreateUserActivityDoc(ctx, tx, activity an
 }
 
 // CreateWorkSegmentDoc mocks base method.
-func (m *MockRepository) CreateWorkSegmentDoc(ctx context.Context, tx *firestore.Transaction, workSegment repository.WorkSegmentDoc) error {
+func (m *MockRepository) CreateWorkSegmentDoc(ctx context.Context, tx repository.Transaction, workSegment repository.WorkSegmentDoc) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CreateWorkSegmentDoc", ctx, tx, workSegment)
 	ret0, _ := ret[0].(error)
@@ -256,7 +355,7 @@ func (mr *MockRepositoryMockRecorder) CreateWorkSegmentDoc(ctx, tx, workSegment
 }
 
 // DeleteDocRef mocks base method.
-func (m *MockRepository) DeleteDocRef(ctx context.Context, tx *firestore.Transaction, ref *firestore.DocumentRef) error {
+func (m *MockRepository) DeleteDocRef(ctx context.Context, tx repository.Transaction, ref repository.DocumentRef) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "DeleteDocRef", ctx, tx, ref)
 	ret0, _ := ret[0].(error)
@@ -270,7 +369,7 @@ func (mr *MockRepositoryMockRecorder) DeleteDocRef(ctx, tx, ref any) *gomock.Cal
 }
 
 // DeleteSeat mocks base method.
-func (m *MockRepository) DeleteSeat(ctx context.Context, tx *firestore.Transaction, seatID int, isMemberSeat bool) error {
+func (m *MockRepository) DeleteSeat(ctx context.Context, tx repository.Transaction, seatID int, isMemberSeat bool) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "DeleteSeat", ctx, tx, seatID, isMemberSeat)
 	ret0, _ := ret[0].(error)
@@ -311,25 +410,39 @@ func (mr *MockRepositoryMockRecorder) DeleteSeatLimitInWHITEList(ctx, docID, isM
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSeatLimitInWHITEList", reflect.TypeOf((*MockRepository)(nil).DeleteSeatLimitInWHITEList), ctx, docID, isMemberSeat)
 }
 
-// FirestoreClient mocks base method.
-func (m *MockRepository) FirestoreClient() repository.DBClient {
+// DeleteSeatReservation mocks base method.
+func (m *MockRepository) DeleteSeatReservation(ctx context.Context, tx repository.Transaction, reservationID string, isMemberSeat bool) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FirestoreClient")
-	ret0, _ := ret[0].(repository.DBClient)
+	ret := m.ctrl.Call(m, "DeleteSeatReservation", ctx, tx, reservationID, isMemberSeat)
+	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// FirestoreClient indicates an expected call of FirestoreClient.
-func (mr *MockRepositoryMockRecorder) FirestoreClient() *gomock.Call {
+// DeleteSeatReservation indicates an expected call of DeleteSeatReservation.
+func (mr *MockRepositoryMockRecorder) DeleteSeatReservation(ctx, tx, reservationID, isMemberSeat any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FirestoreClient", reflect.TypeOf((*MockRepository)(nil).FirestoreClient))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSeatReservation", reflect.TypeOf((*MockRepository)(nil).DeleteSeatReservation), ctx, tx, reservationID, isMemberSeat)
+}
+
+// DeleteUndoableExit mocks base method.
+func (m *MockRepository) DeleteUndoableExit(ctx context.Context, tx repository.Transaction, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUndoableExit", ctx, tx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUndoableExit indicates an expected call of DeleteUndoableExit.
+func (mr *MockRepositoryMockRecorder) DeleteUndoableExit(ctx, tx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUndoableExit", reflect.TypeOf((*MockRepository)(nil).DeleteUndoableExit), ctx, tx, userID)
 }
 
 // Get500LiveChatHistoryDocIDsBeforeDate mocks base method.
-func (m *MockRepository) Get500LiveChatHistoryDocIDsBeforeDate(ctx context.Context, date time.Time) *firestore.DocumentIterator {
+func (m *MockRepository) Get500LiveChatHistoryDocIDsBeforeDate(ctx context.Context, date time.Time) repository.DocumentIterator {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Get500LiveChatHistoryDocIDsBeforeDate", ctx, date)
-	ret0, _ := ret[0].(*firestore.DocumentIterator)
+	ret0, _ := ret[0].(repository.DocumentIterator)
 	return ret0
 }
 
@@ -340,10 +453,10 @@ func (mr *MockRepositoryMockRecorder) Get500LiveChatHistoryDocIDsBeforeDate(ctx,
 }
 
 // Get500OrderHistoryDocIDsBeforeDate mocks base method.
-func (m *MockRepository) Get500OrderHistoryDocIDsBeforeDate(ctx context.Context, date time.Time) *firestore.DocumentIterator {
+func (m *MockRepository) Get500OrderHistoryDocIDsBeforeDate(ctx context.Context, date time.Time) repository.DocumentIterator {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Get500OrderHistoryDocIDsBeforeDate", ctx, date)
-	ret0, _ := ret[0].(*firestore.DocumentIterator)
+	ret0, _ := ret[0].(repository.DocumentIterator)
 	return ret0
 }
 
@@ -354,10 +467,10 @@ func (mr *MockRepositoryMockRecorder) Get500OrderHistoryDocIDsBeforeDate(ctx, da
 }
 
 // Get500SeatLimitsAfterUntilInBLACKList mocks base method.
-func (m *MockRepository) Get500SeatLimitsAfterUntilInBLACKList(ctx context.Context, thresholdTime time.Time, isMemberSeat bool) *firestore.DocumentIterator {
+func (m *MockRepository) Get500SeatLimitsAfterUntilInBLACKList(ctx context.Context, thresholdTime time.Time, isMemberSeat bool) repository.DocumentIterator {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Get500SeatLimitsAfterUntilInBLACKList", ctx, thresholdTime, isMemberSeat)
-	ret0, _ := ret[0].(*firestore.DocumentIterator)
+	ret0, _ := ret[0].(repository.DocumentIterator)
 	return ret0
 }
 
@@ -368,10 +481,10 @@ func (mr *MockRepositoryMockRecorder) Get500SeatLimitsAfterUntilInBLACKList(ctx,
 }
 
 // Get500SeatLimitsAfterUntilInWHITEList mocks base method.
-func (m *MockRepository) Get500SeatLimitsAfterUntilInWHITEList(ctx context.Context, thresholdTime time.Time, isMemberSeat bool) *firestore.DocumentIterator {
+func (m *MockRepository) Get500SeatLimitsAfterUntilInWHITEList(ctx context.Context, thresholdTime time.Time, isMemberSeat bool) repository.DocumentIterator {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Get500SeatLimitsAfterUntilInWHITEList", ctx, thresholdTime, isMemberSeat)
-	ret0, _ := ret[0].(*firestore.DocumentIterator)
+	ret0, _ := ret[0].(repository.DocumentIterator)
 	return ret0
 }
 
@@ -381,11 +494,25 @@ func (mr *MockRepositoryMockRecorder) Get500SeatLimitsAfterUntilInWHITEList(ctx,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get500SeatLimitsAfterUntilInWHITEList", reflect.TypeOf((*MockRepository)(nil).Get500SeatLimitsAfterUntilInWHITEList), ctx, thresholdTime, isMemberSeat)
 }
 
+// Get500UndoableExitDocIDsBeforeDate mocks base method.
+func (m *MockRepository) Get500UndoableExitDocIDsBeforeDate(ctx context.Context, date time.Time) repository.DocumentIterator {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get500UndoableExitDocIDsBeforeDate", ctx, date)
+	ret0, _ := ret[0].(repository.DocumentIterator)
+	return ret0
+}
+
+// Get500UndoableExitDocIDsBeforeDate indicates an expected call of Get500UndoableExitDocIDsBeforeDate.
+func (mr *MockRepositoryMockRecorder) Get500UndoableExitDocIDsBeforeDate(ctx, date any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get500UndoableExitDocIDsBeforeDate", reflect.TypeOf((*MockRepository)(nil).Get500UndoableExitDocIDsBeforeDate), ctx, date)
+}
+
 // Get500UserActivityDocIDsBeforeDate mocks base method.
-func (m *MockRepository) Get500UserActivityDocIDsBeforeDate(ctx context.Context, date time.Time) *firestore.DocumentIterator {
+func (m *MockRepository) Get500UserActivityDocIDsBeforeDate(ctx context.Context, date time.Time) repository.DocumentIterator {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Get500UserActivityDocIDsBeforeDate", ctx, date)
-	ret0, _ := ret[0].(*firestore.DocumentIterator)
+	ret0, _ := ret[0].(repository.DocumentIterator)
 	return ret0
 }
 
@@ -395,11 +522,25 @@ func (mr *MockRepositoryMockRecorder) Get500UserActivityDocIDsBeforeDate(ctx, da
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get500UserActivityDocIDsBeforeDate", reflect.TypeOf((*MockRepository)(nil).Get500UserActivityDocIDsBeforeDate), ctx, date)
 }
 
+// GetAllDailyGoalAchievedUserDocs mocks base method.
+func (m *MockRepository) GetAllDailyGoalAchievedUserDocs(ctx context.Context) repository.DocumentIterator {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllDailyGoalAchievedUserDocs", ctx)
+	ret0, _ := ret[0].(repository.DocumentIterator)
+	return ret0
+}
+
+// GetAllDailyGoalAchievedUserDocs indicates an expected call of GetAllDailyGoalAchievedUserDocs.
+func (mr *MockRepositoryMockRecorder) GetAllDailyGoalAchievedUserDocs(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllDailyGoalAchievedUserDocs", reflect.TypeOf((*MockRepository)(nil).GetAllDailyGoalAchievedUserDocs), ctx)
+}
+
 // GetAllNonDailyZeroUserDocs mocks base method.
-func (m *MockRepository) GetAllNonDailyZeroUserDocs(ctx context.Context) *firestore.DocumentIterator {
+func (m *MockRepository) GetAllNonDailyZeroUserDocs(ctx context.Context) repository.DocumentIterator {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetAllNonDailyZeroUserDocs", ctx)
-	ret0, _ := ret[0].(*firestore.DocumentIterator)
+	ret0, _ := ret[0].(repository.DocumentIterator)
 	return ret0
 }
 
@@ -410,10 +551,10 @@ func (mr *MockRepositoryMockRecorder) GetAllNonDailyZeroUserDocs(ctx any) *gomoc
 }
 
 // GetAllUserActivityDocIDsAfterDate mocks base method.
-func (m *MockRepository) GetAllUserActivityDocIDsAfterDate(ctx context.Context, date time.Time) *firestore.DocumentIterator {
+func (m *MockRepository) GetAllUserActivityDocIDsAfterDate(ctx context.Context, date time.Time) repository.DocumentIterator {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetAllUserActivityDocIDsAfterDate", ctx, date)
-	ret0, _ := ret[0].(*firestore.DocumentIterator)
+	ret0, _ := ret[0].(repository.DocumentIterator)
 	return ret0
 }
 
@@ -424,10 +565,10 @@ func (mr *MockRepositoryMockRecorder) GetAllUserActivityDocIDsAfterDate(ctx, dat
 }
 
 // GetAllUserDocRefs mocks base method.
-func (m *MockRepository) GetAllUserDocRefs(ctx context.Context) ([]*firestore.DocumentRef, error) {
+func (m *MockRepository) GetAllUserDocRefs(ctx context.Context) ([]repository.DocumentRef, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetAllUserDocRefs", ctx)
-	ret0, _ := ret[0].([]*firestore.DocumentRef)
+	ret0, _ := ret[0].([]repository.DocumentRef)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -469,10 +610,10 @@ func (mr *MockRepositoryMockRecorder) GetExitRoomUserActivityDocIDsAfterDateForU
 }
 
 // GetUsersActiveAfterDate mocks base method.
-func (m *MockRepository) GetUsersActiveAfterDate(ctx context.Context, date time.Time) *firestore.DocumentIterator {
+func (m *MockRepository) GetUsersActiveAfterDate(ctx context.Context, date time.Time) repository.DocumentIterator {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetUsersActiveAfterDate", ctx, date)
-	ret0, _ := ret[0].(*firestore.DocumentIterator)
+	ret0, _ := ret[0].(repository.DocumentIterator)
 	return ret0
 }
 
@@ -513,7 +654,7 @@ func (mr *MockRepositoryMockRecorder) ReadAllMenuDocsOrderByCode(ctx any) *gomoc
 }
 
 // ReadCredentialsConfig mocks base method.
-func (m *MockRepository) ReadCredentialsConfig(ctx context.Context, tx *firestore.Transaction) (repository.CredentialsConfigDoc, error) {
+func (m *MockRepository) ReadCredentialsConfig(ctx context.Context, tx repository.Transaction) (repository.CredentialsConfigDoc, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ReadCredentialsConfig", ctx, tx)
 	ret0, _ := ret[0].(repository.CredentialsConfigDoc)
@@ -527,6 +668,36 @@ func (mr *MockRepositoryMockRecorder) ReadCredentialsConfig(ctx, tx any) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadCredentialsConfig", reflect.TypeOf((*MockRepository)(nil).ReadCredentialsConfig), ctx, tx)
 }
 
+// ReadDailyUserWorkHistory mocks base method.
+func (m *MockRepository) ReadDailyUserWorkHistory(ctx context.Context, tx repository.Transaction, userID string, date time.Time) (repository.DailyUserWorkHistoryDoc, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadDailyUserWorkHistory", ctx, tx, userID, date)
+	ret0, _ := ret[0].(repository.DailyUserWorkHistoryDoc)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadDailyUserWorkHistory indicates an expected call of ReadDailyUserWorkHistory.
+func (mr *MockRepositoryMockRecorder) ReadDailyUserWorkHistory(ctx, tx, userID, date any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadDailyUserWorkHistory", reflect.TypeOf((*MockRepository)(nil).ReadDailyUserWorkHistory), ctx, tx, userID, date)
+}
+
+// ReadFanFundingHistoryByUserID mocks base method.
+func (m *MockRepository) ReadFanFundingHistoryByUserID(ctx context.Context, userID string) ([]repository.FanFundingHistoryDoc, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadFanFundingHistoryByUserID", ctx, userID)
+	ret0, _ := ret[0].([]repository.FanFundingHistoryDoc)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadFanFundingHistoryByUserID indicates an expected call of ReadFanFundingHistoryByUserID.
+func (mr *MockRepositoryMockRecorder) ReadFanFundingHistoryByUserID(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadFanFundingHistoryByUserID", reflect.TypeOf((*MockRepository)(nil).ReadFanFundingHistoryByUserID), ctx, userID)
+}
+
 // ReadGeneralSeats mocks base method.
 func (m *MockRepository) ReadGeneralSeats(ctx context.Context) ([]repository.SeatDoc, error) {
 	m.ctrl.T.Helper()
@@ -543,7 +714,7 @@ func (mr *MockRepositoryMockRecorder) ReadGeneralSeats(ctx any) *gomock.Call {
 }
 
 // ReadLiveChatID mocks base method.
-func (m *MockRepository) ReadLiveChatID(ctx context.Context, tx *firestore.Transaction) (string, error) {
+func (m *MockRepository) ReadLiveChatID(ctx context.Context, tx repository.Transaction) (string, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ReadLiveChatID", ctx, tx)
 	ret0, _ := ret[0].(string)
@@ -572,8 +743,23 @@ func (mr *MockRepositoryMockRecorder) ReadMemberSeats(ctx any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadMemberSeats", reflect.TypeOf((*MockRepository)(nil).ReadMemberSeats), ctx)
 }
 
+// ReadModerationActionsByTargetUserID mocks base method.
+func (m *MockRepository) ReadModerationActionsByTargetUserID(ctx context.Context, userID string) ([]repository.ModerationActionDoc, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadModerationActionsByTargetUserID", ctx, userID)
+	ret0, _ := ret[0].([]repository.ModerationActionDoc)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadModerationActionsByTargetUserID indicates an expected call of ReadModerationActionsByTargetUserID.
+func (mr *MockRepositoryMockRecorder) ReadModerationActionsByTargetUserID(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadModerationActionsByTargetUserID", reflect.TypeOf((*MockRepository)(nil).ReadModerationActionsByTargetUserID), ctx, userID)
+}
+
 // ReadNextPageToken mocks base method.
-func (m *MockRepository) ReadNextPageToken(ctx context.Context, tx *firestore.Transaction) (string, error) {
+func (m *MockRepository) ReadNextPageToken(ctx context.Context, tx repository.Transaction) (string, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ReadNextPageToken", ctx, tx)
 	ret0, _ := ret[0].(string)
@@ -587,8 +773,23 @@ func (mr *MockRepositoryMockRecorder) ReadNextPageToken(ctx, tx any) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadNextPageToken", reflect.TypeOf((*MockRepository)(nil).ReadNextPageToken), ctx, tx)
 }
 
+// ReadProcessedLiveChatMessage mocks base method.
+func (m *MockRepository) ReadProcessedLiveChatMessage(ctx context.Context, tx repository.Transaction, messageID string) (repository.ProcessedLiveChatMessageDoc, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadProcessedLiveChatMessage", ctx, tx, messageID)
+	ret0, _ := ret[0].(repository.ProcessedLiveChatMessageDoc)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadProcessedLiveChatMessage indicates an expected call of ReadProcessedLiveChatMessage.
+func (mr *MockRepositoryMockRecorder) ReadProcessedLiveChatMessage(ctx, tx, messageID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadProcessedLiveChatMessage", reflect.TypeOf((*MockRepository)(nil).ReadProcessedLiveChatMessage), ctx, tx, messageID)
+}
+
 // ReadSeat mocks base method.
-func (m *MockRepository) ReadSeat(ctx context.Context, tx *firestore.Transaction, seatID int, isMemberSeat bool) (repository.SeatDoc, error) {
+func (m *MockRepository) ReadSeat(ctx context.Context, tx repository.Transaction, seatID int, isMemberSeat bool) (repository.SeatDoc, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ReadSeat", ctx, tx, seatID, isMemberSeat)
 	ret0, _ := ret[0].(repository.SeatDoc)
@@ -632,6 +833,51 @@ func (mr *MockRepositoryMockRecorder) ReadSeatLimitsWHITEListWithSeatIDAndUserID
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadSeatLimitsWHITEListWithSeatIDAndUserID", reflect.TypeOf((*MockRepository)(nil).ReadSeatLimitsWHITEListWithSeatIDAndUserID), ctx, seatID, userID, isMemberSeat)
 }
 
+// ReadSeatReservationsStartBefore mocks base method.
+func (m *MockRepository) ReadSeatReservationsStartBefore(ctx context.Context, thresholdTime time.Time, isMemberSeat bool, limit int) ([]repository.SeatReservationDoc, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadSeatReservationsStartBefore", ctx, thresholdTime, isMemberSeat, limit)
+	ret0, _ := ret[0].([]repository.SeatReservationDoc)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadSeatReservationsStartBefore indicates an expected call of ReadSeatReservationsStartBefore.
+func (mr *MockRepositoryMockRecorder) ReadSeatReservationsStartBefore(ctx, thresholdTime, isMemberSeat, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadSeatReservationsStartBefore", reflect.TypeOf((*MockRepository)(nil).ReadSeatReservationsStartBefore), ctx, thresholdTime, isMemberSeat, limit)
+}
+
+// ReadSeatReservationsWithSeatID mocks base method.
+func (m *MockRepository) ReadSeatReservationsWithSeatID(ctx context.Context, tx repository.Transaction, seatID int, isMemberSeat bool) ([]repository.SeatReservationDoc, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadSeatReservationsWithSeatID", ctx, tx, seatID, isMemberSeat)
+	ret0, _ := ret[0].([]repository.SeatReservationDoc)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadSeatReservationsWithSeatID indicates an expected call of ReadSeatReservationsWithSeatID.
+func (mr *MockRepositoryMockRecorder) ReadSeatReservationsWithSeatID(ctx, tx, seatID, isMemberSeat any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadSeatReservationsWithSeatID", reflect.TypeOf((*MockRepository)(nil).ReadSeatReservationsWithSeatID), ctx, tx, seatID, isMemberSeat)
+}
+
+// ReadSeatReservationsWithUserID mocks base method.
+func (m *MockRepository) ReadSeatReservationsWithUserID(ctx context.Context, tx repository.Transaction, userID string, isMemberSeat bool) ([]repository.SeatReservationDoc, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadSeatReservationsWithUserID", ctx, tx, userID, isMemberSeat)
+	ret0, _ := ret[0].([]repository.SeatReservationDoc)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadSeatReservationsWithUserID indicates an expected call of ReadSeatReservationsWithUserID.
+func (mr *MockRepositoryMockRecorder) ReadSeatReservationsWithUserID(ctx, tx, userID, isMemberSeat any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadSeatReservationsWithUserID", reflect.TypeOf((*MockRepository)(nil).ReadSeatReservationsWithUserID), ctx, tx, userID, isMemberSeat)
+}
+
 // ReadSeatWithUserID mocks base method.
 func (m *MockRepository) ReadSeatWithUserID(ctx context.Context, userID string, isMemberSeat bool) (repository.SeatDoc, error) {
 	m.ctrl.T.Helper()
@@ -677,8 +923,23 @@ func (mr *MockRepositoryMockRecorder) ReadSeatsExpiredUntil(ctx, thresholdTime,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadSeatsExpiredUntil", reflect.TypeOf((*MockRepository)(nil).ReadSeatsExpiredUntil), ctx, thresholdTime, isMemberSeat)
 }
 
+// ReadSeatsExpiredWorkUntil mocks base method.
+func (m *MockRepository) ReadSeatsExpiredWorkUntil(ctx context.Context, thresholdTime time.Time, isMemberSeat bool) ([]repository.SeatDoc, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadSeatsExpiredWorkUntil", ctx, thresholdTime, isMemberSeat)
+	ret0, _ := ret[0].([]repository.SeatDoc)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadSeatsExpiredWorkUntil indicates an expected call of ReadSeatsExpiredWorkUntil.
+func (mr *MockRepositoryMockRecorder) ReadSeatsExpiredWorkUntil(ctx, thresholdTime, isMemberSeat any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadSeatsExpiredWorkUntil", reflect.TypeOf((*MockRepository)(nil).ReadSeatsExpiredWorkUntil), ctx, thresholdTime, isMemberSeat)
+}
+
 // ReadSystemConstantsConfig mocks base method.
-func (m *MockRepository) ReadSystemConstantsConfig(ctx context.Context, tx *firestore.Transaction) (repository.ConstantsConfigDoc, error) {
+func (m *MockRepository) ReadSystemConstantsConfig(ctx context.Context, tx repository.Transaction) (repository.ConstantsConfigDoc, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ReadSystemConstantsConfig", ctx, tx)
 	ret0, _ := ret[0].(repository.ConstantsConfigDoc)
@@ -692,8 +953,23 @@ func (mr *MockRepositoryMockRecorder) ReadSystemConstantsConfig(ctx, tx any) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadSystemConstantsConfig", reflect.TypeOf((*MockRepository)(nil).ReadSystemConstantsConfig), ctx, tx)
 }
 
+// ReadUndoableExit mocks base method.
+func (m *MockRepository) ReadUndoableExit(ctx context.Context, tx repository.Transaction, userID string) (repository.UndoableExitDoc, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadUndoableExit", ctx, tx, userID)
+	ret0, _ := ret[0].(repository.UndoableExitDoc)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadUndoableExit indicates an expected call of ReadUndoableExit.
+func (mr *MockRepositoryMockRecorder) ReadUndoableExit(ctx, tx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadUndoableExit", reflect.TypeOf((*MockRepository)(nil).ReadUndoableExit), ctx, tx, userID)
+}
+
 // ReadUser mocks base method.
-func (m *MockRepository) ReadUser(ctx context.Context, tx *firestore.Transaction, userID string) (repository.UserDoc, error) {
+func (m *MockRepository) ReadUser(ctx context.Context, tx repository.Transaction, userID string) (repository.UserDoc, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ReadUser", ctx, tx, userID)
 	ret0, _ := ret[0].(repository.UserDoc)
@@ -707,6 +983,51 @@ func (mr *MockRepositoryMockRecorder) ReadUser(ctx, tx, userID any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadUser", reflect.TypeOf((*MockRepository)(nil).ReadUser), ctx, tx, userID)
 }
 
+// ReadUsersPage mocks base method.
+func (m *MockRepository) ReadUsersPage(ctx context.Context, startAfterUserID string, limit int) ([]repository.UserDocWithID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadUsersPage", ctx, startAfterUserID, limit)
+	ret0, _ := ret[0].([]repository.UserDocWithID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadUsersPage indicates an expected call of ReadUsersPage.
+func (mr *MockRepositoryMockRecorder) ReadUsersPage(ctx, startAfterUserID, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadUsersPage", reflect.TypeOf((*MockRepository)(nil).ReadUsersPage), ctx, startAfterUserID, limit)
+}
+
+// ReadWorkSegmentsByUserIDAndTimeRange mocks base method.
+func (m *MockRepository) ReadWorkSegmentsByUserIDAndTimeRange(ctx context.Context, userID string, from, to time.Time, limit int) ([]repository.WorkSegmentDoc, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadWorkSegmentsByUserIDAndTimeRange", ctx, userID, from, to, limit)
+	ret0, _ := ret[0].([]repository.WorkSegmentDoc)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadWorkSegmentsByUserIDAndTimeRange indicates an expected call of ReadWorkSegmentsByUserIDAndTimeRange.
+func (mr *MockRepositoryMockRecorder) ReadWorkSegmentsByUserIDAndTimeRange(ctx, userID, from, to, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadWorkSegmentsByUserIDAndTimeRange", reflect.TypeOf((*MockRepository)(nil).ReadWorkSegmentsByUserIDAndTimeRange), ctx, userID, from, to, limit)
+}
+
+// ReadWorkSegmentsPageByTimeRange mocks base method.
+func (m *MockRepository) ReadWorkSegmentsPageByTimeRange(ctx context.Context, from, to time.Time, startAfter repository.WorkSegmentDocWithID, limit int) ([]repository.WorkSegmentDocWithID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadWorkSegmentsPageByTimeRange", ctx, from, to, startAfter, limit)
+	ret0, _ := ret[0].([]repository.WorkSegmentDocWithID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadWorkSegmentsPageByTimeRange indicates an expected call of ReadWorkSegmentsPageByTimeRange.
+func (mr *MockRepositoryMockRecorder) ReadWorkSegmentsPageByTimeRange(ctx, from, to, startAfter, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadWorkSegmentsPageByTimeRange", reflect.TypeOf((*MockRepository)(nil).ReadWorkSegmentsPageByTimeRange), ctx, from, to, startAfter, limit)
+}
+
 // ReadWorkStateSegmentsBySessionID mocks base method.
 func (m *MockRepository) ReadWorkStateSegmentsBySessionID(ctx context.Context, sessionID string) ([]repository.WorkSegmentDoc, error) {
 	m.ctrl.T.Helper()
@@ -722,22 +1043,93 @@ func (mr *MockRepositoryMockRecorder) ReadWorkStateSegmentsBySessionID(ctx, sess
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadWorkStateSegmentsBySessionID", reflect.TypeOf((*MockRepository)(nil).ReadWorkStateSegmentsBySessionID), ctx, sessionID)
 }
 
+// ReadYoutubeAPIQuotaUsage mocks base method.
+func (m *MockRepository) ReadYoutubeAPIQuotaUsage(ctx context.Context, date string) (repository.YoutubeAPIQuotaUsageDoc, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadYoutubeAPIQuotaUsage", ctx, date)
+	ret0, _ := ret[0].(repository.YoutubeAPIQuotaUsageDoc)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadYoutubeAPIQuotaUsage indicates an expected call of ReadYoutubeAPIQuotaUsage.
+func (mr *MockRepositoryMockRecorder) ReadYoutubeAPIQuotaUsage(ctx, date any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadYoutubeAPIQuotaUsage", reflect.TypeOf((*MockRepository)(nil).ReadYoutubeAPIQuotaUsage), ctx, date)
+}
+
+// ResetDailyGoalAchieved mocks base method.
+func (m *MockRepository) ResetDailyGoalAchieved(ctx context.Context, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetDailyGoalAchieved", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetDailyGoalAchieved indicates an expected call of ResetDailyGoalAchieved.
+func (mr *MockRepositoryMockRecorder) ResetDailyGoalAchieved(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetDailyGoalAchieved", reflect.TypeOf((*MockRepository)(nil).ResetDailyGoalAchieved), ctx, userID)
+}
+
 // ResetDailyTotalStudyTime mocks base method.
-func (m *MockRepository) ResetDailyTotalStudyTime(ctx context.Context, userRef *firestore.DocumentRef) error {
+func (m *MockRepository) ResetDailyTotalStudyTime(ctx context.Context, userID string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ResetDailyTotalStudyTime", ctx, userRef)
+	ret := m.ctrl.Call(m, "ResetDailyTotalStudyTime", ctx, userID)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // ResetDailyTotalStudyTime indicates an expected call of ResetDailyTotalStudyTime.
-func (mr *MockRepositoryMockRecorder) ResetDailyTotalStudyTime(ctx, userRef any) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) ResetDailyTotalStudyTime(ctx, userID any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetDailyTotalStudyTime", reflect.TypeOf((*MockRepository)(nil).ResetDailyTotalStudyTime), ctx, userRef)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetDailyTotalStudyTime", reflect.TypeOf((*MockRepository)(nil).ResetDailyTotalStudyTime), ctx, userID)
+}
+
+// RunTransaction mocks base method.
+func (m *MockRepository) RunTransaction(ctx context.Context, f func(context.Context, repository.Transaction) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RunTransaction", ctx, f)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RunTransaction indicates an expected call of RunTransaction.
+func (mr *MockRepositoryMockRecorder) RunTransaction(ctx, f any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunTransaction", reflect.TypeOf((*MockRepository)(nil).RunTransaction), ctx, f)
+}
+
+// SetDailyUserWorkHistory mocks base method.
+func (m *MockRepository) SetDailyUserWorkHistory(ctx context.Context, tx repository.Transaction, history repository.DailyUserWorkHistoryDoc) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetDailyUserWorkHistory", ctx, tx, history)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetDailyUserWorkHistory indicates an expected call of SetDailyUserWorkHistory.
+func (mr *MockRepositoryMockRecorder) SetDailyUserWorkHistory(ctx, tx, history any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDailyUserWorkHistory", reflect.TypeOf((*MockRepository)(nil).SetDailyUserWorkHistory), ctx, tx, history)
+}
+
+// SetUndoableExit mocks base method.
+func (m *MockRepository) SetUndoableExit(ctx context.Context, tx repository.Transaction, undoableExit repository.UndoableExitDoc) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUndoableExit", ctx, tx, undoableExit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetUndoableExit indicates an expected call of SetUndoableExit.
+func (mr *MockRepositoryMockRecorder) SetUndoableExit(ctx, tx, undoableExit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUndoableExit", reflect.TypeOf((*MockRepository)(nil).SetUndoableExit), ctx, tx, undoableExit)
 }
 
 // UpdateAccessTokenOfBotCredential mocks base method.
-func (m *MockRepository) UpdateAccessTokenOfBotCredential(ctx context.Context, tx *firestore.Transaction, accessToken string, expireDate time.Time) error {
+func (m *MockRepository) UpdateAccessTokenOfBotCredential(ctx context.Context, tx repository.Transaction, accessToken string, expireDate time.Time) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UpdateAccessTokenOfBotCredential", ctx, tx, accessToken, expireDate)
 	ret0, _ := ret[0].(error)
@@ -751,7 +1143,7 @@ func (mr *MockRepositoryMockRecorder) UpdateAccessTokenOfBotCredential(ctx, tx,
 }
 
 // UpdateAccessTokenOfChannelCredential mocks base method.
-func (m *MockRepository) UpdateAccessTokenOfChannelCredential(ctx context.Context, tx *firestore.Transaction, accessToken string, expireDate time.Time) error {
+func (m *MockRepository) UpdateAccessTokenOfChannelCredential(ctx context.Context, tx repository.Transaction, accessToken string, expireDate time.Time) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UpdateAccessTokenOfChannelCredential", ctx, tx, accessToken, expireDate)
 	ret0, _ := ret[0].(error)
@@ -765,7 +1157,7 @@ func (mr *MockRepositoryMockRecorder) UpdateAccessTokenOfChannelCredential(ctx,
 }
 
 // UpdateDesiredMaxSeats mocks base method.
-func (m *MockRepository) UpdateDesiredMaxSeats(ctx context.Context, tx *firestore.Transaction, desiredMaxSeats int) error {
+func (m *MockRepository) UpdateDesiredMaxSeats(ctx context.Context, tx repository.Transaction, desiredMaxSeats int) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UpdateDesiredMaxSeats", ctx, tx, desiredMaxSeats)
 	ret0, _ := ret[0].(error)
@@ -779,7 +1171,7 @@ func (mr *MockRepositoryMockRecorder) UpdateDesiredMaxSeats(ctx, tx, desiredMaxS
 }
 
 // UpdateDesiredMemberMaxSeats mocks base method.
-func (m *MockRepository) UpdateDesiredMemberMaxSeats(ctx context.Context, tx *firestore.Transaction, desiredMemberMaxSeats int) error {
+func (m *MockRepository) UpdateDesiredMemberMaxSeats(ctx context.Context, tx repository.Transaction, desiredMemberMaxSeats int) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UpdateDesiredMemberMaxSeats", ctx, tx, desiredMemberMaxSeats)
 	ret0, _ := ret[0].(error)
@@ -835,7 +1227,7 @@ func (mr *MockRepositoryMockRecorder) UpdateLastTransferCollectionHistoryBigquer
 }
 
 // UpdateLiveChatID mocks base method.
-func (m *MockRepository) UpdateLiveChatID(ctx context.Context, tx *firestore.Transaction, liveChatID string) error {
+func (m *MockRepository) UpdateLiveChatID(ctx context.Context, tx repository.Transaction, liveChatID string) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UpdateLiveChatID", ctx, tx, liveChatID)
 	ret0, _ := ret[0].(error)
@@ -849,7 +1241,7 @@ func (mr *MockRepositoryMockRecorder) UpdateLiveChatID(ctx, tx, liveChatID any)
 }
 
 // UpdateMaxSeats mocks base method.
-func (m *MockRepository) UpdateMaxSeats(ctx context.Context, tx *firestore.Transaction, maxSeats int) error {
+func (m *MockRepository) UpdateMaxSeats(ctx context.Context, tx repository.Transaction, maxSeats int) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UpdateMaxSeats", ctx, tx, maxSeats)
 	ret0, _ := ret[0].(error)
@@ -863,7 +1255,7 @@ func (mr *MockRepositoryMockRecorder) UpdateMaxSeats(ctx, tx, maxSeats any) *gom
 }
 
 // UpdateMemberMaxSeats mocks base method.
-func (m *MockRepository) UpdateMemberMaxSeats(ctx context.Context, tx *firestore.Transaction, memberMaxSeats int) error {
+func (m *MockRepository) UpdateMemberMaxSeats(ctx context.Context, tx repository.Transaction, memberMaxSeats int) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UpdateMemberMaxSeats", ctx, tx, memberMaxSeats)
 	ret0, _ := ret[0].(error)
@@ -891,7 +1283,7 @@ func (mr *MockRepositoryMockRecorder) UpdateNextPageToken(ctx, nextPageToken any
 }
 
 // UpdateSeat mocks base method.
-func (m *MockRepository) UpdateSeat(ctx context.Context, tx *firestore.Transaction, seat repository.SeatDoc, isMemberSeat bool) error {
+func (m *MockRepository) UpdateSeat(ctx context.Context, tx repository.Transaction, seat repository.SeatDoc, isMemberSeat bool) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UpdateSeat", ctx, tx, seat, isMemberSeat)
 	ret0, _ := ret[0].(error)
@@ -904,8 +1296,50 @@ func (mr *MockRepositoryMockRecorder) UpdateSeat(ctx, tx, seat, isMemberSeat any
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSeat", reflect.TypeOf((*MockRepository)(nil).UpdateSeat), ctx, tx, seat, isMemberSeat)
 }
 
+// UpdateUserBestStreakDays mocks base method.
+func (m *MockRepository) UpdateUserBestStreakDays(ctx context.Context, tx repository.Transaction, userID string, bestStreakDays int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserBestStreakDays", ctx, tx, userID, bestStreakDays)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserBestStreakDays indicates an expected call of UpdateUserBestStreakDays.
+func (mr *MockRepositoryMockRecorder) UpdateUserBestStreakDays(ctx, tx, userID, bestStreakDays any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserBestStreakDays", reflect.TypeOf((*MockRepository)(nil).UpdateUserBestStreakDays), ctx, tx, userID, bestStreakDays)
+}
+
+// UpdateUserDailyGoalAchieved mocks base method.
+func (m *MockRepository) UpdateUserDailyGoalAchieved(tx repository.Transaction, userID string, achieved bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserDailyGoalAchieved", tx, userID, achieved)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserDailyGoalAchieved indicates an expected call of UpdateUserDailyGoalAchieved.
+func (mr *MockRepositoryMockRecorder) UpdateUserDailyGoalAchieved(tx, userID, achieved any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserDailyGoalAchieved", reflect.TypeOf((*MockRepository)(nil).UpdateUserDailyGoalAchieved), tx, userID, achieved)
+}
+
+// UpdateUserDailyGoalMin mocks base method.
+func (m *MockRepository) UpdateUserDailyGoalMin(tx repository.Transaction, userID string, dailyGoalMin int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserDailyGoalMin", tx, userID, dailyGoalMin)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserDailyGoalMin indicates an expected call of UpdateUserDailyGoalMin.
+func (mr *MockRepositoryMockRecorder) UpdateUserDailyGoalMin(tx, userID, dailyGoalMin any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserDailyGoalMin", reflect.TypeOf((*MockRepository)(nil).UpdateUserDailyGoalMin), tx, userID, dailyGoalMin)
+}
+
 // UpdateUserDefaultStudyMin mocks base method.
-func (m *MockRepository) UpdateUserDefaultStudyMin(tx *firestore.Transaction, userID string, defaultStudyMin int) error {
+func (m *MockRepository) UpdateUserDefaultStudyMin(tx repository.Transaction, userID string, defaultStudyMin int) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UpdateUserDefaultStudyMin", tx, userID, defaultStudyMin)
 	ret0, _ := ret[0].(error)
@@ -919,7 +1353,7 @@ func (mr *MockRepositoryMockRecorder) UpdateUserDefaultStudyMin(tx, userID, defa
 }
 
 // UpdateUserFavoriteColor mocks base method.
-func (m *MockRepository) UpdateUserFavoriteColor(tx *firestore.Transaction, userID, colorCode string) error {
+func (m *MockRepository) UpdateUserFavoriteColor(tx repository.Transaction, userID, colorCode string) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UpdateUserFavoriteColor", tx, userID, colorCode)
 	ret0, _ := ret[0].(error)
@@ -933,7 +1367,7 @@ func (mr *MockRepositoryMockRecorder) UpdateUserFavoriteColor(tx, userID, colorC
 }
 
 // UpdateUserIsContinuousActiveAndCurrentActivityStateStarted mocks base method.
-func (m *MockRepository) UpdateUserIsContinuousActiveAndCurrentActivityStateStarted(ctx context.Context, tx *firestore.Transaction, userID string, isContinuousActive bool, currentActivityStateStarted time.Time) error {
+func (m *MockRepository) UpdateUserIsContinuousActiveAndCurrentActivityStateStarted(ctx context.Context, tx repository.Transaction, userID string, isContinuousActive bool, currentActivityStateStarted time.Time) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UpdateUserIsContinuousActiveAndCurrentActivityStateStarted", ctx, tx, userID, isContinuousActive, currentActivityStateStarted)
 	ret0, _ := ret[0].(error)
@@ -947,7 +1381,7 @@ func (mr *MockRepositoryMockRecorder) UpdateUserIsContinuousActiveAndCurrentActi
 }
 
 // UpdateUserLastEnteredDate mocks base method.
-func (m *MockRepository) UpdateUserLastEnteredDate(tx *firestore.Transaction, userID string, enteredDate time.Time) error {
+func (m *MockRepository) UpdateUserLastEnteredDate(tx repository.Transaction, userID string, enteredDate time.Time) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UpdateUserLastEnteredDate", tx, userID, enteredDate)
 	ret0, _ := ret[0].(error)
@@ -961,7 +1395,7 @@ func (mr *MockRepositoryMockRecorder) UpdateUserLastEnteredDate(tx, userID, ente
 }
 
 // UpdateUserLastExitedDate mocks base method.
-func (m *MockRepository) UpdateUserLastExitedDate(tx *firestore.Transaction, userID string, exitedDate time.Time) error {
+func (m *MockRepository) UpdateUserLastExitedDate(tx repository.Transaction, userID string, exitedDate time.Time) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UpdateUserLastExitedDate", tx, userID, exitedDate)
 	ret0, _ := ret[0].(error)
@@ -975,7 +1409,7 @@ func (mr *MockRepositoryMockRecorder) UpdateUserLastExitedDate(tx, userID, exite
 }
 
 // UpdateUserLastPenaltyImposedDays mocks base method.
-func (m *MockRepository) UpdateUserLastPenaltyImposedDays(ctx context.Context, tx *firestore.Transaction, userID string, lastPenaltyImposedDays int) error {
+func (m *MockRepository) UpdateUserLastPenaltyImposedDays(ctx context.Context, tx repository.Transaction, userID string, lastPenaltyImposedDays int) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UpdateUserLastPenaltyImposedDays", ctx, tx, userID, lastPenaltyImposedDays)
 	ret0, _ := ret[0].(error)
@@ -989,7 +1423,7 @@ func (mr *MockRepositoryMockRecorder) UpdateUserLastPenaltyImposedDays(ctx, tx,
 }
 
 // UpdateUserLastRPProcessed mocks base method.
-func (m *MockRepository) UpdateUserLastRPProcessed(tx *firestore.Transaction, userID string, date time.Time) error {
+func (m *MockRepository) UpdateUserLastRPProcessed(tx repository.Transaction, userID string, date time.Time) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UpdateUserLastRPProcessed", tx, userID, date)
 	ret0, _ := ret[0].(error)
@@ -1003,7 +1437,7 @@ func (mr *MockRepositoryMockRecorder) UpdateUserLastRPProcessed(tx, userID, date
 }
 
 // UpdateUserRPAndLastPenaltyImposedDays mocks base method.
-func (m *MockRepository) UpdateUserRPAndLastPenaltyImposedDays(ctx context.Context, tx *firestore.Transaction, userID string, newRP, newLastPenaltyImposedDays int) error {
+func (m *MockRepository) UpdateUserRPAndLastPenaltyImposedDays(ctx context.Context, tx repository.Transaction, userID string, newRP, newLastPenaltyImposedDays int) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UpdateUserRPAndLastPenaltyImposedDays", ctx, tx, userID, newRP, newLastPenaltyImposedDays)
 	ret0, _ := ret[0].(error)
@@ -1017,7 +1451,7 @@ func (mr *MockRepositoryMockRecorder) UpdateUserRPAndLastPenaltyImposedDays(ctx,
 }
 
 // UpdateUserRankPoint mocks base method.
-func (m *MockRepository) UpdateUserRankPoint(tx *firestore.Transaction, userID string, rp int) error {
+func (m *MockRepository) UpdateUserRankPoint(tx repository.Transaction, userID string, rp int) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UpdateUserRankPoint", tx, userID, rp)
 	ret0, _ := ret[0].(error)
@@ -1031,7 +1465,7 @@ func (mr *MockRepositoryMockRecorder) UpdateUserRankPoint(tx, userID, rp any) *g
 }
 
 // UpdateUserRankVisible mocks base method.
-func (m *MockRepository) UpdateUserRankVisible(tx *firestore.Transaction, userID string, rankVisible bool) error {
+func (m *MockRepository) UpdateUserRankVisible(tx repository.Transaction, userID string, rankVisible bool) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UpdateUserRankVisible", tx, userID, rankVisible)
 	ret0, _ := ret[0].(error)
@@ -1044,8 +1478,22 @@ func (mr *MockRepositoryMockRecorder) UpdateUserRankVisible(tx, userID, rankVisi
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserRankVisible", reflect.TypeOf((*MockRepository)(nil).UpdateUserRankVisible), tx, userID, rankVisible)
 }
 
+// UpdateUserSupporterAppearanceUntil mocks base method.
+func (m *MockRepository) UpdateUserSupporterAppearanceUntil(tx repository.Transaction, userID string, until time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserSupporterAppearanceUntil", tx, userID, until)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserSupporterAppearanceUntil indicates an expected call of UpdateUserSupporterAppearanceUntil.
+func (mr *MockRepositoryMockRecorder) UpdateUserSupporterAppearanceUntil(tx, userID, until any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserSupporterAppearanceUntil", reflect.TypeOf((*MockRepository)(nil).UpdateUserSupporterAppearanceUntil), tx, userID, until)
+}
+
 // UpdateUserTotalTime mocks base method.
-func (m *MockRepository) UpdateUserTotalTime(tx *firestore.Transaction, userID string, newTotalTimeSec, newDailyTotalTimeSec int) error {
+func (m *MockRepository) UpdateUserTotalTime(tx repository.Transaction, userID string, newTotalTimeSec, newDailyTotalTimeSec int) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UpdateUserTotalTime", tx, userID, newTotalTimeSec, newDailyTotalTimeSec)
 	ret0, _ := ret[0].(error)
@@ -1059,7 +1507,7 @@ func (mr *MockRepositoryMockRecorder) UpdateUserTotalTime(tx, userID, newTotalTi
 }
 
 // UpdateWorkNameTrend mocks base method.
-func (m *MockRepository) UpdateWorkNameTrend(ctx context.Context, tx *firestore.Transaction, workNameTrend repository.WorkNameTrendDoc) error {
+func (m *MockRepository) UpdateWorkNameTrend(ctx context.Context, tx repository.Transaction, workNameTrend repository.WorkNameTrendDoc) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UpdateWorkNameTrend", ctx, tx, workNameTrend)
 	ret0, _ := ret[0].(error)
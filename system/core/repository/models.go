@@ -44,6 +44,14 @@ type ConstantsConfigDoc struct {
 	GcsFirestoreExportBucketName   string `firestore:"gcs-firestore-export-bucket-name"`
 	CollectionHistoryRetentionDays int    `firestore:"collection-history-retention-days"` // 何日間live chat historyおよびuser activityを保持するか
 
+	// !outの直後に!undoで退室を取り消せる時間（分）。0（未設定）ならutils.DefaultUndoExitGraceMin分、負の値なら取り消しできない
+	UndoExitGraceMin int `firestore:"undo-exit-grace-min"`
+
+	// 座席予約関連。ReservationMaxPerUserが0なら予約できない
+	ReservationMaxPerUser     int `firestore:"reservation-max-per-user"`      // ユーザーごと（一般席・メンバー席それぞれ）の予約の上限
+	ReservationLeadMin        int `firestore:"reservation-lead-min"`          // 開始時刻の何分前から他のユーザーの入室を断るか
+	ReservationNoShowGraceMin int `firestore:"reservation-no-show-grace-min"` // 開始時刻から何分経っても入室がなければ予約を取り消すか
+
 	// 同座席入室制限関連
 	RecentRangeMin     int `firestore:"recent-range-min"`     // 過去何分以内に。
 	RecentThresholdMin int `firestore:"recent-threshold-min"` // 何分間以上該当座席に座っていたらアウト
@@ -58,6 +66,24 @@ type ConstantsConfigDoc struct {
 	// Botの設定（ブロック・通知対象の正規表現など）をまとめたスプレッドシートのID
 	BotConfigSpreadsheetID string `firestore:"bot-config-spreadsheet-id" json:"bot_config_spreadsheet_id"`
 
+	// ライブチャットへの投稿の最小の間隔。この間に溜まった短い返信は1つのメッセージにまとめて送る
+	LiveChatPostIntervalMilli int `firestore:"live-chat-post-interval-milli" json:"live_chat_post_interval_milli"`
+
+	// スーパーチャット・スーパーステッカー・メンバーシップ加入・ギフトの支援者への特典。いずれも0（false）なら渡さない
+	FanFundingRewardRP                int  `firestore:"fan-funding-reward-rp" json:"fan_funding_reward_rp"`                                 // 加算するRP
+	FanFundingRewardExtendMin         int  `firestore:"fan-funding-reward-extend-min" json:"fan_funding_reward_extend_min"`                 // 作業中なら自動退室までの時間を延長する（分）
+	FanFundingRewardAppearanceEnabled bool `firestore:"fan-funding-reward-appearance-enabled" json:"fan_funding_reward_appearance_enabled"` // その日は座席を特別な見た目にする
+
+	// YouTube Data APIの1日（太平洋時間）のクォータの予算。見込みの使用量が超える場合はポーリングの間隔を延ばす。0なら制限しない
+	YoutubeAPIDailyQuotaBudget int `firestore:"youtube-api-daily-quota-budget" json:"youtube_api_daily_quota_budget"`
+
+	// アクティブな配信が複数あるときのライブチャットの選び方。""（1つのときのみ）、"newest"、"title"、"bound-stream"
+	LiveBroadcastSelectionPolicy string `firestore:"live-broadcast-selection-policy" json:"live_broadcast_selection_policy"`
+	// "title"のときに配信タイトルと照合する正規表現
+	LiveBroadcastTitlePattern string `firestore:"live-broadcast-title-pattern" json:"live_broadcast_title_pattern"`
+	// "bound-stream"のときの、配信ソフトの送信先のライブストリームのID
+	LiveBroadcastBoundStreamID string `firestore:"live-broadcast-bound-stream-id" json:"live_broadcast_bound_stream_id"`
+
 	YoutubeMembershipEnabled bool `firestore:"youtube-membership-enabled" json:"youtube_membership_enabled"`
 
 	FixedMaxSeatsEnabled bool `firestore:"fixed-max-seats-enabled" json:"fixed_max_seats_enabled"`
@@ -117,6 +143,9 @@ type SeatDoc struct {
 	CumulativeWorkSec       int            `json:"cumulative_work_sec" firestore:"cumulative-work-sec"` // 前回のstateまでの合計作業時間（秒）。休憩時間は含まない。
 	DailyCumulativeWorkSec  int            `json:"daily_cumulative_work_sec" firestore:"daily-cumulative-work-sec"`
 	UserProfileImageURL     string         `json:"user_profile_image_url" firestore:"user-profile-image-url"`
+	PomodoroWorkMin         int            `json:"pomodoro_work_min" firestore:"pomodoro-work-min"`     // ポモドーロの作業時間（分）。0ならポモドーロなし
+	PomodoroBreakMin        int            `json:"pomodoro_break_min" firestore:"pomodoro-break-min"`   // ポモドーロの休憩時間（分）
+	DailyGoalCheckAt        time.Time      `json:"daily_goal_check_at" firestore:"daily-goal-check-at"` // 次に1日の目標作業時間の達成を判定する日時。ゼロ値ならすぐに判定する
 }
 
 // SeatLimitDoc defines limitations of a seat.
@@ -169,6 +198,24 @@ type UserDoc struct {
 
 	// お気に入りの色のカラーコード
 	FavoriteColor string `json:"favorite_color" firestore:"favorite-color"`
+
+	// 1日の目標作業時間（分）。0なら目標なし
+	DailyGoalMin int `json:"daily_goal_min" firestore:"daily-goal-min"`
+
+	// 当日の目標作業時間を達成済みかどうか（お祝いメッセージを1日1回だけ送るため）。日次バッチでリセットされる
+	DailyGoalAchieved bool `json:"daily_goal_achieved" firestore:"daily-goal-achieved"`
+
+	// 連続アクティブ日数の最長記録。日次のRP更新時に更新される
+	BestStreakDays int `json:"best_streak_days" firestore:"best-streak-days"`
+
+	// スーパーチャットなどのお礼の特別な座席の見た目を、この日時まで使う
+	SupporterAppearanceUntil time.Time `json:"supporter_appearance_until" firestore:"supporter-appearance-until"`
+}
+
+// UserDocWithID ドキュメントIDを付けたユーザーのドキュメント。ページングして読み込む場合に使う
+type UserDocWithID struct {
+	UserID string
+	UserDoc
 }
 
 type LiveChatHistoryDoc struct {
@@ -176,7 +223,7 @@ type LiveChatHistoryDoc struct {
 	AuthorDisplayName     string    `json:"author_display_name" firestore:"author-display-name"`
 	AuthorProfileImageURL string    `json:"author_profile_image_url" firestore:"author-profile-image-url"`
 	AuthorIsChatModerator bool      `json:"author_is_chat_moderator" firestore:"author-is-chat-moderator"`
-	ID                    string    `json:"id" firestore:"id"`                     // メッセージのID。APIで取得するliveChatMessages resourceで定義されているid
+	ID                    string    `json:"id" firestore:"id"`                     // メッセージのID。YouTubeはliveChatMessages resourceのid、Twitchは名前空間をつけたid（chat.ID）
 	LiveChatID            string    `json:"live_chat_id" firestore:"live-chat-id"` // ライブ配信ごとのid。ずっと続く配信だと不変。
 	MessageText           string    `json:"message_text" firestore:"message-text"`
 	PublishedAt           time.Time `json:"published_at" firestore:"published-at"`
@@ -216,6 +263,12 @@ type WorkSegmentDoc struct {
 	DurationSec int       `json:"duration_sec" firestore:"duration-sec"`
 }
 
+// WorkSegmentDocWithID ドキュメントIDを付けた作業セグメント。ページングして読み込む場合に使う
+type WorkSegmentDocWithID struct {
+	SegmentID string
+	WorkSegmentDoc
+}
+
 // DailyUserWorkHistoryDoc stores daily totals.
 type DailyUserWorkHistoryDoc struct {
 	UserID string    `json:"user_id" firestore:"user-id"`
@@ -227,6 +280,41 @@ type DailyUserWorkHistoryDoc struct {
 	TimezoneName string `json:"timezone_name" firestore:"timezone-name"`
 }
 
+// UndoableExitDoc は!outによる退室を!undoで取り消すために、退室直前の状態と退室時に加算した値を保持する。
+// ユーザーごとに1つで、最後の!outのみが対象になる。
+type UndoableExitDoc struct {
+	UserID       string    `json:"user_id" firestore:"user-id"`
+	Seat         SeatDoc   `json:"seat" firestore:"seat"` // 退室直前の座席
+	IsMemberSeat bool      `json:"is_member_seat" firestore:"is-member-seat"`
+	ExitedAt     time.Time `json:"exited_at" firestore:"exited-at"`
+
+	// 退室前のユーザーの値
+	PreviousLastExited time.Time `json:"previous_last_exited" firestore:"previous-last-exited"`
+
+	// 退室時に加算した値
+	AddedWorkSec      int                        `json:"added_work_sec" firestore:"added-work-sec"`
+	AddedDailyWorkSec int                        `json:"added_daily_work_sec" firestore:"added-daily-work-sec"`
+	AddedRP           int                        `json:"added_rp" firestore:"added-rp"`
+	AddedDailyHistory []UndoableDailyWorkHistory `json:"added_daily_history" firestore:"added-daily-history"`
+}
+
+type UndoableDailyWorkHistory struct {
+	Date     time.Time `json:"date" firestore:"date"`
+	WorkSec  int       `json:"work_sec" firestore:"work-sec"`
+	BreakSec int       `json:"break_sec" firestore:"break-sec"`
+}
+
+// SeatReservationDoc は座席の予約。一般席・メンバー席でコレクションを分ける。
+type SeatReservationDoc struct {
+	ReservationID   string    `json:"reservation_id" firestore:"reservation-id"`
+	UserID          string    `json:"user_id" firestore:"user-id"`
+	UserDisplayName string    `json:"user_display_name" firestore:"user-display-name"`
+	SeatID          int       `json:"seat_id" firestore:"seat-id"`
+	StartAt         time.Time `json:"start_at" firestore:"start-at"`
+	Until           time.Time `json:"until" firestore:"until"`
+	CreatedAt       time.Time `json:"created_at" firestore:"created-at"`
+}
+
 type MenuDoc struct {
 	Code string `json:"code" firestore:"code"`
 	Name string `json:"name" firestore:"name"`
@@ -240,6 +328,91 @@ type OrderHistoryDoc struct {
 	OrderedAt    time.Time `json:"ordered_at" firestore:"ordered-at"`
 }
 
+type ModerationActionType string
+
+const (
+	KickModerationAction          ModerationActionType = "kick"
+	BlockModerationAction         ModerationActionType = "block"
+	TimeoutModerationAction       ModerationActionType = "timeout"
+	NGWordBanModerationAction     ModerationActionType = "ng-word-ban"
+	NGWordTimeoutModerationAction ModerationActionType = "ng-word-timeout"
+	UnbanModerationAction         ModerationActionType = "unban"
+)
+
+type FanFundingEventType string
+
+const (
+	SuperChatFanFundingEvent      FanFundingEventType = "super-chat"
+	SuperStickerFanFundingEvent   FanFundingEventType = "super-sticker"
+	NewMembershipFanFundingEvent  FanFundingEventType = "new-membership"
+	MembershipGiftFanFundingEvent FanFundingEventType = "membership-gift"
+)
+
+// FanFundingHistoryDoc スーパーチャット・スーパーステッカー・メンバーシップ加入・ギフトの記録と、支援者に渡した特典。
+// ドキュメントIDはライブチャットのメッセージのIDで、同じイベントに特典を2回渡さないために使う。
+type FanFundingHistoryDoc struct {
+	EventType       FanFundingEventType `json:"event_type" firestore:"event-type"`
+	UserID          string              `json:"user_id" firestore:"user-id"`
+	UserDisplayName string              `json:"user_display_name" firestore:"user-display-name"`
+
+	// スーパーチャット・スーパーステッカーの場合のみ
+	AmountMicros        int64  `json:"amount_micros" firestore:"amount-micros"`
+	Currency            string `json:"currency" firestore:"currency"`
+	AmountDisplayString string `json:"amount_display_string" firestore:"amount-display-string"`
+
+	// メンバーシップ加入・ギフトの場合のみ
+	MembershipLevelName string `json:"membership_level_name" firestore:"membership-level-name"`
+	GiftCount           int    `json:"gift_count" firestore:"gift-count"`
+
+	// 渡した特典。未登録のユーザーなど、渡せなかったものは0
+	RewardRP              int       `json:"reward_rp" firestore:"reward-rp"`
+	RewardExtendedMin     int       `json:"reward_extended_min" firestore:"reward-extended-min"`
+	RewardAppearanceUntil time.Time `json:"reward_appearance_until" firestore:"reward-appearance-until"`
+
+	PublishedAt time.Time `json:"published_at" firestore:"published-at"`
+}
+
+// ProcessedLiveChatMessageDoc コマンドとして処理済みのライブチャットのメッセージ。ドキュメントIDはメッセージのID。
+// 再起動で同じページをもう一度受信したときに、同じコマンドを2回実行しないために使う。
+// FirestoreではExpireAtのTTLポリシーで自動的に削除される。
+type ProcessedLiveChatMessageDoc struct {
+	UserID      string    `json:"user_id" firestore:"user-id"`
+	ProcessedAt time.Time `json:"processed_at" firestore:"processed-at"`
+	ExpireAt    time.Time `json:"expire_at" firestore:"expire-at"`
+}
+
+// ModerationActionDoc モデレーターによるキック・ブロック・タイムアウトや、NGワードによる自動ブロックとその解除の記録。
+type ModerationActionDoc struct {
+	ActionType ModerationActionType `json:"action_type" firestore:"action-type"`
+
+	// 実行したモデレーター。NGワードによる自動ブロックの場合は空
+	ActorUserID      string `json:"actor_user_id" firestore:"actor-user-id"`
+	ActorDisplayName string `json:"actor_display_name" firestore:"actor-display-name"`
+
+	TargetUserID      string `json:"target_user_id" firestore:"target-user-id"`
+	TargetDisplayName string `json:"target_display_name" firestore:"target-display-name"`
+
+	// 対象のユーザーが座っていた座席。NGワードによる自動ブロックの場合は0
+	SeatID       int  `json:"seat_id" firestore:"seat-id"`
+	IsMemberSeat bool `json:"is_member_seat" firestore:"is-member-seat"`
+
+	// NGワードによる自動ブロックの場合のみ。一致した正規表現と、対象のチャットメッセージまたはチャンネル名
+	MatchedRegex string `json:"matched_regex" firestore:"matched-regex"`
+	MessageText  string `json:"message_text" firestore:"message-text"`
+
+	// YouTubeのブロック（liveChatBans）のID。ブロック・タイムアウトと、その解除の場合のみ
+	BanID string `json:"ban_id" firestore:"ban-id"`
+	// タイムアウトの場合のみ。0なら無期限のブロック
+	TimeoutDurationSec int `json:"timeout_duration_sec" firestore:"timeout-duration-sec"`
+
+	TakenAt time.Time `json:"taken_at" firestore:"taken-at"`
+}
+
+// IsTimeout 一時的なブロックの記録ならtrue
+func (a ModerationActionDoc) IsTimeout() bool {
+	return a.TimeoutDurationSec > 0
+}
+
 type WorkNameTrendDoc struct {
 	Ranking  []WorkNameTrendRanking `json:"ranking" firestore:"ranking"`
 	RankedAt time.Time              `json:"ranked_at" firestore:"ranked-at"`
@@ -251,3 +424,22 @@ type WorkNameTrendRanking struct {
 	Count    int      `json:"count" firestore:"count"`
 	Examples []string `json:"examples" firestore:"examples"`
 }
+
+// YoutubeAPIQuotaUsageDoc YouTube Data APIのクォータの1日分の使用量。
+// クォータは太平洋時間の0時にリセットされるため、Dateも太平洋時間の日付（2006-01-02）とする。
+type YoutubeAPIQuotaUsageDoc struct {
+	Date  string         `json:"date" firestore:"date"`
+	Units int            `json:"units" firestore:"units"`
+	Calls map[string]int `json:"calls" firestore:"calls"` // メソッドごとの呼び出し回数
+}
+
+// add FirestoreのIncrementと同じく、callsとunitsを加算する
+func (u *YoutubeAPIQuotaUsageDoc) add(calls map[string]int, units int) {
+	if u.Calls == nil {
+		u.Calls = make(map[string]int, len(calls))
+	}
+	for method, n := range calls {
+		u.Calls[method] += n
+	}
+	u.Units += units
+}
@@ -132,7 +132,7 @@ func (s *SeatDoc) ResumeWork(now time.Time, workName string) error {
 
 	s.State = WorkState
 	s.CurrentStateStartedAt = now
-	s.CurrentStateUntil = s.Until
+	s.CurrentStateUntil = s.WorkStateUntil()
 	s.CurrentSegmentStartedAt = now
 	s.DailyCumulativeWorkSec = dailyCumulativeWorkSec
 	s.WorkName = workName
@@ -157,7 +157,7 @@ func (s *SeatDoc) SetWorkDuration(newUntil time.Time) error {
 	}
 
 	s.Until = newUntil
-	s.CurrentStateUntil = newUntil
+	s.CurrentStateUntil = s.WorkStateUntil()
 	return nil
 }
 
@@ -191,7 +191,7 @@ func (s *SeatDoc) ExtendWorkDuration(now time.Time, requestedAddMin int, maxWork
 
 	actualAddedMin = int(timeutil.NoNegativeDuration(newUntil.Sub(s.Until)).Minutes())
 	s.Until = newUntil
-	s.CurrentStateUntil = newUntil
+	s.CurrentStateUntil = s.WorkStateUntil()
 	newRemainingMin = int(timeutil.NoNegativeDuration(newUntil.Sub(now)).Minutes())
 
 	return actualAddedMin, newRemainingMin, nil
@@ -240,6 +240,40 @@ func (s *SeatDoc) ExtendBreakDuration(now time.Time, requestedAddMin int, maxBre
 	return actualAddedMin, newRemainingBreakMin, newRemainingUntilExitMin, nil
 }
 
+// IsPomodoro はポモドーロ（作業と休憩の自動切り替え）が設定されているかを返す。
+func (s *SeatDoc) IsPomodoro() bool {
+	return s.PomodoroWorkMin > 0 && s.PomodoroBreakMin > 0
+}
+
+// SetPomodoro はポモドーロのサイクルを設定する。
+// 作業中の場合は、現在の作業状態の開始時刻を起点に CurrentStateUntil を再計算する。
+func (s *SeatDoc) SetPomodoro(workMin int, breakMin int) {
+	s.PomodoroWorkMin = workMin
+	s.PomodoroBreakMin = breakMin
+	if s.State == WorkState {
+		s.CurrentStateUntil = s.WorkStateUntil()
+	}
+}
+
+// WorkStateUntil は作業状態の終了予定時刻を返す。
+// ポモドーロでなければ Until、ポモドーロであれば作業時間の経過時刻と Until の早い方。
+func (s *SeatDoc) WorkStateUntil() time.Time {
+	if !s.IsPomodoro() {
+		return s.Until
+	}
+	pomodoroWorkUntil := s.CurrentStateStartedAt.Add(time.Duration(s.PomodoroWorkMin) * time.Minute)
+	if pomodoroWorkUntil.Before(s.Until) {
+		return pomodoroWorkUntil
+	}
+	return s.Until
+}
+
+// IsPomodoroWorkFinished はポモドーロの作業時間が終了し、休憩に入るべき状態かを返す。
+// 作業時間の終了が自動退室予定時刻と重なる場合は、休憩せずに自動退室させるため false を返す。
+func (s *SeatDoc) IsPomodoroWorkFinished(now time.Time) bool {
+	return s.State == WorkState && s.IsPomodoro() && s.CurrentStateUntil.Before(now) && s.CurrentStateUntil.Before(s.Until)
+}
+
 func (s *SeatDoc) GenerateWorkSegment(now time.Time, isMemberSeat bool) (WorkSegmentDoc, error) {
 	if s.CurrentSegmentStartedAt.IsZero() {
 		return WorkSegmentDoc{}, fmt.Errorf("currentSegmentStartedAt is zero for seatID: %d, userID: %s, isMemberSeat: %v", s.SeatID, s.UserID, isMemberSeat)
@@ -0,0 +1,347 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SeatSnapshotListener 座席（一般席・メンバー席）とconstantsの変更を購読し、SeatCacheに反映し続ける。
+// 購読はctxがキャンセルされるまで続く。
+type SeatSnapshotListener interface {
+	ListenSeatSnapshots(ctx context.Context, cache *SeatCache) error
+}
+
+// SeatCacheStats SeatCacheのヒット・ミス回数。ミスはRepositoryへの読み取りにフォールバックした回数。
+type SeatCacheStats struct {
+	Hits   int64
+	Misses int64
+}
+
+// seatCacheDirtySettle 書き込みの後、スナップショットを受け取ってもキャッシュを使わない時間。
+// 書き込みより前に作られて配送中だったスナップショットで、dirtyが解除されないようにする。
+const seatCacheDirtySettle = 3 * time.Second
+
+// seatCacheEntry 1つのスナップショットの最新状態。
+// readyでも、書き込みからseatCacheDirtySettle以上経って受け取ったスナップショットがなければ、自分の書き込みが反映されていないため使わない。
+// サーバーとの時刻のずれの影響を受けないよう、時刻はどちらもこのプロセスの時計で比べる。
+type seatCacheEntry[T any] struct {
+	value   T
+	ready   bool
+	dirty   bool
+	dirtyAt time.Time // 最後に書き込んだ時刻
+}
+
+func (e *seatCacheEntry[T]) usable() bool {
+	return e.ready && !e.dirty
+}
+
+func (e *seatCacheEntry[T]) update(value T, receivedAt time.Time) {
+	e.value = value
+	e.ready = true
+	if e.dirty && receivedAt.After(e.dirtyAt.Add(seatCacheDirtySettle)) {
+		e.dirty = false
+	}
+}
+
+func (e *seatCacheEntry[T]) markDirty(now time.Time) {
+	e.dirty = true
+	e.dirtyAt = now
+}
+
+// SeatCache 一般席・メンバー席・constantsのスナップショットを保持する。
+// 1回のコマンド処理で何度も全座席を読むため、読み取り専用の処理はこれを使ってFirestoreの読み取りを減らす。
+type SeatCache struct {
+	mu           sync.RWMutex
+	generalSeats seatCacheEntry[[]SeatDoc]
+	memberSeats  seatCacheEntry[[]SeatDoc]
+	constants    seatCacheEntry[ConstantsConfigDoc]
+	// onConstantsChange WatchConstantsで登録された、constantsのスナップショットを受け取るたびに呼ぶ関数
+	onConstantsChange []func(ConstantsConfigDoc)
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+func NewSeatCache() *SeatCache {
+	return &SeatCache{}
+}
+
+func (c *SeatCache) seatsEntry(isMemberSeat bool) *seatCacheEntry[[]SeatDoc] {
+	if isMemberSeat {
+		return &c.memberSeats
+	}
+	return &c.generalSeats
+}
+
+// UpdateSeats receivedAtに受け取ったスナップショットの全座席でキャッシュを置き換える。seatsはドキュメントID順であること。
+// receivedAtはスナップショットのReadTimeではなく、受け取ったときのこのプロセスの時刻。
+func (c *SeatCache) UpdateSeats(isMemberSeat bool, seats []SeatDoc, receivedAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.seatsEntry(isMemberSeat).update(seats, receivedAt)
+}
+
+// UpdateConstants receivedAtに受け取ったスナップショットのconstantsでキャッシュを置き換え、WatchConstantsで登録された関数に渡す。
+func (c *SeatCache) UpdateConstants(constants ConstantsConfigDoc, receivedAt time.Time) {
+	c.mu.Lock()
+	c.constants.update(constants, receivedAt)
+	onConstantsChange := c.onConstantsChange
+	c.mu.Unlock()
+
+	for _, onChange := range onConstantsChange {
+		onChange(constants)
+	}
+}
+
+// WatchConstants 以後constantsのスナップショットを受け取るたびに、購読しているgoroutineからonChangeを呼ぶ。
+// config/constantsの購読はキャッシュと共有し、別のリスナーは開かない。
+func (c *SeatCache) WatchConstants(onChange func(ConstantsConfigDoc)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onConstantsChange = append(c.onConstantsChange, onChange)
+}
+
+// InvalidateSeats 購読が切れた場合などに呼び、次のスナップショットまでRepositoryから読むようにする。
+func (c *SeatCache) InvalidateSeats(isMemberSeat bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.seatsEntry(isMemberSeat).ready = false
+}
+
+// InvalidateConstants 購読が切れた場合などに呼び、次のスナップショットまでRepositoryから読むようにする。
+func (c *SeatCache) InvalidateConstants() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.constants.ready = false
+}
+
+func (c *SeatCache) Stats() SeatCacheStats {
+	return SeatCacheStats{
+		Hits:   c.hits.Load(),
+		Misses: c.misses.Load(),
+	}
+}
+
+func (c *SeatCache) seats(isMemberSeat bool) ([]SeatDoc, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	entry := c.seatsEntry(isMemberSeat)
+	if !entry.usable() {
+		c.misses.Add(1)
+		return nil, false
+	}
+	c.hits.Add(1)
+	return entry.value, true
+}
+
+func (c *SeatCache) readConstants() (ConstantsConfigDoc, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if !c.constants.usable() {
+		c.misses.Add(1)
+		return ConstantsConfigDoc{}, false
+	}
+	c.hits.Add(1)
+	return c.constants.value, true
+}
+
+func (c *SeatCache) markSeatsDirty(isMemberSeat bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.seatsEntry(isMemberSeat).markDirty(time.Now())
+}
+
+func (c *SeatCache) markConstantsDirty() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.constants.markDirty(time.Now())
+}
+
+// touchDirty 書き込み済みのエントリの書き込み時刻を今にする。トランザクションのコミット後に呼ぶことで、
+// コミット前に読み取られたスナップショットでdirtyが解除されないようにする。
+func (c *SeatCache) touchDirty() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	for _, entry := range []*seatCacheEntry[[]SeatDoc]{&c.generalSeats, &c.memberSeats} {
+		if entry.dirty {
+			entry.dirtyAt = now
+		}
+	}
+	if c.constants.dirty {
+		c.constants.dirtyAt = now
+	}
+}
+
+// CachedRepository 全座席とconstantsのトランザクション外の読み取りをSeatCacheから返すRepository。
+// RunTransactionに渡した関数の中では、txを取らない読み取り（ReadGeneralSeatsなど）もキャッシュを使わず元のRepositoryから読むため、
+// 整合性の検証はトランザクション内で行われる。
+// 座席やconstantsを書き込むと、その後のスナップショットを受け取るまではキャッシュを使わない。
+// ただし他のプロセス（OrganizeDBのLambdaなど）による書き込みは、スナップショットが届くまでの間は反映されていない。
+type CachedRepository struct {
+	Repository
+	cache *SeatCache
+}
+
+func NewCachedRepository(repo Repository, cache *SeatCache) *CachedRepository {
+	return &CachedRepository{Repository: repo, cache: cache}
+}
+
+// inTransactionKey RunTransactionに渡した関数のctxに付ける印
+type inTransactionKey struct{}
+
+func inTransaction(ctx context.Context) bool {
+	return ctx.Value(inTransactionKey{}) != nil
+}
+
+func (r *CachedRepository) RunTransaction(ctx context.Context, f func(ctx context.Context, tx Transaction) error) error {
+	defer r.cache.touchDirty()
+	return r.Repository.RunTransaction(ctx, func(ctx context.Context, tx Transaction) error {
+		return f(context.WithValue(ctx, inTransactionKey{}, true), tx)
+	})
+}
+
+// cachedSeats トランザクションの外であれば、キャッシュされた全座席を返す
+func (r *CachedRepository) cachedSeats(ctx context.Context, isMemberSeat bool) ([]SeatDoc, bool) {
+	if inTransaction(ctx) {
+		return nil, false
+	}
+	return r.cache.seats(isMemberSeat)
+}
+
+func (r *CachedRepository) ReadGeneralSeats(ctx context.Context) ([]SeatDoc, error) {
+	if seats, ok := r.cachedSeats(ctx, false); ok {
+		return append([]SeatDoc{}, seats...), nil
+	}
+	return r.Repository.ReadGeneralSeats(ctx)
+}
+
+func (r *CachedRepository) ReadMemberSeats(ctx context.Context) ([]SeatDoc, error) {
+	if seats, ok := r.cachedSeats(ctx, true); ok {
+		return append([]SeatDoc{}, seats...), nil
+	}
+	return r.Repository.ReadMemberSeats(ctx)
+}
+
+func (r *CachedRepository) ReadSeatWithUserID(ctx context.Context, userID string, isMemberSeat bool) (SeatDoc, error) {
+	seats, ok := r.cachedSeats(ctx, isMemberSeat)
+	if !ok {
+		return r.Repository.ReadSeatWithUserID(ctx, userID, isMemberSeat)
+	}
+	var found []SeatDoc
+	for _, seat := range seats {
+		if seat.UserID == userID {
+			found = append(found, seat)
+		}
+	}
+	if len(found) >= 2 {
+		return SeatDoc{}, errors.New("There are more than two seats with the user id = " + userID + " !!")
+	}
+	if len(found) == 1 {
+		return found[0], nil
+	}
+	return SeatDoc{}, status.Errorf(codes.NotFound, "%s not found", "the document with user id = "+userID)
+}
+
+func (r *CachedRepository) ReadSystemConstantsConfig(ctx context.Context, tx Transaction) (ConstantsConfigDoc, error) {
+	if tx == nil && !inTransaction(ctx) {
+		if constants, ok := r.cache.readConstants(); ok {
+			return constants, nil
+		}
+	}
+	return r.Repository.ReadSystemConstantsConfig(ctx, tx)
+}
+
+// writeSeats 座席への書き込みの前後でキャッシュを使わないようにする。
+func (r *CachedRepository) writeSeats(isMemberSeat bool, write func() error) error {
+	r.cache.markSeatsDirty(isMemberSeat)
+	defer r.cache.touchDirty()
+	return write()
+}
+
+// writeConstants constantsへの書き込みの前後でキャッシュを使わないようにする。
+func (r *CachedRepository) writeConstants(write func() error) error {
+	r.cache.markConstantsDirty()
+	defer r.cache.touchDirty()
+	return write()
+}
+
+func (r *CachedRepository) CreateSeat(tx Transaction, seat SeatDoc, isMemberSeat bool) error {
+	return r.writeSeats(isMemberSeat, func() error {
+		return r.Repository.CreateSeat(tx, seat, isMemberSeat)
+	})
+}
+
+func (r *CachedRepository) UpdateSeat(ctx context.Context, tx Transaction, seat SeatDoc, isMemberSeat bool) error {
+	return r.writeSeats(isMemberSeat, func() error {
+		return r.Repository.UpdateSeat(ctx, tx, seat, isMemberSeat)
+	})
+}
+
+func (r *CachedRepository) DeleteSeat(ctx context.Context, tx Transaction, seatID int, isMemberSeat bool) error {
+	return r.writeSeats(isMemberSeat, func() error {
+		return r.Repository.DeleteSeat(ctx, tx, seatID, isMemberSeat)
+	})
+}
+
+func (r *CachedRepository) DeleteDocRef(ctx context.Context, tx Transaction, ref DocumentRef) error {
+	switch ref.Collection {
+	case SEATS, MemberSeats:
+		return r.writeSeats(ref.Collection == MemberSeats, func() error {
+			return r.Repository.DeleteDocRef(ctx, tx, ref)
+		})
+	case CONFIG:
+		return r.writeConstants(func() error {
+			return r.Repository.DeleteDocRef(ctx, tx, ref)
+		})
+	}
+	return r.Repository.DeleteDocRef(ctx, tx, ref)
+}
+
+func (r *CachedRepository) UpdateLastResetDailyTotalStudyTime(ctx context.Context, timestamp time.Time) error {
+	return r.writeConstants(func() error {
+		return r.Repository.UpdateLastResetDailyTotalStudyTime(ctx, timestamp)
+	})
+}
+
+func (r *CachedRepository) UpdateLastLongTimeSittingChecked(ctx context.Context, timestamp time.Time) error {
+	return r.writeConstants(func() error {
+		return r.Repository.UpdateLastLongTimeSittingChecked(ctx, timestamp)
+	})
+}
+
+func (r *CachedRepository) UpdateLastTransferCollectionHistoryBigquery(ctx context.Context, timestamp time.Time) error {
+	return r.writeConstants(func() error {
+		return r.Repository.UpdateLastTransferCollectionHistoryBigquery(ctx, timestamp)
+	})
+}
+
+func (r *CachedRepository) UpdateDesiredMaxSeats(ctx context.Context, tx Transaction, desiredMaxSeats int) error {
+	return r.writeConstants(func() error {
+		return r.Repository.UpdateDesiredMaxSeats(ctx, tx, desiredMaxSeats)
+	})
+}
+
+func (r *CachedRepository) UpdateDesiredMemberMaxSeats(ctx context.Context, tx Transaction, desiredMemberMaxSeats int) error {
+	return r.writeConstants(func() error {
+		return r.Repository.UpdateDesiredMemberMaxSeats(ctx, tx, desiredMemberMaxSeats)
+	})
+}
+
+func (r *CachedRepository) UpdateMaxSeats(ctx context.Context, tx Transaction, maxSeats int) error {
+	return r.writeConstants(func() error {
+		return r.Repository.UpdateMaxSeats(ctx, tx, maxSeats)
+	})
+}
+
+func (r *CachedRepository) UpdateMemberMaxSeats(ctx context.Context, tx Transaction, memberMaxSeats int) error {
+	return r.writeConstants(func() error {
+		return r.Repository.UpdateMemberMaxSeats(ctx, tx, memberMaxSeats)
+	})
+}
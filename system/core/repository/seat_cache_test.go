@@ -0,0 +1,149 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"app.modules/core/repository"
+)
+
+func TestCachedRepository_ReadsFromSnapshot(t *testing.T) {
+	ctx := context.Background()
+	base := repository.NewInMemoryRepository()
+	cache := repository.NewSeatCache()
+	repo := repository.NewCachedRepository(base, cache)
+
+	// スナップショットを受け取る前はRepositoryから読む
+	require.NoError(t, base.RunTransaction(ctx, func(_ context.Context, tx repository.Transaction) error {
+		return base.CreateSeat(tx, repository.SeatDoc{SeatID: 1, UserID: "user1"}, false)
+	}))
+	seats, err := repo.ReadGeneralSeats(ctx)
+	require.NoError(t, err)
+	assert.Len(t, seats, 1)
+	assert.Equal(t, repository.SeatCacheStats{Hits: 0, Misses: 1}, cache.Stats())
+
+	// スナップショットを受け取った後はキャッシュから読む
+	cache.UpdateSeats(false, []repository.SeatDoc{{SeatID: 1, UserID: "user1"}, {SeatID: 2, UserID: "user2"}}, time.Now())
+	seats, err = repo.ReadGeneralSeats(ctx)
+	require.NoError(t, err)
+	assert.Len(t, seats, 2)
+	seat, err := repo.ReadSeatWithUserID(ctx, "user2", false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, seat.SeatID)
+	_, err = repo.ReadSeatWithUserID(ctx, "user3", false)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Equal(t, repository.SeatCacheStats{Hits: 3, Misses: 1}, cache.Stats())
+
+	// メンバー席は別のスナップショット
+	_, err = repo.ReadMemberSeats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), cache.Stats().Misses)
+}
+
+func TestCachedRepository_WriteBypassesCacheUntilNewerSnapshot(t *testing.T) {
+	ctx := context.Background()
+	base := repository.NewInMemoryRepository()
+	cache := repository.NewSeatCache()
+	repo := repository.NewCachedRepository(base, cache)
+	cache.UpdateSeats(false, []repository.SeatDoc{}, time.Now())
+
+	require.NoError(t, repo.RunTransaction(ctx, func(_ context.Context, tx repository.Transaction) error {
+		return repo.CreateSeat(tx, repository.SeatDoc{SeatID: 1, UserID: "user1"}, false)
+	}))
+
+	// 書き込み後は古いスナップショットを使わない
+	seat, err := repo.ReadSeatWithUserID(ctx, "user1", false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, seat.SeatID)
+
+	// 書き込みの直後に受け取ったスナップショットは、書き込みより前に作られたものかもしれないため使わない
+	cache.UpdateSeats(false, []repository.SeatDoc{}, time.Now())
+	seats, err := repo.ReadGeneralSeats(ctx)
+	require.NoError(t, err)
+	assert.Len(t, seats, 1)
+	assert.Equal(t, int64(0), cache.Stats().Hits)
+
+	cache.UpdateSeats(false, []repository.SeatDoc{{SeatID: 1, UserID: "user1"}}, time.Now().Add(time.Minute))
+	seats, err = repo.ReadGeneralSeats(ctx)
+	require.NoError(t, err)
+	assert.Len(t, seats, 1)
+	assert.Equal(t, int64(1), cache.Stats().Hits)
+}
+
+func TestCachedRepository_TransactionBypassesCache(t *testing.T) {
+	ctx := context.Background()
+	base := repository.NewInMemoryRepository()
+	cache := repository.NewSeatCache()
+	repo := repository.NewCachedRepository(base, cache)
+	// 他のプロセスが退室させた後、まだスナップショットが届いていない
+	cache.UpdateSeats(false, []repository.SeatDoc{{SeatID: 1, UserID: "user1"}}, time.Now())
+
+	require.NoError(t, repo.RunTransaction(ctx, func(ctx context.Context, _ repository.Transaction) error {
+		seats, err := repo.ReadGeneralSeats(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, seats)
+		_, err = repo.ReadSeatWithUserID(ctx, "user1", false)
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		return nil
+	}))
+	assert.Equal(t, repository.SeatCacheStats{}, cache.Stats())
+
+	seats, err := repo.ReadGeneralSeats(ctx)
+	require.NoError(t, err)
+	assert.Len(t, seats, 1)
+}
+
+func TestCachedRepository_Constants(t *testing.T) {
+	ctx := context.Background()
+	base := repository.NewInMemoryRepository()
+	require.NoError(t, base.SetSystemConstantsConfig(repository.ConstantsConfigDoc{MaxSeats: 10}))
+	cache := repository.NewSeatCache()
+	repo := repository.NewCachedRepository(base, cache)
+	cache.UpdateConstants(repository.ConstantsConfigDoc{MaxSeats: 10}, time.Now())
+
+	constants, err := repo.ReadSystemConstantsConfig(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 10, constants.MaxSeats)
+	assert.Equal(t, int64(1), cache.Stats().Hits)
+
+	// トランザクション内では常にRepositoryから読む
+	require.NoError(t, repo.RunTransaction(ctx, func(ctx context.Context, tx repository.Transaction) error {
+		if _, err := repo.ReadSystemConstantsConfig(ctx, tx); err != nil {
+			return err
+		}
+		return repo.UpdateMaxSeats(ctx, tx, 20)
+	}))
+	assert.Equal(t, repository.SeatCacheStats{Hits: 1, Misses: 0}, cache.Stats())
+
+	constants, err = repo.ReadSystemConstantsConfig(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 20, constants.MaxSeats)
+	assert.Equal(t, int64(1), cache.Stats().Misses)
+
+	// 購読が切れた場合も無効になる
+	cache.UpdateConstants(repository.ConstantsConfigDoc{MaxSeats: 20}, time.Now().Add(time.Minute))
+	cache.InvalidateConstants()
+	_, err = repo.ReadSystemConstantsConfig(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), cache.Stats().Misses)
+}
+
+func TestSeatCache_WatchConstants(t *testing.T) {
+	cache := repository.NewSeatCache()
+	var received []repository.ConstantsConfigDoc
+	cache.WatchConstants(func(constants repository.ConstantsConfigDoc) {
+		received = append(received, constants)
+	})
+
+	cache.UpdateConstants(repository.ConstantsConfigDoc{MaxSeats: 10}, time.Now())
+	cache.UpdateConstants(repository.ConstantsConfigDoc{MaxSeats: 20}, time.Now())
+	cache.InvalidateConstants()
+
+	assert.Equal(t, []repository.ConstantsConfigDoc{{MaxSeats: 10}, {MaxSeats: 20}}, received)
+}
@@ -774,6 +774,66 @@ func TestSeatDoc_ExtendBreakDuration(t *testing.T) {
 	})
 }
 
+func TestSeatDoc_Pomodoro(t *testing.T) {
+	t.Run("作業再開時はポモドーロの作業時間後が作業状態の終了予定時刻になる", func(t *testing.T) {
+		seat := SeatDoc{
+			State:                 BreakState,
+			CurrentStateStartedAt: mustParseTime(testTimeLayout, "2026-02-01 12:00:00"),
+			Until:                 mustParseTime(testTimeLayout, "2026-02-01 18:00:00"),
+			PomodoroWorkMin:       25,
+			PomodoroBreakMin:      5,
+		}
+
+		now := mustParseTime(testTimeLayout, "2026-02-01 12:05:00")
+		err := seat.ResumeWork(now, "作業")
+
+		assert.NoError(t, err)
+		assert.Equal(t, mustParseTime(testTimeLayout, "2026-02-01 12:30:00"), seat.CurrentStateUntil)
+		assert.Equal(t, mustParseTime(testTimeLayout, "2026-02-01 18:00:00"), seat.Until) // 変化なし
+	})
+
+	t.Run("作業時間がUntilを超える場合はUntilまで", func(t *testing.T) {
+		seat := mustSeat(func(s *SeatDoc) {
+			s.Until = mustParseTime(testTimeLayout, "2026-02-01 10:10:00")
+		})
+		seat.SetPomodoro(25, 5)
+
+		assert.Equal(t, mustParseTime(testTimeLayout, "2026-02-01 10:10:00"), seat.CurrentStateUntil)
+		assert.False(t, seat.IsPomodoroWorkFinished(mustParseTime(testTimeLayout, "2026-02-01 10:11:00")))
+	})
+
+	t.Run("作業中に設定した場合は作業状態の開始時刻を起点に再計算する", func(t *testing.T) {
+		seat := mustSeat(nil)
+		seat.SetPomodoro(50, 10)
+
+		assert.True(t, seat.IsPomodoro())
+		assert.Equal(t, mustParseTime(testTimeLayout, "2026-02-01 10:50:00"), seat.CurrentStateUntil)
+		assert.False(t, seat.IsPomodoroWorkFinished(mustParseTime(testTimeLayout, "2026-02-01 10:49:00")))
+		assert.True(t, seat.IsPomodoroWorkFinished(mustParseTime(testTimeLayout, "2026-02-01 10:51:00")))
+	})
+
+	t.Run("作業時間の延長でポモドーロの作業時間は変わらない", func(t *testing.T) {
+		seat := mustSeat(nil)
+		seat.SetPomodoro(25, 5)
+
+		now := mustParseTime(testTimeLayout, "2026-02-01 10:10:00")
+		_, _, err := seat.ExtendWorkDuration(now, 30, 600)
+
+		assert.NoError(t, err)
+		assert.Equal(t, mustParseTime(testTimeLayout, "2026-02-01 18:30:00"), seat.Until)
+		assert.Equal(t, mustParseTime(testTimeLayout, "2026-02-01 10:25:00"), seat.CurrentStateUntil)
+	})
+
+	t.Run("ポモドーロでなければ作業時間の終了判定はされない", func(t *testing.T) {
+		seat := mustSeat(func(s *SeatDoc) {
+			s.CurrentStateUntil = mustParseTime(testTimeLayout, "2026-02-01 10:25:00")
+		})
+
+		assert.False(t, seat.IsPomodoro())
+		assert.False(t, seat.IsPomodoroWorkFinished(mustParseTime(testTimeLayout, "2026-02-01 10:30:00")))
+	})
+}
+
 func TestSeatDoc_GenerateWorkSegment(t *testing.T) {
 	t.Run("通常の作業セグメントを生成できること", func(t *testing.T) {
 		seat := SeatDoc{
@@ -0,0 +1,1430 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"app.modules/core/timeutil"
+)
+
+//go:embed sqlmigrations/*.sql
+var sqlMigrationFiles embed.FS
+
+// SQLDialect SQLの方言。プレースホルダーの書き方やトランザクションの分離レベルが異なる。
+type SQLDialect int
+
+const (
+	SQLDialectSQLite SQLDialect = iota
+	SQLDialectPostgres
+)
+
+const (
+	sqlTransactionMaxAttempts = 5
+	sqlRetryBaseInterval      = 20 * time.Millisecond
+)
+
+var errSQLReadAfterWrite = errors.New("sql repository: read after write in transaction")
+
+// SQLRepository はRepositoryをdatabase/sqlで実装したもの。GCPプロジェクトなしでステージング環境を動かすために使う。
+// テーブルはFirestoreのコレクションと1対1に対応し、idがドキュメントIDに相当する。
+// トランザクションはFirestoreと同じく書き込みをコミット直前まで保留するため、書き込んだ後に同じトランザクションで読み取るとエラーになる。
+type SQLRepository struct {
+	db      *sql.DB
+	dialect SQLDialect
+}
+
+// OpenSQLRepository はdriverNameからSQLDialectを判定してデータベースを開き、マイグレーションを適用する。
+// driverNameは"sqlite"（modernc.org/sqlite）または"pgx"（github.com/jackc/pgx/v5/stdlib）で、ドライバーは呼び出し側でimportしておくこと。
+// SQLiteはトランザクション外の書き込みと並行できるよう、ファイルのデータベースにWALとbusy_timeoutを指定して使う。
+func OpenSQLRepository(ctx context.Context, driverName string, dataSourceName string) (*SQLRepository, error) {
+	var dialect SQLDialect
+	switch driverName {
+	case "sqlite":
+		dialect = SQLDialectSQLite
+	case "pgx", "postgres":
+		dialect = SQLDialectPostgres
+	default:
+		return nil, fmt.Errorf("unsupported sql driver: %s", driverName)
+	}
+	db, err := sql.Open(driverName, dataSourceName)
+	if err != nil {
+		return nil, fmt.Errorf("in sql.Open(): %w", err)
+	}
+	r, err := NewSQLRepository(ctx, db, dialect)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// NewSQLRepository は未適用のマイグレーションを適用してからSQLRepositoryを返す。
+func NewSQLRepository(ctx context.Context, db *sql.DB, dialect SQLDialect) (*SQLRepository, error) {
+	r := &SQLRepository{db: db, dialect: dialect}
+	if err := r.migrate(ctx); err != nil {
+		return nil, fmt.Errorf("in migrate(): %w", err)
+	}
+	return r, nil
+}
+
+// migrate はsqlmigrations以下のファイルをファイル名順に適用し、適用済みのバージョンをschema_migrationsに記録する。
+func (r *SQLRepository) migrate(ctx context.Context) error {
+	if _, err := r.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version TEXT PRIMARY KEY,
+    applied_at BIGINT NOT NULL
+)`); err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+	entries, err := fs.ReadDir(sqlMigrationFiles, "sqlmigrations")
+	if err != nil {
+		return fmt.Errorf("read migrations: %w", err)
+	}
+	for _, entry := range entries { // fs.ReadDirはファイル名順
+		version := strings.TrimSuffix(entry.Name(), ".sql")
+		var applied int
+		if err := r.db.QueryRowContext(ctx, r.rebind("SELECT COUNT(*) FROM schema_migrations WHERE version = ?"), version).Scan(&applied); err != nil {
+			return fmt.Errorf("check migration %s: %w", version, err)
+		}
+		if applied > 0 {
+			continue
+		}
+		content, err := sqlMigrationFiles.ReadFile("sqlmigrations/" + entry.Name())
+		if err != nil {
+			return fmt.Errorf("read migration %s: %w", version, err)
+		}
+		if err := r.applyMigration(ctx, version, string(content)); err != nil {
+			return fmt.Errorf("apply migration %s: %w", version, err)
+		}
+	}
+	return nil
+}
+
+func (r *SQLRepository) applyMigration(ctx context.Context, version string, content string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+	for _, statement := range splitSQLStatements(content) {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("%w: %s", err, statement)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, r.rebind("INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)"),
+		version, time.Now().UnixMicro()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// splitSQLStatements は行コメントを除いて;で区切る。マイグレーションの文字列リテラルに;や--を含めないこと。
+func splitSQLStatements(content string) []string {
+	var lines []string
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "--") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	var statements []string
+	for _, statement := range strings.Split(strings.Join(lines, "\n"), ";") {
+		if statement = strings.TrimSpace(statement); statement != "" {
+			statements = append(statements, statement)
+		}
+	}
+	return statements
+}
+
+// rebind は?のプレースホルダーをPostgreSQLの$1, $2, ...に書き換える。
+func (r *SQLRepository) rebind(query string) string {
+	if r.dialect != SQLDialectPostgres {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, c := range query {
+		if c == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// sqlQuerier *sql.DBと*sql.Txの共通部分
+type sqlQuerier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// sqlConn プレースホルダーを方言に合わせてからクエリを実行する。
+type sqlConn struct {
+	q sqlQuerier
+	r *SQLRepository
+}
+
+func (c sqlConn) exec(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return c.q.ExecContext(ctx, c.r.rebind(query), args...)
+}
+
+func (c sqlConn) query(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return c.q.QueryContext(ctx, c.r.rebind(query), args...)
+}
+
+func (c sqlConn) queryRow(ctx context.Context, query string, args ...any) *sql.Row {
+	return c.q.QueryRowContext(ctx, c.r.rebind(query), args...)
+}
+
+// sqlWrite はコミット時にトランザクション内で実行される書き込み。
+type sqlWrite func(ctx context.Context, conn sqlConn) error
+
+type sqlTransaction struct {
+	tx             *sql.Tx
+	writes         []sqlWrite
+	readAfterWrite bool
+	finished       bool
+}
+
+func (*sqlTransaction) transaction() {}
+
+func (r *SQLRepository) txOptions() *sql.TxOptions {
+	if r.dialect == SQLDialectPostgres {
+		// 同時に更新された行に書き込むとシリアライゼーションエラーになり、やり直せる
+		return &sql.TxOptions{Isolation: sql.LevelRepeatableRead}
+	}
+	return nil
+}
+
+// RunTransaction はfが成功したら保留していた書き込みを実行してコミットする。競合した場合はfからやり直す。
+func (r *SQLRepository) RunTransaction(ctx context.Context, f func(ctx context.Context, tx Transaction) error) error {
+	var err error
+	for attempt := 0; attempt < sqlTransactionMaxAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(attempt) * sqlRetryBaseInterval):
+			}
+		}
+		err = r.runTransactionOnce(ctx, f)
+		if err == nil || !isRetryableSQLError(err) {
+			return err
+		}
+	}
+	return fmt.Errorf("sql repository: transaction failed after %d attempts: %w", sqlTransactionMaxAttempts, err)
+}
+
+func (r *SQLRepository) runTransactionOnce(ctx context.Context, f func(ctx context.Context, tx Transaction) error) error {
+	sqlTx, err := r.db.BeginTx(ctx, r.txOptions())
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	state := &sqlTransaction{tx: sqlTx}
+	committed := false
+	defer func() {
+		state.finished = true
+		if !committed {
+			_ = sqlTx.Rollback()
+		}
+	}()
+
+	err = f(ctx, state)
+	if state.readAfterWrite {
+		return errSQLReadAfterWrite
+	}
+	if err != nil {
+		return err
+	}
+	conn := sqlConn{q: sqlTx, r: r}
+	for _, write := range state.writes {
+		if err := write(ctx, conn); err != nil {
+			return err
+		}
+	}
+	if err := sqlTx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	committed = true
+	return nil
+}
+
+// isRetryableSQLError は他のトランザクションとの競合による失敗かどうかを、ドライバーに依存せずに判定する。
+func isRetryableSQLError(err error) bool {
+	var pgErr interface{ SQLState() string }
+	if errors.As(err, &pgErr) {
+		switch pgErr.SQLState() {
+		case "40001", "40P01": // serialization_failure, deadlock_detected
+			return true
+		}
+	}
+	var sqliteErr interface{ Code() int }
+	if errors.As(err, &sqliteErr) {
+		switch sqliteErr.Code() & 0xff { // 拡張コード（SQLITE_BUSY_SNAPSHOTなど）は下位8ビットが基本コード
+		case 5, 6: // SQLITE_BUSY, SQLITE_LOCKED
+			return true
+		}
+	}
+	return false
+}
+
+func (r *SQLRepository) Close() error {
+	return r.db.Close()
+}
+
+func (r *SQLRepository) transaction(tx Transaction) (*sqlTransaction, error) {
+	state, ok := tx.(*sqlTransaction)
+	if !ok {
+		return nil, fmt.Errorf("sql repository: unexpected transaction type: %T", tx)
+	}
+	if state.finished {
+		return nil, errors.New("sql repository: finished transaction")
+	}
+	return state, nil
+}
+
+// reader はtxがnilならトランザクション外の、そうでなければtxの接続を返す。
+func (r *SQLRepository) reader(tx Transaction) (sqlConn, error) {
+	if tx == nil {
+		return sqlConn{q: r.db, r: r}, nil
+	}
+	state, err := r.transaction(tx)
+	if err != nil {
+		return sqlConn{}, err
+	}
+	if len(state.writes) > 0 {
+		state.readAfterWrite = true
+		return sqlConn{}, errSQLReadAfterWrite
+	}
+	return sqlConn{q: state.tx, r: r}, nil
+}
+
+// write はtxがnilなら単独のトランザクションで実行し、そうでなければコミットまで保留する。
+func (r *SQLRepository) write(ctx context.Context, tx Transaction, write sqlWrite) error {
+	if tx == nil {
+		return r.RunTransaction(ctx, func(ctx context.Context, tx Transaction) error {
+			return r.write(ctx, tx, write)
+		})
+	}
+	state, err := r.transaction(tx)
+	if err != nil {
+		return err
+	}
+	state.writes = append(state.writes, write)
+	return nil
+}
+
+// sqlTime 日時列（UTCのUnixマイクロ秒）を読み取る。FirestoreのTimestampと同じくマイクロ秒未満は切り捨てられる。
+type sqlTime struct {
+	t *time.Time
+}
+
+func (s sqlTime) Scan(src any) error {
+	v, ok := src.(int64)
+	if !ok {
+		return fmt.Errorf("unexpected time column type: %T", src)
+	}
+	*s.t = time.UnixMicro(v).UTC()
+	return nil
+}
+
+func sqlTimeValue(t time.Time) int64 {
+	return t.UnixMicro()
+}
+
+// sqlTableName はコレクション名をテーブル名にする。
+func sqlTableName(collection string) string {
+	return strings.ReplaceAll(collection, "-", "_")
+}
+
+// sqlCollections マイグレーションでテーブルを作成しているコレクション
+var sqlCollections = map[string]bool{
+	CONFIG: true, SEATS: true, MemberSeats: true, USERS: true, LiveChatHistory: true, UserActivities: true,
+	WorkSegments: true, DailyUserWorkHistory: true, UndoableExits: true, SeatReservations: true,
+	MemberSeatReservations: true, MENU: true, OrderHistory: true, SeatLimitsBlackList: true,
+	SeatLimitsWhiteList: true, MemberSeatLimitsBlackList: true, MemberSeatLimitsWhiteList: true, WorkNameTrend: true,
+	ModerationActions: true, YoutubeAPIQuotaUsage: true, FanFundingHistory: true,
+	ProcessedLiveChatMessages: true,
+}
+
+// sqlTable 1つのコレクションに対応するテーブルと、ドキュメントの型との対応
+type sqlTable[T any] struct {
+	collection string
+	columns    []string                                      // id以外の列
+	values     func(doc T) []any                             // columnsと同じ順の値
+	scan       func(scan func(dest ...any) error) (T, error) // columnsと同じ順に読み取る
+}
+
+func (t sqlTable[T]) name() string {
+	return sqlTableName(t.collection)
+}
+
+func (t sqlTable[T]) selectFrom(clause string) string {
+	return "SELECT " + strings.Join(t.columns, ", ") + " FROM " + t.name() + " " + clause
+}
+
+func (t sqlTable[T]) get(ctx context.Context, r *SQLRepository, tx Transaction, id string) (T, error) {
+	var zero T
+	conn, err := r.reader(tx)
+	if err != nil {
+		return zero, err
+	}
+	doc, err := t.scan(conn.queryRow(ctx, t.selectFrom("WHERE id = ?"), id).Scan)
+	if errors.Is(err, sql.ErrNoRows) {
+		return zero, notFoundError(t.collection, id)
+	}
+	if err != nil {
+		return zero, fmt.Errorf("get %s: %w", docPath(t.collection, id), err)
+	}
+	return doc, nil
+}
+
+// query はトランザクション外で読み取る。clauseにORDER BYがなければドキュメントID順にする。
+func (t sqlTable[T]) query(ctx context.Context, r *SQLRepository, clause string, args ...any) ([]T, error) {
+	return t.queryInTransaction(ctx, r, nil, clause, args...)
+}
+
+// queryInTransaction はtxがnilならトランザクション外で、そうでなければtxの中で読み取る。
+func (t sqlTable[T]) queryInTransaction(ctx context.Context, r *SQLRepository, tx Transaction, clause string, args ...any) ([]T, error) {
+	conn, err := r.reader(tx)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.Contains(clause, "ORDER BY") {
+		clause += " ORDER BY id"
+	}
+	rows, err := conn.query(ctx, t.selectFrom(clause), args...)
+	if err != nil {
+		return nil, fmt.Errorf("query %s: %w", t.collection, err)
+	}
+	defer rows.Close()
+	docs := make([]T, 0) // jsonになったときにnullとならないように。
+	for rows.Next() {
+		doc, err := t.scan(rows.Scan)
+		if err != nil {
+			return nil, fmt.Errorf("scan %s: %w", t.collection, err)
+		}
+		docs = append(docs, doc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query %s: %w", t.collection, err)
+	}
+	return docs, nil
+}
+
+// queryRefs は条件を満たすドキュメントをドキュメントID順に最大limit件（0なら無制限）返すイテレーターを作る。
+func (t sqlTable[T]) queryRefs(ctx context.Context, r *SQLRepository, limit int, clause string, args ...any) *documentRefIterator {
+	query := "SELECT id FROM " + t.name() + " " + clause + " ORDER BY id"
+	if limit > 0 {
+		query += " LIMIT " + strconv.Itoa(limit)
+	}
+	rows, err := r.db.QueryContext(ctx, r.rebind(query), args...)
+	if err != nil {
+		return &documentRefIterator{err: fmt.Errorf("query %s: %w", t.collection, err)}
+	}
+	defer rows.Close()
+	refs := make([]DocumentRef, 0)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return &documentRefIterator{err: fmt.Errorf("scan %s: %w", t.collection, err)}
+		}
+		refs = append(refs, DocumentRef{Collection: t.collection, ID: id})
+	}
+	if err := rows.Err(); err != nil {
+		return &documentRefIterator{err: fmt.Errorf("query %s: %w", t.collection, err)}
+	}
+	return &documentRefIterator{refs: refs}
+}
+
+func (t sqlTable[T]) insertQuery(onConflict string) string {
+	columns := append([]string{"id"}, t.columns...)
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")
+	return "INSERT INTO " + t.name() + " (" + strings.Join(columns, ", ") + ") VALUES (" + placeholders + ") ON CONFLICT (id) " + onConflict
+}
+
+// create はFirestoreのCreateと同じく、ドキュメントが既に存在すればAlreadyExistsとする。
+func (t sqlTable[T]) create(id string, doc T) sqlWrite {
+	return func(ctx context.Context, conn sqlConn) error {
+		result, err := conn.exec(ctx, t.insertQuery("DO NOTHING"), append([]any{id}, t.values(doc)...)...)
+		if err != nil {
+			return fmt.Errorf("create %s: %w", docPath(t.collection, id), err)
+		}
+		if n, err := result.RowsAffected(); err != nil {
+			return fmt.Errorf("create %s: %w", docPath(t.collection, id), err)
+		} else if n == 0 {
+			return status.Errorf(codes.AlreadyExists, "%q already exists", docPath(t.collection, id))
+		}
+		return nil
+	}
+}
+
+// set はドキュメント全体を上書きする。存在しなければ作成する。
+func (t sqlTable[T]) set(id string, doc T) sqlWrite {
+	assignments := make([]string, 0, len(t.columns))
+	for _, column := range t.columns {
+		assignments = append(assignments, column+" = excluded."+column)
+	}
+	query := t.insertQuery("DO UPDATE SET " + strings.Join(assignments, ", "))
+	return func(ctx context.Context, conn sqlConn) error {
+		if _, err := conn.exec(ctx, query, append([]any{id}, t.values(doc)...)...); err != nil {
+			return fmt.Errorf("set %s: %w", docPath(t.collection, id), err)
+		}
+		return nil
+	}
+}
+
+// sqlAssignment UPDATEで更新する列と値
+type sqlAssignment struct {
+	column string
+	value  any
+}
+
+// update はFirestoreのUpdateと同じく、ドキュメントが存在しなければNotFoundとする。
+func (t sqlTable[T]) update(id string, assignments ...sqlAssignment) sqlWrite {
+	sets := make([]string, 0, len(assignments))
+	args := make([]any, 0, len(assignments)+1)
+	for _, a := range assignments {
+		sets = append(sets, a.column+" = ?")
+		args = append(args, a.value)
+	}
+	args = append(args, id)
+	query := "UPDATE " + t.name() + " SET " + strings.Join(sets, ", ") + " WHERE id = ?"
+	return func(ctx context.Context, conn sqlConn) error {
+		result, err := conn.exec(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("update %s: %w", docPath(t.collection, id), err)
+		}
+		if n, err := result.RowsAffected(); err != nil {
+			return fmt.Errorf("update %s: %w", docPath(t.collection, id), err)
+		} else if n == 0 {
+			return notFoundError(t.collection, id)
+		}
+		return nil
+	}
+}
+
+func sqlDelete(collection string, id string) sqlWrite {
+	return func(ctx context.Context, conn sqlConn) error {
+		if _, err := conn.exec(ctx, "DELETE FROM "+sqlTableName(collection)+" WHERE id = ?", id); err != nil {
+			return fmt.Errorf("delete %s: %w", docPath(collection, id), err)
+		}
+		return nil
+	}
+}
+
+// sqlJSONTable 1行に1ドキュメントをJSONで保存するテーブル（config, undoable-exits, work-name-trend）
+func sqlJSONTable[T any](collection string, normalize func(doc *T)) sqlTable[T] {
+	return sqlTable[T]{
+		collection: collection,
+		columns:    []string{"data"},
+		values: func(doc T) []any {
+			data, err := json.Marshal(doc)
+			if err != nil {
+				// モデルの構造体はすべてJSONに変換できる
+				panic(fmt.Errorf("in json.Marshal(): %w", err))
+			}
+			return []any{string(data)}
+		},
+		scan: func(scan func(dest ...any) error) (T, error) {
+			var doc T
+			var data string
+			if err := scan(&data); err != nil {
+				return doc, err
+			}
+			if err := json.Unmarshal([]byte(data), &doc); err != nil {
+				return doc, fmt.Errorf("in json.Unmarshal(): %w", err)
+			}
+			if normalize != nil {
+				normalize(&doc)
+			}
+			return doc, nil
+		},
+	}
+}
+
+// updateJSON はJSONで保存したドキュメントを読み取って書き換える。存在しなければNotFoundとする。
+func updateJSON[T any](t sqlTable[T], id string, update func(doc *T)) sqlWrite {
+	return func(ctx context.Context, conn sqlConn) error {
+		doc, err := t.scan(conn.queryRow(ctx, t.selectFrom("WHERE id = ?"), id).Scan)
+		if errors.Is(err, sql.ErrNoRows) {
+			return notFoundError(t.collection, id)
+		}
+		if err != nil {
+			return fmt.Errorf("get %s: %w", docPath(t.collection, id), err)
+		}
+		update(&doc)
+		return t.set(id, doc)(ctx, conn)
+	}
+}
+
+// JSONにするとタイムゾーンが固定オフセットになるため、Firestoreと同じくUTCで返す。
+var (
+	sqlCredentialsTable = sqlJSONTable[CredentialsConfigDoc](CONFIG, nil)
+	sqlConstantsTable   = sqlJSONTable(CONFIG, func(doc *ConstantsConfigDoc) {
+		doc.LastResetDailyTotalStudySec = doc.LastResetDailyTotalStudySec.UTC()
+		doc.LastTransferCollectionHistoryBigquery = doc.LastTransferCollectionHistoryBigquery.UTC()
+	})
+	sqlUndoableExitsTable = sqlJSONTable(UndoableExits, func(doc *UndoableExitDoc) {
+		doc.Seat = utcSeat(doc.Seat)
+		doc.ExitedAt = doc.ExitedAt.UTC()
+		doc.PreviousLastExited = doc.PreviousLastExited.UTC()
+		for i := range doc.AddedDailyHistory {
+			doc.AddedDailyHistory[i].Date = doc.AddedDailyHistory[i].Date.UTC()
+		}
+	})
+	sqlWorkNameTrendTable = sqlJSONTable(WorkNameTrend, func(doc *WorkNameTrendDoc) {
+		doc.RankedAt = doc.RankedAt.UTC()
+	})
+	sqlYoutubeAPIQuotaUsageTable = sqlJSONTable[YoutubeAPIQuotaUsageDoc](YoutubeAPIQuotaUsage, nil)
+)
+
+func utcSeat(seat SeatDoc) SeatDoc {
+	seat.EnteredAt = seat.EnteredAt.UTC()
+	seat.Until = seat.Until.UTC()
+	seat.CurrentStateStartedAt = seat.CurrentStateStartedAt.UTC()
+	seat.CurrentStateUntil = seat.CurrentStateUntil.UTC()
+	seat.CurrentSegmentStartedAt = seat.CurrentSegmentStartedAt.UTC()
+	seat.DailyGoalCheckAt = seat.DailyGoalCheckAt.UTC()
+	return seat
+}
+
+func sqlSeatsTable(isMemberSeat bool) sqlTable[SeatDoc] {
+	return sqlTable[SeatDoc]{
+		collection: seatsCollectionName(isMemberSeat),
+		columns: []string{"seat_id", "user_id", "session_id", "user_display_name", "work_name", "break_work_name",
+			"entered_at", "until", "color_code1", "color_code2", "num_stars", "color_gradient_enabled", "menu_code",
+			"state", "current_state_started_at", "current_state_until", "current_segment_started_at",
+			"cumulative_work_sec", "daily_cumulative_work_sec", "user_profile_image_url", "pomodoro_work_min",
+			"pomodoro_break_min", "daily_goal_check_at"},
+		values: func(s SeatDoc) []any {
+			return []any{s.SeatID, s.UserID, s.SessionID, s.UserDisplayName, s.WorkName, s.BreakWorkName,
+				sqlTimeValue(s.EnteredAt), sqlTimeValue(s.Until), s.Appearance.ColorCode1, s.Appearance.ColorCode2,
+				s.Appearance.NumStars, s.Appearance.ColorGradientEnabled, s.MenuCode, string(s.State),
+				sqlTimeValue(s.CurrentStateStartedAt), sqlTimeValue(s.CurrentStateUntil),
+				sqlTimeValue(s.CurrentSegmentStartedAt), s.CumulativeWorkSec, s.DailyCumulativeWorkSec,
+				s.UserProfileImageURL, s.PomodoroWorkMin, s.PomodoroBreakMin, sqlTimeValue(s.DailyGoalCheckAt)}
+		},
+		scan: func(scan func(dest ...any) error) (SeatDoc, error) {
+			var s SeatDoc
+			var state string
+			err := scan(&s.SeatID, &s.UserID, &s.SessionID, &s.UserDisplayName, &s.WorkName, &s.BreakWorkName,
+				sqlTime{&s.EnteredAt}, sqlTime{&s.Until}, &s.Appearance.ColorCode1, &s.Appearance.ColorCode2,
+				&s.Appearance.NumStars, &s.Appearance.ColorGradientEnabled, &s.MenuCode, &state,
+				sqlTime{&s.CurrentStateStartedAt}, sqlTime{&s.CurrentStateUntil}, sqlTime{&s.CurrentSegmentStartedAt},
+				&s.CumulativeWorkSec, &s.DailyCumulativeWorkSec, &s.UserProfileImageURL, &s.PomodoroWorkMin,
+				&s.PomodoroBreakMin, sqlTime{&s.DailyGoalCheckAt})
+			s.State = SeatState(state)
+			return s, err
+		},
+	}
+}
+
+var sqlUsersTable = sqlTable[UserDoc]{
+	collection: USERS,
+	columns: []string{"daily_total_study_sec", "total_study_sec", "registration_date", "status_message",
+		"last_entered", "last_exited", "rank_visible", "default_study_min", "rank_point", "last_rp_processed",
+		"last_penalty_imposed_days", "is_continuous_active", "current_activity_state_started", "favorite_color",
+		"daily_goal_min", "daily_goal_achieved", "best_streak_days", "supporter_appearance_until"},
+	values: func(u UserDoc) []any {
+		return []any{u.DailyTotalStudySec, u.TotalStudySec, sqlTimeValue(u.RegistrationDate), u.StatusMessage,
+			sqlTimeValue(u.LastEntered), sqlTimeValue(u.LastExited), u.RankVisible, u.DefaultStudyMin, u.RankPoint,
+			sqlTimeValue(u.LastRPProcessed), u.LastPenaltyImposedDays, u.IsContinuousActive,
+			sqlTimeValue(u.CurrentActivityStateStarted), u.FavoriteColor, u.DailyGoalMin, u.DailyGoalAchieved,
+			u.BestStreakDays, sqlTimeValue(u.SupporterAppearanceUntil)}
+	},
+	scan: func(scan func(dest ...any) error) (UserDoc, error) {
+		var u UserDoc
+		err := scan(&u.DailyTotalStudySec, &u.TotalStudySec, sqlTime{&u.RegistrationDate}, &u.StatusMessage,
+			sqlTime{&u.LastEntered}, sqlTime{&u.LastExited}, &u.RankVisible, &u.DefaultStudyMin, &u.RankPoint,
+			sqlTime{&u.LastRPProcessed}, &u.LastPenaltyImposedDays, &u.IsContinuousActive,
+			sqlTime{&u.CurrentActivityStateStarted}, &u.FavoriteColor, &u.DailyGoalMin, &u.DailyGoalAchieved,
+			&u.BestStreakDays, sqlTime{&u.SupporterAppearanceUntil})
+		return u, err
+	},
+}
+
+var sqlLiveChatHistoryTable = sqlTable[LiveChatHistoryDoc]{
+	collection: LiveChatHistory,
+	columns: []string{"author_channel_id", "author_display_name", "author_profile_image_url",
+		"author_is_chat_moderator", "message_id", "live_chat_id", "message_text", "published_at", "type"},
+	values: func(h LiveChatHistoryDoc) []any {
+		return []any{h.AuthorChannelID, h.AuthorDisplayName, h.AuthorProfileImageURL, h.AuthorIsChatModerator, h.ID,
+			h.LiveChatID, h.MessageText, sqlTimeValue(h.PublishedAt), h.Type}
+	},
+	scan: func(scan func(dest ...any) error) (LiveChatHistoryDoc, error) {
+		var h LiveChatHistoryDoc
+		err := scan(&h.AuthorChannelID, &h.AuthorDisplayName, &h.AuthorProfileImageURL, &h.AuthorIsChatModerator,
+			&h.ID, &h.LiveChatID, &h.MessageText, sqlTime{&h.PublishedAt}, &h.Type)
+		return h, err
+	},
+}
+
+var sqlUserActivitiesTable = sqlTable[UserActivityDoc]{
+	collection: UserActivities,
+	columns:    []string{"user_id", "activity_type", "seat_id", "is_member_seat", "taken_at"},
+	values: func(a UserActivityDoc) []any {
+		return []any{a.UserID, string(a.ActivityType), a.SeatID, a.IsMemberSeat, sqlTimeValue(a.TakenAt)}
+	},
+	scan: func(scan func(dest ...any) error) (UserActivityDoc, error) {
+		var a UserActivityDoc
+		var activityType string
+		err := scan(&a.UserID, &activityType, &a.SeatID, &a.IsMemberSeat, sqlTime{&a.TakenAt})
+		a.ActivityType = UserActivityType(activityType)
+		return a, err
+	},
+}
+
+var sqlWorkSegmentsTable = sqlTable[WorkSegmentDoc]{
+	collection: WorkSegments,
+	columns: []string{"user_id", "seat_id", "is_member_seat", "session_id", "work_name", "segment_type",
+		"started_at", "ended_at", "duration_sec"},
+	values: func(w WorkSegmentDoc) []any {
+		return []any{w.UserID, w.SeatID, w.IsMemberSeat, w.SessionID, w.WorkName, string(w.SegmentType),
+			sqlTimeValue(w.StartedAt), sqlTimeValue(w.EndedAt), w.DurationSec}
+	},
+	scan: func(scan func(dest ...any) error) (WorkSegmentDoc, error) {
+		var w WorkSegmentDoc
+		var segmentType string
+		err := scan(&w.UserID, &w.SeatID, &w.IsMemberSeat, &w.SessionID, &w.WorkName, &segmentType,
+			sqlTime{&w.StartedAt}, sqlTime{&w.EndedAt}, &w.DurationSec)
+		w.SegmentType = SeatState(segmentType)
+		return w, err
+	},
+}
+
+var sqlDailyUserWorkHistoryTable = sqlTable[DailyUserWorkHistoryDoc]{
+	collection: DailyUserWorkHistory,
+	columns:    []string{"user_id", "date", "total_study_sec", "total_break_sec", "timezone_name"},
+	values: func(h DailyUserWorkHistoryDoc) []any {
+		return []any{h.UserID, sqlTimeValue(h.Date), h.TotalStudySec, h.TotalBreakSec, h.TimezoneName}
+	},
+	scan: func(scan func(dest ...any) error) (DailyUserWorkHistoryDoc, error) {
+		var h DailyUserWorkHistoryDoc
+		err := scan(&h.UserID, sqlTime{&h.Date}, &h.TotalStudySec, &h.TotalBreakSec, &h.TimezoneName)
+		return h, err
+	},
+}
+
+func sqlSeatReservationsTable(isMemberSeat bool) sqlTable[SeatReservationDoc] {
+	return sqlTable[SeatReservationDoc]{
+		collection: seatReservationsCollectionName(isMemberSeat),
+		columns:    []string{"user_id", "user_display_name", "seat_id", "start_at", "until", "created_at"},
+		values: func(s SeatReservationDoc) []any {
+			return []any{s.UserID, s.UserDisplayName, s.SeatID, sqlTimeValue(s.StartAt), sqlTimeValue(s.Until),
+				sqlTimeValue(s.CreatedAt)}
+		},
+		scan: func(scan func(dest ...any) error) (SeatReservationDoc, error) {
+			var s SeatReservationDoc
+			err := scan(&s.UserID, &s.UserDisplayName, &s.SeatID, sqlTime{&s.StartAt}, sqlTime{&s.Until},
+				sqlTime{&s.CreatedAt})
+			return s, err
+		},
+	}
+}
+
+func sqlSeatLimitsTable(collection string) sqlTable[SeatLimitDoc] {
+	return sqlTable[SeatLimitDoc]{
+		collection: collection,
+		columns:    []string{"seat_id", "user_id", "created_at", "until"},
+		values: func(l SeatLimitDoc) []any {
+			return []any{l.SeatID, l.UserID, sqlTimeValue(l.CreatedAt), sqlTimeValue(l.Until)}
+		},
+		scan: func(scan func(dest ...any) error) (SeatLimitDoc, error) {
+			var l SeatLimitDoc
+			err := scan(&l.SeatID, &l.UserID, sqlTime{&l.CreatedAt}, sqlTime{&l.Until})
+			return l, err
+		},
+	}
+}
+
+var sqlMenuTable = sqlTable[MenuDoc]{
+	collection: MENU,
+	columns:    []string{"code", "name"},
+	values:     func(m MenuDoc) []any { return []any{m.Code, m.Name} },
+	scan: func(scan func(dest ...any) error) (MenuDoc, error) {
+		var m MenuDoc
+		err := scan(&m.Code, &m.Name)
+		return m, err
+	},
+}
+
+var sqlOrderHistoryTable = sqlTable[OrderHistoryDoc]{
+	collection: OrderHistory,
+	columns:    []string{"user_id", "menu_code", "seat_id", "is_member_seat", "ordered_at"},
+	values: func(o OrderHistoryDoc) []any {
+		return []any{o.UserID, o.MenuCode, o.SeatID, o.IsMemberSeat, sqlTimeValue(o.OrderedAt)}
+	},
+	scan: func(scan func(dest ...any) error) (OrderHistoryDoc, error) {
+		var o OrderHistoryDoc
+		err := scan(&o.UserID, &o.MenuCode, &o.SeatID, &o.IsMemberSeat, sqlTime{&o.OrderedAt})
+		return o, err
+	},
+}
+
+var sqlModerationActionsTable = sqlTable[ModerationActionDoc]{
+	collection: ModerationActions,
+	columns: []string{"action_type", "actor_user_id", "actor_display_name", "target_user_id", "target_display_name",
+		"seat_id", "is_member_seat", "matched_regex", "message_text", "ban_id", "timeout_duration_sec", "taken_at"},
+	values: func(m ModerationActionDoc) []any {
+		return []any{string(m.ActionType), m.ActorUserID, m.ActorDisplayName, m.TargetUserID, m.TargetDisplayName,
+			m.SeatID, m.IsMemberSeat, m.MatchedRegex, m.MessageText, m.BanID, m.TimeoutDurationSec, sqlTimeValue(m.TakenAt)}
+	},
+	scan: func(scan func(dest ...any) error) (ModerationActionDoc, error) {
+		var m ModerationActionDoc
+		var actionType string
+		err := scan(&actionType, &m.ActorUserID, &m.ActorDisplayName, &m.TargetUserID, &m.TargetDisplayName,
+			&m.SeatID, &m.IsMemberSeat, &m.MatchedRegex, &m.MessageText, &m.BanID, &m.TimeoutDurationSec, sqlTime{&m.TakenAt})
+		m.ActionType = ModerationActionType(actionType)
+		return m, err
+	},
+}
+
+var sqlFanFundingHistoryTable = sqlTable[FanFundingHistoryDoc]{
+	collection: FanFundingHistory,
+	columns: []string{"event_type", "user_id", "user_display_name", "amount_micros", "currency",
+		"amount_display_string", "membership_level_name", "gift_count", "reward_rp", "reward_extended_min",
+		"reward_appearance_until", "published_at"},
+	values: func(h FanFundingHistoryDoc) []any {
+		return []any{string(h.EventType), h.UserID, h.UserDisplayName, h.AmountMicros, h.Currency,
+			h.AmountDisplayString, h.MembershipLevelName, h.GiftCount, h.RewardRP, h.RewardExtendedMin,
+			sqlTimeValue(h.RewardAppearanceUntil), sqlTimeValue(h.PublishedAt)}
+	},
+	scan: func(scan func(dest ...any) error) (FanFundingHistoryDoc, error) {
+		var h FanFundingHistoryDoc
+		var eventType string
+		err := scan(&eventType, &h.UserID, &h.UserDisplayName, &h.AmountMicros, &h.Currency,
+			&h.AmountDisplayString, &h.MembershipLevelName, &h.GiftCount, &h.RewardRP, &h.RewardExtendedMin,
+			sqlTime{&h.RewardAppearanceUntil}, sqlTime{&h.PublishedAt})
+		h.EventType = FanFundingEventType(eventType)
+		return h, err
+	},
+}
+
+var sqlProcessedLiveChatMessagesTable = sqlTable[ProcessedLiveChatMessageDoc]{
+	collection: ProcessedLiveChatMessages,
+	columns:    []string{"user_id", "processed_at", "expire_at"},
+	values: func(m ProcessedLiveChatMessageDoc) []any {
+		return []any{m.UserID, sqlTimeValue(m.ProcessedAt), sqlTimeValue(m.ExpireAt)}
+	},
+	scan: func(scan func(dest ...any) error) (ProcessedLiveChatMessageDoc, error) {
+		var m ProcessedLiveChatMessageDoc
+		err := scan(&m.UserID, sqlTime{&m.ProcessedAt}, sqlTime{&m.ExpireAt})
+		return m, err
+	},
+}
+
+// SetCredentialsConfig はcredentialsの設定ドキュメントを上書きする。Repositoryには作成する操作がないため、初期データの投入用。
+func (r *SQLRepository) SetCredentialsConfig(ctx context.Context, doc CredentialsConfigDoc) error {
+	return r.write(ctx, nil, sqlCredentialsTable.set(CredentialsConfigDocName, doc))
+}
+
+// SetSystemConstantsConfig はconstantsの設定ドキュメントを上書きする。初期データの投入用。
+func (r *SQLRepository) SetSystemConstantsConfig(ctx context.Context, doc ConstantsConfigDoc) error {
+	return r.write(ctx, nil, sqlConstantsTable.set(SystemConstantsConfigDocName, doc))
+}
+
+// SetMenuDoc はメニューのドキュメントを上書きする。初期データの投入用。
+func (r *SQLRepository) SetMenuDoc(ctx context.Context, menu MenuDoc) error {
+	return r.write(ctx, nil, sqlMenuTable.set(menu.Code, menu))
+}
+
+func (r *SQLRepository) DeleteDocRef(ctx context.Context, tx Transaction, ref DocumentRef) error {
+	if !sqlCollections[ref.Collection] {
+		return fmt.Errorf("sql repository: unknown collection: %s", ref.Collection)
+	}
+	return r.write(ctx, tx, sqlDelete(ref.Collection, ref.ID))
+}
+
+func (r *SQLRepository) ReadCredentialsConfig(ctx context.Context, tx Transaction) (CredentialsConfigDoc, error) {
+	return sqlCredentialsTable.get(ctx, r, tx, CredentialsConfigDocName)
+}
+
+func (r *SQLRepository) ReadSystemConstantsConfig(ctx context.Context, tx Transaction) (ConstantsConfigDoc, error) {
+	return sqlConstantsTable.get(ctx, r, tx, SystemConstantsConfigDocName)
+}
+
+func (r *SQLRepository) ReadLiveChatID(ctx context.Context, tx Transaction) (string, error) {
+	credentialsDoc, err := r.ReadCredentialsConfig(ctx, tx)
+	if err != nil {
+		return "", fmt.Errorf("in ReadCredentialsConfig: %w", err)
+	}
+	return credentialsDoc.YoutubeLiveChatID, nil
+}
+
+func (r *SQLRepository) ReadNextPageToken(ctx context.Context, tx Transaction) (string, error) {
+	credentialsDoc, err := r.ReadCredentialsConfig(ctx, tx)
+	if err != nil {
+		return "", fmt.Errorf("in ReadCredentialsConfig: %w", err)
+	}
+	return credentialsDoc.YoutubeLiveChatNextPageToken, nil
+}
+
+func (r *SQLRepository) UpdateNextPageToken(ctx context.Context, nextPageToken string) error {
+	return r.write(ctx, nil, updateJSON(sqlCredentialsTable, CredentialsConfigDocName, func(doc *CredentialsConfigDoc) {
+		doc.YoutubeLiveChatNextPageToken = nextPageToken
+	}))
+}
+
+func (r *SQLRepository) ReadGeneralSeats(ctx context.Context) ([]SeatDoc, error) {
+	return sqlSeatsTable(false).query(ctx, r, "")
+}
+
+func (r *SQLRepository) ReadMemberSeats(ctx context.Context) ([]SeatDoc, error) {
+	return sqlSeatsTable(true).query(ctx, r, "")
+}
+
+func (r *SQLRepository) ReadSeatsExpiredUntil(ctx context.Context, thresholdTime time.Time, isMemberSeat bool) ([]SeatDoc, error) {
+	return sqlSeatsTable(isMemberSeat).query(ctx, r, "WHERE until < ?", sqlTimeValue(thresholdTime))
+}
+
+func (r *SQLRepository) ReadSeatsExpiredBreakUntil(ctx context.Context, thresholdTime time.Time, isMemberSeat bool) ([]SeatDoc, error) {
+	return sqlSeatsTable(isMemberSeat).query(ctx, r, "WHERE state = ? AND current_state_until < ?",
+		string(BreakState), sqlTimeValue(thresholdTime))
+}
+
+func (r *SQLRepository) ReadSeatsExpiredWorkUntil(ctx context.Context, thresholdTime time.Time, isMemberSeat bool) ([]SeatDoc, error) {
+	return sqlSeatsTable(isMemberSeat).query(ctx, r, "WHERE state = ? AND current_state_until < ?",
+		string(WorkState), sqlTimeValue(thresholdTime))
+}
+
+func (r *SQLRepository) ReadSeat(ctx context.Context, tx Transaction, seatID int, isMemberSeat bool) (SeatDoc, error) {
+	return sqlSeatsTable(isMemberSeat).get(ctx, r, tx, strconv.Itoa(seatID))
+}
+
+func (r *SQLRepository) ReadSeatWithUserID(ctx context.Context, userID string, isMemberSeat bool) (SeatDoc, error) {
+	seats, err := sqlSeatsTable(isMemberSeat).query(ctx, r, "WHERE user_id = ?", userID)
+	if err != nil {
+		return SeatDoc{}, err
+	}
+	if len(seats) >= 2 {
+		return SeatDoc{}, errors.New("There are more than two seats with the user id = " + userID + " !!")
+	}
+	if len(seats) == 1 {
+		return seats[0], nil
+	}
+	return SeatDoc{}, status.Errorf(codes.NotFound, "%s not found", "the document with user id = "+userID)
+}
+
+func (r *SQLRepository) ReadActiveWorkNameSeats(ctx context.Context, isMemberSeat bool) ([]SeatDoc, error) {
+	return sqlSeatsTable(isMemberSeat).query(ctx, r, "WHERE work_name <> ''")
+}
+
+func (r *SQLRepository) CreateSeat(tx Transaction, seat SeatDoc, isMemberSeat bool) error {
+	if tx == nil {
+		return errors.New("transaction is required")
+	}
+	return r.write(context.Background(), tx, sqlSeatsTable(isMemberSeat).create(strconv.Itoa(seat.SeatID), seat))
+}
+
+func (r *SQLRepository) UpdateSeat(ctx context.Context, tx Transaction, seat SeatDoc, isMemberSeat bool) error {
+	return r.write(ctx, tx, sqlSeatsTable(isMemberSeat).set(strconv.Itoa(seat.SeatID), seat))
+}
+
+func (r *SQLRepository) DeleteSeat(ctx context.Context, tx Transaction, seatID int, isMemberSeat bool) error {
+	return r.write(ctx, tx, sqlDelete(seatsCollectionName(isMemberSeat), strconv.Itoa(seatID)))
+}
+
+func (r *SQLRepository) ReadUser(ctx context.Context, tx Transaction, userID string) (UserDoc, error) {
+	return sqlUsersTable.get(ctx, r, tx, userID)
+}
+
+func (r *SQLRepository) CreateUser(ctx context.Context, tx Transaction, userID string, userData UserDoc) error {
+	return r.write(ctx, tx, sqlUsersTable.create(userID, userData))
+}
+
+// updateUser はトランザクション内でのみ呼ばれる更新に使う。
+func (r *SQLRepository) updateUser(tx Transaction, userID string, assignments ...sqlAssignment) error {
+	if tx == nil {
+		return errors.New("transaction is required")
+	}
+	return r.write(context.Background(), tx, sqlUsersTable.update(userID, assignments...))
+}
+
+func (r *SQLRepository) UpdateUserLastEnteredDate(tx Transaction, userID string, enteredDate time.Time) error {
+	return r.updateUser(tx, userID, sqlAssignment{"last_entered", sqlTimeValue(enteredDate)})
+}
+
+func (r *SQLRepository) UpdateUserLastExitedDate(tx Transaction, userID string, exitedDate time.Time) error {
+	return r.updateUser(tx, userID, sqlAssignment{"last_exited", sqlTimeValue(exitedDate)})
+}
+
+func (r *SQLRepository) UpdateUserRankVisible(tx Transaction, userID string, rankVisible bool) error {
+	return r.updateUser(tx, userID, sqlAssignment{"rank_visible", rankVisible})
+}
+
+func (r *SQLRepository) UpdateUserDefaultStudyMin(tx Transaction, userID string, defaultStudyMin int) error {
+	return r.updateUser(tx, userID, sqlAssignment{"default_study_min", defaultStudyMin})
+}
+
+func (r *SQLRepository) UpdateUserFavoriteColor(tx Transaction, userID string, colorCode string) error {
+	return r.updateUser(tx, userID, sqlAssignment{"favorite_color", colorCode})
+}
+
+// UpdateUserDailyGoalMin はFirestoreの実装と同じく達成フラグもリセットする。
+func (r *SQLRepository) UpdateUserDailyGoalMin(tx Transaction, userID string, dailyGoalMin int) error {
+	return r.updateUser(tx, userID, sqlAssignment{"daily_goal_min", dailyGoalMin}, sqlAssignment{"daily_goal_achieved", false})
+}
+
+func (r *SQLRepository) UpdateUserDailyGoalAchieved(tx Transaction, userID string, achieved bool) error {
+	return r.updateUser(tx, userID, sqlAssignment{"daily_goal_achieved", achieved})
+}
+
+func (r *SQLRepository) UpdateUserTotalTime(tx Transaction, userID string, newTotalTimeSec int, newDailyTotalTimeSec int) error {
+	return r.updateUser(tx, userID, sqlAssignment{"daily_total_study_sec", newDailyTotalTimeSec},
+		sqlAssignment{"total_study_sec", newTotalTimeSec})
+}
+
+func (r *SQLRepository) UpdateUserSupporterAppearanceUntil(tx Transaction, userID string, until time.Time) error {
+	return r.updateUser(tx, userID, sqlAssignment{"supporter_appearance_until", sqlTimeValue(until)})
+}
+
+func (r *SQLRepository) UpdateUserRankPoint(tx Transaction, userID string, rp int) error {
+	return r.updateUser(tx, userID, sqlAssignment{"rank_point", rp})
+}
+
+func (r *SQLRepository) UpdateUserLastRPProcessed(tx Transaction, userID string, date time.Time) error {
+	return r.updateUser(tx, userID, sqlAssignment{"last_rp_processed", sqlTimeValue(date)})
+}
+
+func (r *SQLRepository) UpdateUserRPAndLastPenaltyImposedDays(ctx context.Context, tx Transaction, userID string, newRP int, newLastPenaltyImposedDays int) error {
+	return r.write(ctx, tx, sqlUsersTable.update(userID, sqlAssignment{"rank_point", newRP},
+		sqlAssignment{"last_penalty_imposed_days", newLastPenaltyImposedDays}))
+}
+
+func (r *SQLRepository) UpdateUserIsContinuousActiveAndCurrentActivityStateStarted(ctx context.Context, tx Transaction, userID string, isContinuousActive bool, currentActivityStateStarted time.Time) error {
+	return r.write(ctx, tx, sqlUsersTable.update(userID, sqlAssignment{"is_continuous_active", isContinuousActive},
+		sqlAssignment{"current_activity_state_started", sqlTimeValue(currentActivityStateStarted)}))
+}
+
+func (r *SQLRepository) UpdateUserLastPenaltyImposedDays(ctx context.Context, tx Transaction, userID string, lastPenaltyImposedDays int) error {
+	return r.write(ctx, tx, sqlUsersTable.update(userID, sqlAssignment{"last_penalty_imposed_days", lastPenaltyImposedDays}))
+}
+
+func (r *SQLRepository) UpdateUserBestStreakDays(ctx context.Context, tx Transaction, userID string, bestStreakDays int) error {
+	return r.write(ctx, tx, sqlUsersTable.update(userID, sqlAssignment{"best_streak_days", bestStreakDays}))
+}
+
+func (r *SQLRepository) UpdateLiveChatID(ctx context.Context, tx Transaction, liveChatID string) error {
+	return r.write(ctx, tx, updateJSON(sqlCredentialsTable, CredentialsConfigDocName, func(doc *CredentialsConfigDoc) {
+		doc.YoutubeLiveChatID = liveChatID
+	}))
+}
+
+func (r *SQLRepository) CreateLiveChatHistoryDoc(ctx context.Context, tx Transaction, liveChatHistoryDoc LiveChatHistoryDoc) error {
+	return r.write(ctx, tx, sqlLiveChatHistoryTable.create(newDocID(), liveChatHistoryDoc))
+}
+
+func (r *SQLRepository) Get500LiveChatHistoryDocIDsBeforeDate(ctx context.Context, date time.Time) DocumentIterator {
+	return sqlLiveChatHistoryTable.queryRefs(ctx, r, FirestoreWritesLimitPerRequest, "WHERE published_at < ?", sqlTimeValue(date))
+}
+
+func (r *SQLRepository) CreateUserActivityDoc(ctx context.Context, tx Transaction, activity UserActivityDoc) error {
+	return r.write(ctx, tx, sqlUserActivitiesTable.create(newDocID(), activity))
+}
+
+func (r *SQLRepository) Get500UserActivityDocIDsBeforeDate(ctx context.Context, date time.Time) DocumentIterator {
+	return sqlUserActivitiesTable.queryRefs(ctx, r, FirestoreWritesLimitPerRequest, "WHERE taken_at < ?", sqlTimeValue(date))
+}
+
+func (r *SQLRepository) GetAllUserActivityDocIDsAfterDate(ctx context.Context, date time.Time) DocumentIterator {
+	return sqlUserActivitiesTable.queryRefs(ctx, r, 0, "WHERE taken_at >= ?", sqlTimeValue(date))
+}
+
+func (r *SQLRepository) Get500OrderHistoryDocIDsBeforeDate(ctx context.Context, date time.Time) DocumentIterator {
+	return sqlOrderHistoryTable.queryRefs(ctx, r, FirestoreWritesLimitPerRequest, "WHERE ordered_at < ?", sqlTimeValue(date))
+}
+
+func (r *SQLRepository) readUserActivitiesAfterDateForUserAndSeat(ctx context.Context, date time.Time, userID string, seatID int, isMemberSeat bool, activityType UserActivityType) ([]UserActivityDoc, error) {
+	return sqlUserActivitiesTable.query(ctx, r,
+		"WHERE taken_at >= ? AND user_id = ? AND seat_id = ? AND activity_type = ? AND is_member_seat = ? ORDER BY taken_at, id",
+		sqlTimeValue(date), userID, seatID, string(activityType), isMemberSeat)
+}
+
+func (r *SQLRepository) GetEnterRoomUserActivityDocIDsAfterDateForUserAndSeat(ctx context.Context, date time.Time, userID string, seatID int, isMemberSeat bool) ([]UserActivityDoc, error) {
+	return r.readUserActivitiesAfterDateForUserAndSeat(ctx, date, userID, seatID, isMemberSeat, EnterRoomActivity)
+}
+
+func (r *SQLRepository) GetExitRoomUserActivityDocIDsAfterDateForUserAndSeat(ctx context.Context, date time.Time, userID string, seatID int, isMemberSeat bool) ([]UserActivityDoc, error) {
+	return r.readUserActivitiesAfterDateForUserAndSeat(ctx, date, userID, seatID, isMemberSeat, ExitRoomActivity)
+}
+
+// GetUsersActiveAfterDate date以後に入室したことのあるuserを全て取得
+func (r *SQLRepository) GetUsersActiveAfterDate(ctx context.Context, date time.Time) DocumentIterator {
+	return sqlUsersTable.queryRefs(ctx, r, 0, "WHERE last_entered >= ?", sqlTimeValue(date))
+}
+
+func (r *SQLRepository) CreateWorkSegmentDoc(ctx context.Context, tx Transaction, workSegment WorkSegmentDoc) error {
+	return r.write(ctx, tx, sqlWorkSegmentsTable.create(newDocID(), workSegment))
+}
+
+func (r *SQLRepository) ReadWorkStateSegmentsBySessionID(ctx context.Context, sessionID string) ([]WorkSegmentDoc, error) {
+	return sqlWorkSegmentsTable.query(ctx, r, "WHERE session_id = ? AND segment_type = ?", sessionID, string(WorkState))
+}
+
+func (r *SQLRepository) ReadWorkSegmentsByUserIDAndTimeRange(ctx context.Context, userID string, from time.Time, to time.Time, limit int) ([]WorkSegmentDoc, error) {
+	clause := "WHERE user_id = ? AND started_at >= ? AND started_at < ? ORDER BY started_at DESC, id"
+	if limit > 0 {
+		clause += " LIMIT " + strconv.Itoa(limit)
+	}
+	return sqlWorkSegmentsTable.query(ctx, r, clause, userID, sqlTimeValue(from), sqlTimeValue(to))
+}
+
+func (r *SQLRepository) ReadWorkSegmentsPageByTimeRange(ctx context.Context, from time.Time, to time.Time, startAfter WorkSegmentDocWithID, limit int) ([]WorkSegmentDocWithID, error) {
+	clause := " WHERE started_at >= ? AND started_at < ?"
+	args := []any{sqlTimeValue(from), sqlTimeValue(to)}
+	if startAfter.SegmentID != "" {
+		clause += " AND (started_at > ? OR (started_at = ? AND id > ?))"
+		args = append(args, sqlTimeValue(startAfter.StartedAt), sqlTimeValue(startAfter.StartedAt), startAfter.SegmentID)
+	}
+	query := "SELECT id, " + strings.Join(sqlWorkSegmentsTable.columns, ", ") + " FROM " + sqlWorkSegmentsTable.name() +
+		clause + " ORDER BY started_at, id LIMIT " + strconv.Itoa(limit)
+	rows, err := r.db.QueryContext(ctx, r.rebind(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("query %s: %w", WorkSegments, err)
+	}
+	defer rows.Close()
+	segments := make([]WorkSegmentDocWithID, 0, limit)
+	for rows.Next() {
+		var segmentID string
+		segment, err := sqlWorkSegmentsTable.scan(func(dest ...any) error {
+			return rows.Scan(append([]any{&segmentID}, dest...)...)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("scan %s: %w", WorkSegments, err)
+		}
+		segments = append(segments, WorkSegmentDocWithID{SegmentID: segmentID, WorkSegmentDoc: segment})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query %s: %w", WorkSegments, err)
+	}
+	return segments, nil
+}
+
+func (r *SQLRepository) ReadDailyUserWorkHistory(ctx context.Context, tx Transaction, userID string, date time.Time) (DailyUserWorkHistoryDoc, error) {
+	history, err := sqlDailyUserWorkHistoryTable.get(ctx, r, tx, dailyUserWorkHistoryDocID(userID, date))
+	if err != nil {
+		return DailyUserWorkHistoryDoc{}, fmt.Errorf("get daily user work history: %w", err)
+	}
+	return history, nil
+}
+
+// AddDailyUserWorkHistory はFirestoreのIncrementと同じく、ドキュメントがなければ0に加算したものとして作成する。
+func (r *SQLRepository) AddDailyUserWorkHistory(ctx context.Context, tx Transaction, userID string, date time.Time, studySec int, breakSec int) error {
+	t := sqlDailyUserWorkHistoryTable
+	id := dailyUserWorkHistoryDocID(userID, date)
+	query := t.insertQuery(`DO UPDATE SET user_id = excluded.user_id, date = excluded.date,
+    total_study_sec = ` + t.name() + `.total_study_sec + excluded.total_study_sec,
+    total_break_sec = ` + t.name() + `.total_break_sec + excluded.total_break_sec,
+    timezone_name = excluded.timezone_name`)
+	history := DailyUserWorkHistoryDoc{
+		UserID:        userID,
+		Date:          timeutil.StartOfDayJST(date),
+		TotalStudySec: studySec,
+		TotalBreakSec: breakSec,
+		TimezoneName:  timeutil.JapanLocation().String(),
+	}
+	return r.write(ctx, tx, func(ctx context.Context, conn sqlConn) error {
+		if _, err := conn.exec(ctx, query, append([]any{id}, t.values(history)...)...); err != nil {
+			return fmt.Errorf("add %s: %w", docPath(t.collection, id), err)
+		}
+		return nil
+	})
+}
+
+func (r *SQLRepository) SetDailyUserWorkHistory(ctx context.Context, tx Transaction, history DailyUserWorkHistoryDoc) error {
+	return r.write(ctx, tx, sqlDailyUserWorkHistoryTable.set(dailyUserWorkHistoryDocID(history.UserID, history.Date), history))
+}
+
+func (r *SQLRepository) ReadUndoableExit(ctx context.Context, tx Transaction, userID string) (UndoableExitDoc, error) {
+	return sqlUndoableExitsTable.get(ctx, r, tx, userID)
+}
+
+func (r *SQLRepository) SetUndoableExit(ctx context.Context, tx Transaction, undoableExit UndoableExitDoc) error {
+	return r.write(ctx, tx, sqlUndoableExitsTable.set(undoableExit.UserID, undoableExit))
+}
+
+func (r *SQLRepository) DeleteUndoableExit(ctx context.Context, tx Transaction, userID string) error {
+	return r.write(ctx, tx, sqlDelete(UndoableExits, userID))
+}
+
+// Get500UndoableExitDocIDsBeforeDate undoable_exitsはJSONで保存していて退室時刻の列がないため、読み取ってから絞り込む。
+// ドキュメントはユーザーごとに1件までなので全件読んでも多くはない。
+func (r *SQLRepository) Get500UndoableExitDocIDsBeforeDate(ctx context.Context, date time.Time) DocumentIterator {
+	undoableExits, err := sqlUndoableExitsTable.query(ctx, r, "")
+	if err != nil {
+		return &documentRefIterator{err: err}
+	}
+	refs := make([]DocumentRef, 0)
+	for _, undoableExit := range undoableExits {
+		if len(refs) == FirestoreWritesLimitPerRequest {
+			break
+		}
+		if undoableExit.ExitedAt.Before(date) {
+			refs = append(refs, DocumentRef{Collection: UndoableExits, ID: undoableExit.UserID})
+		}
+	}
+	return &documentRefIterator{refs: refs}
+}
+
+// readSeatReservations は採番したReservationIDをidから埋める。
+func (r *SQLRepository) readSeatReservations(ctx context.Context, tx Transaction, isMemberSeat bool, clause string, args ...any) ([]SeatReservationDoc, error) {
+	t := sqlSeatReservationsTable(isMemberSeat)
+	t.columns = append([]string{"id"}, t.columns...)
+	scan := t.scan
+	t.scan = func(rowScan func(dest ...any) error) (SeatReservationDoc, error) {
+		var id string
+		reservation, err := scan(func(dest ...any) error {
+			return rowScan(append([]any{&id}, dest...)...)
+		})
+		reservation.ReservationID = id
+		return reservation, err
+	}
+	return t.queryInTransaction(ctx, r, tx, clause, args...)
+}
+
+func (r *SQLRepository) ReadSeatReservationsWithUserID(ctx context.Context, tx Transaction, userID string, isMemberSeat bool) ([]SeatReservationDoc, error) {
+	return r.readSeatReservations(ctx, tx, isMemberSeat, "WHERE user_id = ?", userID)
+}
+
+func (r *SQLRepository) ReadSeatReservationsWithSeatID(ctx context.Context, tx Transaction, seatID int, isMemberSeat bool) ([]SeatReservationDoc, error) {
+	return r.readSeatReservations(ctx, tx, isMemberSeat, "WHERE seat_id = ?", seatID)
+}
+
+func (r *SQLRepository) ReadSeatReservationsStartBefore(ctx context.Context, thresholdTime time.Time, isMemberSeat bool, limit int) ([]SeatReservationDoc, error) {
+	clause := "WHERE start_at < ? ORDER BY start_at, id"
+	if limit > 0 {
+		clause += " LIMIT " + strconv.Itoa(limit)
+	}
+	return r.readSeatReservations(ctx, nil, isMemberSeat, clause, sqlTimeValue(thresholdTime))
+}
+
+// CreateSeatReservation は予約を作成する。ReservationIDはここで採番する。
+func (r *SQLRepository) CreateSeatReservation(ctx context.Context, tx Transaction, reservation SeatReservationDoc, isMemberSeat bool) error {
+	reservation.ReservationID = newDocID()
+	return r.write(ctx, tx, sqlSeatReservationsTable(isMemberSeat).create(reservation.ReservationID, reservation))
+}
+
+func (r *SQLRepository) DeleteSeatReservation(ctx context.Context, tx Transaction, reservationID string, isMemberSeat bool) error {
+	return r.write(ctx, tx, sqlDelete(seatReservationsCollectionName(isMemberSeat), reservationID))
+}
+
+func (r *SQLRepository) ReadSeatLimitsWHITEListWithSeatIDAndUserID(ctx context.Context, seatID int, userID string, isMemberSeat bool) ([]SeatLimitDoc, error) {
+	return sqlSeatLimitsTable(seatLimitsWHITEListCollectionName(isMemberSeat)).query(ctx, r, "WHERE seat_id = ? AND user_id = ?", seatID, userID)
+}
+
+func (r *SQLRepository) ReadSeatLimitsBLACKListWithSeatIDAndUserID(ctx context.Context, seatID int, userID string, isMemberSeat bool) ([]SeatLimitDoc, error) {
+	return sqlSeatLimitsTable(seatLimitsBLACKListCollectionName(isMemberSeat)).query(ctx, r, "WHERE seat_id = ? AND user_id = ?", seatID, userID)
+}
+
+func (r *SQLRepository) CreateSeatLimitInWHITEList(ctx context.Context, seatID int, userID string, createdAt, until time.Time, isMemberSeat bool) error {
+	return r.write(ctx, nil, sqlSeatLimitsTable(seatLimitsWHITEListCollectionName(isMemberSeat)).create(newDocID(), SeatLimitDoc{
+		SeatID:    seatID,
+		UserID:    userID,
+		CreatedAt: createdAt,
+		Until:     until,
+	}))
+}
+
+func (r *SQLRepository) CreateSeatLimitInBLACKList(ctx context.Context, seatID int, userID string, createdAt, until time.Time, isMemberSeat bool) error {
+	return r.write(ctx, nil, sqlSeatLimitsTable(seatLimitsBLACKListCollectionName(isMemberSeat)).create(newDocID(), SeatLimitDoc{
+		SeatID:    seatID,
+		UserID:    userID,
+		CreatedAt: createdAt,
+		Until:     until,
+	}))
+}
+
+// Get500SeatLimitsAfterUntilInWHITEList returns all seat limit docs whose `until` is before `thresholdTime`, same as the Firestore implementation.
+func (r *SQLRepository) Get500SeatLimitsAfterUntilInWHITEList(ctx context.Context, thresholdTime time.Time, isMemberSeat bool) DocumentIterator {
+	return sqlSeatLimitsTable(seatLimitsWHITEListCollectionName(isMemberSeat)).queryRefs(ctx, r, FirestoreWritesLimitPerRequest,
+		"WHERE until < ?", sqlTimeValue(thresholdTime))
+}
+
+// Get500SeatLimitsAfterUntilInBLACKList returns all seat limit docs whose `until` is before `thresholdTime`, same as the Firestore implementation.
+func (r *SQLRepository) Get500SeatLimitsAfterUntilInBLACKList(ctx context.Context, thresholdTime time.Time, isMemberSeat bool) DocumentIterator {
+	return sqlSeatLimitsTable(seatLimitsBLACKListCollectionName(isMemberSeat)).queryRefs(ctx, r, FirestoreWritesLimitPerRequest,
+		"WHERE until < ?", sqlTimeValue(thresholdTime))
+}
+
+func (r *SQLRepository) DeleteSeatLimitInWHITEList(ctx context.Context, docID string, isMemberSeat bool) error {
+	return r.write(ctx, nil, sqlDelete(seatLimitsWHITEListCollectionName(isMemberSeat), docID))
+}
+
+func (r *SQLRepository) DeleteSeatLimitInBLACKList(ctx context.Context, docID string, isMemberSeat bool) error {
+	return r.write(ctx, nil, sqlDelete(seatLimitsBLACKListCollectionName(isMemberSeat), docID))
+}
+
+func (r *SQLRepository) ReadAllMenuDocsOrderByCode(ctx context.Context) ([]MenuDoc, error) {
+	return sqlMenuTable.query(ctx, r, "ORDER BY code")
+}
+
+func (r *SQLRepository) CountUserOrdersOfTheDay(ctx context.Context, userID string, date time.Time) (int64, error) {
+	start := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.Local)
+	end := start.AddDate(0, 0, 1)
+	var count int64
+	err := r.db.QueryRowContext(ctx, r.rebind("SELECT COUNT(*) FROM "+sqlOrderHistoryTable.name()+
+		" WHERE user_id = ? AND ordered_at >= ? AND ordered_at < ?"), userID, sqlTimeValue(start), sqlTimeValue(end)).Scan(&count)
+	if err != nil {
+		return -1, fmt.Errorf("count order history: %w", err)
+	}
+	return count, nil
+}
+
+func (r *SQLRepository) CreateOrderHistoryDoc(ctx context.Context, tx Transaction, orderHistoryDoc OrderHistoryDoc) error {
+	return r.write(ctx, tx, sqlOrderHistoryTable.create(newDocID(), orderHistoryDoc))
+}
+
+func (r *SQLRepository) CreateModerationActionDoc(ctx context.Context, tx Transaction, action ModerationActionDoc) error {
+	return r.write(ctx, tx, sqlModerationActionsTable.create(newDocID(), action))
+}
+
+// ReadModerationActionsByTargetUserID userIDのユーザーに対するモデレーションの記録を古い順に取得
+func (r *SQLRepository) ReadModerationActionsByTargetUserID(ctx context.Context, userID string) ([]ModerationActionDoc, error) {
+	return sqlModerationActionsTable.query(ctx, r, "WHERE target_user_id = ? ORDER BY taken_at, id", userID)
+}
+
+func (r *SQLRepository) CreateFanFundingHistoryDoc(ctx context.Context, tx Transaction, messageID string, history FanFundingHistoryDoc) error {
+	return r.write(ctx, tx, sqlFanFundingHistoryTable.create(messageID, history))
+}
+
+// ReadFanFundingHistoryByUserID userIDのユーザーの支援の記録を古い順に取得
+func (r *SQLRepository) ReadFanFundingHistoryByUserID(ctx context.Context, userID string) ([]FanFundingHistoryDoc, error) {
+	return sqlFanFundingHistoryTable.query(ctx, r, "WHERE user_id = ? ORDER BY published_at, id", userID)
+}
+
+func (r *SQLRepository) ReadProcessedLiveChatMessage(ctx context.Context, tx Transaction, messageID string) (ProcessedLiveChatMessageDoc, error) {
+	return sqlProcessedLiveChatMessagesTable.get(ctx, r, tx, messageID)
+}
+
+func (r *SQLRepository) CreateProcessedLiveChatMessage(ctx context.Context, tx Transaction, messageID string, processed ProcessedLiveChatMessageDoc) error {
+	return r.write(ctx, tx, sqlProcessedLiveChatMessagesTable.create(messageID, processed))
+}
+
+func (r *SQLRepository) AddYoutubeAPIQuotaUsage(ctx context.Context, date string, calls map[string]int, units int) error {
+	t := sqlYoutubeAPIQuotaUsageTable
+	return r.write(ctx, nil, func(ctx context.Context, conn sqlConn) error {
+		usage, err := t.scan(conn.queryRow(ctx, t.selectFrom("WHERE id = ?"), date).Scan)
+		if errors.Is(err, sql.ErrNoRows) {
+			usage = YoutubeAPIQuotaUsageDoc{Date: date}
+		} else if err != nil {
+			return fmt.Errorf("get %s: %w", docPath(t.collection, date), err)
+		}
+		usage.add(calls, units)
+		return t.set(date, usage)(ctx, conn)
+	})
+}
+
+func (r *SQLRepository) ReadYoutubeAPIQuotaUsage(ctx context.Context, date string) (YoutubeAPIQuotaUsageDoc, error) {
+	return sqlYoutubeAPIQuotaUsageTable.get(ctx, r, nil, date)
+}
+
+func (r *SQLRepository) UpdateWorkNameTrend(ctx context.Context, tx Transaction, workNameTrend WorkNameTrendDoc) error {
+	return r.write(ctx, tx, sqlWorkNameTrendTable.set(WorkNameTrendDocName, workNameTrend))
+}
+
+func (r *SQLRepository) GetAllUserDocRefs(ctx context.Context) ([]DocumentRef, error) {
+	iter := sqlUsersTable.queryRefs(ctx, r, 0, "")
+	if iter.err != nil {
+		return nil, iter.err
+	}
+	return iter.refs, nil
+}
+
+// ReadUsersPage startAfterUserIDより後のユーザーをドキュメントID順に最大limit件取得。startAfterUserIDが空なら先頭から
+func (r *SQLRepository) ReadUsersPage(ctx context.Context, startAfterUserID string, limit int) ([]UserDocWithID, error) {
+	query := "SELECT id, " + strings.Join(sqlUsersTable.columns, ", ") + " FROM " + sqlUsersTable.name() +
+		" WHERE id > ? ORDER BY id LIMIT " + strconv.Itoa(limit)
+	rows, err := r.db.QueryContext(ctx, r.rebind(query), startAfterUserID)
+	if err != nil {
+		return nil, fmt.Errorf("query %s: %w", USERS, err)
+	}
+	defer rows.Close()
+	users := make([]UserDocWithID, 0, limit)
+	for rows.Next() {
+		var userID string
+		user, err := sqlUsersTable.scan(func(dest ...any) error {
+			return rows.Scan(append([]any{&userID}, dest...)...)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("scan %s: %w", USERS, err)
+		}
+		users = append(users, UserDocWithID{UserID: userID, UserDoc: user})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query %s: %w", USERS, err)
+	}
+	return users, nil
+}
+
+func (r *SQLRepository) GetAllNonDailyZeroUserDocs(ctx context.Context) DocumentIterator {
+	return sqlUsersTable.queryRefs(ctx, r, 0, "WHERE daily_total_study_sec <> 0")
+}
+
+func (r *SQLRepository) ResetDailyTotalStudyTime(ctx context.Context, userID string) error {
+	return r.write(ctx, nil, sqlUsersTable.update(userID, sqlAssignment{"daily_total_study_sec", 0}))
+}
+
+func (r *SQLRepository) GetAllDailyGoalAchievedUserDocs(ctx context.Context) DocumentIterator {
+	return sqlUsersTable.queryRefs(ctx, r, 0, "WHERE daily_goal_achieved = ?", true)
+}
+
+func (r *SQLRepository) ResetDailyGoalAchieved(ctx context.Context, userID string) error {
+	return r.write(ctx, nil, sqlUsersTable.update(userID, sqlAssignment{"daily_goal_achieved", false}))
+}
+
+func (r *SQLRepository) updateConstants(ctx context.Context, tx Transaction, update func(doc *ConstantsConfigDoc)) error {
+	return r.write(ctx, tx, updateJSON(sqlConstantsTable, SystemConstantsConfigDocName, update))
+}
+
+func (r *SQLRepository) UpdateLastResetDailyTotalStudyTime(ctx context.Context, timestamp time.Time) error {
+	return r.updateConstants(ctx, nil, func(doc *ConstantsConfigDoc) { doc.LastResetDailyTotalStudySec = timestamp })
+}
+
+func (r *SQLRepository) UpdateLastLongTimeSittingChecked(ctx context.Context, _ time.Time) error {
+	// ConstantsConfigDocにフィールドがないため、Firestoreと同じく読み出せる値はない
+	return r.updateConstants(ctx, nil, func(*ConstantsConfigDoc) {})
+}
+
+func (r *SQLRepository) UpdateLastTransferCollectionHistoryBigquery(ctx context.Context, timestamp time.Time) error {
+	return r.updateConstants(ctx, nil, func(doc *ConstantsConfigDoc) { doc.LastTransferCollectionHistoryBigquery = timestamp })
+}
+
+func (r *SQLRepository) UpdateDesiredMaxSeats(ctx context.Context, tx Transaction, desiredMaxSeats int) error {
+	return r.updateConstants(ctx, tx, func(doc *ConstantsConfigDoc) { doc.DesiredMaxSeats = desiredMaxSeats })
+}
+
+func (r *SQLRepository) UpdateDesiredMemberMaxSeats(ctx context.Context, tx Transaction, desiredMemberMaxSeats int) error {
+	return r.updateConstants(ctx, tx, func(doc *ConstantsConfigDoc) { doc.DesiredMemberMaxSeats = desiredMemberMaxSeats })
+}
+
+func (r *SQLRepository) UpdateMaxSeats(ctx context.Context, tx Transaction, maxSeats int) error {
+	return r.updateConstants(ctx, tx, func(doc *ConstantsConfigDoc) { doc.MaxSeats = maxSeats })
+}
+
+func (r *SQLRepository) UpdateMemberMaxSeats(ctx context.Context, tx Transaction, memberMaxSeats int) error {
+	return r.updateConstants(ctx, tx, func(doc *ConstantsConfigDoc) { doc.MemberMaxSeats = memberMaxSeats })
+}
+
+// UpdateAccessTokenOfChannelCredential アクセストークンはCredentialsConfigDocに含まれないため、ドキュメントの存在のみ確認する。
+func (r *SQLRepository) UpdateAccessTokenOfChannelCredential(ctx context.Context, tx Transaction, _ string, _ time.Time) error {
+	return r.write(ctx, tx, updateJSON(sqlCredentialsTable, CredentialsConfigDocName, func(*CredentialsConfigDoc) {}))
+}
+
+// UpdateAccessTokenOfBotCredential アクセストークンはCredentialsConfigDocに含まれないため、ドキュメントの存在のみ確認する。
+func (r *SQLRepository) UpdateAccessTokenOfBotCredential(ctx context.Context, tx Transaction, _ string, _ time.Time) error {
+	return r.write(ctx, tx, updateJSON(sqlCredentialsTable, CredentialsConfigDocName, func(*CredentialsConfigDoc) {}))
+}
@@ -0,0 +1,78 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+
+	"app.modules/core/repository"
+	"app.modules/internal/repositorytest"
+)
+
+// newSQLiteRepository はテストごとに別ファイルのSQLiteデータベースを使う。
+func newSQLiteRepository(t *testing.T) *repository.SQLRepository {
+	t.Helper()
+	dsn := "file:" + filepath.Join(t.TempDir(), "test.db") + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+	repo, err := repository.OpenSQLRepository(context.Background(), "sqlite", dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = repo.Close() })
+	return repo
+}
+
+func TestSQLRepository_Conformance(t *testing.T) {
+	repositorytest.RunConformance(t, func(t *testing.T) repositorytest.Fixture {
+		repo := newSQLiteRepository(t)
+		ctx := context.Background()
+		return repositorytest.Fixture{
+			Repository: repo,
+			SeedConfigs: func(t *testing.T, constants repository.ConstantsConfigDoc, credentials repository.CredentialsConfigDoc) {
+				require.NoError(t, repo.SetSystemConstantsConfig(ctx, constants))
+				require.NoError(t, repo.SetCredentialsConfig(ctx, credentials))
+			},
+			SeedMenus: func(t *testing.T, menus []repository.MenuDoc) {
+				for _, menu := range menus {
+					require.NoError(t, repo.SetMenuDoc(ctx, menu))
+				}
+			},
+		}
+	})
+}
+
+func TestSQLRepository_MigrationsAreIdempotent(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite", "file:"+filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo, err := repository.NewSQLRepository(ctx, db, repository.SQLDialectSQLite)
+	require.NoError(t, err)
+	require.NoError(t, repo.CreateUser(ctx, nil, "user", repository.UserDoc{TotalStudySec: 100}))
+
+	// 2回目は適用済みのマイグレーションを飛ばし、データも残る
+	repo, err = repository.NewSQLRepository(ctx, db, repository.SQLDialectSQLite)
+	require.NoError(t, err)
+	user, err := repo.ReadUser(ctx, nil, "user")
+	require.NoError(t, err)
+	assert.Equal(t, 100, user.TotalStudySec)
+}
+
+func TestSQLRepository_FinishedTransaction(t *testing.T) {
+	repo := newSQLiteRepository(t)
+	ctx := context.Background()
+
+	var finished repository.Transaction
+	require.NoError(t, repo.RunTransaction(ctx, func(_ context.Context, tx repository.Transaction) error {
+		finished = tx
+		return nil
+	}))
+
+	err := repo.CreateSeat(finished, repository.SeatDoc{SeatID: 1}, false)
+	assert.Error(t, err)
+	_, err = repo.ReadSeat(ctx, nil, 1, false)
+	assert.Error(t, err)
+}
@@ -0,0 +1,232 @@
+-- Firestoreのコレクションと同じ単位でテーブルを作る。
+-- idはFirestoreのドキュメントIDに相当し、日時はUTCのUnixマイクロ秒で保存する。
+-- PostgreSQLとSQLiteの両方で実行できる構文のみを使うこと。
+
+CREATE TABLE config (
+    id TEXT PRIMARY KEY,
+    data TEXT NOT NULL
+);
+
+CREATE TABLE seats (
+    id TEXT PRIMARY KEY,
+    seat_id BIGINT NOT NULL,
+    user_id TEXT NOT NULL,
+    session_id TEXT NOT NULL,
+    user_display_name TEXT NOT NULL,
+    work_name TEXT NOT NULL,
+    break_work_name TEXT NOT NULL,
+    entered_at BIGINT NOT NULL,
+    until BIGINT NOT NULL,
+    color_code1 TEXT NOT NULL,
+    color_code2 TEXT NOT NULL,
+    num_stars BIGINT NOT NULL,
+    color_gradient_enabled BOOLEAN NOT NULL,
+    menu_code TEXT NOT NULL,
+    state TEXT NOT NULL,
+    current_state_started_at BIGINT NOT NULL,
+    current_state_until BIGINT NOT NULL,
+    current_segment_started_at BIGINT NOT NULL,
+    cumulative_work_sec BIGINT NOT NULL,
+    daily_cumulative_work_sec BIGINT NOT NULL,
+    user_profile_image_url TEXT NOT NULL,
+    pomodoro_work_min BIGINT NOT NULL,
+    pomodoro_break_min BIGINT NOT NULL
+);
+
+CREATE INDEX seats_user_id_idx ON seats (user_id);
+
+CREATE TABLE member_seats (
+    id TEXT PRIMARY KEY,
+    seat_id BIGINT NOT NULL,
+    user_id TEXT NOT NULL,
+    session_id TEXT NOT NULL,
+    user_display_name TEXT NOT NULL,
+    work_name TEXT NOT NULL,
+    break_work_name TEXT NOT NULL,
+    entered_at BIGINT NOT NULL,
+    until BIGINT NOT NULL,
+    color_code1 TEXT NOT NULL,
+    color_code2 TEXT NOT NULL,
+    num_stars BIGINT NOT NULL,
+    color_gradient_enabled BOOLEAN NOT NULL,
+    menu_code TEXT NOT NULL,
+    state TEXT NOT NULL,
+    current_state_started_at BIGINT NOT NULL,
+    current_state_until BIGINT NOT NULL,
+    current_segment_started_at BIGINT NOT NULL,
+    cumulative_work_sec BIGINT NOT NULL,
+    daily_cumulative_work_sec BIGINT NOT NULL,
+    user_profile_image_url TEXT NOT NULL,
+    pomodoro_work_min BIGINT NOT NULL,
+    pomodoro_break_min BIGINT NOT NULL
+);
+
+CREATE INDEX member_seats_user_id_idx ON member_seats (user_id);
+
+CREATE TABLE users (
+    id TEXT PRIMARY KEY,
+    daily_total_study_sec BIGINT NOT NULL,
+    total_study_sec BIGINT NOT NULL,
+    registration_date BIGINT NOT NULL,
+    status_message TEXT NOT NULL,
+    last_entered BIGINT NOT NULL,
+    last_exited BIGINT NOT NULL,
+    rank_visible BOOLEAN NOT NULL,
+    default_study_min BIGINT NOT NULL,
+    rank_point BIGINT NOT NULL,
+    last_rp_processed BIGINT NOT NULL,
+    last_penalty_imposed_days BIGINT NOT NULL,
+    is_continuous_active BOOLEAN NOT NULL,
+    current_activity_state_started BIGINT NOT NULL,
+    favorite_color TEXT NOT NULL,
+    daily_goal_min BIGINT NOT NULL,
+    daily_goal_achieved BOOLEAN NOT NULL,
+    best_streak_days BIGINT NOT NULL
+);
+
+CREATE INDEX users_last_entered_idx ON users (last_entered);
+
+CREATE TABLE live_chat_history (
+    id TEXT PRIMARY KEY,
+    author_channel_id TEXT NOT NULL,
+    author_display_name TEXT NOT NULL,
+    author_profile_image_url TEXT NOT NULL,
+    author_is_chat_moderator BOOLEAN NOT NULL,
+    message_id TEXT NOT NULL,
+    live_chat_id TEXT NOT NULL,
+    message_text TEXT NOT NULL,
+    published_at BIGINT NOT NULL,
+    type TEXT NOT NULL
+);
+
+CREATE INDEX live_chat_history_published_at_idx ON live_chat_history (published_at);
+
+CREATE TABLE user_activities (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    activity_type TEXT NOT NULL,
+    seat_id BIGINT NOT NULL,
+    is_member_seat BOOLEAN NOT NULL,
+    taken_at BIGINT NOT NULL
+);
+
+CREATE INDEX user_activities_taken_at_idx ON user_activities (taken_at);
+CREATE INDEX user_activities_user_id_seat_id_idx ON user_activities (user_id, seat_id, taken_at);
+
+CREATE TABLE work_segments (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    seat_id BIGINT NOT NULL,
+    is_member_seat BOOLEAN NOT NULL,
+    session_id TEXT NOT NULL,
+    work_name TEXT NOT NULL,
+    segment_type TEXT NOT NULL,
+    started_at BIGINT NOT NULL,
+    ended_at BIGINT NOT NULL,
+    duration_sec BIGINT NOT NULL
+);
+
+CREATE INDEX work_segments_session_id_idx ON work_segments (session_id);
+CREATE INDEX work_segments_user_id_started_at_idx ON work_segments (user_id, started_at);
+CREATE INDEX work_segments_started_at_idx ON work_segments (started_at);
+
+CREATE TABLE daily_user_work_history (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    date BIGINT NOT NULL,
+    total_study_sec BIGINT NOT NULL,
+    total_break_sec BIGINT NOT NULL,
+    timezone_name TEXT NOT NULL
+);
+
+CREATE TABLE undoable_exits (
+    id TEXT PRIMARY KEY,
+    data TEXT NOT NULL
+);
+
+CREATE TABLE seat_reservations (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    user_display_name TEXT NOT NULL,
+    seat_id BIGINT NOT NULL,
+    start_at BIGINT NOT NULL,
+    until BIGINT NOT NULL,
+    created_at BIGINT NOT NULL
+);
+
+CREATE TABLE member_seat_reservations (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    user_display_name TEXT NOT NULL,
+    seat_id BIGINT NOT NULL,
+    start_at BIGINT NOT NULL,
+    until BIGINT NOT NULL,
+    created_at BIGINT NOT NULL
+);
+
+CREATE TABLE seat_limits_black_list (
+    id TEXT PRIMARY KEY,
+    seat_id BIGINT NOT NULL,
+    user_id TEXT NOT NULL,
+    created_at BIGINT NOT NULL,
+    until BIGINT NOT NULL
+);
+
+CREATE INDEX seat_limits_black_list_seat_id_user_id_idx ON seat_limits_black_list (seat_id, user_id);
+CREATE INDEX seat_limits_black_list_until_idx ON seat_limits_black_list (until);
+
+CREATE TABLE seat_limits_white_list (
+    id TEXT PRIMARY KEY,
+    seat_id BIGINT NOT NULL,
+    user_id TEXT NOT NULL,
+    created_at BIGINT NOT NULL,
+    until BIGINT NOT NULL
+);
+
+CREATE INDEX seat_limits_white_list_seat_id_user_id_idx ON seat_limits_white_list (seat_id, user_id);
+CREATE INDEX seat_limits_white_list_until_idx ON seat_limits_white_list (until);
+
+CREATE TABLE member_seat_limits_black_list (
+    id TEXT PRIMARY KEY,
+    seat_id BIGINT NOT NULL,
+    user_id TEXT NOT NULL,
+    created_at BIGINT NOT NULL,
+    until BIGINT NOT NULL
+);
+
+CREATE INDEX member_seat_limits_black_list_seat_id_user_id_idx ON member_seat_limits_black_list (seat_id, user_id);
+CREATE INDEX member_seat_limits_black_list_until_idx ON member_seat_limits_black_list (until);
+
+CREATE TABLE member_seat_limits_white_list (
+    id TEXT PRIMARY KEY,
+    seat_id BIGINT NOT NULL,
+    user_id TEXT NOT NULL,
+    created_at BIGINT NOT NULL,
+    until BIGINT NOT NULL
+);
+
+CREATE INDEX member_seat_limits_white_list_seat_id_user_id_idx ON member_seat_limits_white_list (seat_id, user_id);
+CREATE INDEX member_seat_limits_white_list_until_idx ON member_seat_limits_white_list (until);
+
+CREATE TABLE menu (
+    id TEXT PRIMARY KEY,
+    code TEXT NOT NULL,
+    name TEXT NOT NULL
+);
+
+CREATE TABLE order_history (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    menu_code TEXT NOT NULL,
+    seat_id BIGINT NOT NULL,
+    is_member_seat BOOLEAN NOT NULL,
+    ordered_at BIGINT NOT NULL
+);
+
+CREATE INDEX order_history_user_id_ordered_at_idx ON order_history (user_id, ordered_at);
+CREATE INDEX order_history_ordered_at_idx ON order_history (ordered_at);
+
+CREATE TABLE work_name_trend (
+    id TEXT PRIMARY KEY,
+    data TEXT NOT NULL
+);
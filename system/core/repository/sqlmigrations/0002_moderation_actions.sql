@@ -0,0 +1,15 @@
+CREATE TABLE moderation_actions (
+    id TEXT PRIMARY KEY,
+    action_type TEXT NOT NULL,
+    actor_user_id TEXT NOT NULL,
+    actor_display_name TEXT NOT NULL,
+    target_user_id TEXT NOT NULL,
+    target_display_name TEXT NOT NULL,
+    seat_id BIGINT NOT NULL,
+    is_member_seat BOOLEAN NOT NULL,
+    matched_regex TEXT NOT NULL,
+    message_text TEXT NOT NULL,
+    taken_at BIGINT NOT NULL
+);
+
+CREATE INDEX moderation_actions_target_user_id_taken_at_idx ON moderation_actions (target_user_id, taken_at);
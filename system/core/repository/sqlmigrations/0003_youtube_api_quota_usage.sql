@@ -0,0 +1,4 @@
+CREATE TABLE youtube_api_quota_usage (
+    id TEXT PRIMARY KEY,
+    data TEXT NOT NULL
+);
@@ -0,0 +1,2 @@
+ALTER TABLE moderation_actions ADD COLUMN ban_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE moderation_actions ADD COLUMN timeout_duration_sec BIGINT NOT NULL DEFAULT 0;
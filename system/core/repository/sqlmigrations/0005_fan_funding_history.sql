@@ -0,0 +1,20 @@
+-- 既存のユーザーはtime.Time{}（UnixMicroで-62135596800000000）にする
+ALTER TABLE users ADD COLUMN supporter_appearance_until BIGINT NOT NULL DEFAULT -62135596800000000;
+
+CREATE TABLE fan_funding_history (
+    id TEXT PRIMARY KEY,
+    event_type TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    user_display_name TEXT NOT NULL,
+    amount_micros BIGINT NOT NULL,
+    currency TEXT NOT NULL,
+    amount_display_string TEXT NOT NULL,
+    membership_level_name TEXT NOT NULL,
+    gift_count BIGINT NOT NULL,
+    reward_rp BIGINT NOT NULL,
+    reward_extended_min BIGINT NOT NULL,
+    reward_appearance_until BIGINT NOT NULL,
+    published_at BIGINT NOT NULL
+);
+
+CREATE INDEX fan_funding_history_user_id_published_at_idx ON fan_funding_history (user_id, published_at);
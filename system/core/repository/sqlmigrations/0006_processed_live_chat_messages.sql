@@ -0,0 +1,7 @@
+-- SQLにはTTLがないため、expire_atを過ぎた行は残り続ける
+CREATE TABLE processed_live_chat_messages (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    processed_at BIGINT NOT NULL,
+    expire_at BIGINT NOT NULL
+);
@@ -0,0 +1,3 @@
+-- 既存の行は0（1970年）になり、次のOrganizeDBDailyGoalですぐに判定される
+ALTER TABLE seats ADD COLUMN daily_goal_check_at BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE member_seats ADD COLUMN daily_goal_check_at BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/api/iterator"
+)
+
+// DefaultUserPageSize UserIteratorが1回に読み込むユーザー数の目安
+const DefaultUserPageSize = 500
+
+// UserIterator 全ユーザーをドキュメントID順に、pageSize件ずつ読み込みながら1件ずつ返す。
+type UserIterator struct {
+	ctx      context.Context
+	repo     Repository
+	pageSize int
+
+	page   []UserDocWithID
+	next   int
+	cursor string // 最後に返したユーザーのID
+	done   bool
+}
+
+// NewUserIterator startAfterUserIDより後のユーザーから読み込むイテレーターを作る。startAfterUserIDが空なら先頭から。
+func NewUserIterator(ctx context.Context, repo Repository, startAfterUserID string, pageSize int) *UserIterator {
+	return &UserIterator{
+		ctx:      ctx,
+		repo:     repo,
+		pageSize: pageSize,
+		cursor:   startAfterUserID,
+	}
+}
+
+// Next 次のユーザーを返す。全て返し終えたらiterator.Doneを返す。
+func (it *UserIterator) Next() (UserDocWithID, error) {
+	if it.next == len(it.page) {
+		if it.done {
+			return UserDocWithID{}, iterator.Done
+		}
+		page, err := it.repo.ReadUsersPage(it.ctx, it.cursor, it.pageSize)
+		if err != nil {
+			return UserDocWithID{}, fmt.Errorf("in ReadUsersPage(): %w", err)
+		}
+		it.page, it.next = page, 0
+		it.done = len(page) < it.pageSize
+		if len(page) == 0 {
+			return UserDocWithID{}, iterator.Done
+		}
+	}
+	user := it.page[it.next]
+	it.next++
+	it.cursor = user.UserID
+	return user, nil
+}
+
+// Cursor 最後に返したユーザーのID。NewUserIteratorに渡すと、その続きから読み込める。
+func (it *UserIterator) Cursor() string {
+	return it.cursor
+}
@@ -0,0 +1,47 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/iterator"
+
+	"app.modules/core/repository"
+)
+
+func TestUserIterator_PagesAndResumesFromCursor(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewInMemoryRepository()
+	for i := 1; i <= 5; i++ {
+		require.NoError(t, repo.CreateUser(ctx, nil, fmt.Sprintf("user-%d", i), repository.UserDoc{TotalStudySec: i * 60}))
+	}
+
+	// 3件目まで読んだところで中断する
+	iter := repository.NewUserIterator(ctx, repo, "", 2)
+	for i := 1; i <= 3; i++ {
+		user, err := iter.Next()
+		require.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("user-%d", i), user.UserID)
+		assert.Equal(t, i*60, user.TotalStudySec)
+	}
+	assert.Equal(t, "user-3", iter.Cursor())
+
+	// カーソルから再開すると残りだけを返す
+	resumed := repository.NewUserIterator(ctx, repo, iter.Cursor(), 2)
+	var userIDs []string
+	for {
+		user, err := resumed.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		require.NoError(t, err)
+		userIDs = append(userIDs, user.UserID)
+	}
+	assert.Equal(t, []string{"user-4", "user-5"}, userIDs)
+	_, err := resumed.Next()
+	assert.ErrorIs(t, err, iterator.Done)
+}
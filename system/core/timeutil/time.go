@@ -35,6 +35,12 @@ func DateEqualJST(date1, date2 time.Time) bool {
 	return y1 == y2 && m1 == m2 && d1 == d2
 }
 
+// StartOfDayJST returns midnight (00:00:00) in JST of the JST calendar day that contains t.
+func StartOfDayJST(t time.Time) time.Time {
+	jst := t.In(JapanLocation())
+	return time.Date(jst.Year(), jst.Month(), jst.Day(), 0, 0, 0, 0, JapanLocation())
+}
+
 // OverlapSecondsInJSTDay returns the length in whole seconds of the intersection of the
 // half-open interval [start, end) with the JST calendar day that contains dayAnchor
 // (that day is [midnight, next midnight) in Asia/Tokyo).
@@ -44,9 +50,7 @@ func OverlapSecondsInJSTDay(start, end, dayAnchor time.Time) int {
 		return 0
 	}
 
-	loc := JapanLocation()
-	jstAnchor := dayAnchor.In(loc)
-	dayStart := time.Date(jstAnchor.Year(), jstAnchor.Month(), jstAnchor.Day(), 0, 0, 0, 0, loc)
+	dayStart := StartOfDayJST(dayAnchor)
 	dayEnd := dayStart.AddDate(0, 0, 1)
 
 	overlapStart := start
@@ -169,6 +169,38 @@ func TestDateEqualJST(t *testing.T) {
 	}
 }
 
+func TestStartOfDayJST(t *testing.T) {
+	jst := JapanLocation()
+	tests := []struct {
+		name     string
+		input    time.Time
+		expected time.Time
+	}{
+		{
+			name:     "JST daytime",
+			input:    time.Date(2026, 8, 2, 15, 4, 5, 6, jst),
+			expected: time.Date(2026, 8, 2, 0, 0, 0, 0, jst),
+		},
+		{
+			name:     "JST midnight",
+			input:    time.Date(2026, 8, 2, 0, 0, 0, 0, jst),
+			expected: time.Date(2026, 8, 2, 0, 0, 0, 0, jst),
+		},
+		{
+			name:     "UTC input on the next JST day",
+			input:    time.Date(2026, 8, 1, 15, 30, 0, 0, time.UTC),
+			expected: time.Date(2026, 8, 2, 0, 0, 0, 0, jst),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := StartOfDayJST(tt.input)
+			assert.True(t, tt.expected.Equal(result), "expected %v, got %v", tt.expected, result)
+		})
+	}
+}
+
 func TestOverlapSecondsInJSTDay(t *testing.T) {
 	jst := JapanLocation()
 	tests := []struct {
@@ -0,0 +1,85 @@
+package twitchbot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// maxTimeoutDuration Helix APIで指定できるタイムアウトの最大時間（2週間）
+const maxTimeoutDuration = 14 * 24 * time.Hour
+
+type helixBanRequest struct {
+	Data helixBanData `json:"data"`
+}
+
+type helixBanData struct {
+	UserID   string `json:"user_id"`
+	Duration int    `json:"duration,omitempty"` // 秒。0なら無期限のブロック
+}
+
+// BanUser platformUserIDのユーザーを無期限でブロックする。
+// Twitchのブロックにはユーザーごとに1つしかなくIDがないため、ユーザーIDをブロックのIDとして返す。
+func (c *TwitchChat) BanUser(ctx context.Context, platformUserID string) (string, error) {
+	return c.ban(ctx, platformUserID, 0)
+}
+
+// TimeOut platformUserIDのユーザーをdurationの間だけブロックする。ブロックのIDはBanUserと同じくユーザーID。
+func (c *TwitchChat) TimeOut(ctx context.Context, platformUserID string, duration time.Duration) (string, error) {
+	if duration < time.Second || maxTimeoutDuration < duration {
+		return "", fmt.Errorf("invalid twitch timeout duration: %s", duration)
+	}
+	return c.ban(ctx, platformUserID, int(duration.Seconds()))
+}
+
+func (c *TwitchChat) ban(ctx context.Context, platformUserID string, durationSec int) (string, error) {
+	body, err := json.Marshal(helixBanRequest{Data: helixBanData{UserID: platformUserID, Duration: durationSec}})
+	if err != nil {
+		return "", fmt.Errorf("in json.Marshal(): %w", err)
+	}
+	if err := c.callHelix(ctx, http.MethodPost, c.moderationBansURL(nil), body); err != nil {
+		return "", err
+	}
+	return platformUserID, nil
+}
+
+// UnbanUser BanUserまたはTimeOutで返したブロックのIDのユーザーのブロックを解除する
+func (c *TwitchChat) UnbanUser(ctx context.Context, banID string) error {
+	return c.callHelix(ctx, http.MethodDelete, c.moderationBansURL(url.Values{"user_id": {banID}}), nil)
+}
+
+func (c *TwitchChat) moderationBansURL(query url.Values) string {
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set("broadcaster_id", c.config.BroadcasterID)
+	query.Set("moderator_id", c.config.ModeratorID)
+	return c.helixEndpoint + "moderation/bans?" + query.Encode()
+}
+
+func (c *TwitchChat) callHelix(ctx context.Context, method string, endpoint string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("in http.NewRequestWithContext(): %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.config.OAuthToken)
+	req.Header.Set("Client-Id", c.config.ClientID)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("twitch helix %s: %w", method, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || 300 <= resp.StatusCode {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("twitch helix %s moderation/bans: status %d: %s", method, resp.StatusCode, message)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package twitchbot
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"app.modules/core/chat"
+)
+
+// ircMessage IRCv3のメッセージ。TwitchのIRCはタグにユーザーIDやバッジを載せる。
+// 例：@badges=moderator/1;display-name=User;user-id=123 :user!user@user.tmi.twitch.tv PRIVMSG #channel :!in
+type ircMessage struct {
+	tags    map[string]string
+	prefix  string
+	command string
+	params  []string // 末尾のパラメータ（:以降）も含む
+}
+
+func parseIRCMessage(line string) (ircMessage, error) {
+	var msg ircMessage
+	line = strings.TrimRight(line, "\r\n")
+	if rawTags, ok := strings.CutPrefix(line, "@"); ok {
+		var rest string
+		rawTags, rest, ok = strings.Cut(rawTags, " ")
+		if !ok {
+			return ircMessage{}, errors.New("missing command: " + line)
+		}
+		msg.tags = parseIRCTags(rawTags)
+		line = rest
+	}
+	if prefix, ok := strings.CutPrefix(line, ":"); ok {
+		var rest string
+		prefix, rest, ok = strings.Cut(prefix, " ")
+		if !ok {
+			return ircMessage{}, errors.New("missing command: " + line)
+		}
+		msg.prefix = prefix
+		line = rest
+	}
+
+	middle, trailing, hasTrailing := strings.Cut(line, " :")
+	if strings.HasPrefix(line, ":") {
+		middle, trailing, hasTrailing = "", line[1:], true
+	}
+	fields := strings.Fields(middle)
+	if len(fields) == 0 {
+		return ircMessage{}, errors.New("missing command: " + line)
+	}
+	msg.command = fields[0]
+	msg.params = fields[1:]
+	if hasTrailing {
+		msg.params = append(msg.params, trailing)
+	}
+	return msg, nil
+}
+
+func parseIRCTags(rawTags string) map[string]string {
+	tags := make(map[string]string)
+	for _, tag := range strings.Split(rawTags, ";") {
+		key, value, _ := strings.Cut(tag, "=")
+		tags[key] = unescapeIRCTagValue(value)
+	}
+	return tags
+}
+
+// unescapeIRCTagValue IRCv3のタグの値のエスケープを戻す
+func unescapeIRCTagValue(value string) string {
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+	var builder strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i+1 == len(value) {
+			builder.WriteByte(value[i])
+			continue
+		}
+		i++
+		switch value[i] {
+		case ':':
+			builder.WriteByte(';')
+		case 's':
+			builder.WriteByte(' ')
+		case 'r':
+			builder.WriteByte('\r')
+		case 'n':
+			builder.WriteByte('\n')
+		default:
+			builder.WriteByte(value[i])
+		}
+	}
+	return builder.String()
+}
+
+// nick prefix（nick!user@host）のニックネーム。Twitchではログイン名
+func (m ircMessage) nick() string {
+	nick, _, _ := strings.Cut(m.prefix, "!")
+	return nick
+}
+
+// badges バッジ名とバージョン。例：broadcaster/1,subscriber/12
+func (m ircMessage) badges() map[string]string {
+	badges := make(map[string]string)
+	if m.tags["badges"] == "" {
+		return badges
+	}
+	for _, badge := range strings.Split(m.tags["badges"], ",") {
+		name, version, _ := strings.Cut(badge, "/")
+		badges[name] = version
+	}
+	return badges
+}
+
+// toChatMessage PRIVMSGを配信サービスに依存しないChatMessageにする。PRIVMSGでなければfalseを返す。
+func (m ircMessage) toChatMessage() (chat.ChatMessage, bool) {
+	if m.command != "PRIVMSG" || len(m.params) < 2 || m.tags["user-id"] == "" {
+		return chat.ChatMessage{}, false
+	}
+	text := m.params[len(m.params)-1]
+	// /meで投稿されたメッセージ
+	if action, ok := strings.CutPrefix(text, "\x01ACTION "); ok {
+		text = strings.TrimSuffix(action, "\x01")
+	}
+
+	displayName := m.tags["display-name"]
+	if displayName == "" {
+		displayName = m.nick()
+	}
+	var publishedAt time.Time
+	if sentMilli, err := strconv.ParseInt(m.tags["tmi-sent-ts"], 10, 64); err == nil {
+		publishedAt = time.UnixMilli(sentMilli)
+	}
+	var messageID string // 空ならProcessMessageで処理済みかを確認しない
+	if m.tags["id"] != "" {
+		messageID = chat.ID(chat.Twitch, m.tags["id"])
+	}
+	badges := m.badges()
+	_, isOwner := badges["broadcaster"]
+	_, isFounder := badges["founder"]
+
+	return chat.ChatMessage{
+		ID:       messageID,
+		Platform: chat.Twitch,
+		ChatID:   m.params[0],
+		Type:     m.command,
+		Author: chat.Author{
+			ID:          chat.ID(chat.Twitch, m.tags["user-id"]),
+			DisplayName: displayName,
+			IsModerator: m.tags["mod"] == "1",
+			IsOwner:     isOwner,
+			IsMember:    isOwner || isFounder || m.tags["subscriber"] == "1",
+		},
+		Text:        text,
+		PublishedAt: publishedAt,
+	}, true
+}
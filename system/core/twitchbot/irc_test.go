@@ -0,0 +1,130 @@
+package twitchbot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app.modules/core/chat"
+)
+
+func TestParseIRCMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		expected ircMessage
+	}{
+		{
+			name: "PING",
+			line: "PING :tmi.twitch.tv\r\n",
+			expected: ircMessage{
+				command: "PING",
+				params:  []string{"tmi.twitch.tv"},
+			},
+		},
+		{
+			name: "PRIVMSG with tags",
+			line: `@badges=broadcaster/1;display-name=User\sName;user-id=123 :user!user@user.tmi.twitch.tv PRIVMSG #channel :!in work=数学`,
+			expected: ircMessage{
+				tags:    map[string]string{"badges": "broadcaster/1", "display-name": "User Name", "user-id": "123"},
+				prefix:  "user!user@user.tmi.twitch.tv",
+				command: "PRIVMSG",
+				params:  []string{"#channel", "!in work=数学"},
+			},
+		},
+		{
+			name: "Numeric reply",
+			line: ":tmi.twitch.tv 001 bot :Welcome, GLHF!",
+			expected: ircMessage{
+				prefix:  "tmi.twitch.tv",
+				command: "001",
+				params:  []string{"bot", "Welcome, GLHF!"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := parseIRCMessage(tt.line)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, msg)
+		})
+	}
+
+	_, err := parseIRCMessage("@badges=")
+	assert.Error(t, err)
+}
+
+func TestUnescapeIRCTagValue(t *testing.T) {
+	assert.Equal(t, `a;b c\d`+"\r\n", unescapeIRCTagValue(`a\:b\sc\\d\r\n`))
+	assert.Equal(t, "plain", unescapeIRCTagValue("plain"))
+}
+
+func TestIRCMessage_ToChatMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		expected chat.ChatMessage
+		ok       bool
+	}{
+		{
+			name: "Subscriber",
+			line: "@badges=subscriber/12;display-name=User;id=message-id;mod=0;subscriber=1;tmi-sent-ts=1767229200000;user-id=123 " +
+				":user!user@user.tmi.twitch.tv PRIVMSG #channel :!in",
+			expected: chat.ChatMessage{
+				ID:          "twitch:message-id",
+				Platform:    chat.Twitch,
+				ChatID:      "#channel",
+				Type:        "PRIVMSG",
+				Author:      chat.Author{ID: "twitch:123", DisplayName: "User", IsMember: true},
+				Text:        "!in",
+				PublishedAt: time.UnixMilli(1767229200000),
+			},
+			ok: true,
+		},
+		{
+			name: "Broadcaster with /me and no display name",
+			line: "@badges=broadcaster/1;id=message-id;mod=0;subscriber=0;user-id=456 " +
+				":owner!owner@owner.tmi.twitch.tv PRIVMSG #channel :\x01ACTION !out\x01",
+			expected: chat.ChatMessage{
+				ID:       "twitch:message-id",
+				Platform: chat.Twitch,
+				ChatID:   "#channel",
+				Type:     "PRIVMSG",
+				Author:   chat.Author{ID: "twitch:456", DisplayName: "owner", IsOwner: true, IsMember: true},
+				Text:     "!out",
+			},
+			ok: true,
+		},
+		{
+			name: "Moderator",
+			line: "@badges=moderator/1;display-name=Mod;id=message-id;mod=1;user-id=789 :mod!mod@mod.tmi.twitch.tv PRIVMSG #channel :!kick 3",
+			expected: chat.ChatMessage{
+				ID:       "twitch:message-id",
+				Platform: chat.Twitch,
+				ChatID:   "#channel",
+				Type:     "PRIVMSG",
+				Author:   chat.Author{ID: "twitch:789", DisplayName: "Mod", IsModerator: true},
+				Text:     "!kick 3",
+			},
+			ok: true,
+		},
+		{
+			name: "Not PRIVMSG",
+			line: "@emote-sets=0;user-id=123 :tmi.twitch.tv GLOBALUSERSTATE",
+			ok:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := parseIRCMessage(tt.line)
+			require.NoError(t, err)
+			message, ok := msg.toChatMessage()
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, message)
+		})
+	}
+}
@@ -0,0 +1,238 @@
+// Package twitchbot はTwitchのチャットをchat.ChatReceiverとして扱う。
+// チャットの受信と投稿はIRC、ユーザーのブロックはHelix APIを使う。
+package twitchbot
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"app.modules/core/chat"
+)
+
+const (
+	ChannelEnv       = "TWITCH_CHANNEL"
+	BotLoginEnv      = "TWITCH_BOT_LOGIN"
+	OAuthTokenEnv    = "TWITCH_OAUTH_TOKEN"
+	ClientIDEnv      = "TWITCH_CLIENT_ID"
+	BroadcasterIDEnv = "TWITCH_BROADCASTER_ID"
+	ModeratorIDEnv   = "TWITCH_MODERATOR_ID"
+
+	DefaultIRCAddr       = "irc.chat.twitch.tv:6697"
+	DefaultHelixEndpoint = "https://api.twitch.tv/helix/"
+
+	// MaxMessageLength Twitchのチャットの1メッセージの最大文字数
+	MaxMessageLength = 500
+	// MinPostInterval 投稿の最小の間隔。モデレーターでないbotは30秒に20メッセージまで送れる
+	MinPostInterval = 1500 * time.Millisecond
+
+	// minimumTryTimesToNotify 連続して何回接続に失敗したらownerに通知するか
+	minimumTryTimesToNotify = 2
+
+	maxRetryInterval = 5 * time.Minute
+)
+
+var (
+	// errReconnectRequested Twitchのサーバーから再接続を求められた
+	errReconnectRequested = errors.New("twitch requested reconnect")
+	// errNotConnected 再接続を待っている間に投稿しようとした
+	errNotConnected = errors.New("not connected to twitch chat")
+)
+
+// Config Twitchのチャットに接続するための設定
+type Config struct {
+	Channel  string // 配信者のログイン名（#なし）
+	BotLogin string
+	// chat:read、chat:edit、moderator:manage:banned_usersのスコープを持つbotのユーザーアクセストークン（oauth:なし）
+	OAuthToken    string
+	ClientID      string
+	BroadcasterID string
+	ModeratorID   string // botのユーザーID
+}
+
+// ConfigFromEnv 環境変数からConfigを読む。ChannelEnvが設定されていなければfalseを返す。
+func ConfigFromEnv() (Config, bool) {
+	config := Config{
+		Channel:       strings.ToLower(strings.TrimPrefix(os.Getenv(ChannelEnv), "#")),
+		BotLogin:      strings.ToLower(os.Getenv(BotLoginEnv)),
+		OAuthToken:    strings.TrimPrefix(os.Getenv(OAuthTokenEnv), "oauth:"),
+		ClientID:      os.Getenv(ClientIDEnv),
+		BroadcasterID: os.Getenv(BroadcasterIDEnv),
+		ModeratorID:   os.Getenv(ModeratorIDEnv),
+	}
+	return config, config.Channel != ""
+}
+
+var _ chat.ChatReceiver = (*TwitchChat)(nil)
+
+type TwitchChat struct {
+	config        Config
+	helixEndpoint string
+	httpClient    *http.Client
+	dial          func(ctx context.Context) (net.Conn, error)
+	// notify 接続の失敗が続いたときにownerへ知らせる
+	notify        func(ctx context.Context, message string, err error)
+	retryInterval func(numContinuousFailed int) time.Duration
+
+	// 受信とメッセージ送信が別のgoroutineから呼ばれるため、接続の読み書きを保護する
+	connMu sync.Mutex
+	conn   net.Conn // 接続中のみnil以外
+}
+
+func NewTwitchChat(config Config, notify func(ctx context.Context, message string, err error)) *TwitchChat {
+	return &TwitchChat{
+		config:        config,
+		helixEndpoint: DefaultHelixEndpoint,
+		httpClient:    http.DefaultClient,
+		dial: func(ctx context.Context) (net.Conn, error) {
+			dialer := &tls.Dialer{}
+			return dialer.DialContext(ctx, "tcp", DefaultIRCAddr)
+		},
+		notify:        notify,
+		retryInterval: calculateRetryInterval,
+	}
+}
+
+func (c *TwitchChat) Platform() chat.Platform {
+	return chat.Twitch
+}
+
+// Receive ctxがキャンセルされるまでチャットを受信してoutに送る。切断されたら間隔を空けて再接続する。
+func (c *TwitchChat) Receive(ctx context.Context, out chan<- chat.ChatMessage) error {
+	numContinuousFailed := 0
+	for {
+		err := c.receiveUntilDisconnected(ctx, out, func() { numContinuousFailed = 0 })
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if errors.Is(err, errReconnectRequested) {
+			slog.InfoContext(ctx, "twitch requested reconnect")
+			continue
+		}
+		numContinuousFailed++
+		slog.WarnContext(ctx, "twitch chat disconnected", "error", err, "numContinuousFailed", numContinuousFailed)
+		if numContinuousFailed >= minimumTryTimesToNotify {
+			c.notify(ctx, fmt.Sprintf("Twitchのチャットへの接続に%d回連続で失敗しました", numContinuousFailed), err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(c.retryInterval(numContinuousFailed)):
+		}
+	}
+}
+
+// receiveUntilDisconnected 1回接続して、切断されるまで受信する。ログインに成功したらonConnectedを呼ぶ。
+func (c *TwitchChat) receiveUntilDisconnected(ctx context.Context, out chan<- chat.ChatMessage, onConnected func()) error {
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return fmt.Errorf("in dial(): %w", err)
+	}
+	c.connMu.Lock()
+	c.conn = conn
+	c.connMu.Unlock()
+	defer func() {
+		c.connMu.Lock()
+		c.conn = nil
+		c.connMu.Unlock()
+		_ = conn.Close()
+	}()
+	// ctxがキャンセルされたら読み込みを止める
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
+
+	for _, line := range []string{
+		"CAP REQ :twitch.tv/tags twitch.tv/commands",
+		"PASS oauth:" + c.config.OAuthToken,
+		"NICK " + c.config.BotLogin,
+		"JOIN #" + c.config.Channel,
+	} {
+		if err := c.writeLine(line); err != nil {
+			return err
+		}
+	}
+
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		msg, err := parseIRCMessage(scanner.Text())
+		if err != nil {
+			slog.WarnContext(ctx, "failed to parse twitch irc message", "error", err)
+			continue
+		}
+		switch msg.command {
+		case "001": // ログインに成功した
+			onConnected()
+		case "PING":
+			if err := c.writeLine("PONG :" + strings.Join(msg.params, " ")); err != nil {
+				return err
+			}
+		case "RECONNECT":
+			return errReconnectRequested
+		case "NOTICE":
+			if len(msg.params) > 0 && strings.Contains(msg.params[len(msg.params)-1], "authentication failed") {
+				return errors.New("twitch login authentication failed")
+			}
+		case "PRIVMSG":
+			message, ok := msg.toChatMessage()
+			if !ok {
+				continue
+			}
+			select {
+			case out <- message:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("in scanner.Scan(): %w", err)
+	}
+	return errors.New("connection closed by server")
+}
+
+func (c *TwitchChat) writeLine(line string) error {
+	c.connMu.Lock()
+	defer c.connMu.Unlock()
+	if c.conn == nil {
+		return errNotConnected
+	}
+	if _, err := c.conn.Write([]byte(line + "\r\n")); err != nil {
+		return fmt.Errorf("write to twitch chat: %w", err)
+	}
+	return nil
+}
+
+// PostMessage チャンネルにメッセージを投稿する。改行は空白にし、最大文字数を超える場合は分割して投稿する。
+func (c *TwitchChat) PostMessage(_ context.Context, message string) error {
+	message = strings.NewReplacer("\r", " ", "\n", " ").Replace(message)
+	for _, part := range chat.SplitMessage(message, MaxMessageLength) {
+		if err := c.writeLine("PRIVMSG #" + c.config.Channel + " :" + part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsTransientPostError 再接続を待っている間や通信エラーならtrue。chat.OutboundQueueで再試行するかの判定に使う
+func IsTransientPostError(err error) bool {
+	if errors.Is(err, errNotConnected) {
+		return true
+	}
+	var errNet net.Error
+	return errors.As(err, &errNet)
+}
+
+// calculateRetryInterval 連続で失敗した回数に応じて再接続までの間隔を伸ばす
+func calculateRetryInterval(numContinuousFailed int) time.Duration {
+	// 2^9秒でmaxRetryIntervalを超えるため、それ以上はシフトしない
+	return min(time.Second<<min(numContinuousFailed, 9), maxRetryInterval)
+}
@@ -0,0 +1,140 @@
+package twitchbot
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app.modules/core/chat"
+)
+
+func TestTwitchChat_ReceiveAndPostMessage(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	server, client := net.Pipe()
+	defer func() { _ = server.Close() }()
+	c := NewTwitchChat(Config{Channel: "channel", BotLogin: "bot", OAuthToken: "token"},
+		func(context.Context, string, error) {})
+	c.dial = func(context.Context) (net.Conn, error) { return client, nil }
+
+	out := make(chan chat.ChatMessage)
+	done := make(chan error, 1)
+	go func() { done <- c.Receive(ctx, out) }()
+
+	reader := bufio.NewReader(server)
+	readLine := func() string {
+		t.Helper()
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		return line
+	}
+	writeLine := func(line string) {
+		t.Helper()
+		_, err := server.Write([]byte(line + "\r\n"))
+		require.NoError(t, err)
+	}
+
+	// ログイン
+	assert.Equal(t, "CAP REQ :twitch.tv/tags twitch.tv/commands\r\n", readLine())
+	assert.Equal(t, "PASS oauth:token\r\n", readLine())
+	assert.Equal(t, "NICK bot\r\n", readLine())
+	assert.Equal(t, "JOIN #channel\r\n", readLine())
+	writeLine(":tmi.twitch.tv 001 bot :Welcome, GLHF!")
+
+	writeLine("PING :tmi.twitch.tv")
+	assert.Equal(t, "PONG :tmi.twitch.tv\r\n", readLine())
+
+	writeLine("@display-name=User;id=message-id;user-id=123 :user!user@user.tmi.twitch.tv PRIVMSG #channel :!in")
+	message := <-out
+	assert.Equal(t, "twitch:123", message.Author.ID)
+	assert.Equal(t, "!in", message.Text)
+
+	posted := make(chan error, 1)
+	go func() { posted <- c.PostMessage(ctx, "@User さん、\n作業を始めました") }()
+	assert.Equal(t, "PRIVMSG #channel :@User さん、 作業を始めました\r\n", readLine())
+	require.NoError(t, <-posted)
+
+	// 最大文字数を超えるメッセージは分割して投稿する
+	go func() { posted <- c.PostMessage(ctx, strings.Repeat("あ", MaxMessageLength)+"い") }()
+	assert.Equal(t, "PRIVMSG #channel :"+strings.Repeat("あ", MaxMessageLength)+"\r\n", readLine())
+	assert.Equal(t, "PRIVMSG #channel :い\r\n", readLine())
+	require.NoError(t, <-posted)
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+	err := c.PostMessage(context.Background(), "not connected")
+	assert.ErrorIs(t, err, errNotConnected)
+	assert.True(t, IsTransientPostError(err))
+}
+
+func TestTwitchChat_Helix(t *testing.T) {
+	type request struct {
+		method string
+		query  map[string]string
+		header http.Header
+		body   helixBanRequest
+	}
+	var requests []request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/helix/moderation/bans", r.URL.Path)
+		req := request{method: r.Method, query: map[string]string{}, header: r.Header}
+		for key := range r.URL.Query() {
+			req.query[key] = r.URL.Query().Get(key)
+		}
+		if body, _ := io.ReadAll(r.Body); len(body) > 0 {
+			assert.NoError(t, json.Unmarshal(body, &req.body))
+		}
+		requests = append(requests, req)
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := NewTwitchChat(Config{OAuthToken: "token", ClientID: "client", BroadcasterID: "10", ModeratorID: "20"}, nil)
+	c.helixEndpoint = srv.URL + "/helix/"
+
+	banID, err := c.BanUser(ctx, "123")
+	require.NoError(t, err)
+	assert.Equal(t, "123", banID)
+	_, err = c.TimeOut(ctx, "123", 10*time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, c.UnbanUser(ctx, banID))
+	_, err = c.TimeOut(ctx, "123", 15*24*time.Hour)
+	assert.Error(t, err)
+
+	require.Len(t, requests, 3)
+	assert.Equal(t, http.MethodPost, requests[0].method)
+	assert.Equal(t, map[string]string{"broadcaster_id": "10", "moderator_id": "20"}, requests[0].query)
+	assert.Equal(t, "Bearer token", requests[0].header.Get("Authorization"))
+	assert.Equal(t, "client", requests[0].header.Get("Client-Id"))
+	assert.Equal(t, helixBanData{UserID: "123"}, requests[0].body.Data)
+	assert.Equal(t, helixBanData{UserID: "123", Duration: 600}, requests[1].body.Data)
+	assert.Equal(t, http.MethodDelete, requests[2].method)
+	assert.Equal(t, map[string]string{"broadcaster_id": "10", "moderator_id": "20", "user_id": "123"}, requests[2].query)
+}
+
+func TestTwitchChat_HelixError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"user is already banned"}`, http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	c := NewTwitchChat(Config{}, nil)
+	c.helixEndpoint = srv.URL + "/"
+	_, err := c.BanUser(context.Background(), "123")
+	assert.ErrorContains(t, err, "status 400")
+	assert.ErrorContains(t, err, "user is already banned")
+}
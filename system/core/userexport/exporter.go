@@ -0,0 +1,237 @@
+// Package userexport は全ユーザーのドキュメントを、メモリに溜めずにページ単位でJSON LinesまたはCSVに書き出す。
+// ページごとにチェックポイント（最後に書き出したユーザーIDと出力のバイト数）を返すので、中断しても続きから再開できる。
+package userexport
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/api/iterator"
+
+	"app.modules/core/repository"
+	"app.modules/core/timeutil"
+)
+
+type Format string
+
+const (
+	FormatJSONLines Format = "jsonl"
+	FormatCSV       Format = "csv"
+)
+
+// Field 書き出す項目。user_idは常に先頭に書き出す
+type Field string
+
+const (
+	FieldTotalStudySec    Field = "total_study_sec"
+	FieldRankPoint        Field = "rank_point"
+	FieldRegistrationDate Field = "registration_date"
+	FieldLastEntered      Field = "last_entered"
+)
+
+var allFields = []Field{FieldTotalStudySec, FieldRankPoint, FieldRegistrationDate, FieldLastEntered}
+
+const userIDColumn = "user_id"
+
+// Options 書き出しの設定
+type Options struct {
+	Format   Format
+	Fields   []Field
+	PageSize int // 0ならrepository.DefaultUserPageSize
+}
+
+// Checkpoint ここまで書き出したという記録。Exportに渡すとLastUserIDの次のユーザーから書き出す
+type Checkpoint struct {
+	LastUserID string `json:"last_user_id"`
+	Offset     int64  `json:"offset"`   // 書き出し済みのバイト数。再開時は出力をここまで切り詰めてから追記する
+	Exported   int    `json:"exported"` // 書き出し済みのユーザー数
+}
+
+// ParseFields カンマ区切りの項目名を解析する。空なら全項目。
+func ParseFields(s string) ([]Field, error) {
+	if strings.TrimSpace(s) == "" {
+		return slices.Clone(allFields), nil
+	}
+	var fields []Field
+	for _, name := range strings.Split(s, ",") {
+		field := Field(strings.TrimSpace(name))
+		if !slices.Contains(allFields, field) {
+			return nil, fmt.Errorf("unknown field: %q", field)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// Export fromの続きから全ユーザーをwに書き出す。1ページ書き出すたびにonCheckpointを呼ぶ。
+// wにはfrom.Offsetバイト目から書き込まれる前提で、from.Offsetが0のときだけCSVのヘッダーを書く。
+func Export(ctx context.Context, repo repository.Repository, w io.Writer, opts Options, from Checkpoint,
+	onCheckpoint func(Checkpoint) error,
+) (Checkpoint, error) {
+	if opts.Format != FormatJSONLines && opts.Format != FormatCSV {
+		return from, fmt.Errorf("unknown format: %q", opts.Format)
+	}
+	pageSize := opts.PageSize
+	if pageSize <= 0 {
+		pageSize = repository.DefaultUserPageSize
+	}
+
+	counter := &countingWriter{w: w}
+	buffered := bufio.NewWriter(counter)
+	var csvWriter *csv.Writer
+	if opts.Format == FormatCSV {
+		csvWriter = csv.NewWriter(buffered)
+		if from.Offset == 0 {
+			header := []string{userIDColumn}
+			for _, field := range opts.Fields {
+				header = append(header, string(field))
+			}
+			if err := csvWriter.Write(header); err != nil {
+				return from, fmt.Errorf("write csv header: %w", err)
+			}
+		}
+	}
+
+	checkpoint := from
+	flush := func(cursor string) error {
+		if csvWriter != nil {
+			csvWriter.Flush()
+			if err := csvWriter.Error(); err != nil {
+				return fmt.Errorf("flush csv: %w", err)
+			}
+		}
+		if err := buffered.Flush(); err != nil {
+			return fmt.Errorf("flush: %w", err)
+		}
+		checkpoint.LastUserID = cursor
+		checkpoint.Offset = from.Offset + counter.n
+		if onCheckpoint != nil {
+			if err := onCheckpoint(checkpoint); err != nil {
+				return fmt.Errorf("in onCheckpoint(): %w", err)
+			}
+		}
+		return nil
+	}
+
+	iter := repository.NewUserIterator(ctx, repo, from.LastUserID, pageSize)
+	inPage := 0
+	for {
+		user, err := iter.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			return checkpoint, fmt.Errorf("in iter.Next(): %w", err)
+		}
+
+		values := fieldValues(user, opts.Fields)
+		if csvWriter != nil {
+			record := []string{user.UserID}
+			for _, value := range values {
+				record = append(record, formatCSVValue(value))
+			}
+			if err := csvWriter.Write(record); err != nil {
+				return checkpoint, fmt.Errorf("write csv record: %w", err)
+			}
+		} else {
+			if err := writeJSONLine(buffered, user.UserID, opts.Fields, values); err != nil {
+				return checkpoint, err
+			}
+		}
+		checkpoint.Exported++
+		inPage++
+
+		if inPage == pageSize {
+			if err := flush(iter.Cursor()); err != nil {
+				return checkpoint, err
+			}
+			inPage = 0
+		}
+	}
+	if err := flush(iter.Cursor()); err != nil {
+		return checkpoint, err
+	}
+	return checkpoint, nil
+}
+
+func fieldValues(user repository.UserDocWithID, fields []Field) []any {
+	values := make([]any, 0, len(fields))
+	for _, field := range fields {
+		switch field {
+		case FieldTotalStudySec:
+			values = append(values, user.TotalStudySec)
+		case FieldRankPoint:
+			values = append(values, user.RankPoint)
+		case FieldRegistrationDate:
+			values = append(values, formatTime(user.RegistrationDate))
+		case FieldLastEntered:
+			values = append(values, formatTime(user.LastEntered))
+		}
+	}
+	return values
+}
+
+// formatTime 日本時間のRFC3339で書き出す。未設定なら空文字列
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.In(timeutil.JapanLocation()).Format(time.RFC3339)
+}
+
+func formatCSVValue(value any) string {
+	switch v := value.(type) {
+	case int:
+		return strconv.Itoa(v)
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// writeJSONLine 項目の順番を保つため、mapではなく1項目ずつ書き出す。項目名はASCIIの定数なのでそのまま使う。
+func writeJSONLine(w io.Writer, userID string, fields []Field, values []any) error {
+	keys := []string{userIDColumn}
+	for _, field := range fields {
+		keys = append(keys, string(field))
+	}
+	var line strings.Builder
+	for i, v := range append([]any{userID}, values...) {
+		value, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("in json.Marshal(): %w", err)
+		}
+		if i == 0 {
+			line.WriteString("{")
+		} else {
+			line.WriteString(",")
+		}
+		line.WriteString(`"` + keys[i] + `":` + string(value))
+	}
+	line.WriteString("}\n")
+	if _, err := io.WriteString(w, line.String()); err != nil {
+		return fmt.Errorf("write json line: %w", err)
+	}
+	return nil
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,91 @@
+package userexport
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app.modules/core/repository"
+)
+
+func newTestRepository(t *testing.T) *repository.InMemoryRepository {
+	repo := repository.NewInMemoryRepository()
+	registeredAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	for i := 1; i <= 5; i++ {
+		require.NoError(t, repo.CreateUser(context.Background(), nil, fmt.Sprintf("user-%d", i), repository.UserDoc{
+			TotalStudySec:    i * 60,
+			RankPoint:        i * 100,
+			RegistrationDate: registeredAt,
+		}))
+	}
+	return repo
+}
+
+func TestExport_JSONLines(t *testing.T) {
+	repo := newTestRepository(t)
+	out := &bytes.Buffer{}
+	var checkpoints []Checkpoint
+	opts := Options{Format: FormatJSONLines, Fields: []Field{FieldTotalStudySec, FieldRegistrationDate, FieldLastEntered}, PageSize: 2}
+
+	last, err := Export(context.Background(), repo, out, opts, Checkpoint{}, func(c Checkpoint) error {
+		checkpoints = append(checkpoints, c)
+		return nil
+	})
+	require.NoError(t, err)
+
+	lines := bytes.Split(bytes.TrimSuffix(out.Bytes(), []byte("\n")), []byte("\n"))
+	require.Len(t, lines, 5)
+	assert.Equal(t, `{"user_id":"user-1","total_study_sec":60,"registration_date":"2026-01-02T12:04:05+09:00","last_entered":""}`, string(lines[0]))
+	assert.Equal(t, Checkpoint{LastUserID: "user-5", Offset: int64(out.Len()), Exported: 5}, last)
+	require.GreaterOrEqual(t, len(checkpoints), 3)
+	assert.Equal(t, "user-2", checkpoints[0].LastUserID)
+	assert.Equal(t, 2, checkpoints[0].Exported)
+}
+
+func TestExport_CSVResumesFromCheckpoint(t *testing.T) {
+	repo := newTestRepository(t)
+	opts := Options{Format: FormatCSV, Fields: []Field{FieldTotalStudySec, FieldRankPoint}, PageSize: 2}
+
+	// 2ページ目の途中で中断したことにする
+	out := &bytes.Buffer{}
+	var saved Checkpoint
+	errInterrupted := errors.New("interrupted")
+	_, err := Export(context.Background(), repo, out, opts, Checkpoint{}, func(c Checkpoint) error {
+		if c.Exported > 2 {
+			out.WriteString("user-3,partial") // 書きかけの行
+			return errInterrupted
+		}
+		saved = c
+		return nil
+	})
+	require.ErrorIs(t, err, errInterrupted)
+	assert.Equal(t, "user-2", saved.LastUserID)
+
+	// 保存したチェックポイントまで切り詰めて再開する
+	out.Truncate(int(saved.Offset))
+	last, err := Export(context.Background(), repo, out, opts, saved, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "user_id,total_study_sec,rank_point\n"+
+		"user-1,60,100\nuser-2,120,200\nuser-3,180,300\nuser-4,240,400\nuser-5,300,500\n", out.String())
+	assert.Equal(t, Checkpoint{LastUserID: "user-5", Offset: int64(out.Len()), Exported: 5}, last)
+}
+
+func TestParseFields(t *testing.T) {
+	fields, err := ParseFields("")
+	require.NoError(t, err)
+	assert.Equal(t, allFields, fields)
+
+	fields, err = ParseFields("rank_point, last_entered")
+	require.NoError(t, err)
+	assert.Equal(t, []Field{FieldRankPoint, FieldLastEntered}, fields)
+
+	_, err = ParseFields("rank_point,unknown")
+	assert.Error(t, err)
+}
@@ -0,0 +1,349 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	i18nmsg "app.modules/core/i18n/typed"
+)
+
+// CommandParser はコマンドの引数を解析する。
+// fullStringは整形済みのコマンド全文、argStrはそこからコマンド名を除いた部分。
+// isMemberSeatはメンバー席用のコマンド名（/in など）で呼ばれたかどうか。
+type CommandParser func(fullString string, argStr string, isMemberSeat bool) (*CommandDetails, string)
+
+// EmojiCommand はメンバー用の絵文字コマンド。絵文字名がNameのものをTextに置換してから解析する。
+type EmojiCommand struct {
+	Name string
+	Text string
+}
+
+// CommandSpec はコマンドの定義。
+// コマンドを追加するときは、ここのcommandSpecsに1つ追加し、workspaceapp側で検証・実行処理を登録する。
+type CommandSpec struct {
+	Type CommandType
+
+	// コマンド名。先頭が正式な名前で、以降はエイリアス。
+	Names []string
+	// メンバー席を対象とするコマンド名（/in など）。
+	MemberSeatNames []string
+	// メンバーのみ使える絵文字コマンド。
+	Emojis []EmojiCommand
+	// trueならメンバーのみ使えるコマンド。
+	MemberOnly bool
+	// trueならモデレーター・オーナーのみ使えるコマンド。!helpでもモデレーター・オーナーにのみ表示する。
+	ModeratorOnly bool
+
+	// nilなら引数は無視する。
+	Parse CommandParser
+	// !helpで表示する使い方。
+	Usage func() string
+}
+
+func (s *CommandSpec) parse(fullString string, argStr string, isMemberSeat bool) (*CommandDetails, string) {
+	if s.Parse == nil {
+		return &CommandDetails{
+			CommandType: s.Type,
+		}, ""
+	}
+	return s.Parse(fullString, argStr, isMemberSeat)
+}
+
+var commandSpecs = []CommandSpec{
+	{
+		Type:            In,
+		Names:           []string{InCommand, WorkCommand},
+		MemberSeatNames: []string{MemberInCommand, MemberWorkCommand},
+		Emojis: []EmojiCommand{
+			{Name: InZeroString, Text: InZeroCommand},
+			{Name: InString, Text: InCommand},
+			{Name: MemberInString, Text: MemberInCommand},
+			{Name: MemberInZeroString, Text: MemberInZeroCommand},
+		},
+		Parse: func(_ string, argStr string, isMemberSeat bool) (*CommandDetails, string) {
+			return ParseIn(argStr, isMemberSeat, false, 0)
+		},
+		Usage: i18nmsg.CommandHelpIn,
+	},
+	{
+		Type:   Out,
+		Names:  []string{OutCommand},
+		Emojis: []EmojiCommand{{Name: OutString, Text: OutCommand}},
+		Usage:  i18nmsg.CommandHelpOut,
+	},
+	{
+		Type:  Undo,
+		Names: []string{UndoCommand, BackCommand},
+		Usage: i18nmsg.CommandHelpUndo,
+	},
+	{
+		Type:  Info,
+		Names: []string{InfoCommand},
+		Emojis: []EmojiCommand{
+			{Name: InfoString, Text: InfoCommand},
+			{Name: InfoDString, Text: InfoDCommand},
+		},
+		Parse: func(_ string, argStr string, _ bool) (*CommandDetails, string) {
+			return ParseInfo(argStr)
+		},
+		Usage: i18nmsg.CommandHelpInfo,
+	},
+	{
+		Type:   My,
+		Names:  []string{MyCommand},
+		Emojis: []EmojiCommand{{Name: MyString, Text: MyCommand}},
+		Parse: func(_ string, argStr string, _ bool) (*CommandDetails, string) {
+			return ParseMy(argStr)
+		},
+		Usage: i18nmsg.CommandHelpMy,
+	},
+	{
+		Type:   Change,
+		Names:  []string{ChangeCommand},
+		Emojis: []EmojiCommand{{Name: ChangeString, Text: ChangeCommand}},
+		Parse: func(_ string, argStr string, _ bool) (*CommandDetails, string) {
+			return ParseChange(argStr)
+		},
+		Usage: i18nmsg.CommandHelpChange,
+	},
+	{
+		Type:  Seat,
+		Names: []string{SeatCommand},
+		Emojis: []EmojiCommand{
+			{Name: SeatString, Text: SeatCommand},
+			{Name: SeatDString, Text: SeatDCommand},
+		},
+		Parse: func(_ string, argStr string, _ bool) (*CommandDetails, string) {
+			return ParseSeat(argStr)
+		},
+		Usage: i18nmsg.CommandHelpSeat,
+	},
+	{
+		Type:  Report,
+		Names: []string{ReportCommand},
+		// NOTE: !reportの場合は全文を送信する。
+		Parse: func(fullString string, _ string, _ bool) (*CommandDetails, string) {
+			return ParseReport(fullString)
+		},
+		Usage: i18nmsg.CommandHelpReport,
+	},
+	{
+		Type:            Kick,
+		Names:           []string{KickCommand},
+		MemberSeatNames: []string{MemberKickCommand},
+		ModeratorOnly:   true,
+		Parse: func(_ string, argStr string, isMemberSeat bool) (*CommandDetails, string) {
+			return ParseKick(argStr, isMemberSeat)
+		},
+		Usage: i18nmsg.CommandHelpKick,
+	},
+	{
+		Type:            Check,
+		Names:           []string{CheckCommand},
+		MemberSeatNames: []string{MemberCheckCommand},
+		ModeratorOnly:   true,
+		Parse: func(_ string, argStr string, isMemberSeat bool) (*CommandDetails, string) {
+			return ParseCheck(argStr, isMemberSeat)
+		},
+		Usage: i18nmsg.CommandHelpCheck,
+	},
+	{
+		Type:            Block,
+		Names:           []string{BlockCommand},
+		MemberSeatNames: []string{MemberBlockCommand},
+		ModeratorOnly:   true,
+		Parse: func(_ string, argStr string, isMemberSeat bool) (*CommandDetails, string) {
+			return ParseBlock(argStr, isMemberSeat)
+		},
+		Usage: i18nmsg.CommandHelpBlock,
+	},
+	{
+		Type:            Timeout,
+		Names:           []string{TimeoutCommand},
+		MemberSeatNames: []string{MemberTimeoutCommand},
+		ModeratorOnly:   true,
+		Parse: func(_ string, argStr string, isMemberSeat bool) (*CommandDetails, string) {
+			return ParseTimeout(argStr, isMemberSeat)
+		},
+		Usage: i18nmsg.CommandHelpTimeout,
+	},
+	{
+		Type:   More,
+		Names:  []string{MoreCommand, OkawariCommand},
+		Emojis: []EmojiCommand{{Name: MoreString, Text: MoreCommand}},
+		Parse: func(_ string, argStr string, _ bool) (*CommandDetails, string) {
+			return ParseMore(argStr)
+		},
+		Usage: i18nmsg.CommandHelpMore,
+	},
+	{
+		Type:   Break,
+		Names:  []string{BreakCommand, RestCommand, ChillCommand},
+		Emojis: []EmojiCommand{{Name: BreakString, Text: BreakCommand}},
+		Parse: func(_ string, argStr string, _ bool) (*CommandDetails, string) {
+			return ParseBreak(argStr)
+		},
+		Usage: i18nmsg.CommandHelpBreak,
+	},
+	{
+		Type:   Resume,
+		Names:  []string{ResumeCommand},
+		Emojis: []EmojiCommand{{Name: ResumeString, Text: ResumeCommand}},
+		Parse: func(_ string, argStr string, _ bool) (*CommandDetails, string) {
+			return ParseResume(argStr)
+		},
+		Usage: i18nmsg.CommandHelpResume,
+	},
+	{
+		Type:   Rank,
+		Names:  []string{RankCommand},
+		Emojis: []EmojiCommand{{Name: RankString, Text: RankCommand}},
+		Usage:  i18nmsg.CommandHelpRank,
+	},
+	{
+		Type:  Order,
+		Names: []string{OrderCommand},
+		Emojis: []EmojiCommand{
+			{Name: OrderString, Text: OrderCommand},
+			{Name: OrderClearString, Text: OrderClearCommand},
+		},
+		Parse: func(_ string, argStr string, _ bool) (*CommandDetails, string) {
+			return ParseOrder(argStr)
+		},
+		Usage: i18nmsg.CommandHelpOrder,
+	},
+	{
+		Type:  Clear,
+		Names: []string{ClearCommand, ClearShortCommand},
+		Usage: i18nmsg.CommandHelpClear,
+	},
+	{
+		Type:  History,
+		Names: []string{HistoryCommand},
+		Parse: func(_ string, argStr string, _ bool) (*CommandDetails, string) {
+			return ParseHistory(argStr)
+		},
+		Usage: i18nmsg.CommandHelpHistory,
+	},
+	{
+		Type:  Help,
+		Names: []string{HelpCommand},
+		Parse: func(_ string, argStr string, _ bool) (*CommandDetails, string) {
+			return ParseHelp(argStr)
+		},
+		Usage: i18nmsg.CommandHelpHelp,
+	},
+	{
+		Type:            Reserve,
+		Names:           []string{ReserveCommand},
+		MemberSeatNames: []string{MemberReserveCommand},
+		Parse: func(_ string, argStr string, isMemberSeat bool) (*CommandDetails, string) {
+			return ParseReserve(argStr, isMemberSeat)
+		},
+		Usage: i18nmsg.CommandHelpReserve,
+	},
+	{
+		Type:  Streak,
+		Names: []string{StreakCommand},
+		Usage: i18nmsg.CommandHelpStreak,
+	},
+	{
+		Type:          Quota,
+		Names:         []string{QuotaCommand},
+		ModeratorOnly: true,
+		Usage:         i18nmsg.CommandHelpQuota,
+	},
+}
+
+type commandNameEntry struct {
+	spec         *CommandSpec
+	isMemberSeat bool
+}
+
+var commandNameIndex = buildCommandNameIndex(commandSpecs)
+
+func buildCommandNameIndex(specs []CommandSpec) map[string]commandNameEntry {
+	index := make(map[string]commandNameEntry)
+	add := func(name string, entry commandNameEntry) {
+		if _, ok := index[name]; ok {
+			panic(fmt.Sprintf("duplicate command name: %s", name))
+		}
+		index[name] = entry
+	}
+	for i := range specs {
+		spec := &specs[i]
+		for _, name := range spec.Names {
+			add(name, commandNameEntry{spec: spec, isMemberSeat: false})
+		}
+		for _, name := range spec.MemberSeatNames {
+			add(name, commandNameEntry{spec: spec, isMemberSeat: true})
+		}
+	}
+	return index
+}
+
+// CommandSpecs は登録されている全コマンドの定義を返す。
+func CommandSpecs() []CommandSpec {
+	return commandSpecs
+}
+
+// LookupCommandSpec はコマンドの種類から定義を返す。
+func LookupCommandSpec(commandType CommandType) (CommandSpec, bool) {
+	for _, spec := range commandSpecs {
+		if spec.Type == commandType {
+			return spec, true
+		}
+	}
+	return CommandSpec{}, false
+}
+
+// OptionHelp はコマンドのオプションの使い方。
+type OptionHelp struct {
+	Keys  []string
+	Usage func() string
+}
+
+var optionHelps = []OptionHelp{
+	{Keys: []string{WorkNameOptionKey, "w"}, Usage: i18nmsg.CommandHelpOptionWork},
+	{Keys: []string{TimeOptionKey, "m"}, Usage: i18nmsg.CommandHelpOptionMin},
+	{Keys: []string{OrderOptionKey, "o"}, Usage: i18nmsg.CommandHelpOptionOrder},
+	{Keys: []string{PomodoroOptionKey}, Usage: i18nmsg.CommandHelpOptionPomo},
+	{Keys: []string{FavoriteColorMyOptionKey}, Usage: i18nmsg.CommandHelpOptionColor},
+	{Keys: []string{DailyGoalMyOptionKey}, Usage: i18nmsg.CommandHelpOptionGoal},
+	{Keys: []string{RankVisibleMyOptionKey}, Usage: i18nmsg.CommandHelpOptionRank},
+}
+
+// HelpUsage は!helpのトピック（コマンド名またはオプション名）に対応する使い方を返す。
+// トピックは "in"、"!in"、"/in"、"min="のいずれの形式でもよい。"rank="のように=が付いている場合はオプションとして探す。
+// isModeratorOrOwnerがfalseならモデレーター用のコマンドは見つからなかったものとする。
+func HelpUsage(topic string, isModeratorOrOwner bool) (string, bool) {
+	name := strings.ToLower(topic)
+	name = strings.TrimPrefix(name, CommandPrefix)
+	name = strings.TrimPrefix(name, MemberCommandPrefix)
+	isOption := strings.HasSuffix(name, HalfWidthEqualSign)
+	name = strings.TrimSuffix(name, HalfWidthEqualSign)
+	if name == "" {
+		return "", false
+	}
+
+	if !isOption {
+		for _, commandName := range []string{CommandPrefix + name, MemberCommandPrefix + name} {
+			entry, ok := commandNameIndex[commandName]
+			if !ok || entry.spec.Usage == nil {
+				continue
+			}
+			if entry.spec.ModeratorOnly && !isModeratorOrOwner {
+				return "", false
+			}
+			return entry.spec.Usage(), true
+		}
+	}
+	for _, option := range optionHelps {
+		for _, key := range option.Keys {
+			if key == name {
+				return option.Usage(), true
+			}
+		}
+	}
+	return "", false
+}
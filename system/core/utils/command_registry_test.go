@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"app.modules/core/i18n"
+)
+
+func TestCommandSpecs(t *testing.T) {
+	t.Run("コマンドの種類は重複しない", func(t *testing.T) {
+		seen := make(map[CommandType]bool)
+		for _, spec := range CommandSpecs() {
+			assert.False(t, seen[spec.Type], "duplicate command type: %d", spec.Type)
+			seen[spec.Type] = true
+			assert.NotEmpty(t, spec.Names, "command type %d has no name", spec.Type)
+			assert.NotNil(t, spec.Usage, "command type %d has no usage", spec.Type)
+		}
+	})
+
+	t.Run("コマンド名は重複しない", func(t *testing.T) {
+		assert.NotPanics(t, func() {
+			buildCommandNameIndex(CommandSpecs())
+		})
+		assert.Panics(t, func() {
+			buildCommandNameIndex([]CommandSpec{
+				{Type: Out, Names: []string{OutCommand}},
+				{Type: Clear, Names: []string{OutCommand}},
+			})
+		})
+	})
+
+	t.Run("種類から定義を取得できる", func(t *testing.T) {
+		spec, ok := LookupCommandSpec(Break)
+		assert.True(t, ok)
+		assert.Equal(t, []string{BreakCommand, RestCommand, ChillCommand}, spec.Names)
+
+		_, ok = LookupCommandSpec(InvalidCommand)
+		assert.False(t, ok)
+	})
+}
+
+func TestHelpUsage(t *testing.T) {
+	if err := i18n.LoadLocaleFolderFS(); err != nil {
+		panic(err)
+	}
+
+	for _, topic := range []string{"in", "!in", "/in", "IN", "work"} {
+		usage, ok := HelpUsage(topic, false)
+		assert.True(t, ok, topic)
+		assert.NotEmpty(t, usage, topic)
+	}
+
+	// オプション名はどの形式でも同じ説明になる
+	minUsage, _ := HelpUsage("min", false)
+	for _, topic := range []string{"min=", "m"} {
+		usage, ok := HelpUsage(topic, false)
+		assert.True(t, ok, topic)
+		assert.Equal(t, minUsage, usage, topic)
+	}
+
+	for _, topic := range []string{"", "!", "foo"} {
+		_, ok := HelpUsage(topic, false)
+		assert.False(t, ok, topic)
+	}
+
+	// =が付いていればコマンドではなくオプションの説明になる
+	rankCommandUsage, _ := HelpUsage("rank", false)
+	rankOptionUsage, ok := HelpUsage("rank=", false)
+	assert.True(t, ok)
+	assert.NotEqual(t, rankCommandUsage, rankOptionUsage)
+
+	// モデレーター用のコマンドはモデレーター・オーナーにのみ表示する
+	for _, topic := range []string{"kick", "check", "block", "timeout", "quota"} {
+		_, ok := HelpUsage(topic, false)
+		assert.False(t, ok, topic)
+		usage, ok := HelpUsage(topic, true)
+		assert.True(t, ok, topic)
+		assert.NotEmpty(t, usage, topic)
+	}
+}
+
+func TestParseCommand_MemberOnly(t *testing.T) {
+	if err := i18n.LoadLocaleFolderFS(); err != nil {
+		panic(err)
+	}
+
+	// メンバー限定のコマンドを一時的に登録する
+	specs := []CommandSpec{
+		{Type: Rank, Names: []string{RankCommand}, MemberOnly: true},
+	}
+	originalIndex := commandNameIndex
+	commandNameIndex = buildCommandNameIndex(specs)
+	t.Cleanup(func() {
+		commandNameIndex = originalIndex
+	})
+
+	out, message := ParseCommand("!rank", true)
+	assert.Empty(t, message)
+	assert.Equal(t, &CommandDetails{CommandType: Rank}, out)
+
+	out, message = ParseCommand("!rank", false)
+	assert.NotEmpty(t, message)
+	assert.Nil(t, out)
+}
@@ -28,18 +28,29 @@ const (
 	WorkCommand       = "!work"
 	ClearCommand      = "!clear"
 	ClearShortCommand = "!clr"
+	HistoryCommand    = "!history"
+	HelpCommand       = "!help"
+	UndoCommand       = "!undo"
+	BackCommand       = "!back"
+	ReserveCommand    = "!reserve"
+	StreakCommand     = "!streak"
 
-	KickCommand  = "!kick"
-	CheckCommand = "!check"
-	BlockCommand = "!block"
+	KickCommand    = "!kick"
+	CheckCommand   = "!check"
+	BlockCommand   = "!block"
+	TimeoutCommand = "!timeout"
+	QuotaCommand   = "!quota"
 
 	MemberInCommand     = "/in"
 	MemberInZeroCommand = "/0"
 	MemberWorkCommand   = "/work"
 
-	MemberKickCommand  = "/kick"
-	MemberCheckCommand = "/check"
-	MemberBlockCommand = "/block"
+	MemberKickCommand    = "/kick"
+	MemberCheckCommand   = "/check"
+	MemberBlockCommand   = "/block"
+	MemberTimeoutCommand = "/timeout"
+
+	MemberReserveCommand = "/reserve"
 
 	EmojiSide          = ":"
 	EmojiCommandPrefix = EmojiSide + "_command"
@@ -74,6 +85,15 @@ const (
 	OrderOptionPrefix = "order="
 	OrderOptionKey    = "order"
 
+	PomodoroOptionPrefix    = "pomo="
+	PomodoroOptionKey       = "pomo"
+	PomodoroOptionSeparator = "/"
+	PomodoroOffValue        = "off" // ポモドーロを解除する。例：!change pomo off
+	DefaultPomodoroWorkMin  = 25
+	DefaultPomodoroBreakMin = 5
+
+	DefaultUndoExitGraceMin = 5 // ConstantsConfigDoc.UndoExitGraceMinが未設定（0）のときに!undoで退室を取り消せる時間（分）
+
 	ShowDetailsOption = "d"
 	OrderClearOption  = "-"
 
@@ -85,10 +105,29 @@ const (
 	FavoriteColorMyOptionPrefix = "color="
 	FavoriteColorMyOptionKey    = "color"
 
+	DailyGoalMyOptionPrefix = "goal="
+	DailyGoalMyOptionKey    = "goal"
+	MaxDailyGoalMin         = 24 * 60
+
+	DefaultHistorySessionCount = 1
+	MaxHistorySessionCount     = 5
+	HistoryLookbackDays        = 30  // NOTE: !historyで遡る最大日数
+	HistoryMaxSegments         = 500 // NOTE: !historyで読み込む作業セグメントの最大件数
+
+	SeatReservationsReadLimit = 500 // NOTE: 開始時刻を過ぎた（または間近の）座席予約を一度に読み込む最大件数
+
+	MaxTimeoutMin = 24 * 60
+
+	ReserveTimeLayout    = "15:04"
+	ReserveStartAtLayout = "1/2 15:04" // 予約の開始日時の表示
+	ReserveCancelOption  = "cancel"    // 予約を取り消す。例：!reserve cancel 5
+
 	FullWidthSpace     = "　"
 	HalfWidthSpace     = " "
 	FullWidthEqualSign = "＝"
 	HalfWidthEqualSign = "="
+	FullWidthColon     = "："
+	HalfWidthColon     = ":"
 )
 
 type EmojiElement int
@@ -23,6 +23,16 @@ func TestParseChange(t *testing.T) {
 				},
 			},
 		},
+		{
+			Name:  "ポモドーロを解除",
+			Input: "!change pomo off",
+			Output: &CommandDetails{
+				CommandType: Change,
+				ChangeOption: MinWorkOrderOption{
+					IsPomodoroSet: true,
+				},
+			},
+		},
 		{
 			Name:    "オプションなしは不可",
 			Input:   "!change",
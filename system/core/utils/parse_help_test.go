@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"app.modules/core/i18n"
+)
+
+func TestParseHelp(t *testing.T) {
+	testCases := []ParseCommandTestCase{
+		{
+			Name:  "ヘルプ（指定なし）",
+			Input: "!help",
+			Output: &CommandDetails{
+				CommandType: Help,
+			},
+		},
+		{
+			Name:  "ヘルプ（コマンド指定）",
+			Input: "!help in",
+			Output: &CommandDetails{
+				CommandType: Help,
+				HelpOption: HelpOption{
+					Topic: "in",
+				},
+			},
+		},
+		{
+			Name:  "ヘルプ（全角！と全角スペース）",
+			Input: "！help　!break",
+			Output: &CommandDetails{
+				CommandType: Help,
+				HelpOption: HelpOption{
+					Topic: "!break",
+				},
+			},
+		},
+	}
+
+	if err := i18n.LoadLocaleFolderFS(); err != nil {
+		panic(err)
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			out, message := ParseCommand(testCase.Input, testCase.IsMember)
+			if testCase.WillErr {
+				assert.NotEmpty(t, message, "Expected error message but got none")
+			} else {
+				assert.Empty(t, message, "Expected no error message but got: %s", message)
+				assert.Equal(t, testCase.Output, out, "Command details do not match")
+			}
+		})
+	}
+}
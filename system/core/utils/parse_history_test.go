@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"app.modules/core/i18n"
+)
+
+func TestParseHistory(t *testing.T) {
+	testCases := []ParseCommandTestCase{
+		{
+			Name:  "履歴（指定なし）",
+			Input: "!history",
+			Output: &CommandDetails{
+				CommandType: History,
+				HistoryOption: HistoryOption{
+					SessionCount: DefaultHistorySessionCount,
+				},
+			},
+		},
+		{
+			Name:  "履歴（件数指定）",
+			Input: "!history 3",
+			Output: &CommandDetails{
+				CommandType: History,
+				HistoryOption: HistoryOption{
+					SessionCount: 3,
+				},
+			},
+		},
+		{
+			Name:  "履歴（全角！と全角スペース）",
+			Input: "！history　2",
+			Output: &CommandDetails{
+				CommandType: History,
+				HistoryOption: HistoryOption{
+					SessionCount: 2,
+				},
+			},
+		},
+		{
+			Name:     "メンバーによる履歴",
+			Input:    "!history 5",
+			IsMember: true,
+			Output: &CommandDetails{
+				CommandType: History,
+				HistoryOption: HistoryOption{
+					SessionCount: 5,
+				},
+			},
+		},
+		{
+			Name:    "数値以外の件数（エラーケース）",
+			Input:   "!history abc",
+			WillErr: true,
+		},
+	}
+
+	if err := i18n.LoadLocaleFolderFS(); err != nil {
+		panic(err)
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			out, message := ParseCommand(testCase.Input, testCase.IsMember)
+			if testCase.WillErr {
+				assert.NotEmpty(t, message, "Expected error message but got none")
+			} else {
+				assert.Empty(t, message, "Expected no error message but got: %s", message)
+				assert.Equal(t, testCase.Output, out, "Command details do not match")
+			}
+		})
+	}
+}
@@ -709,6 +709,80 @@ func TestParseIn(t *testing.T) {
 				},
 			},
 		},
+		{
+			Name:  "ポモドーロ付き入室",
+			Input: "!in math pomo 25/5",
+			Output: &CommandDetails{
+				CommandType: In,
+				InOption: InOption{
+					MinWorkOrderOption: &MinWorkOrderOption{
+						IsWorkNameSet:    true,
+						IsPomodoroSet:    true,
+						WorkName:         "math",
+						PomodoroWorkMin:  25,
+						PomodoroBreakMin: 5,
+					},
+				},
+			},
+		},
+		{
+			Name:  "ポモドーロ付き入室（=付き、時間指定あり）",
+			Input: "!in pomo=50/10 work=数学 min=180",
+			Output: &CommandDetails{
+				CommandType: In,
+				InOption: InOption{
+					MinWorkOrderOption: &MinWorkOrderOption{
+						IsWorkNameSet:    true,
+						IsDurationMinSet: true,
+						IsPomodoroSet:    true,
+						WorkName:         "数学",
+						DurationMin:      180,
+						PomodoroWorkMin:  50,
+						PomodoroBreakMin: 10,
+					},
+				},
+			},
+		},
+		{
+			Name:  "ポモドーロの値を省略した場合はデフォルト",
+			Input: "!in pomo 英語",
+			Output: &CommandDetails{
+				CommandType: In,
+				InOption: InOption{
+					MinWorkOrderOption: &MinWorkOrderOption{
+						IsWorkNameSet:    true,
+						IsPomodoroSet:    true,
+						WorkName:         "英語",
+						PomodoroWorkMin:  DefaultPomodoroWorkMin,
+						PomodoroBreakMin: DefaultPomodoroBreakMin,
+					},
+				},
+			},
+		},
+		{
+			Name:  "ポモドーロを解除して入室",
+			Input: "!in pomo=off 英語",
+			Output: &CommandDetails{
+				CommandType: In,
+				InOption: InOption{
+					MinWorkOrderOption: &MinWorkOrderOption{
+						IsWorkNameSet: true,
+						IsPomodoroSet: true,
+						WorkName:      "英語",
+					},
+				},
+			},
+		},
+		{
+			Name:    "ポモドーロの値が不正",
+			Input:   "!in pomo 25/x",
+			WillErr: true,
+		},
+		{
+			Name:    "ポモドーロの値に休憩時間がない",
+			Input:   "!in pomo 25",
+			WillErr: true,
+		},
 		{
 			Name:     "非メンバーによるメンバー用絵文字入室（無効）",
 			Input:    TestEmojiMemberIn0,
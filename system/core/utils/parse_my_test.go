@@ -148,6 +148,37 @@ func TestParseMy(t *testing.T) {
 				},
 			},
 		},
+		{
+			Name:  "1日の目標作業時間設定",
+			Input: "!my goal=180",
+			Output: &CommandDetails{
+				CommandType: My,
+				MyOptions: []MyOption{
+					{
+						Type:     DailyGoalMin,
+						IntValue: 180,
+					},
+				},
+			},
+		},
+		{
+			Name:  "1日の目標作業時間リセット",
+			Input: "!my goal 0",
+			Output: &CommandDetails{
+				CommandType: My,
+				MyOptions: []MyOption{
+					{
+						Type:     DailyGoalMin,
+						IntValue: 0,
+					},
+				},
+			},
+		},
+		{
+			Name:    "1日の目標作業時間が数字でない",
+			Input:   "!my goal=abc",
+			WillErr: true,
+		},
 		{
 			Name:  "複数オプション設定",
 			Input: "!my min 40 color ピンク  rank off",
@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"app.modules/core/i18n"
+)
+
+func TestParseReserve(t *testing.T) {
+	testCases := []ParseCommandTestCase{
+		{
+			Name:  "予約",
+			Input: "!reserve 5 21:00",
+			Output: &CommandDetails{
+				CommandType: Reserve,
+				ReserveOption: ReserveOption{
+					SeatID: 5,
+					Hour:   21,
+					Minute: 0,
+				},
+			},
+		},
+		{
+			Name:  "予約（時間指定）",
+			Input: "!reserve 5 9:30 min=90",
+			Output: &CommandDetails{
+				CommandType: Reserve,
+				ReserveOption: ReserveOption{
+					SeatID:           5,
+					Hour:             9,
+					Minute:           30,
+					IsDurationMinSet: true,
+					DurationMin:      90,
+				},
+			},
+		},
+		{
+			Name:  "予約（全角コロンと短縮オプション）",
+			Input: "！reserve　12　21：15 m 30",
+			Output: &CommandDetails{
+				CommandType: Reserve,
+				ReserveOption: ReserveOption{
+					SeatID:           12,
+					Hour:             21,
+					Minute:           15,
+					IsDurationMinSet: true,
+					DurationMin:      30,
+				},
+			},
+		},
+		{
+			Name:     "メンバー席の予約",
+			Input:    "/reserve 2 21:00",
+			IsMember: true,
+			Output: &CommandDetails{
+				CommandType: Reserve,
+				ReserveOption: ReserveOption{
+					SeatID:             2,
+					IsTargetMemberSeat: true,
+					Hour:               21,
+					Minute:             0,
+				},
+			},
+		},
+		{
+			Name:  "予約の取り消し",
+			Input: "!reserve cancel 5",
+			Output: &CommandDetails{
+				CommandType: Reserve,
+				ReserveOption: ReserveOption{
+					SeatID:   5,
+					IsCancel: true,
+				},
+			},
+		},
+		{
+			Name:     "すべての予約の取り消し（メンバー席）",
+			Input:    "/reserve cancel",
+			IsMember: true,
+			Output: &CommandDetails{
+				CommandType: Reserve,
+				ReserveOption: ReserveOption{
+					IsTargetMemberSeat: true,
+					IsCancel:           true,
+				},
+			},
+		},
+		{
+			Name:    "取り消しの不正な席番号（エラーケース）",
+			Input:   "!reserve cancel abc",
+			WillErr: true,
+		},
+		{
+			Name:    "席番号なし（エラーケース）",
+			Input:   "!reserve",
+			WillErr: true,
+		},
+		{
+			Name:    "時刻なし（エラーケース）",
+			Input:   "!reserve 5",
+			WillErr: true,
+		},
+		{
+			Name:    "不正な時刻（エラーケース）",
+			Input:   "!reserve 5 25:00",
+			WillErr: true,
+		},
+		{
+			Name:    "不正な時間（エラーケース）",
+			Input:   "!reserve 5 21:00 min=abc",
+			WillErr: true,
+		},
+	}
+
+	if err := i18n.LoadLocaleFolderFS(); err != nil {
+		panic(err)
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			out, message := ParseCommand(testCase.Input, testCase.IsMember)
+			if testCase.WillErr {
+				assert.NotEmpty(t, message, "Expected error message but got none")
+			} else {
+				assert.Empty(t, message, "Expected no error message but got: %s", message)
+				assert.Equal(t, testCase.Output, out, "Command details do not match")
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"app.modules/core/i18n"
+)
+
+func TestParseTimeout(t *testing.T) {
+	testCases := []ParseCommandTestCase{
+		{
+			Name:  "タイムアウト",
+			Input: "!timeout 3 10",
+			Output: &CommandDetails{
+				CommandType: Timeout,
+				TimeoutOption: TimeoutOption{
+					SeatID:      3,
+					DurationMin: 10,
+				},
+			},
+		},
+		{
+			Name:  "メンバー席のタイムアウト（全角スペース）",
+			Input: "/timeout　5　60",
+			Output: &CommandDetails{
+				CommandType: Timeout,
+				TimeoutOption: TimeoutOption{
+					SeatID:             5,
+					IsTargetMemberSeat: true,
+					DurationMin:        60,
+				},
+			},
+		},
+		{
+			Name:    "席番号なし（エラーケース）",
+			Input:   "!timeout",
+			WillErr: true,
+		},
+		{
+			Name:    "時間なし（エラーケース）",
+			Input:   "!timeout 3",
+			WillErr: true,
+		},
+		{
+			Name:    "数値以外の時間（エラーケース）",
+			Input:   "!timeout 3 abc",
+			WillErr: true,
+		},
+	}
+
+	if err := i18n.LoadLocaleFolderFS(); err != nil {
+		panic(err)
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			out, message := ParseCommand(testCase.Input, testCase.IsMember)
+			if testCase.WillErr {
+				assert.NotEmpty(t, message, "Expected error message but got none")
+			} else {
+				assert.Empty(t, message, "Expected no error message but got: %s", message)
+				assert.Equal(t, testCase.Output, out, "Command details do not match")
+			}
+		})
+	}
+}
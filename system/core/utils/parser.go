@@ -7,6 +7,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	i18nmsg "app.modules/core/i18n/typed"
 )
@@ -36,100 +37,27 @@ func ParseCommand(fullString string, isMember bool) (*CommandDetails, string) {
 
 	if strings.HasPrefix(fullString, CommandPrefix) || strings.HasPrefix(fullString, MemberCommandPrefix) {
 		slice := strings.Split(fullString, HalfWidthSpace)
-		switch slice[0] {
-		case MemberInCommand:
-			argStr := strings.TrimPrefix(fullString, MemberInCommand)
-			return ParseIn(argStr, true, false, 0)
-		case InCommand:
-			argStr := strings.TrimPrefix(fullString, InCommand)
-			return ParseIn(argStr, false, false, 0)
-		case MemberWorkCommand:
-			argStr := strings.TrimPrefix(fullString, MemberWorkCommand)
-			return ParseIn(argStr, true, false, 0)
-		case WorkCommand:
-			argStr := strings.TrimPrefix(fullString, WorkCommand)
-			return ParseIn(argStr, false, false, 0)
-		case OutCommand:
-			return &CommandDetails{
-				CommandType: Out,
-			}, ""
-		case InfoCommand:
-			argStr := strings.TrimPrefix(fullString, InfoCommand)
-			return ParseInfo(argStr)
-		case MyCommand:
-			argStr := strings.TrimPrefix(fullString, MyCommand)
-			return ParseMy(argStr)
-		case ChangeCommand:
-			argStr := strings.TrimPrefix(fullString, ChangeCommand)
-			return ParseChange(argStr)
-		case SeatCommand:
-			argStr := strings.TrimPrefix(fullString, SeatCommand)
-			return ParseSeat(argStr)
-		case ReportCommand:
-			// NOTE: !reportの場合は全文を送信する。
-			return ParseReport(fullString)
-		case KickCommand:
-			argStr := strings.TrimPrefix(fullString, KickCommand)
-			return ParseKick(argStr, false)
-		case MemberKickCommand:
-			argStr := strings.TrimPrefix(fullString, MemberKickCommand)
-			return ParseKick(argStr, true)
-		case CheckCommand:
-			argStr := strings.TrimPrefix(fullString, CheckCommand)
-			return ParseCheck(argStr, false)
-		case MemberCheckCommand:
-			argStr := strings.TrimPrefix(fullString, MemberCheckCommand)
-			return ParseCheck(argStr, true)
-		case BlockCommand:
-			argStr := strings.TrimPrefix(fullString, BlockCommand)
-			return ParseBlock(argStr, false)
-		case MemberBlockCommand:
-			argStr := strings.TrimPrefix(fullString, MemberBlockCommand)
-			return ParseBlock(argStr, true)
-		case OkawariCommand:
-			argStr := strings.TrimPrefix(fullString, OkawariCommand)
-			return ParseMore(argStr)
-		case MoreCommand:
-			argStr := strings.TrimPrefix(fullString, MoreCommand)
-			return ParseMore(argStr)
-		case RestCommand:
-			argStr := strings.TrimPrefix(fullString, RestCommand)
-			return ParseBreak(argStr)
-		case ChillCommand:
-			argStr := strings.TrimPrefix(fullString, ChillCommand)
-			return ParseBreak(argStr)
-		case BreakCommand:
-			argStr := strings.TrimPrefix(fullString, BreakCommand)
-			return ParseBreak(argStr)
-		case ResumeCommand:
-			argStr := strings.TrimPrefix(fullString, ResumeCommand)
-			return ParseResume(argStr)
-		case RankCommand:
-			return &CommandDetails{
-				CommandType: Rank,
-			}, ""
-		case OrderCommand:
-			argStr := strings.TrimPrefix(fullString, OrderCommand)
-			return ParseOrder(argStr)
-		case ClearCommand, ClearShortCommand:
-			return &CommandDetails{
-				CommandType: Clear,
-			}, ""
-		default: // !席番号 or 間違いコマンド
-			// "!席番号" or "/席番号" かも
-			if num, err := strconv.Atoi(strings.TrimPrefix(slice[0], CommandPrefix)); err == nil {
-				argStr := strings.TrimPrefix(fullString, slice[0])
-				return ParseSeatIn(num, argStr, false)
-			} else if num, err := strconv.Atoi(strings.TrimPrefix(slice[0], MemberCommandPrefix)); err == nil {
-				argStr := strings.TrimPrefix(fullString, slice[0])
-				return ParseSeatIn(num, argStr, true)
+		if entry, ok := commandNameIndex[slice[0]]; ok {
+			if entry.spec.MemberOnly && !isMember {
+				return nil, i18nmsg.ParseMemberOnlyCommand(entry.spec.Names[0])
 			}
+			argStr := strings.TrimPrefix(fullString, slice[0])
+			return entry.spec.parse(fullString, argStr, entry.isMemberSeat)
+		}
 
-			// 間違いコマンド
-			return &CommandDetails{
-				CommandType: InvalidCommand,
-			}, ""
+		// "!席番号" or "/席番号" かも
+		if num, err := strconv.Atoi(strings.TrimPrefix(slice[0], CommandPrefix)); err == nil {
+			argStr := strings.TrimPrefix(fullString, slice[0])
+			return ParseSeatIn(num, argStr, false)
+		} else if num, err := strconv.Atoi(strings.TrimPrefix(slice[0], MemberCommandPrefix)); err == nil {
+			argStr := strings.TrimPrefix(fullString, slice[0])
+			return ParseSeatIn(num, argStr, true)
 		}
+
+		// 間違いコマンド
+		return &CommandDetails{
+			CommandType: InvalidCommand,
+		}, ""
 	}
 	return &CommandDetails{
 		CommandType: NotCommand,
@@ -140,41 +68,13 @@ func ReplaceEmojiCommandToText(fullString string) (string, string) {
 	// コマンドの置換（オプション除く）
 	emojiStrings := emojiCommandRegex.FindAllString(fullString, -1)
 	for _, s := range emojiStrings {
+		if text, ok := matchCommandEmoji(s); ok {
+			fullString = strings.Replace(fullString, s, HalfWidthSpace+text+HalfWidthSpace, 1)
+			continue
+		}
+
+		// オプションの置換
 		switch true {
-		case MatchEmojiCommand(s, InZeroString):
-			fullString = strings.Replace(fullString, s, HalfWidthSpace+InZeroCommand+HalfWidthSpace, 1)
-		case MatchEmojiCommand(s, InString):
-			fullString = strings.Replace(fullString, s, HalfWidthSpace+InCommand+HalfWidthSpace, 1)
-		case MatchEmojiCommand(s, OutString):
-			fullString = strings.Replace(fullString, s, HalfWidthSpace+OutCommand+HalfWidthSpace, 1)
-		case MatchEmojiCommand(s, InfoString):
-			fullString = strings.Replace(fullString, s, HalfWidthSpace+InfoCommand+HalfWidthSpace, 1)
-		case MatchEmojiCommand(s, InfoDString):
-			fullString = strings.Replace(fullString, s, HalfWidthSpace+InfoDCommand+HalfWidthSpace, 1)
-		case MatchEmojiCommand(s, MyString):
-			fullString = strings.Replace(fullString, s, HalfWidthSpace+MyCommand+HalfWidthSpace, 1)
-		case MatchEmojiCommand(s, ChangeString):
-			fullString = strings.Replace(fullString, s, HalfWidthSpace+ChangeCommand+HalfWidthSpace, 1)
-		case MatchEmojiCommand(s, SeatString):
-			fullString = strings.Replace(fullString, s, HalfWidthSpace+SeatCommand+HalfWidthSpace, 1)
-		case MatchEmojiCommand(s, SeatDString):
-			fullString = strings.Replace(fullString, s, HalfWidthSpace+SeatDCommand+HalfWidthSpace, 1)
-		case MatchEmojiCommand(s, MoreString):
-			fullString = strings.Replace(fullString, s, HalfWidthSpace+MoreCommand+HalfWidthSpace, 1)
-		case MatchEmojiCommand(s, BreakString):
-			fullString = strings.Replace(fullString, s, HalfWidthSpace+BreakCommand+HalfWidthSpace, 1)
-		case MatchEmojiCommand(s, ResumeString):
-			fullString = strings.Replace(fullString, s, HalfWidthSpace+ResumeCommand+HalfWidthSpace, 1)
-		case MatchEmojiCommand(s, MemberInString):
-			fullString = strings.Replace(fullString, s, HalfWidthSpace+MemberInCommand+HalfWidthSpace, 1)
-		case MatchEmojiCommand(s, MemberInZeroString):
-			fullString = strings.Replace(fullString, s, HalfWidthSpace+MemberInZeroCommand+HalfWidthSpace, 1)
-		case MatchEmojiCommand(s, OrderString):
-			fullString = strings.Replace(fullString, s, HalfWidthSpace+OrderCommand+HalfWidthSpace, 1)
-		case MatchEmojiCommand(s, OrderClearString):
-			fullString = strings.Replace(fullString, s, HalfWidthSpace+OrderClearCommand+HalfWidthSpace, 1)
-		case MatchEmojiCommand(s, RankString):
-			fullString = strings.Replace(fullString, s, HalfWidthSpace+RankCommand+HalfWidthSpace, 1)
 		case MatchEmojiCommand(s, WorkString):
 			fullString = strings.Replace(fullString, s, HalfWidthSpace+WorkNameOptionKey+HalfWidthSpace, 1)
 		case MatchEmojiCommand(s, MinString):
@@ -195,6 +95,18 @@ func ReplaceEmojiCommandToText(fullString string) (string, string) {
 	return fullString, ""
 }
 
+// matchCommandEmoji は絵文字がいずれかのコマンドの絵文字であれば、置換後の文字列を返す。
+func matchCommandEmoji(emoji string) (string, bool) {
+	for _, spec := range commandSpecs {
+		for _, e := range spec.Emojis {
+			if MatchEmojiCommand(emoji, e.Name) {
+				return e.Text, true
+			}
+		}
+	}
+	return "", false
+}
+
 // FormatStringToParse はコマンド解析のために文字列を整形する
 func FormatStringToParse(fullString string) string {
 	// 全角スペースを半角に変換
@@ -219,6 +131,7 @@ func FormatStringToParse(fullString string) string {
 	fullString = strings.ReplaceAll(fullString, " work=", " work ")
 	fullString = strings.ReplaceAll(fullString, " min=", " min ")
 	fullString = strings.ReplaceAll(fullString, " order=", " order ")
+	fullString = strings.ReplaceAll(fullString, " pomo=", " pomo ")
 	fullString = strings.ReplaceAll(fullString, " w=", " w ")
 	fullString = strings.ReplaceAll(fullString, " m=", " m ")
 	fullString = strings.ReplaceAll(fullString, " o=", " o ")
@@ -230,6 +143,7 @@ func FormatStringToParse(fullString string) string {
 	fullString = strings.ReplaceAll(fullString, " o-", " order ")
 	fullString = strings.ReplaceAll(fullString, " rank=", " rank ")
 	fullString = strings.ReplaceAll(fullString, " color=", " color ")
+	fullString = strings.ReplaceAll(fullString, " goal=", " goal ")
 
 	// オプションの短縮系は非短縮に変換
 	fullString = strings.ReplaceAll(fullString, " w ", " work ")
@@ -312,6 +226,7 @@ func ParseMyOptions(argStr string) ([]MyOption, string) {
 		Rank = iota
 		Min
 		Color
+		Goal
 		Any
 	)
 	currentMode := Any
@@ -322,6 +237,8 @@ func ParseMyOptions(argStr string) ([]MyOption, string) {
 	var defaultStudyMinValue int
 	isFavoriteColorSet := false
 	var favoriteColorValue string
+	isDailyGoalMinSet := false
+	var dailyGoalMinValue int
 
 	for _, field := range fields {
 		switch currentMode {
@@ -355,6 +272,15 @@ func ParseMyOptions(argStr string) ([]MyOption, string) {
 			favoriteColorValue = field
 			currentMode = Any
 			continue
+		case Goal:
+			// 0ならリセットとする。
+			value, err := strconv.Atoi(field)
+			if err != nil {
+				return []MyOption{}, i18nmsg.ParseCheckOption(DailyGoalMyOptionPrefix)
+			}
+			dailyGoalMinValue = value
+			currentMode = Any
+			continue
 		default:
 			// pass
 		}
@@ -367,6 +293,9 @@ func ParseMyOptions(argStr string) ([]MyOption, string) {
 		} else if field == FavoriteColorMyOptionKey && !isFavoriteColorSet {
 			currentMode = Color
 			isFavoriteColorSet = true // 空白の場合も対応（リセット）するのでここでセット
+		} else if field == DailyGoalMyOptionKey && !isDailyGoalMinSet {
+			currentMode = Goal
+			isDailyGoalMinSet = true // 空白の場合も対応（リセット）するのでここでセット
 		}
 	}
 
@@ -390,6 +319,12 @@ func ParseMyOptions(argStr string) ([]MyOption, string) {
 			StringValue: favoriteColorValue,
 		})
 	}
+	if isDailyGoalMinSet {
+		options = append(options, MyOption{
+			Type:     DailyGoalMin,
+			IntValue: dailyGoalMinValue,
+		})
+	}
 
 	return options, ""
 }
@@ -463,6 +398,35 @@ func ParseBlock(argStr string, isTargetMemberSeat bool) (*CommandDetails, string
 	}, ""
 }
 
+func ParseTimeout(argStr string, isTargetMemberSeat bool) (*CommandDetails, string) {
+	fields := strings.Fields(argStr)
+
+	if len(fields) == 0 {
+		return nil, i18nmsg.ParseMissingSeatId()
+	}
+	targetSeatID, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return nil, i18nmsg.ParseInvalidSeatId()
+	}
+
+	if len(fields) == 1 {
+		return nil, i18nmsg.ParseMissingTimeoutDuration()
+	}
+	durationMin, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return nil, i18nmsg.ParseInvalidOption()
+	}
+
+	return &CommandDetails{
+		CommandType: Timeout,
+		TimeoutOption: TimeoutOption{
+			SeatID:             targetSeatID,
+			IsTargetMemberSeat: isTargetMemberSeat,
+			DurationMin:        durationMin,
+		},
+	}, ""
+}
+
 func ParseReport(fullString string) (*CommandDetails, string) {
 	fields := strings.Fields(fullString)
 
@@ -622,6 +586,102 @@ func ParseOrderOption(argStr string) (*OrderOption, string) {
 	}, ""
 }
 
+func ParseHistory(argStr string) (*CommandDetails, string) {
+	fields := strings.Fields(argStr)
+
+	sessionCount := DefaultHistorySessionCount
+	if len(fields) > 0 {
+		value, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return nil, i18nmsg.ParseInvalidOption()
+		}
+		sessionCount = value
+	}
+
+	return &CommandDetails{
+		CommandType: History,
+		HistoryOption: HistoryOption{
+			SessionCount: sessionCount,
+		},
+	}, ""
+}
+
+func ParseHelp(argStr string) (*CommandDetails, string) {
+	fields := strings.Fields(argStr)
+
+	topic := ""
+	if len(fields) > 0 {
+		topic = fields[0]
+	}
+
+	return &CommandDetails{
+		CommandType: Help,
+		HelpOption: HelpOption{
+			Topic: topic,
+		},
+	}, ""
+}
+
+// ParseReserve は「!reserve 席番号 HH:MM [min 分]」「!reserve cancel [席番号]」を解析する。
+func ParseReserve(argStr string, isTargetMemberSeat bool) (*CommandDetails, string) {
+	fields := strings.Fields(argStr)
+	if len(fields) == 0 {
+		return nil, i18nmsg.ParseMissingSeatId()
+	}
+	if fields[0] == ReserveCancelOption {
+		option := ReserveOption{
+			IsTargetMemberSeat: isTargetMemberSeat,
+			IsCancel:           true,
+		}
+		if len(fields) >= 2 {
+			seatID, err := strconv.Atoi(fields[1])
+			if err != nil {
+				return nil, i18nmsg.ParseInvalidSeatId()
+			}
+			option.SeatID = seatID
+		}
+		return &CommandDetails{
+			CommandType:   Reserve,
+			ReserveOption: option,
+		}, ""
+	}
+	seatID, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return nil, i18nmsg.ParseInvalidSeatId()
+	}
+	if len(fields) < 2 {
+		return nil, i18nmsg.ParseInvalidReserveTime()
+	}
+	startTime, err := time.Parse(ReserveTimeLayout, strings.ReplaceAll(fields[1], FullWidthColon, HalfWidthColon))
+	if err != nil {
+		return nil, i18nmsg.ParseInvalidReserveTime()
+	}
+
+	option := ReserveOption{
+		SeatID:             seatID,
+		IsTargetMemberSeat: isTargetMemberSeat,
+		Hour:               startTime.Hour(),
+		Minute:             startTime.Minute(),
+	}
+	rest := fields[2:]
+	if len(rest) > 0 {
+		if rest[0] != TimeOptionKey || len(rest) < 2 {
+			return nil, i18nmsg.ParseCheckOption(TimeOptionPrefix)
+		}
+		value, err := strconv.Atoi(rest[1])
+		if err != nil {
+			return nil, i18nmsg.ParseCheckOption(TimeOptionPrefix)
+		}
+		option.IsDurationMinSet = true
+		option.DurationMin = value
+	}
+
+	return &CommandDetails{
+		CommandType:   Reserve,
+		ReserveOption: option,
+	}, ""
+}
+
 func ParseWorkNameOption(argText string) WorkNameOption {
 	argText = strings.TrimSpace(argText)
 
@@ -652,6 +712,7 @@ func ParseMinWorkOrderOptions(argStr string) (*MinWorkOrderOption, string) {
 	const (
 		Min = iota
 		Order
+		Pomodoro
 		Any
 		Work
 	)
@@ -677,8 +738,25 @@ func ParseMinWorkOrderOptions(argStr string) (*MinWorkOrderOption, string) {
 			options.IsOrderSet = true
 			currentMode = Any
 			continue
+		case Pomodoro:
+			currentMode = Any
+			if field == PomodoroOffValue {
+				options.PomodoroWorkMin = 0
+				options.PomodoroBreakMin = 0
+				continue
+			}
+			if _, err := strconv.Atoi(field); err == nil || strings.Contains(field, PomodoroOptionSeparator) {
+				workMin, breakMin, ok := ParsePomodoroValue(field)
+				if !ok {
+					return nil, i18nmsg.ParseCheckOption(PomodoroOptionPrefix)
+				}
+				options.PomodoroWorkMin = workMin
+				options.PomodoroBreakMin = breakMin
+				continue
+			}
+			// 値の指定がなければデフォルトのサイクルとし、このフィールドは通常通り解析する
 		case Work:
-			if field == TimeOptionKey || field == OrderOptionKey {
+			if field == TimeOptionKey || field == OrderOptionKey || field == PomodoroOptionKey {
 				currentMode = Any
 			} else {
 				options.WorkName += field + HalfWidthSpace
@@ -692,6 +770,11 @@ func ParseMinWorkOrderOptions(argStr string) (*MinWorkOrderOption, string) {
 			currentMode = Min
 		} else if field == OrderOptionKey && !options.IsOrderSet {
 			currentMode = Order
+		} else if field == PomodoroOptionKey && !options.IsPomodoroSet {
+			currentMode = Pomodoro
+			options.IsPomodoroSet = true // 値の指定がない場合もあるので、ここでセット
+			options.PomodoroWorkMin = DefaultPomodoroWorkMin
+			options.PomodoroBreakMin = DefaultPomodoroBreakMin
 		} else if field == WorkNameOptionKey && !options.IsWorkNameSet {
 			currentMode = Work
 			options.IsWorkNameSet = true // リセット（空文字）の場合もあるので、ここでセット
@@ -707,6 +790,24 @@ func ParseMinWorkOrderOptions(argStr string) (*MinWorkOrderOption, string) {
 	return &options, ""
 }
 
+// ParsePomodoroValue は"25/5"のようなポモドーロの値を作業時間（分）と休憩時間（分）に分解する。
+// 形式が正しくない場合は ok == false を返す。
+func ParsePomodoroValue(value string) (workMin int, breakMin int, ok bool) {
+	workStr, breakStr, found := strings.Cut(value, PomodoroOptionSeparator)
+	if !found {
+		return 0, 0, false
+	}
+	workMin, err := strconv.Atoi(workStr)
+	if err != nil {
+		return 0, 0, false
+	}
+	breakMin, err = strconv.Atoi(breakStr)
+	if err != nil {
+		return 0, 0, false
+	}
+	return workMin, breakMin, true
+}
+
 // ReplaceEmojiMinToText は"min="や"min=360"の絵文字をテキストに変換する。
 func ReplaceEmojiMinToText(emojiString string) (string, error) {
 	tmp := strings.TrimPrefix(emojiString, EmojiCommandPrefix) // ex. "360Min0:"
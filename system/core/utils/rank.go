@@ -117,8 +117,11 @@ func CalcContinuousInactiveDays(lastActiveAt time.Time) (int, error) {
 
 // CalcContinuousActiveDays 連続アクティブn日目のとき、n-1を返す。
 func CalcContinuousActiveDays(yesterdayContinuedActive bool, currentStateStarted time.Time, lastActiveAt time.Time) (int, error) {
-	jstNow := timeutil.JstNow()
+	return CalcContinuousActiveDaysAt(yesterdayContinuedActive, currentStateStarted, lastActiveAt, timeutil.JstNow())
+}
 
+// CalcContinuousActiveDaysAt jstNow時点でCalcContinuousActiveDaysを計算する。
+func CalcContinuousActiveDaysAt(yesterdayContinuedActive bool, currentStateStarted time.Time, lastActiveAt time.Time, jstNow time.Time) (int, error) {
 	// 未来の日付がある場合はエラー
 	if currentStateStarted.After(jstNow) || lastActiveAt.After(jstNow) {
 		return 0, errors.New("currentStateStarted.After(jstNow) is true or lastActiveAt.After(jstNow) is true")
@@ -139,6 +142,42 @@ func CalcContinuousActiveDays(yesterdayContinuedActive bool, currentStateStarted
 	}
 }
 
+// StreakMilestoneDays 入室時にお祝いを告知する連続アクティブ日数。
+var StreakMilestoneDays = []int{7, 30, 100, 365}
+
+// CalcStreakDays 連続アクティブ日数（今日まだ入室していなければ昨日まで）を返す。連続が途切れていれば0。
+func CalcStreakDays(isContinuousActive bool, currentActivityStateStarted time.Time, lastActiveAt time.Time, jstNow time.Time) (int, error) {
+	if !isContinuousActive {
+		return 0, nil
+	}
+	if !timeutil.DateEqualJST(lastActiveAt, jstNow) && !timeutil.DateEqualJST(lastActiveAt, jstNow.AddDate(0, 0, -1)) {
+		return 0, nil
+	}
+	days, err := CalcContinuousActiveDaysAt(true, currentActivityStateStarted, lastActiveAt, jstNow)
+	if err != nil {
+		return 0, fmt.Errorf("in CalcContinuousActiveDaysAt: %w", err)
+	}
+	return days + 1, nil
+}
+
+// CalcStreakDaysAfterEntry その日最初の入室をした後の連続アクティブ日数を返す。
+// 引数は入室前のユーザーの値。
+func CalcStreakDaysAfterEntry(isContinuousActive bool, currentActivityStateStarted time.Time, lastEntered, lastExited, jstNow time.Time) (int, error) {
+	previousStreakDays, err := CalcStreakDays(isContinuousActive, currentActivityStateStarted, LastActiveAt(lastEntered, lastExited, jstNow), jstNow)
+	if err != nil {
+		return 0, fmt.Errorf("in CalcStreakDays: %w", err)
+	}
+	if previousStreakDays == 0 { // 久しぶりの入室なので今日から1日目
+		return 1, nil
+	}
+	return CalcStreakDays(true, currentActivityStateStarted, jstNow, jstNow)
+}
+
+// IsStreakMilestone 連続アクティブ日数がお祝いを告知する日数かどうか。
+func IsStreakMilestone(streakDays int) bool {
+	return Contains(StreakMilestoneDays, streakDays)
+}
+
 func ApplyRPRange(rp int) int {
 	if rp < RankPointLowerLimit {
 		return RankPointLowerLimit
@@ -789,3 +789,72 @@ func TestCalcNewRPContinuousInactivity(t *testing.T) {
 		})
 	}
 }
+
+func TestCalcStreakDays(t *testing.T) {
+	jstNow := time.Date(2026, time.January, 10, 10, 0, 0, 0, timeutil.JapanLocation())
+
+	tests := []struct {
+		name                        string
+		isContinuousActive          bool
+		currentActivityStateStarted time.Time
+		lastActiveAt                time.Time
+		expectedDays                int
+	}{
+		{
+			name:                        "今日入室済みで3日連続",
+			isContinuousActive:          true,
+			currentActivityStateStarted: jstNow.AddDate(0, 0, -2),
+			lastActiveAt:                jstNow,
+			expectedDays:                3,
+		},
+		{
+			name:                        "今日は未入室で昨日まで2日連続",
+			isContinuousActive:          true,
+			currentActivityStateStarted: jstNow.AddDate(0, 0, -2),
+			lastActiveAt:                jstNow.AddDate(0, 0, -1),
+			expectedDays:                2,
+		},
+		{
+			name:                        "非アクティブ",
+			isContinuousActive:          false,
+			currentActivityStateStarted: jstNow.AddDate(0, 0, -5),
+			lastActiveAt:                jstNow.AddDate(0, 0, -5),
+			expectedDays:                0,
+		},
+		{
+			name:                        "最終アクティブが一昨日以前なら途切れている",
+			isContinuousActive:          true,
+			currentActivityStateStarted: jstNow.AddDate(0, 0, -5),
+			lastActiveAt:                jstNow.AddDate(0, 0, -2),
+			expectedDays:                0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			days, err := CalcStreakDays(tt.isContinuousActive, tt.currentActivityStateStarted, tt.lastActiveAt, jstNow)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedDays, days)
+		})
+	}
+}
+
+func TestCalcStreakDaysAfterEntry(t *testing.T) {
+	jstNow := time.Date(2026, time.January, 10, 10, 0, 0, 0, timeutil.JapanLocation())
+
+	t.Run("昨日まで6日連続なら7日目", func(t *testing.T) {
+		lastExited := jstNow.AddDate(0, 0, -1)
+		days, err := CalcStreakDaysAfterEntry(true, jstNow.AddDate(0, 0, -6), lastExited.Add(-time.Hour), lastExited, jstNow)
+		assert.NoError(t, err)
+		assert.Equal(t, 7, days)
+		assert.True(t, IsStreakMilestone(days))
+	})
+
+	t.Run("久しぶりの入室なら1日目", func(t *testing.T) {
+		lastExited := jstNow.AddDate(0, 0, -3)
+		days, err := CalcStreakDaysAfterEntry(false, lastExited, lastExited.Add(-time.Hour), lastExited, jstNow)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, days)
+		assert.False(t, IsStreakMilestone(days))
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"reflect"
 	"strconv"
+	"time"
 
 	"app.modules/core/repository"
 	"app.modules/core/timeutil"
@@ -55,6 +56,10 @@ const (
 	ColorRank9         = "#BF80DF"
 	ColorRank10        = "#FF66FF"
 	ColorRank10andMore = "#FF5252"
+
+	// 支援（スーパーチャット・メンバーシップなど）の特典として、その日のみ使える座席の色
+	ColorSupporter1 = "#FFD700"
+	ColorSupporter2 = "#FF8C00"
 )
 
 func GetSeatAppearance(totalStudySec int, rankVisible bool, rp int, favoriteColor string) (repository.SeatAppearance, error) {
@@ -82,6 +87,18 @@ func GetSeatAppearance(totalStudySec int, rankVisible bool, rp int, favoriteColo
 	}, nil
 }
 
+// ApplySupporterAppearance supporterAppearanceUntilがnowより後であれば、支援特典の座席の色にして返す。
+// 星の数は変えない。
+func ApplySupporterAppearance(appearance repository.SeatAppearance, supporterAppearanceUntil time.Time, now time.Time) repository.SeatAppearance {
+	if !now.Before(supporterAppearanceUntil) {
+		return appearance
+	}
+	appearance.ColorCode1 = ColorSupporter1
+	appearance.ColorCode2 = ColorSupporter2
+	appearance.ColorGradientEnabled = true
+	return appearance
+}
+
 func CanUseFavoriteColor(totalStudySec int) bool {
 	hours := timeutil.SecondsToHours(totalStudySec)
 	return hours >= FavoriteColorAvailableThresholdHours
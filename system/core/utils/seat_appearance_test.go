@@ -2,6 +2,7 @@ package utils
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -88,6 +89,47 @@ func TestGetSeatAppearance(t *testing.T) {
 	}
 }
 
+func TestApplySupporterAppearance(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	base := repository.SeatAppearance{
+		ColorCode1: ColorHours0To5,
+		NumStars:   2,
+	}
+	tests := []struct {
+		name     string
+		until    time.Time
+		expected repository.SeatAppearance
+	}{
+		{
+			name:  "Before until",
+			until: now.Add(time.Hour),
+			expected: repository.SeatAppearance{
+				ColorCode1:           ColorSupporter1,
+				ColorCode2:           ColorSupporter2,
+				NumStars:             2,
+				ColorGradientEnabled: true,
+			},
+		},
+		{
+			name:     "At until",
+			until:    now,
+			expected: base,
+		},
+		{
+			name:     "Zero until",
+			until:    time.Time{},
+			expected: base,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ApplySupporterAppearance(base, tt.until, now)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
 func TestCanUseFavoriteColor(t *testing.T) {
 	tests := []struct {
 		name          string
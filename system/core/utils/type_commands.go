@@ -1,20 +1,24 @@
 package utils
 
 type CommandDetails struct {
-	CommandType  CommandType
-	InOption     InOption
-	InfoOption   InfoOption
-	MyOptions    []MyOption
-	SeatOption   SeatOption
-	KickOption   KickOption
-	CheckOption  CheckOption
-	BlockOption  BlockOption
-	ReportOption ReportOption
-	ChangeOption MinWorkOrderOption
-	MoreOption   MoreOption
-	BreakOption  MinWorkOrderOption // NOTE: !breakではorderオプションもパースはするが注文処理はされない
-	ResumeOption WorkNameOption
-	OrderOption  OrderOption
+	CommandType   CommandType
+	InOption      InOption
+	InfoOption    InfoOption
+	MyOptions     []MyOption
+	SeatOption    SeatOption
+	KickOption    KickOption
+	CheckOption   CheckOption
+	BlockOption   BlockOption
+	TimeoutOption TimeoutOption
+	ReportOption  ReportOption
+	ChangeOption  MinWorkOrderOption
+	MoreOption    MoreOption
+	BreakOption   MinWorkOrderOption // NOTE: !breakではorderオプションもパースはするが注文処理はされない
+	ResumeOption  WorkNameOption
+	OrderOption   OrderOption
+	HistoryOption HistoryOption
+	HelpOption    HelpOption
+	ReserveOption ReserveOption
 }
 
 type CommandType uint
@@ -38,6 +42,13 @@ const (
 	Resume // !resume
 	Order
 	Clear
+	History // !history
+	Help    // !help
+	Undo    // !undo
+	Reserve // !reserve or /reserve
+	Streak  // !streak
+	Quota   // !quota
+	Timeout // !timeout
 )
 
 type InfoOption struct {
@@ -50,6 +61,7 @@ const (
 	RankVisible MyOptionType = iota
 	DefaultStudyMin
 	FavoriteColor
+	DailyGoalMin
 )
 
 type InOption struct {
@@ -85,6 +97,12 @@ type BlockOption struct {
 	IsTargetMemberSeat bool
 }
 
+type TimeoutOption struct {
+	SeatID             int
+	IsTargetMemberSeat bool
+	DurationMin        int
+}
+
 type ReportOption struct {
 	Message string
 }
@@ -103,9 +121,36 @@ type MinWorkOrderOption struct {
 	IsWorkNameSet    bool
 	IsDurationMinSet bool
 	IsOrderSet       bool
+	IsPomodoroSet    bool
 	WorkName         string
 	DurationMin      int
 	OrderNum         int
+	PomodoroWorkMin  int // NOTE: ポモドーロの作業時間（分）。IsPomodoroSetのときのみ有効。解除する場合は0
+	PomodoroBreakMin int // NOTE: ポモドーロの休憩時間（分）。IsPomodoroSetのときのみ有効。解除する場合は0
+}
+
+// IsPomodoroOff ポモドーロの解除が指定されたか
+func (o *MinWorkOrderOption) IsPomodoroOff() bool {
+	return o.IsPomodoroSet && o.PomodoroWorkMin == 0 && o.PomodoroBreakMin == 0
+}
+
+type HistoryOption struct {
+	SessionCount int
+}
+
+type HelpOption struct {
+	Topic string // NOTE: 空なら一覧を表示する
+}
+
+// ReserveOption は!reserveの指定。日付は実行時に決める（指定時刻を過ぎていれば翌日）。
+type ReserveOption struct {
+	SeatID             int
+	IsTargetMemberSeat bool
+	Hour               int
+	Minute             int
+	IsDurationMinSet   bool
+	DurationMin        int
+	IsCancel           bool // 予約の取り消し。SeatIDが0なら自分の予約をすべて取り消す
 }
 
 type OrderOption struct {
@@ -114,7 +159,7 @@ type OrderOption struct {
 }
 
 func (o *MinWorkOrderOption) NumOptionsSet() int {
-	return NumTrue(o.IsWorkNameSet, o.IsDurationMinSet)
+	return NumTrue(o.IsWorkNameSet, o.IsDurationMinSet, o.IsPomodoroSet)
 }
 
 type UserIDTotalStudySecSet struct {
@@ -125,6 +125,14 @@ func RealTimeDailyTotalStudyDurationOfSeat(seat repository.SeatDoc, now time.Tim
 	return duration, nil
 }
 
+// DailyGoalProgressPercent は1日の目標作業時間に対する達成率（%、四捨五入）を返す。100%を超える場合もそのまま返す。
+func DailyGoalProgressPercent(dailyStudyMin int, dailyGoalMin int) int {
+	if dailyGoalMin <= 0 {
+		return 0
+	}
+	return (dailyStudyMin*100 + dailyGoalMin/2) / dailyGoalMin
+}
+
 func SortUserActivityByTakenAtAscending(docs []repository.UserActivityDoc) {
 	sort.Slice(docs, func(i, j int) bool { return docs[i].TakenAt.Before(docs[j].TakenAt) })
 }
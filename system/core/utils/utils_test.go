@@ -253,6 +253,46 @@ func TestContains(t *testing.T) {
 	}
 }
 
+func TestDailyGoalProgressPercent(t *testing.T) {
+	tests := []struct {
+		name          string
+		dailyStudyMin int
+		dailyGoalMin  int
+		expected      int
+	}{
+		{
+			name:          "途中",
+			dailyStudyMin: 95,
+			dailyGoalMin:  180,
+			expected:      53,
+		},
+		{
+			name:          "達成",
+			dailyStudyMin: 180,
+			dailyGoalMin:  180,
+			expected:      100,
+		},
+		{
+			name:          "超過",
+			dailyStudyMin: 270,
+			dailyGoalMin:  180,
+			expected:      150,
+		},
+		{
+			name:          "目標なし",
+			dailyStudyMin: 95,
+			dailyGoalMin:  0,
+			expected:      0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, DailyGoalProgressPercent(tt.dailyStudyMin, tt.dailyGoalMin))
+		})
+	}
+}
+
 func TestGenerateSessionID(t *testing.T) {
 	hexRegex := regexp.MustCompile("^[0-9a-f]{32}$")
 
@@ -0,0 +1,153 @@
+package utils
+
+import (
+	"sort"
+	"time"
+
+	"app.modules/core/repository"
+	"app.modules/core/timeutil"
+)
+
+// WorkSessionSummary は1回の入室〜退室（セッション）の集計結果。
+type WorkSessionSummary struct {
+	SessionID     string
+	SeatID        int
+	IsMemberSeat  bool
+	StartedAt     time.Time
+	WorkDuration  time.Duration
+	BreakDuration time.Duration
+	// 作業内容ごとの作業時間。作業内容が最初に現れた順に並ぶ。
+	WorkNameDurations []WorkNameDuration
+}
+
+type WorkNameDuration struct {
+	WorkName string
+	Duration time.Duration
+}
+
+// SummarizeWorkSessions はセグメントをセッションごとに集計し、開始時刻が新しい順に最大maxSessions件を返す。
+func SummarizeWorkSessions(segments []repository.WorkSegmentDoc, maxSessions int) []WorkSessionSummary {
+	// セグメントを開始時刻の昇順に並べてから集計することで、作業内容の並びを時系列順にする
+	sorted := make([]repository.WorkSegmentDoc, len(segments))
+	copy(sorted, segments)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].StartedAt.Before(sorted[j].StartedAt)
+	})
+
+	summaryIndexBySessionID := make(map[string]int)
+	var summaries []WorkSessionSummary
+	for _, segment := range sorted {
+		index, ok := summaryIndexBySessionID[segment.SessionID]
+		if !ok {
+			summaries = append(summaries, WorkSessionSummary{
+				SessionID:    segment.SessionID,
+				SeatID:       segment.SeatID,
+				IsMemberSeat: segment.IsMemberSeat,
+				StartedAt:    segment.StartedAt,
+			})
+			index = len(summaries) - 1
+			summaryIndexBySessionID[segment.SessionID] = index
+		}
+		summary := &summaries[index]
+
+		duration := time.Duration(segment.DurationSec) * time.Second
+		switch segment.SegmentType {
+		case repository.WorkState:
+			summary.WorkDuration += duration
+			summary.addWorkNameDuration(segment.WorkName, duration)
+		case repository.BreakState:
+			summary.BreakDuration += duration
+		}
+	}
+
+	sort.SliceStable(summaries, func(i, j int) bool {
+		return summaries[i].StartedAt.After(summaries[j].StartedAt)
+	})
+	if len(summaries) > maxSessions {
+		summaries = summaries[:maxSessions]
+	}
+	return summaries
+}
+
+func (s *WorkSessionSummary) addWorkNameDuration(workName string, duration time.Duration) {
+	for i := range s.WorkNameDurations {
+		if s.WorkNameDurations[i].WorkName == workName {
+			s.WorkNameDurations[i].Duration += duration
+			return
+		}
+	}
+	s.WorkNameDurations = append(s.WorkNameDurations, WorkNameDuration{
+		WorkName: workName,
+		Duration: duration,
+	})
+}
+
+// DailyWorkBreakSec は1日（JST）あたりの作業時間・休憩時間。
+type DailyWorkBreakSec struct {
+	Date     time.Time // JSTの0時
+	WorkSec  int
+	BreakSec int
+}
+
+// SessionDailyWorkBreakSecs は入室〜退室までの1セッションを、JSTの日付ごとの作業時間・休憩時間に振り分ける。
+// 作業セグメント以外は無視し、セッション中の作業以外の時間を休憩時間とみなす。
+func SessionDailyWorkBreakSecs(enteredAt, exitedAt time.Time, segments []repository.WorkSegmentDoc) []DailyWorkBreakSec {
+	sessionStartedAt := enteredAt
+	for _, segment := range segments {
+		if sessionStartedAt.IsZero() || segment.StartedAt.Before(sessionStartedAt) {
+			sessionStartedAt = segment.StartedAt
+		}
+	}
+	if sessionStartedAt.IsZero() || !sessionStartedAt.Before(exitedAt) {
+		return nil
+	}
+
+	var result []DailyWorkBreakSec
+	for day := timeutil.StartOfDayJST(sessionStartedAt); day.Before(exitedAt); day = day.AddDate(0, 0, 1) {
+		workSec := 0
+		for _, segment := range segments {
+			if segment.SegmentType == repository.WorkState {
+				workSec += timeutil.OverlapSecondsInJSTDay(segment.StartedAt, segment.EndedAt, day)
+			}
+		}
+		sessionSec := timeutil.OverlapSecondsInJSTDay(sessionStartedAt, exitedAt, day)
+		breakSec := sessionSec - workSec
+		if breakSec < 0 {
+			breakSec = 0
+		}
+		if workSec == 0 && breakSec == 0 {
+			continue
+		}
+		result = append(result, DailyWorkBreakSec{
+			Date:     day,
+			WorkSec:  workSec,
+			BreakSec: breakSec,
+		})
+	}
+	return result
+}
+
+// DailyWorkBreakSecOfSegments は退室済みのセッションの作業セグメントから、dayAnchorを含むJSTの日付の作業時間・休憩時間を集計する。
+// 休憩時間は退室時と同じく SessionDailyWorkBreakSecs で求める（セッションの最初のセグメントの開始から最後のセグメントの終了までのうち、作業以外の時間）。
+func DailyWorkBreakSecOfSegments(segments []repository.WorkSegmentDoc, dayAnchor time.Time) DailyWorkBreakSec {
+	day := timeutil.StartOfDayJST(dayAnchor)
+	sessions := make(map[string][]repository.WorkSegmentDoc)
+	exitedAts := make(map[string]time.Time)
+	for _, segment := range segments {
+		sessions[segment.SessionID] = append(sessions[segment.SessionID], segment)
+		if segment.EndedAt.After(exitedAts[segment.SessionID]) {
+			exitedAts[segment.SessionID] = segment.EndedAt
+		}
+	}
+
+	result := DailyWorkBreakSec{Date: day}
+	for sessionID, sessionSegments := range sessions {
+		for _, daily := range SessionDailyWorkBreakSecs(time.Time{}, exitedAts[sessionID], sessionSegments) {
+			if daily.Date.Equal(day) {
+				result.WorkSec += daily.WorkSec
+				result.BreakSec += daily.BreakSec
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,168 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"app.modules/core/repository"
+	"app.modules/core/timeutil"
+)
+
+func TestSummarizeWorkSessions(t *testing.T) {
+	jst := timeutil.JapanLocation()
+	olderStartedAt := time.Date(2026, 8, 1, 9, 0, 0, 0, jst)
+	newerStartedAt := time.Date(2026, 8, 2, 20, 0, 0, 0, jst)
+
+	// NOTE: リポジトリからは新しい順に返るので、入力も新しい順にしておく
+	segments := []repository.WorkSegmentDoc{
+		{
+			SessionID:   "newer",
+			SeatID:      3,
+			WorkName:    "数学",
+			SegmentType: repository.WorkState,
+			StartedAt:   newerStartedAt.Add(40 * time.Minute),
+			DurationSec: 20 * 60,
+		},
+		{
+			SessionID:   "newer",
+			SeatID:      3,
+			WorkName:    "コーヒー",
+			SegmentType: repository.BreakState,
+			StartedAt:   newerStartedAt.Add(30 * time.Minute),
+			DurationSec: 10 * 60,
+		},
+		{
+			SessionID:   "newer",
+			SeatID:      3,
+			WorkName:    "英語",
+			SegmentType: repository.WorkState,
+			StartedAt:   newerStartedAt,
+			DurationSec: 30 * 60,
+		},
+		{
+			SessionID:    "older",
+			SeatID:       5,
+			IsMemberSeat: true,
+			WorkName:     "",
+			SegmentType:  repository.WorkState,
+			StartedAt:    olderStartedAt,
+			DurationSec:  60 * 60,
+		},
+	}
+
+	t.Run("新しいセッション順に作業内容ごとに集計する", func(t *testing.T) {
+		got := SummarizeWorkSessions(segments, 5)
+		assert.Equal(t, []WorkSessionSummary{
+			{
+				SessionID:     "newer",
+				SeatID:        3,
+				IsMemberSeat:  false,
+				StartedAt:     newerStartedAt,
+				WorkDuration:  50 * time.Minute,
+				BreakDuration: 10 * time.Minute,
+				WorkNameDurations: []WorkNameDuration{
+					{WorkName: "英語", Duration: 30 * time.Minute},
+					{WorkName: "数学", Duration: 20 * time.Minute},
+				},
+			},
+			{
+				SessionID:    "older",
+				SeatID:       5,
+				IsMemberSeat: true,
+				StartedAt:    olderStartedAt,
+				WorkDuration: time.Hour,
+				WorkNameDurations: []WorkNameDuration{
+					{WorkName: "", Duration: time.Hour},
+				},
+			},
+		}, got)
+	})
+
+	t.Run("件数を制限する", func(t *testing.T) {
+		got := SummarizeWorkSessions(segments, 1)
+		assert.Len(t, got, 1)
+		assert.Equal(t, "newer", got[0].SessionID)
+	})
+
+	t.Run("同じ作業内容は合算する", func(t *testing.T) {
+		got := SummarizeWorkSessions([]repository.WorkSegmentDoc{
+			{SessionID: "s", WorkName: "読書", SegmentType: repository.WorkState, StartedAt: newerStartedAt, DurationSec: 600},
+			{SessionID: "s", WorkName: "読書", SegmentType: repository.WorkState, StartedAt: newerStartedAt.Add(time.Hour), DurationSec: 900},
+		}, 1)
+		assert.Equal(t, []WorkNameDuration{{WorkName: "読書", Duration: 25 * time.Minute}}, got[0].WorkNameDurations)
+	})
+
+	t.Run("セグメントがない", func(t *testing.T) {
+		assert.Empty(t, SummarizeWorkSessions(nil, 3))
+	})
+}
+
+func TestSessionDailyWorkBreakSecs(t *testing.T) {
+	jst := timeutil.JapanLocation()
+
+	t.Run("同日内のセッション", func(t *testing.T) {
+		enteredAt := time.Date(2026, 8, 1, 9, 0, 0, 0, jst)
+		exitedAt := enteredAt.Add(2 * time.Hour)
+		segments := []repository.WorkSegmentDoc{
+			{SegmentType: repository.WorkState, StartedAt: enteredAt, EndedAt: enteredAt.Add(time.Hour)},
+			{SegmentType: repository.BreakState, StartedAt: enteredAt.Add(time.Hour), EndedAt: enteredAt.Add(90 * time.Minute)},
+			{SegmentType: repository.WorkState, StartedAt: enteredAt.Add(90 * time.Minute), EndedAt: exitedAt},
+		}
+		got := SessionDailyWorkBreakSecs(enteredAt, exitedAt, segments)
+		assert.Equal(t, []DailyWorkBreakSec{
+			{Date: time.Date(2026, 8, 1, 0, 0, 0, 0, jst), WorkSec: 90 * 60, BreakSec: 30 * 60},
+		}, got)
+	})
+
+	t.Run("日付を跨いだセッションは日ごとに振り分ける", func(t *testing.T) {
+		enteredAt := time.Date(2026, 8, 1, 23, 0, 0, 0, jst)
+		exitedAt := time.Date(2026, 8, 2, 1, 0, 0, 0, jst)
+		// 23:00-23:30 作業、23:30-0:30 休憩、0:30-1:00 作業（休憩セグメントは渡されなくてもよい）
+		segments := []repository.WorkSegmentDoc{
+			{SegmentType: repository.WorkState, StartedAt: enteredAt, EndedAt: enteredAt.Add(30 * time.Minute)},
+			{SegmentType: repository.WorkState, StartedAt: exitedAt.Add(-30 * time.Minute), EndedAt: exitedAt},
+		}
+		got := SessionDailyWorkBreakSecs(enteredAt, exitedAt, segments)
+		assert.Equal(t, []DailyWorkBreakSec{
+			{Date: time.Date(2026, 8, 1, 0, 0, 0, 0, jst), WorkSec: 30 * 60, BreakSec: 30 * 60},
+			{Date: time.Date(2026, 8, 2, 0, 0, 0, 0, jst), WorkSec: 30 * 60, BreakSec: 30 * 60},
+		}, got)
+	})
+
+	t.Run("入室時刻が未設定ならセグメントの開始時刻を使う", func(t *testing.T) {
+		startedAt := time.Date(2026, 8, 1, 9, 0, 0, 0, jst)
+		segments := []repository.WorkSegmentDoc{
+			{SegmentType: repository.WorkState, StartedAt: startedAt, EndedAt: startedAt.Add(time.Hour)},
+		}
+		got := SessionDailyWorkBreakSecs(time.Time{}, startedAt.Add(time.Hour), segments)
+		assert.Equal(t, []DailyWorkBreakSec{
+			{Date: time.Date(2026, 8, 1, 0, 0, 0, 0, jst), WorkSec: 60 * 60, BreakSec: 0},
+		}, got)
+	})
+
+	t.Run("入室時刻もセグメントもない", func(t *testing.T) {
+		assert.Empty(t, SessionDailyWorkBreakSecs(time.Time{}, time.Date(2026, 8, 1, 9, 0, 0, 0, jst), nil))
+	})
+}
+
+func TestDailyWorkBreakSecOfSegments(t *testing.T) {
+	jst := timeutil.JapanLocation()
+	day := time.Date(2026, 8, 2, 0, 0, 0, 0, jst)
+	segments := []repository.WorkSegmentDoc{
+		// 前日から跨いだセッションは当日分のみ
+		{SessionID: "a", SegmentType: repository.WorkState, StartedAt: day.Add(-time.Hour), EndedAt: day.Add(time.Hour)},
+		{SessionID: "a", SegmentType: repository.BreakState, StartedAt: day.Add(time.Hour), EndedAt: day.Add(80 * time.Minute)},
+		// 休憩セグメントがなくても、作業の間の時間は休憩時間になる（退室時と同じ定義）
+		{SessionID: "b", SegmentType: repository.WorkState, StartedAt: day.Add(10 * time.Hour), EndedAt: day.Add(11 * time.Hour)},
+		{SessionID: "b", SegmentType: repository.WorkState, StartedAt: day.Add(11*time.Hour + 10*time.Minute), EndedAt: day.Add(12 * time.Hour)},
+		// 前日のみのセッションは含めない
+		{SessionID: "c", SegmentType: repository.WorkState, StartedAt: day.Add(-3 * time.Hour), EndedAt: day.Add(-2 * time.Hour)},
+	}
+
+	got := DailyWorkBreakSecOfSegments(segments, day.Add(12*time.Hour))
+	assert.Equal(t, DailyWorkBreakSec{Date: day, WorkSec: 60*60 + 110*60, BreakSec: 20*60 + 10*60}, got)
+
+	assert.Equal(t, DailyWorkBreakSec{Date: day}, DailyWorkBreakSecOfSegments(nil, day))
+}
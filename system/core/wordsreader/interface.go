@@ -3,6 +3,7 @@ package wordsreader
 import "context"
 
 type WordsReader interface {
-	ReadBlockRegexes(ctx context.Context) (chatRegexes []string, channelRegexes []string, err error)
+	// ReadBlockRegexes timeoutMinByRegexは一致したときにタイムアウトにする正規表現とその時間（分）。含まれない正規表現は無期限のブロック
+	ReadBlockRegexes(ctx context.Context) (chatRegexes []string, channelRegexes []string, timeoutMinByRegex map[string]int, err error)
 	ReadNotificationRegexes(ctx context.Context) (chatRegexes []string, channelRegexes []string, err error)
 }
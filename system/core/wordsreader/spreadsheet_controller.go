@@ -60,13 +60,15 @@ func NewSpreadsheetReader(
 	}, nil
 }
 
-func (sc *SpreadsheetReader) ReadBlockRegexes(ctx context.Context) (chatRegexes []string, channelRegexes []string, err error) {
-	readRange := fmt.Sprintf("%s!A2:C999", sc.blockRegexSheetName) // 「有効, 文字列, チャンネル名にも適用」2行目スタート。999行目まで。
+func (sc *SpreadsheetReader) ReadBlockRegexes(ctx context.Context) (chatRegexes []string, channelRegexes []string, timeoutMinByRegex map[string]int, err error) {
+	readRange := fmt.Sprintf("%s!A2:D999", sc.blockRegexSheetName) // 「有効, 文字列, チャンネル名にも適用, タイムアウト（分）」2行目スタート。999行目まで。
 	resp, err := sc.client.Spreadsheets.Values.Get(sc.spreadsheetID, readRange).Context(ctx).Do()
 	if err != nil {
-		return nil, nil, fmt.Errorf("in sc.client.Spreadsheets.Values.Get: %w", err)
+		return nil, nil, nil, fmt.Errorf("in sc.client.Spreadsheets.Values.Get: %w", err)
 	}
 
+	timeoutMinByRegex = make(map[string]int)
+
 	for _, row := range resp.Values {
 		if len(row) < 3 {
 			continue
@@ -93,13 +95,32 @@ func (sc *SpreadsheetReader) ReadBlockRegexes(ctx context.Context) (chatRegexes
 			continue
 		}
 
+		chatRegexes = append(chatRegexes, regex)
+		// タイムアウト（分）は任意。空欄なら無期限のブロック
+		timeoutMin := 0
+		if len(row) >= 4 {
+			timeoutMinStr, ok := row[3].(string)
+			if !ok {
+				continue
+			}
+			if timeoutMinStr != "" {
+				timeoutMin, err = strconv.Atoi(timeoutMinStr)
+				if err != nil || timeoutMin < 0 {
+					continue
+				}
+			}
+		}
+
 		chatRegexes = append(chatRegexes, regex)
 		if applyForChannelName {
 			channelRegexes = append(channelRegexes, regex)
 		}
+		if timeoutMin > 0 {
+			timeoutMinByRegex[regex] = timeoutMin
+		}
 	}
 
-	return
+	return chatRegexes, channelRegexes, timeoutMinByRegex, nil
 }
 
 func (sc *SpreadsheetReader) ReadNotificationRegexes(ctx context.Context) (chatRegexes []string, channelRegexes []string, err error) {
@@ -9,7 +9,6 @@ import (
 	"strconv"
 	"time"
 
-	"cloud.google.com/go/firestore"
 	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
 	"google.golang.org/grpc/codes"
@@ -19,6 +18,7 @@ import (
 	"app.modules/core/mybigquery"
 	"app.modules/core/mystorage"
 	"app.modules/core/repository"
+	"app.modules/core/studyspaceerror"
 	"app.modules/core/timeutil"
 	"app.modules/core/utils"
 	"app.modules/core/workspaceapp/presenter"
@@ -27,6 +27,10 @@ import (
 // OrganizeDB 1分ごとに処理を行う。
 // - 自動退室予定時刻(until)を過ぎているルーム内のユーザーを退室させる。
 // - CurrentStateUntilを過ぎている休憩中のユーザーを作業再開させる。
+// - ポモドーロの作業時間を過ぎているユーザーを休憩させる。
+// - 1日の目標作業時間を達成したユーザーをお祝いする。
+// - 予約の開始時刻を過ぎても予約者以外が座っている席から、そのユーザーを別の席へ移動させる。
+// - 開始時刻から猶予時間が過ぎた座席予約を削除する。
 // - 一時着席制限ブラックリスト・ホワイトリストのuntilを過ぎているドキュメントを削除する。
 func (app *WorkspaceApp) OrganizeDB(ctx context.Context, isMemberRoom bool) error {
 	slog.Info(utils.NameOf(app.OrganizeDB), "isMemberRoom", isMemberRoom)
@@ -42,6 +46,26 @@ func (app *WorkspaceApp) OrganizeDB(ctx context.Context, isMemberRoom bool) erro
 		return fmt.Errorf("in OrganizeDBResume(): %w", err)
 	}
 
+	slog.Info("ポモドーロ休憩")
+	if err := app.OrganizeDBPomodoroBreak(ctx, isMemberRoom); err != nil {
+		return fmt.Errorf("in OrganizeDBPomodoroBreak(): %w", err)
+	}
+
+	slog.Info("目標作業時間の達成チェック")
+	if err := app.OrganizeDBDailyGoal(ctx, isMemberRoom); err != nil {
+		return fmt.Errorf("in OrganizeDBDailyGoal(): %w", err)
+	}
+
+	slog.Info("予約時間になった席の明け渡し")
+	if err := app.OrganizeDBMoveReservedSeatOccupants(ctx, isMemberRoom); err != nil {
+		return fmt.Errorf("in OrganizeDBMoveReservedSeatOccupants(): %w", err)
+	}
+
+	slog.Info("座席予約の期限切れ")
+	if err := app.OrganizeDBExpireSeatReservations(ctx, isMemberRoom); err != nil {
+		return fmt.Errorf("in OrganizeDBExpireSeatReservations(): %w", err)
+	}
+
 	slog.Info("一時着席制限ブラックリスト・ホワイトリストのクリーニング")
 	if err := app.OrganizeDBDeleteExpiredSeatLimits(ctx, isMemberRoom); err != nil {
 		return fmt.Errorf("in OrganizeDBDeleteExpiredSeatLimits(): %w", err)
@@ -59,7 +83,7 @@ func (app *WorkspaceApp) OrganizeDBAutoExit(ctx context.Context, isMemberRoom bo
 
 	for _, seatSnapshot := range candidateSeatsSnapshot {
 		liveChatMessage := ""
-		txErr := app.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		txErr := app.RunTransaction(ctx, func(ctx context.Context, tx repository.Transaction) error {
 			jstNow := app.currentTime() // スナップショットごとに最新の時刻を取得
 			app.SetProcessedUser(seatSnapshot.UserID, seatSnapshot.UserDisplayName, seatSnapshot.UserProfileImageURL, false, false, isMemberRoom)
 
@@ -127,7 +151,7 @@ func (app *WorkspaceApp) OrganizeDBResume(ctx context.Context, isMemberRoom bool
 
 	for _, seatSnapshot := range candidateSeatsSnapshot {
 		liveChatMessage := ""
-		txErr := app.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		txErr := app.RunTransaction(ctx, func(ctx context.Context, tx repository.Transaction) error {
 			jstNow := app.currentTime() // snapshotごとに最新の時刻を取得
 			app.SetProcessedUser(seatSnapshot.UserID, seatSnapshot.UserDisplayName, seatSnapshot.UserProfileImageURL, false, false, isMemberRoom)
 
@@ -177,11 +201,288 @@ func (app *WorkspaceApp) OrganizeDBResume(ctx context.Context, isMemberRoom bool
 				}
 				seatIDStr := presenter.SeatIDStr(seat.SeatID, isMemberRoom)
 
-				liveChatMessage = i18nmsg.CommandResumeWork(
-					app.ProcessedUserDisplayName,
-					seatIDStr,
-					seat.RemainingWorkMin(jstNow),
-				)
+				if seat.IsPomodoro() {
+					liveChatMessage = i18nmsg.CommandPomodoroWork(
+						app.ProcessedUserDisplayName,
+						int(timeutil.NoNegativeDuration(seat.CurrentStateUntil.Sub(jstNow)).Minutes()),
+						seatIDStr,
+					)
+				} else {
+					liveChatMessage = i18nmsg.CommandResumeWork(
+						app.ProcessedUserDisplayName,
+						seatIDStr,
+						seat.RemainingWorkMin(jstNow),
+					)
+				}
+			}
+			return nil
+		})
+		if txErr != nil {
+			app.MessageToOwnerWithError(ctx, "failed transaction", txErr)
+			continue // txErr != nil でもreturnではなく次に進む
+		}
+		if liveChatMessage != "" {
+			app.MessageToLiveChat(ctx, liveChatMessage)
+		}
+	}
+	return nil
+}
+
+// OrganizeDBPomodoroBreak ポモドーロの作業時間を過ぎている作業中のユーザーを休憩させる。
+// 休憩からの作業再開は OrganizeDBResume で通常の休憩と同様に行われる。
+func (app *WorkspaceApp) OrganizeDBPomodoroBreak(ctx context.Context, isMemberRoom bool) error {
+	candidateSeatsSnapshot, err := app.Repository.ReadSeatsExpiredWorkUntil(ctx, app.currentTime(), isMemberRoom)
+	if err != nil {
+		return fmt.Errorf("in ReadSeatsExpiredWorkUntil(): %w", err)
+	}
+	slog.Info("ポモドーロ休憩候補" + strconv.Itoa(len(candidateSeatsSnapshot)) + "人")
+
+	for _, seatSnapshot := range candidateSeatsSnapshot {
+		liveChatMessage := ""
+		txErr := app.RunTransaction(ctx, func(ctx context.Context, tx repository.Transaction) error {
+			jstNow := app.currentTime() // snapshotごとに最新の時刻を取得
+			app.SetProcessedUser(seatSnapshot.UserID, seatSnapshot.UserDisplayName, seatSnapshot.UserProfileImageURL, false, false, isMemberRoom)
+
+			// 現在も存在しているか
+			seat, err := app.Repository.ReadSeat(ctx, tx, seatSnapshot.SeatID, isMemberRoom)
+			if err != nil {
+				if status.Code(err) == codes.NotFound {
+					slog.Info("すぐ前に退室したということなのでスルー")
+					return nil
+				}
+				return fmt.Errorf("in ReadSeat(): %w", err)
+			}
+			if !reflect.DeepEqual(seat, seatSnapshot) {
+				slog.Info("その座席に少しでも変更が加えられているのでスルー")
+				return nil
+			}
+
+			// NOTE: ポモドーロでない作業中の座席もCurrentStateUntil（=Until）を過ぎていれば候補に含まれるが、それは自動退室の対象なのでここではスルー
+			if !seat.IsPomodoroWorkFinished(jstNow) {
+				return nil
+			}
+
+			// 以下書き込みのみ
+
+			workSegment, err := seat.GenerateWorkSegment(jstNow, isMemberRoom)
+			if err != nil {
+				return fmt.Errorf("in GenerateWorkSegment(): %w", err)
+			}
+			if err := app.Repository.CreateWorkSegmentDoc(ctx, tx, workSegment); err != nil {
+				return fmt.Errorf("in CreateWorkSegmentDoc(): %w", err)
+			}
+
+			if err := seat.StartBreak(jstNow, "", seat.PomodoroBreakMin); err != nil {
+				return fmt.Errorf("in StartBreak(): %w", err)
+			}
+			if err := app.Repository.UpdateSeat(ctx, tx, seat, isMemberRoom); err != nil {
+				return fmt.Errorf("in UpdateSeat(): %w", err)
+			}
+			// DEPRECATED: activityログ記録
+			startBreakActivity := repository.UserActivityDoc{
+				UserID:       app.ProcessedUserID,
+				ActivityType: repository.StartBreakActivity,
+				SeatID:       seat.SeatID,
+				IsMemberSeat: isMemberRoom,
+				TakenAt:      jstNow,
+			}
+			if err := app.Repository.CreateUserActivityDoc(ctx, tx, startBreakActivity); err != nil {
+				return fmt.Errorf("in CreateUserActivityDoc(): %w", err)
+			}
+
+			seatIDStr := presenter.SeatIDStr(seat.SeatID, isMemberRoom)
+			liveChatMessage = i18nmsg.CommandPomodoroBreak(app.ProcessedUserDisplayName, seat.PomodoroBreakMin, seatIDStr)
+			return nil
+		})
+		if txErr != nil {
+			app.MessageToOwnerWithError(ctx, "failed transaction", txErr)
+			continue // txErr != nil でもreturnではなく次に進む
+		}
+		if liveChatMessage != "" {
+			app.MessageToLiveChat(ctx, liveChatMessage)
+		}
+	}
+	return nil
+}
+
+// OrganizeDBDailyGoal 入室中のユーザーのうち、当日の作業時間が目標作業時間に達したユーザーにお祝いメッセージを送る。
+// 毎分全員のユーザードキュメントを読むのを避けるため、座席ごとに次の判定日時（DailyGoalCheckAt）を持たせ、それを過ぎた座席だけを判定する。
+// 達成済みフラグは ResetDailyTotalStudyTime で日付が変わるときにリセットされる。
+func (app *WorkspaceApp) OrganizeDBDailyGoal(ctx context.Context, isMemberRoom bool) error {
+	now := app.currentTime()
+	// 日付が変わってから当日の累計作業時間と達成フラグがリセットされるまでは、前日の値で判定してしまうので待つ
+	if app.Configs.Constants.LastResetDailyTotalStudySec.Before(timeutil.StartOfDayJST(now)) {
+		return nil
+	}
+
+	var seatsSnapshot []repository.SeatDoc
+	var err error
+	if isMemberRoom {
+		seatsSnapshot, err = app.Repository.ReadMemberSeats(ctx)
+		if err != nil {
+			return fmt.Errorf("in ReadMemberSeats(): %w", err)
+		}
+	} else {
+		seatsSnapshot, err = app.Repository.ReadGeneralSeats(ctx)
+		if err != nil {
+			return fmt.Errorf("in ReadGeneralSeats(): %w", err)
+		}
+	}
+
+	for _, seatSnapshot := range seatsSnapshot {
+		if seatSnapshot.State != repository.WorkState { // 休憩中は作業時間が増えないのでスルー
+			continue
+		}
+		if seatSnapshot.DailyGoalCheckAt.After(now) {
+			continue
+		}
+
+		liveChatMessage := ""
+		txErr := app.RunTransaction(ctx, func(ctx context.Context, tx repository.Transaction) error {
+			app.SetProcessedUser(seatSnapshot.UserID, seatSnapshot.UserDisplayName, seatSnapshot.UserProfileImageURL, false, false, isMemberRoom)
+
+			seat, err := app.Repository.ReadSeat(ctx, tx, seatSnapshot.SeatID, isMemberRoom)
+			if err != nil {
+				if status.Code(err) == codes.NotFound {
+					return nil
+				}
+				return fmt.Errorf("in ReadSeat(): %w", err)
+			}
+			if seat.UserID != seatSnapshot.UserID || seat.State != repository.WorkState || seat.DailyGoalCheckAt.After(now) {
+				return nil
+			}
+			userDoc, err := app.Repository.ReadUser(ctx, tx, app.ProcessedUserID)
+			if err != nil {
+				return fmt.Errorf("in ReadUser(): %w", err)
+			}
+
+			achieved := false
+			if userDoc.DailyGoalMin == 0 || userDoc.DailyGoalAchieved {
+				// 目標の変更（!my goal=）か日付が変わるまでは判定不要
+				seat.DailyGoalCheckAt = timeutil.StartOfDayJST(now).AddDate(0, 0, 1)
+			} else {
+				realtimeDailyDuration, err := utils.RealTimeDailyTotalStudyDurationOfSeat(seat, now)
+				if err != nil {
+					return fmt.Errorf("in RealTimeDailyTotalStudyDurationOfSeat(): %w", err)
+				}
+				dailyTotalStudyDuration := realtimeDailyDuration + time.Duration(userDoc.DailyTotalStudySec)*time.Second
+				remaining := time.Duration(userDoc.DailyGoalMin)*time.Minute - dailyTotalStudyDuration
+				if remaining <= 0 {
+					achieved = true
+					seat.DailyGoalCheckAt = timeutil.StartOfDayJST(now).AddDate(0, 0, 1)
+				} else {
+					// 休憩を挟むと達成はさらに遅れるだけなので、残り時間後に判定すれば取りこぼさない
+					seat.DailyGoalCheckAt = now.Add(remaining)
+				}
+			}
+
+			// 以下書き込みのみ
+
+			if err := app.Repository.UpdateSeat(ctx, tx, seat, isMemberRoom); err != nil {
+				return fmt.Errorf("in UpdateSeat(): %w", err)
+			}
+			if achieved {
+				if err := app.Repository.UpdateUserDailyGoalAchieved(tx, app.ProcessedUserID, true); err != nil {
+					return fmt.Errorf("in UpdateUserDailyGoalAchieved(): %w", err)
+				}
+				liveChatMessage = i18nmsg.OthersDailyGoalAchieved(app.ProcessedUserDisplayName, userDoc.DailyGoalMin)
+			}
+			return nil
+		})
+		if txErr != nil {
+			app.MessageToOwnerWithError(ctx, "failed transaction", txErr)
+			continue // txErr != nil でもreturnではなく次に進む
+		}
+		if liveChatMessage != "" {
+			app.MessageToLiveChat(ctx, liveChatMessage)
+		}
+	}
+	return nil
+}
+
+// OrganizeDBMoveReservedSeatOccupants 予約の開始時刻を過ぎても予約者以外が座っている席から、そのユーザーを空いている別の席へ移動させる。
+// 開始時刻の少し前からは他のユーザーの入室を断っているが、それより前から座っているユーザーはここで移動させる。
+func (app *WorkspaceApp) OrganizeDBMoveReservedSeatOccupants(ctx context.Context, isMemberRoom bool) error {
+	jstNow := app.currentTime()
+	reservationsSnapshot, err := app.Repository.ReadSeatReservationsStartBefore(ctx, jstNow, isMemberRoom, utils.SeatReservationsReadLimit)
+	if err != nil {
+		return fmt.Errorf("in ReadSeatReservationsStartBefore(): %w", err)
+	}
+
+	for _, reservation := range reservationsSnapshot {
+		if !reservation.Until.After(jstNow) {
+			continue
+		}
+		var occupiedSeat repository.SeatDoc
+		var forcedMove bool
+		txErr := app.RunTransaction(ctx, func(ctx context.Context, tx repository.Transaction) error {
+			seat, err := app.Repository.ReadSeat(ctx, tx, reservation.SeatID, isMemberRoom)
+			if err != nil {
+				if status.Code(err) == codes.NotFound {
+					return nil
+				}
+				return fmt.Errorf("in ReadSeat(): %w", err)
+			}
+			if seat.UserID == reservation.UserID {
+				return nil
+			}
+			occupiedSeat = seat
+			forcedMove = true
+			return nil
+		})
+		if txErr != nil {
+			app.MessageToOwnerWithError(ctx, "failed transaction in OrganizeDBMoveReservedSeatOccupants", txErr)
+			continue
+		}
+		if forcedMove { // nested transactionとならないよう、RunTransactionの外側で実行
+			app.SetProcessedUser(occupiedSeat.UserID, occupiedSeat.UserDisplayName, occupiedSeat.UserProfileImageURL, false, false, isMemberRoom)
+			seatIDStr := presenter.SeatIDStr(occupiedSeat.SeatID, isMemberRoom)
+			app.MessageToLiveChat(ctx, i18nmsg.OthersReservedSeatMove(app.ProcessedUserDisplayName, seatIDStr))
+			if err := app.moveToAvailableSeat(ctx, occupiedSeat, isMemberRoom); err != nil {
+				return fmt.Errorf("%sさん（%s）の予約席からの席移動処理中にエラーが発生しました: %w", app.ProcessedUserDisplayName, app.ProcessedUserID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// OrganizeDBExpireSeatReservations 開始時刻から猶予時間が過ぎた予約を削除する。
+// 予約者が開始時刻以降に一度も座っていなければ通知する（すでに退室していても、座っていれば通知しない）。
+func (app *WorkspaceApp) OrganizeDBExpireSeatReservations(ctx context.Context, isMemberRoom bool) error {
+	graceMin := app.Configs.Constants.ReservationNoShowGraceMin
+	reservationsSnapshot, err := app.Repository.ReadSeatReservationsStartBefore(ctx, app.currentTime().Add(-time.Duration(graceMin)*time.Minute), isMemberRoom, utils.SeatReservationsReadLimit)
+	if err != nil {
+		return fmt.Errorf("in ReadSeatReservationsStartBefore(): %w", err)
+	}
+	slog.Info("期限切れの予約" + strconv.Itoa(len(reservationsSnapshot)) + "件")
+
+	for _, reservation := range reservationsSnapshot {
+		liveChatMessage := ""
+		txErr := app.RunTransaction(ctx, func(ctx context.Context, tx repository.Transaction) error {
+			showedUp := false
+			seat, err := app.Repository.ReadSeat(ctx, tx, reservation.SeatID, isMemberRoom)
+			if err != nil {
+				if status.Code(err) != codes.NotFound {
+					return fmt.Errorf("in ReadSeat(): %w", err)
+				}
+			} else {
+				showedUp = seat.UserID == reservation.UserID
+			}
+			if !showedUp {
+				showedUp, err = app.UserUsedSeatSince(ctx, reservation.UserID, reservation.SeatID, isMemberRoom, reservation.StartAt)
+				if err != nil {
+					return fmt.Errorf("in UserUsedSeatSince(): %w", err)
+				}
+			}
+
+			// 以下書き込みのみ
+
+			if err := app.Repository.DeleteSeatReservation(ctx, tx, reservation.ReservationID, isMemberRoom); err != nil {
+				return fmt.Errorf("in DeleteSeatReservation(): %w", err)
+			}
+			if !showedUp {
+				seatIDStr := presenter.SeatIDStr(reservation.SeatID, isMemberRoom)
+				liveChatMessage = i18nmsg.CommandReserveNoShow(reservation.UserDisplayName, seatIDStr)
 			}
 			return nil
 		})
@@ -228,7 +529,7 @@ func (app *WorkspaceApp) OrganizeDBForceMove(ctx context.Context, seatsSnapshot
 	slog.Info(utils.NameOf(app.OrganizeDBForceMove), "isMemberSeat", isMemberSeat, "len(seatsSnapshot)", len(seatsSnapshot))
 	for _, seatSnapshot := range seatsSnapshot {
 		var forcedMove bool // 長時間入室制限による強制席移動
-		txErr := app.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		txErr := app.RunTransaction(ctx, func(ctx context.Context, tx repository.Transaction) error {
 			app.SetProcessedUser(seatSnapshot.UserID, seatSnapshot.UserDisplayName, seatSnapshot.UserProfileImageURL, false, false, isMemberSeat)
 
 			// 現在も存在しているか
@@ -264,34 +565,7 @@ func (app *WorkspaceApp) OrganizeDBForceMove(ctx context.Context, seatsSnapshot
 		if forcedMove { // 長時間入室制限による強制席移動。nested transactionとならないよう、RunTransactionの外側で実行
 			seatIDStr := presenter.SeatIDStr(seatSnapshot.SeatID, isMemberSeat)
 			app.MessageToLiveChat(ctx, i18nmsg.OthersForceMove(app.ProcessedUserDisplayName, seatIDStr))
-
-			var isOrderSet bool
-			var menuNum int
-			if seatSnapshot.MenuCode != "" {
-				var err error
-				menuNum, err = app.GetMenuNumByCode(seatSnapshot.MenuCode)
-				if err != nil {
-					return fmt.Errorf("in GetMenuNumByCode(): %w", err)
-				}
-			}
-
-			inCommandDetails := &utils.CommandDetails{
-				CommandType: utils.In,
-				InOption: utils.InOption{
-					IsSeatIDSet: true,
-					SeatID:      0,
-					MinWorkOrderOption: &utils.MinWorkOrderOption{
-						IsWorkNameSet:    true,
-						IsDurationMinSet: true,
-						IsOrderSet:       isOrderSet,
-						WorkName:         seatSnapshot.WorkName,
-						DurationMin:      seatSnapshot.RemainingWorkMin(app.currentTime()),
-						OrderNum:         menuNum,
-					},
-					IsMemberSeat: isMemberSeat,
-				},
-			}
-			if err := app.In(ctx, &inCommandDetails.InOption); err != nil {
+			if err := app.moveToAvailableSeat(ctx, seatSnapshot, isMemberSeat); err != nil {
 				return fmt.Errorf("%sさん（%s）の自動席移動処理中にエラーが発生しました: %w", app.ProcessedUserDisplayName, app.ProcessedUserID, err)
 			}
 		}
@@ -299,28 +573,87 @@ func (app *WorkspaceApp) OrganizeDBForceMove(ctx context.Context, seatsSnapshot
 	return nil
 }
 
-func (app *WorkspaceApp) DailyOrganizeDB(ctx context.Context) ([]string, error) {
+// moveToAvailableSeat 処理中のユーザーをseatSnapshotの席から空いている席へ、作業内容と残り時間を引き継いで移動させる。
+// Inを呼ぶため、トランザクションの外側で実行する。
+func (app *WorkspaceApp) moveToAvailableSeat(ctx context.Context, seatSnapshot repository.SeatDoc, isMemberSeat bool) error {
+	var isOrderSet bool
+	var menuNum int
+	if seatSnapshot.MenuCode != "" {
+		var err error
+		menuNum, err = app.GetMenuNumByCode(seatSnapshot.MenuCode)
+		if err != nil {
+			return fmt.Errorf("in GetMenuNumByCode(): %w", err)
+		}
+	}
+
+	inCommandDetails := &utils.CommandDetails{
+		CommandType: utils.In,
+		InOption: utils.InOption{
+			IsSeatIDSet: true,
+			SeatID:      0,
+			MinWorkOrderOption: &utils.MinWorkOrderOption{
+				IsWorkNameSet:    true,
+				IsDurationMinSet: true,
+				IsOrderSet:       isOrderSet,
+				WorkName:         seatSnapshot.WorkName,
+				DurationMin:      seatSnapshot.RemainingWorkMin(app.currentTime()),
+				OrderNum:         menuNum,
+			},
+			IsMemberSeat: isMemberSeat,
+		},
+	}
+	return app.In(ctx, &inCommandDetails.InOption)
+}
+
+// DailyOrganizeDB 日付が変わった直後に1回実行する、DBの日次整理。RP更新・BigQueryへの転送は別のジョブで行う。
+func (app *WorkspaceApp) DailyOrganizeDB(ctx context.Context) error {
 	slog.Info(utils.NameOf(app.DailyOrganizeDB))
 	var ownerMessage string
 
 	slog.Info("一時的累計作業時間をリセット")
 	dailyResetCount, err := app.ResetDailyTotalStudyTime(ctx)
 	if err != nil {
-		return []string{}, fmt.Errorf("in ResetDailyTotalStudyTime(): %w", err)
+		return fmt.Errorf("in ResetDailyTotalStudyTime(): %w", err)
 	}
 	ownerMessage += "\nsuccessfully reset daily total study time. (" + strconv.Itoa(dailyResetCount) + " users)"
 
-	slog.Info("RP関連の情報更新・ペナルティ処理を行うユーザーのIDのリストを取得")
-	userIDsToProcessRP, err := app.GetUserIDsToProcessRP(ctx)
+	slog.Info("前日の日ごとの作業履歴を作業セグメントから補完")
+	backfilledCount, err := app.BackfillDailyUserWorkHistory(ctx, app.currentTime().AddDate(0, 0, -1))
+	if err != nil {
+		return fmt.Errorf("in BackfillDailyUserWorkHistory(): %w", err)
+	}
+	ownerMessage += "\nsuccessfully backfilled daily user work history. (" + strconv.Itoa(backfilledCount) + " users)"
+
+	slog.Info("取り消せる時間を過ぎた退室の記録を削除")
+	deletedUndoableExitCount, err := app.DeleteExpiredUndoableExits(ctx)
 	if err != nil {
-		return []string{}, fmt.Errorf("in GetUserIDsToProcessRP(): %w", err)
+		return fmt.Errorf("in DeleteExpiredUndoableExits(): %w", err)
 	}
+	ownerMessage += "\nsuccessfully deleted expired undoable exits. (" + strconv.Itoa(deletedUndoableExitCount) + " docs)"
 
-	ownerMessage += "\n過去31日以内に入室した人数（RP処理対象）: " + strconv.Itoa(len(userIDsToProcessRP))
 	ownerMessage += "\n本日のDailyOrganizeDB()処理が完了しました（RP更新処理以外）。"
 	app.MessageToOwner(ctx, ownerMessage)
 	slog.Info("finished " + utils.NameOf(app.DailyOrganizeDB))
-	return userIDsToProcessRP, nil
+	return nil
+}
+
+// DeleteExpiredUndoableExits !undoで取り消せる時間を過ぎた退室の記録を削除し、削除した件数を返す。
+// 取り消し済みの退室や入室し直したユーザーの記録は!undoでしか削除されないため、ここでまとめて削除する。
+func (app *WorkspaceApp) DeleteExpiredUndoableExits(ctx context.Context) (int, error) {
+	threshold := app.currentTime().Add(-time.Duration(max(app.UndoExitGraceMin(), 0)) * time.Minute)
+	total := 0
+	for {
+		iter := app.Repository.Get500UndoableExitDocIDsBeforeDate(ctx, threshold)
+		count, err := app.DeleteIteratorDocs(ctx, iter)
+		if err != nil {
+			return 0, fmt.Errorf("in DeleteIteratorDocs(): %w", err)
+		}
+		if count == 0 {
+			break
+		}
+		total += count
+	}
+	return total, nil
 }
 
 func (app *WorkspaceApp) ResetDailyTotalStudyTime(ctx context.Context) (int, error) {
@@ -340,11 +673,28 @@ func (app *WorkspaceApp) ResetDailyTotalStudyTime(ctx context.Context) (int, err
 			if err != nil {
 				return 0, fmt.Errorf("in userIter.Next(): %w", err)
 			}
-			if err := app.Repository.ResetDailyTotalStudyTime(ctx, doc.Ref); err != nil {
+			if err := app.Repository.ResetDailyTotalStudyTime(ctx, doc.ID); err != nil {
 				return 0, fmt.Errorf("in ResetDailyTotalStudyTime(): %w", err)
 			}
 			count += 1
 		}
+
+		// 1日の目標作業時間の達成フラグもリセット
+		// NOTE: 入室したまま日付を跨いだユーザーは当日の累計作業時間が0のままのことがあるので、上とは別に取得する
+		achievedUserIter := app.Repository.GetAllDailyGoalAchievedUserDocs(ctx)
+		for {
+			doc, err := achievedUserIter.Next()
+			if errors.Is(err, iterator.Done) {
+				break
+			}
+			if err != nil {
+				return 0, fmt.Errorf("in achievedUserIter.Next(): %w", err)
+			}
+			if err := app.Repository.ResetDailyGoalAchieved(ctx, doc.ID); err != nil {
+				return 0, fmt.Errorf("in ResetDailyGoalAchieved(): %w", err)
+			}
+		}
+
 		if err := app.Repository.UpdateLastResetDailyTotalStudyTime(ctx, now); err != nil {
 			return 0, fmt.Errorf("in UpdateLastResetDailyTotalStudyTime(): %w", err)
 		}
@@ -355,6 +705,106 @@ func (app *WorkspaceApp) ResetDailyTotalStudyTime(ctx context.Context) (int, err
 	}
 }
 
+// workSegmentPageSize BackfillDailyUserWorkHistoryで作業セグメントを1回に読み込む件数
+const workSegmentPageSize = 500
+
+// BackfillDailyUserWorkHistory はdateを含む日（JST）の日ごとの作業履歴を、作業セグメントから計算し直して上書きする。
+// 退室時の加算が失敗していた場合や、この機能の導入前の日付の補完に使う。
+func (app *WorkspaceApp) BackfillDailyUserWorkHistory(ctx context.Context, date time.Time) (int, error) {
+	slog.Info(utils.NameOf(app.BackfillDailyUserWorkHistory), "date", date)
+	dayStart := timeutil.StartOfDayJST(date)
+	dayEnd := dayStart.AddDate(0, 0, 1)
+	// NOTE: 1日以上続くセグメントはないものとして、前日に始まったセグメントまで遡る
+	from := dayStart.AddDate(0, 0, -1)
+
+	// 対象のユーザーをページングしながら集める
+	var userIDs []string
+	seenUserIDs := make(map[string]bool)
+	var cursor repository.WorkSegmentDocWithID
+	for {
+		page, err := app.Repository.ReadWorkSegmentsPageByTimeRange(ctx, from, dayEnd, cursor, workSegmentPageSize)
+		if err != nil {
+			return 0, fmt.Errorf("in ReadWorkSegmentsPageByTimeRange(): %w", err)
+		}
+		for _, segment := range page {
+			if !seenUserIDs[segment.UserID] {
+				seenUserIDs[segment.UserID] = true
+				userIDs = append(userIDs, segment.UserID)
+			}
+		}
+		if len(page) < workSegmentPageSize {
+			break
+		}
+		cursor = page[len(page)-1]
+	}
+
+	count := 0
+	for _, userID := range userIDs {
+		updated := false
+		// 退室時の加算（exitRoom）と同じドキュメントを書き換えるので、ユーザーごとにトランザクションで再計算する。
+		// 処理中に退室された場合はトランザクションがやり直しになり、そのセッションも含めて計算し直される。
+		txErr := app.RunTransaction(ctx, func(ctx context.Context, tx repository.Transaction) error {
+			updated = false
+			// 入室中のセッションは退室時にまとめて加算されるので、ここでは除外する
+			activeSessionIDs := make(map[string]bool)
+			for _, isMemberSeat := range []bool{false, true} {
+				seat, err := app.CurrentSeat(ctx, tx, userID, isMemberSeat)
+				if err != nil {
+					if errors.Is(err, studyspaceerror.ErrUserNotInTheRoom) {
+						continue
+					}
+					return fmt.Errorf("in CurrentSeat(): %w", err)
+				}
+				activeSessionIDs[seat.SessionID] = true
+			}
+			segments, err := app.Repository.ReadWorkSegmentsByUserIDAndTimeRange(ctx, userID, from, dayEnd, 0)
+			if err != nil {
+				return fmt.Errorf("in ReadWorkSegmentsByUserIDAndTimeRange(): %w", err)
+			}
+			current, err := app.Repository.ReadDailyUserWorkHistory(ctx, tx, userID, dayStart)
+			if err != nil && status.Code(err) != codes.NotFound {
+				return fmt.Errorf("in ReadDailyUserWorkHistory(): %w", err)
+			}
+
+			var closedSegments []repository.WorkSegmentDoc
+			for _, segment := range segments {
+				if !activeSessionIDs[segment.SessionID] {
+					closedSegments = append(closedSegments, segment)
+				}
+			}
+			daily := utils.DailyWorkBreakSecOfSegments(closedSegments, dayStart)
+			if daily.WorkSec == 0 && daily.BreakSec == 0 {
+				return nil
+			}
+			if current.TotalStudySec == daily.WorkSec && current.TotalBreakSec == daily.BreakSec {
+				return nil // 退室時の加算で記録済み
+			}
+
+			// 以下書き込みのみ
+
+			history := repository.DailyUserWorkHistoryDoc{
+				UserID:        userID,
+				Date:          daily.Date,
+				TotalStudySec: daily.WorkSec,
+				TotalBreakSec: daily.BreakSec,
+				TimezoneName:  timeutil.JapanLocation().String(),
+			}
+			if err := app.Repository.SetDailyUserWorkHistory(ctx, tx, history); err != nil {
+				return fmt.Errorf("in SetDailyUserWorkHistory(): %w", err)
+			}
+			updated = true
+			return nil
+		})
+		if txErr != nil {
+			return count, fmt.Errorf("in RunTransaction() for %s: %w", userID, txErr)
+		}
+		if updated {
+			count++
+		}
+	}
+	return count, nil
+}
+
 func (app *WorkspaceApp) UpdateUserRPBatch(ctx context.Context, userIDs []string, timeLimitSeconds int) []string {
 	startTime := app.currentTime()
 	var doneUserIDs []string
@@ -387,7 +837,7 @@ func (app *WorkspaceApp) UpdateUserRPBatch(ctx context.Context, userIDs []string
 
 func (app *WorkspaceApp) UpdateUserRP(ctx context.Context, userID string, jstNow time.Time) error {
 	slog.Info("processing RP.", "userID", userID)
-	return app.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+	return app.RunTransaction(ctx, func(ctx context.Context, tx repository.Transaction) error {
 		userDoc, err := app.Repository.ReadUser(ctx, tx, userID)
 		if err != nil {
 			return fmt.Errorf("in ReadUser(): %w", err)
@@ -423,6 +873,17 @@ func (app *WorkspaceApp) UpdateUserRP(ctx context.Context, userID string, jstNow
 			}
 		}
 
+		lastActiveAt := utils.LastActiveAt(userDoc.LastEntered, userDoc.LastExited, jstNow)
+		streakDays, err := utils.CalcStreakDays(isContinuousActive, currentActivityStateStarted, lastActiveAt, jstNow)
+		if err != nil {
+			return fmt.Errorf("in CalcStreakDays(): %w", err)
+		}
+		if streakDays > userDoc.BestStreakDays {
+			if err := app.Repository.UpdateUserBestStreakDays(ctx, tx, userID, streakDays); err != nil {
+				return fmt.Errorf("in UpdateUserBestStreakDays(): %w", err)
+			}
+		}
+
 		if err := app.Repository.UpdateUserLastRPProcessed(tx, userID, jstNow); err != nil {
 			return fmt.Errorf("in UpdateUserLastRPProcessed(): %w", err)
 		}
@@ -464,7 +925,7 @@ func (app *WorkspaceApp) BackupCollectionHistoryFromGcsToBigquery(ctx context.Co
 			ctx,
 			gcsTargetFolderName,
 			app.Configs.Constants.GcsFirestoreExportBucketName,
-			[]string{repository.LiveChatHistory, repository.UserActivities, repository.OrderHistory},
+			[]string{repository.LiveChatHistory, repository.UserActivities, repository.OrderHistory, repository.ModerationActions},
 		); err != nil {
 			return fmt.Errorf("in ReadCollectionsFromGcs(): %w", err)
 		}
@@ -0,0 +1,411 @@
+package workspaceapp
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"app.modules/core/i18n"
+	i18nmsg "app.modules/core/i18n/typed"
+	"app.modules/core/moderatorbot"
+	"app.modules/core/repository"
+	"app.modules/core/timeutil"
+	mock_youtubebot "app.modules/core/youtubebot/mocks"
+)
+
+func TestOrganizeDBDeleteExpiredSeatLimits(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, time.January, 1, 10, 0, 0, 0, timeutil.JapanLocation())
+	repo := repository.NewInMemoryRepository()
+	app := WorkspaceApp{
+		Repository:    repo,
+		alertOwnerBot: moderatorbot.DummyMessageBot{},
+		nowFunc:       func() time.Time { return now },
+	}
+
+	// 期限切れ600件（1回の削除上限を超える）と有効な1件
+	for i := 0; i < 600; i++ {
+		require.NoError(t, repo.CreateSeatLimitInBLACKList(ctx, 1, "test_user_id", now.Add(-2*time.Hour), now.Add(-time.Hour), false))
+	}
+	require.NoError(t, repo.CreateSeatLimitInBLACKList(ctx, 2, "test_user_id", now, now.Add(time.Hour), false))
+	require.NoError(t, repo.CreateSeatLimitInWHITEList(ctx, 1, "test_user_id", now.Add(-2*time.Hour), now.Add(-time.Hour), false))
+
+	require.NoError(t, app.OrganizeDBDeleteExpiredSeatLimits(ctx, false))
+
+	blackList, err := repo.ReadSeatLimitsBLACKListWithSeatIDAndUserID(ctx, 1, "test_user_id", false)
+	require.NoError(t, err)
+	assert.Empty(t, blackList)
+	blackList, err = repo.ReadSeatLimitsBLACKListWithSeatIDAndUserID(ctx, 2, "test_user_id", false)
+	require.NoError(t, err)
+	assert.Len(t, blackList, 1)
+	whiteList, err := repo.ReadSeatLimitsWHITEListWithSeatIDAndUserID(ctx, 1, "test_user_id", false)
+	require.NoError(t, err)
+	assert.Empty(t, whiteList)
+}
+
+func TestDeleteExpiredUndoableExits(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, time.January, 1, 10, 0, 0, 0, timeutil.JapanLocation())
+	repo := repository.NewInMemoryRepository()
+	app := WorkspaceApp{
+		Configs: &Configs{
+			Constants: repository.ConstantsConfigDoc{UndoExitGraceMin: 5},
+		},
+		Repository:    repo,
+		alertOwnerBot: moderatorbot.DummyMessageBot{},
+		nowFunc:       func() time.Time { return now },
+	}
+
+	// 取り消せる時間を過ぎた600件（1回の削除上限を超える）とまだ取り消せる1件
+	for i := 0; i < 600; i++ {
+		require.NoError(t, repo.SetUndoableExit(ctx, nil, repository.UndoableExitDoc{
+			UserID:   "expired_user_id" + strconv.Itoa(i),
+			ExitedAt: now.Add(-10 * time.Minute),
+		}))
+	}
+	require.NoError(t, repo.SetUndoableExit(ctx, nil, repository.UndoableExitDoc{
+		UserID:   "test_user_id",
+		ExitedAt: now.Add(-time.Minute),
+	}))
+
+	count, err := app.DeleteExpiredUndoableExits(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 600, count)
+
+	_, err = repo.ReadUndoableExit(ctx, nil, "expired_user_id0")
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = repo.ReadUndoableExit(ctx, nil, "test_user_id")
+	assert.NoError(t, err)
+}
+
+func TestOrganizeDBPomodoroCycle(t *testing.T) {
+	require.NoError(t, i18n.LoadLocaleFolderFS())
+	enteredAt := time.Date(2026, time.January, 1, 10, 0, 0, 0, timeutil.JapanLocation())
+	at := func(min int) time.Time { return enteredAt.Add(time.Duration(min) * time.Minute) }
+
+	type step struct {
+		nowMin                    int
+		expectedState             repository.SeatState
+		expectedCurrentStateUntil time.Time
+		expectedMessage           string // 空なら投稿しない
+	}
+	tests := []struct {
+		name         string
+		pomodoroWork int // 0ならポモドーロなし
+		untilMin     int
+		steps        []step
+	}{
+		{
+			name:         "作業→休憩→作業",
+			pomodoroWork: 25,
+			untilMin:     120,
+			steps: []step{
+				{nowMin: 20, expectedState: repository.WorkState, expectedCurrentStateUntil: at(25)},
+				{nowMin: 26, expectedState: repository.BreakState, expectedCurrentStateUntil: at(31),
+					expectedMessage: i18nmsg.CommandPomodoroBreak("テストユーザー", 5, "3")},
+				{nowMin: 30, expectedState: repository.BreakState, expectedCurrentStateUntil: at(31)},
+				{nowMin: 32, expectedState: repository.WorkState, expectedCurrentStateUntil: at(57),
+					expectedMessage: i18nmsg.CommandPomodoroWork("テストユーザー", 25, "3")},
+				{nowMin: 58, expectedState: repository.BreakState, expectedCurrentStateUntil: at(63),
+					expectedMessage: i18nmsg.CommandPomodoroBreak("テストユーザー", 5, "3")},
+			},
+		},
+		{
+			name:         "作業の終了が自動退室予定時刻と重なる場合は休憩しない",
+			pomodoroWork: 25,
+			untilMin:     25,
+			steps: []step{
+				{nowMin: 26, expectedState: repository.WorkState, expectedCurrentStateUntil: at(25)},
+			},
+		},
+		{
+			name:     "ポモドーロでなければ休憩しない",
+			untilMin: 120,
+			steps: []step{
+				{nowMin: 26, expectedState: repository.WorkState, expectedCurrentStateUntil: at(120)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			ctrl := gomock.NewController(t)
+			liveChatBot := mock_youtubebot.NewMockLiveChatBot(ctrl)
+			repo := repository.NewInMemoryRepository()
+			var now time.Time
+			app := WorkspaceApp{
+				Configs:       &Configs{},
+				Repository:    repo,
+				ChatSources:   youtubeChatSources(liveChatBot),
+				alertOwnerBot: moderatorbot.DummyMessageBot{},
+				nowFunc:       func() time.Time { return now },
+			}
+
+			seat := repository.SeatDoc{
+				SeatID:                  3,
+				UserID:                  "test_user_id",
+				UserDisplayName:         "テストユーザー",
+				State:                   repository.WorkState,
+				EnteredAt:               enteredAt,
+				Until:                   at(tt.untilMin),
+				CurrentStateStartedAt:   enteredAt,
+				CurrentStateUntil:       at(tt.untilMin),
+				CurrentSegmentStartedAt: enteredAt,
+			}
+			if tt.pomodoroWork > 0 {
+				seat.SetPomodoro(tt.pomodoroWork, 5)
+			}
+			require.NoError(t, repo.CreateSeat(nil, seat, false))
+
+			for _, step := range tt.steps {
+				now = at(step.nowMin)
+				if step.expectedMessage != "" {
+					liveChatBot.EXPECT().PostMessage(gomock.Any(), step.expectedMessage).Return(nil).Times(1)
+				}
+				// OrganizeDBと同じ順に実行する
+				require.NoError(t, app.OrganizeDBResume(ctx, false))
+				require.NoError(t, app.OrganizeDBPomodoroBreak(ctx, false))
+
+				seat, err := repo.ReadSeat(ctx, nil, 3, false)
+				require.NoError(t, err)
+				assert.Equal(t, step.expectedState, seat.State, "at %d min", step.nowMin)
+				assert.Equal(t, step.expectedCurrentStateUntil, seat.CurrentStateUntil, "at %d min", step.nowMin)
+			}
+		})
+	}
+}
+
+func TestOrganizeDBDailyGoal(t *testing.T) {
+	require.NoError(t, i18n.LoadLocaleFolderFS())
+	enteredAt := time.Date(2026, time.January, 1, 10, 0, 0, 0, timeutil.JapanLocation())
+	at := func(min int) time.Time { return enteredAt.Add(time.Duration(min) * time.Minute) }
+	nextDayStart := time.Date(2026, time.January, 2, 0, 0, 0, 0, timeutil.JapanLocation())
+
+	type step struct {
+		nowMin                   int
+		expectedDailyGoalCheckAt time.Time
+		expectedAchieved         bool
+		expectedMessage          string // 空なら投稿しない
+	}
+	tests := []struct {
+		name         string
+		dailyGoalMin int
+		lastReset    time.Time
+		steps        []step
+	}{
+		{
+			name:         "残り時間が経過してから達成を判定する",
+			dailyGoalMin: 60,
+			lastReset:    at(-60),
+			steps: []step{
+				// 当日の累計30分 + 在席0分なので、残り30分後に判定する
+				{nowMin: 0, expectedDailyGoalCheckAt: at(30)},
+				{nowMin: 20, expectedDailyGoalCheckAt: at(30)},
+				{nowMin: 30, expectedDailyGoalCheckAt: nextDayStart, expectedAchieved: true,
+					expectedMessage: i18nmsg.OthersDailyGoalAchieved("テストユーザー", 60)},
+				{nowMin: 31, expectedDailyGoalCheckAt: nextDayStart, expectedAchieved: true},
+			},
+		},
+		{
+			name:      "目標がなければ翌日まで判定しない",
+			lastReset: at(-60),
+			steps: []step{
+				{nowMin: 0, expectedDailyGoalCheckAt: nextDayStart},
+			},
+		},
+		{
+			name:         "当日の累計作業時間のリセット前は判定しない",
+			dailyGoalMin: 10,
+			lastReset:    at(-24 * 60),
+			steps: []step{
+				{nowMin: 0, expectedDailyGoalCheckAt: time.Time{}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			ctrl := gomock.NewController(t)
+			liveChatBot := mock_youtubebot.NewMockLiveChatBot(ctrl)
+			repo := repository.NewInMemoryRepository()
+			var now time.Time
+			app := WorkspaceApp{
+				Configs:       &Configs{Constants: repository.ConstantsConfigDoc{LastResetDailyTotalStudySec: tt.lastReset}},
+				Repository:    repo,
+				ChatSources:   youtubeChatSources(liveChatBot),
+				alertOwnerBot: moderatorbot.DummyMessageBot{},
+				nowFunc:       func() time.Time { return now },
+			}
+
+			require.NoError(t, repo.CreateUser(ctx, nil, "test_user_id", repository.UserDoc{
+				DailyTotalStudySec: 30 * 60,
+				DailyGoalMin:       tt.dailyGoalMin,
+			}))
+			require.NoError(t, repo.CreateSeat(nil, repository.SeatDoc{
+				SeatID:                  3,
+				UserID:                  "test_user_id",
+				UserDisplayName:         "テストユーザー",
+				State:                   repository.WorkState,
+				EnteredAt:               enteredAt,
+				Until:                   at(120),
+				CurrentStateStartedAt:   enteredAt,
+				CurrentStateUntil:       at(120),
+				CurrentSegmentStartedAt: enteredAt,
+			}, false))
+
+			for _, step := range tt.steps {
+				now = at(step.nowMin)
+				if step.expectedMessage != "" {
+					liveChatBot.EXPECT().PostMessage(gomock.Any(), step.expectedMessage).Return(nil).Times(1)
+				}
+				require.NoError(t, app.OrganizeDBDailyGoal(ctx, false))
+
+				seat, err := repo.ReadSeat(ctx, nil, 3, false)
+				require.NoError(t, err)
+				assert.True(t, step.expectedDailyGoalCheckAt.Equal(seat.DailyGoalCheckAt), "at %d min: %v", step.nowMin, seat.DailyGoalCheckAt)
+				user, err := repo.ReadUser(ctx, nil, "test_user_id")
+				require.NoError(t, err)
+				assert.Equal(t, step.expectedAchieved, user.DailyGoalAchieved, "at %d min", step.nowMin)
+			}
+		})
+	}
+}
+
+func TestBackfillDailyUserWorkHistory(t *testing.T) {
+	ctx := context.Background()
+	day := time.Date(2026, time.January, 1, 0, 0, 0, 0, timeutil.JapanLocation())
+	at := func(hour, min int) time.Time {
+		return day.Add(time.Duration(hour)*time.Hour + time.Duration(min)*time.Minute)
+	}
+	repo := repository.NewInMemoryRepository()
+	app := WorkspaceApp{
+		Configs:       &Configs{},
+		Repository:    repo,
+		alertOwnerBot: moderatorbot.DummyMessageBot{},
+		nowFunc:       func() time.Time { return at(24, 1) },
+	}
+
+	segments := []repository.WorkSegmentDoc{
+		// 退室済み（退室時の加算が失敗していた）
+		{UserID: "closed", SessionID: "s1", SegmentType: repository.WorkState, StartedAt: at(10, 0), EndedAt: at(11, 0)},
+		{UserID: "closed", SessionID: "s1", SegmentType: repository.BreakState, StartedAt: at(11, 0), EndedAt: at(11, 10)},
+		{UserID: "closed", SessionID: "s1", SegmentType: repository.WorkState, StartedAt: at(11, 10), EndedAt: at(12, 0)},
+		// 入室中のセッションは退室時に加算されるので対象外
+		{UserID: "active", SessionID: "s2", SegmentType: repository.WorkState, StartedAt: at(20, 0), EndedAt: at(21, 0)},
+		// 退室時の加算で記録済み
+		{UserID: "recorded", SessionID: "s3", SegmentType: repository.WorkState, StartedAt: at(9, 0), EndedAt: at(10, 0)},
+	}
+	for _, segment := range segments {
+		require.NoError(t, repo.CreateWorkSegmentDoc(ctx, nil, segment))
+	}
+	require.NoError(t, repo.CreateSeat(nil, repository.SeatDoc{
+		SeatID:    1,
+		UserID:    "active",
+		SessionID: "s2",
+		State:     repository.BreakState,
+	}, false))
+	require.NoError(t, repo.AddDailyUserWorkHistory(ctx, nil, "recorded", day, 60*60, 0))
+
+	count, err := app.BackfillDailyUserWorkHistory(ctx, day)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	history, err := repo.ReadDailyUserWorkHistory(ctx, nil, "closed", day)
+	require.NoError(t, err)
+	assert.Equal(t, 110*60, history.TotalStudySec)
+	assert.Equal(t, 10*60, history.TotalBreakSec)
+
+	_, err = repo.ReadDailyUserWorkHistory(ctx, nil, "active", day)
+	assert.Error(t, err)
+
+	history, err = repo.ReadDailyUserWorkHistory(ctx, nil, "recorded", day)
+	require.NoError(t, err)
+	assert.Equal(t, 60*60, history.TotalStudySec)
+}
+
+func TestOrganizeDBSeatReservations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	require.NoError(t, i18n.LoadLocaleFolderFS())
+
+	ctx := context.Background()
+	now := time.Date(2026, time.January, 1, 10, 0, 0, 0, timeutil.JapanLocation())
+	constants := repository.ConstantsConfigDoc{
+		MaxWorkTimeMin:            360,
+		MinWorkTimeMin:            5,
+		DefaultWorkTimeMin:        60,
+		MaxSeats:                  10,
+		ReservationMaxPerUser:     2,
+		ReservationLeadMin:        10,
+		ReservationNoShowGraceMin: 15,
+	}
+	repo := repository.NewInMemoryRepository()
+	require.NoError(t, repo.SetSystemConstantsConfig(constants))
+
+	var postedMessages []string
+	liveChatBot := mock_youtubebot.NewMockLiveChatBot(ctrl)
+	liveChatBot.EXPECT().PostMessage(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, message string) error {
+			postedMessages = append(postedMessages, message)
+			return nil
+		},
+	).AnyTimes()
+	app := WorkspaceApp{
+		Configs:       &Configs{Constants: constants},
+		Repository:    repo,
+		ChatSources:   youtubeChatSources(liveChatBot),
+		alertOwnerBot: moderatorbot.DummyMessageBot{},
+		nowFunc:       func() time.Time { return now },
+	}
+	processMessage := func(command string, userID string, userDisplayName string) {
+		t.Helper()
+		require.NoError(t, app.ProcessMessage(ctx, NGWordConfig{}, "", command, userID, userDisplayName, "", false, false, false))
+	}
+
+	// 予約の受付前から座っている他のユーザーは、予約の開始時刻に別の席へ移動する
+	processMessage("!3 work=数学", "other_user_id", "他のユーザー")
+	processMessage("!reserve 3 10:30 min=60", "test_user_id", "テストユーザー")
+	now = now.Add(31 * time.Minute)
+	postedMessages = nil
+	require.NoError(t, app.OrganizeDBMoveReservedSeatOccupants(ctx, false))
+	require.NotEmpty(t, postedMessages)
+	assert.Equal(t, i18nmsg.OthersReservedSeatMove("他のユーザー", "3"), postedMessages[0])
+	_, err := repo.ReadSeat(ctx, nil, 3, false)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	movedSeat, err := repo.ReadSeatWithUserID(ctx, "other_user_id", false)
+	require.NoError(t, err)
+	assert.NotEqual(t, 3, movedSeat.SeatID)
+	assert.Equal(t, "数学", movedSeat.WorkName)
+
+	// 予約者が一度座ってから退室していれば、猶予時間を過ぎても来なかったとは通知しない
+	now = now.Add(time.Minute)
+	processMessage("!3", "test_user_id", "テストユーザー")
+	now = now.Add(9 * time.Minute)
+	processMessage("!out", "test_user_id", "テストユーザー")
+	processMessage("!reserve 4 11:00", "test_user_id", "テストユーザー")
+	now = now.Add(6 * time.Minute)
+	postedMessages = nil
+	require.NoError(t, app.OrganizeDBExpireSeatReservations(ctx, false))
+	assert.Empty(t, postedMessages)
+	reservations, err := repo.ReadSeatReservationsWithUserID(ctx, nil, "test_user_id", false)
+	require.NoError(t, err)
+	require.Len(t, reservations, 1)
+	assert.Equal(t, 4, reservations[0].SeatID)
+
+	// 一度も座らなければ通知する
+	now = now.Add(30 * time.Minute)
+	postedMessages = nil
+	require.NoError(t, app.OrganizeDBExpireSeatReservations(ctx, false))
+	assert.Equal(t, []string{i18nmsg.CommandReserveNoShow("テストユーザー", "4")}, postedMessages)
+	reservations, err = repo.ReadSeatReservationsWithUserID(ctx, nil, "test_user_id", false)
+	require.NoError(t, err)
+	assert.Empty(t, reservations)
+}
@@ -0,0 +1,40 @@
+package workspaceapp
+
+import (
+	"context"
+	"fmt"
+
+	"app.modules/core/chat"
+)
+
+// AddChatSource 配信サービスのチャットを追加する。同じ配信サービスのチャットがあれば置き換える。
+// 受信したメッセージはProcessChatMessageに渡し、返信やブロックはsourceを通して行う。
+func (app *WorkspaceApp) AddChatSource(source chat.ChatSource) {
+	if app.ChatSources == nil {
+		app.ChatSources = make(map[chat.Platform]chat.ChatSource)
+	}
+	app.ChatSources[source.Platform()] = source
+}
+
+// ProcessChatMessage 配信サービスに依存しないメッセージをコマンドとして処理する。
+// 処理中のライブチャットへの返信は、メッセージを受信した配信サービスに送る。
+func (app *WorkspaceApp) ProcessChatMessage(ctx context.Context, ngWordConfig NGWordConfig, message chat.ChatMessage) error {
+	app.replyPlatform = message.Platform
+	defer func() { app.replyPlatform = "" }()
+
+	author := message.Author
+	return app.ProcessMessage(ctx, ngWordConfig, message.ID, message.Text, author.ID, author.DisplayName,
+		author.ProfileImageURL, author.IsModerator, author.IsOwner, author.IsMember)
+}
+
+// chatSourceFor platformのチャット。platformが空ならYouTube
+func (app *WorkspaceApp) chatSourceFor(platform chat.Platform) (chat.ChatSource, error) {
+	if platform == "" {
+		platform = chat.YouTube
+	}
+	source, ok := app.ChatSources[platform]
+	if !ok {
+		return nil, fmt.Errorf("no chat source for platform %s", platform)
+	}
+	return source, nil
+}
@@ -0,0 +1,84 @@
+package workspaceapp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"app.modules/core/chat"
+	mock_chat "app.modules/core/chat/mocks"
+	"app.modules/core/i18n"
+	"app.modules/core/moderatorbot"
+	"app.modules/core/repository"
+	"app.modules/core/timeutil"
+	"app.modules/core/youtubebot"
+	mock_youtubebot "app.modules/core/youtubebot/mocks"
+)
+
+// youtubeChatSources botをYouTubeのチャットとして持つChatSources
+func youtubeChatSources(bot youtubebot.LiveChatBot) map[chat.Platform]chat.ChatSource {
+	return map[chat.Platform]chat.ChatSource{chat.YouTube: youtubebot.NewChatSource(bot)}
+}
+
+func TestWorkspaceApp_ProcessChatMessage_Twitch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	require.NoError(t, i18n.LoadLocaleFolderFS())
+
+	ctx := context.Background()
+	now := time.Date(2026, time.January, 1, 10, 0, 0, 0, timeutil.JapanLocation())
+	constants := repository.ConstantsConfigDoc{
+		MaxWorkTimeMin:      360,
+		MinWorkTimeMin:      5,
+		DefaultWorkTimeMin:  60,
+		MaxBreakDurationMin: 60,
+		MaxSeats:            10,
+	}
+	repo := repository.NewInMemoryRepository()
+	require.NoError(t, repo.SetSystemConstantsConfig(constants))
+
+	// YouTubeのライブチャットには何も送らない
+	mockLiveChatBot := mock_youtubebot.NewMockLiveChatBot(ctrl)
+	mockTwitch := mock_chat.NewMockChatSource(ctrl)
+	mockTwitch.EXPECT().Platform().Return(chat.Twitch).AnyTimes()
+	mockTwitch.EXPECT().PostMessage(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	mockTwitch.EXPECT().BanUser(gomock.Any(), "123").Return("123", nil).Times(1)
+	mockTwitch.EXPECT().UnbanUser(gomock.Any(), "123").Return(nil).Times(1)
+
+	app := WorkspaceApp{
+		Configs:       &Configs{Constants: constants},
+		Repository:    repo,
+		ChatSources:   youtubeChatSources(mockLiveChatBot),
+		alertOwnerBot: moderatorbot.DummyMessageBot{},
+		nowFunc:       func() time.Time { return now },
+	}
+	app.AddChatSource(mockTwitch)
+
+	require.NoError(t, app.ProcessChatMessage(ctx, NGWordConfig{}, chat.ChatMessage{
+		ID:       "twitch:message-1",
+		Platform: chat.Twitch,
+		Author:   chat.Author{ID: "twitch:123", DisplayName: "TwitchUser"},
+		Text:     "!3 work=数学",
+	}))
+	assert.Empty(t, app.replyPlatform)
+
+	// 同じFirestoreのルームに、名前空間つきのユーザーIDで入室する
+	seat, err := repo.ReadSeat(ctx, nil, 3, false)
+	require.NoError(t, err)
+	assert.Equal(t, "twitch:123", seat.UserID)
+	_, err = repo.ReadUser(ctx, nil, "twitch:123")
+	require.NoError(t, err)
+
+	// ブロックはTwitchのユーザーIDでTwitchに送る
+	require.NoError(t, app.BanUser(ctx, nil, repository.ModerationActionDoc{
+		ActionType:   repository.BlockModerationAction,
+		TargetUserID: "twitch:123",
+		TakenAt:      now,
+	}))
+	ban, err := app.UnbanUser(ctx, "twitch:123")
+	require.NoError(t, err)
+	assert.Equal(t, "123", ban.BanID)
+}
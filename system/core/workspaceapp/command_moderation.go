@@ -5,12 +5,14 @@ import (
 	"fmt"
 	"log/slog"
 	"strconv"
+	"time"
 
-	"cloud.google.com/go/firestore"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	"app.modules/core/chat"
 	i18nmsg "app.modules/core/i18n/typed"
+	"app.modules/core/repository"
 	"app.modules/core/timeutil"
 	"app.modules/core/utils"
 	"app.modules/core/workspaceapp/presenter"
@@ -45,7 +47,7 @@ func (app *WorkspaceApp) Kick(ctx context.Context, kickOption *utils.KickOption)
 		return nil
 	}
 
-	txErr := app.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+	txErr := app.RunTransaction(ctx, func(ctx context.Context, tx repository.Transaction) error {
 		// ターゲットの座席は誰か使っているか
 		{
 			isSeatAvailable, err := app.IfSeatVacant(ctx, tx, targetSeatID, isTargetMemberSeat)
@@ -92,13 +94,27 @@ func (app *WorkspaceApp) Kick(ctx context.Context, kickOption *utils.KickOption)
 		}
 		replyMessage += i18nmsg.CommandExit(targetSeat.UserDisplayName, workedTimeSec/60, seatIDStr, rpEarned)
 
+		action := repository.ModerationActionDoc{
+			ActionType:        repository.KickModerationAction,
+			ActorUserID:       app.ProcessedUserID,
+			ActorDisplayName:  app.ProcessedUserDisplayName,
+			TargetUserID:      targetSeat.UserID,
+			TargetDisplayName: targetSeat.UserDisplayName,
+			SeatID:            targetSeatID,
+			IsMemberSeat:      isTargetMemberSeat,
+			TakenAt:           app.currentTime(),
+		}
+		if err := app.Repository.CreateModerationActionDoc(ctx, tx, action); err != nil {
+			return fmt.Errorf("in CreateModerationActionDoc: %w", err)
+		}
+
 		{
 			err := app.LogToModerators(ctx, app.ProcessedUserDisplayName+"さん、"+strconv.Itoa(targetSeat.
 				SeatID)+"番席のユーザーをkickしました。\n"+
 				"チャンネル名: "+targetSeat.UserDisplayName+"\n"+
 				"作業名: "+targetSeat.WorkName+"\n休憩中の作業名: "+targetSeat.BreakWorkName+"\n"+
 				"入室時間: "+strconv.Itoa(workedTimeSec/60)+"分\n"+
-				"チャンネルURL: https://youtube.com/channel/"+targetSeat.UserID)
+				"チャンネルURL: "+chat.ChannelURL(targetSeat.UserID))
 			if err != nil {
 				return fmt.Errorf("failed LogToModerators(): %w", err)
 			}
@@ -119,7 +135,7 @@ func (app *WorkspaceApp) Check(ctx context.Context, checkOption *utils.CheckOpti
 	isTargetMemberSeat := checkOption.IsTargetMemberSeat
 
 	var replyMessage string
-	txErr := app.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+	txErr := app.RunTransaction(ctx, func(ctx context.Context, tx repository.Transaction) error {
 		// commanderはモデレーターかチャットオーナーか
 		if !app.ProcessedUserIsModeratorOrOwner {
 			replyMessage = i18nmsg.CommandPermission(app.ProcessedUserDisplayName, utils.CheckCommand)
@@ -153,7 +169,7 @@ func (app *WorkspaceApp) Check(ctx context.Context, checkOption *utils.CheckOpti
 			"チャンネル名: " + seat.UserDisplayName + "\n" + "入室時間: " + strconv.Itoa(sinceMinutes) + "分\n" +
 			"作業名: " + seat.WorkName + "\n" + "休憩中の作業名: " + seat.BreakWorkName + "\n" +
 			"自動退室まで" + strconv.Itoa(untilMinutes) + "分\n" +
-			"チャンネルURL: https://youtube.com/channel/" + seat.UserID
+			"チャンネルURL: " + chat.ChannelURL(seat.UserID)
 		if err := app.LogToModerators(ctx, message); err != nil {
 			return fmt.Errorf("failed LogToModerators(): %w", err)
 		}
@@ -169,14 +185,28 @@ func (app *WorkspaceApp) Check(ctx context.Context, checkOption *utils.CheckOpti
 }
 
 func (app *WorkspaceApp) Block(ctx context.Context, blockOption *utils.BlockOption) error {
-	targetSeatID := blockOption.SeatID
-	isTargetMemberSeat := blockOption.IsTargetMemberSeat
+	return app.banSeatUser(ctx, blockOption.SeatID, blockOption.IsTargetMemberSeat, 0)
+}
+
+// Timeout 指定した席のユーザーを退室させ、timeoutOption.DurationMin分の間ブロックする
+func (app *WorkspaceApp) Timeout(ctx context.Context, timeoutOption *utils.TimeoutOption) error {
+	return app.banSeatUser(ctx, timeoutOption.SeatID, timeoutOption.IsTargetMemberSeat,
+		time.Duration(timeoutOption.DurationMin)*time.Minute)
+}
+
+// banSeatUser 指定した席のユーザーを退室させてブロックする。timeoutが0なら無期限、それ以外はその時間だけのタイムアウト
+func (app *WorkspaceApp) banSeatUser(ctx context.Context, targetSeatID int, isTargetMemberSeat bool, timeout time.Duration) error {
+	commandName, actionType, actionName := utils.BlockCommand, repository.BlockModerationAction, "block"
+	if timeout > 0 {
+		commandName, actionType = utils.TimeoutCommand, repository.TimeoutModerationAction
+		actionName = strconv.Itoa(int(timeout.Minutes())) + "分間timeout"
+	}
 
 	var replyMessage string
-	txErr := app.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+	txErr := app.RunTransaction(ctx, func(ctx context.Context, tx repository.Transaction) error {
 		// commanderはモデレーターかチャットオーナーか
 		if !app.ProcessedUserIsModeratorOrOwner {
-			replyMessage = i18nmsg.CommandPermission(app.ProcessedUserDisplayName, utils.BlockCommand)
+			replyMessage = i18nmsg.CommandPermission(app.ProcessedUserDisplayName, commandName)
 			return nil
 		}
 
@@ -203,7 +233,11 @@ func (app *WorkspaceApp) Block(ctx context.Context, blockOption *utils.BlockOpti
 			return fmt.Errorf("in ReadSeat: %w", err)
 		}
 		seatIDStr := presenter.SeatIDStr(targetSeatID, isTargetMemberSeat)
-		replyMessage = i18nmsg.CommandBlockBlock(app.ProcessedUserDisplayName, seatIDStr, targetSeat.UserDisplayName)
+		if timeout > 0 {
+			replyMessage = i18nmsg.CommandTimeoutTimeout(app.ProcessedUserDisplayName, seatIDStr, targetSeat.UserDisplayName, int(timeout.Minutes()))
+		} else {
+			replyMessage = i18nmsg.CommandBlockBlock(app.ProcessedUserDisplayName, seatIDStr, targetSeat.UserDisplayName)
+		}
 
 		// app.ProcessedUserが処理の対象ではないことに注意。
 		userDoc, err := app.Repository.ReadUser(ctx, tx, targetSeat.UserID)
@@ -227,17 +261,28 @@ func (app *WorkspaceApp) Block(ctx context.Context, blockOption *utils.BlockOpti
 		replyMessage += i18nmsg.CommandExit(targetSeat.UserDisplayName, workedTimeSec/60, seatIDStr, rpEarned)
 
 		// ブロック
-		if err := app.BanUser(ctx, targetSeat.UserID); err != nil {
+		action := repository.ModerationActionDoc{
+			ActionType:         actionType,
+			ActorUserID:        app.ProcessedUserID,
+			ActorDisplayName:   app.ProcessedUserDisplayName,
+			TargetUserID:       targetSeat.UserID,
+			TargetDisplayName:  targetSeat.UserDisplayName,
+			SeatID:             targetSeatID,
+			IsMemberSeat:       isTargetMemberSeat,
+			TimeoutDurationSec: int(timeout.Seconds()),
+			TakenAt:            app.currentTime(),
+		}
+		if err := app.BanUser(ctx, tx, action); err != nil {
 			return fmt.Errorf("in BanUser: %w", err)
 		}
 
 		{
 			err := app.LogToModerators(ctx, app.ProcessedUserDisplayName+"さん、"+strconv.Itoa(targetSeat.
-				SeatID)+"番席のユーザーをblockしました。\n"+
+				SeatID)+"番席のユーザーを"+actionName+"しました。\n"+
 				"チャンネル名: "+targetSeat.UserDisplayName+"\n"+
 				"作業名: "+targetSeat.WorkName+"\n休憩中の作業名: "+targetSeat.BreakWorkName+"\n"+
 				"入室時間: "+strconv.Itoa(workedTimeSec/60)+"分\n"+
-				"チャンネルURL: https://youtube.com/channel/"+targetSeat.UserID)
+				"チャンネルURL: "+chat.ChannelURL(targetSeat.UserID))
 			if err != nil {
 				return fmt.Errorf("failed LogToModerators(): %w", err)
 			}
@@ -245,9 +290,21 @@ func (app *WorkspaceApp) Block(ctx context.Context, blockOption *utils.BlockOpti
 		return nil
 	})
 	if txErr != nil {
-		slog.Error("txErr in Block()", "txErr", txErr)
+		slog.Error("txErr in banSeatUser()", "txErr", txErr, "timeout", timeout)
 		replyMessage = i18nmsg.CommandError(app.ProcessedUserDisplayName)
 	}
 	app.MessageToLiveChat(ctx, replyMessage)
 	return txErr
 }
+
+// Quota YouTube Data APIの今日の使用量をownerに送る
+func (app *WorkspaceApp) Quota(ctx context.Context) error {
+	if !app.ProcessedUserIsModeratorOrOwner {
+		app.MessageToLiveChat(ctx, i18nmsg.CommandPermission(app.ProcessedUserDisplayName, utils.QuotaCommand))
+		return nil
+	}
+
+	app.MessageToOwner(ctx, app.youtubeAPIQuotaReport(app.QuotaMeter.Usage()))
+	app.MessageToLiveChat(ctx, i18nmsg.CommandSent(app.ProcessedUserDisplayName))
+	return nil
+}
@@ -0,0 +1,89 @@
+package workspaceapp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"app.modules/core/i18n"
+	"app.modules/core/repository"
+	"app.modules/core/utils"
+	mock_youtubebot "app.modules/core/youtubebot/mocks"
+)
+
+func TestSystem_Timeout(t *testing.T) {
+	require.NoError(t, i18n.LoadLocaleFolderFS())
+	ctrl := gomock.NewController(t)
+	ctx := context.Background()
+
+	t.Run("モデレーター以外は使えない", func(t *testing.T) {
+		mockLiveChatBot := mock_youtubebot.NewMockLiveChatBot(ctrl)
+		mockLiveChatBot.EXPECT().PostMessage(gomock.Any(), "@テストユーザー さんは「!timeout」コマンドを使用できません🔒").Return(nil)
+		app := WorkspaceApp{
+			Configs:                  &Configs{},
+			Repository:               repository.NewInMemoryRepository(),
+			ChatSources:              youtubeChatSources(mockLiveChatBot),
+			ProcessedUserDisplayName: "テストユーザー",
+		}
+		require.NoError(t, app.Timeout(ctx, &utils.TimeoutOption{SeatID: 1, DurationMin: 10}))
+	})
+
+	t.Run("時間の範囲", func(t *testing.T) {
+		app := WorkspaceApp{}
+		for _, durationMin := range []int{0, utils.MaxTimeoutMin + 1} {
+			message := app.ValidateTimeout(utils.CommandDetails{
+				CommandType:   utils.Timeout,
+				TimeoutOption: utils.TimeoutOption{SeatID: 1, DurationMin: durationMin},
+			})
+			assert.Equal(t, "タイムアウトの時間（分）は1～1440の値にしてください⏱️", message, durationMin)
+		}
+		assert.Empty(t, app.ValidateTimeout(utils.CommandDetails{
+			CommandType:   utils.Timeout,
+			TimeoutOption: utils.TimeoutOption{SeatID: 1, DurationMin: utils.MaxTimeoutMin},
+		}))
+	})
+}
+
+func TestWorkspaceApp_UnbanUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := context.Background()
+	now := time.Date(2026, time.January, 1, 10, 0, 0, 0, time.UTC)
+
+	repo := repository.NewInMemoryRepository()
+	for _, action := range []repository.ModerationActionDoc{
+		{ActionType: repository.BlockModerationAction, TargetUserID: "user", BanID: "ban-1", TakenAt: now.Add(-3 * time.Hour)},
+		{ActionType: repository.KickModerationAction, TargetUserID: "user", TakenAt: now.Add(-2 * time.Hour)},
+		// 期限切れのタイムアウトは解除の対象にならない
+		{ActionType: repository.TimeoutModerationAction, TargetUserID: "user", BanID: "ban-2", TimeoutDurationSec: 600,
+			TakenAt: now.Add(-time.Hour)},
+	} {
+		require.NoError(t, repo.CreateModerationActionDoc(ctx, nil, action))
+	}
+
+	mockLiveChatBot := mock_youtubebot.NewMockLiveChatBot(ctrl)
+	mockLiveChatBot.EXPECT().UnbanUser(gomock.Any(), "ban-1").Return(nil).Times(1)
+	app := WorkspaceApp{
+		Repository:  repo,
+		ChatSources: youtubeChatSources(mockLiveChatBot),
+		nowFunc:     func() time.Time { return now },
+	}
+
+	ban, err := app.UnbanUser(ctx, "user")
+	require.NoError(t, err)
+	assert.Equal(t, "ban-1", ban.BanID)
+
+	actions, err := repo.ReadModerationActionsByTargetUserID(ctx, "user")
+	require.NoError(t, err)
+	require.Len(t, actions, 4)
+	assert.Equal(t, repository.ModerationActionDoc{
+		ActionType: repository.UnbanModerationAction, TargetUserID: "user", BanID: "ban-1", TakenAt: now,
+	}, actions[3])
+
+	// 解除済みのブロックしか残っていない
+	_, err = app.UnbanUser(ctx, "user")
+	assert.Error(t, err)
+}
@@ -0,0 +1,150 @@
+package workspaceapp
+
+import (
+	"context"
+
+	"app.modules/core/utils"
+)
+
+// commandHandler はコマンドの検証・実行処理。
+// コマンド名や解析処理はutils.CommandSpecで定義し、ここではCommandTypeに対応する処理を登録する。
+// NOTE: utilsパッケージはworkspaceappに依存できないため、定義を分けている。
+type commandHandler struct {
+	// nilなら検証しない。
+	validate func(app *WorkspaceApp, command utils.CommandDetails) string
+	execute  func(app *WorkspaceApp, ctx context.Context, command *utils.CommandDetails) error
+}
+
+var commandHandlers = map[utils.CommandType]commandHandler{
+	utils.In: {
+		validate: (*WorkspaceApp).ValidateIn,
+		execute: func(app *WorkspaceApp, ctx context.Context, command *utils.CommandDetails) error {
+			return app.In(ctx, &command.InOption)
+		},
+	},
+	utils.Out: {
+		execute: func(app *WorkspaceApp, ctx context.Context, _ *utils.CommandDetails) error {
+			return app.Out(ctx)
+		},
+	},
+	utils.Undo: {
+		execute: func(app *WorkspaceApp, ctx context.Context, _ *utils.CommandDetails) error {
+			return app.Undo(ctx)
+		},
+	},
+	utils.Info: {
+		validate: (*WorkspaceApp).ValidateInfo,
+		execute: func(app *WorkspaceApp, ctx context.Context, command *utils.CommandDetails) error {
+			return app.ShowUserInfo(ctx, &command.InfoOption)
+		},
+	},
+	utils.My: {
+		validate: (*WorkspaceApp).ValidateMy,
+		execute: func(app *WorkspaceApp, ctx context.Context, command *utils.CommandDetails) error {
+			return app.My(ctx, command.MyOptions)
+		},
+	},
+	utils.Change: {
+		// seatStateに依存するためChange()の中で検証する。
+		execute: func(app *WorkspaceApp, ctx context.Context, command *utils.CommandDetails) error {
+			return app.Change(ctx, &command.ChangeOption)
+		},
+	},
+	utils.Seat: {
+		validate: (*WorkspaceApp).ValidateSeat,
+		execute: func(app *WorkspaceApp, ctx context.Context, command *utils.CommandDetails) error {
+			return app.ShowSeatInfo(ctx, &command.SeatOption)
+		},
+	},
+	utils.Report: {
+		validate: (*WorkspaceApp).ValidateReport,
+		execute: func(app *WorkspaceApp, ctx context.Context, command *utils.CommandDetails) error {
+			return app.Report(ctx, &command.ReportOption)
+		},
+	},
+	utils.Kick: {
+		validate: (*WorkspaceApp).ValidateKick,
+		execute: func(app *WorkspaceApp, ctx context.Context, command *utils.CommandDetails) error {
+			return app.Kick(ctx, &command.KickOption)
+		},
+	},
+	utils.Check: {
+		validate: (*WorkspaceApp).ValidateCheck,
+		execute: func(app *WorkspaceApp, ctx context.Context, command *utils.CommandDetails) error {
+			return app.Check(ctx, &command.CheckOption)
+		},
+	},
+	utils.Block: {
+		validate: (*WorkspaceApp).ValidateBlock,
+		execute: func(app *WorkspaceApp, ctx context.Context, command *utils.CommandDetails) error {
+			return app.Block(ctx, &command.BlockOption)
+		},
+	},
+	utils.Timeout: {
+		validate: (*WorkspaceApp).ValidateTimeout,
+		execute: func(app *WorkspaceApp, ctx context.Context, command *utils.CommandDetails) error {
+			return app.Timeout(ctx, &command.TimeoutOption)
+		},
+	},
+	utils.More: {
+		validate: (*WorkspaceApp).ValidateMore,
+		execute: func(app *WorkspaceApp, ctx context.Context, command *utils.CommandDetails) error {
+			return app.More(ctx, &command.MoreOption)
+		},
+	},
+	utils.Break: {
+		validate: (*WorkspaceApp).ValidateBreak,
+		execute: func(app *WorkspaceApp, ctx context.Context, command *utils.CommandDetails) error {
+			return app.Break(ctx, &command.BreakOption)
+		},
+	},
+	utils.Resume: {
+		validate: (*WorkspaceApp).ValidateResume,
+		execute: func(app *WorkspaceApp, ctx context.Context, command *utils.CommandDetails) error {
+			return app.Resume(ctx, &command.ResumeOption)
+		},
+	},
+	utils.Rank: {
+		execute: func(app *WorkspaceApp, ctx context.Context, command *utils.CommandDetails) error {
+			return app.Rank(ctx, command)
+		},
+	},
+	utils.Order: {
+		validate: (*WorkspaceApp).ValidateOrder,
+		execute: func(app *WorkspaceApp, ctx context.Context, command *utils.CommandDetails) error {
+			return app.Order(ctx, &command.OrderOption)
+		},
+	},
+	utils.Clear: {
+		execute: func(app *WorkspaceApp, ctx context.Context, _ *utils.CommandDetails) error {
+			return app.Clear(ctx)
+		},
+	},
+	utils.History: {
+		validate: (*WorkspaceApp).ValidateHistory,
+		execute: func(app *WorkspaceApp, ctx context.Context, command *utils.CommandDetails) error {
+			return app.History(ctx, &command.HistoryOption)
+		},
+	},
+	utils.Help: {
+		execute: func(app *WorkspaceApp, ctx context.Context, command *utils.CommandDetails) error {
+			return app.Help(ctx, &command.HelpOption)
+		},
+	},
+	utils.Reserve: {
+		validate: (*WorkspaceApp).ValidateReserve,
+		execute: func(app *WorkspaceApp, ctx context.Context, command *utils.CommandDetails) error {
+			return app.Reserve(ctx, &command.ReserveOption)
+		},
+	},
+	utils.Streak: {
+		execute: func(app *WorkspaceApp, ctx context.Context, _ *utils.CommandDetails) error {
+			return app.Streak(ctx)
+		},
+	},
+	utils.Quota: {
+		execute: func(app *WorkspaceApp, ctx context.Context, _ *utils.CommandDetails) error {
+			return app.Quota(ctx)
+		},
+	},
+}
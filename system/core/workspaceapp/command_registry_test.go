@@ -0,0 +1,20 @@
+package workspaceapp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"app.modules/core/utils"
+)
+
+func TestCommandHandlers(t *testing.T) {
+	// 登録されている全コマンドに実行処理があること
+	for _, spec := range utils.CommandSpecs() {
+		handler, ok := commandHandlers[spec.Type]
+		if assert.True(t, ok, "no handler for %s", spec.Names[0]) {
+			assert.NotNil(t, handler.execute, "no execute for %s", spec.Names[0])
+		}
+	}
+	assert.Len(t, commandHandlers, len(utils.CommandSpecs()))
+}
@@ -7,7 +7,8 @@ import (
 	"log/slog"
 	"time"
 
-	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	i18nmsg "app.modules/core/i18n/typed"
 	"app.modules/core/repository"
@@ -21,6 +22,7 @@ import (
 func (app *WorkspaceApp) In(ctx context.Context, inOption *utils.InOption) error {
 	jstNow := app.currentTime()
 	var replyMessage string
+	var streakMilestoneMessage string
 	result := usecase.Result{}
 	isTargetMemberSeat := inOption.IsMemberSeat
 
@@ -33,7 +35,7 @@ func (app *WorkspaceApp) In(ctx context.Context, inOption *utils.InOption) error
 		return nil
 	}
 
-	txErr := app.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+	txErr := app.RunTransaction(ctx, func(ctx context.Context, tx repository.Transaction) error {
 		// 席が指定されているか？
 		if inOption.IsSeatIDSet {
 			// 0番席だったら最小番号の空席に決定
@@ -55,6 +57,17 @@ func (app *WorkspaceApp) In(ctx context.Context, inOption *utils.InOption) error
 						return nil
 					}
 				}
+				// まもなく他のユーザーの予約時間となる席ではないか？
+				{
+					reservedSeatIDs, err := app.SeatIDsReservedByOthers(ctx, app.ProcessedUserID, isTargetMemberSeat, app.Configs.Constants.ReservationLeadMin)
+					if err != nil {
+						return fmt.Errorf("in app.SeatIDsReservedByOthers(): %w", err)
+					}
+					if reservedSeatIDs[inOption.SeatID] {
+						replyMessage = i18nmsg.CommandReserveSeatReserved(app.ProcessedUserDisplayName, presenter.SeatIDStr(inOption.SeatID, isTargetMemberSeat))
+						return nil
+					}
+				}
 				// ユーザーはその席に対して入室制限を受けてないか？
 				{
 					isTooMuch, err := app.CheckIfUserSittingTooMuchForSeat(ctx, app.ProcessedUserID, inOption.SeatID, isTargetMemberSeat)
@@ -107,7 +120,7 @@ func (app *WorkspaceApp) In(ctx context.Context, inOption *utils.InOption) error
 		var currentSeat repository.SeatDoc
 		if isInRoom { // 現在座っている席を取得
 			var err error
-			currentSeat, err = app.CurrentSeat(ctx, app.ProcessedUserID, isInMemberRoom)
+			currentSeat, err = app.CurrentSeat(ctx, tx, app.ProcessedUserID, isInMemberRoom)
 			if err != nil {
 				return fmt.Errorf("in CurrentSeat(): %w", err)
 			}
@@ -267,10 +280,26 @@ func (app *WorkspaceApp) In(ctx context.Context, inOption *utils.InOption) error
 				}
 			}
 
+			if inOption.MinWorkOrderOption.IsPomodoroSet {
+				currentSeat.SetPomodoro(inOption.MinWorkOrderOption.PomodoroWorkMin, inOption.MinWorkOrderOption.PomodoroBreakMin)
+				replyMessage += presenter.PomodoroSettingMessage(inOption.MinWorkOrderOption.PomodoroWorkMin, inOption.MinWorkOrderOption.PomodoroBreakMin)
+			}
+
 			if err := app.Repository.UpdateSeat(ctx, tx, currentSeat, isInMemberRoom); err != nil {
 				return fmt.Errorf("in UpdateSeat(): %w", err)
 			}
 		} else { // 入室のみ
+			// その日最初の入室で連続入室日数が節目に達したらお祝いする
+			if !timeutil.DateEqualJST(userDoc.LastEntered, jstNow) {
+				streakDays, err := utils.CalcStreakDaysAfterEntry(userDoc.IsContinuousActive, userDoc.CurrentActivityStateStarted, userDoc.LastEntered, userDoc.LastExited, jstNow)
+				if err != nil {
+					return fmt.Errorf("in CalcStreakDaysAfterEntry(): %w", err)
+				}
+				if utils.IsStreakMilestone(streakDays) {
+					streakMilestoneMessage = i18nmsg.CommandStreakMilestone(app.ProcessedUserDisplayName, streakDays)
+				}
+			}
+
 			untilExitMin, err := app.enterRoom(
 				ctx,
 				tx,
@@ -282,6 +311,8 @@ func (app *WorkspaceApp) In(ctx context.Context, inOption *utils.InOption) error
 				inOption.MinWorkOrderOption.WorkName,
 				"",
 				inOption.MinWorkOrderOption.DurationMin,
+				inOption.MinWorkOrderOption.PomodoroWorkMin,
+				inOption.MinWorkOrderOption.PomodoroBreakMin,
 				seatAppearance,
 				targetMenuItem.Code,
 				repository.WorkState,
@@ -293,10 +324,12 @@ func (app *WorkspaceApp) In(ctx context.Context, inOption *utils.InOption) error
 				return fmt.Errorf("in enterRoom(): %w", err)
 			}
 			result.Add(usecase.SeatEntered{
-				SeatID:       inOption.SeatID,
-				IsMemberSeat: isTargetMemberSeat,
-				WorkName:     inOption.MinWorkOrderOption.WorkName,
-				UntilExitMin: untilExitMin,
+				SeatID:           inOption.SeatID,
+				IsMemberSeat:     isTargetMemberSeat,
+				WorkName:         inOption.MinWorkOrderOption.WorkName,
+				UntilExitMin:     untilExitMin,
+				PomodoroWorkMin:  inOption.MinWorkOrderOption.PomodoroWorkMin,
+				PomodoroBreakMin: inOption.MinWorkOrderOption.PomodoroBreakMin,
 			})
 		}
 		return nil
@@ -308,12 +341,15 @@ func (app *WorkspaceApp) In(ctx context.Context, inOption *utils.InOption) error
 		replyMessage += presenter.BuildInMessage(result, app.ProcessedUserDisplayName)
 	}
 	app.MessageToLiveChat(ctx, replyMessage)
+	if txErr == nil && streakMilestoneMessage != "" {
+		app.MessageToLiveChat(ctx, streakMilestoneMessage)
+	}
 	return txErr
 }
 
 func (app *WorkspaceApp) Out(ctx context.Context) error {
 	var replyMessage string
-	txErr := app.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+	txErr := app.RunTransaction(ctx, func(ctx context.Context, tx repository.Transaction) error {
 		userDoc, err := app.Repository.ReadUser(ctx, tx, app.ProcessedUserID)
 		if err != nil {
 			return fmt.Errorf("in ReadUser(): %w", err)
@@ -335,7 +371,7 @@ func (app *WorkspaceApp) Out(ctx context.Context) error {
 		}
 
 		// 現在座っている席を特定
-		seat, err := app.CurrentSeat(ctx, app.ProcessedUserID, isInMemberRoom)
+		seat, err := app.CurrentSeat(ctx, tx, app.ProcessedUserID, isInMemberRoom)
 		if err != nil {
 			return fmt.Errorf("in CurrentSeat(): %w", err)
 		}
@@ -346,16 +382,42 @@ func (app *WorkspaceApp) Out(ctx context.Context) error {
 		}
 
 		// 退室処理
-		workedTimeSec, addedRP, err := app.exitRoom(ctx, tx, isInMemberRoom, seat, &userDoc, workSegments)
+		exit, err := app.exitRoomWithResult(ctx, tx, isInMemberRoom, seat, &userDoc, workSegments)
 		if err != nil {
-			return fmt.Errorf("in exitRoom(): %w", err)
+			return fmt.Errorf("in exitRoomWithResult(): %w", err)
 		}
+
+		// !undoで取り消せるように退室直前の状態を残す
+		if app.UndoExitGraceMin() > 0 {
+			addedDailyHistory := make([]repository.UndoableDailyWorkHistory, 0, len(exit.dailyWorkBreakSecs))
+			for _, daily := range exit.dailyWorkBreakSecs {
+				addedDailyHistory = append(addedDailyHistory, repository.UndoableDailyWorkHistory{
+					Date:     daily.Date,
+					WorkSec:  daily.WorkSec,
+					BreakSec: daily.BreakSec,
+				})
+			}
+			if err := app.Repository.SetUndoableExit(ctx, tx, repository.UndoableExitDoc{
+				UserID:             app.ProcessedUserID,
+				Seat:               seat,
+				IsMemberSeat:       isInMemberRoom,
+				ExitedAt:           exit.exitedAt,
+				PreviousLastExited: userDoc.LastExited,
+				AddedWorkSec:       exit.addedWorkedTimeSec,
+				AddedDailyWorkSec:  exit.addedDailyWorkedTimeSec,
+				AddedRP:            exit.addedRP,
+				AddedDailyHistory:  addedDailyHistory,
+			}); err != nil {
+				return fmt.Errorf("in SetUndoableExit(): %w", err)
+			}
+		}
+
 		var rpEarned string
 		if userDoc.RankVisible {
-			rpEarned = i18nmsg.CommandRpEarned(addedRP)
+			rpEarned = i18nmsg.CommandRpEarned(exit.addedRP)
 		}
 		seatIDStr := presenter.SeatIDStr(seat.SeatID, isInMemberRoom)
-		replyMessage = i18nmsg.CommandExit(app.ProcessedUserDisplayName, workedTimeSec/60, seatIDStr, rpEarned)
+		replyMessage = i18nmsg.CommandExit(app.ProcessedUserDisplayName, exit.addedWorkedTimeSec/60, seatIDStr, rpEarned)
 		return nil
 	})
 	if txErr != nil {
@@ -366,11 +428,264 @@ func (app *WorkspaceApp) Out(ctx context.Context) error {
 	return txErr
 }
 
+// Undo 直前の!outによる退室を取り消し、退室前の座席に戻す。
+// 退室時に加算した累計作業時間・RP・日ごとの作業履歴は差し引き、退室していた間も入室を続けていたものとして扱う。
+func (app *WorkspaceApp) Undo(ctx context.Context) error {
+	jstNow := app.currentTime()
+	var replyMessage string
+	txErr := app.RunTransaction(ctx, func(ctx context.Context, tx repository.Transaction) error {
+		undoableExit, err := app.Repository.ReadUndoableExit(ctx, tx, app.ProcessedUserID)
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				replyMessage = i18nmsg.CommandUndoNothing(app.ProcessedUserDisplayName)
+				return nil
+			}
+			return fmt.Errorf("in ReadUndoableExit(): %w", err)
+		}
+		userDoc, err := app.Repository.ReadUser(ctx, tx, app.ProcessedUserID)
+		if err != nil {
+			return fmt.Errorf("in ReadUser(): %w", err)
+		}
+
+		for _, isMemberRoom := range []bool{false, true} {
+			_, err := app.CurrentSeat(ctx, tx, app.ProcessedUserID, isMemberRoom)
+			if err == nil {
+				replyMessage = i18nmsg.CommandUndoAlreadyIn(app.ProcessedUserDisplayName)
+				return nil
+			}
+			if !errors.Is(err, studyspaceerror.ErrUserNotInTheRoom) {
+				return fmt.Errorf("in CurrentSeat(): %w", err)
+			}
+		}
+		// 退室後に入室し直していれば、最後の退室は!outによるものではない
+		if userDoc.LastEntered.After(undoableExit.ExitedAt) {
+			replyMessage = i18nmsg.CommandUndoNothing(app.ProcessedUserDisplayName)
+			return nil
+		}
+		graceMin := app.UndoExitGraceMin()
+		if graceMin <= 0 {
+			replyMessage = i18nmsg.CommandUndoNothing(app.ProcessedUserDisplayName)
+			return nil
+		}
+		if jstNow.Sub(undoableExit.ExitedAt) > time.Duration(graceMin)*time.Minute {
+			replyMessage = i18nmsg.CommandUndoExpired(app.ProcessedUserDisplayName, graceMin)
+			return nil
+		}
+
+		seat := undoableExit.Seat
+		isMemberSeat := undoableExit.IsMemberSeat
+		seatIDStr := presenter.SeatIDStr(seat.SeatID, isMemberSeat)
+		isVacant, err := app.IfSeatVacant(ctx, tx, seat.SeatID, isMemberSeat)
+		if err != nil {
+			return fmt.Errorf("in IfSeatVacant(): %w", err)
+		}
+		if !isVacant {
+			replyMessage = i18nmsg.CommandUndoSeatTaken(app.ProcessedUserDisplayName, seatIDStr, utils.InCommand)
+			return nil
+		}
+		// 退室していた間に他のユーザーが予約していないか？
+		reservedSeatIDs, err := app.SeatIDsReservedByOthers(ctx, app.ProcessedUserID, isMemberSeat, app.Configs.Constants.ReservationLeadMin)
+		if err != nil {
+			return fmt.Errorf("in SeatIDsReservedByOthers(): %w", err)
+		}
+		if reservedSeatIDs[seat.SeatID] {
+			replyMessage = i18nmsg.CommandReserveSeatReserved(app.ProcessedUserDisplayName, seatIDStr)
+			return nil
+		}
+		// ユーザーはその席に対して入室制限を受けてないか？
+		isTooMuch, err := app.CheckIfUserSittingTooMuchForSeat(ctx, app.ProcessedUserID, seat.SeatID, isMemberSeat)
+		if err != nil {
+			return fmt.Errorf("in CheckIfUserSittingTooMuchForSeat(): %w", err)
+		}
+		if isTooMuch {
+			replyMessage = i18nmsg.CommandInNoAvailability(app.ProcessedUserDisplayName, utils.InCommand)
+			return nil
+		}
+
+		// 座席を戻す。退室時刻までのセグメントは記録済みなので、次のセグメントは退室時刻から始める
+		seat.CurrentSegmentStartedAt = undoableExit.ExitedAt
+		if err := app.Repository.CreateSeat(tx, seat, isMemberSeat); err != nil {
+			return fmt.Errorf("in CreateSeat(): %w", err)
+		}
+
+		// 退室時に加算した値を差し引く。日付が変わっていれば当日の累計作業時間はリセット済みなのでそのままにする
+		newTotalSec := max(userDoc.TotalStudySec-undoableExit.AddedWorkSec, 0)
+		newDailyTotalSec := userDoc.DailyTotalStudySec
+		if timeutil.StartOfDayJST(undoableExit.ExitedAt).Equal(timeutil.StartOfDayJST(jstNow)) {
+			newDailyTotalSec = max(newDailyTotalSec-undoableExit.AddedDailyWorkSec, 0)
+		}
+		if err := app.Repository.UpdateUserTotalTime(tx, app.ProcessedUserID, newTotalSec, newDailyTotalSec); err != nil {
+			return fmt.Errorf("in UpdateUserTotalTime(): %w", err)
+		}
+		if err := app.Repository.UpdateUserRankPoint(tx, app.ProcessedUserID, max(userDoc.RankPoint-undoableExit.AddedRP, 0)); err != nil {
+			return fmt.Errorf("in UpdateUserRankPoint(): %w", err)
+		}
+		for _, daily := range undoableExit.AddedDailyHistory {
+			if err := app.Repository.AddDailyUserWorkHistory(ctx, tx, app.ProcessedUserID, daily.Date, -daily.WorkSec, -daily.BreakSec); err != nil {
+				return fmt.Errorf("in AddDailyUserWorkHistory(): %w", err)
+			}
+		}
+		if err := app.Repository.UpdateUserLastExitedDate(tx, app.ProcessedUserID, undoableExit.PreviousLastExited); err != nil {
+			return fmt.Errorf("in UpdateUserLastExitedDate(): %w", err)
+		}
+		// 同座席入室制限の集計で入室・退室の対応がずれないように、入室として記録する
+		enterActivity := repository.UserActivityDoc{
+			UserID:       app.ProcessedUserID,
+			ActivityType: repository.EnterRoomActivity,
+			SeatID:       seat.SeatID,
+			IsMemberSeat: isMemberSeat,
+			TakenAt:      jstNow,
+		}
+		if err := app.Repository.CreateUserActivityDoc(ctx, tx, enterActivity); err != nil {
+			return fmt.Errorf("in CreateUserActivityDoc(): %w", err)
+		}
+		if err := app.Repository.DeleteUndoableExit(ctx, tx, app.ProcessedUserID); err != nil {
+			return fmt.Errorf("in DeleteUndoableExit(): %w", err)
+		}
+
+		replyMessage = i18nmsg.CommandUndoRestored(app.ProcessedUserDisplayName, seatIDStr)
+		return nil
+	})
+	if txErr != nil {
+		slog.Error("txErr in Undo()", "txErr", txErr)
+		replyMessage = i18nmsg.CommandError(app.ProcessedUserDisplayName)
+	}
+	app.MessageToLiveChat(ctx, replyMessage)
+	return txErr
+}
+
+// Reserve は指定した時刻からの座席を予約する。予約時刻を過ぎていれば翌日の予約とする。
+func (app *WorkspaceApp) Reserve(ctx context.Context, reserveOption *utils.ReserveOption) error {
+	if reserveOption.IsCancel {
+		return app.CancelReservation(ctx, reserveOption)
+	}
+	jstNow := app.currentTime()
+	isTargetMemberSeat := reserveOption.IsTargetMemberSeat
+
+	if isTargetMemberSeat && !app.ProcessedUserIsMember {
+		if app.Configs.Constants.YoutubeMembershipEnabled {
+			app.MessageToLiveChat(ctx, i18nmsg.CommandInMemberSeatForbidden(app.ProcessedUserDisplayName))
+		} else {
+			app.MessageToLiveChat(ctx, i18nmsg.CommandInMembershipDisabled(app.ProcessedUserDisplayName))
+		}
+		return nil
+	}
+	maxPerUser := app.Configs.Constants.ReservationMaxPerUser
+	if maxPerUser == 0 {
+		app.MessageToLiveChat(ctx, i18nmsg.CommandReserveDisabled(app.ProcessedUserDisplayName))
+		return nil
+	}
+
+	startAt := time.Date(jstNow.Year(), jstNow.Month(), jstNow.Day(), reserveOption.Hour, reserveOption.Minute, 0, 0, timeutil.JapanLocation())
+	if !startAt.After(jstNow) {
+		startAt = startAt.AddDate(0, 0, 1)
+	}
+	durationMin := app.Configs.Constants.DefaultWorkTimeMin
+	if reserveOption.IsDurationMinSet {
+		durationMin = reserveOption.DurationMin
+	}
+	until := startAt.Add(time.Duration(durationMin) * time.Minute)
+	seatIDStr := presenter.SeatIDStr(reserveOption.SeatID, isTargetMemberSeat)
+
+	var replyMessage string
+	txErr := app.RunTransaction(ctx, func(ctx context.Context, tx repository.Transaction) error {
+		isExist, err := app.IsSeatExist(ctx, reserveOption.SeatID, isTargetMemberSeat)
+		if err != nil {
+			return fmt.Errorf("in IsSeatExist(): %w", err)
+		}
+		if !isExist {
+			replyMessage = i18nmsg.CommandReserveNoSeat(app.ProcessedUserDisplayName, seatIDStr)
+			return nil
+		}
+
+		userReservations, err := app.Repository.ReadSeatReservationsWithUserID(ctx, tx, app.ProcessedUserID, isTargetMemberSeat)
+		if err != nil {
+			return fmt.Errorf("in ReadSeatReservationsWithUserID(): %w", err)
+		}
+		if len(userReservations) >= maxPerUser {
+			replyMessage = i18nmsg.CommandReserveLimit(app.ProcessedUserDisplayName, maxPerUser)
+			return nil
+		}
+
+		seatReservations, err := app.Repository.ReadSeatReservationsWithSeatID(ctx, tx, reserveOption.SeatID, isTargetMemberSeat)
+		if err != nil {
+			return fmt.Errorf("in ReadSeatReservationsWithSeatID(): %w", err)
+		}
+		for _, reservation := range seatReservations {
+			if reservation.StartAt.Before(until) && startAt.Before(reservation.Until) {
+				replyMessage = i18nmsg.CommandReserveConflict(app.ProcessedUserDisplayName, seatIDStr)
+				return nil
+			}
+		}
+
+		// 以下書き込みのみ
+
+		reservation := repository.SeatReservationDoc{
+			UserID:          app.ProcessedUserID,
+			UserDisplayName: app.ProcessedUserDisplayName,
+			SeatID:          reserveOption.SeatID,
+			StartAt:         startAt,
+			Until:           until,
+			CreatedAt:       jstNow,
+		}
+		if err := app.Repository.CreateSeatReservation(ctx, tx, reservation, isTargetMemberSeat); err != nil {
+			return fmt.Errorf("in CreateSeatReservation(): %w", err)
+		}
+
+		replyMessage = i18nmsg.CommandReserveReserved(app.ProcessedUserDisplayName, seatIDStr, startAt.Format(utils.ReserveStartAtLayout), durationMin)
+		return nil
+	})
+	if txErr != nil {
+		slog.Error("txErr in Reserve()", "txErr", txErr)
+		replyMessage = i18nmsg.CommandError(app.ProcessedUserDisplayName)
+	}
+	app.MessageToLiveChat(ctx, replyMessage)
+	return txErr
+}
+
+// CancelReservation は自分の座席予約を取り消す。席番号の指定がなければすべての予約を取り消す。
+// メンバーでなくなった後や予約の受付を停止した後でも、残っている予約は取り消せるようにする。
+func (app *WorkspaceApp) CancelReservation(ctx context.Context, reserveOption *utils.ReserveOption) error {
+	isTargetMemberSeat := reserveOption.IsTargetMemberSeat
+	var replyMessage string
+	txErr := app.RunTransaction(ctx, func(ctx context.Context, tx repository.Transaction) error {
+		userReservations, err := app.Repository.ReadSeatReservationsWithUserID(ctx, tx, app.ProcessedUserID, isTargetMemberSeat)
+		if err != nil {
+			return fmt.Errorf("in ReadSeatReservationsWithUserID(): %w", err)
+		}
+
+		// 以下書き込みのみ
+
+		canceledCount := 0
+		for _, reservation := range userReservations {
+			if reserveOption.SeatID != 0 && reservation.SeatID != reserveOption.SeatID {
+				continue
+			}
+			if err := app.Repository.DeleteSeatReservation(ctx, tx, reservation.ReservationID, isTargetMemberSeat); err != nil {
+				return fmt.Errorf("in DeleteSeatReservation(): %w", err)
+			}
+			canceledCount++
+		}
+		if canceledCount == 0 {
+			replyMessage = i18nmsg.CommandReserveNoReservation(app.ProcessedUserDisplayName)
+			return nil
+		}
+		replyMessage = i18nmsg.CommandReserveCanceled(app.ProcessedUserDisplayName, canceledCount)
+		return nil
+	})
+	if txErr != nil {
+		slog.Error("txErr in CancelReservation()", "txErr", txErr)
+		replyMessage = i18nmsg.CommandError(app.ProcessedUserDisplayName)
+	}
+	app.MessageToLiveChat(ctx, replyMessage)
+	return txErr
+}
+
 func (app *WorkspaceApp) ShowSeatInfo(ctx context.Context, seatOption *utils.SeatOption) error {
 	jstNow := app.currentTime()
 	showDetails := seatOption.ShowDetails
 	var replyMessage string
-	txErr := app.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+	txErr := app.RunTransaction(ctx, func(ctx context.Context, tx repository.Transaction) error {
 		// そのユーザーは入室しているか？
 		isInMemberRoom, isInGeneralRoom, err := app.IsUserInRoom(ctx, app.ProcessedUserID)
 		if err != nil {
@@ -378,7 +693,7 @@ func (app *WorkspaceApp) ShowSeatInfo(ctx context.Context, seatOption *utils.Sea
 		}
 		isInRoom := isInMemberRoom || isInGeneralRoom
 		if isInRoom {
-			currentSeat, err := app.CurrentSeat(ctx, app.ProcessedUserID, isInMemberRoom)
+			currentSeat, err := app.CurrentSeat(ctx, tx, app.ProcessedUserID, isInMemberRoom)
 			if err != nil {
 				return fmt.Errorf("in app.CurrentSeat(): %w", err)
 			}
@@ -427,7 +742,7 @@ func (app *WorkspaceApp) Change(ctx context.Context, changeOption *utils.MinWork
 	jstNow := app.currentTime()
 	replyMessage := ""
 	var result usecase.Result
-	txErr := app.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+	txErr := app.RunTransaction(ctx, func(ctx context.Context, tx repository.Transaction) error {
 		// そのユーザーは入室中か？
 		isInMemberRoom, isInGeneralRoom, err := app.IsUserInRoom(ctx, app.ProcessedUserID)
 		if err != nil {
@@ -441,7 +756,7 @@ func (app *WorkspaceApp) Change(ctx context.Context, changeOption *utils.MinWork
 			return nil
 		}
 
-		currentSeat, err := app.CurrentSeat(ctx, app.ProcessedUserID, isInMemberRoom)
+		currentSeat, err := app.CurrentSeat(ctx, tx, app.ProcessedUserID, isInMemberRoom)
 		if err != nil {
 			return fmt.Errorf("failed app.CurrentSeat(): %w", err)
 		}
@@ -540,6 +855,14 @@ func (app *WorkspaceApp) Change(ctx context.Context, changeOption *utils.MinWork
 				}
 			}
 		}
+		// 作業時間を変えた場合もポモドーロの作業の終了時刻を計算し直すため、最後に設定する
+		if changeOption.IsPomodoroSet {
+			currentSeat.SetPomodoro(changeOption.PomodoroWorkMin, changeOption.PomodoroBreakMin)
+			result.Add(usecase.ChangePomodoroUpdated{
+				WorkMin:  changeOption.PomodoroWorkMin,
+				BreakMin: changeOption.PomodoroBreakMin,
+			})
+		}
 		if err := app.Repository.UpdateSeat(ctx, tx, currentSeat, isInMemberRoom); err != nil {
 			return fmt.Errorf("in UpdateSeat: %w", err)
 		}
@@ -560,7 +883,7 @@ func (app *WorkspaceApp) Change(ctx context.Context, changeOption *utils.MinWork
 func (app *WorkspaceApp) More(ctx context.Context, moreOption *utils.MoreOption) error {
 	replyMessage := ""
 	var result usecase.Result
-	txErr := app.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+	txErr := app.RunTransaction(ctx, func(ctx context.Context, tx repository.Transaction) error {
 		jstNow := app.currentTime()
 
 		// 入室しているか？
@@ -574,7 +897,7 @@ func (app *WorkspaceApp) More(ctx context.Context, moreOption *utils.MoreOption)
 			return nil
 		}
 
-		currentSeat, err := app.CurrentSeat(ctx, app.ProcessedUserID, isInMemberRoom)
+		currentSeat, err := app.CurrentSeat(ctx, tx, app.ProcessedUserID, isInMemberRoom)
 		if err != nil {
 			return fmt.Errorf("failed app.CurrentSeat(): %w", err)
 		}
@@ -674,7 +997,7 @@ func (app *WorkspaceApp) More(ctx context.Context, moreOption *utils.MoreOption)
 func (app *WorkspaceApp) Break(ctx context.Context, breakOption *utils.MinWorkOrderOption) error {
 	replyMessage := ""
 	var result usecase.Result
-	txErr := app.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+	txErr := app.RunTransaction(ctx, func(ctx context.Context, tx repository.Transaction) error {
 		jstNow := app.currentTime()
 		// 入室しているか？
 		isInMemberRoom, isInGeneralRoom, err := app.IsUserInRoom(ctx, app.ProcessedUserID)
@@ -688,7 +1011,7 @@ func (app *WorkspaceApp) Break(ctx context.Context, breakOption *utils.MinWorkOr
 		}
 
 		// stateを確認
-		currentSeat, err := app.CurrentSeat(ctx, app.ProcessedUserID, isInMemberRoom)
+		currentSeat, err := app.CurrentSeat(ctx, tx, app.ProcessedUserID, isInMemberRoom)
 		if err != nil {
 			return fmt.Errorf("failed app.CurrentSeat(): %w", err)
 		}
@@ -769,7 +1092,7 @@ func (app *WorkspaceApp) Break(ctx context.Context, breakOption *utils.MinWorkOr
 func (app *WorkspaceApp) Resume(ctx context.Context, resumeOption *utils.WorkNameOption) error {
 	replyMessage := ""
 	var result usecase.Result
-	txErr := app.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+	txErr := app.RunTransaction(ctx, func(ctx context.Context, tx repository.Transaction) error {
 		// 入室しているか？
 		isInMemberRoom, isInGeneralRoom, err := app.IsUserInRoom(ctx, app.ProcessedUserID)
 		if err != nil {
@@ -782,7 +1105,7 @@ func (app *WorkspaceApp) Resume(ctx context.Context, resumeOption *utils.WorkNam
 		}
 
 		// stateを確認
-		currentSeat, err := app.CurrentSeat(ctx, app.ProcessedUserID, isInMemberRoom)
+		currentSeat, err := app.CurrentSeat(ctx, tx, app.ProcessedUserID, isInMemberRoom)
 		if err != nil {
 			return fmt.Errorf("failed app.CurrentSeat(): %w", err)
 		}
@@ -858,7 +1181,7 @@ func (app *WorkspaceApp) Resume(ctx context.Context, resumeOption *utils.WorkNam
 func (app *WorkspaceApp) Order(ctx context.Context, orderOption *utils.OrderOption) error {
 	replyMessage := ""
 	var result usecase.Result
-	txErr := app.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+	txErr := app.RunTransaction(ctx, func(ctx context.Context, tx repository.Transaction) error {
 		jstNow := app.currentTime()
 
 		// 入室しているか？
@@ -886,7 +1209,7 @@ func (app *WorkspaceApp) Order(ctx context.Context, orderOption *utils.OrderOpti
 			}
 		}
 
-		currentSeat, err := app.CurrentSeat(ctx, app.ProcessedUserID, isInMemberRoom)
+		currentSeat, err := app.CurrentSeat(ctx, tx, app.ProcessedUserID, isInMemberRoom)
 		if err != nil {
 			return fmt.Errorf("failed app.CurrentSeat(): %w", err)
 		}
@@ -949,7 +1272,7 @@ func (app *WorkspaceApp) Clear(ctx context.Context) error {
 	jstNow := app.currentTime()
 	replyMessage := ""
 	var result usecase.Result
-	txErr := app.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+	txErr := app.RunTransaction(ctx, func(ctx context.Context, tx repository.Transaction) error {
 		// 入室しているか？
 		isInMemberRoom, isInGeneralRoom, err := app.IsUserInRoom(ctx, app.ProcessedUserID)
 		if err != nil {
@@ -961,7 +1284,7 @@ func (app *WorkspaceApp) Clear(ctx context.Context) error {
 			return nil
 		}
 
-		seat, err := app.CurrentSeat(ctx, app.ProcessedUserID, isInMemberRoom)
+		seat, err := app.CurrentSeat(ctx, tx, app.ProcessedUserID, isInMemberRoom)
 		if err != nil {
 			return fmt.Errorf("failed app.CurrentSeat(): %w", err)
 		}
@@ -7,7 +7,6 @@ import (
 	"testing"
 	"time"
 
-	"cloud.google.com/go/firestore"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
 	"google.golang.org/grpc/codes"
@@ -38,7 +37,9 @@ func TestSystem_In(t *testing.T) {
 		currentSeatOfUser             *repository.SeatDoc
 		currentSeatDeleted            bool
 		seatMoved                     bool
+		continuousActiveSince         time.Time // ゼロ値なら連続入室中ではない
 		expectedReplyMessage          string
+		expectedStreakMessage         string
 	}{
 		{
 			name: "一般席入室",
@@ -280,6 +281,28 @@ func TestSystem_In(t *testing.T) {
 			seatMoved:            false,
 			expectedReplyMessage: "@テストユーザー さんが作業を始めました🔥（作業内容：\"\"、最大60分、1番席）",
 		},
+		{
+			name: "連続入室7日目の入室",
+			constantsConfig: repository.ConstantsConfigDoc{
+				MaxSeats: 10,
+			},
+			commandDetails: utils.CommandDetails{
+				CommandType: utils.In,
+				InOption: utils.InOption{
+					IsSeatIDSet: true,
+					SeatID:      1,
+					MinWorkOrderOption: &utils.MinWorkOrderOption{
+						IsWorkNameSet:    true,
+						IsDurationMinSet: true,
+						DurationMin:      30,
+						WorkName:         "テスト作業",
+					},
+				},
+			},
+			continuousActiveSince: fixedNow.AddDate(0, 0, -6),
+			expectedReplyMessage:  "@テストユーザー さんが作業を始めました🔥（作業内容：\"テスト作業\"、最大30分、1番席）",
+			expectedStreakMessage: "🎉@テストユーザー さん、連続入室7日を達成しました！おめでとうございます🎉",
+		},
 	}
 
 	for _, tt := range inTestCases {
@@ -306,12 +329,18 @@ func TestSystem_In(t *testing.T) {
 				Return([]repository.UserActivityDoc{}, nil).AnyTimes()
 			mockDB.EXPECT().GetExitRoomUserActivityDocIDsAfterDateForUserAndSeat(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 				Return([]repository.UserActivityDoc{}, nil).AnyTimes()
-			mockDB.EXPECT().ReadUser(gomock.Any(), gomock.Any(), "test_user_id").
-				Return(repository.UserDoc{
-					DefaultStudyMin:    100,
-					RankVisible:        false,
-					IsContinuousActive: false,
-				}, nil).AnyTimes()
+			userDoc := repository.UserDoc{
+				DefaultStudyMin:    100,
+				RankVisible:        false,
+				IsContinuousActive: false,
+			}
+			if !tt.continuousActiveSince.IsZero() {
+				userDoc.IsContinuousActive = true
+				userDoc.CurrentActivityStateStarted = tt.continuousActiveSince
+				userDoc.LastEntered = fixedNow.AddDate(0, 0, -1)
+				userDoc.LastExited = fixedNow.AddDate(0, 0, -1).Add(time.Hour)
+			}
+			mockDB.EXPECT().ReadUser(gomock.Any(), gomock.Any(), "test_user_id").Return(userDoc, nil).AnyTimes()
 			if tt.currentSeatOfUser != nil {
 				mockDB.EXPECT().ReadSeatWithUserID(gomock.Any(), "test_user_id", tt.currentSeatOfUserIsMemberSeat).
 					Return(*tt.currentSeatOfUser, nil).AnyTimes()
@@ -328,16 +357,14 @@ func TestSystem_In(t *testing.T) {
 			mockDB.EXPECT().UpdateUserLastPenaltyImposedDays(gomock.Any(), gomock.Any(), "test_user_id", 0).Return(nil).AnyTimes()
 			mockDB.EXPECT().ReadGeneralSeats(gomock.Any()).Return([]repository.SeatDoc{}, nil).AnyTimes()
 			mockDB.EXPECT().ReadMemberSeats(gomock.Any()).Return([]repository.SeatDoc{}, nil).AnyTimes()
+			mockDB.EXPECT().ReadSeatReservationsStartBefore(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]repository.SeatReservationDoc{}, nil).AnyTimes()
 			mockDB.EXPECT().ReadWorkStateSegmentsBySessionID(gomock.Any(), gomock.Any()).Return([]repository.WorkSegmentDoc{}, nil).AnyTimes()
-			mockFirestoreClient := mock_repository.NewMockDBClient(ctrl)
-			mockFirestoreClient.EXPECT().RunTransaction(gomock.Any(), gomock.Any()).
+			mockDB.EXPECT().RunTransaction(gomock.Any(), gomock.Any()).
 				DoAndReturn(
-					func(ctx context.Context, f func(context.Context, *firestore.Transaction) error, opts ...firestore.TransactionOption) error {
-						tx := &firestore.Transaction{}
-						return f(ctx, tx)
+					func(ctx context.Context, f func(context.Context, repository.Transaction) error) error {
+						return f(ctx, nil)
 					},
 				).AnyTimes()
-			mockDB.EXPECT().FirestoreClient().Return(mockFirestoreClient).AnyTimes()
 			if tt.currentSeatOfUser != nil && !tt.seatMoved {
 				mockDB.EXPECT().UpdateSeat(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
 			}
@@ -347,17 +374,21 @@ func TestSystem_In(t *testing.T) {
 				mockDB.EXPECT().UpdateUserTotalTime(gomock.Any(), "test_user_id", gomock.Any(), gomock.Any()).Return(nil).Times(1)
 				mockDB.EXPECT().UpdateUserRankPoint(gomock.Any(), "test_user_id", gomock.Any()).Return(nil).Times(1)
 				mockDB.EXPECT().CreateWorkSegmentDoc(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				mockDB.EXPECT().AddDailyUserWorkHistory(gomock.Any(), gomock.Any(), "test_user_id", gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
 			}
 
 			mockLiveChatBot := mock_youtubebot.NewMockLiveChatBot(ctrl)
 			mockLiveChatBot.EXPECT().PostMessage(gomock.Any(), tt.expectedReplyMessage).Return(nil).Times(1)
+			if tt.expectedStreakMessage != "" {
+				mockLiveChatBot.EXPECT().PostMessage(gomock.Any(), tt.expectedStreakMessage).Return(nil).Times(1)
+			}
 
 			app := WorkspaceApp{
 				Configs: &Configs{
 					Constants: tt.constantsConfig,
 				},
 				Repository:               mockDB,
-				LiveChatBot:              mockLiveChatBot,
+				ChatSources:              youtubeChatSources(mockLiveChatBot),
 				alertOwnerBot:            moderatorbot.DummyMessageBot{},
 				ProcessedUserID:          "test_user_id",
 				ProcessedUserDisplayName: "テストユーザー",
@@ -407,20 +438,27 @@ func TestSystem_Out(t *testing.T) {
 			userIsMember:         true,
 			expectedReplyMessage: "@テストユーザー さんが退室しました🚪 （+ 0分、VIP1番席）",
 		},
+		{
+			name: "退室の取り消しが無効",
+			constantsConfig: repository.ConstantsConfigDoc{
+				UndoExitGraceMin: -1,
+			},
+			commandDetails: utils.CommandDetails{
+				CommandType: utils.Out,
+			},
+			expectedReplyMessage: "@テストユーザー さんが退室しました🚪 （+ 0分、1番席）",
+		},
 	}
 
 	for _, tt := range outTestCases {
 		t.Run(tt.name, func(t *testing.T) {
 			mockDB := mock_repository.NewMockRepository(ctrl)
-			mockFirestoreClient := mock_repository.NewMockDBClient(ctrl)
-			mockFirestoreClient.EXPECT().RunTransaction(gomock.Any(), gomock.Any()).
+			mockDB.EXPECT().RunTransaction(gomock.Any(), gomock.Any()).
 				DoAndReturn(
-					func(ctx context.Context, f func(context.Context, *firestore.Transaction) error, opts ...firestore.TransactionOption) error {
-						tx := &firestore.Transaction{}
-						return f(ctx, tx)
+					func(ctx context.Context, f func(context.Context, repository.Transaction) error) error {
+						return f(ctx, nil)
 					},
 				).AnyTimes()
-			mockDB.EXPECT().FirestoreClient().Return(mockFirestoreClient).AnyTimes()
 			mockDB.EXPECT().ReadUser(gomock.Any(), gomock.Any(), "test_user_id").Return(repository.UserDoc{}, nil).AnyTimes()
 			mockDB.EXPECT().ReadSeatWithUserID(gomock.Any(), "test_user_id", tt.userIsMember).Return(repository.SeatDoc{
 				SeatID:                  1,
@@ -435,13 +473,30 @@ func TestSystem_Out(t *testing.T) {
 			mockDB.EXPECT().UpdateUserTotalTime(gomock.Any(), "test_user_id", gomock.Any(), gomock.Any()).Return(nil).Times(1)
 			mockDB.EXPECT().UpdateUserRankPoint(gomock.Any(), "test_user_id", gomock.Any()).Return(nil).Times(1)
 			mockDB.EXPECT().CreateWorkSegmentDoc(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+			mockDB.EXPECT().AddDailyUserWorkHistory(gomock.Any(), gomock.Any(), "test_user_id", gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+			setUndoableExitTimes := 1
+			if tt.constantsConfig.UndoExitGraceMin < 0 {
+				setUndoableExitTimes = 0
+			}
+			mockDB.EXPECT().SetUndoableExit(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, _ repository.Transaction, undoableExit repository.UndoableExitDoc) error {
+					assert.Equal(t, "test_user_id", undoableExit.UserID)
+					assert.Equal(t, 1, undoableExit.Seat.SeatID)
+					assert.Equal(t, tt.userIsMember, undoableExit.IsMemberSeat)
+					assert.Equal(t, fixedNow, undoableExit.ExitedAt)
+					return nil
+				},
+			).Times(setUndoableExitTimes)
 
 			mockLiveChatBot := mock_youtubebot.NewMockLiveChatBot(ctrl)
 			mockLiveChatBot.EXPECT().PostMessage(gomock.Any(), tt.expectedReplyMessage).Return(nil).Times(1)
 
 			app := WorkspaceApp{
+				Configs: &Configs{
+					Constants: tt.constantsConfig,
+				},
 				Repository:               mockDB,
-				LiveChatBot:              mockLiveChatBot,
+				ChatSources:              youtubeChatSources(mockLiveChatBot),
 				alertOwnerBot:            moderatorbot.DummyMessageBot{},
 				ProcessedUserID:          "test_user_id",
 				ProcessedUserDisplayName: "テストユーザー",
@@ -460,6 +515,445 @@ func TestSystem_Out(t *testing.T) {
 	}
 }
 
+func TestSystem_Undo(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	fixedNow := time.Date(2026, time.January, 1, 10, 0, 0, 0, timeutil.JapanLocation())
+	exitedAt := fixedNow.Add(-2 * time.Minute)
+	previousSeat := repository.SeatDoc{
+		SeatID:                  3,
+		UserID:                  "test_user_id",
+		SessionID:               "session",
+		WorkName:                "英語",
+		MenuCode:                "coffee",
+		State:                   repository.WorkState,
+		EnteredAt:               fixedNow.Add(-time.Hour),
+		Until:                   fixedNow.Add(time.Hour),
+		CurrentStateStartedAt:   fixedNow.Add(-time.Hour),
+		CurrentSegmentStartedAt: fixedNow.Add(-time.Hour),
+	}
+	undoableExit := repository.UndoableExitDoc{
+		UserID:             "test_user_id",
+		Seat:               previousSeat,
+		ExitedAt:           exitedAt,
+		PreviousLastExited: fixedNow.Add(-24 * time.Hour),
+		AddedWorkSec:       58 * 60,
+		AddedDailyWorkSec:  58 * 60,
+		AddedRP:            100,
+		AddedDailyHistory: []repository.UndoableDailyWorkHistory{
+			{Date: timeutil.StartOfDayJST(exitedAt), WorkSec: 58 * 60},
+		},
+	}
+	userDoc := repository.UserDoc{
+		TotalStudySec:      10 * 3600,
+		DailyTotalStudySec: 2 * 3600,
+		RankPoint:          1000,
+		LastEntered:        previousSeat.EnteredAt,
+		LastExited:         exitedAt,
+	}
+
+	undoTestCases := []struct {
+		name                 string
+		graceMin             int
+		undoableExit         *repository.UndoableExitDoc // nilなら取り消せる退室なし
+		userDoc              repository.UserDoc
+		seatTaken            bool
+		seatReserved         bool // 他のユーザーが予約している
+		seatLimited          bool // 長時間入室制限のブラックリストに入っている
+		willRestore          bool
+		expectedReplyMessage string
+	}{
+		{
+			name:                 "退室を取り消す",
+			graceMin:             5,
+			undoableExit:         &undoableExit,
+			userDoc:              userDoc,
+			willRestore:          true,
+			expectedReplyMessage: "@テストユーザー さん、退室を取り消して3番席に戻りました🔙",
+		},
+		{
+			name:                 "取り消せる退室がない",
+			graceMin:             5,
+			userDoc:              userDoc,
+			expectedReplyMessage: "@テストユーザー さん、取り消せる退室はありません🙏",
+		},
+		{
+			name:                 "猶予時間を過ぎている",
+			graceMin:             1,
+			undoableExit:         &undoableExit,
+			userDoc:              userDoc,
+			expectedReplyMessage: "@テストユーザー さん、退室から1分以上経ったため取り消せません⌛",
+		},
+		{
+			name:         "退室後に入室し直している",
+			graceMin:     5,
+			undoableExit: &undoableExit,
+			userDoc: repository.UserDoc{
+				LastEntered: fixedNow.Add(-time.Minute),
+				LastExited:  fixedNow.Add(-30 * time.Second),
+			},
+			expectedReplyMessage: "@テストユーザー さん、取り消せる退室はありません🙏",
+		},
+		{
+			name:                 "席が使われている",
+			graceMin:             5,
+			undoableExit:         &undoableExit,
+			userDoc:              userDoc,
+			seatTaken:            true,
+			expectedReplyMessage: "@テストユーザー さん、3番席はすでに使われているため取り消せません。「!in」コマンドで入室してください🪑",
+		},
+		{
+			name:                 "席が他のユーザーに予約されている",
+			graceMin:             5,
+			undoableExit:         &undoableExit,
+			userDoc:              userDoc,
+			seatReserved:         true,
+			expectedReplyMessage: "@テストユーザー さん、3番席はまもなく予約の時間です。別の席を指定してください📅",
+		},
+		{
+			name:                 "席に長時間入室制限がかかっている",
+			graceMin:             5,
+			undoableExit:         &undoableExit,
+			userDoc:              userDoc,
+			seatLimited:          true,
+			expectedReplyMessage: "@テストユーザー さん、その番号の席は長時間入室制限のためしばらく使えません。他の空いている席を選ぶか、「!in」で席を指定せずに入室してください⏳",
+		},
+		{
+			name:                 "猶予時間が未設定ならデフォルト値",
+			graceMin:             0,
+			undoableExit:         &undoableExit,
+			userDoc:              userDoc,
+			willRestore:          true,
+			expectedReplyMessage: "@テストユーザー さん、退室を取り消して3番席に戻りました🔙",
+		},
+		{
+			name:                 "取り消しが無効",
+			graceMin:             -1,
+			undoableExit:         &undoableExit,
+			userDoc:              userDoc,
+			expectedReplyMessage: "@テストユーザー さん、取り消せる退室はありません🙏",
+		},
+	}
+
+	for _, tt := range undoTestCases {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := mock_repository.NewMockRepository(ctrl)
+			mockDB.EXPECT().RunTransaction(gomock.Any(), gomock.Any()).
+				DoAndReturn(
+					func(ctx context.Context, f func(context.Context, repository.Transaction) error) error {
+						return f(ctx, nil)
+					},
+				).AnyTimes()
+			if tt.undoableExit != nil {
+				mockDB.EXPECT().ReadUndoableExit(gomock.Any(), gomock.Any(), "test_user_id").Return(*tt.undoableExit, nil).Times(1)
+			} else {
+				mockDB.EXPECT().ReadUndoableExit(gomock.Any(), gomock.Any(), "test_user_id").Return(repository.UndoableExitDoc{}, status.Errorf(codes.NotFound, "")).Times(1)
+			}
+			mockDB.EXPECT().ReadUser(gomock.Any(), gomock.Any(), "test_user_id").Return(tt.userDoc, nil).AnyTimes()
+			mockDB.EXPECT().ReadSeatWithUserID(gomock.Any(), "test_user_id", gomock.Any()).Return(repository.SeatDoc{}, status.Errorf(codes.NotFound, "")).AnyTimes()
+			if tt.seatTaken {
+				mockDB.EXPECT().ReadSeat(gomock.Any(), gomock.Any(), 3, false).Return(repository.SeatDoc{SeatID: 3, UserID: "other_user_id"}, nil).AnyTimes()
+			} else {
+				mockDB.EXPECT().ReadSeat(gomock.Any(), gomock.Any(), 3, false).Return(repository.SeatDoc{}, status.Errorf(codes.NotFound, "")).AnyTimes()
+			}
+			mockDB.EXPECT().ReadSystemConstantsConfig(gomock.Any(), gomock.Any()).Return(repository.ConstantsConfigDoc{MaxSeats: 10}, nil).AnyTimes()
+			var reservations []repository.SeatReservationDoc
+			if tt.seatReserved {
+				reservations = append(reservations, repository.SeatReservationDoc{UserID: "other_user_id", SeatID: 3, StartAt: fixedNow, Until: fixedNow.Add(time.Hour)})
+			}
+			mockDB.EXPECT().ReadSeatReservationsStartBefore(gomock.Any(), gomock.Any(), false, gomock.Any()).Return(reservations, nil).AnyTimes()
+			mockDB.EXPECT().ReadSeatLimitsWHITEListWithSeatIDAndUserID(gomock.Any(), 3, "test_user_id", false).
+				Return([]repository.SeatLimitDoc{}, nil).AnyTimes()
+			var blackList []repository.SeatLimitDoc
+			if tt.seatLimited {
+				blackList = append(blackList, repository.SeatLimitDoc{SeatID: 3, UserID: "test_user_id", Until: fixedNow.Add(time.Hour)})
+			}
+			mockDB.EXPECT().ReadSeatLimitsBLACKListWithSeatIDAndUserID(gomock.Any(), 3, "test_user_id", false).
+				Return(blackList, nil).AnyTimes()
+			mockDB.EXPECT().GetEnterRoomUserActivityDocIDsAfterDateForUserAndSeat(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				Return([]repository.UserActivityDoc{}, nil).AnyTimes()
+			mockDB.EXPECT().GetExitRoomUserActivityDocIDsAfterDateForUserAndSeat(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				Return([]repository.UserActivityDoc{}, nil).AnyTimes()
+
+			if tt.willRestore {
+				restoredSeat := previousSeat
+				restoredSeat.CurrentSegmentStartedAt = exitedAt
+				mockDB.EXPECT().CreateSeat(gomock.Any(), restoredSeat, false).Return(nil).Times(1)
+				mockDB.EXPECT().UpdateUserTotalTime(gomock.Any(), "test_user_id", 10*3600-58*60, 2*3600-58*60).Return(nil).Times(1)
+				mockDB.EXPECT().UpdateUserRankPoint(gomock.Any(), "test_user_id", 900).Return(nil).Times(1)
+				mockDB.EXPECT().AddDailyUserWorkHistory(gomock.Any(), gomock.Any(), "test_user_id", timeutil.StartOfDayJST(exitedAt), -58*60, 0).Return(nil).Times(1)
+				mockDB.EXPECT().UpdateUserLastExitedDate(gomock.Any(), "test_user_id", fixedNow.Add(-24*time.Hour)).Return(nil).Times(1)
+				mockDB.EXPECT().CreateUserActivityDoc(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				mockDB.EXPECT().DeleteUndoableExit(gomock.Any(), gomock.Any(), "test_user_id").Return(nil).Times(1)
+			}
+
+			mockLiveChatBot := mock_youtubebot.NewMockLiveChatBot(ctrl)
+			mockLiveChatBot.EXPECT().PostMessage(gomock.Any(), tt.expectedReplyMessage).Return(nil).Times(1)
+
+			app := WorkspaceApp{
+				Configs: &Configs{
+					Constants: repository.ConstantsConfigDoc{
+						UndoExitGraceMin: tt.graceMin,
+					},
+				},
+				Repository:               mockDB,
+				ChatSources:              youtubeChatSources(mockLiveChatBot),
+				alertOwnerBot:            moderatorbot.DummyMessageBot{},
+				ProcessedUserID:          "test_user_id",
+				ProcessedUserDisplayName: "テストユーザー",
+				nowFunc:                  func() time.Time { return fixedNow },
+			}
+
+			if err := i18n.LoadLocaleFolderFS(); err != nil {
+				panic(fmt.Errorf("in LoadLocaleFolderFS(): %w", err))
+			}
+
+			// テスト対象の関数を実行
+			err := app.Undo(context.Background())
+
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func TestSystem_Reserve(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	fixedNow := time.Date(2026, time.January, 1, 10, 0, 0, 0, timeutil.JapanLocation())
+	todayAt := func(hour, minute int) time.Time {
+		return time.Date(2026, time.January, 1, hour, minute, 0, 0, timeutil.JapanLocation())
+	}
+
+	reserveTestCases := []struct {
+		name                 string
+		maxPerUser           int
+		reserveOption        utils.ReserveOption
+		userReservations     []repository.SeatReservationDoc
+		seatReservations     []repository.SeatReservationDoc
+		expectedReservation  *repository.SeatReservationDoc // nilなら予約しない
+		expectedCanceledIDs  []string
+		expectedReplyMessage string
+	}{
+		{
+			name:          "予約する",
+			maxPerUser:    2,
+			reserveOption: utils.ReserveOption{SeatID: 5, Hour: 21, Minute: 0, IsDurationMinSet: true, DurationMin: 60},
+			expectedReservation: &repository.SeatReservationDoc{
+				UserID:          "test_user_id",
+				UserDisplayName: "テストユーザー",
+				SeatID:          5,
+				StartAt:         todayAt(21, 0),
+				Until:           todayAt(22, 0),
+				CreatedAt:       fixedNow,
+			},
+			expectedReplyMessage: "@テストユーザー さん、5番席を1/1 21:00から60分間予約しました📅",
+		},
+		{
+			name:          "過ぎた時刻なら翌日に予約する",
+			maxPerUser:    2,
+			reserveOption: utils.ReserveOption{SeatID: 5, Hour: 9, Minute: 30},
+			expectedReservation: &repository.SeatReservationDoc{
+				UserID:          "test_user_id",
+				UserDisplayName: "テストユーザー",
+				SeatID:          5,
+				StartAt:         todayAt(9, 30).AddDate(0, 0, 1),
+				Until:           todayAt(10, 20).AddDate(0, 0, 1),
+				CreatedAt:       fixedNow,
+			},
+			expectedReplyMessage: "@テストユーザー さん、5番席を1/2 09:30から50分間予約しました📅",
+		},
+		{
+			name:                 "予約を受け付けていない",
+			maxPerUser:           0,
+			reserveOption:        utils.ReserveOption{SeatID: 5, Hour: 21, Minute: 0},
+			expectedReplyMessage: "@テストユーザー さん、現在は席の予約を受け付けていません🙏",
+		},
+		{
+			name:                 "存在しない席",
+			maxPerUser:           2,
+			reserveOption:        utils.ReserveOption{SeatID: 11, Hour: 21, Minute: 0},
+			expectedReplyMessage: "@テストユーザー さん、11番席は存在しません🪑",
+		},
+		{
+			name:          "予約数の上限",
+			maxPerUser:    1,
+			reserveOption: utils.ReserveOption{SeatID: 5, Hour: 21, Minute: 0},
+			userReservations: []repository.SeatReservationDoc{
+				{UserID: "test_user_id", SeatID: 3, StartAt: todayAt(12, 0), Until: todayAt(13, 0)},
+			},
+			expectedReplyMessage: "@テストユーザー さん、予約できるのは1件までです📅",
+		},
+		{
+			name:          "時間帯が重なる予約がある",
+			maxPerUser:    2,
+			reserveOption: utils.ReserveOption{SeatID: 5, Hour: 21, Minute: 0, IsDurationMinSet: true, DurationMin: 60},
+			seatReservations: []repository.SeatReservationDoc{
+				{UserID: "other_user_id", SeatID: 5, StartAt: todayAt(21, 30), Until: todayAt(22, 30)},
+			},
+			expectedReplyMessage: "@テストユーザー さん、その時間帯の5番席はすでに予約されています📅",
+		},
+		{
+			name:          "時間帯が重ならない予約は問題ない",
+			maxPerUser:    2,
+			reserveOption: utils.ReserveOption{SeatID: 5, Hour: 21, Minute: 0, IsDurationMinSet: true, DurationMin: 60},
+			seatReservations: []repository.SeatReservationDoc{
+				{UserID: "other_user_id", SeatID: 5, StartAt: todayAt(20, 0), Until: todayAt(21, 0)},
+			},
+			expectedReservation: &repository.SeatReservationDoc{
+				UserID:          "test_user_id",
+				UserDisplayName: "テストユーザー",
+				SeatID:          5,
+				StartAt:         todayAt(21, 0),
+				Until:           todayAt(22, 0),
+				CreatedAt:       fixedNow,
+			},
+			expectedReplyMessage: "@テストユーザー さん、5番席を1/1 21:00から60分間予約しました📅",
+		},
+		{
+			name:          "席を指定して予約を取り消す（予約の受付停止中でも取り消せる）",
+			maxPerUser:    0,
+			reserveOption: utils.ReserveOption{SeatID: 5, IsCancel: true},
+			userReservations: []repository.SeatReservationDoc{
+				{ReservationID: "reservation1", UserID: "test_user_id", SeatID: 5, StartAt: todayAt(21, 0), Until: todayAt(22, 0)},
+				{ReservationID: "reservation2", UserID: "test_user_id", SeatID: 3, StartAt: todayAt(12, 0), Until: todayAt(13, 0)},
+			},
+			expectedCanceledIDs:  []string{"reservation1"},
+			expectedReplyMessage: "@テストユーザー さん、予約を1件取り消しました📅",
+		},
+		{
+			name:          "すべての予約を取り消す",
+			maxPerUser:    2,
+			reserveOption: utils.ReserveOption{IsCancel: true},
+			userReservations: []repository.SeatReservationDoc{
+				{ReservationID: "reservation1", UserID: "test_user_id", SeatID: 5, StartAt: todayAt(21, 0), Until: todayAt(22, 0)},
+				{ReservationID: "reservation2", UserID: "test_user_id", SeatID: 3, StartAt: todayAt(12, 0), Until: todayAt(13, 0)},
+			},
+			expectedCanceledIDs:  []string{"reservation1", "reservation2"},
+			expectedReplyMessage: "@テストユーザー さん、予約を2件取り消しました📅",
+		},
+		{
+			name:          "取り消せる予約がない",
+			maxPerUser:    2,
+			reserveOption: utils.ReserveOption{SeatID: 7, IsCancel: true},
+			userReservations: []repository.SeatReservationDoc{
+				{ReservationID: "reservation1", UserID: "test_user_id", SeatID: 5, StartAt: todayAt(21, 0), Until: todayAt(22, 0)},
+			},
+			expectedReplyMessage: "@テストユーザー さん、取り消せる予約はありません🙏",
+		},
+	}
+
+	for _, tt := range reserveTestCases {
+		t.Run(tt.name, func(t *testing.T) {
+			constants := repository.ConstantsConfigDoc{
+				MaxSeats:              10,
+				DefaultWorkTimeMin:    50,
+				ReservationMaxPerUser: tt.maxPerUser,
+			}
+			mockDB := mock_repository.NewMockRepository(ctrl)
+			mockDB.EXPECT().RunTransaction(gomock.Any(), gomock.Any()).
+				DoAndReturn(
+					func(ctx context.Context, f func(context.Context, repository.Transaction) error) error {
+						return f(ctx, nil)
+					},
+				).AnyTimes()
+			mockDB.EXPECT().ReadSystemConstantsConfig(gomock.Any(), gomock.Any()).Return(constants, nil).AnyTimes()
+			mockDB.EXPECT().ReadSeatReservationsWithUserID(gomock.Any(), gomock.Any(), "test_user_id", false).Return(tt.userReservations, nil).AnyTimes()
+			mockDB.EXPECT().ReadSeatReservationsWithSeatID(gomock.Any(), gomock.Any(), tt.reserveOption.SeatID, false).Return(tt.seatReservations, nil).AnyTimes()
+			if tt.expectedReservation != nil {
+				mockDB.EXPECT().CreateSeatReservation(gomock.Any(), gomock.Any(), *tt.expectedReservation, false).Return(nil).Times(1)
+			}
+			for _, reservationID := range tt.expectedCanceledIDs {
+				mockDB.EXPECT().DeleteSeatReservation(gomock.Any(), gomock.Any(), reservationID, false).Return(nil).Times(1)
+			}
+
+			mockLiveChatBot := mock_youtubebot.NewMockLiveChatBot(ctrl)
+			mockLiveChatBot.EXPECT().PostMessage(gomock.Any(), tt.expectedReplyMessage).Return(nil).Times(1)
+
+			app := WorkspaceApp{
+				Configs: &Configs{
+					Constants: constants,
+				},
+				Repository:               mockDB,
+				ChatSources:              youtubeChatSources(mockLiveChatBot),
+				alertOwnerBot:            moderatorbot.DummyMessageBot{},
+				ProcessedUserID:          "test_user_id",
+				ProcessedUserDisplayName: "テストユーザー",
+				nowFunc:                  func() time.Time { return fixedNow },
+			}
+
+			if err := i18n.LoadLocaleFolderFS(); err != nil {
+				panic(fmt.Errorf("in LoadLocaleFolderFS(): %w", err))
+			}
+
+			// テスト対象の関数を実行
+			err := app.Reserve(context.Background(), &tt.reserveOption)
+
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func TestAvailableSeatIDForUser_SkipsReservedSeats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	fixedNow := time.Date(2026, time.January, 1, 10, 0, 0, 0, timeutil.JapanLocation())
+	constants := repository.ConstantsConfigDoc{
+		MaxSeats:           3,
+		ReservationLeadMin: 10,
+	}
+	reservations := []repository.SeatReservationDoc{
+		{UserID: "other_user_id", SeatID: 1, StartAt: fixedNow.Add(5 * time.Minute), Until: fixedNow.Add(time.Hour)},
+		// 自分の予約は除外しない
+		{UserID: "test_user_id", SeatID: 2, StartAt: fixedNow.Add(5 * time.Minute), Until: fixedNow.Add(time.Hour)},
+		// 予約時間が終わっていれば除外しない
+		{UserID: "other_user_id", SeatID: 3, StartAt: fixedNow.Add(-time.Hour), Until: fixedNow.Add(-time.Minute)},
+	}
+
+	mockDB := mock_repository.NewMockRepository(ctrl)
+	mockDB.EXPECT().ReadSystemConstantsConfig(gomock.Any(), gomock.Any()).Return(constants, nil).AnyTimes()
+	mockDB.EXPECT().ReadGeneralSeats(gomock.Any()).Return([]repository.SeatDoc{}, nil).AnyTimes()
+	mockDB.EXPECT().ReadSeatReservationsStartBefore(gomock.Any(), fixedNow.Add(10*time.Minute), false, gomock.Any()).Return(reservations, nil).AnyTimes()
+	mockDB.EXPECT().ReadSeatLimitsWHITEListWithSeatIDAndUserID(gomock.Any(), gomock.Any(), "test_user_id", gomock.Any()).
+		Return([]repository.SeatLimitDoc{}, nil).AnyTimes()
+	mockDB.EXPECT().ReadSeatLimitsBLACKListWithSeatIDAndUserID(gomock.Any(), gomock.Any(), "test_user_id", gomock.Any()).
+		Return([]repository.SeatLimitDoc{}, nil).AnyTimes()
+	mockDB.EXPECT().GetEnterRoomUserActivityDocIDsAfterDateForUserAndSeat(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return([]repository.UserActivityDoc{}, nil).AnyTimes()
+	mockDB.EXPECT().GetExitRoomUserActivityDocIDsAfterDateForUserAndSeat(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return([]repository.UserActivityDoc{}, nil).AnyTimes()
+
+	app := WorkspaceApp{
+		Configs: &Configs{
+			Constants: constants,
+		},
+		Repository: mockDB,
+		nowFunc:    func() time.Time { return fixedNow },
+	}
+
+	t.Run("予約済みの席を除いた最小の席番号", func(t *testing.T) {
+		seatID, err := app.MinAvailableSeatIDForUser(context.Background(), nil, "test_user_id", false)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, seatID)
+	})
+
+	t.Run("ランダムな席は予約済みの席を選ばない", func(t *testing.T) {
+		for range 20 {
+			seatID, err := app.RandomAvailableSeatIDForUser(context.Background(), nil, "test_user_id", false)
+			assert.NoError(t, err)
+			assert.NotEqual(t, 1, seatID)
+		}
+	})
+
+	t.Run("他のユーザーは予約済みの席を選ばない", func(t *testing.T) {
+		otherReservedSeatIDs, err := app.SeatIDsReservedByOthers(context.Background(), "another_user_id", false, constants.ReservationLeadMin)
+		assert.NoError(t, err)
+		assert.Equal(t, map[int]bool{1: true, 2: true}, otherReservedSeatIDs)
+	})
+}
+
 func TestSystem_ShowSeatInfo(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -581,17 +1075,15 @@ func TestSystem_ShowSeatInfo(t *testing.T) {
 	for _, tt := range showSeatInfoTestCases {
 		t.Run(tt.name, func(t *testing.T) {
 			mockDB := mock_repository.NewMockRepository(ctrl)
-			mockFirestoreClient := mock_repository.NewMockDBClient(ctrl)
-			mockFirestoreClient.EXPECT().RunTransaction(gomock.Any(), gomock.Any()).
+			mockDB.EXPECT().RunTransaction(gomock.Any(), gomock.Any()).
 				DoAndReturn(
-					func(ctx context.Context, f func(context.Context, *firestore.Transaction) error, opts ...firestore.TransactionOption) error {
-						tx := &firestore.Transaction{}
-						return f(ctx, tx)
+					func(ctx context.Context, f func(context.Context, repository.Transaction) error) error {
+						return f(ctx, nil)
 					},
 				).AnyTimes()
-			mockDB.EXPECT().FirestoreClient().Return(mockFirestoreClient).AnyTimes()
 			mockDB.EXPECT().ReadGeneralSeats(gomock.Any()).Return(tt.generalSeats, nil).AnyTimes()
 			mockDB.EXPECT().ReadMemberSeats(gomock.Any()).Return(tt.memberSeats, nil).AnyTimes()
+			mockDB.EXPECT().ReadSeatReservationsStartBefore(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]repository.SeatReservationDoc{}, nil).AnyTimes()
 			mockDB.EXPECT().ReadUser(gomock.Any(), gomock.Any(), "test_user_id").Return(repository.UserDoc{}, nil).AnyTimes()
 			if tt.currentSeatDoc != nil {
 				mockDB.EXPECT().ReadSeatWithUserID(gomock.Any(), "test_user_id", tt.userIsMember).Return(*tt.currentSeatDoc, nil).AnyTimes()
@@ -610,7 +1102,7 @@ func TestSystem_ShowSeatInfo(t *testing.T) {
 
 			app := WorkspaceApp{
 				Repository:               mockDB,
-				LiveChatBot:              mockLiveChatBot,
+				ChatSources:              youtubeChatSources(mockLiveChatBot),
 				alertOwnerBot:            moderatorbot.DummyMessageBot{},
 				ProcessedUserID:          "test_user_id",
 				ProcessedUserDisplayName: "テストユーザー",
@@ -645,6 +1137,8 @@ func TestSystem_Change(t *testing.T) {
 		userIsMember         bool
 		currentSeatDoc       *repository.SeatDoc
 		expectedReplyMessage string
+		// ポモドーロを指定した場合の、変更後のCurrentStateUntil
+		expectedCurrentStateUntil time.Time
 	}{
 		{
 			name: "作業内容・入室時間変更（一般席）",
@@ -760,24 +1254,81 @@ func TestSystem_Change(t *testing.T) {
 			},
 			expectedReplyMessage: "@テストユーザー さん、休憩内容を\"\"に更新しました✍️（5番席）",
 		},
+		{
+			name: "ポモドーロを設定",
+			constantsConfig: repository.ConstantsConfigDoc{
+				MaxSeats:            10,
+				MinWorkTimeMin:      5,
+				MaxWorkTimeMin:      360,
+				MinBreakDurationMin: 1,
+				MaxBreakDurationMin: 60,
+			},
+			commandDetails: utils.CommandDetails{
+				CommandType: utils.Change,
+				ChangeOption: utils.MinWorkOrderOption{
+					IsPomodoroSet:    true,
+					PomodoroWorkMin:  25,
+					PomodoroBreakMin: 5,
+				},
+			},
+			userIsMember: false,
+			currentSeatDoc: &repository.SeatDoc{
+				SeatID:                  5,
+				UserID:                  "test_user_id",
+				State:                   repository.WorkState,
+				CurrentStateStartedAt:   fixedNow.Add(-10 * time.Minute),
+				CurrentSegmentStartedAt: fixedNow.Add(-10 * time.Minute),
+				EnteredAt:               fixedNow.Add(-10 * time.Minute),
+				Until:                   fixedNow.Add(90 * time.Minute),
+				CurrentStateUntil:       fixedNow.Add(90 * time.Minute),
+			},
+			expectedCurrentStateUntil: fixedNow.Add(15 * time.Minute),
+			expectedReplyMessage:      "@テストユーザー さん、（🍅ポモドーロ：作業25分／休憩5分）",
+		},
+		{
+			name: "ポモドーロを解除",
+			constantsConfig: repository.ConstantsConfigDoc{
+				MaxSeats:       10,
+				MinWorkTimeMin: 5,
+				MaxWorkTimeMin: 360,
+			},
+			commandDetails: utils.CommandDetails{
+				CommandType: utils.Change,
+				ChangeOption: utils.MinWorkOrderOption{
+					IsPomodoroSet: true,
+				},
+			},
+			userIsMember: false,
+			currentSeatDoc: &repository.SeatDoc{
+				SeatID:                  5,
+				UserID:                  "test_user_id",
+				State:                   repository.WorkState,
+				CurrentStateStartedAt:   fixedNow.Add(-10 * time.Minute),
+				CurrentSegmentStartedAt: fixedNow.Add(-10 * time.Minute),
+				EnteredAt:               fixedNow.Add(-10 * time.Minute),
+				Until:                   fixedNow.Add(90 * time.Minute),
+				CurrentStateUntil:       fixedNow.Add(15 * time.Minute),
+				PomodoroWorkMin:         25,
+				PomodoroBreakMin:        5,
+			},
+			expectedCurrentStateUntil: fixedNow.Add(90 * time.Minute),
+			expectedReplyMessage:      "@テストユーザー さん、（🍅ポモドーロを解除しました）",
+		},
 	}
 
 	for _, tt := range changeTestCases {
 		t.Run(tt.name, func(t *testing.T) {
 			mockDB := mock_repository.NewMockRepository(ctrl)
-			mockFirestoreClient := mock_repository.NewMockDBClient(ctrl)
-			mockFirestoreClient.EXPECT().RunTransaction(gomock.Any(), gomock.Any()).
+			mockDB.EXPECT().RunTransaction(gomock.Any(), gomock.Any()).
 				DoAndReturn(
-					func(ctx context.Context, f func(context.Context, *firestore.Transaction) error, opts ...firestore.TransactionOption) error {
-						tx := &firestore.Transaction{}
-						return f(ctx, tx)
+					func(ctx context.Context, f func(context.Context, repository.Transaction) error) error {
+						return f(ctx, nil)
 					},
 				).AnyTimes()
-			mockDB.EXPECT().FirestoreClient().Return(mockFirestoreClient).AnyTimes()
 			mockDB.EXPECT().ReadUser(gomock.Any(), gomock.Any(), "test_user_id").Return(repository.UserDoc{}, nil).AnyTimes()
 			mockDB.EXPECT().ReadSeatWithUserID(gomock.Any(), "test_user_id", tt.userIsMember).Return(*tt.currentSeatDoc, nil).AnyTimes()
 			mockDB.EXPECT().ReadSeatWithUserID(gomock.Any(), "test_user_id", !tt.userIsMember).Return(repository.SeatDoc{}, status.Errorf(codes.NotFound, "")).AnyTimes()
-			mockDB.EXPECT().UpdateSeat(gomock.Any(), gomock.Any(), gomock.Any(), tt.userIsMember).DoAndReturn(func(ctx context.Context, tx *firestore.Transaction, seat repository.SeatDoc, isMemberSeat bool) error {
+			mockDB.EXPECT().UpdateSeat(gomock.Any(), gomock.Any(), gomock.Any(), tt.userIsMember).DoAndReturn(func(ctx context.Context, tx repository.Transaction, seat repository.SeatDoc, isMemberSeat bool) error {
 				assert.Equal(t, tt.currentSeatDoc.SeatID, seat.SeatID)
 				assert.Equal(t, tt.currentSeatDoc.UserID, seat.UserID)
 
@@ -794,6 +1345,13 @@ func TestSystem_Change(t *testing.T) {
 						assert.Equal(t, tt.commandDetails.ChangeOption.WorkName, seat.BreakWorkName)
 					}
 				}
+
+				// ポモドーロが指定されている場合のみ検証
+				if tt.commandDetails.ChangeOption.IsPomodoroSet {
+					assert.Equal(t, tt.commandDetails.ChangeOption.PomodoroWorkMin, seat.PomodoroWorkMin)
+					assert.Equal(t, tt.commandDetails.ChangeOption.PomodoroBreakMin, seat.PomodoroBreakMin)
+					assert.Equal(t, tt.expectedCurrentStateUntil, seat.CurrentStateUntil)
+				}
 				return nil
 			}).Times(1)
 			mockDB.EXPECT().CreateUserActivityDoc(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
@@ -806,7 +1364,7 @@ func TestSystem_Change(t *testing.T) {
 
 			app := WorkspaceApp{
 				Repository:               mockDB,
-				LiveChatBot:              mockLiveChatBot,
+				ChatSources:              youtubeChatSources(mockLiveChatBot),
 				alertOwnerBot:            moderatorbot.DummyMessageBot{},
 				ProcessedUserID:          "test_user_id",
 				ProcessedUserDisplayName: "テストユーザー",
@@ -953,19 +1511,16 @@ func TestSystem_More(t *testing.T) {
 	for _, tt := range moreTestCases {
 		t.Run(tt.name, func(t *testing.T) {
 			mockDB := mock_repository.NewMockRepository(ctrl)
-			mockFirestoreClient := mock_repository.NewMockDBClient(ctrl)
-			mockFirestoreClient.EXPECT().RunTransaction(gomock.Any(), gomock.Any()).
+			mockDB.EXPECT().RunTransaction(gomock.Any(), gomock.Any()).
 				DoAndReturn(
-					func(ctx context.Context, f func(context.Context, *firestore.Transaction) error, opts ...firestore.TransactionOption) error {
-						tx := &firestore.Transaction{}
-						return f(ctx, tx)
+					func(ctx context.Context, f func(context.Context, repository.Transaction) error) error {
+						return f(ctx, nil)
 					},
 				).AnyTimes()
-			mockDB.EXPECT().FirestoreClient().Return(mockFirestoreClient).AnyTimes()
 			mockDB.EXPECT().ReadUser(gomock.Any(), gomock.Any(), "test_user_id").Return(repository.UserDoc{}, nil).AnyTimes()
 			mockDB.EXPECT().ReadSeatWithUserID(gomock.Any(), "test_user_id", tt.userIsMember).Return(*tt.currentSeatDoc, nil).AnyTimes()
 			mockDB.EXPECT().ReadSeatWithUserID(gomock.Any(), "test_user_id", !tt.userIsMember).Return(repository.SeatDoc{}, status.Errorf(codes.NotFound, "")).AnyTimes()
-			mockDB.EXPECT().UpdateSeat(gomock.Any(), gomock.Any(), gomock.Any(), tt.userIsMember).DoAndReturn(func(ctx context.Context, tx *firestore.Transaction, seat repository.SeatDoc, isMemberSeat bool) error {
+			mockDB.EXPECT().UpdateSeat(gomock.Any(), gomock.Any(), gomock.Any(), tt.userIsMember).DoAndReturn(func(ctx context.Context, tx repository.Transaction, seat repository.SeatDoc, isMemberSeat bool) error {
 				assert.Equal(t, tt.currentSeatDoc.SeatID, seat.SeatID)
 				assert.Equal(t, tt.currentSeatDoc.UserID, seat.UserID)
 
@@ -982,7 +1537,7 @@ func TestSystem_More(t *testing.T) {
 
 			app := WorkspaceApp{
 				Repository:               mockDB,
-				LiveChatBot:              mockLiveChatBot,
+				ChatSources:              youtubeChatSources(mockLiveChatBot),
 				alertOwnerBot:            moderatorbot.DummyMessageBot{},
 				ProcessedUserID:          "test_user_id",
 				ProcessedUserDisplayName: "テストユーザー",
@@ -1142,19 +1697,16 @@ func TestSystem_Break(t *testing.T) {
 	for _, tt := range breakTestCases {
 		t.Run(tt.name, func(t *testing.T) {
 			mockDB := mock_repository.NewMockRepository(ctrl)
-			mockFirestoreClient := mock_repository.NewMockDBClient(ctrl)
-			mockFirestoreClient.EXPECT().RunTransaction(gomock.Any(), gomock.Any()).
+			mockDB.EXPECT().RunTransaction(gomock.Any(), gomock.Any()).
 				DoAndReturn(
-					func(ctx context.Context, f func(context.Context, *firestore.Transaction) error, opts ...firestore.TransactionOption) error {
-						tx := &firestore.Transaction{}
-						return f(ctx, tx)
+					func(ctx context.Context, f func(context.Context, repository.Transaction) error) error {
+						return f(ctx, nil)
 					},
 				).AnyTimes()
-			mockDB.EXPECT().FirestoreClient().Return(mockFirestoreClient).AnyTimes()
 			mockDB.EXPECT().ReadUser(gomock.Any(), gomock.Any(), "test_user_id").Return(repository.UserDoc{}, nil).AnyTimes()
 			mockDB.EXPECT().ReadSeatWithUserID(gomock.Any(), "test_user_id", tt.userIsMember).Return(*tt.currentSeatDoc, nil).AnyTimes()
 			mockDB.EXPECT().ReadSeatWithUserID(gomock.Any(), "test_user_id", !tt.userIsMember).Return(repository.SeatDoc{}, status.Errorf(codes.NotFound, "")).AnyTimes()
-			mockDB.EXPECT().UpdateSeat(gomock.Any(), gomock.Any(), gomock.Any(), tt.userIsMember).DoAndReturn(func(ctx context.Context, tx *firestore.Transaction, seat repository.SeatDoc, isMemberSeat bool) error {
+			mockDB.EXPECT().UpdateSeat(gomock.Any(), gomock.Any(), gomock.Any(), tt.userIsMember).DoAndReturn(func(ctx context.Context, tx repository.Transaction, seat repository.SeatDoc, isMemberSeat bool) error {
 				assert.Equal(t, tt.currentSeatDoc.SeatID, seat.SeatID)
 				assert.Equal(t, tt.currentSeatDoc.UserID, seat.UserID)
 				assert.Equal(t, repository.BreakState, seat.State)
@@ -1169,7 +1721,7 @@ func TestSystem_Break(t *testing.T) {
 
 			app := WorkspaceApp{
 				Repository:               mockDB,
-				LiveChatBot:              mockLiveChatBot,
+				ChatSources:              youtubeChatSources(mockLiveChatBot),
 				alertOwnerBot:            moderatorbot.DummyMessageBot{},
 				ProcessedUserID:          "test_user_id",
 				ProcessedUserDisplayName: "テストユーザー",
@@ -1357,19 +1909,16 @@ func TestSystem_Resume(t *testing.T) {
 	for _, tt := range resumeTestCases {
 		t.Run(tt.name, func(t *testing.T) {
 			mockDB := mock_repository.NewMockRepository(ctrl)
-			mockFirestoreClient := mock_repository.NewMockDBClient(ctrl)
-			mockFirestoreClient.EXPECT().RunTransaction(gomock.Any(), gomock.Any()).
+			mockDB.EXPECT().RunTransaction(gomock.Any(), gomock.Any()).
 				DoAndReturn(
-					func(ctx context.Context, f func(context.Context, *firestore.Transaction) error, opts ...firestore.TransactionOption) error {
-						tx := &firestore.Transaction{}
-						return f(ctx, tx)
+					func(ctx context.Context, f func(context.Context, repository.Transaction) error) error {
+						return f(ctx, nil)
 					},
 				).AnyTimes()
-			mockDB.EXPECT().FirestoreClient().Return(mockFirestoreClient).AnyTimes()
 			mockDB.EXPECT().ReadUser(gomock.Any(), gomock.Any(), "test_user_id").Return(repository.UserDoc{}, nil).AnyTimes()
 			mockDB.EXPECT().ReadSeatWithUserID(gomock.Any(), "test_user_id", tt.userIsMember).Return(*tt.currentSeatDoc, nil).AnyTimes()
 			mockDB.EXPECT().ReadSeatWithUserID(gomock.Any(), "test_user_id", !tt.userIsMember).Return(repository.SeatDoc{}, status.Errorf(codes.NotFound, "")).AnyTimes()
-			mockDB.EXPECT().UpdateSeat(gomock.Any(), gomock.Any(), gomock.Any(), tt.userIsMember).DoAndReturn(func(ctx context.Context, tx *firestore.Transaction, seat repository.SeatDoc, isMemberSeat bool) error {
+			mockDB.EXPECT().UpdateSeat(gomock.Any(), gomock.Any(), gomock.Any(), tt.userIsMember).DoAndReturn(func(ctx context.Context, tx repository.Transaction, seat repository.SeatDoc, isMemberSeat bool) error {
 				assert.Equal(t, tt.currentSeatDoc.SeatID, seat.SeatID)
 				assert.Equal(t, tt.currentSeatDoc.UserID, seat.UserID)
 				assert.Equal(t, repository.WorkState, seat.State)
@@ -1392,7 +1941,7 @@ func TestSystem_Resume(t *testing.T) {
 
 			app := WorkspaceApp{
 				Repository:               mockDB,
-				LiveChatBot:              mockLiveChatBot,
+				ChatSources:              youtubeChatSources(mockLiveChatBot),
 				alertOwnerBot:            moderatorbot.DummyMessageBot{},
 				ProcessedUserID:          "test_user_id",
 				ProcessedUserDisplayName: "テストユーザー",
@@ -1560,17 +2109,15 @@ func TestSystem_Order(t *testing.T) {
 	for _, tt := range orderTestCases {
 		t.Run(tt.name, func(t *testing.T) {
 			mockDB := mock_repository.NewMockRepository(ctrl)
-			mockFirestoreClient := mock_repository.NewMockDBClient(ctrl)
-			mockFirestoreClient.EXPECT().RunTransaction(gomock.Any(), gomock.Any()).
+			mockDB.EXPECT().RunTransaction(gomock.Any(), gomock.Any()).
 				DoAndReturn(
-					func(ctx context.Context, f func(context.Context, *firestore.Transaction) error, opts ...firestore.TransactionOption) error {
-						tx := &firestore.Transaction{}
-						return f(ctx, tx)
+					func(ctx context.Context, f func(context.Context, repository.Transaction) error) error {
+						return f(ctx, nil)
 					},
 				).AnyTimes()
-			mockDB.EXPECT().FirestoreClient().Return(mockFirestoreClient).AnyTimes()
 			mockDB.EXPECT().ReadGeneralSeats(gomock.Any()).Return([]repository.SeatDoc{}, nil).AnyTimes()
 			mockDB.EXPECT().ReadMemberSeats(gomock.Any()).Return([]repository.SeatDoc{}, nil).AnyTimes()
+			mockDB.EXPECT().ReadSeatReservationsStartBefore(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]repository.SeatReservationDoc{}, nil).AnyTimes()
 
 			if tt.currentSeatDoc != nil {
 				mockDB.EXPECT().ReadSeatWithUserID(gomock.Any(), "test_user_id", tt.userIsMember).Return(*tt.currentSeatDoc, nil).AnyTimes()
@@ -1583,7 +2130,7 @@ func TestSystem_Order(t *testing.T) {
 			mockDB.EXPECT().ReadAllMenuDocsOrderByCode(gomock.Any()).Return(menuDocs, nil).AnyTimes()
 			mockDB.EXPECT().CountUserOrdersOfTheDay(gomock.Any(), "test_user_id", gomock.Any()).Return(tt.alreadyOrderedCountToday, nil).AnyTimes()
 			mockDB.EXPECT().CreateOrderHistoryDoc(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
-			mockDB.EXPECT().UpdateSeat(gomock.Any(), gomock.Any(), gomock.Any(), tt.userIsMember).DoAndReturn(func(ctx context.Context, tx *firestore.Transaction, seat repository.SeatDoc, isMemberSeat bool) error {
+			mockDB.EXPECT().UpdateSeat(gomock.Any(), gomock.Any(), gomock.Any(), tt.userIsMember).DoAndReturn(func(ctx context.Context, tx repository.Transaction, seat repository.SeatDoc, isMemberSeat bool) error {
 				assert.Equal(t, tt.currentSeatDoc.SeatID, seat.SeatID)
 				assert.Equal(t, tt.currentSeatDoc.UserID, seat.UserID)
 				assert.NotEmpty(t, seat.MenuCode)
@@ -1595,7 +2142,7 @@ func TestSystem_Order(t *testing.T) {
 
 			app := WorkspaceApp{
 				Repository:               mockDB,
-				LiveChatBot:              mockLiveChatBot,
+				ChatSources:              youtubeChatSources(mockLiveChatBot),
 				alertOwnerBot:            moderatorbot.DummyMessageBot{},
 				ProcessedUserID:          "test_user_id",
 				ProcessedUserIsMember:    tt.userIsMember,
@@ -4,18 +4,18 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strings"
 
 	i18nmsg "app.modules/core/i18n/typed"
 	"app.modules/core/repository"
 	"app.modules/core/timeutil"
 	"app.modules/core/utils"
-
-	"cloud.google.com/go/firestore"
+	"app.modules/core/workspaceapp/presenter"
 )
 
 func (app *WorkspaceApp) ShowUserInfo(ctx context.Context, infoOption *utils.InfoOption) error {
 	var replyMessage string
-	txErr := app.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+	txErr := app.RunTransaction(ctx, func(ctx context.Context, tx repository.Transaction) error {
 		totalStudyDuration, dailyTotalStudyDuration, err := app.GetUserRealtimeTotalStudyDurations(ctx, tx, app.ProcessedUserID)
 		if err != nil {
 			return fmt.Errorf("in app.GetUserRealtimeTotalStudyDurations(): %w", err)
@@ -33,6 +33,11 @@ func (app *WorkspaceApp) ShowUserInfo(ctx context.Context, infoOption *utils.Inf
 			replyMessage += i18nmsg.CommandUserInfoRank(userDoc.RankPoint)
 		}
 
+		if userDoc.DailyGoalMin > 0 {
+			dailyTotalStudyMin := int(dailyTotalStudyDuration.Minutes())
+			replyMessage += i18nmsg.CommandUserInfoDailyGoal(dailyTotalStudyMin, userDoc.DailyGoalMin, utils.DailyGoalProgressPercent(dailyTotalStudyMin, userDoc.DailyGoalMin))
+		}
+
 		if infoOption.ShowDetails {
 			switch userDoc.RankVisible {
 			case true:
@@ -82,7 +87,7 @@ func (app *WorkspaceApp) My(ctx context.Context, myOptions []utils.MyOption) err
 	}
 
 	replyMessage := ""
-	txErr := app.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+	txErr := app.RunTransaction(ctx, func(ctx context.Context, tx repository.Transaction) error {
 		// 変更前のuserDocを読み込んでおく
 		userDoc, err := app.Repository.ReadUser(ctx, tx, app.ProcessedUserID)
 		if err != nil {
@@ -95,17 +100,13 @@ func (app *WorkspaceApp) My(ctx context.Context, myOptions []utils.MyOption) err
 		}
 		isInRoom := isInMemberRoom || isInGeneralRoom
 
-		var seats []repository.SeatDoc
-		if isInMemberRoom {
-			seats, err = app.Repository.ReadMemberSeats(ctx)
-			if err != nil {
-				return fmt.Errorf("in ReadMemberSeats: %w", err)
-			}
-		}
-		if isInGeneralRoom {
-			seats, err = app.Repository.ReadGeneralSeats(ctx)
+		// 入室中であれば、座席の更新はすべてこのseatに反映してから最後に1回だけ書き込む
+		var seat repository.SeatDoc
+		seatUpdated := false
+		if isInRoom {
+			seat, err = app.CurrentSeat(ctx, tx, app.ProcessedUserID, isInMemberRoom)
 			if err != nil {
-				return fmt.Errorf("in ReadGeneralSeats: %w", err)
+				return fmt.Errorf("in CurrentSeat: %w", err)
 			}
 		}
 		realTimeTotalStudyDuration, _, err := app.GetUserRealtimeTotalStudyDurations(ctx, tx, app.ProcessedUserID)
@@ -149,16 +150,11 @@ func (app *WorkspaceApp) My(ctx context.Context, myOptions []utils.MyOption) err
 						if err != nil {
 							return fmt.Errorf("in GetSeatAppearance: %w", err)
 						}
+						seatAppearance = utils.ApplySupporterAppearance(seatAppearance, userDoc.SupporterAppearanceUntil, app.currentTime())
 
 						// 席の色を更新
-						newSeat, err := utils.GetSeatByUserID(seats, app.ProcessedUserID)
-						if err != nil {
-							return fmt.Errorf("in GetSeatByUserID: %w", err)
-						}
-						newSeat.Appearance = seatAppearance
-						if err := app.Repository.UpdateSeat(ctx, tx, newSeat, isInMemberRoom); err != nil {
-							return fmt.Errorf("in app.Repository.UpdateSeat: %w", err)
-						}
+						seat.Appearance = seatAppearance
+						seatUpdated = true
 					}
 				}
 				currentRankVisible = newRankVisible
@@ -185,25 +181,40 @@ func (app *WorkspaceApp) My(ctx context.Context, myOptions []utils.MyOption) err
 
 				// 入室中であれば、座席の色も変える
 				if isInRoom {
-					newSeat, err := utils.GetSeatByUserID(seats, app.ProcessedUserID)
-					if err != nil {
-						return fmt.Errorf("in GetSeatByUserID: %w", err)
-					}
 					seatAppearance, err := utils.GetSeatAppearance(realTimeTotalStudySec, currentRankVisible, userDoc.RankPoint, colorCode)
 					if err != nil {
 						return fmt.Errorf("in GetSeatAppearance: %w", err)
 					}
+					seatAppearance = utils.ApplySupporterAppearance(seatAppearance, userDoc.SupporterAppearanceUntil, app.currentTime())
 
 					// 席の色を更新
-					newSeat.Appearance = seatAppearance
-					if err := app.Repository.UpdateSeat(ctx, tx, newSeat, isInMemberRoom); err != nil {
-						return fmt.Errorf("in app.Repository.UpdateSeat(): %w", err)
-					}
+					seat.Appearance = seatAppearance
+					seatUpdated = true
+				}
+			case utils.DailyGoalMin:
+				if err := app.Repository.UpdateUserDailyGoalMin(tx, app.ProcessedUserID, myOption.IntValue); err != nil {
+					return fmt.Errorf("in UpdateUserDailyGoalMin: %w", err)
+				}
+				// 入室中であれば、次のOrganizeDBDailyGoalで新しい目標を判定させる
+				if isInRoom {
+					seat.DailyGoalCheckAt = app.currentTime()
+					seatUpdated = true
+				}
+				// 値が0はリセットのこと。
+				if myOption.IntValue == 0 {
+					replyMessage += i18nmsg.CommandMyResetDailyGoal()
+				} else {
+					replyMessage += i18nmsg.CommandMySetDailyGoal(myOption.IntValue)
 				}
 			default:
 				// pass
 			}
 		}
+		if seatUpdated {
+			if err := app.Repository.UpdateSeat(ctx, tx, seat, isInMemberRoom); err != nil {
+				return fmt.Errorf("in app.Repository.UpdateSeat(): %w", err)
+			}
+		}
 		return nil
 	})
 	if txErr != nil {
@@ -216,7 +227,7 @@ func (app *WorkspaceApp) My(ctx context.Context, myOptions []utils.MyOption) err
 
 func (app *WorkspaceApp) Rank(ctx context.Context, _ *utils.CommandDetails) error {
 	replyMessage := ""
-	txErr := app.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+	txErr := app.RunTransaction(ctx, func(ctx context.Context, tx repository.Transaction) error {
 		// 変更前のuserDocを読み込んでおく
 		userDoc, err := app.Repository.ReadUser(ctx, tx, app.ProcessedUserID)
 		if err != nil {
@@ -233,7 +244,7 @@ func (app *WorkspaceApp) Rank(ctx context.Context, _ *utils.CommandDetails) erro
 		var realtimeTotalStudySec int
 		if isInRoom {
 			var err error
-			currentSeat, err = app.CurrentSeat(ctx, app.ProcessedUserID, isInMemberRoom)
+			currentSeat, err = app.CurrentSeat(ctx, tx, app.ProcessedUserID, isInMemberRoom)
 			if err != nil {
 				return fmt.Errorf("failed app.CurrentSeat(): %w", err)
 			}
@@ -266,6 +277,7 @@ func (app *WorkspaceApp) Rank(ctx context.Context, _ *utils.CommandDetails) erro
 			if err != nil {
 				return fmt.Errorf("in GetSeatAppearance: %w", err)
 			}
+			seatAppearance = utils.ApplySupporterAppearance(seatAppearance, userDoc.SupporterAppearanceUntil, app.currentTime())
 
 			// 席の色を更新
 			currentSeat.Appearance = seatAppearance
@@ -283,3 +295,93 @@ func (app *WorkspaceApp) Rank(ctx context.Context, _ *utils.CommandDetails) erro
 	app.MessageToLiveChat(ctx, replyMessage)
 	return txErr
 }
+
+func (app *WorkspaceApp) History(ctx context.Context, historyOption *utils.HistoryOption) error {
+	jstNow := app.currentTime()
+	from := jstNow.AddDate(0, 0, -utils.HistoryLookbackDays)
+	// NOTE: 進行中のセグメントはまだ書き込まれていないため、入室中のセッションは途中までの集計になる。
+	segments, err := app.Repository.ReadWorkSegmentsByUserIDAndTimeRange(ctx, app.ProcessedUserID, from, jstNow, utils.HistoryMaxSegments)
+	if err != nil {
+		app.MessageToLiveChat(ctx, i18nmsg.CommandError(app.ProcessedUserDisplayName))
+		return fmt.Errorf("in ReadWorkSegmentsByUserIDAndTimeRange(): %w", err)
+	}
+
+	sessions := utils.SummarizeWorkSessions(segments, len(segments))
+	// 上限件数まで読み込んだ場合、最も古いセッションは途中までしか読めていないことがあるので除く
+	if len(segments) == utils.HistoryMaxSegments && len(sessions) > 1 {
+		sessions = sessions[:len(sessions)-1]
+	}
+	if len(sessions) > historyOption.SessionCount {
+		sessions = sessions[:historyOption.SessionCount]
+	}
+	if len(sessions) == 0 {
+		app.MessageToLiveChat(ctx, i18nmsg.CommandHistoryNoHistory(app.ProcessedUserDisplayName, utils.HistoryLookbackDays))
+		return nil
+	}
+
+	replyMessage := i18nmsg.CommandHistoryHeader(app.ProcessedUserDisplayName, len(sessions))
+	for _, session := range sessions {
+		workNames := make([]string, 0, len(session.WorkNameDurations))
+		for _, w := range session.WorkNameDurations {
+			workName := w.WorkName
+			if workName == "" {
+				workName = i18nmsg.CommandHistoryNoWorkName()
+			}
+			workNames = append(workNames, i18nmsg.CommandHistoryWorkName(workName, timeutil.DurationToString(w.Duration)))
+		}
+		startedAt := session.StartedAt.In(timeutil.JapanLocation())
+		replyMessage += i18nmsg.CommandHistorySession(
+			int(startedAt.Month()),
+			startedAt.Day(),
+			presenter.SeatIDStr(session.SeatID, session.IsMemberSeat),
+			timeutil.DurationToString(session.WorkDuration),
+			timeutil.DurationToString(session.BreakDuration),
+			strings.Join(workNames, i18nmsg.CommandHistoryWorkNameSeparator()),
+		)
+	}
+	app.MessageToLiveChat(ctx, replyMessage)
+	return nil
+}
+
+// Streak 現在の連続入室日数と最長記録を返信する。最長記録は日次でしか更新されないので、現在の日数の方が長ければそちらを表示する。
+func (app *WorkspaceApp) Streak(ctx context.Context) error {
+	jstNow := app.currentTime()
+	userDoc, err := app.Repository.ReadUser(ctx, nil, app.ProcessedUserID)
+	if err != nil {
+		app.MessageToLiveChat(ctx, i18nmsg.CommandError(app.ProcessedUserDisplayName))
+		return fmt.Errorf("in ReadUser(): %w", err)
+	}
+
+	lastActiveAt := utils.LastActiveAt(userDoc.LastEntered, userDoc.LastExited, jstNow)
+	streakDays, err := utils.CalcStreakDays(userDoc.IsContinuousActive, userDoc.CurrentActivityStateStarted, lastActiveAt, jstNow)
+	if err != nil {
+		app.MessageToLiveChat(ctx, i18nmsg.CommandError(app.ProcessedUserDisplayName))
+		return fmt.Errorf("in CalcStreakDays(): %w", err)
+	}
+	bestStreakDays := max(userDoc.BestStreakDays, streakDays)
+
+	app.MessageToLiveChat(ctx, i18nmsg.CommandStreakStreak(app.ProcessedUserDisplayName, streakDays, bestStreakDays))
+	return nil
+}
+
+func (app *WorkspaceApp) Help(ctx context.Context, helpOption *utils.HelpOption) error {
+	if helpOption.Topic == "" {
+		commandNames := make([]string, 0, len(utils.CommandSpecs()))
+		for _, spec := range utils.CommandSpecs() {
+			if spec.ModeratorOnly && !app.ProcessedUserIsModeratorOrOwner {
+				continue
+			}
+			commandNames = append(commandNames, spec.Names[0])
+		}
+		app.MessageToLiveChat(ctx, i18nmsg.CommandHelpList(app.ProcessedUserDisplayName, strings.Join(commandNames, " ")))
+		return nil
+	}
+
+	usage, ok := utils.HelpUsage(helpOption.Topic, app.ProcessedUserIsModeratorOrOwner)
+	if !ok {
+		app.MessageToLiveChat(ctx, i18nmsg.CommandHelpUnknownTopic(app.ProcessedUserDisplayName, helpOption.Topic))
+		return nil
+	}
+	app.MessageToLiveChat(ctx, i18nmsg.CommonSir(app.ProcessedUserDisplayName)+usage)
+	return nil
+}
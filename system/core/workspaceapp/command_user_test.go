@@ -6,7 +6,6 @@ import (
 	"testing"
 	"time"
 
-	"cloud.google.com/go/firestore"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
 	"google.golang.org/grpc/codes"
@@ -67,15 +66,12 @@ func TestSystem_ShowUserInfo(t *testing.T) {
 	for _, tt := range showUserInfoTestCases {
 		t.Run(tt.name, func(t *testing.T) {
 			mockDB := mock_myfirestore.NewMockRepository(ctrl)
-			mockFirestoreClient := mock_myfirestore.NewMockDBClient(ctrl)
-			mockFirestoreClient.EXPECT().RunTransaction(gomock.Any(), gomock.Any()).
+			mockDB.EXPECT().RunTransaction(gomock.Any(), gomock.Any()).
 				DoAndReturn(
-					func(ctx context.Context, f func(context.Context, *firestore.Transaction) error, opts ...firestore.TransactionOption) error {
-						tx := &firestore.Transaction{}
-						return f(ctx, tx)
+					func(ctx context.Context, f func(context.Context, repository.Transaction) error) error {
+						return f(ctx, nil)
 					},
 				).AnyTimes()
-			mockDB.EXPECT().FirestoreClient().Return(mockFirestoreClient).AnyTimes()
 			mockDB.EXPECT().ReadUser(gomock.Any(), gomock.Any(), "test_user_id").Return(repository.UserDoc{}, nil).AnyTimes()
 			if tt.currentSeatDoc != nil {
 				mockDB.EXPECT().ReadSeatWithUserID(gomock.Any(), "test_user_id", tt.userIsMember).Return(*tt.currentSeatDoc, nil).AnyTimes()
@@ -90,7 +86,7 @@ func TestSystem_ShowUserInfo(t *testing.T) {
 
 			app := WorkspaceApp{
 				Repository:               mockDB,
-				LiveChatBot:              mockLiveChatBot,
+				ChatSources:              youtubeChatSources(mockLiveChatBot),
 				alertOwnerBot:            moderatorbot.DummyMessageBot{},
 				ProcessedUserID:          "test_user_id",
 				ProcessedUserDisplayName: "テストユーザー",
@@ -154,17 +150,15 @@ func TestSystem_Rank(t *testing.T) {
 	for _, tt := range rankTestCases {
 		t.Run(tt.name, func(t *testing.T) {
 			mockDB := mock_myfirestore.NewMockRepository(ctrl)
-			mockFirestoreClient := mock_myfirestore.NewMockDBClient(ctrl)
-			mockFirestoreClient.EXPECT().RunTransaction(gomock.Any(), gomock.Any()).
+			mockDB.EXPECT().RunTransaction(gomock.Any(), gomock.Any()).
 				DoAndReturn(
-					func(ctx context.Context, f func(context.Context, *firestore.Transaction) error, opts ...firestore.TransactionOption) error {
-						tx := &firestore.Transaction{}
-						return f(ctx, tx)
+					func(ctx context.Context, f func(context.Context, repository.Transaction) error) error {
+						return f(ctx, nil)
 					},
 				).AnyTimes()
-			mockDB.EXPECT().FirestoreClient().Return(mockFirestoreClient).AnyTimes()
 			mockDB.EXPECT().ReadGeneralSeats(gomock.Any()).Return([]repository.SeatDoc{}, nil).AnyTimes()
 			mockDB.EXPECT().ReadMemberSeats(gomock.Any()).Return([]repository.SeatDoc{}, nil).AnyTimes()
+			mockDB.EXPECT().ReadSeatReservationsStartBefore(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]repository.SeatReservationDoc{}, nil).AnyTimes()
 			mockDB.EXPECT().ReadUser(gomock.Any(), gomock.Any(), "test_user_id").Return(tt.currentUserDoc, nil).AnyTimes()
 			mockDB.EXPECT().ReadSeatWithUserID(gomock.Any(), "test_user_id", tt.userIsMember).Return(repository.SeatDoc{}, status.Errorf(codes.NotFound, "")).AnyTimes()
 			mockDB.EXPECT().ReadSeatWithUserID(gomock.Any(), "test_user_id", !tt.userIsMember).Return(repository.SeatDoc{}, status.Errorf(codes.NotFound, "")).AnyTimes()
@@ -176,7 +170,7 @@ func TestSystem_Rank(t *testing.T) {
 
 			app := WorkspaceApp{
 				Repository:               mockDB,
-				LiveChatBot:              mockLiveChatBot,
+				ChatSources:              youtubeChatSources(mockLiveChatBot),
 				alertOwnerBot:            moderatorbot.DummyMessageBot{},
 				ProcessedUserID:          "test_user_id",
 				ProcessedUserDisplayName: "テストユーザー",
@@ -309,6 +303,44 @@ func TestSystem_My(t *testing.T) {
 			},
 			expectedReplyMessage: "@テストユーザー さん、デフォルトの作業時間を60分に設定しました⏱️",
 		},
+		{
+			name: "1日の目標作業時間設定",
+			constantsConfig: repository.ConstantsConfigDoc{
+				MaxSeats: 10,
+			},
+			commandDetails: utils.CommandDetails{
+				CommandType: utils.My,
+				MyOptions: []utils.MyOption{
+					{
+						Type:     utils.DailyGoalMin,
+						IntValue: 180,
+					},
+				},
+			},
+			userIsMember:         false,
+			currentUserDoc:       repository.UserDoc{},
+			expectedReplyMessage: "@テストユーザー さん、1日の目標作業時間を180分に設定しました🎯",
+		},
+		{
+			name: "1日の目標作業時間リセット",
+			constantsConfig: repository.ConstantsConfigDoc{
+				MaxSeats: 10,
+			},
+			commandDetails: utils.CommandDetails{
+				CommandType: utils.My,
+				MyOptions: []utils.MyOption{
+					{
+						Type:     utils.DailyGoalMin,
+						IntValue: 0,
+					},
+				},
+			},
+			userIsMember: false,
+			currentUserDoc: repository.UserDoc{
+				DailyGoalMin: 180,
+			},
+			expectedReplyMessage: "@テストユーザー さん、1日の目標作業時間をリセットしました🔄",
+		},
 		{
 			name: "お気に入りカラーを設定（まだ使用不可）",
 			constantsConfig: repository.ConstantsConfigDoc{
@@ -355,17 +387,15 @@ func TestSystem_My(t *testing.T) {
 	for _, tt := range myTestCases {
 		t.Run(tt.name, func(t *testing.T) {
 			mockDB := mock_myfirestore.NewMockRepository(ctrl)
-			mockFirestoreClient := mock_myfirestore.NewMockDBClient(ctrl)
-			mockFirestoreClient.EXPECT().RunTransaction(gomock.Any(), gomock.Any()).
+			mockDB.EXPECT().RunTransaction(gomock.Any(), gomock.Any()).
 				DoAndReturn(
-					func(ctx context.Context, f func(context.Context, *firestore.Transaction) error, opts ...firestore.TransactionOption) error {
-						tx := &firestore.Transaction{}
-						return f(ctx, tx)
+					func(ctx context.Context, f func(context.Context, repository.Transaction) error) error {
+						return f(ctx, nil)
 					},
 				).AnyTimes()
-			mockDB.EXPECT().FirestoreClient().Return(mockFirestoreClient).AnyTimes()
 			mockDB.EXPECT().ReadGeneralSeats(gomock.Any()).Return([]repository.SeatDoc{}, nil).AnyTimes()
 			mockDB.EXPECT().ReadMemberSeats(gomock.Any()).Return([]repository.SeatDoc{}, nil).AnyTimes()
+			mockDB.EXPECT().ReadSeatReservationsStartBefore(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]repository.SeatReservationDoc{}, nil).AnyTimes()
 			mockDB.EXPECT().ReadUser(gomock.Any(), gomock.Any(), "test_user_id").Return(tt.currentUserDoc, nil).AnyTimes()
 			mockDB.EXPECT().ReadSeatWithUserID(gomock.Any(), "test_user_id", tt.userIsMember).Return(repository.SeatDoc{}, status.Errorf(codes.NotFound, "")).AnyTimes()
 			mockDB.EXPECT().ReadSeatWithUserID(gomock.Any(), "test_user_id", !tt.userIsMember).Return(repository.SeatDoc{}, status.Errorf(codes.NotFound, "")).AnyTimes()
@@ -373,13 +403,14 @@ func TestSystem_My(t *testing.T) {
 			mockDB.EXPECT().CreateUserActivityDoc(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 			mockDB.EXPECT().UpdateUserDefaultStudyMin(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).MaxTimes(1)
 			mockDB.EXPECT().UpdateUserFavoriteColor(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).MaxTimes(1)
+			mockDB.EXPECT().UpdateUserDailyGoalMin(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).MaxTimes(1)
 
 			mockLiveChatBot := mock_youtubebot.NewMockLiveChatBot(ctrl)
 			mockLiveChatBot.EXPECT().PostMessage(gomock.Any(), tt.expectedReplyMessage).Return(nil).Times(1)
 
 			app := WorkspaceApp{
 				Repository:               mockDB,
-				LiveChatBot:              mockLiveChatBot,
+				ChatSources:              youtubeChatSources(mockLiveChatBot),
 				alertOwnerBot:            moderatorbot.DummyMessageBot{},
 				ProcessedUserID:          "test_user_id",
 				ProcessedUserDisplayName: "テストユーザー",
@@ -399,3 +430,255 @@ func TestSystem_My(t *testing.T) {
 		})
 	}
 }
+
+func TestSystem_History(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	fixedNow := time.Date(2026, time.January, 10, 10, 0, 0, 0, timeutil.JapanLocation())
+	sessionStartedAt := time.Date(2026, time.January, 9, 20, 0, 0, 0, timeutil.JapanLocation())
+
+	// 上限件数まで読み込んだ場合：直近のセッションのあとに、途中までしか読めていない古いセッションが続く
+	truncatedSegments := []repository.WorkSegmentDoc{
+		{SessionID: "latest", SeatID: 3, SegmentType: repository.WorkState, StartedAt: sessionStartedAt, DurationSec: 60 * 60},
+	}
+	for i := 1; len(truncatedSegments) < utils.HistoryMaxSegments; i++ {
+		truncatedSegments = append(truncatedSegments, repository.WorkSegmentDoc{
+			SessionID:   "truncated",
+			SeatID:      5,
+			SegmentType: repository.WorkState,
+			StartedAt:   sessionStartedAt.Add(-time.Duration(i) * time.Minute),
+			DurationSec: 60,
+		})
+	}
+
+	historyTestCases := []struct {
+		name                 string
+		historyOption        utils.HistoryOption
+		segments             []repository.WorkSegmentDoc
+		expectedReplyMessage string
+	}{
+		{
+			name:                 "履歴なし",
+			historyOption:        utils.HistoryOption{SessionCount: 1},
+			segments:             []repository.WorkSegmentDoc{},
+			expectedReplyMessage: "@テストユーザー さん、過去30日間の作業履歴はありません📖",
+		},
+		{
+			name:          "直近1回の履歴",
+			historyOption: utils.HistoryOption{SessionCount: 1},
+			segments: []repository.WorkSegmentDoc{
+				{
+					SessionID:   "session",
+					SeatID:      3,
+					SegmentType: repository.WorkState,
+					StartedAt:   sessionStartedAt.Add(90 * time.Minute),
+					DurationSec: 30 * 60,
+				},
+				{
+					SessionID:   "session",
+					SeatID:      3,
+					WorkName:    "散歩",
+					SegmentType: repository.BreakState,
+					StartedAt:   sessionStartedAt.Add(80 * time.Minute),
+					DurationSec: 10 * 60,
+				},
+				{
+					SessionID:   "session",
+					SeatID:      3,
+					WorkName:    "英語",
+					SegmentType: repository.WorkState,
+					StartedAt:   sessionStartedAt,
+					DurationSec: 80 * 60,
+				},
+			},
+			expectedReplyMessage: "@テストユーザー さんの直近1回の作業履歴📖［1/9 3番席 作業1時間50分・休憩10分（英語：1時間20分、作業内容なし：30分）］",
+		},
+		{
+			name:                 "上限件数まで読み込んだ場合は途中までの古いセッションを表示しない",
+			historyOption:        utils.HistoryOption{SessionCount: 5},
+			segments:             truncatedSegments,
+			expectedReplyMessage: "@テストユーザー さんの直近1回の作業履歴📖［1/9 3番席 作業1時間0分・休憩0分（作業内容なし：1時間0分）］",
+		},
+	}
+
+	for _, tt := range historyTestCases {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := mock_myfirestore.NewMockRepository(ctrl)
+			mockDB.EXPECT().ReadWorkSegmentsByUserIDAndTimeRange(gomock.Any(), "test_user_id", fixedNow.AddDate(0, 0, -utils.HistoryLookbackDays), fixedNow, utils.HistoryMaxSegments).Return(tt.segments, nil).Times(1)
+
+			mockLiveChatBot := mock_youtubebot.NewMockLiveChatBot(ctrl)
+			mockLiveChatBot.EXPECT().PostMessage(gomock.Any(), tt.expectedReplyMessage).Return(nil).Times(1)
+
+			app := WorkspaceApp{
+				Repository:               mockDB,
+				ChatSources:              youtubeChatSources(mockLiveChatBot),
+				alertOwnerBot:            moderatorbot.DummyMessageBot{},
+				ProcessedUserID:          "test_user_id",
+				ProcessedUserDisplayName: "テストユーザー",
+				nowFunc:                  func() time.Time { return fixedNow },
+			}
+
+			if err := i18n.LoadLocaleFolderFS(); err != nil {
+				panic(fmt.Errorf("in LoadLocaleFolderFS(): %w", err))
+			}
+
+			// テスト対象の関数を実行
+			err := app.History(context.Background(), &tt.historyOption)
+
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func TestSystem_Streak(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	fixedNow := time.Date(2026, time.January, 10, 10, 0, 0, 0, timeutil.JapanLocation())
+
+	streakTestCases := []struct {
+		name                 string
+		userDoc              repository.UserDoc
+		expectedReplyMessage string
+	}{
+		{
+			name: "連続入室中",
+			userDoc: repository.UserDoc{
+				IsContinuousActive:          true,
+				CurrentActivityStateStarted: fixedNow.AddDate(0, 0, -4),
+				LastEntered:                 fixedNow.Add(-2 * time.Hour),
+				LastExited:                  fixedNow.Add(-time.Hour),
+				BestStreakDays:              12,
+			},
+			expectedReplyMessage: "@テストユーザー さんの連続入室は5日、最長記録は12日です🔥",
+		},
+		{
+			name: "最長記録を更新中",
+			userDoc: repository.UserDoc{
+				IsContinuousActive:          true,
+				CurrentActivityStateStarted: fixedNow.AddDate(0, 0, -4),
+				LastEntered:                 fixedNow.AddDate(0, 0, -1),
+				LastExited:                  fixedNow.AddDate(0, 0, -1).Add(time.Hour),
+				BestStreakDays:              3,
+			},
+			expectedReplyMessage: "@テストユーザー さんの連続入室は4日、最長記録は4日です🔥",
+		},
+		{
+			name: "途切れている",
+			userDoc: repository.UserDoc{
+				IsContinuousActive: false,
+				LastEntered:        fixedNow.AddDate(0, 0, -10),
+				LastExited:         fixedNow.AddDate(0, 0, -10),
+				BestStreakDays:     30,
+			},
+			expectedReplyMessage: "@テストユーザー さんの連続入室は0日、最長記録は30日です🔥",
+		},
+	}
+
+	for _, tt := range streakTestCases {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := mock_myfirestore.NewMockRepository(ctrl)
+			mockDB.EXPECT().ReadUser(gomock.Any(), gomock.Any(), "test_user_id").Return(tt.userDoc, nil).Times(1)
+
+			mockLiveChatBot := mock_youtubebot.NewMockLiveChatBot(ctrl)
+			mockLiveChatBot.EXPECT().PostMessage(gomock.Any(), tt.expectedReplyMessage).Return(nil).Times(1)
+
+			app := WorkspaceApp{
+				Repository:               mockDB,
+				ChatSources:              youtubeChatSources(mockLiveChatBot),
+				alertOwnerBot:            moderatorbot.DummyMessageBot{},
+				ProcessedUserID:          "test_user_id",
+				ProcessedUserDisplayName: "テストユーザー",
+				nowFunc:                  func() time.Time { return fixedNow },
+			}
+
+			if err := i18n.LoadLocaleFolderFS(); err != nil {
+				panic(fmt.Errorf("in LoadLocaleFolderFS(): %w", err))
+			}
+
+			// テスト対象の関数を実行
+			err := app.Streak(context.Background())
+
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func TestSystem_Help(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	helpTestCases := []struct {
+		name                 string
+		helpOption           utils.HelpOption
+		isModeratorOrOwner   bool
+		expectedReplyMessage string
+	}{
+		{
+			name:                 "コマンド一覧",
+			helpOption:           utils.HelpOption{},
+			expectedReplyMessage: "@テストユーザー さん、使えるコマンド：!in !out !undo !info !my !change !seat !report !more !break !resume !rank !order !clear !history !help !reserve !streak。「!help コマンド名」で詳しい使い方を表示します📖",
+		},
+		{
+			name:                 "モデレーターのコマンド一覧",
+			helpOption:           utils.HelpOption{},
+			isModeratorOrOwner:   true,
+			expectedReplyMessage: "@テストユーザー さん、使えるコマンド：!in !out !undo !info !my !change !seat !report !kick !check !block !timeout !more !break !resume !rank !order !clear !history !help !reserve !streak !quota。「!help コマンド名」で詳しい使い方を表示します📖",
+		},
+		{
+			name:                 "コマンドの使い方",
+			helpOption:           utils.HelpOption{Topic: "!break"},
+			expectedReplyMessage: "@テストユーザー さん、!break：休憩します。オプション：work（休憩内容）、min（休憩時間）",
+		},
+		{
+			name:                 "エイリアスの使い方",
+			helpOption:           utils.HelpOption{Topic: "rest"},
+			expectedReplyMessage: "@テストユーザー さん、!break：休憩します。オプション：work（休憩内容）、min（休憩時間）",
+		},
+		{
+			name:                 "オプションの使い方",
+			helpOption:           utils.HelpOption{Topic: "min"},
+			expectedReplyMessage: "@テストユーザー さん、min：作業時間（分）を設定します。例：!in min=60",
+		},
+		{
+			name:                 "コマンドと同名のオプションの使い方",
+			helpOption:           utils.HelpOption{Topic: "rank="},
+			expectedReplyMessage: "@テストユーザー さん、rank：ランク表示のオン・オフを設定します。例：!my rank=on",
+		},
+		{
+			name:                 "モデレーター用のコマンドの使い方",
+			helpOption:           utils.HelpOption{Topic: "kick"},
+			expectedReplyMessage: "@テストユーザー さん、「kick」の使い方は見つかりませんでした。「!help」で使えるコマンドを確認できます📖",
+		},
+		{
+			name:                 "存在しないトピック",
+			helpOption:           utils.HelpOption{Topic: "foo"},
+			expectedReplyMessage: "@テストユーザー さん、「foo」の使い方は見つかりませんでした。「!help」で使えるコマンドを確認できます📖",
+		},
+	}
+
+	for _, tt := range helpTestCases {
+		t.Run(tt.name, func(t *testing.T) {
+			mockLiveChatBot := mock_youtubebot.NewMockLiveChatBot(ctrl)
+			mockLiveChatBot.EXPECT().PostMessage(gomock.Any(), tt.expectedReplyMessage).Return(nil).Times(1)
+
+			app := WorkspaceApp{
+				ChatSources:                     youtubeChatSources(mockLiveChatBot),
+				alertOwnerBot:                   moderatorbot.DummyMessageBot{},
+				ProcessedUserID:                 "test_user_id",
+				ProcessedUserDisplayName:        "テストユーザー",
+				ProcessedUserIsModeratorOrOwner: tt.isModeratorOrOwner,
+			}
+
+			if err := i18n.LoadLocaleFolderFS(); err != nil {
+				panic(fmt.Errorf("in LoadLocaleFolderFS(): %w", err))
+			}
+
+			// テスト対象の関数を実行
+			err := app.Help(context.Background(), &tt.helpOption)
+
+			assert.Nil(t, err)
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package workspaceapp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+
+	"app.modules/core/repository"
+	"app.modules/core/youtubebot"
+)
+
+// diffIgnoredConstantsFields プログラムが自動で更新する項目。反映はするがownerへの通知には含めない
+var diffIgnoredConstantsFields = map[string]bool{
+	"LastResetDailyTotalStudySec":           true,
+	"LastTransferCollectionHistoryBigquery": true,
+	"MaxSeats":                              true,
+	"MemberMaxSeats":                        true,
+	"DesiredMaxSeats":                       true,
+	"DesiredMemberMaxSeats":                 true,
+}
+
+// StartConstantsWatcher config/constantsの変更の購読を始める。購読は座席キャッシュのスナップショットリスナーと共有するため、StartSeatCacheの後に呼ぶ。
+// 受け取った値はすぐには反映せず、ApplyConstantsUpdatesを呼んだときに検証してapp.Configsを差し替える。
+func (app *WorkspaceApp) StartConstantsWatcher(ctx context.Context) {
+	if app.seatCache == nil {
+		slog.InfoContext(ctx, "seat cache is disabled, constants hot-reload disabled")
+		return
+	}
+	updates := make(chan repository.ConstantsConfigDoc, 1)
+	app.seatCache.WatchConstants(func(constants repository.ConstantsConfigDoc) {
+		// 未反映の古い値は捨てて、最新の値だけを残す
+		select {
+		case <-updates:
+		default:
+		}
+		updates <- constants
+	})
+	app.constantsUpdates = updates
+}
+
+// ApplyConstantsUpdates 購読しているconfig/constantsに変更があれば、検証した上でapp.Configsを新しいコピーに差し替える。
+// 変更された項目はownerに通知し、不正な値の場合は反映せずにその旨を通知する。
+// app.Configsを読む処理と同じgoroutineから、処理の区切りで呼ぶこと。
+func (app *WorkspaceApp) ApplyConstantsUpdates(ctx context.Context) {
+	select {
+	case constants := <-app.constantsUpdates:
+		app.applyConstants(ctx, constants)
+	default:
+	}
+}
+
+func (app *WorkspaceApp) applyConstants(ctx context.Context, constants repository.ConstantsConfigDoc) {
+	diff := diffConstants(app.Configs.Constants, constants)
+	if err := validateConstants(constants); err != nil {
+		app.MessageToOwnerWithError(ctx, "config/constantsの変更が不正なため反映しませんでした。\n"+strings.Join(diff, "\n"), err)
+		return
+	}
+
+	configs := *app.Configs
+	configs.Constants = constants
+	app.Configs = &configs
+
+	if len(diff) > 0 {
+		app.MessageToOwner(ctx, "config/constantsの変更を反映しました。\n"+strings.Join(diff, "\n"))
+	}
+}
+
+// diffConstants 値が変わった項目を「項目名: 変更前 → 変更後」の形式で返す。
+func diffConstants(before, after repository.ConstantsConfigDoc) []string {
+	var diff []string
+	beforeValue, afterValue := reflect.ValueOf(before), reflect.ValueOf(after)
+	for i := 0; i < beforeValue.NumField(); i++ {
+		name := beforeValue.Type().Field(i).Name
+		if diffIgnoredConstantsFields[name] {
+			continue
+		}
+		b, a := beforeValue.Field(i).Interface(), afterValue.Field(i).Interface()
+		if !reflect.DeepEqual(b, a) {
+			diff = append(diff, fmt.Sprintf("%s: %v → %v", name, b, a))
+		}
+	}
+	return diff
+}
+
+// validateConstants 稼働中のBotに反映しても問題ない値か確認する。
+func validateConstants(c repository.ConstantsConfigDoc) error {
+	var errs []error
+	if c.MinWorkTimeMin > c.MaxWorkTimeMin {
+		errs = append(errs, fmt.Errorf("MinWorkTimeMin (%d) > MaxWorkTimeMin (%d)", c.MinWorkTimeMin, c.MaxWorkTimeMin))
+	}
+	if c.DefaultWorkTimeMin < c.MinWorkTimeMin || c.MaxWorkTimeMin < c.DefaultWorkTimeMin {
+		errs = append(errs, fmt.Errorf("DefaultWorkTimeMin (%d) is out of [MinWorkTimeMin, MaxWorkTimeMin]", c.DefaultWorkTimeMin))
+	}
+	if c.MinBreakDurationMin > c.MaxBreakDurationMin {
+		errs = append(errs, fmt.Errorf("MinBreakDurationMin (%d) > MaxBreakDurationMin (%d)", c.MinBreakDurationMin, c.MaxBreakDurationMin))
+	}
+	if c.DefaultBreakDurationMin < c.MinBreakDurationMin || c.MaxBreakDurationMin < c.DefaultBreakDurationMin {
+		errs = append(errs, fmt.Errorf("DefaultBreakDurationMin (%d) is out of [MinBreakDurationMin, MaxBreakDurationMin]", c.DefaultBreakDurationMin))
+	}
+	if c.SleepIntervalMilli <= 0 {
+		errs = append(errs, fmt.Errorf("SleepIntervalMilli (%d) must be positive", c.SleepIntervalMilli))
+	}
+	if c.CheckDesiredMaxSeatsIntervalSec <= 0 {
+		errs = append(errs, fmt.Errorf("CheckDesiredMaxSeatsIntervalSec (%d) must be positive", c.CheckDesiredMaxSeatsIntervalSec))
+	}
+	if c.MinVacancyRate < 0 || 1 < c.MinVacancyRate {
+		errs = append(errs, fmt.Errorf("MinVacancyRate (%v) is out of [0, 1]", c.MinVacancyRate))
+	}
+	if _, err := youtubebot.BroadcastSelectorFromConstants(c); err != nil {
+		errs = append(errs, fmt.Errorf("LiveBroadcastSelectionPolicy (%q): %w", c.LiveBroadcastSelectionPolicy, err))
+	}
+	for _, field := range []struct {
+		name  string
+		value int
+	}{
+		{"MaxDailyOrderCount", c.MaxDailyOrderCount},
+		{"LiveChatPostIntervalMilli", c.LiveChatPostIntervalMilli},
+		{"YoutubeAPIDailyQuotaBudget", c.YoutubeAPIDailyQuotaBudget},
+		{"ReservationMaxPerUser", c.ReservationMaxPerUser},
+		{"ReservationLeadMin", c.ReservationLeadMin},
+		{"ReservationNoShowGraceMin", c.ReservationNoShowGraceMin},
+		{"FanFundingRewardRP", c.FanFundingRewardRP},
+		{"FanFundingRewardExtendMin", c.FanFundingRewardExtendMin},
+	} {
+		if field.value < 0 {
+			errs = append(errs, fmt.Errorf("%s (%d) must not be negative", field.name, field.value))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,97 @@
+package workspaceapp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app.modules/core/repository"
+)
+
+func validTestConstants() repository.ConstantsConfigDoc {
+	return repository.ConstantsConfigDoc{
+		MinWorkTimeMin:                  5,
+		MaxWorkTimeMin:                  360,
+		DefaultWorkTimeMin:              60,
+		MinBreakDurationMin:             1,
+		MaxBreakDurationMin:             60,
+		DefaultBreakDurationMin:         10,
+		MaxDailyOrderCount:              5,
+		SleepIntervalMilli:              1000,
+		CheckDesiredMaxSeatsIntervalSec: 60,
+		MaxSeats:                        30,
+	}
+}
+
+func TestApplyConstantsUpdates(t *testing.T) {
+	ctx := context.Background()
+	cache := repository.NewSeatCache()
+	ownerBot := &spyMessageBot{}
+	app := WorkspaceApp{
+		Configs:       &Configs{Constants: validTestConstants(), LiveChatBotChannelID: "bot"},
+		Repository:    repository.NewCachedRepository(repository.NewInMemoryRepository(), cache),
+		alertOwnerBot: ownerBot,
+		seatCache:     cache,
+	}
+	app.StartConstantsWatcher(ctx)
+	onChange := func(constants repository.ConstantsConfigDoc) { cache.UpdateConstants(constants, time.Now()) }
+	previousConfigs := app.Configs
+
+	// 変更がなければ何もしない
+	app.ApplyConstantsUpdates(ctx)
+	assert.Same(t, previousConfigs, app.Configs)
+
+	// 最新の値だけが反映され、変更された項目が通知される。自動で更新される項目は通知しない
+	updated := validTestConstants()
+	updated.MaxDailyOrderCount = 10
+	onChange(updated)
+	updated.MaxWorkTimeMin = 480
+	updated.MaxSeats = 40
+	onChange(updated)
+	app.ApplyConstantsUpdates(ctx)
+
+	assert.Equal(t, updated, app.Configs.Constants)
+	assert.Equal(t, "bot", app.Configs.LiveChatBotChannelID)
+	assert.Equal(t, 5, previousConfigs.Constants.MaxDailyOrderCount, "the previous copy must not be modified")
+	require.Len(t, ownerBot.messages, 1)
+	assert.Contains(t, ownerBot.messages[0], "MaxWorkTimeMin: 360 → 480")
+	assert.Contains(t, ownerBot.messages[0], "MaxDailyOrderCount: 5 → 10")
+	assert.NotContains(t, ownerBot.messages[0], "MaxSeats")
+
+	// 不正な値は反映しない
+	invalid := updated
+	invalid.MinWorkTimeMin = 500
+	onChange(invalid)
+	app.ApplyConstantsUpdates(ctx)
+	assert.Equal(t, updated, app.Configs.Constants)
+	require.Len(t, ownerBot.messagesWithError, 1)
+	assert.Contains(t, ownerBot.messagesWithError[0], "MinWorkTimeMin: 5 → 500")
+}
+
+func TestValidateConstants(t *testing.T) {
+	require.NoError(t, validateConstants(validTestConstants()))
+
+	tests := []struct {
+		name   string
+		modify func(c *repository.ConstantsConfigDoc)
+	}{
+		{"min_work_time_exceeds_max", func(c *repository.ConstantsConfigDoc) { c.MinWorkTimeMin = c.MaxWorkTimeMin + 1 }},
+		{"default_work_time_out_of_range", func(c *repository.ConstantsConfigDoc) { c.DefaultWorkTimeMin = c.MaxWorkTimeMin + 1 }},
+		{"min_break_exceeds_max", func(c *repository.ConstantsConfigDoc) { c.MinBreakDurationMin = c.MaxBreakDurationMin + 1 }},
+		{"zero_sleep_interval", func(c *repository.ConstantsConfigDoc) { c.SleepIntervalMilli = 0 }},
+		{"negative_order_count", func(c *repository.ConstantsConfigDoc) { c.MaxDailyOrderCount = -1 }},
+		{"vacancy_rate_over_one", func(c *repository.ConstantsConfigDoc) { c.MinVacancyRate = 1.5 }},
+		{"unknown_broadcast_policy", func(c *repository.ConstantsConfigDoc) { c.LiveBroadcastSelectionPolicy = "oldest" }},
+		{"title_policy_without_pattern", func(c *repository.ConstantsConfigDoc) { c.LiveBroadcastSelectionPolicy = "title" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			constants := validTestConstants()
+			tt.modify(&constants)
+			assert.Error(t, validateConstants(constants))
+		})
+	}
+}
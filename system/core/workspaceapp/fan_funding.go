@@ -0,0 +1,144 @@
+package workspaceapp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"app.modules/core/repository"
+	"app.modules/core/timeutil"
+	"app.modules/core/utils"
+	"app.modules/core/workspaceapp/presenter"
+	"app.modules/core/workspaceapp/usecase"
+	"app.modules/core/youtubebot"
+)
+
+// ProcessFanFundingEvent スーパーチャットやメンバーシップ加入などの支援に対して、設定された特典を付与し、お礼のメッセージを送信する。
+// 同じメッセージIDのイベントは一度だけ処理する。
+func (app *WorkspaceApp) ProcessFanFundingEvent(ctx context.Context, event youtubebot.FanFundingEvent) error {
+	constants := app.Configs.Constants
+	var result usecase.Result
+	var history repository.FanFundingHistoryDoc
+	txErr := app.RunTransaction(ctx, func(ctx context.Context, tx repository.Transaction) error {
+		result = usecase.Result{}
+		jstNow := app.currentTime()
+		history = repository.FanFundingHistoryDoc{
+			EventType:           event.Type,
+			UserID:              event.UserID,
+			UserDisplayName:     event.DisplayName,
+			AmountMicros:        int64(event.AmountMicros),
+			Currency:            event.Currency,
+			AmountDisplayString: event.AmountDisplayString,
+			MembershipLevelName: event.MembershipLevelName,
+			GiftCount:           event.GiftCount,
+			PublishedAt:         event.PublishedAt.In(timeutil.JapanLocation()),
+		}
+		result.Add(usecase.FanFundingThanked{
+			EventType:           event.Type,
+			AmountDisplayString: event.AmountDisplayString,
+			GiftCount:           event.GiftCount,
+		})
+
+		// 特典はユーザーのドキュメントがある場合のみ付与する
+		userDoc, err := app.Repository.ReadUser(ctx, tx, event.UserID)
+		if err != nil {
+			if status.Code(err) != codes.NotFound {
+				return fmt.Errorf("in ReadUser(): %w", err)
+			}
+			if err := app.Repository.CreateFanFundingHistoryDoc(ctx, tx, event.MessageID, history); err != nil {
+				return fmt.Errorf("in CreateFanFundingHistoryDoc(): %w", err)
+			}
+			return nil
+		}
+
+		isInMemberRoom, isInGeneralRoom, err := app.IsUserInRoom(ctx, event.UserID)
+		if err != nil {
+			return fmt.Errorf("in IsUserInRoom(): %w", err)
+		}
+		isInRoom := isInMemberRoom || isInGeneralRoom
+		var currentSeat repository.SeatDoc
+		var realtimeTotalStudySec int
+		if isInRoom {
+			currentSeat, err = app.CurrentSeat(ctx, tx, event.UserID, isInMemberRoom)
+			if err != nil {
+				return fmt.Errorf("in CurrentSeat(): %w", err)
+			}
+			totalStudyDuration, _, err := app.GetUserRealtimeTotalStudyDurations(ctx, tx, event.UserID)
+			if err != nil {
+				return fmt.Errorf("in GetUserRealtimeTotalStudyDurations(): %w", err)
+			}
+			realtimeTotalStudySec = int(totalStudyDuration.Seconds())
+		}
+
+		// 以降書き込みのみ
+		newRP := userDoc.RankPoint
+		if constants.FanFundingRewardRP > 0 {
+			newRP += constants.FanFundingRewardRP
+			if err := app.Repository.UpdateUserRankPoint(tx, event.UserID, newRP); err != nil {
+				return fmt.Errorf("in UpdateUserRankPoint(): %w", err)
+			}
+			history.RewardRP = constants.FanFundingRewardRP
+			result.Add(usecase.FanFundingRPAwarded{AddedRP: constants.FanFundingRewardRP})
+		}
+
+		seatUpdated := false
+		if constants.FanFundingRewardExtendMin > 0 && isInRoom && currentSeat.State == repository.WorkState {
+			addedMin, _, err := currentSeat.ExtendWorkDuration(jstNow, constants.FanFundingRewardExtendMin, constants.MaxWorkTimeMin)
+			if err != nil {
+				return fmt.Errorf("in ExtendWorkDuration(): %w", err)
+			}
+			if addedMin > 0 {
+				seatUpdated = true
+				history.RewardExtendedMin = addedMin
+				result.Add(usecase.FanFundingUntilExtended{ExtendedMin: addedMin})
+			}
+		}
+
+		supporterAppearanceUntil := userDoc.SupporterAppearanceUntil
+		if constants.FanFundingRewardAppearanceEnabled {
+			// その日の終わり（JST）まで
+			supporterAppearanceUntil = timeutil.StartOfDayJST(jstNow).AddDate(0, 0, 1)
+			if err := app.Repository.UpdateUserSupporterAppearanceUntil(tx, event.UserID, supporterAppearanceUntil); err != nil {
+				return fmt.Errorf("in UpdateUserSupporterAppearanceUntil(): %w", err)
+			}
+			history.RewardAppearanceUntil = supporterAppearanceUntil
+			result.Add(usecase.FanFundingAppearanceGranted{})
+		}
+
+		// 入室中であれば、座席の色も変える
+		if isInRoom && (history.RewardRP > 0 || !history.RewardAppearanceUntil.IsZero()) {
+			seatAppearance, err := utils.GetSeatAppearance(realtimeTotalStudySec, userDoc.RankVisible, newRP, userDoc.FavoriteColor)
+			if err != nil {
+				return fmt.Errorf("in GetSeatAppearance(): %w", err)
+			}
+			currentSeat.Appearance = utils.ApplySupporterAppearance(seatAppearance, supporterAppearanceUntil, jstNow)
+			seatUpdated = true
+		}
+		if seatUpdated {
+			if err := app.Repository.UpdateSeat(ctx, tx, currentSeat, isInMemberRoom); err != nil {
+				return fmt.Errorf("in UpdateSeat(): %w", err)
+			}
+		}
+
+		if err := app.Repository.CreateFanFundingHistoryDoc(ctx, tx, event.MessageID, history); err != nil {
+			return fmt.Errorf("in CreateFanFundingHistoryDoc(): %w", err)
+		}
+		return nil
+	})
+	if txErr != nil {
+		if status.Code(txErr) == codes.AlreadyExists {
+			slog.InfoContext(ctx, "fan funding event already processed", "messageID", event.MessageID)
+			return nil
+		}
+		return fmt.Errorf("in RunTransaction(): %w", txErr)
+	}
+
+	app.MessageToLiveChat(ctx, presenter.BuildFanFundingMessage(result, event.DisplayName))
+	app.MessageToOwner(ctx, fmt.Sprintf("Fan funding event: %s by %s (%s) %s\nreward: %dRP, +%dmin, appearance until %s",
+		event.Type, event.DisplayName, event.UserID, event.AmountDisplayString,
+		history.RewardRP, history.RewardExtendedMin, history.RewardAppearanceUntil.Format("2006-01-02 15:04")))
+	return nil
+}
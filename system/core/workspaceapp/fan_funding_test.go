@@ -0,0 +1,127 @@
+package workspaceapp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"app.modules/core/i18n"
+	"app.modules/core/moderatorbot"
+	"app.modules/core/repository"
+	"app.modules/core/timeutil"
+	"app.modules/core/utils"
+	"app.modules/core/youtubebot"
+	mock_youtubebot "app.modules/core/youtubebot/mocks"
+)
+
+func TestWorkspaceApp_ProcessFanFundingEvent(t *testing.T) {
+	require.NoError(t, i18n.LoadLocaleFolderFS())
+	ctrl := gomock.NewController(t)
+	ctx := context.Background()
+	now := time.Date(2026, time.January, 1, 10, 0, 0, 0, timeutil.JapanLocation())
+
+	repo := repository.NewInMemoryRepository()
+	require.NoError(t, repo.CreateUser(ctx, nil, "user", repository.UserDoc{RankPoint: 500}))
+	require.NoError(t, repo.CreateSeat(nil, repository.SeatDoc{
+		SeatID:                  3,
+		UserID:                  "user",
+		EnteredAt:               now.Add(-30 * time.Minute),
+		Until:                   now.Add(time.Hour),
+		State:                   repository.WorkState,
+		CurrentStateStartedAt:   now.Add(-30 * time.Minute),
+		CurrentStateUntil:       now.Add(time.Hour),
+		CurrentSegmentStartedAt: now.Add(-30 * time.Minute),
+	}, false))
+
+	mockLiveChatBot := mock_youtubebot.NewMockLiveChatBot(ctrl)
+	mockLiveChatBot.EXPECT().PostMessage(gomock.Any(),
+		"@テストユーザー さん、スーパーチャット（￥1,000）ありがとうございます🎉100RPを進呈しました✨作業時間を30分延長しました⏱️今日は座席が特別カラーになります🌟",
+	).Return(nil).Times(1)
+	app := WorkspaceApp{
+		Configs: &Configs{Constants: repository.ConstantsConfigDoc{
+			MaxWorkTimeMin:                    360,
+			FanFundingRewardRP:                100,
+			FanFundingRewardExtendMin:         30,
+			FanFundingRewardAppearanceEnabled: true,
+		}},
+		Repository:    repo,
+		ChatSources:   youtubeChatSources(mockLiveChatBot),
+		alertOwnerBot: moderatorbot.DummyMessageBot{},
+		nowFunc:       func() time.Time { return now },
+	}
+
+	event := youtubebot.FanFundingEvent{
+		Type:                repository.SuperChatFanFundingEvent,
+		MessageID:           "message-1",
+		UserID:              "user",
+		DisplayName:         "テストユーザー",
+		AmountMicros:        1000000000,
+		Currency:            "JPY",
+		AmountDisplayString: "￥1,000",
+		PublishedAt:         now,
+	}
+	require.NoError(t, app.ProcessFanFundingEvent(ctx, event))
+	// 同じメッセージは二重に処理しない
+	require.NoError(t, app.ProcessFanFundingEvent(ctx, event))
+
+	endOfDay := time.Date(2026, time.January, 2, 0, 0, 0, 0, timeutil.JapanLocation())
+	user, err := repo.ReadUser(ctx, nil, "user")
+	require.NoError(t, err)
+	assert.Equal(t, 600, user.RankPoint)
+	assert.True(t, user.SupporterAppearanceUntil.Equal(endOfDay))
+
+	seat, err := repo.ReadSeat(ctx, nil, 3, false)
+	require.NoError(t, err)
+	assert.True(t, seat.Until.Equal(now.Add(90*time.Minute)))
+	assert.Equal(t, utils.ColorSupporter1, seat.Appearance.ColorCode1)
+	assert.Equal(t, utils.ColorSupporter2, seat.Appearance.ColorCode2)
+
+	histories, err := repo.ReadFanFundingHistoryByUserID(ctx, "user")
+	require.NoError(t, err)
+	require.Len(t, histories, 1)
+	assert.Equal(t, repository.SuperChatFanFundingEvent, histories[0].EventType)
+	assert.Equal(t, int64(1000000000), histories[0].AmountMicros)
+	assert.Equal(t, 100, histories[0].RewardRP)
+	assert.Equal(t, 30, histories[0].RewardExtendedMin)
+	assert.True(t, histories[0].RewardAppearanceUntil.Equal(endOfDay))
+}
+
+func TestWorkspaceApp_ProcessFanFundingEvent_UnknownUser(t *testing.T) {
+	require.NoError(t, i18n.LoadLocaleFolderFS())
+	ctrl := gomock.NewController(t)
+	ctx := context.Background()
+	now := time.Date(2026, time.January, 1, 10, 0, 0, 0, timeutil.JapanLocation())
+
+	repo := repository.NewInMemoryRepository()
+	mockLiveChatBot := mock_youtubebot.NewMockLiveChatBot(ctrl)
+	mockLiveChatBot.EXPECT().PostMessage(gomock.Any(), "@新しいメンバー さん、メンバーシップへのご加入ありがとうございます🍀").Return(nil).Times(1)
+	app := WorkspaceApp{
+		Configs: &Configs{Constants: repository.ConstantsConfigDoc{
+			FanFundingRewardRP:                100,
+			FanFundingRewardAppearanceEnabled: true,
+		}},
+		Repository:    repo,
+		ChatSources:   youtubeChatSources(mockLiveChatBot),
+		alertOwnerBot: moderatorbot.DummyMessageBot{},
+		nowFunc:       func() time.Time { return now },
+	}
+
+	// 一度も利用していないユーザーには特典を付与せず、記録とお礼のみ
+	require.NoError(t, app.ProcessFanFundingEvent(ctx, youtubebot.FanFundingEvent{
+		Type:        repository.NewMembershipFanFundingEvent,
+		MessageID:   "message-2",
+		UserID:      "new-user",
+		DisplayName: "新しいメンバー",
+		PublishedAt: now,
+	}))
+
+	histories, err := repo.ReadFanFundingHistoryByUserID(ctx, "new-user")
+	require.NoError(t, err)
+	require.Len(t, histories, 1)
+	assert.Zero(t, histories[0].RewardRP)
+	assert.True(t, histories[0].RewardAppearanceUntil.IsZero())
+}
@@ -1,12 +1,19 @@
 package workspaceapp
 
-import "slices"
+import (
+	"maps"
+	"slices"
+	"time"
+)
 
 type NGWordConfig struct {
 	blockRegexesForChatMessage        []string
 	blockRegexesForChannelName        []string
 	notificationRegexesForChatMessage []string
 	notificationRegexesForChannelName []string
+
+	// ブロック用の正規表現ごとのタイムアウトの時間。含まれない正規表現に一致した場合は無期限でブロックする
+	blockTimeouts map[string]time.Duration
 }
 
 func NewNGWordConfig(
@@ -23,6 +30,17 @@ func NewNGWordConfig(
 	}
 }
 
+// WithBlockTimeouts ブロック用の正規表現のうち、timeoutsに含まれるものに一致した場合はその時間だけのタイムアウトにする
+func (c NGWordConfig) WithBlockTimeouts(timeouts map[string]time.Duration) NGWordConfig {
+	c.blockTimeouts = maps.Clone(timeouts)
+	return c
+}
+
+// blockTimeout regexに一致したときのタイムアウトの時間。0なら無期限のブロック
+func (c NGWordConfig) blockTimeout(regex string) time.Duration {
+	return c.blockTimeouts[regex]
+}
+
 func (c NGWordConfig) Count() int {
 	return len(c.blockRegexesForChatMessage) +
 		len(c.blockRegexesForChannelName) +
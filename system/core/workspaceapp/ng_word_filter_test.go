@@ -8,6 +8,7 @@ import (
 
 	"go.uber.org/mock/gomock"
 
+	"app.modules/core/repository"
 	"app.modules/core/timeutil"
 	mock_youtubebot "app.modules/core/youtubebot/mocks"
 )
@@ -35,7 +36,7 @@ func TestCheckIfUnwantedWordIncluded_BlocksByChatMessageRegex(t *testing.T) {
 	mockLiveChatBot := mock_youtubebot.NewMockLiveChatBot(ctrl)
 	mockLiveChatBot.EXPECT().
 		BanUser(gomock.Any(), "test_user_id").
-		Return(nil).
+		Return("ban-1", nil).
 		Times(1)
 
 	logBot := &spyMessageBot{}
@@ -70,6 +71,83 @@ func TestCheckIfUnwantedWordIncluded_BlocksByChatMessageRegex(t *testing.T) {
 	if got, want := len(alertBot.messages), 0; got != want {
 		t.Fatalf("alert messages len = %d, want %d", got, want)
 	}
+
+	actions, err := app.Repository.ReadModerationActionsByTargetUserID(ctx, "test_user_id")
+	if err != nil {
+		t.Fatalf("ReadModerationActionsByTargetUserID() error = %v", err)
+	}
+	want := repository.ModerationActionDoc{
+		ActionType:        repository.NGWordBanModerationAction,
+		TargetUserID:      "test_user_id",
+		TargetDisplayName: "テストユーザー",
+		MatchedRegex:      "荒らし",
+		MessageText:       "これは荒らしです",
+		BanID:             "ban-1",
+		TakenAt:           app.currentTime(),
+	}
+	if len(actions) != 1 || actions[0] != want {
+		t.Fatalf("moderation actions = %+v, want [%+v]", actions, want)
+	}
+}
+
+func TestCheckIfUnwantedWordIncluded_TimesOutByChannelNameRegex(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mockLiveChatBot := mock_youtubebot.NewMockLiveChatBot(ctrl)
+	mockLiveChatBot.EXPECT().
+		TimeOut(gomock.Any(), "test_user_id", 10*time.Minute).
+		Return("ban-1", nil).
+		Times(1)
+
+	logBot := &spyMessageBot{}
+	alertBot := &spyMessageBot{}
+	app := newTestNGWordFilterApp(mockLiveChatBot, logBot, alertBot)
+	ngWordConfig := NewNGWordConfig(
+		[]string{"荒らし", "宣伝"},
+		[]string{"宣伝"},
+		nil,
+		nil,
+	).WithBlockTimeouts(map[string]time.Duration{"宣伝": 10 * time.Minute})
+
+	blocked, err := app.CheckIfUnwantedWordIncluded(
+		ctx,
+		ngWordConfig,
+		"test_user_id",
+		"こんにちは",
+		"宣伝チャンネル",
+	)
+	if err != nil {
+		t.Fatalf("CheckIfUnwantedWordIncluded() error = %v", err)
+	}
+	if !blocked {
+		t.Fatal("blocked = false, want true")
+	}
+	if got, want := len(logBot.messages), 1; got != want {
+		t.Fatalf("log messages len = %d, want %d", got, want)
+	}
+	if !strings.Contains(logBot.messages[0], "10分間タイムアウト") {
+		t.Fatalf("log message does not mention the timeout: %q", logBot.messages[0])
+	}
+
+	actions, err := app.Repository.ReadModerationActionsByTargetUserID(ctx, "test_user_id")
+	if err != nil {
+		t.Fatalf("ReadModerationActionsByTargetUserID() error = %v", err)
+	}
+	want := repository.ModerationActionDoc{
+		ActionType:         repository.NGWordTimeoutModerationAction,
+		TargetUserID:       "test_user_id",
+		TargetDisplayName:  "宣伝チャンネル",
+		MatchedRegex:       "宣伝",
+		MessageText:        "宣伝チャンネル",
+		BanID:              "ban-1",
+		TimeoutDurationSec: 600,
+		TakenAt:            app.currentTime(),
+	}
+	if len(actions) != 1 || actions[0] != want {
+		t.Fatalf("moderation actions = %+v, want [%+v]", actions, want)
+	}
 }
 
 func TestCheckIfUnwantedWordIncluded_NotifiesByChatMessageRegex(t *testing.T) {
@@ -111,6 +189,14 @@ func TestCheckIfUnwantedWordIncluded_NotifiesByChatMessageRegex(t *testing.T) {
 	if got, want := len(logBot.messages), 0; got != want {
 		t.Fatalf("log messages len = %d, want %d", got, want)
 	}
+
+	actions, err := app.Repository.ReadModerationActionsByTargetUserID(ctx, "test_user_id")
+	if err != nil {
+		t.Fatalf("ReadModerationActionsByTargetUserID() error = %v", err)
+	}
+	if len(actions) != 0 {
+		t.Fatalf("moderation actions = %+v, want none", actions)
+	}
 }
 
 func TestCheckIfUnwantedWordIncluded_NoMatch(t *testing.T) {
@@ -159,9 +245,52 @@ func newTestNGWordFilterApp(
 	fixedNow := time.Date(2026, time.January, 1, 10, 0, 0, 0, timeutil.JapanLocation())
 
 	return WorkspaceApp{
-		LiveChatBot:        liveChatBot,
+		Repository:         repository.NewInMemoryRepository(),
+		ChatSources:        youtubeChatSources(liveChatBot),
 		alertModeratorsBot: alertBot,
 		logModeratorsBot:   logBot,
 		nowFunc:            func() time.Time { return fixedNow },
 	}
 }
+
+func TestProcessMessage_DoesNotBlockProcessedMessageAgain(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mockLiveChatBot := mock_youtubebot.NewMockLiveChatBot(ctrl)
+	mockLiveChatBot.EXPECT().
+		BanUser(gomock.Any(), "test_user_id").
+		Return("ban-1", nil).
+		Times(1)
+
+	logBot := &spyMessageBot{}
+	app := newTestNGWordFilterApp(mockLiveChatBot, logBot, &spyMessageBot{})
+	app.Configs = &Configs{}
+	ngWordConfig := NewNGWordConfig(
+		[]string{"荒らし"},
+		nil,
+		nil,
+		nil,
+	)
+
+	// 再起動で同じページを受信し直しても、ブロックやモデレーターへの通知を繰り返さない
+	for range 2 {
+		if err := app.ProcessMessage(ctx, ngWordConfig, "message-1", "これは荒らしです", "test_user_id", "テストユーザー", "", false, false, false); err != nil {
+			t.Fatalf("ProcessMessage() error = %v", err)
+		}
+	}
+	if got, want := len(logBot.messages), 1; got != want {
+		t.Fatalf("log messages len = %d, want %d", got, want)
+	}
+	actions, err := app.Repository.ReadModerationActionsByTargetUserID(ctx, "test_user_id")
+	if err != nil {
+		t.Fatalf("ReadModerationActionsByTargetUserID() error = %v", err)
+	}
+	if got, want := len(actions), 1; got != want {
+		t.Fatalf("moderation actions len = %d, want %d", got, want)
+	}
+	if _, err := app.Repository.ReadProcessedLiveChatMessage(ctx, nil, "message-1"); err != nil {
+		t.Fatalf("ReadProcessedLiveChatMessage() error = %v", err)
+	}
+}
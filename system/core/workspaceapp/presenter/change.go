@@ -31,6 +31,8 @@ func BuildChangeMessage(res usecase.Result, displayName string) string {
 			builder.WriteString(i18nmsg.CommandChangeBreakDurationBefore(e.RequestedMin, e.RealtimeBreakDurationMin, e.RemainingBreakMin))
 		case usecase.ChangeBreakDurationUpdated:
 			builder.WriteString(i18nmsg.CommandChangeBreakDuration(e.RequestedMin, e.RealtimeBreakDurationMin, e.RemainingBreakMin))
+		case usecase.ChangePomodoroUpdated:
+			builder.WriteString(PomodoroSettingMessage(e.WorkMin, e.BreakMin))
 		}
 	}
 	return builder.String()
@@ -0,0 +1,38 @@
+package presenter
+
+import (
+	"strings"
+
+	i18nmsg "app.modules/core/i18n/typed"
+	"app.modules/core/repository"
+	"app.modules/core/workspaceapp/usecase"
+)
+
+// BuildFanFundingMessage converts fan funding events into a localized thank-you message.
+// Namespace: fan-funding
+func BuildFanFundingMessage(res usecase.Result, displayName string) string {
+	var builder strings.Builder
+	builder.WriteString(i18nmsg.CommonSir(displayName))
+	for _, event := range res.Events {
+		switch e := event.(type) {
+		case usecase.FanFundingThanked:
+			switch e.EventType {
+			case repository.SuperChatFanFundingEvent:
+				builder.WriteString(i18nmsg.FanFundingSuperChat(e.AmountDisplayString))
+			case repository.SuperStickerFanFundingEvent:
+				builder.WriteString(i18nmsg.FanFundingSuperSticker(e.AmountDisplayString))
+			case repository.NewMembershipFanFundingEvent:
+				builder.WriteString(i18nmsg.FanFundingNewMembership())
+			case repository.MembershipGiftFanFundingEvent:
+				builder.WriteString(i18nmsg.FanFundingMembershipGift(e.GiftCount))
+			}
+		case usecase.FanFundingRPAwarded:
+			builder.WriteString(i18nmsg.FanFundingRewardRp(e.AddedRP))
+		case usecase.FanFundingUntilExtended:
+			builder.WriteString(i18nmsg.FanFundingRewardExtend(e.ExtendedMin))
+		case usecase.FanFundingAppearanceGranted:
+			builder.WriteString(i18nmsg.FanFundingRewardAppearance())
+		}
+	}
+	return builder.String()
+}
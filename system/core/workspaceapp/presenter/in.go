@@ -28,8 +28,19 @@ func BuildInMessage(res usecase.Result, displayName string) string {
 		case usecase.SeatEntered:
 			seat := SeatIDStr(e.SeatID, e.IsMemberSeat)
 			builder.WriteString(i18nmsg.CommandInStart(displayName, e.WorkName, e.UntilExitMin, seat))
+			if e.PomodoroWorkMin > 0 {
+				builder.WriteString(PomodoroSettingMessage(e.PomodoroWorkMin, e.PomodoroBreakMin))
+			}
 		}
 	}
 	builder.WriteString(order.String())
 	return builder.String()
 }
+
+// PomodoroSettingMessage ポモドーロのサイクルを設定した、またはworkMinが0なら解除したことを示す
+func PomodoroSettingMessage(workMin, breakMin int) string {
+	if workMin == 0 {
+		return i18nmsg.CommandPomodoroCleared()
+	}
+	return i18nmsg.CommandPomodoroSetting(workMin, breakMin)
+}
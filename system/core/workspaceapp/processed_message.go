@@ -0,0 +1,70 @@
+package workspaceapp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"app.modules/core/repository"
+)
+
+// processedLiveChatMessageRetention 処理済みのメッセージを記録しておく期間。
+// 同じページを受信し直すのはページトークンを保存する前に落ちた直後の再起動時なので、長く残す必要はない。
+const processedLiveChatMessageRetention = 7 * 24 * time.Hour
+
+// errLiveChatMessageAlreadyProcessed コマンドやNGワードによるブロックのトランザクションで、メッセージが処理済みだとわかった
+var errLiveChatMessageAlreadyProcessed = errors.New("live chat message already processed")
+
+// processingMessage ProcessMessageで処理中のメッセージ
+type processingMessage struct {
+	id     string
+	userID string
+	// コマンドの実行中か。ユーザーの登録など、コマンドより前のトランザクションでは確認も記録もしない
+	inCommand bool
+	recorded  bool
+	// 処理済みだったか。trueならライブチャットへの返信を送らない
+	duplicate bool
+}
+
+// isProcessingMessageProcessed 処理中のメッセージが処理済みかをトランザクション外で確かめる。
+// NOTE: 同時に同じメッセージを処理した場合に備え、記録するトランザクションの中でも確かめる。
+func (app *WorkspaceApp) isProcessingMessageProcessed(ctx context.Context) (bool, error) {
+	_, err := app.Repository.ReadProcessedLiveChatMessage(ctx, nil, app.processingMessage.id)
+	if err == nil {
+		return true, nil
+	}
+	if status.Code(err) != codes.NotFound {
+		return false, fmt.Errorf("in ReadProcessedLiveChatMessage(): %w", err)
+	}
+	return false, nil
+}
+
+// checkAndRecordProcessingMessage コマンドのトランザクションの最初に処理中のメッセージが処理済みでないことを確かめ、
+// executeの書き込みとともに処理済みとして記録する。処理済みであればexecuteを呼ばずにerrLiveChatMessageAlreadyProcessedを返す。
+func (app *WorkspaceApp) checkAndRecordProcessingMessage(ctx context.Context, tx repository.Transaction,
+	execute func(ctx context.Context, tx repository.Transaction) error,
+) error {
+	message := app.processingMessage
+	if _, err := app.Repository.ReadProcessedLiveChatMessage(ctx, tx, message.id); err == nil {
+		return errLiveChatMessageAlreadyProcessed
+	} else if status.Code(err) != codes.NotFound {
+		return fmt.Errorf("in ReadProcessedLiveChatMessage(): %w", err)
+	}
+	if err := execute(ctx, tx); err != nil {
+		return err
+	}
+	jstNow := app.currentTime()
+	processed := repository.ProcessedLiveChatMessageDoc{
+		UserID:      message.userID,
+		ProcessedAt: jstNow,
+		ExpireAt:    jstNow.Add(processedLiveChatMessageRetention),
+	}
+	if err := app.Repository.CreateProcessedLiveChatMessage(ctx, tx, message.id, processed); err != nil {
+		return fmt.Errorf("in CreateProcessedLiveChatMessage(): %w", err)
+	}
+	return nil
+}
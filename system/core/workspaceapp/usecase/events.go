@@ -7,6 +7,8 @@ package usecase
 // transaction. This decouples write-path side effects from message rendering
 // and preserves existing reply ordering semantics.
 
+import "app.modules/core/repository"
+
 // Event is a marker interface for usecase events.
 // Handlers accumulate these during state changes; presenters render them.
 type Event interface{ isEvent() }
@@ -27,11 +29,14 @@ type SeatMoved struct {
 func (SeatMoved) isEvent() {}
 
 // SeatEntered represents that a user entered a seat.
+// PomodoroWorkMin is zero when pomodoro mode is not set.
 type SeatEntered struct {
-	SeatID       int
-	IsMemberSeat bool
-	WorkName     string
-	UntilExitMin int
+	SeatID           int
+	IsMemberSeat     bool
+	WorkName         string
+	UntilExitMin     int
+	PomodoroWorkMin  int
+	PomodoroBreakMin int
 }
 
 func (SeatEntered) isEvent() {}
@@ -111,6 +116,14 @@ type ChangeBreakDurationUpdated struct {
 
 func (ChangeBreakDurationUpdated) isEvent() {}
 
+// ChangePomodoroUpdated ポモドーロのサイクルを設定した。WorkMinが0なら解除した
+type ChangePomodoroUpdated struct {
+	WorkMin  int
+	BreakMin int
+}
+
+func (ChangePomodoroUpdated) isEvent() {}
+
 // ChangeValidationError represents a validation error occurred in Change usecase (message already localized).
 type ChangeValidationError struct {
 	Message string
@@ -242,6 +255,36 @@ type ClearBreak struct {
 
 func (ClearBreak) isEvent() {}
 
+// ============ Fan funding usecase events ============
+// These events describe a Super Chat / membership event and the rewards
+// granted for it, and are formatted by presenter/fan_funding.go.
+
+// FanFundingThanked represents the fan funding event itself.
+// AmountDisplayString is set for Super Chat / Super Sticker, GiftCount for membership gifts.
+type FanFundingThanked struct {
+	EventType           repository.FanFundingEventType
+	AmountDisplayString string
+	GiftCount           int
+}
+
+func (FanFundingThanked) isEvent() {}
+
+type FanFundingRPAwarded struct {
+	AddedRP int
+}
+
+func (FanFundingRPAwarded) isEvent() {}
+
+type FanFundingUntilExtended struct {
+	ExtendedMin int
+}
+
+func (FanFundingUntilExtended) isEvent() {}
+
+type FanFundingAppearanceGranted struct{}
+
+func (FanFundingAppearanceGranted) isEvent() {}
+
 // Result aggregates events produced by a usecase execution.
 type Result struct {
 	Events []Event
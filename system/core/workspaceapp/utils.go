@@ -6,16 +6,16 @@ import (
 	"fmt"
 	"log/slog"
 	"math/rand"
+	"slices"
 	"strconv"
 	"time"
 
-	"cloud.google.com/go/firestore"
 	"github.com/kr/pretty"
 	"google.golang.org/api/iterator"
-	"google.golang.org/api/youtube/v3"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	"app.modules/core/chat"
 	"app.modules/core/guardians"
 	i18nmsg "app.modules/core/i18n/typed"
 	"app.modules/core/repository"
@@ -23,7 +23,6 @@ import (
 	"app.modules/core/timeutil"
 	"app.modules/core/utils"
 	"app.modules/core/workspaceapp/presenter"
-	"app.modules/core/youtubebot"
 )
 
 // IsSeatExist 席番号1～max-seatsの席かどうかを判定。
@@ -39,8 +38,41 @@ func (app *WorkspaceApp) IsSeatExist(ctx context.Context, seatID int, isMemberSe
 	}
 }
 
+// SeatIDsReservedByOthers 他のユーザーが予約していて、leadMin分後までに予約時間が始まる席番号の集合を返す。
+func (app *WorkspaceApp) SeatIDsReservedByOthers(ctx context.Context, userID string, isMemberSeat bool, leadMin int) (map[int]bool, error) {
+	jstNow := app.currentTime()
+	reservations, err := app.Repository.ReadSeatReservationsStartBefore(ctx, jstNow.Add(time.Duration(leadMin)*time.Minute), isMemberSeat, utils.SeatReservationsReadLimit)
+	if err != nil {
+		return nil, fmt.Errorf("in ReadSeatReservationsStartBefore(): %w", err)
+	}
+	reservedSeatIDs := make(map[int]bool)
+	for _, reservation := range reservations {
+		if reservation.UserID == userID || !reservation.Until.After(jstNow) {
+			continue
+		}
+		reservedSeatIDs[reservation.SeatID] = true
+	}
+	return reservedSeatIDs, nil
+}
+
+// UserUsedSeatSince userIDのユーザーがsince以降にその席へ入室または退室したかどうか。
+func (app *WorkspaceApp) UserUsedSeatSince(ctx context.Context, userID string, seatID int, isMemberSeat bool, since time.Time) (bool, error) {
+	enterActivities, err := app.Repository.GetEnterRoomUserActivityDocIDsAfterDateForUserAndSeat(ctx, since, userID, seatID, isMemberSeat)
+	if err != nil {
+		return false, fmt.Errorf("in GetEnterRoomUserActivityDocIDsAfterDateForUserAndSeat(): %w", err)
+	}
+	if len(enterActivities) > 0 {
+		return true, nil
+	}
+	exitActivities, err := app.Repository.GetExitRoomUserActivityDocIDsAfterDateForUserAndSeat(ctx, since, userID, seatID, isMemberSeat)
+	if err != nil {
+		return false, fmt.Errorf("in GetExitRoomUserActivityDocIDsAfterDateForUserAndSeat(): %w", err)
+	}
+	return len(exitActivities) > 0, nil
+}
+
 // IfSeatVacant 席番号がseatIDの席が空いているかどうか。
-func (app *WorkspaceApp) IfSeatVacant(ctx context.Context, tx *firestore.Transaction, seatID int, isMemberSeat bool) (bool, error) {
+func (app *WorkspaceApp) IfSeatVacant(ctx context.Context, tx repository.Transaction, seatID int, isMemberSeat bool) (bool, error) {
 	_, err := app.Repository.ReadSeat(ctx, tx, seatID, isMemberSeat)
 	if err != nil {
 		if status.Code(err) == codes.NotFound { // その座席のドキュメントは存在しない
@@ -57,7 +89,7 @@ func (app *WorkspaceApp) IfSeatVacant(ctx context.Context, tx *firestore.Transac
 	return false, nil
 }
 
-func (app *WorkspaceApp) IfUserRegistered(ctx context.Context, tx *firestore.Transaction) (bool, error) {
+func (app *WorkspaceApp) IfUserRegistered(ctx context.Context, tx repository.Transaction) (bool, error) {
 	_, err := app.Repository.ReadUser(ctx, tx, app.ProcessedUserID)
 	if err != nil {
 		if status.Code(err) == codes.NotFound {
@@ -93,7 +125,7 @@ func (app *WorkspaceApp) IsUserInRoom(ctx context.Context, userID string) (isInM
 	return isInMemberRoom, isInGeneralRoom, nil
 }
 
-func (app *WorkspaceApp) CreateUser(ctx context.Context, tx *firestore.Transaction) error {
+func (app *WorkspaceApp) CreateUser(ctx context.Context, tx repository.Transaction) error {
 	slog.Info(utils.NameOf(app.CreateUser))
 	userData := repository.UserDoc{
 		DailyTotalStudySec: 0,
@@ -106,7 +138,7 @@ func (app *WorkspaceApp) CreateUser(ctx context.Context, tx *firestore.Transacti
 	return nil
 }
 
-func (app *WorkspaceApp) GetNextPageToken(ctx context.Context, tx *firestore.Transaction) (string, error) {
+func (app *WorkspaceApp) GetNextPageToken(ctx context.Context, tx repository.Transaction) (string, error) {
 	pageToken, err := app.Repository.ReadNextPageToken(ctx, tx)
 	if err != nil {
 		return "", fmt.Errorf("read next page token: %w", err)
@@ -121,7 +153,19 @@ func (app *WorkspaceApp) SaveNextPageToken(ctx context.Context, nextPageToken st
 	return nil
 }
 
-func (app *WorkspaceApp) CurrentSeat(ctx context.Context, userID string, isMemberSeat bool) (repository.SeatDoc, error) {
+// UndoExitGraceMin !outの直後に!undoで退室を取り消せる時間（分）。
+// 設定が0（未設定）ならutils.DefaultUndoExitGraceMin分。負の値なら取り消しできないので0未満を返す。
+func (app *WorkspaceApp) UndoExitGraceMin() int {
+	if app.Configs.Constants.UndoExitGraceMin == 0 {
+		return utils.DefaultUndoExitGraceMin
+	}
+	return app.Configs.Constants.UndoExitGraceMin
+}
+
+// CurrentSeat userIDのユーザーが座っている座席。
+// txがnilでなければ、座席をトランザクション内で読み直す。ユーザーIDでの検索はトランザクション外の読み取りのため、
+// その後に他のプロセス（OrganizeDBのLambdaなど）が退室させたり席を変えたりしていないことをトランザクション内で確かめる。
+func (app *WorkspaceApp) CurrentSeat(ctx context.Context, tx repository.Transaction, userID string, isMemberSeat bool) (repository.SeatDoc, error) {
 	seat, err := app.Repository.ReadSeatWithUserID(ctx, userID, isMemberSeat)
 	if err != nil {
 		if status.Code(err) == codes.NotFound {
@@ -129,10 +173,23 @@ func (app *WorkspaceApp) CurrentSeat(ctx context.Context, userID string, isMembe
 		}
 		return repository.SeatDoc{}, fmt.Errorf("in ReadSeatWithUserID: %w", err)
 	}
+	if tx == nil {
+		return seat, nil
+	}
+	seat, err = app.Repository.ReadSeat(ctx, tx, seat.SeatID, isMemberSeat)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return repository.SeatDoc{}, studyspaceerror.ErrUserNotInTheRoom
+		}
+		return repository.SeatDoc{}, fmt.Errorf("in ReadSeat: %w", err)
+	}
+	if seat.UserID != userID {
+		return repository.SeatDoc{}, studyspaceerror.ErrUserNotInTheRoom
+	}
 	return seat, nil
 }
 
-func (app *WorkspaceApp) UpdateTotalWorkTime(tx *firestore.Transaction, userID string, previousUserDoc *repository.UserDoc, newWorkedTimeSec int, newDailyWorkedTimeSec int) error {
+func (app *WorkspaceApp) UpdateTotalWorkTime(tx repository.Transaction, userID string, previousUserDoc *repository.UserDoc, newWorkedTimeSec int, newDailyWorkedTimeSec int) error {
 	// 更新前の値
 	previousTotalSec := previousUserDoc.TotalStudySec
 	previousDailyTotalSec := previousUserDoc.DailyTotalStudySec
@@ -152,7 +209,7 @@ func (app *WorkspaceApp) UpdateTotalWorkTime(tx *firestore.Transaction, userID s
 }
 
 // GetUserRealtimeTotalStudyDurations リアルタイムの累積作業時間・当日累積作業時間を返す。
-func (app *WorkspaceApp) GetUserRealtimeTotalStudyDurations(ctx context.Context, tx *firestore.Transaction, userID string) (time.Duration, time.Duration, error) {
+func (app *WorkspaceApp) GetUserRealtimeTotalStudyDurations(ctx context.Context, tx repository.Transaction, userID string) (time.Duration, time.Duration, error) {
 	jstNow := app.currentTime()
 	// 入室中ならばリアルタイムの作業時間も加算する
 	realtimeDuration := time.Duration(0)
@@ -163,7 +220,7 @@ func (app *WorkspaceApp) GetUserRealtimeTotalStudyDurations(ctx context.Context,
 	}
 	if isInMemberRoom || isInGeneralRoom {
 		// 作業時間を計算
-		currentSeat, err := app.CurrentSeat(ctx, userID, isInMemberRoom)
+		currentSeat, err := app.CurrentSeat(ctx, tx, userID, isInMemberRoom)
 		if err != nil {
 			return 0, 0, fmt.Errorf("failed s.CurrentSeat(): %w", err)
 		}
@@ -213,7 +270,7 @@ func (app *WorkspaceApp) ExitAllUsersInRoom(ctx context.Context, isMemberRoom bo
 		}
 		for _, seatCandidate := range seats {
 			var message string
-			txErr := app.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+			txErr := app.RunTransaction(ctx, func(ctx context.Context, tx repository.Transaction) error {
 				seat, err := app.Repository.ReadSeat(ctx, tx, seatCandidate.SeatID, isMemberRoom)
 				if err != nil {
 					return fmt.Errorf("in ReadSeat: %w", err)
@@ -252,16 +309,18 @@ func (app *WorkspaceApp) ExitAllUsersInRoom(ctx context.Context, isMemberRoom bo
 	return nil
 }
 
-func (app *WorkspaceApp) ListLiveChatMessages(ctx context.Context, pageToken string) ([]*youtube.LiveChatMessage, string, int, error) {
-	messages, nextPageToken, pollingIntervalMillis, err := app.LiveChatBot.ListMessages(ctx, pageToken)
+// MessageToLiveChat ライブチャットにメッセージを送信する。ProcessChatMessageで処理している間はメッセージを受信した配信サービスに、
+// それ以外はYouTubeに送る。処理済みのメッセージを受信し直した場合は送らない。
+func (app *WorkspaceApp) MessageToLiveChat(ctx context.Context, message string) {
+	if app.processingMessage != nil && app.processingMessage.duplicate {
+		return
+	}
+	source, err := app.chatSourceFor(app.replyPlatform)
 	if err != nil {
-		return nil, "", 0, fmt.Errorf("list live chat messages: %w", err)
+		app.MessageToOwnerWithError(ctx, "failed to send live chat message \""+message+"\"\n", err)
+		return
 	}
-	return messages, nextPageToken, pollingIntervalMillis, nil
-}
-
-func (app *WorkspaceApp) MessageToLiveChat(ctx context.Context, message string) {
-	if err := app.LiveChatBot.PostMessage(ctx, message); err != nil {
+	if err := source.PostMessage(ctx, message); err != nil {
 		app.MessageToOwnerWithError(ctx, "failed to send live chat message \""+message+"\"\n", err)
 	}
 }
@@ -321,7 +380,8 @@ func (app *WorkspaceApp) CheckLongTimeSitting(ctx context.Context, isMemberRoom
 }
 
 func (app *WorkspaceApp) CheckLiveStreamStatus(ctx context.Context) error {
-	checker := guardians.NewLiveStreamChecker(app.Repository, app.LiveChatBot, app.alertOwnerBot)
+	checker := guardians.NewLiveStreamChecker(app.Repository, app.LiveChatBot, app.alertOwnerBot, app.Configs.YoutubeAPIEndpoint,
+		app.QuotaMeter)
 	if err := checker.Check(ctx); err != nil {
 		return fmt.Errorf("check live stream status: %w", err)
 	}
@@ -344,7 +404,7 @@ func (app *WorkspaceApp) GetUserIDsToProcessRP(ctx context.Context) ([]string, e
 		if err != nil {
 			return []string{}, fmt.Errorf("in iter.Next(): %w", err)
 		}
-		userID := doc.Ref.ID
+		userID := doc.ID
 		userIDs = append(userIDs, userID)
 	}
 	return userIDs, nil
@@ -353,25 +413,25 @@ func (app *WorkspaceApp) GetUserIDsToProcessRP(ctx context.Context) ([]string, e
 func (app *WorkspaceApp) GetAllUsersTotalStudySecList(ctx context.Context) ([]utils.UserIDTotalStudySecSet, error) {
 	var set []utils.UserIDTotalStudySecSet
 
-	userDocRefs, err := app.Repository.GetAllUserDocRefs(ctx)
-	if err != nil {
-		return set, fmt.Errorf("in GetAllUserDocRefs: %w", err)
-	}
-	for _, userDocRef := range userDocRefs {
-		userDoc, err := app.Repository.ReadUser(ctx, nil, userDocRef.ID)
+	iter := repository.NewUserIterator(ctx, app.Repository, "", repository.DefaultUserPageSize)
+	for {
+		user, err := iter.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
 		if err != nil {
-			return set, fmt.Errorf("in ReadUser: %w", err)
+			return set, fmt.Errorf("in iter.Next(): %w", err)
 		}
 		set = append(set, utils.UserIDTotalStudySecSet{
-			UserID:        userDocRef.ID,
-			TotalStudySec: userDoc.TotalStudySec,
+			UserID:        user.UserID,
+			TotalStudySec: user.TotalStudySec,
 		})
 	}
 	return set, nil
 }
 
 // MinAvailableSeatIDForUser 空いている最小の番号の席番号を求める。該当ユーザーの入室上限にかからない範囲に限定。
-func (app *WorkspaceApp) MinAvailableSeatIDForUser(ctx context.Context, tx *firestore.Transaction, userID string, isMemberSeat bool) (int, error) {
+func (app *WorkspaceApp) MinAvailableSeatIDForUser(ctx context.Context, tx repository.Transaction, userID string, isMemberSeat bool) (int, error) {
 	var seats []repository.SeatDoc
 	var err error
 	if isMemberSeat {
@@ -391,11 +451,18 @@ func (app *WorkspaceApp) MinAvailableSeatIDForUser(ctx context.Context, tx *fire
 		return -1, fmt.Errorf("in ReadSystemConstantsConfig(): %w", err)
 	}
 
-	// 使用されている座席番号リストを取得
+	// 使用されている座席番号リストを取得。まもなく他のユーザーの予約時間となる席も使用中とみなす
 	var usedSeatIDs []int
 	for _, seat := range seats {
 		usedSeatIDs = append(usedSeatIDs, seat.SeatID)
 	}
+	reservedSeatIDs, err := app.SeatIDsReservedByOthers(ctx, userID, isMemberSeat, constants.ReservationLeadMin)
+	if err != nil {
+		return -1, fmt.Errorf("in SeatIDsReservedByOthers(): %w", err)
+	}
+	for seatID := range reservedSeatIDs {
+		usedSeatIDs = append(usedSeatIDs, seatID)
+	}
 
 	// 使用されていない最小の席番号を求める。1から順に探索
 	searchingSeatID := 1
@@ -422,24 +489,25 @@ func (app *WorkspaceApp) MinAvailableSeatIDForUser(ctx context.Context, tx *fire
 	return -1, studyspaceerror.ErrNoSeatAvailable
 }
 
-func (app *WorkspaceApp) AddLiveChatHistoryDoc(ctx context.Context, chatMessage *youtube.LiveChatMessage) error {
-	// example of publishedAt: "2021-11-13T07:21:30.486982+00:00"
-	publishedAt, err := time.Parse(time.RFC3339Nano, chatMessage.Snippet.PublishedAt)
-	if err != nil {
-		return fmt.Errorf("failed to Parse publishedAt: %w", err)
+// AddLiveChatHistoryDoc 配信サービスに依存しないメッセージをlive-chat-historyに保存する。
+// 配信サービスから投稿日時を取得できなかった場合は現在時刻を使う。
+func (app *WorkspaceApp) AddLiveChatHistoryDoc(ctx context.Context, message chat.ChatMessage) error {
+	publishedAt := message.PublishedAt
+	if publishedAt.IsZero() {
+		publishedAt = timeutil.JstNow()
 	}
 	publishedAt = publishedAt.In(timeutil.JapanLocation())
 
 	liveChatHistoryDoc := repository.LiveChatHistoryDoc{
-		AuthorChannelID:       chatMessage.AuthorDetails.ChannelId,
-		AuthorDisplayName:     chatMessage.AuthorDetails.DisplayName,
-		AuthorProfileImageURL: chatMessage.AuthorDetails.ProfileImageUrl,
-		AuthorIsChatModerator: chatMessage.AuthorDetails.IsChatModerator,
-		ID:                    chatMessage.Id,
-		LiveChatID:            chatMessage.Snippet.LiveChatId,
-		MessageText:           youtubebot.ExtractTextMessageByAuthor(chatMessage),
+		AuthorChannelID:       message.Author.ID,
+		AuthorDisplayName:     message.Author.DisplayName,
+		AuthorProfileImageURL: message.Author.ProfileImageURL,
+		AuthorIsChatModerator: message.Author.IsModerator,
+		ID:                    message.ID,
+		LiveChatID:            message.ChatID,
+		MessageText:           message.Text,
 		PublishedAt:           publishedAt,
-		Type:                  chatMessage.Snippet.Type,
+		Type:                  message.Type,
 	}
 	if err := app.Repository.CreateLiveChatHistoryDoc(ctx, nil, liveChatHistoryDoc); err != nil {
 		return fmt.Errorf("create live chat history: %w", err)
@@ -494,9 +562,9 @@ func (app *WorkspaceApp) DeleteCollectionHistoryBeforeDate(ctx context.Context,
 }
 
 // DeleteIteratorDocs iterは最大500件とすること。
-func (app *WorkspaceApp) DeleteIteratorDocs(ctx context.Context, iter *firestore.DocumentIterator) (int, error) {
+func (app *WorkspaceApp) DeleteIteratorDocs(ctx context.Context, iter repository.DocumentIterator) (int, error) {
 	count := 0 // iterのアイテムの件数
-	txErr := app.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+	txErr := app.RunTransaction(ctx, func(ctx context.Context, tx repository.Transaction) error {
 		// forで各docをdeleteしていく
 		for {
 			doc, err := iter.Next()
@@ -508,7 +576,7 @@ func (app *WorkspaceApp) DeleteIteratorDocs(ctx context.Context, iter *firestore
 			}
 			count++
 			{
-				if err := app.Repository.DeleteDocRef(ctx, tx, doc.Ref); err != nil {
+				if err := app.Repository.DeleteDocRef(ctx, tx, doc); err != nil {
 					return fmt.Errorf("in DeleteDocRef(): %w", err)
 				}
 			}
@@ -638,13 +706,83 @@ func (app *WorkspaceApp) GetRecentUserSittingTimeForSeat(ctx context.Context, us
 	return totalEntryDuration, nil
 }
 
-func (app *WorkspaceApp) BanUser(ctx context.Context, userID string) error {
-	if err := app.LiveChatBot.BanUser(ctx, userID); err != nil {
+// BanUser action.TargetUserIDのユーザーをブロックし、ブロックのIDとともにmoderation-actionsに記録する。
+// action.TimeoutDurationSecが設定されていればその時間だけのタイムアウトにする。
+func (app *WorkspaceApp) BanUser(ctx context.Context, tx repository.Transaction, action repository.ModerationActionDoc) error {
+	platform, platformUserID := chat.SplitID(action.TargetUserID)
+	moderator, err := app.chatSourceFor(platform)
+	if err != nil {
+		return fmt.Errorf("in chatSourceFor(): %w", err)
+	}
+	var banID string
+	if action.IsTimeout() {
+		banID, err = moderator.TimeOut(ctx, platformUserID, time.Duration(action.TimeoutDurationSec)*time.Second)
+	} else {
+		banID, err = moderator.BanUser(ctx, platformUserID)
+	}
+	if err != nil {
 		return fmt.Errorf("in BanUser: %w", err)
 	}
+	action.BanID = banID
+	if err := app.Repository.CreateModerationActionDoc(ctx, tx, action); err != nil {
+		return fmt.Errorf("in CreateModerationActionDoc: %w", err)
+	}
 	return nil
 }
 
+// UnbanUser userIDのユーザーに対する、まだ解除されていない最新のブロック（期限切れのタイムアウトを除く）を解除し、
+// moderation-actionsに記録する。解除したブロックの記録を返す。
+func (app *WorkspaceApp) UnbanUser(ctx context.Context, userID string) (repository.ModerationActionDoc, error) {
+	actions, err := app.Repository.ReadModerationActionsByTargetUserID(ctx, userID)
+	if err != nil {
+		return repository.ModerationActionDoc{}, fmt.Errorf("in ReadModerationActionsByTargetUserID: %w", err)
+	}
+	ban, found := activeBan(actions, app.currentTime())
+	if !found {
+		return repository.ModerationActionDoc{}, fmt.Errorf("no active ban for user %s", userID)
+	}
+
+	platform, _ := chat.SplitID(ban.TargetUserID)
+	moderator, err := app.chatSourceFor(platform)
+	if err != nil {
+		return repository.ModerationActionDoc{}, fmt.Errorf("in chatSourceFor(): %w", err)
+	}
+	if err := moderator.UnbanUser(ctx, ban.BanID); err != nil {
+		return repository.ModerationActionDoc{}, fmt.Errorf("in UnbanUser: %w", err)
+	}
+	action := repository.ModerationActionDoc{
+		ActionType:        repository.UnbanModerationAction,
+		TargetUserID:      ban.TargetUserID,
+		TargetDisplayName: ban.TargetDisplayName,
+		BanID:             ban.BanID,
+		TakenAt:           app.currentTime(),
+	}
+	if err := app.Repository.CreateModerationActionDoc(ctx, nil, action); err != nil {
+		return repository.ModerationActionDoc{}, fmt.Errorf("in CreateModerationActionDoc: %w", err)
+	}
+	return ban, nil
+}
+
+// activeBan 古い順に並んだactionsから、解除されておらず期限も切れていない最新のブロックを探す
+func activeBan(actions []repository.ModerationActionDoc, now time.Time) (repository.ModerationActionDoc, bool) {
+	unbanned := make(map[string]bool)
+	for _, action := range actions {
+		if action.ActionType == repository.UnbanModerationAction {
+			unbanned[action.BanID] = true
+		}
+	}
+	for _, action := range slices.Backward(actions) {
+		if action.BanID == "" || action.ActionType == repository.UnbanModerationAction || unbanned[action.BanID] {
+			continue
+		}
+		if action.IsTimeout() && !now.Before(action.TakenAt.Add(time.Duration(action.TimeoutDurationSec)*time.Second)) {
+			continue
+		}
+		return action, true
+	}
+	return repository.ModerationActionDoc{}, false
+}
+
 // GetMenuItemByNumber メニュー番号からメニューアイテムを取得する。
 func (app *WorkspaceApp) GetMenuItemByNumber(number int) (repository.MenuDoc, error) {
 	if number < 1 || len(app.SortedMenuItems) < number {
@@ -663,7 +801,7 @@ func (app *WorkspaceApp) GetMenuNumByCode(code string) (int, error) {
 }
 
 // GetUserRealtimeSeatAppearance リアルタイムの現在のランクを求める
-func (app *WorkspaceApp) GetUserRealtimeSeatAppearance(ctx context.Context, tx *firestore.Transaction, userID string) (repository.SeatAppearance, error) {
+func (app *WorkspaceApp) GetUserRealtimeSeatAppearance(ctx context.Context, tx repository.Transaction, userID string) (repository.SeatAppearance, error) {
 	userDoc, err := app.Repository.ReadUser(ctx, tx, userID)
 	if err != nil {
 		return repository.SeatAppearance{}, fmt.Errorf("in ReadUser(): %w", err)
@@ -676,13 +814,13 @@ func (app *WorkspaceApp) GetUserRealtimeSeatAppearance(ctx context.Context, tx *
 	if err != nil {
 		return repository.SeatAppearance{}, fmt.Errorf("in GetSeatAppearance(): %w", err)
 	}
-	return seatAppearance, nil
+	return utils.ApplySupporterAppearance(seatAppearance, userDoc.SupporterAppearanceUntil, app.currentTime()), nil
 }
 
 // RandomAvailableSeatIDForUser
 // ルームの席が空いているならその中からランダムな席番号（該当ユーザーの入室上限にかからない範囲に限定）を、
 // 空いていないならmax-seatsを増やし、最小の空席番号を返す。
-func (app *WorkspaceApp) RandomAvailableSeatIDForUser(ctx context.Context, tx *firestore.Transaction, userID string, isMemberSeat bool) (int,
+func (app *WorkspaceApp) RandomAvailableSeatIDForUser(ctx context.Context, tx repository.Transaction, userID string, isMemberSeat bool) (int,
 	error,
 ) {
 	var seats []repository.SeatDoc
@@ -710,8 +848,16 @@ func (app *WorkspaceApp) RandomAvailableSeatIDForUser(ctx context.Context, tx *f
 		maxSeats = constants.MaxSeats
 	}
 
+	reservedSeatIDs, err := app.SeatIDsReservedByOthers(ctx, userID, isMemberSeat, constants.ReservationLeadMin)
+	if err != nil {
+		return 0, fmt.Errorf("in SeatIDsReservedByOthers: %w", err)
+	}
+
 	var vacantSeatIDList []int
 	for id := 1; id <= maxSeats; id++ {
+		if reservedSeatIDs[id] { // まもなく他のユーザーの予約時間となる席は除く
+			continue
+		}
 		isUsed := false
 		for _, seatInUse := range seats {
 			if id == seatInUse.SeatID {
@@ -744,7 +890,7 @@ func (app *WorkspaceApp) RandomAvailableSeatIDForUser(ctx context.Context, tx *f
 // enterRoom ユーザーを入室させる。
 func (app *WorkspaceApp) enterRoom(
 	ctx context.Context,
-	tx *firestore.Transaction,
+	tx repository.Transaction,
 	userID string,
 	userDisplayName string,
 	userProfileImageURL string,
@@ -753,6 +899,8 @@ func (app *WorkspaceApp) enterRoom(
 	workName string,
 	breakWorkName string,
 	workMin int,
+	pomodoroWorkMin int, // 0ならポモドーロなし
+	pomodoroBreakMin int,
 	seatAppearance repository.SeatAppearance,
 	menuCode string,
 	state repository.SeatState,
@@ -768,7 +916,7 @@ func (app *WorkspaceApp) enterRoom(
 	switch state {
 	case repository.WorkState:
 		currentStateStartedAt = enterDate
-		currentStateUntil = exitDate
+		currentStateUntil = exitDate // ポモドーロの場合は下で再計算
 	case repository.BreakState:
 		currentStateStartedAt = breakStartedAt
 		currentStateUntil = breakUntil
@@ -793,6 +941,9 @@ func (app *WorkspaceApp) enterRoom(
 		CumulativeWorkSec:       0,
 		DailyCumulativeWorkSec:  0,
 	}
+	if pomodoroWorkMin > 0 {
+		newSeat.SetPomodoro(pomodoroWorkMin, pomodoroBreakMin)
+	}
 	if err := app.Repository.CreateSeat(tx, newSeat, isMemberSeat); err != nil {
 		return 0, fmt.Errorf("in CreateSeat: %w", err)
 	}
@@ -831,12 +982,37 @@ func (app *WorkspaceApp) enterRoom(
 // exitRoom ユーザーを退室させる。
 func (app *WorkspaceApp) exitRoom(
 	ctx context.Context,
-	tx *firestore.Transaction,
+	tx repository.Transaction,
 	isMemberSeat bool,
 	previousSeat repository.SeatDoc,
 	previousUserDoc *repository.UserDoc,
 	previousWorkSegments []repository.WorkSegmentDoc,
 ) (int, int, error) {
+	result, err := app.exitRoomWithResult(ctx, tx, isMemberSeat, previousSeat, previousUserDoc, previousWorkSegments)
+	if err != nil {
+		return 0, 0, err
+	}
+	return result.addedWorkedTimeSec, result.addedRP, nil
+}
+
+// exitResult は退室処理でユーザーに加算した値。
+type exitResult struct {
+	exitedAt                time.Time
+	addedWorkedTimeSec      int
+	addedDailyWorkedTimeSec int
+	addedRP                 int
+	dailyWorkBreakSecs      []utils.DailyWorkBreakSec
+}
+
+// exitRoomWithResult ユーザーを退室させ、加算した値を返す。
+func (app *WorkspaceApp) exitRoomWithResult(
+	ctx context.Context,
+	tx repository.Transaction,
+	isMemberSeat bool,
+	previousSeat repository.SeatDoc,
+	previousUserDoc *repository.UserDoc,
+	previousWorkSegments []repository.WorkSegmentDoc,
+) (exitResult, error) {
 	// 作業時間を計算
 	exitDate := app.currentTime()
 	var addedWorkedTimeSec int
@@ -864,7 +1040,7 @@ func (app *WorkspaceApp) exitRoom(
 
 	// 退室処理
 	if err := app.Repository.DeleteSeat(ctx, tx, previousSeat.SeatID, isMemberSeat); err != nil {
-		return 0, 0, fmt.Errorf("in DeleteSeat: %w", err)
+		return exitResult{}, fmt.Errorf("in DeleteSeat: %w", err)
 	}
 
 	// DEPRECATED: activityログ記録
@@ -876,19 +1052,29 @@ func (app *WorkspaceApp) exitRoom(
 		TakenAt:      exitDate,
 	}
 	if err := app.Repository.CreateUserActivityDoc(ctx, tx, exitActivity); err != nil {
-		return 0, 0, fmt.Errorf("in CreateUserActivityDoc: %w", err)
+		return exitResult{}, fmt.Errorf("in CreateUserActivityDoc: %w", err)
 	}
 	// work segmentログ記録
 	workSegment, err := previousSeat.GenerateWorkSegment(exitDate, isMemberSeat)
 	if err != nil {
-		return 0, 0, fmt.Errorf("in GenerateWorkSegment: %w", err)
+		return exitResult{}, fmt.Errorf("in GenerateWorkSegment: %w", err)
 	}
 	if err := app.Repository.CreateWorkSegmentDoc(ctx, tx, workSegment); err != nil {
-		return 0, 0, fmt.Errorf("in CreateWorkSegmentDoc: %w", err)
+		return exitResult{}, fmt.Errorf("in CreateWorkSegmentDoc: %w", err)
+	}
+	// 日ごとの作業履歴に加算（日付を跨いだセッションは日ごとに振り分ける）
+	sessionSegments := make([]repository.WorkSegmentDoc, 0, len(previousWorkSegments)+1)
+	sessionSegments = append(sessionSegments, previousWorkSegments...)
+	sessionSegments = append(sessionSegments, workSegment)
+	dailyWorkBreakSecs := utils.SessionDailyWorkBreakSecs(previousSeat.EnteredAt, exitDate, sessionSegments)
+	for _, daily := range dailyWorkBreakSecs {
+		if err := app.Repository.AddDailyUserWorkHistory(ctx, tx, previousSeat.UserID, daily.Date, daily.WorkSec, daily.BreakSec); err != nil {
+			return exitResult{}, fmt.Errorf("in AddDailyUserWorkHistory: %w", err)
+		}
 	}
 	// 退室時刻を記録
 	if err := app.Repository.UpdateUserLastExitedDate(tx, previousSeat.UserID, exitDate); err != nil {
-		return 0, 0, fmt.Errorf("in UpdateUserLastExitedDate: %w", err)
+		return exitResult{}, fmt.Errorf("in UpdateUserLastExitedDate: %w", err)
 	}
 
 	// 検算：addedWorkedTimeSec
@@ -961,16 +1147,16 @@ func (app *WorkspaceApp) exitRoom(
 
 	// 累計作業時間を更新
 	if err := app.UpdateTotalWorkTime(tx, previousSeat.UserID, previousUserDoc, addedWorkedTimeSec, addedDailyWorkedTimeSec); err != nil {
-		return 0, 0, fmt.Errorf("in UpdateTotalWorkTime: %w", err)
+		return exitResult{}, fmt.Errorf("in UpdateTotalWorkTime: %w", err)
 	}
 	// RP更新
 	netStudyDuration := time.Duration(addedWorkedTimeSec) * time.Second
 	newRP, err := utils.CalcNewRPExitRoom(netStudyDuration, previousSeat.WorkName != "", previousUserDoc.IsContinuousActive, previousUserDoc.CurrentActivityStateStarted, exitDate, previousUserDoc.RankPoint)
 	if err != nil {
-		return 0, 0, fmt.Errorf("in CalcNewRPExitRoom: %w", err)
+		return exitResult{}, fmt.Errorf("in CalcNewRPExitRoom: %w", err)
 	}
 	if err := app.Repository.UpdateUserRankPoint(tx, previousSeat.UserID, newRP); err != nil {
-		return 0, 0, fmt.Errorf("in UpdateUserRP: %w", err)
+		return exitResult{}, fmt.Errorf("in UpdateUserRP: %w", err)
 	}
 	addedRP := newRP - previousUserDoc.RankPoint
 
@@ -981,12 +1167,18 @@ func (app *WorkspaceApp) exitRoom(
 		"addedRP", addedRP,
 		"newRP", newRP,
 		"previous RP", previousUserDoc.RankPoint)
-	return addedWorkedTimeSec, addedRP, nil
+	return exitResult{
+		exitedAt:                exitDate,
+		addedWorkedTimeSec:      addedWorkedTimeSec,
+		addedDailyWorkedTimeSec: addedDailyWorkedTimeSec,
+		addedRP:                 addedRP,
+		dailyWorkBreakSecs:      dailyWorkBreakSecs,
+	}, nil
 }
 
 func (app *WorkspaceApp) moveSeat(
 	ctx context.Context,
-	tx *firestore.Transaction,
+	tx repository.Transaction,
 	targetSeatID int,
 	latestUserProfileImage string,
 	beforeIsMemberSeat,
@@ -1022,12 +1214,17 @@ func (app *WorkspaceApp) moveSeat(
 	} else {
 		workMin = previousSeat.RemainingWorkMin(jstNow)
 	}
+	pomodoroWorkMin, pomodoroBreakMin := previousSeat.PomodoroWorkMin, previousSeat.PomodoroBreakMin
+	if option.IsPomodoroSet {
+		pomodoroWorkMin, pomodoroBreakMin = option.PomodoroWorkMin, option.PomodoroBreakMin
+	}
 	newTotalStudyDuration := time.Duration(previousUserDoc.TotalStudySec+workedTimeSec) * time.Second
 	newRP := previousUserDoc.RankPoint + addedRP
 	newSeatAppearance, err := utils.GetSeatAppearance(int(newTotalStudyDuration.Seconds()), previousUserDoc.RankVisible, newRP, previousUserDoc.FavoriteColor)
 	if err != nil {
 		return 0, 0, 0, fmt.Errorf("in GetSeatAppearance: %w", err)
 	}
+	newSeatAppearance = utils.ApplySupporterAppearance(newSeatAppearance, previousUserDoc.SupporterAppearanceUntil, jstNow)
 
 	// 入室
 	untilExitMin, err := app.enterRoom(
@@ -1041,6 +1238,8 @@ func (app *WorkspaceApp) moveSeat(
 		workName,
 		previousSeat.BreakWorkName,
 		workMin,
+		pomodoroWorkMin,
+		pomodoroBreakMin,
 		newSeatAppearance,
 		previousSeat.MenuCode,
 		previousSeat.State,
@@ -9,43 +9,16 @@ import (
 )
 
 func (app *WorkspaceApp) ValidateCommand(command utils.CommandDetails) string {
-	switch command.CommandType {
-	case utils.In:
-		return app.ValidateIn(command)
-	case utils.Out:
-		return ""
-	case utils.Info:
-		return app.ValidateInfo(command)
-	case utils.My:
-		return app.ValidateMy(command)
-	case utils.Change:
-		// seatStateに依存するためChange()の中で行う。
-		return ""
-	case utils.Seat:
-		return app.ValidateSeat(command)
-	case utils.Report:
-		return app.ValidateReport(command)
-	case utils.Kick:
-		return app.ValidateKick(command)
-	case utils.Check:
-		return app.ValidateCheck(command)
-	case utils.Block:
-		return app.ValidateBlock(command)
-	case utils.More:
-		return app.ValidateMore(command)
-	case utils.Break:
-		return app.ValidateBreak(command)
-	case utils.Resume:
-		return app.ValidateResume(command)
-	case utils.Rank:
-		return ""
-	case utils.Order:
-		return app.ValidateOrder(command)
-	case utils.Clear:
-		return ""
-	default:
+	// NOTE: ParseCommandでも確認しているが、CommandDetailsを直接組み立てて実行する場合に備えてここでも確認する。
+	if spec, ok := utils.LookupCommandSpec(command.CommandType); ok && spec.MemberOnly && !app.ProcessedUserIsMember {
+		return i18nmsg.ParseMemberOnlyCommand(spec.Names[0])
+	}
+
+	handler, ok := commandHandlers[command.CommandType]
+	if !ok || handler.validate == nil {
 		return ""
 	}
+	return handler.validate(app, command)
 }
 
 func (app *WorkspaceApp) ValidateIn(command utils.CommandDetails) string {
@@ -57,6 +30,10 @@ func (app *WorkspaceApp) ValidateIn(command utils.CommandDetails) string {
 			return i18nmsg.ValidateInvalidWorkTimeRange(app.Configs.Constants.MinWorkTimeMin, app.Configs.Constants.MaxWorkTimeMin)
 		}
 	}
+	// ポモドーロの作業時間・休憩時間の値
+	if message := app.validatePomodoroOption(*command.InOption.MinWorkOrderOption); message != "" {
+		return message
+	}
 	// 席番号
 	if command.InOption.IsSeatIDSet {
 		if command.InOption.SeatID < 0 {
@@ -79,6 +56,20 @@ func (app *WorkspaceApp) ValidateIn(command utils.CommandDetails) string {
 	return ""
 }
 
+// validatePomodoroOption ポモドーロの作業時間・休憩時間が範囲内か確認する。解除の指定は確認しない
+func (app *WorkspaceApp) validatePomodoroOption(option utils.MinWorkOrderOption) string {
+	if !option.IsPomodoroSet || option.IsPomodoroOff() {
+		return ""
+	}
+	if option.PomodoroWorkMin < app.Configs.Constants.MinWorkTimeMin || app.Configs.Constants.MaxWorkTimeMin < option.PomodoroWorkMin {
+		return i18nmsg.ValidateInvalidWorkTimeRange(app.Configs.Constants.MinWorkTimeMin, app.Configs.Constants.MaxWorkTimeMin)
+	}
+	if option.PomodoroBreakMin < app.Configs.Constants.MinBreakDurationMin || app.Configs.Constants.MaxBreakDurationMin < option.PomodoroBreakMin {
+		return i18nmsg.ValidateInvalidBreakTimeRange(app.Configs.Constants.MinBreakDurationMin, app.Configs.Constants.MaxBreakDurationMin)
+	}
+	return ""
+}
+
 func (app *WorkspaceApp) ValidateInfo(_ utils.CommandDetails) string {
 	// pass
 
@@ -86,7 +77,7 @@ func (app *WorkspaceApp) ValidateInfo(_ utils.CommandDetails) string {
 }
 
 func (app *WorkspaceApp) ValidateMy(command utils.CommandDetails) string {
-	var isRankVisibleSet, isDefaultStudyMinSet, isFavoriteColorSet bool
+	var isRankVisibleSet, isDefaultStudyMinSet, isFavoriteColorSet, isDailyGoalMinSet bool
 
 	for _, option := range command.MyOptions {
 		switch option.Type {
@@ -116,6 +107,15 @@ func (app *WorkspaceApp) ValidateMy(command utils.CommandDetails) string {
 				return i18nmsg.ValidateInvalidFavoriteColorOption(utils.FavoriteColorMyOptionPrefix)
 			}
 			isFavoriteColorSet = true
+		case utils.DailyGoalMin:
+			if isDailyGoalMinSet {
+				return "more than 2 DailyGoalMin options."
+			}
+			inputDailyGoalMin := option.IntValue
+			if inputDailyGoalMin < 0 || utils.MaxDailyGoalMin < inputDailyGoalMin {
+				return i18nmsg.ValidateInvalidDailyGoalRange(utils.MaxDailyGoalMin)
+			}
+			isDailyGoalMinSet = true
 		default:
 			return "there is an unknown option in command.MyOptions"
 		}
@@ -156,6 +156,18 @@ func (app *WorkspaceApp) ValidateBlock(command utils.CommandDetails) string {
 	return ""
 }
 
+func (app *WorkspaceApp) ValidateTimeout(command utils.CommandDetails) string {
+	// 指定座席番号
+	if command.TimeoutOption.SeatID <= 0 {
+		return i18nmsg.ValidateNonOneOrMoreSeatId()
+	}
+	if command.TimeoutOption.DurationMin < 1 || utils.MaxTimeoutMin < command.TimeoutOption.DurationMin {
+		return i18nmsg.ValidateInvalidTimeoutRange(utils.MaxTimeoutMin)
+	}
+
+	return ""
+}
+
 func (app *WorkspaceApp) ValidateReport(command utils.CommandDetails) string {
 	// 空欄でないか
 	if command.ReportOption.Message == "" {
@@ -198,6 +210,11 @@ func (app *WorkspaceApp) ValidateChange(changeOption utils.MinWorkOrderOption, s
 		}
 	}
 
+	// ポモドーロ
+	if message := app.validatePomodoroOption(changeOption); message != "" {
+		return errors.New(message)
+	}
+
 	return nil
 }
 
@@ -244,3 +261,33 @@ func (app *WorkspaceApp) ValidateOrder(command utils.CommandDetails) string {
 
 	return ""
 }
+
+func (app *WorkspaceApp) ValidateHistory(command utils.CommandDetails) string {
+	sessionCount := command.HistoryOption.SessionCount
+	if sessionCount < 1 || utils.MaxHistorySessionCount < sessionCount {
+		return i18nmsg.ValidateInvalidHistoryCount(utils.MaxHistorySessionCount)
+	}
+
+	return ""
+}
+
+func (app *WorkspaceApp) ValidateReserve(command utils.CommandDetails) string {
+	if command.ReserveOption.IsCancel {
+		// 席番号の指定なし（0）はすべての予約の取り消し
+		if command.ReserveOption.SeatID < 0 {
+			return i18nmsg.ValidateNonOneOrMoreSeatId()
+		}
+		return ""
+	}
+	if command.ReserveOption.SeatID < 1 {
+		return i18nmsg.ValidateNonOneOrMoreSeatId()
+	}
+	if command.ReserveOption.IsDurationMinSet {
+		durationMin := command.ReserveOption.DurationMin
+		if durationMin < app.Configs.Constants.MinWorkTimeMin || app.Configs.Constants.MaxWorkTimeMin < durationMin {
+			return i18nmsg.ValidateInvalidWorkTimeRange(app.Configs.Constants.MinWorkTimeMin, app.Configs.Constants.MaxWorkTimeMin)
+		}
+	}
+
+	return ""
+}
@@ -7,7 +7,6 @@ import (
 	"log/slog"
 	"strings"
 
-	"cloud.google.com/go/firestore"
 	"github.com/openai/openai-go/v2" // imported as openai
 	"github.com/openai/openai-go/v2/option"
 	"github.com/openai/openai-go/v2/responses"
@@ -177,7 +176,7 @@ func (app *WorkspaceApp) saveWorkNameTrend(ctx context.Context, rankings []repos
 		Ranking:  normalizeWorkNameTrendRankings(rankings),
 		RankedAt: app.currentTime(),
 	}
-	txErr := app.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+	txErr := app.RunTransaction(ctx, func(ctx context.Context, tx repository.Transaction) error {
 		return app.Repository.UpdateWorkNameTrend(ctx, tx, workNameTrend)
 	})
 	if txErr != nil {
@@ -5,7 +5,6 @@ import (
 	"testing"
 	"time"
 
-	"cloud.google.com/go/firestore"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
@@ -21,23 +20,20 @@ func TestUpdateWorkNameTrend_EmptyWorkNames(t *testing.T) {
 	ctx := context.Background()
 	fixedNow := time.Date(2026, time.January, 1, 10, 0, 0, 0, time.UTC)
 	mockDB := mock_myfirestore.NewMockRepository(ctrl)
-	mockFirestoreClient := mock_myfirestore.NewMockDBClient(ctrl)
 
 	mockDB.EXPECT().ReadActiveWorkNameSeats(gomock.Any(), true).Return([]repository.SeatDoc{}, nil).Times(1)
 	mockDB.EXPECT().ReadActiveWorkNameSeats(gomock.Any(), false).Return([]repository.SeatDoc{}, nil).Times(1)
-	mockDB.EXPECT().FirestoreClient().Return(mockFirestoreClient).Times(1)
 
 	var savedWorkNameTrend *repository.WorkNameTrendDoc
 	mockDB.EXPECT().UpdateWorkNameTrend(gomock.Any(), gomock.Any(), gomock.Any()).
-		DoAndReturn(func(ctx context.Context, tx *firestore.Transaction, workNameTrend repository.WorkNameTrendDoc) error {
+		DoAndReturn(func(ctx context.Context, tx repository.Transaction, workNameTrend repository.WorkNameTrendDoc) error {
 			savedWorkNameTrend = &workNameTrend
 			return nil
 		}).
 		Times(1)
-	mockFirestoreClient.EXPECT().RunTransaction(gomock.Any(), gomock.Any()).
-		DoAndReturn(func(ctx context.Context, f func(context.Context, *firestore.Transaction) error, opts ...firestore.TransactionOption) error {
-			tx := &firestore.Transaction{}
-			return f(ctx, tx)
+	mockDB.EXPECT().RunTransaction(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, f func(context.Context, repository.Transaction) error) error {
+			return f(ctx, nil)
 		}).
 		Times(1)
 
@@ -5,12 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"os"
 	"reflect"
+	"strconv"
 	"time"
 
-	"cloud.google.com/go/firestore"
 	"google.golang.org/api/option"
 
+	"app.modules/core/chat"
 	"app.modules/core/i18n"
 	i18nmsg "app.modules/core/i18n/typed"
 	"app.modules/core/moderatorbot"
@@ -23,7 +25,9 @@ import (
 type WorkspaceApp struct {
 	Configs            *Configs
 	Repository         repository.Repository
-	LiveChatBot        youtubebot.LiveChatBot
+	LiveChatBot        youtubebot.LiveChatBot            // YouTubeのライブチャットの受信に使う。投稿とモデレーションはChatSourcesを通す
+	ChatSources        map[chat.Platform]chat.ChatSource // 配信サービスごとのチャット。AddChatSourceで追加する
+	QuotaMeter         *youtubebot.QuotaMeter            // YouTube Data APIの使用量。CloseFirestoreClientで保存する
 	alertOwnerBot      moderatorbot.MessageBot
 	alertModeratorsBot moderatorbot.MessageBot
 	logModeratorsBot   moderatorbot.MessageBot
@@ -34,8 +38,17 @@ type WorkspaceApp struct {
 	ProcessedUserIsModeratorOrOwner bool
 	ProcessedUserIsMember           bool
 
+	processingMessage *processingMessage // ProcessMessageで処理している間のみnil以外
+	replyPlatform     chat.Platform      // ProcessChatMessageで処理している間のみ空以外。ライブチャットへの返信先。空ならYouTube
+
 	SortedMenuItems []repository.MenuDoc // メニューコードで昇順ソートして格納
 
+	seatCache *repository.SeatCache // StartSeatCacheを呼ぶまではnil
+
+	constantsUpdates chan repository.ConstantsConfigDoc // StartConstantsWatcherを呼ぶまではnil
+
+	quotaAlertedDate string // YouTube Data APIの予算超過をownerに知らせた日付（太平洋時間）
+
 	nowFunc func() time.Time // テストの時刻注入用
 }
 
@@ -44,29 +57,40 @@ type Configs struct {
 	Constants repository.ConstantsConfigDoc
 
 	LiveChatBotChannelID string
+
+	YoutubeAPIEndpoint string // 空なら本物のYouTube Data API。youtubebot.APIEndpointEnvで指定する
 }
 
 func NewWorkspaceApp(ctx context.Context, interactive bool, clientOption option.ClientOption) (*WorkspaceApp, error) {
-	if err := i18n.LoadLocaleFolderFS(); err != nil {
-		return nil, fmt.Errorf("in LoadLocaleFolderFS(): %w", err)
-	}
-
 	slog.InfoContext(ctx, "initializing firestore client...")
 	firestoreController, err := repository.NewFirestoreController(ctx, clientOption)
 	if err != nil {
 		return nil, fmt.Errorf("in NewFirestoreController(): %w", err)
 	}
+	return NewWorkspaceAppWithRepository(ctx, interactive, firestoreController)
+}
+
+// NewWorkspaceAppWithRepository Firestore以外のRepository（ステージング用のSQLデータベースなど）でWorkspaceAppを生成する。
+func NewWorkspaceAppWithRepository(ctx context.Context, interactive bool, repo repository.Repository) (*WorkspaceApp, error) {
+	if err := i18n.LoadLocaleFolderFS(); err != nil {
+		return nil, fmt.Errorf("in LoadLocaleFolderFS(): %w", err)
+	}
 
 	// credentials
 	slog.InfoContext(ctx, "reading credentials config...")
-	credentialsDoc, err := firestoreController.ReadCredentialsConfig(ctx, nil)
+	credentialsDoc, err := repo.ReadCredentialsConfig(ctx, nil)
 	if err != nil {
 		return nil, fmt.Errorf("in ReadCredentialsConfig(): %w", err)
 	}
 
 	// YouTube live chatbot
 	slog.InfoContext(ctx, "initializing youtube live chat bot...")
-	liveChatBot, err := youtubebot.NewYoutubeLiveChatBot(credentialsDoc.YoutubeLiveChatID, firestoreController, ctx)
+	youtubeAPIEndpoint := os.Getenv(youtubebot.APIEndpointEnv)
+	if youtubeAPIEndpoint != "" {
+		slog.WarnContext(ctx, "using custom YouTube Data API endpoint", "endpoint", youtubeAPIEndpoint)
+	}
+	quotaMeter := youtubebot.NewQuotaMeter(repo)
+	liveChatBot, err := youtubebot.NewYoutubeLiveChatBot(credentialsDoc.YoutubeLiveChatID, repo, youtubeAPIEndpoint, quotaMeter, ctx)
 	if err != nil {
 		return nil, fmt.Errorf("in NewYoutubeLiveChatBot(): %w", err)
 	}
@@ -89,7 +113,7 @@ func NewWorkspaceApp(ctx context.Context, interactive bool, clientOption option.
 
 	// core constant values
 	slog.InfoContext(ctx, "reading system constants config...")
-	constantsConfig, err := firestoreController.ReadSystemConstantsConfig(ctx, nil)
+	constantsConfig, err := repo.ReadSystemConstantsConfig(ctx, nil)
 	if err != nil {
 		return nil, fmt.Errorf("in ReadSystemConstantsConfig(): %w", err)
 	}
@@ -97,6 +121,7 @@ func NewWorkspaceApp(ctx context.Context, interactive bool, clientOption option.
 	configs := Configs{
 		Constants:            constantsConfig,
 		LiveChatBotChannelID: credentialsDoc.YoutubeBotChannelID,
+		YoutubeAPIEndpoint:   youtubeAPIEndpoint,
 	}
 
 	// 全ての項目が初期化できているか確認
@@ -127,15 +152,17 @@ func NewWorkspaceApp(ctx context.Context, interactive bool, clientOption option.
 	}
 
 	slog.InfoContext(ctx, "reading menu docs...")
-	sortedMenuItems, err := firestoreController.ReadAllMenuDocsOrderByCode(ctx)
+	sortedMenuItems, err := repo.ReadAllMenuDocsOrderByCode(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("in ReadAllMenuDocsOrderByCode(): %w", err)
 	}
 
 	return &WorkspaceApp{
 		Configs:            &configs,
-		Repository:         firestoreController,
+		Repository:         repo,
 		LiveChatBot:        liveChatBot,
+		ChatSources:        map[chat.Platform]chat.ChatSource{chat.YouTube: youtubebot.NewChatSource(liveChatBot)},
+		QuotaMeter:         quotaMeter,
 		alertOwnerBot:      discordOwnerBot,
 		alertModeratorsBot: discordSharedBot,
 		logModeratorsBot:   discordSharedLogBot,
@@ -144,6 +171,31 @@ func NewWorkspaceApp(ctx context.Context, interactive bool, clientOption option.
 	}, nil
 }
 
+// StartSeatCache 座席とconstantsのスナップショットの購読を開始し、以後トランザクション外の読み取りにキャッシュを使う。
+// 常駐するbotで使う。Repositoryが購読に対応していなければ何もしない。
+func (app *WorkspaceApp) StartSeatCache(ctx context.Context) error {
+	listener, ok := app.Repository.(repository.SeatSnapshotListener)
+	if !ok {
+		slog.InfoContext(ctx, "repository does not support seat snapshots, seat cache disabled")
+		return nil
+	}
+	cache := repository.NewSeatCache()
+	if err := listener.ListenSeatSnapshots(ctx, cache); err != nil {
+		return fmt.Errorf("in ListenSeatSnapshots(): %w", err)
+	}
+	app.Repository = repository.NewCachedRepository(app.Repository, cache)
+	app.seatCache = cache
+	return nil
+}
+
+// SeatCacheStats 座席キャッシュのヒット・ミス回数。キャッシュを使っていなければokはfalse。
+func (app *WorkspaceApp) SeatCacheStats() (stats repository.SeatCacheStats, ok bool) {
+	if app.seatCache == nil {
+		return repository.SeatCacheStats{}, false
+	}
+	return app.seatCache.Stats(), true
+}
+
 func (app *WorkspaceApp) currentTime() time.Time {
 	if app.nowFunc != nil {
 		return app.nowFunc()
@@ -151,9 +203,30 @@ func (app *WorkspaceApp) currentTime() time.Time {
 	return timeutil.JstNow()
 }
 
-func (app *WorkspaceApp) RunTransaction(ctx context.Context, f func(ctx context.Context, tx *firestore.Transaction) error) error {
-	if err := app.Repository.FirestoreClient().RunTransaction(ctx, f); err != nil {
-		return fmt.Errorf("run Firestore transaction: %w", err)
+func (app *WorkspaceApp) RunTransaction(ctx context.Context, f func(ctx context.Context, tx repository.Transaction) error) error {
+	message := app.processingMessage
+	return app.runTransaction(ctx, message != nil && message.inCommand, f)
+}
+
+// runTransaction recordsMessageがtrueなら、処理中のメッセージをfの書き込みとともに処理済みとして記録する。
+func (app *WorkspaceApp) runTransaction(ctx context.Context, recordsMessage bool, f func(ctx context.Context, tx repository.Transaction) error) error {
+	message := app.processingMessage
+	recordsMessage = recordsMessage && message != nil && !message.recorded
+	if recordsMessage {
+		// コマンドによる状態の変更と、メッセージを処理済みとする記録を同時に反映する
+		execute := f
+		f = func(ctx context.Context, tx repository.Transaction) error {
+			return app.checkAndRecordProcessingMessage(ctx, tx, execute)
+		}
+	}
+	if err := app.Repository.RunTransaction(ctx, f); err != nil {
+		if recordsMessage && errors.Is(err, errLiveChatMessageAlreadyProcessed) {
+			message.duplicate = true
+		}
+		return fmt.Errorf("run transaction: %w", err)
+	}
+	if recordsMessage {
+		message.recorded = true
 	}
 	return nil
 }
@@ -167,7 +240,14 @@ func (app *WorkspaceApp) SetProcessedUser(userID string, userDisplayName string,
 }
 
 func (app *WorkspaceApp) CloseFirestoreClient() {
-	if err := app.Repository.FirestoreClient().Close(); err != nil {
+	// 閉じる前に、まだ保存していないYouTube Data APIの使用量を保存する
+	ctx, cancel := context.WithTimeout(context.Background(), youtubeAPIQuotaFlushTimeout)
+	defer cancel()
+	if err := app.QuotaMeter.Flush(ctx); err != nil {
+		slog.Error("failed to flush YouTube API quota usage.", "err", err)
+	}
+
+	if err := app.Repository.Close(); err != nil {
 		slog.Error("failed close firestore client.")
 	} else {
 		slog.Info("successfully closed firestore client.")
@@ -209,13 +289,18 @@ func (app *WorkspaceApp) CheckIfUnwantedWordIncluded(ctx context.Context, ngWord
 		return false, fmt.Errorf("check chat message against block regexes: %w", err)
 	}
 	if found {
-		if err := app.BanUser(ctx, userID); err != nil {
-			return false, fmt.Errorf("in BanUser(): %w", err)
+		regex := ngWordConfig.blockRegexesForChatMessage[index]
+		timeout := ngWordConfig.blockTimeout(regex)
+		if err := app.banUserForNGWord(ctx, newNGWordBanAction(userID, channelName, regex, message, timeout, app.currentTime())); err != nil {
+			if errors.Is(err, errLiveChatMessageAlreadyProcessed) {
+				return true, nil
+			}
+			return false, fmt.Errorf("in banUserForNGWord(): %w", err)
 		}
-		return true, app.LogToModerators(ctx, "発言から禁止ワードを検出、ユーザーをブロックしました。"+
-			"\n禁止ワード: `"+ngWordConfig.blockRegexesForChatMessage[index]+"`"+
+		return true, app.LogToModerators(ctx, "発言から禁止ワードを検出、ユーザーを"+ngWordBanDescription(timeout)+"しました。"+
+			"\n禁止ワード: `"+regex+"`"+
 			"\nチャンネル名: `"+channelName+"`"+
-			"\nチャンネルURL: https://youtube.com/channel/"+userID+
+			"\nチャンネルURL: "+chat.ChannelURL(userID)+
 			"\nチャット内容: `"+message+"`"+
 			"\n日時: "+app.currentTime().String())
 	}
@@ -224,13 +309,18 @@ func (app *WorkspaceApp) CheckIfUnwantedWordIncluded(ctx context.Context, ngWord
 		return false, fmt.Errorf("in ContainsRegexWithIndex(): %w", err)
 	}
 	if found {
-		if err := app.BanUser(ctx, userID); err != nil {
-			return false, fmt.Errorf("in BanUser(): %w", err)
+		regex := ngWordConfig.blockRegexesForChannelName[index]
+		timeout := ngWordConfig.blockTimeout(regex)
+		if err := app.banUserForNGWord(ctx, newNGWordBanAction(userID, channelName, regex, channelName, timeout, app.currentTime())); err != nil {
+			if errors.Is(err, errLiveChatMessageAlreadyProcessed) {
+				return true, nil
+			}
+			return false, fmt.Errorf("in banUserForNGWord(): %w", err)
 		}
-		return true, app.LogToModerators(ctx, "チャンネル名から禁止ワードを検出、ユーザーをブロックしました。"+
-			"\n禁止ワード: `"+ngWordConfig.blockRegexesForChannelName[index]+"`"+
+		return true, app.LogToModerators(ctx, "チャンネル名から禁止ワードを検出、ユーザーを"+ngWordBanDescription(timeout)+"しました。"+
+			"\n禁止ワード: `"+regex+"`"+
 			"\nチャンネル名: `"+channelName+"`"+
-			"\nチャンネルURL: https://youtube.com/channel/"+userID+
+			"\nチャンネルURL: "+chat.ChannelURL(userID)+
 			"\nチャット内容: `"+message+"`"+
 			"\n日時: "+app.currentTime().String())
 	}
@@ -244,7 +334,7 @@ func (app *WorkspaceApp) CheckIfUnwantedWordIncluded(ctx context.Context, ngWord
 		return false, app.MessageToModerators(ctx, "発言から禁止ワードを検出しました。（通知のみ）"+
 			"\n禁止ワード: `"+ngWordConfig.notificationRegexesForChatMessage[index]+"`"+
 			"\nチャンネル名: `"+channelName+"`"+
-			"\nチャンネルURL: https://youtube.com/channel/"+userID+
+			"\nチャンネルURL: "+chat.ChannelURL(userID)+
 			"\nチャット内容: `"+message+"`"+
 			"\n日時: "+app.currentTime().String())
 	}
@@ -256,17 +346,52 @@ func (app *WorkspaceApp) CheckIfUnwantedWordIncluded(ctx context.Context, ngWord
 		return false, app.MessageToModerators(ctx, "チャンネルから禁止ワードを検出しました。（通知のみ）"+
 			"\n禁止ワード: `"+ngWordConfig.notificationRegexesForChannelName[index]+"`"+
 			"\nチャンネル名: `"+channelName+"`"+
-			"\nチャンネルURL: https://youtube.com/channel/"+userID+
+			"\nチャンネルURL: "+chat.ChannelURL(userID)+
 			"\nチャット内容: `"+message+"`"+
 			"\n日時: "+app.currentTime().String())
 	}
 	return false, nil
 }
 
+// banUserForNGWord NGワードによる自動ブロックを、処理中のメッセージを処理済みとする記録と同じトランザクションで記録する。
+// メッセージが処理済みなら（再起動で同じページを受信し直した場合など）ブロックせずにerrLiveChatMessageAlreadyProcessedを返す。
+func (app *WorkspaceApp) banUserForNGWord(ctx context.Context, action repository.ModerationActionDoc) error {
+	return app.runTransaction(ctx, true, func(ctx context.Context, tx repository.Transaction) error {
+		return app.BanUser(ctx, tx, action)
+	})
+}
+
+// newNGWordBanAction NGワードによる自動ブロックの記録。matchedTextは正規表現に一致したチャットメッセージまたはチャンネル名。
+// timeoutが0なら無期限のブロック
+func newNGWordBanAction(userID, channelName, matchedRegex, matchedText string, timeout time.Duration, takenAt time.Time) repository.ModerationActionDoc {
+	action := repository.ModerationActionDoc{
+		ActionType:        repository.NGWordBanModerationAction,
+		TargetUserID:      userID,
+		TargetDisplayName: channelName,
+		MatchedRegex:      matchedRegex,
+		MessageText:       matchedText,
+		TakenAt:           takenAt,
+	}
+	if timeout > 0 {
+		action.ActionType = repository.NGWordTimeoutModerationAction
+		action.TimeoutDurationSec = int(timeout.Seconds())
+	}
+	return action
+}
+
+func ngWordBanDescription(timeout time.Duration) string {
+	if timeout > 0 {
+		return strconv.Itoa(int(timeout.Minutes())) + "分間タイムアウト"
+	}
+	return "ブロック"
+}
+
 // ProcessMessage 入力コマンドを解析して実行
+// messageIDが空でなければ、状態を変更するコマンドやNGワードによるブロックはそのトランザクション内で処理済みかを確かめて記録し、処理済みなら何もしない。
 func (app *WorkspaceApp) ProcessMessage(
 	ctx context.Context,
 	ngWordConfig NGWordConfig,
+	messageID string,
 	commandString string,
 	userID string,
 	userDisplayName string,
@@ -278,11 +403,49 @@ func (app *WorkspaceApp) ProcessMessage(
 	if userID == app.Configs.LiveChatBotChannelID {
 		return nil
 	}
+	if messageID == "" {
+		return app.processMessage(ctx, ngWordConfig, commandString, userID, userDisplayName, userProfileImageURL, isChatModerator, isChatOwner, isChatMember)
+	}
+
+	app.processingMessage = &processingMessage{id: messageID, userID: userID}
+	defer func() { app.processingMessage = nil }()
+	err := app.processMessage(ctx, ngWordConfig, commandString, userID, userDisplayName, userProfileImageURL, isChatModerator, isChatOwner, isChatMember)
+	// 再起動で同じページを受信し直した場合など
+	if app.processingMessage.duplicate {
+		slog.InfoContext(ctx, "skipping already processed live chat message", "messageID", messageID)
+		return nil
+	}
+	return err
+}
+
+func (app *WorkspaceApp) processMessage(
+	ctx context.Context,
+	ngWordConfig NGWordConfig,
+	commandString string,
+	userID string,
+	userDisplayName string,
+	userProfileImageURL string,
+	isChatModerator bool,
+	isChatOwner bool,
+	isChatMember bool,
+) error {
 	if !app.Configs.Constants.YoutubeMembershipEnabled {
 		isChatMember = false
 	}
 	app.SetProcessedUser(userID, userDisplayName, userProfileImageURL, isChatModerator, isChatOwner, isChatMember)
 
+	// NGワードによるブロックやモデレーターへの通知を繰り返さないように、先に処理済みかを確かめる
+	if app.processingMessage != nil {
+		processed, err := app.isProcessingMessageProcessed(ctx)
+		if err != nil {
+			return fmt.Errorf("in isProcessingMessageProcessed(): %w", err)
+		}
+		if processed {
+			app.processingMessage.duplicate = true
+			return nil
+		}
+	}
+
 	// check if an unwanted word included
 	if !isChatModerator && !isChatOwner {
 		blocked, err := app.CheckIfUnwantedWordIncluded(ctx, ngWordConfig, userID, commandString, userDisplayName)
@@ -296,7 +459,7 @@ func (app *WorkspaceApp) ProcessMessage(
 	}
 
 	// 初回の利用の場合はユーザーデータを初期化
-	txErr := app.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+	txErr := app.RunTransaction(ctx, func(ctx context.Context, tx repository.Transaction) error {
 		isRegistered, err := app.IfUserRegistered(ctx, tx)
 		if err != nil {
 			return fmt.Errorf("in IfUserRegistered(): %w", err)
@@ -331,45 +494,18 @@ func (app *WorkspaceApp) ProcessMessage(
 
 // executeCommand 解析済みのコマンドを実行する
 func (app *WorkspaceApp) executeCommand(ctx context.Context, commandDetails *utils.CommandDetails, commandString string) error {
-	// commandDetailsに基づいて命令処理
 	switch commandDetails.CommandType {
-	case utils.NotCommand:
-		return nil
-	case utils.InvalidCommand:
+	case utils.NotCommand, utils.InvalidCommand:
 		return nil
-	case utils.In:
-		return app.In(ctx, &commandDetails.InOption)
-	case utils.Out:
-		return app.Out(ctx)
-	case utils.Info:
-		return app.ShowUserInfo(ctx, &commandDetails.InfoOption)
-	case utils.My:
-		return app.My(ctx, commandDetails.MyOptions)
-	case utils.Change:
-		return app.Change(ctx, &commandDetails.ChangeOption)
-	case utils.Seat:
-		return app.ShowSeatInfo(ctx, &commandDetails.SeatOption)
-	case utils.Report:
-		return app.Report(ctx, &commandDetails.ReportOption)
-	case utils.Kick:
-		return app.Kick(ctx, &commandDetails.KickOption)
-	case utils.Check:
-		return app.Check(ctx, &commandDetails.CheckOption)
-	case utils.Block:
-		return app.Block(ctx, &commandDetails.BlockOption)
-	case utils.More:
-		return app.More(ctx, &commandDetails.MoreOption)
-	case utils.Break:
-		return app.Break(ctx, &commandDetails.BreakOption)
-	case utils.Resume:
-		return app.Resume(ctx, &commandDetails.ResumeOption)
-	case utils.Rank:
-		return app.Rank(ctx, commandDetails)
-	case utils.Order:
-		return app.Order(ctx, &commandDetails.OrderOption)
-	case utils.Clear:
-		return app.Clear(ctx)
-	default:
+	}
+
+	// commandDetailsに基づいて命令処理
+	handler, ok := commandHandlers[commandDetails.CommandType]
+	if !ok {
 		return errors.New("Unknown command: " + commandString)
 	}
+	if app.processingMessage != nil {
+		app.processingMessage.inCommand = true
+	}
+	return handler.execute(app, ctx, commandDetails)
 }
@@ -9,8 +9,6 @@ import (
 
 	"google.golang.org/api/iterator"
 
-	"cloud.google.com/go/firestore"
-
 	"app.modules/core/moderatorbot"
 	"app.modules/core/repository"
 	"app.modules/core/timeutil"
@@ -63,7 +61,7 @@ func TestEnterRoom(t *testing.T) {
 	}
 
 	var resultUntilExitMin int
-	txErr := app.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+	txErr := app.RunTransaction(ctx, func(ctx context.Context, tx repository.Transaction) error {
 		untilExitMin, err := app.enterRoom(
 			ctx,
 			tx,
@@ -75,6 +73,8 @@ func TestEnterRoom(t *testing.T) {
 			inOption.MinWorkOrderOption.WorkName,
 			"",
 			inOption.MinWorkOrderOption.DurationMin,
+			0,
+			0,
 			seatAppearance,
 			"",
 			repository.WorkState,
@@ -119,7 +119,7 @@ func TestEnterRoom(t *testing.T) {
 	}, seat)
 
 	// 履歴が作成されたことを確認
-	iter := app.Repository.FirestoreClient().Collection(repository.UserActivities).Where(repository.UserIDDocProperty, "==", userID).Documents(ctx)
+	iter := firestoreController.FirestoreClient().Collection(repository.UserActivities).Where(repository.UserIDDocProperty, "==", userID).Documents(ctx)
 	var userActivities []repository.UserActivityDoc
 	for {
 		doc, err := iter.Next()
@@ -0,0 +1,174 @@
+package workspaceapp
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"app.modules/core/i18n"
+	"app.modules/core/moderatorbot"
+	"app.modules/core/repository"
+	"app.modules/core/timeutil"
+	mock_youtubebot "app.modules/core/youtubebot/mocks"
+)
+
+func TestProcessMessage_InMemoryRepository(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	if err := i18n.LoadLocaleFolderFS(); err != nil {
+		panic(fmt.Errorf("in LoadLocaleFolderFS(): %w", err))
+	}
+
+	ctx := context.Background()
+	now := time.Date(2026, time.January, 1, 10, 0, 0, 0, timeutil.JapanLocation())
+	constants := repository.ConstantsConfigDoc{
+		MaxWorkTimeMin:          360,
+		MinWorkTimeMin:          5,
+		DefaultWorkTimeMin:      60,
+		MinBreakDurationMin:     1,
+		MaxBreakDurationMin:     60,
+		DefaultBreakDurationMin: 30,
+		MinBreakIntervalMin:     0,
+		MaxSeats:                10,
+		MemberMaxSeats:          5,
+		UndoExitGraceMin:        5,
+	}
+	repo := repository.NewInMemoryRepository()
+	require.NoError(t, repo.SetSystemConstantsConfig(constants))
+
+	var postedMessages []string
+	mockLiveChatBot := mock_youtubebot.NewMockLiveChatBot(ctrl)
+	mockLiveChatBot.EXPECT().PostMessage(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, message string) error {
+			postedMessages = append(postedMessages, message)
+			return nil
+		},
+	).AnyTimes()
+
+	app := WorkspaceApp{
+		Configs:       &Configs{Constants: constants},
+		Repository:    repo,
+		ChatSources:   youtubeChatSources(mockLiveChatBot),
+		alertOwnerBot: moderatorbot.DummyMessageBot{},
+		nowFunc:       func() time.Time { return now },
+	}
+	// 入室から退室の取り消しまでをコマンドで一通り実行し、リポジトリの状態を確認する
+	processMessage := func(command string) {
+		t.Helper()
+		postedMessages = nil
+		require.NoError(t, app.ProcessMessage(ctx, NGWordConfig{}, "", command, "test_user_id", "テストユーザー", "", false, false, false))
+	}
+
+	processMessage("!3 work=数学 min=30")
+	assert.Equal(t, []string{`@テストユーザー さんが作業を始めました🔥（作業内容："数学"、最大30分、3番席）`}, postedMessages)
+	seat, err := repo.ReadSeat(ctx, nil, 3, false)
+	require.NoError(t, err)
+	assert.Equal(t, "test_user_id", seat.UserID)
+	assert.Equal(t, "数学", seat.WorkName)
+	assert.True(t, now.Add(30*time.Minute).Equal(seat.Until))
+
+	now = now.Add(10 * time.Minute)
+	processMessage("!break min=5")
+	assert.Equal(t, []string{`@テストユーザー さんが休憩します☕（休憩内容：""、最大5分、3番席）`}, postedMessages)
+	seat, err = repo.ReadSeat(ctx, nil, 3, false)
+	require.NoError(t, err)
+	assert.Equal(t, repository.BreakState, seat.State)
+
+	now = now.Add(5 * time.Minute)
+	processMessage("!resume")
+	assert.Equal(t, []string{`@テストユーザー さんが作業を再開します🔥（3番席、自動退室まで15分）`}, postedMessages)
+	seat, err = repo.ReadSeat(ctx, nil, 3, false)
+	require.NoError(t, err)
+	assert.Equal(t, repository.WorkState, seat.State)
+
+	now = now.Add(10 * time.Minute)
+	processMessage("!out")
+	assert.Equal(t, []string{`@テストユーザー さんが退室しました🚪 （+ 20分、3番席）`}, postedMessages)
+	_, err = repo.ReadSeat(ctx, nil, 3, false)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	user, err := repo.ReadUser(ctx, nil, "test_user_id")
+	require.NoError(t, err)
+	assert.Equal(t, 20*60, user.TotalStudySec)
+
+	now = now.Add(time.Minute)
+	processMessage("!undo")
+	assert.Equal(t, []string{`@テストユーザー さん、退室を取り消して3番席に戻りました🔙`}, postedMessages)
+	seat, err = repo.ReadSeat(ctx, nil, 3, false)
+	require.NoError(t, err)
+	assert.Equal(t, "数学", seat.WorkName)
+	user, err = repo.ReadUser(ctx, nil, "test_user_id")
+	require.NoError(t, err)
+	assert.Equal(t, 0, user.TotalStudySec)
+}
+
+func TestProcessMessage_SkipsProcessedMessage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	require.NoError(t, i18n.LoadLocaleFolderFS())
+
+	ctx := context.Background()
+	now := time.Date(2026, time.January, 1, 10, 0, 0, 0, timeutil.JapanLocation())
+	constants := repository.ConstantsConfigDoc{
+		MaxWorkTimeMin:      360,
+		MinWorkTimeMin:      5,
+		DefaultWorkTimeMin:  60,
+		MaxBreakDurationMin: 60,
+		MaxSeats:            10,
+	}
+	repo := repository.NewInMemoryRepository()
+	require.NoError(t, repo.SetSystemConstantsConfig(constants))
+
+	var postedMessages []string
+	mockLiveChatBot := mock_youtubebot.NewMockLiveChatBot(ctrl)
+	mockLiveChatBot.EXPECT().PostMessage(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, message string) error {
+			postedMessages = append(postedMessages, message)
+			return nil
+		},
+	).AnyTimes()
+
+	app := WorkspaceApp{
+		Configs:       &Configs{Constants: constants},
+		Repository:    repo,
+		ChatSources:   youtubeChatSources(mockLiveChatBot),
+		alertOwnerBot: moderatorbot.DummyMessageBot{},
+		nowFunc:       func() time.Time { return now },
+	}
+	processMessage := func(messageID, command string) {
+		t.Helper()
+		require.NoError(t, app.ProcessMessage(ctx, NGWordConfig{}, messageID, command, "test_user_id", "テストユーザー", "", false, false, false))
+	}
+
+	processMessage("message-1", "!3 work=数学 min=30")
+	require.Len(t, postedMessages, 1)
+	processed, err := repo.ReadProcessedLiveChatMessage(ctx, nil, "message-1")
+	require.NoError(t, err)
+	assert.Equal(t, "test_user_id", processed.UserID)
+	assert.True(t, now.Add(processedLiveChatMessageRetention).Equal(processed.ExpireAt))
+
+	// 再起動で同じページを受信し直しても、同じコマンドは実行しない
+	now = now.Add(time.Minute)
+	processMessage("message-1", "!3 work=数学 min=30")
+	assert.Len(t, postedMessages, 1)
+	seat, err := repo.ReadSeat(ctx, nil, 3, false)
+	require.NoError(t, err)
+	assert.True(t, now.Add(-time.Minute).Equal(seat.EnteredAt))
+
+	// コマンドでないチャットは記録しない
+	processMessage("message-2", "こんにちは")
+	_, err = repo.ReadProcessedLiveChatMessage(ctx, nil, "message-2")
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	processMessage("message-3", "!in")
+	processMessage("message-3", "!in")
+	assert.Equal(t, "@テストユーザー さんは3番の席に座っています🪑", postedMessages[len(postedMessages)-1])
+	assert.Len(t, postedMessages, 2)
+	assert.Nil(t, app.processingMessage)
+}
@@ -0,0 +1,61 @@
+package workspaceapp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"app.modules/core/youtubebot"
+)
+
+const (
+	// YoutubeAPIQuotaFlushInterval 常駐するbotがYouTube Data APIの使用量を保存する間隔
+	YoutubeAPIQuotaFlushInterval = time.Minute
+
+	youtubeAPIQuotaFlushTimeout = 10 * time.Second
+)
+
+// FlushYoutubeAPIQuota 計測したYouTube Data APIの使用量を保存する。
+// 見込みの使用量が予算を超える場合は、1日に1回だけownerに知らせる。
+func (app *WorkspaceApp) FlushYoutubeAPIQuota(ctx context.Context) {
+	if err := app.QuotaMeter.Flush(ctx); err != nil {
+		// 保存できなかった分は次のFlushに持ち越される
+		slog.ErrorContext(ctx, "failed to flush YouTube API quota usage", "err", err)
+	}
+
+	budget := app.Configs.Constants.YoutubeAPIDailyQuotaBudget
+	usage := app.QuotaMeter.Usage()
+	if budget <= 0 || usage.ProjectedUnits <= budget || app.quotaAlertedDate == usage.Date {
+		return
+	}
+	app.quotaAlertedDate = usage.Date
+	app.MessageToOwner(ctx, "YouTube Data APIの使用量が予算を超える見込みです。\n"+app.youtubeAPIQuotaReport(usage))
+}
+
+// ThrottledPollingInterval 使用量を予算に収めるためのポーリングの最小の間隔。制限しない場合は0
+func (app *WorkspaceApp) ThrottledPollingInterval() time.Duration {
+	return app.QuotaMeter.Usage().ThrottledPollingInterval(app.Configs.Constants.YoutubeAPIDailyQuotaBudget)
+}
+
+func (app *WorkspaceApp) youtubeAPIQuotaReport(usage youtubebot.QuotaUsage) string {
+	budget := app.Configs.Constants.YoutubeAPIDailyQuotaBudget
+	var b strings.Builder
+	fmt.Fprintf(&b, "YouTube Data APIのクォータ使用量（%s 太平洋時間）\n", usage.Date)
+	if budget > 0 {
+		fmt.Fprintf(&b, "使用量: %d / %d units（1日の見込み: %d units）\n", usage.Units, budget, usage.ProjectedUnits)
+	} else {
+		fmt.Fprintf(&b, "使用量: %d units（1日の見込み: %d units、予算は未設定）\n", usage.Units, usage.ProjectedUnits)
+	}
+	fmt.Fprintf(&b, "リセットまで: %s\n", usage.UntilReset.Truncate(time.Minute))
+	for _, method := range youtubebot.QuotaMethods {
+		if n := usage.Calls[method]; n > 0 {
+			fmt.Fprintf(&b, "- %s: %d回（%d units）\n", method, n, n*youtubebot.QuotaCost(method))
+		}
+	}
+	if interval := usage.ThrottledPollingInterval(budget); interval > 0 {
+		fmt.Fprintf(&b, "ポーリングの間隔を%s以上に延ばしています\n", interval.Round(time.Second))
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
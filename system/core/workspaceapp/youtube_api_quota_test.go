@@ -0,0 +1,88 @@
+package workspaceapp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"app.modules/core/i18n"
+	"app.modules/core/repository"
+	"app.modules/core/youtubebot"
+	mock_youtubebot "app.modules/core/youtubebot/mocks"
+)
+
+func TestSystem_Quota(t *testing.T) {
+	require.NoError(t, i18n.LoadLocaleFolderFS())
+	ctrl := gomock.NewController(t)
+	ctx := context.Background()
+
+	quotaMeter := youtubebot.NewQuotaMeter(repository.NewInMemoryRepository())
+	quotaMeter.Record(youtubebot.QuotaListMessages)
+	quotaMeter.Record(youtubebot.QuotaInsertMessage)
+
+	t.Run("モデレーター以外は使えない", func(t *testing.T) {
+		mockLiveChatBot := mock_youtubebot.NewMockLiveChatBot(ctrl)
+		mockLiveChatBot.EXPECT().PostMessage(gomock.Any(), "@テストユーザー さんは「!quota」コマンドを使用できません🔒").Return(nil)
+		ownerBot := &spyMessageBot{}
+		app := WorkspaceApp{
+			Configs:                  &Configs{},
+			ChatSources:              youtubeChatSources(mockLiveChatBot),
+			QuotaMeter:               quotaMeter,
+			alertOwnerBot:            ownerBot,
+			ProcessedUserDisplayName: "テストユーザー",
+		}
+		require.NoError(t, app.Quota(ctx))
+		assert.Empty(t, ownerBot.messages)
+	})
+
+	t.Run("使用量をownerに送る", func(t *testing.T) {
+		mockLiveChatBot := mock_youtubebot.NewMockLiveChatBot(ctrl)
+		mockLiveChatBot.EXPECT().PostMessage(gomock.Any(), "@モデレーター さん、情報を送信しました📨").Return(nil)
+		ownerBot := &spyMessageBot{}
+		app := WorkspaceApp{
+			Configs:                         &Configs{Constants: repository.ConstantsConfigDoc{YoutubeAPIDailyQuotaBudget: 10000}},
+			ChatSources:                     youtubeChatSources(mockLiveChatBot),
+			QuotaMeter:                      quotaMeter,
+			alertOwnerBot:                   ownerBot,
+			ProcessedUserDisplayName:        "モデレーター",
+			ProcessedUserIsModeratorOrOwner: true,
+		}
+		require.NoError(t, app.Quota(ctx))
+		require.Len(t, ownerBot.messages, 1)
+		assert.Contains(t, ownerBot.messages[0], "使用量: 55 / 10000 units")
+		assert.Contains(t, ownerBot.messages[0], "- list-messages: 1回（5 units）")
+		assert.Contains(t, ownerBot.messages[0], "- insert-message: 1回（50 units）")
+		assert.NotContains(t, ownerBot.messages[0], "insert-ban")
+	})
+}
+
+func TestFlushYoutubeAPIQuota(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewInMemoryRepository()
+	ownerBot := &spyMessageBot{}
+	app := WorkspaceApp{
+		Configs:       &Configs{Constants: repository.ConstantsConfigDoc{YoutubeAPIDailyQuotaBudget: 0}},
+		QuotaMeter:    youtubebot.NewQuotaMeter(repo),
+		alertOwnerBot: ownerBot,
+	}
+
+	// 予算がなければ知らせず、制限もしない
+	app.QuotaMeter.Record(youtubebot.QuotaListMessages)
+	app.FlushYoutubeAPIQuota(ctx)
+	stored, err := repo.ReadYoutubeAPIQuotaUsage(ctx, app.QuotaMeter.Usage().Date)
+	require.NoError(t, err)
+	assert.Equal(t, 5, stored.Units)
+	assert.Empty(t, ownerBot.messages)
+	assert.Zero(t, app.ThrottledPollingInterval())
+
+	// 見込みが予算を超えたら、その日は1回だけ知らせてポーリングの間隔を延ばす
+	app.Configs.Constants.YoutubeAPIDailyQuotaBudget = 1
+	app.FlushYoutubeAPIQuota(ctx)
+	app.FlushYoutubeAPIQuota(ctx)
+	require.Len(t, ownerBot.messages, 1)
+	assert.Contains(t, ownerBot.messages[0], "予算を超える見込み")
+	assert.Equal(t, youtubebot.MaxThrottledPollingInterval, app.ThrottledPollingInterval())
+}
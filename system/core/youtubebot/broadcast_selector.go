@@ -0,0 +1,115 @@
+package youtubebot
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"google.golang.org/api/youtube/v3"
+
+	"app.modules/core/repository"
+)
+
+// BroadcastSelectionPolicy アクティブな配信が複数あるときに、どの配信のライブチャットを使うか
+type BroadcastSelectionPolicy string
+
+const (
+	// SelectOnlyBroadcast アクティブな配信が1つだけのときのみ選ぶ
+	SelectOnlyBroadcast BroadcastSelectionPolicy = ""
+	// SelectNewestBroadcast 開始時刻がもっとも新しい配信を選ぶ
+	SelectNewestBroadcast BroadcastSelectionPolicy = "newest"
+	// SelectBroadcastByTitle タイトルが正規表現に一致する配信を選ぶ
+	SelectBroadcastByTitle BroadcastSelectionPolicy = "title"
+	// SelectBroadcastByBoundStream 指定したライブストリーム（配信ソフトの送信先）に紐づいた配信を選ぶ
+	SelectBroadcastByBoundStream BroadcastSelectionPolicy = "bound-stream"
+)
+
+// BroadcastSelector 配信の選び方。条件に一致する配信が複数あれば、開始時刻がもっとも新しいものを選ぶ
+type BroadcastSelector struct {
+	policy        BroadcastSelectionPolicy
+	titlePattern  *regexp.Regexp
+	boundStreamID string
+}
+
+// NewBroadcastSelector policyに必要な値（titlePatternやboundStreamID）が不正ならエラーを返す
+func NewBroadcastSelector(policy BroadcastSelectionPolicy, titlePattern string, boundStreamID string) (BroadcastSelector, error) {
+	selector := BroadcastSelector{policy: policy, boundStreamID: boundStreamID}
+	switch policy {
+	case SelectOnlyBroadcast, SelectNewestBroadcast:
+	case SelectBroadcastByTitle:
+		if titlePattern == "" {
+			return BroadcastSelector{}, errors.New("title pattern is required for the title policy")
+		}
+		pattern, err := regexp.Compile(titlePattern)
+		if err != nil {
+			return BroadcastSelector{}, fmt.Errorf("in regexp.Compile(): %w", err)
+		}
+		selector.titlePattern = pattern
+	case SelectBroadcastByBoundStream:
+		if boundStreamID == "" {
+			return BroadcastSelector{}, errors.New("bound stream id is required for the bound-stream policy")
+		}
+	default:
+		return BroadcastSelector{}, fmt.Errorf("unknown broadcast selection policy: %q", policy)
+	}
+	return selector, nil
+}
+
+// BroadcastSelectorFromConstants config/constantsの設定から配信の選び方を作る
+func BroadcastSelectorFromConstants(constants repository.ConstantsConfigDoc) (BroadcastSelector, error) {
+	return NewBroadcastSelector(BroadcastSelectionPolicy(constants.LiveBroadcastSelectionPolicy),
+		constants.LiveBroadcastTitlePattern, constants.LiveBroadcastBoundStreamID)
+}
+
+// Select broadcastsからライブチャットを使う配信を1つ選ぶ
+func (s BroadcastSelector) Select(broadcasts []*youtube.LiveBroadcast) (*youtube.LiveBroadcast, error) {
+	if len(broadcasts) == 0 {
+		return nil, errors.New("ライブ1個もやってない")
+	}
+	if s.policy == SelectOnlyBroadcast {
+		if len(broadcasts) > 1 {
+			return nil, errors.New("more than 2 live broadcasts!: " + strconv.Itoa(len(broadcasts)))
+		}
+		return broadcasts[0], nil
+	}
+
+	var selected *youtube.LiveBroadcast
+	for _, broadcast := range broadcasts {
+		if !s.matches(broadcast) {
+			continue
+		}
+		if selected == nil || broadcastStartedAt(broadcast).After(broadcastStartedAt(selected)) {
+			selected = broadcast
+		}
+	}
+	if selected == nil {
+		return nil, fmt.Errorf("no active live broadcast matches the %q policy (%d active)", s.policy, len(broadcasts))
+	}
+	return selected, nil
+}
+
+func (s BroadcastSelector) matches(broadcast *youtube.LiveBroadcast) bool {
+	switch s.policy {
+	case SelectBroadcastByTitle:
+		return broadcast.Snippet != nil && s.titlePattern.MatchString(broadcast.Snippet.Title)
+	case SelectBroadcastByBoundStream:
+		return broadcast.ContentDetails != nil && broadcast.ContentDetails.BoundStreamId == s.boundStreamID
+	default:
+		return true
+	}
+}
+
+// broadcastStartedAt 配信の開始時刻。開始前や不明な場合は予定の開始時刻、それもなければゼロ値
+func broadcastStartedAt(broadcast *youtube.LiveBroadcast) time.Time {
+	if broadcast.Snippet == nil {
+		return time.Time{}
+	}
+	for _, value := range []string{broadcast.Snippet.ActualStartTime, broadcast.Snippet.ScheduledStartTime} {
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
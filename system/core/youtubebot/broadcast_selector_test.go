@@ -0,0 +1,105 @@
+package youtubebot
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/youtube/v3"
+
+	"app.modules/core/repository"
+)
+
+func testBroadcast(id string, title string, startedAt string, boundStreamID string) *youtube.LiveBroadcast {
+	return &youtube.LiveBroadcast{
+		Id:             id,
+		Snippet:        &youtube.LiveBroadcastSnippet{Title: title, ActualStartTime: startedAt, LiveChatId: "chat-" + id},
+		ContentDetails: &youtube.LiveBroadcastContentDetails{BoundStreamId: boundStreamID},
+	}
+}
+
+func TestBroadcastSelector_Select(t *testing.T) {
+	older := testBroadcast("older", "作業部屋 #1", "2026-08-01T10:00:00Z", "stream-a")
+	newer := testBroadcast("newer", "作業部屋 #2", "2026-08-02T10:00:00Z", "stream-b")
+	other := testBroadcast("other", "雑談配信", "2026-08-03T10:00:00Z", "stream-c")
+	broadcasts := []*youtube.LiveBroadcast{older, newer, other}
+
+	tests := []struct {
+		name          string
+		policy        BroadcastSelectionPolicy
+		titlePattern  string
+		boundStreamID string
+		broadcasts    []*youtube.LiveBroadcast
+		expected      *youtube.LiveBroadcast
+		expectErr     bool
+	}{
+		{name: "only_single", broadcasts: []*youtube.LiveBroadcast{older}, expected: older},
+		{name: "only_multiple", broadcasts: broadcasts, expectErr: true},
+		{name: "none", policy: SelectNewestBroadcast, expectErr: true},
+		{name: "newest", policy: SelectNewestBroadcast, broadcasts: broadcasts, expected: other},
+		{name: "title_newest_match", policy: SelectBroadcastByTitle, titlePattern: "^作業部屋", broadcasts: broadcasts, expected: newer},
+		{name: "title_no_match", policy: SelectBroadcastByTitle, titlePattern: "^ゲーム", broadcasts: broadcasts, expectErr: true},
+		{name: "bound_stream", policy: SelectBroadcastByBoundStream, boundStreamID: "stream-a", broadcasts: broadcasts, expected: older},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selector, err := NewBroadcastSelector(tt.policy, tt.titlePattern, tt.boundStreamID)
+			require.NoError(t, err)
+			selected, err := selector.Select(tt.broadcasts)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Same(t, tt.expected, selected)
+		})
+	}
+}
+
+func TestNewBroadcastSelector_Invalid(t *testing.T) {
+	_, err := NewBroadcastSelector("oldest", "", "")
+	assert.Error(t, err)
+	_, err = NewBroadcastSelector(SelectBroadcastByTitle, "", "")
+	assert.Error(t, err)
+	_, err = NewBroadcastSelector(SelectBroadcastByTitle, "(", "")
+	assert.Error(t, err)
+	_, err = NewBroadcastSelector(SelectBroadcastByBoundStream, "", "")
+	assert.Error(t, err)
+}
+
+func TestRefreshLiveChatIDSelectsAmongMultipleBroadcasts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/youtube/v3/liveBroadcasts", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		writeResponseBody(t, w, `{"items":[`+
+			`{"id":"old","snippet":{"title":"作業部屋","actualStartTime":"2026-08-01T10:00:00Z","liveChatId":"old-chat"},"contentDetails":{"boundStreamId":"room-stream"}},`+
+			`{"id":"new","snippet":{"title":"作業部屋","actualStartTime":"2026-08-02T10:00:00Z","liveChatId":"new-chat"},"contentDetails":{"boundStreamId":"other-stream"}}]}`)
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	repo := repository.NewInMemoryRepository()
+	require.NoError(t, repo.SetCredentialsConfig(repository.CredentialsConfigDoc{}))
+	service := newTestYouTubeService(t, server)
+	bot := &YoutubeLiveChatBot{ChannelYoutubeService: service, FirestoreController: repo}
+
+	// 設定がなければ従来どおり複数の配信は選ばない
+	assert.Error(t, bot.refreshLiveChatID(ctx))
+
+	require.NoError(t, repo.SetSystemConstantsConfig(repository.ConstantsConfigDoc{LiveBroadcastSelectionPolicy: "newest"}))
+	require.NoError(t, bot.refreshLiveChatID(ctx))
+	assert.Equal(t, "new-chat", bot.currentLiveChatID())
+
+	require.NoError(t, repo.SetSystemConstantsConfig(repository.ConstantsConfigDoc{
+		LiveBroadcastSelectionPolicy: "bound-stream",
+		LiveBroadcastBoundStreamID:   "room-stream",
+	}))
+	require.NoError(t, bot.refreshLiveChatID(ctx))
+	assert.Equal(t, "old-chat", bot.currentLiveChatID())
+	credentials, err := repo.ReadCredentialsConfig(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "old-chat", credentials.YoutubeLiveChatID)
+}
@@ -0,0 +1,21 @@
+package youtubebot
+
+import (
+	"app.modules/core/chat"
+)
+
+var _ chat.ChatSource = (*ChatSource)(nil)
+
+// ChatSource LiveChatBotをchat.ChatSourceとして扱う。ライブチャットへの投稿とモデレーションはそのまま内側のLiveChatBotを呼ぶ。
+// チャットの受信はpage tokenの保存と支援のイベントの処理があるため、これではなくLiveChatReceiverで行う。
+type ChatSource struct {
+	LiveChatBot
+}
+
+func NewChatSource(bot LiveChatBot) *ChatSource {
+	return &ChatSource{LiveChatBot: bot}
+}
+
+func (s *ChatSource) Platform() chat.Platform {
+	return chat.YouTube
+}
@@ -0,0 +1,21 @@
+package youtubebot
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app.modules/core/chat"
+)
+
+func TestChatSource(t *testing.T) {
+	bot := &recordingPostBot{}
+	source := NewChatSource(bot)
+	assert.Equal(t, chat.YouTube, source.Platform())
+
+	// 投稿は内側のLiveChatBotに送る
+	require.NoError(t, source.PostMessage(context.Background(), "hello"))
+	assert.Equal(t, []string{"hello"}, bot.posts)
+}
@@ -0,0 +1,70 @@
+package youtubebot
+
+import (
+	"fmt"
+	"time"
+
+	"google.golang.org/api/youtube/v3"
+
+	"app.modules/core/repository"
+)
+
+// FanFundingEvent スーパーチャット・スーパーステッカー・メンバーシップ加入・ギフトのライブチャットのメッセージ
+type FanFundingEvent struct {
+	Type        repository.FanFundingEventType
+	MessageID   string
+	UserID      string
+	DisplayName string
+
+	// スーパーチャット・スーパーステッカーの場合のみ
+	AmountMicros        uint64
+	Currency            string
+	AmountDisplayString string
+
+	// メンバーシップ加入・ギフトの場合のみ
+	MembershipLevelName string
+	GiftCount           int
+
+	PublishedAt time.Time
+}
+
+// ParseFanFundingEvent chatが特典の対象になる支援のイベントであれば、FanFundingEventにして返す。
+// メンバーシップのマイルストーンなど、対象でないイベントの場合はfalseを返す。
+func ParseFanFundingEvent(chat *youtube.LiveChatMessage) (FanFundingEvent, bool, error) {
+	if chat.Snippet == nil || chat.AuthorDetails == nil {
+		return FanFundingEvent{}, false, nil
+	}
+	event := FanFundingEvent{
+		MessageID:   chat.Id,
+		UserID:      ExtractAuthorChannelID(chat),
+		DisplayName: ExtractAuthorDisplayName(chat),
+	}
+	switch {
+	case chat.Snippet.Type == SuperChatEvent && chat.Snippet.SuperChatDetails != nil:
+		details := chat.Snippet.SuperChatDetails
+		event.Type = repository.SuperChatFanFundingEvent
+		event.AmountMicros, event.Currency, event.AmountDisplayString = details.AmountMicros, details.Currency, details.AmountDisplayString
+	case chat.Snippet.Type == SuperStickerEvent && chat.Snippet.SuperStickerDetails != nil:
+		details := chat.Snippet.SuperStickerDetails
+		event.Type = repository.SuperStickerFanFundingEvent
+		event.AmountMicros, event.Currency, event.AmountDisplayString = details.AmountMicros, details.Currency, details.AmountDisplayString
+	case chat.Snippet.Type == NewSponsorEvent:
+		event.Type = repository.NewMembershipFanFundingEvent
+		if chat.Snippet.NewSponsorDetails != nil {
+			event.MembershipLevelName = chat.Snippet.NewSponsorDetails.MemberLevelName
+		}
+	case chat.Snippet.Type == MembershipGiftingEvent && chat.Snippet.MembershipGiftingDetails != nil:
+		details := chat.Snippet.MembershipGiftingDetails
+		event.Type = repository.MembershipGiftFanFundingEvent
+		event.MembershipLevelName, event.GiftCount = details.GiftMembershipsLevelName, int(details.GiftMembershipsCount)
+	default:
+		return FanFundingEvent{}, false, nil
+	}
+
+	publishedAt, err := time.Parse(time.RFC3339Nano, chat.Snippet.PublishedAt)
+	if err != nil {
+		return FanFundingEvent{}, false, fmt.Errorf("failed to Parse publishedAt: %w", err)
+	}
+	event.PublishedAt = publishedAt
+	return event, true, nil
+}
@@ -0,0 +1,109 @@
+package youtubebot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/youtube/v3"
+
+	"app.modules/core/repository"
+)
+
+func TestParseFanFundingEvent(t *testing.T) {
+	newMessage := func(snippet *youtube.LiveChatMessageSnippet) *youtube.LiveChatMessage {
+		snippet.PublishedAt = "2026-01-01T01:00:00.123Z"
+		return &youtube.LiveChatMessage{
+			Id:            "message-id",
+			Snippet:       snippet,
+			AuthorDetails: &youtube.LiveChatMessageAuthorDetails{ChannelId: "channel-id", DisplayName: "@user"},
+		}
+	}
+	publishedAt := time.Date(2026, time.January, 1, 1, 0, 0, 123000000, time.UTC)
+
+	tests := []struct {
+		name     string
+		chat     *youtube.LiveChatMessage
+		expected FanFundingEvent
+		ok       bool
+	}{
+		{
+			name: "Super Chat",
+			chat: newMessage(&youtube.LiveChatMessageSnippet{
+				Type: SuperChatEvent,
+				SuperChatDetails: &youtube.LiveChatSuperChatDetails{
+					AmountMicros: 1000000000, Currency: "JPY", AmountDisplayString: "￥1,000",
+				},
+			}),
+			expected: FanFundingEvent{
+				Type: repository.SuperChatFanFundingEvent, MessageID: "message-id", UserID: "channel-id", DisplayName: "user",
+				AmountMicros: 1000000000, Currency: "JPY", AmountDisplayString: "￥1,000", PublishedAt: publishedAt,
+			},
+			ok: true,
+		},
+		{
+			name: "Super Sticker",
+			chat: newMessage(&youtube.LiveChatMessageSnippet{
+				Type: SuperStickerEvent,
+				SuperStickerDetails: &youtube.LiveChatSuperStickerDetails{
+					AmountMicros: 2000000, Currency: "USD", AmountDisplayString: "$2.00",
+				},
+			}),
+			expected: FanFundingEvent{
+				Type: repository.SuperStickerFanFundingEvent, MessageID: "message-id", UserID: "channel-id", DisplayName: "user",
+				AmountMicros: 2000000, Currency: "USD", AmountDisplayString: "$2.00", PublishedAt: publishedAt,
+			},
+			ok: true,
+		},
+		{
+			name: "New membership",
+			chat: newMessage(&youtube.LiveChatMessageSnippet{
+				Type:              NewSponsorEvent,
+				NewSponsorDetails: &youtube.LiveChatNewSponsorDetails{MemberLevelName: "メンバー"},
+			}),
+			expected: FanFundingEvent{
+				Type: repository.NewMembershipFanFundingEvent, MessageID: "message-id", UserID: "channel-id", DisplayName: "user",
+				MembershipLevelName: "メンバー", PublishedAt: publishedAt,
+			},
+			ok: true,
+		},
+		{
+			name: "Membership gift",
+			chat: newMessage(&youtube.LiveChatMessageSnippet{
+				Type: MembershipGiftingEvent,
+				MembershipGiftingDetails: &youtube.LiveChatMembershipGiftingDetails{
+					GiftMembershipsCount: 5, GiftMembershipsLevelName: "メンバー",
+				},
+			}),
+			expected: FanFundingEvent{
+				Type: repository.MembershipGiftFanFundingEvent, MessageID: "message-id", UserID: "channel-id", DisplayName: "user",
+				MembershipLevelName: "メンバー", GiftCount: 5, PublishedAt: publishedAt,
+			},
+			ok: true,
+		},
+		{
+			name: "Member milestone is not a reward target",
+			chat: newMessage(&youtube.LiveChatMessageSnippet{Type: MemberMilestoneChatEvent}),
+			ok:   false,
+		},
+		{
+			name: "Text message",
+			chat: newMessage(&youtube.LiveChatMessageSnippet{Type: "textMessageEvent"}),
+			ok:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, ok, err := ParseFanFundingEvent(tt.chat)
+			require.NoError(t, err)
+			assert.Equal(t, tt.ok, ok)
+			if tt.ok {
+				assert.True(t, tt.expected.PublishedAt.Equal(event.PublishedAt))
+				event.PublishedAt = tt.expected.PublishedAt
+				assert.Equal(t, tt.expected, event)
+			}
+		})
+	}
+}
@@ -5,15 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
-	"strconv"
-	"unicode/utf8"
+	"net/http"
+	"time"
 
-	"cloud.google.com/go/firestore"
 	"golang.org/x/oauth2"
 	"google.golang.org/api/googleapi"
-	"google.golang.org/api/option"
 	"google.golang.org/api/youtube/v3"
 
+	"app.modules/core/chat"
 	"app.modules/core/repository"
 	"app.modules/core/utils"
 )
@@ -27,11 +26,16 @@ type AccessTokenResponseStruct struct {
 	TokenType   string `json:"token_type"`
 }
 
-func NewYoutubeLiveChatBot(liveChatID string, controller repository.Repository, ctx context.Context) (LiveChatBot, error) {
+// NewYoutubeLiveChatBot endpointが空でなければ、認証せずにそのYouTube Data API（シミュレーターなど）に接続する。
+// APIの呼び出しはquotaMeterに記録する。
+func NewYoutubeLiveChatBot(liveChatID string, controller repository.Repository, endpoint string, quotaMeter *QuotaMeter,
+	ctx context.Context,
+) (LiveChatBot, error) {
 	var channelYoutubeService *youtube.Service
 	var botYoutubeService *youtube.Service
+	var botHTTPClient *http.Client
 
-	txErr := controller.FirestoreClient().RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+	txErr := controller.RunTransaction(ctx, func(ctx context.Context, tx repository.Transaction) error {
 		credentials, err := controller.ReadCredentialsConfig(ctx, tx)
 		if err != nil {
 			return fmt.Errorf("read YouTube credentials: %w", err)
@@ -50,8 +54,7 @@ func NewYoutubeLiveChatBot(liveChatID string, controller repository.Repository,
 			RefreshToken: credentials.YoutubeChannelRefreshToken,
 		}
 		channelTokenSource := channelConfig.TokenSource(ctx, channelToken)
-		channelClientOption := option.WithTokenSource(channelTokenSource)
-		channelYoutubeService, err = youtube.NewService(ctx, channelClientOption)
+		channelYoutubeService, _, err = NewYoutubeService(ctx, endpoint, channelTokenSource)
 		if err != nil {
 			return fmt.Errorf("create channel YouTube service: %w", err)
 		}
@@ -69,8 +72,7 @@ func NewYoutubeLiveChatBot(liveChatID string, controller repository.Repository,
 			RefreshToken: credentials.YoutubeBotRefreshToken,
 		}
 		botTokenSource := botConfig.TokenSource(ctx, botToken)
-		botClientOption := option.WithTokenSource(botTokenSource)
-		botYoutubeService, err = youtube.NewService(ctx, botClientOption)
+		botYoutubeService, botHTTPClient, err = NewYoutubeService(ctx, endpoint, botTokenSource)
 		if err != nil {
 			return fmt.Errorf("create bot YouTube service: %w", err)
 		}
@@ -84,13 +86,15 @@ func NewYoutubeLiveChatBot(liveChatID string, controller repository.Repository,
 		LiveChatID:            liveChatID,
 		ChannelYoutubeService: channelYoutubeService,
 		BotYoutubeService:     botYoutubeService,
+		BotHTTPClient:         botHTTPClient,
 		FirestoreController:   controller,
+		QuotaMeter:            quotaMeter,
 	}, nil
 }
 
 func (b *YoutubeLiveChatBot) ListMessages(ctx context.Context, nextPageToken string) ([]*youtube.LiveChatMessage, string, int, error) {
 	// 1回目の試行
-	response, err := b.tryListMessages(nextPageToken, b.LiveChatID)
+	response, err := b.tryListMessages(nextPageToken, b.currentLiveChatID())
 	if err == nil {
 		return response.Items, response.NextPageToken, int(response.PollingIntervalMillis), nil
 	}
@@ -119,7 +123,7 @@ func (b *YoutubeLiveChatBot) ListMessages(ctx context.Context, nextPageToken str
 
 	// 2回目の試行（更新されたLiveChatIDで）
 	slog.Info("trying second call in ListMessages()...")
-	response, err = b.tryListMessages(nextPageToken, b.LiveChatID)
+	response, err = b.tryListMessages(nextPageToken, b.currentLiveChatID())
 	if err != nil {
 		slog.Error("second call failed in tryListMessages()")
 		return nil, "", 0, err
@@ -141,6 +145,7 @@ func (b *YoutubeLiveChatBot) tryListMessages(nextPageToken string, liveChatID st
 		listCall = listCall.PageToken(nextPageToken)
 	}
 
+	b.QuotaMeter.Record(QuotaListMessages)
 	response, err := listCall.Do()
 	if err != nil {
 		return nil, fmt.Errorf("list live chat messages: %w", err)
@@ -151,28 +156,7 @@ func (b *YoutubeLiveChatBot) tryListMessages(nextPageToken string, liveChatID st
 func (b *YoutubeLiveChatBot) PostMessage(ctx context.Context, message string) error {
 	slog.Info("sending a message to Youtube Live.", "message", message)
 
-	if utf8.RuneCountInString(message) <= MaxLiveChatMessageLength {
-		return b.postMessage(ctx, message)
-	}
-	var messages []string
-	for {
-		if utf8.RuneCountInString(message) <= MaxLiveChatMessageLength {
-			messages = append(messages, message)
-			break
-		}
-		var p int // 文字列中のインデックス
-		for i := range message {
-			if utf8.RuneCountInString(message[:i]) > MaxLiveChatMessageLength {
-				break
-			}
-			p = i
-		}
-
-		// リストに追加
-		messages = append(messages, message[:p])
-		message = message[p:]
-	}
-	for _, m := range messages {
+	for _, m := range chat.SplitMessage(message, MaxLiveChatMessageLength) {
 		if err := b.postMessage(ctx, m); err != nil {
 			return err
 		}
@@ -186,7 +170,7 @@ func (b *YoutubeLiveChatBot) postMessage(ctx context.Context, message string) er
 	}
 
 	// メッセージ送信を試行
-	err := b.tryPostMessage(message, b.LiveChatID)
+	err := b.tryPostMessage(message, b.currentLiveChatID())
 	if err == nil {
 		return nil
 	}
@@ -197,7 +181,7 @@ func (b *YoutubeLiveChatBot) postMessage(ctx context.Context, message string) er
 
 	// 2回目の試行
 	slog.Warn("first post failed; retrying", "err", err)
-	err = b.tryPostMessage(message, b.LiveChatID)
+	err = b.tryPostMessage(message, b.currentLiveChatID())
 	if err == nil {
 		slog.Info("second post succeeded!")
 		return nil
@@ -215,7 +199,7 @@ func (b *YoutubeLiveChatBot) postMessage(ctx context.Context, message string) er
 	}
 
 	// 3回目の試行（更新されたLiveChatIDで）
-	err = b.tryPostMessage(message, b.LiveChatID)
+	err = b.tryPostMessage(message, b.currentLiveChatID())
 	if err != nil {
 		if handleLiveChatEndedPostFailure(err) {
 			return nil
@@ -252,6 +236,7 @@ func (b *YoutubeLiveChatBot) tryPostMessage(message string, liveChatID string) e
 	}
 	liveChatMessageService := youtube.NewLiveChatMessagesService(b.BotYoutubeService)
 	insertCall := liveChatMessageService.Insert(part, &liveChatMessage)
+	b.QuotaMeter.Record(QuotaInsertMessage)
 	_, err := insertCall.Do()
 	if err != nil {
 		return fmt.Errorf("insert live chat message: %w", err)
@@ -259,10 +244,13 @@ func (b *YoutubeLiveChatBot) tryPostMessage(message string, liveChatID string) e
 	return nil
 }
 
-// refreshLiveChatID live chat idを取得するとともに、firestoreに保存（更新）する
+// refreshLiveChatID live chat idを取得するとともに、firestoreに保存（更新）する。
+// アクティブな配信が複数ある場合は、config/constantsの選び方（BroadcastSelector）に従って選ぶ
 func (b *YoutubeLiveChatBot) refreshLiveChatID(ctx context.Context) error {
 	slog.Info(utils.NameOf(b.refreshLiveChatID))
 
+	selector := b.broadcastSelector(ctx)
+
 	// 1回目の試行
 	response, err := b.fetchActiveBroadcasts()
 	if err != nil {
@@ -270,41 +258,56 @@ func (b *YoutubeLiveChatBot) refreshLiveChatID(ctx context.Context) error {
 		return err
 	}
 
-	if len(response.Items) == 1 {
-		return b.updateLiveChatID(ctx, response.Items[0].Snippet.LiveChatId)
-	} else if len(response.Items) == 0 {
+	if len(response.Items) == 0 {
 		slog.Warn("ライブ1個もやってない（1回目）")
 
 		// たまに、配信してるのにこの結果になることがあるかも（未確認）しれないので、もう一度。
-		response, err := b.fetchActiveBroadcasts()
+		response, err = b.fetchActiveBroadcasts()
 		if err != nil {
 			slog.Error("second attempt to fetch broadcasts failed", "err", err)
 			return err
 		}
+	}
 
-		if len(response.Items) == 1 {
-			return b.updateLiveChatID(ctx, response.Items[0].Snippet.LiveChatId)
-		} else if len(response.Items) == 0 {
-			return errors.New("2回試したけどライブ1個もやってない")
-		} else {
-			return errors.New("more than 2 live broadcasts!: " + strconv.Itoa(len(response.Items)))
-		}
-	} else {
-		return errors.New("more than 2 live broadcasts!: " + strconv.Itoa(len(response.Items)))
+	broadcast, err := selector.Select(response.Items)
+	if err != nil {
+		return fmt.Errorf("select live broadcast: %w", err)
 	}
+	if len(response.Items) > 1 {
+		slog.Info("selected live broadcast", "id", broadcast.Id, "title", broadcast.Snippet.Title,
+			"numActiveBroadcasts", len(response.Items))
+	}
+	return b.updateLiveChatID(ctx, broadcast.Snippet.LiveChatId)
+}
+
+// broadcastSelector 配信の選び方をconfig/constantsから読む。読めない場合は1つのときのみ選ぶ
+func (b *YoutubeLiveChatBot) broadcastSelector(ctx context.Context) BroadcastSelector {
+	constants, err := b.FirestoreController.ReadSystemConstantsConfig(ctx, nil)
+	if err != nil {
+		slog.Warn("failed to read broadcast selection policy; selecting only a single broadcast", "err", err)
+		return BroadcastSelector{}
+	}
+	selector, err := BroadcastSelectorFromConstants(constants)
+	if err != nil {
+		slog.Warn("invalid broadcast selection policy; selecting only a single broadcast", "err", err)
+		return BroadcastSelector{}
+	}
+	return selector
 }
 
 // fetchActiveBroadcasts アクティブな配信を取得する
 func (b *YoutubeLiveChatBot) fetchActiveBroadcasts() (*youtube.LiveBroadcastListResponse, error) {
 	broadCastsService := youtube.NewLiveBroadcastsService(b.ChannelYoutubeService)
-	part := []string{"snippet"}
+	part := []string{"snippet", "contentDetails"} // contentDetailsはBoundStreamIdによる選択に使う
 	listCall := broadCastsService.List(part).BroadcastStatus("active")
+	b.QuotaMeter.Record(QuotaListBroadcasts)
 	response, err := listCall.Do()
 	if err != nil {
 		slog.Info("trying second call...")
 		// 失敗した場合は再試行
 		broadCastsService = youtube.NewLiveBroadcastsService(b.ChannelYoutubeService)
 		listCall = broadCastsService.List(part).BroadcastStatus("active")
+		b.QuotaMeter.Record(QuotaListBroadcasts)
 		response, err = listCall.Do()
 	}
 	if err != nil {
@@ -319,36 +322,59 @@ func (b *YoutubeLiveChatBot) updateLiveChatID(ctx context.Context, newLiveChatID
 	if err := b.FirestoreController.UpdateLiveChatID(ctx, nil, newLiveChatID); err != nil {
 		return fmt.Errorf("persist live chat ID: %w", err)
 	}
+	b.liveChatIDMu.Lock()
+	defer b.liveChatIDMu.Unlock()
 	b.LiveChatID = newLiveChatID
 	return nil
 }
 
-// BanUser 指定したユーザー（Youtubeチャンネル）をブロックする。
-func (b *YoutubeLiveChatBot) BanUser(ctx context.Context, userID string) error {
+func (b *YoutubeLiveChatBot) currentLiveChatID() string {
+	b.liveChatIDMu.RLock()
+	defer b.liveChatIDMu.RUnlock()
+	return b.LiveChatID
+}
+
+// BanUser 指定したユーザー（Youtubeチャンネル）を無期限でブロックし、ブロックのIDを返す。
+func (b *YoutubeLiveChatBot) BanUser(ctx context.Context, userID string) (string, error) {
+	return b.insertBan(ctx, userID, 0)
+}
+
+// TimeOut 指定したユーザー（Youtubeチャンネル）をdurationの間だけブロックし、ブロックのIDを返す。
+// durationは秒単位に切り捨てられる。
+func (b *YoutubeLiveChatBot) TimeOut(ctx context.Context, userID string, duration time.Duration) (string, error) {
+	if duration < time.Second {
+		return "", fmt.Errorf("timeout duration must be at least 1 second: %s", duration)
+	}
+	return b.insertBan(ctx, userID, duration)
+}
+
+// insertBan durationが0なら無期限、それ以外は一時的なブロックを作成する
+func (b *YoutubeLiveChatBot) insertBan(ctx context.Context, userID string, duration time.Duration) (string, error) {
 	// 1回目の試行
-	err := b.tryBanUser(userID, b.LiveChatID)
+	banID, err := b.tryBanUser(userID, b.currentLiveChatID(), duration)
 	if err == nil {
-		return nil
+		return banID, nil
 	}
 
 	slog.Error("first ban request failed", "err", err)
 
 	// live chat idが変わっている可能性があるため、更新して再試行
 	if err := b.refreshLiveChatID(ctx); err != nil {
-		return err
+		return "", err
 	}
 
 	// 2回目の試行（更新されたLiveChatIDで）
-	if err := b.tryBanUser(userID, b.LiveChatID); err != nil {
+	banID, err = b.tryBanUser(userID, b.currentLiveChatID(), duration)
+	if err != nil {
 		slog.Error("second ban request failed", "err", err)
-		return err
+		return "", err
 	}
 
-	return nil
+	return banID, nil
 }
 
-// tryBanUser 指定されたLiveChatIDでユーザーをブロックする
-func (b *YoutubeLiveChatBot) tryBanUser(userID string, liveChatID string) error {
+// tryBanUser 指定されたLiveChatIDでユーザーをブロックする。durationが0なら無期限
+func (b *YoutubeLiveChatBot) tryBanUser(userID string, liveChatID string, duration time.Duration) (string, error) {
 	part := []string{"snippet"}
 	liveChatBan := youtube.LiveChatBan{
 		Snippet: &youtube.LiveChatBanSnippet{
@@ -359,12 +385,28 @@ func (b *YoutubeLiveChatBot) tryBanUser(userID string, liveChatID string) error
 			},
 		},
 	}
+	if duration > 0 {
+		liveChatBan.Snippet.Type = "temporary"
+		liveChatBan.Snippet.BanDurationSeconds = uint64(duration / time.Second)
+	}
 	liveChatBanService := youtube.NewLiveChatBansService(b.BotYoutubeService)
 	insertCall := liveChatBanService.Insert(part, &liveChatBan)
 
-	_, err := insertCall.Do()
+	b.QuotaMeter.Record(QuotaInsertBan)
+	inserted, err := insertCall.Do()
 	if err != nil {
-		return fmt.Errorf("insert live chat ban: %w", err)
+		return "", fmt.Errorf("insert live chat ban: %w", err)
+	}
+	return inserted.Id, nil
+}
+
+// UnbanUser BanUserまたはTimeOutで作成したブロックを解除する。
+func (b *YoutubeLiveChatBot) UnbanUser(_ context.Context, banID string) error {
+	liveChatBanService := youtube.NewLiveChatBansService(b.BotYoutubeService)
+
+	b.QuotaMeter.Record(QuotaDeleteBan)
+	if err := liveChatBanService.Delete(banID).Do(); err != nil {
+		return fmt.Errorf("delete live chat ban: %w", err)
 	}
 	return nil
 }
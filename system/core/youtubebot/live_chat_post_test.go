@@ -16,6 +16,7 @@ import (
 	"google.golang.org/api/option"
 	"google.golang.org/api/youtube/v3"
 
+	myfirestore "app.modules/core/repository"
 	mock_repository "app.modules/core/repository/mocks"
 )
 
@@ -97,6 +98,9 @@ func TestPostMessageLogsErrorAfterRefreshRetryFails(t *testing.T) {
 	defer ctrl.Finish()
 
 	repository := mock_repository.NewMockRepository(ctrl)
+	repository.EXPECT().
+		ReadSystemConstantsConfig(gomock.Any(), nil).
+		Return(myfirestore.ConstantsConfigDoc{}, nil)
 	repository.EXPECT().
 		UpdateLiveChatID(gomock.Any(), nil, "new-live-chat-id").
 		Return(nil)
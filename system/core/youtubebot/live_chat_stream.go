@@ -0,0 +1,114 @@
+package youtubebot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/youtube/v3"
+)
+
+// ErrStreamingUnavailable liveChatMessages.streamListが使えないことを表す。この場合はListMessagesでポーリングする。
+var ErrStreamingUnavailable = errors.New("live chat streaming is unavailable")
+
+const streamListPath = "youtube/v3/liveChat/messages/stream"
+
+// StreamMessages liveChatMessages.streamListでチャットを受信する。
+// レスポンスはLiveChatMessageListResponseのJSON配列として少しずつ返ってくるので、要素を読むたびにonPageを呼ぶ。
+func (b *YoutubeLiveChatBot) StreamMessages(ctx context.Context, nextPageToken string, onPage func(LiveChatPage) error) error {
+	if b.BotHTTPClient == nil {
+		return ErrStreamingUnavailable
+	}
+
+	err := b.tryStreamMessages(ctx, nextPageToken, b.currentLiveChatID(), onPage)
+	if err == nil || errors.Is(err, ErrStreamingUnavailable) {
+		return err
+	}
+
+	var errGoogle *googleapi.Error
+	if errors.As(err, &errGoogle) {
+		switch errGoogle.Code {
+		case 400, 403, 404:
+			// live chat idが変わっている可能性があるため、更新しておく。再接続は呼び出し元に任せる
+			if refreshErr := b.refreshLiveChatID(ctx); refreshErr != nil {
+				return errors.Join(err, refreshErr)
+			}
+		}
+	}
+	return err
+}
+
+// tryStreamMessages 指定されたLiveChatIDでストリームを開き、閉じられるまで読む
+func (b *YoutubeLiveChatBot) tryStreamMessages(ctx context.Context, nextPageToken string, liveChatID string,
+	onPage func(LiveChatPage) error,
+) error {
+	params := url.Values{
+		"liveChatId": {liveChatID},
+		"part":       {"snippet", "authorDetails"},
+		"alt":        {"json"},
+	}
+	if nextPageToken != "" {
+		params.Set("pageToken", nextPageToken)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.BotYoutubeService.BasePath+streamListPath+"?"+params.Encode(), nil)
+	if err != nil {
+		return fmt.Errorf("in http.NewRequestWithContext(): %w", err)
+	}
+	b.QuotaMeter.Record(QuotaStreamMessages)
+	resp, err := b.BotHTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("open live chat stream: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.Warn("failed to close live chat stream", "err", err)
+		}
+	}()
+	if err := googleapi.CheckResponse(resp); err != nil {
+		if isStreamingUnavailableError(err) {
+			return fmt.Errorf("%w: %w", ErrStreamingUnavailable, err)
+		}
+		return fmt.Errorf("open live chat stream: %w", err)
+	}
+
+	decoder := json.NewDecoder(resp.Body)
+	token, err := decoder.Token()
+	if err != nil {
+		return fmt.Errorf("read live chat stream: %w", err)
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return fmt.Errorf("unexpected start of live chat stream: %v", token)
+	}
+	for decoder.More() {
+		var response youtube.LiveChatMessageListResponse
+		if err := decoder.Decode(&response); err != nil {
+			return fmt.Errorf("read live chat stream: %w", err)
+		}
+		if err := onPage(LiveChatPage{Messages: response.Items, NextPageToken: response.NextPageToken}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isStreamingUnavailableError エンドポイント自体がない（理由の付かない404など）場合にtrue。
+// liveChatNotFoundのように理由が付いた404はlive chat idの問題なので含めない。
+func isStreamingUnavailableError(err error) bool {
+	var errGoogle *googleapi.Error
+	if !errors.As(err, &errGoogle) {
+		return false
+	}
+	switch errGoogle.Code {
+	case http.StatusNotFound:
+		return len(errGoogle.Errors) == 0
+	case http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,86 @@
+package youtubebot
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamMessagesReadsEachResponseOfStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/youtube/v3/liveChat/messages/stream", r.URL.Path)
+		assert.Equal(t, "live-chat-id", r.URL.Query().Get("liveChatId"))
+		assert.Equal(t, "token-0", r.URL.Query().Get("pageToken"))
+		assert.Equal(t, []string{"snippet", "authorDetails"}, r.URL.Query()["part"])
+
+		w.Header().Set("Content-Type", "application/json")
+		writeResponseBody(t, w, `[{"items": [{"id": "m1"}], "nextPageToken": "token-1"}`+"\n")
+		w.(http.Flusher).Flush()
+		writeResponseBody(t, w, `,{"items": [{"id": "m2"}, {"id": "m3"}], "nextPageToken": "token-2"}`+"\n]")
+	}))
+	defer server.Close()
+
+	bot := &YoutubeLiveChatBot{
+		LiveChatID:        "live-chat-id",
+		BotYoutubeService: newTestYouTubeService(t, server),
+		BotHTTPClient:     server.Client(),
+	}
+
+	var pages []LiveChatPage
+	err := bot.StreamMessages(context.Background(), "token-0", func(page LiveChatPage) error {
+		pages = append(pages, page)
+		return nil
+	})
+
+	require.NoError(t, err)
+	require.Len(t, pages, 2)
+	assert.Equal(t, "token-1", pages[0].NextPageToken)
+	assert.Equal(t, "m1", pages[0].Messages[0].Id)
+	assert.Equal(t, "token-2", pages[1].NextPageToken)
+	assert.Len(t, pages[1].Messages, 2)
+}
+
+func TestStreamMessagesStopsWhenOnPageFails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeResponseBody(t, w, `[{"nextPageToken": "token-1"},{"nextPageToken": "token-2"}]`)
+	}))
+	defer server.Close()
+
+	bot := &YoutubeLiveChatBot{
+		LiveChatID:        "live-chat-id",
+		BotYoutubeService: newTestYouTubeService(t, server),
+		BotHTTPClient:     server.Client(),
+	}
+	errStop := errors.New("stop")
+	calls := 0
+	err := bot.StreamMessages(context.Background(), "", func(LiveChatPage) error {
+		calls++
+		return errStop
+	})
+
+	assert.ErrorIs(t, err, errStop)
+	assert.Equal(t, 1, calls)
+}
+
+func TestStreamMessagesReturnsUnavailableWithoutEndpoint(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	bot := &YoutubeLiveChatBot{
+		LiveChatID:        "live-chat-id",
+		BotYoutubeService: newTestYouTubeService(t, server),
+		BotHTTPClient:     server.Client(),
+	}
+	err := bot.StreamMessages(context.Background(), "", func(LiveChatPage) error { return nil })
+	assert.ErrorIs(t, err, ErrStreamingUnavailable)
+
+	// HTTPクライアントがなければストリーミングしない
+	bot.BotHTTPClient = nil
+	err = bot.StreamMessages(context.Background(), "", func(LiveChatPage) error { return nil })
+	assert.ErrorIs(t, err, ErrStreamingUnavailable)
+}
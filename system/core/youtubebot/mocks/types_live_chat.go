@@ -12,7 +12,9 @@ package mock_youtubebot
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
+	youtubebot "app.modules/core/youtubebot"
 	gomock "go.uber.org/mock/gomock"
 	youtube "google.golang.org/api/youtube/v3"
 )
@@ -42,11 +44,12 @@ func (m *MockLiveChatBot) EXPECT() *MockLiveChatBotMockRecorder {
 }
 
 // BanUser mocks base method.
-func (m *MockLiveChatBot) BanUser(ctx context.Context, userID string) error {
+func (m *MockLiveChatBot) BanUser(ctx context.Context, userID string) (string, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "BanUser", ctx, userID)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // BanUser indicates an expected call of BanUser.
@@ -85,3 +88,70 @@ func (mr *MockLiveChatBotMockRecorder) PostMessage(ctx, message any) *gomock.Cal
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostMessage", reflect.TypeOf((*MockLiveChatBot)(nil).PostMessage), ctx, message)
 }
+
+// TimeOut mocks base method.
+func (m *MockLiveChatBot) TimeOut(ctx context.Context, userID string, duration time.Duration) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TimeOut", ctx, userID, duration)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TimeOut indicates an expected call of TimeOut.
+func (mr *MockLiveChatBotMockRecorder) TimeOut(ctx, userID, duration any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TimeOut", reflect.TypeOf((*MockLiveChatBot)(nil).TimeOut), ctx, userID, duration)
+}
+
+// UnbanUser mocks base method.
+func (m *MockLiveChatBot) UnbanUser(ctx context.Context, banID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnbanUser", ctx, banID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnbanUser indicates an expected call of UnbanUser.
+func (mr *MockLiveChatBotMockRecorder) UnbanUser(ctx, banID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnbanUser", reflect.TypeOf((*MockLiveChatBot)(nil).UnbanUser), ctx, banID)
+}
+
+// MockLiveChatStreamer is a mock of LiveChatStreamer interface.
+type MockLiveChatStreamer struct {
+	ctrl     *gomock.Controller
+	recorder *MockLiveChatStreamerMockRecorder
+	isgomock struct{}
+}
+
+// MockLiveChatStreamerMockRecorder is the mock recorder for MockLiveChatStreamer.
+type MockLiveChatStreamerMockRecorder struct {
+	mock *MockLiveChatStreamer
+}
+
+// NewMockLiveChatStreamer creates a new mock instance.
+func NewMockLiveChatStreamer(ctrl *gomock.Controller) *MockLiveChatStreamer {
+	mock := &MockLiveChatStreamer{ctrl: ctrl}
+	mock.recorder = &MockLiveChatStreamerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLiveChatStreamer) EXPECT() *MockLiveChatStreamerMockRecorder {
+	return m.recorder
+}
+
+// StreamMessages mocks base method.
+func (m *MockLiveChatStreamer) StreamMessages(ctx context.Context, nextPageToken string, onPage func(youtubebot.LiveChatPage) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamMessages", ctx, nextPageToken, onPage)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamMessages indicates an expected call of StreamMessages.
+func (mr *MockLiveChatStreamerMockRecorder) StreamMessages(ctx, nextPageToken, onPage any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamMessages", reflect.TypeOf((*MockLiveChatStreamer)(nil).StreamMessages), ctx, nextPageToken, onPage)
+}
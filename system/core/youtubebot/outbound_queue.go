@@ -0,0 +1,66 @@
+package youtubebot
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"google.golang.org/api/googleapi"
+
+	"app.modules/core/chat"
+)
+
+// QueuedLiveChatBot PostMessageをchat.OutboundQueueに積んで返し、Runのgoroutineから送信するLiveChatBot。
+// 短い返信は MaxLiveChatMessageLength 文字に収まる範囲で1つにまとめ、送信の間隔は SetMinInterval で制限する。
+// PostMessage以外はそのまま内側のLiveChatBotを呼ぶ。
+type QueuedLiveChatBot struct {
+	LiveChatBot
+	queue *chat.OutboundQueue
+}
+
+func NewQueuedLiveChatBot(bot LiveChatBot, notify func(ctx context.Context, message string, err error)) *QueuedLiveChatBot {
+	return &QueuedLiveChatBot{
+		LiveChatBot: bot,
+		queue:       chat.NewOutboundQueue(bot, MaxLiveChatMessageLength, isTransientPostError, notify),
+	}
+}
+
+// SetMinInterval 送信の最小の間隔を設定する。動作中に変更してよい。
+func (q *QueuedLiveChatBot) SetMinInterval(interval time.Duration) {
+	q.queue.SetMinInterval(interval)
+}
+
+// PostMessage messageをキューに積む。送信の結果は待たない。
+func (q *QueuedLiveChatBot) PostMessage(ctx context.Context, message string) error {
+	return q.queue.PostMessage(ctx, message)
+}
+
+// StreamMessages 内側のLiveChatBotがストリーミングに対応していれば、それに任せる。
+func (q *QueuedLiveChatBot) StreamMessages(ctx context.Context, nextPageToken string, onPage func(LiveChatPage) error) error {
+	streamer, ok := q.LiveChatBot.(LiveChatStreamer)
+	if !ok {
+		return ErrStreamingUnavailable
+	}
+	return streamer.StreamMessages(ctx, nextPageToken, onPage)
+}
+
+// Run キューに積まれたメッセージを送信し続ける。Shutdownが呼ばれると残りを送ってから返る。
+func (q *QueuedLiveChatBot) Run(ctx context.Context) {
+	q.queue.Run(ctx)
+}
+
+// Shutdown 以後のPostMessageは直接送るようにし、キューに残っているメッセージをctxの期限まで送る。
+func (q *QueuedLiveChatBot) Shutdown(ctx context.Context) {
+	q.queue.Shutdown(ctx)
+}
+
+// isTransientPostError サーバー側の一時的なエラーや通信エラーならtrue
+func isTransientPostError(err error) bool {
+	var errGoogle *googleapi.Error
+	if errors.As(err, &errGoogle) {
+		return errGoogle.Code == 429 || errGoogle.Code >= 500
+	}
+	var errNet net.Error
+	return errors.As(err, &errNet)
+}
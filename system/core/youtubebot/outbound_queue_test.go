@@ -0,0 +1,50 @@
+package youtubebot
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/googleapi"
+)
+
+// recordingPostBot 投稿を記録する
+type recordingPostBot struct {
+	fakeStreamingBot
+
+	mu    sync.Mutex
+	posts []string
+}
+
+func (b *recordingPostBot) PostMessage(_ context.Context, message string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.posts = append(b.posts, message)
+	return nil
+}
+
+func TestQueuedLiveChatBot_CoalescesUpToMaxLength(t *testing.T) {
+	bot := &recordingPostBot{}
+	queue := NewQueuedLiveChatBot(bot, func(context.Context, string, error) {})
+	ctx := context.Background()
+
+	long := strings.Repeat("あ", MaxLiveChatMessageLength-10)
+	for _, message := range []string{"@a さん、入室しました", "@b さん、退室しました", long} {
+		require.NoError(t, queue.PostMessage(ctx, message))
+	}
+	go queue.Run(ctx)
+	queue.Shutdown(ctx)
+
+	assert.Equal(t, []string{"@a さん、入室しました　@b さん、退室しました", long}, bot.posts)
+}
+
+func TestIsTransientPostError(t *testing.T) {
+	assert.True(t, isTransientPostError(&googleapi.Error{Code: 503}))
+	assert.True(t, isTransientPostError(&googleapi.Error{Code: 429}))
+	assert.False(t, isTransientPostError(&googleapi.Error{Code: 403}))
+	assert.False(t, isTransientPostError(errors.New("invalid")))
+}
@@ -0,0 +1,216 @@
+package youtubebot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+	_ "time/tzdata" // クォータの日付の計算に太平洋時間のタイムゾーン情報が必要
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"app.modules/core/repository"
+)
+
+// QuotaMethod クォータを消費するYouTube Data APIの呼び出し。値は使用量を保存するときのキーになる
+type QuotaMethod string
+
+const (
+	QuotaListMessages   QuotaMethod = "list-messages"   // liveChatMessages.list
+	QuotaStreamMessages QuotaMethod = "stream-messages" // liveChatMessages.streamList（接続ごと）
+	QuotaInsertMessage  QuotaMethod = "insert-message"  // liveChatMessages.insert
+	QuotaInsertBan      QuotaMethod = "insert-ban"      // liveChatBans.insert
+	QuotaDeleteBan      QuotaMethod = "delete-ban"      // liveChatBans.delete
+	QuotaListBroadcasts QuotaMethod = "list-broadcasts" // liveBroadcasts.list
+	QuotaListStreams    QuotaMethod = "list-streams"    // liveStreams.list
+)
+
+// QuotaMethods レポートに表示する順
+var QuotaMethods = []QuotaMethod{
+	QuotaListMessages, QuotaStreamMessages, QuotaInsertMessage, QuotaInsertBan, QuotaDeleteBan, QuotaListBroadcasts, QuotaListStreams,
+}
+
+// quotaCosts 1回の呼び出しで消費するユニット数。
+// https://developers.google.com/youtube/v3/determine_quota_cost による。streamListは公表されていないためlistと同じとみなす
+var quotaCosts = map[QuotaMethod]int{
+	QuotaListMessages:   5,
+	QuotaStreamMessages: 5,
+	QuotaInsertMessage:  50,
+	QuotaInsertBan:      50,
+	QuotaDeleteBan:      50,
+	QuotaListBroadcasts: 1,
+	QuotaListStreams:    1,
+}
+
+const (
+	quotaDateLayout = "2006-01-02"
+
+	// MaxThrottledPollingInterval 予算を使い切ったときのポーリングの間隔
+	MaxThrottledPollingInterval = 5 * time.Minute
+
+	// minElapsedForProjection 日付が変わった直後は見込みが大きく振れるため、少なくともこの時間が経ったものとして見込む
+	minElapsedForProjection = time.Hour
+)
+
+// quotaLocation YouTube Data APIのクォータは太平洋時間の0時にリセットされる
+var quotaLocation = func() *time.Location {
+	location, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		// time/tzdataを埋め込んでいるので失敗しない
+		panic(fmt.Errorf("in time.LoadLocation(): %w", err))
+	}
+	return location
+}()
+
+// QuotaCost methodの1回の呼び出しで消費するユニット数
+func QuotaCost(method QuotaMethod) int {
+	return quotaCosts[method]
+}
+
+// QuotaDate tを含むクォータの日付（太平洋時間）
+func QuotaDate(t time.Time) string {
+	return t.In(quotaLocation).Format(quotaDateLayout)
+}
+
+// QuotaUsage ある日のクォータの使用量
+type QuotaUsage struct {
+	Date           string
+	Units          int
+	Calls          map[QuotaMethod]int
+	ProjectedUnits int           // このままのペースで使った場合の1日の使用量
+	UntilReset     time.Duration // 次にクォータがリセットされるまでの時間
+}
+
+// QuotaMeter YouTube Data APIの呼び出しをメソッドごとに数え、Flushで日ごとの使用量として保存する。
+// 他のプロセス（Lambdaなど）の使用量もFlushのたびに読み直して合算する。nilのQuotaMeterは何も記録しない。
+type QuotaMeter struct {
+	repo repository.Repository
+
+	mu        sync.Mutex
+	unflushed map[string]map[QuotaMethod]int // 日付ごとの、まだ保存していない呼び出し回数
+	stored    repository.YoutubeAPIQuotaUsageDoc
+
+	nowFunc func() time.Time // テストの時刻注入用
+}
+
+func NewQuotaMeter(repo repository.Repository) *QuotaMeter {
+	return &QuotaMeter{
+		repo:      repo,
+		unflushed: make(map[string]map[QuotaMethod]int),
+		nowFunc:   time.Now,
+	}
+}
+
+// Record methodを1回呼び出したことを記録する。呼び出しが失敗してもクォータは消費されるので、結果によらず記録する
+func (m *QuotaMeter) Record(method QuotaMethod) {
+	if m == nil {
+		return
+	}
+	date := QuotaDate(m.nowFunc())
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.unflushed[date] == nil {
+		m.unflushed[date] = make(map[QuotaMethod]int)
+	}
+	m.unflushed[date][method]++
+}
+
+// Flush 記録した呼び出しを保存し、今日の使用量を読み直す。保存に失敗した分は次のFlushで再び保存を試みる
+func (m *QuotaMeter) Flush(ctx context.Context) error {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	unflushed := m.unflushed
+	m.unflushed = make(map[string]map[QuotaMethod]int)
+	m.mu.Unlock()
+
+	var errs []error
+	for date, calls := range unflushed {
+		units := 0
+		keyed := make(map[string]int, len(calls))
+		for method, n := range calls {
+			units += QuotaCost(method) * n
+			keyed[string(method)] = n
+		}
+		if err := m.repo.AddYoutubeAPIQuotaUsage(ctx, date, keyed, units); err != nil {
+			m.restore(date, calls)
+			errs = append(errs, fmt.Errorf("in AddYoutubeAPIQuotaUsage(%s): %w", date, err))
+		}
+	}
+
+	today := QuotaDate(m.nowFunc())
+	stored, err := m.repo.ReadYoutubeAPIQuotaUsage(ctx, today)
+	if status.Code(err) == codes.NotFound {
+		stored, err = repository.YoutubeAPIQuotaUsageDoc{Date: today}, nil
+	}
+	if err != nil {
+		errs = append(errs, fmt.Errorf("in ReadYoutubeAPIQuotaUsage(%s): %w", today, err))
+	} else {
+		m.mu.Lock()
+		m.stored = stored
+		m.mu.Unlock()
+	}
+	return errors.Join(errs...)
+}
+
+func (m *QuotaMeter) restore(date string, calls map[QuotaMethod]int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.unflushed[date] == nil {
+		m.unflushed[date] = make(map[QuotaMethod]int)
+	}
+	for method, n := range calls {
+		m.unflushed[date][method] += n
+	}
+}
+
+// Usage 今日の使用量。最後にFlushしたときに読んだ値に、まだ保存していない分を加える
+func (m *QuotaMeter) Usage() QuotaUsage {
+	if m == nil {
+		return quotaUsageAt(time.Now(), repository.YoutubeAPIQuotaUsageDoc{}, nil)
+	}
+	now := m.nowFunc()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return quotaUsageAt(now, m.stored, m.unflushed[QuotaDate(now)])
+}
+
+func quotaUsageAt(now time.Time, stored repository.YoutubeAPIQuotaUsageDoc, unflushed map[QuotaMethod]int) QuotaUsage {
+	now = now.In(quotaLocation)
+	date := now.Format(quotaDateLayout)
+	usage := QuotaUsage{Date: date, Calls: make(map[QuotaMethod]int)}
+	if stored.Date == date {
+		usage.Units = stored.Units
+		for method, n := range stored.Calls {
+			usage.Calls[QuotaMethod(method)] = n
+		}
+	}
+	for method, n := range unflushed {
+		usage.Units += QuotaCost(method) * n
+		usage.Calls[method] += n
+	}
+
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, quotaLocation)
+	endOfDay := startOfDay.AddDate(0, 0, 1) // 夏時間の切り替えがあっても次の0時になる
+	elapsed := max(now.Sub(startOfDay), minElapsedForProjection)
+	usage.ProjectedUnits = int(float64(usage.Units) * float64(endOfDay.Sub(startOfDay)) / float64(elapsed))
+	usage.UntilReset = endOfDay.Sub(now)
+	return usage
+}
+
+// ThrottledPollingInterval 見込みの使用量がbudgetを超える場合に、今日の残りの予算をリセットまでのポーリングに
+// 均等に割り当てたときの間隔を返す。超えない場合やbudgetが0以下の場合は制限しないので0を返す。
+// 予算を使い切った場合は MaxThrottledPollingInterval を返す
+func (u QuotaUsage) ThrottledPollingInterval(budget int) time.Duration {
+	if budget <= 0 || u.ProjectedUnits <= budget {
+		return 0
+	}
+	remainingCalls := (budget - u.Units) / QuotaCost(QuotaListMessages)
+	if remainingCalls <= 0 {
+		return MaxThrottledPollingInterval
+	}
+	return min(u.UntilReset/time.Duration(remainingCalls), MaxThrottledPollingInterval)
+}
@@ -0,0 +1,103 @@
+package youtubebot
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app.modules/core/repository"
+)
+
+// failingQuotaRepository AddYoutubeAPIQuotaUsageがfailの間だけ失敗する
+type failingQuotaRepository struct {
+	*repository.InMemoryRepository
+	fail bool
+}
+
+func (r *failingQuotaRepository) AddYoutubeAPIQuotaUsage(ctx context.Context, date string, calls map[string]int, units int) error {
+	if r.fail {
+		return errors.New("unavailable")
+	}
+	return r.InMemoryRepository.AddYoutubeAPIQuotaUsage(ctx, date, calls, units)
+}
+
+func newTestQuotaMeter(repo repository.Repository, now *time.Time) *QuotaMeter {
+	meter := NewQuotaMeter(repo)
+	meter.nowFunc = func() time.Time { return *now }
+	return meter
+}
+
+func TestQuotaDate(t *testing.T) {
+	// 太平洋時間の0時（夏時間なのでUTC 7時）で日付が変わる
+	assert.Equal(t, "2026-08-01", QuotaDate(time.Date(2026, 8, 2, 6, 59, 0, 0, time.UTC)))
+	assert.Equal(t, "2026-08-02", QuotaDate(time.Date(2026, 8, 2, 7, 0, 0, 0, time.UTC)))
+	// 冬はUTC 8時
+	assert.Equal(t, "2026-12-01", QuotaDate(time.Date(2026, 12, 2, 7, 59, 0, 0, time.UTC)))
+}
+
+func TestQuotaMeter_RecordAndFlush(t *testing.T) {
+	ctx := context.Background()
+	repo := &failingQuotaRepository{InMemoryRepository: repository.NewInMemoryRepository()}
+	now := time.Date(2026, 8, 2, 19, 0, 0, 0, time.UTC) // 太平洋時間 8/2 12:00
+	meter := newTestQuotaMeter(repo, &now)
+
+	// 他のプロセスが保存した使用量もFlushで読み込む
+	require.NoError(t, repo.InMemoryRepository.AddYoutubeAPIQuotaUsage(ctx, "2026-08-02", map[string]int{"list-broadcasts": 3}, 3))
+
+	meter.Record(QuotaListMessages)
+	meter.Record(QuotaListMessages)
+	meter.Record(QuotaInsertMessage)
+	usage := meter.Usage()
+	assert.Equal(t, 60, usage.Units, "unflushed calls are counted before flush")
+	assert.Equal(t, 120, usage.ProjectedUnits, "half of the day has passed")
+	assert.Equal(t, 12*time.Hour, usage.UntilReset)
+
+	require.NoError(t, meter.Flush(ctx))
+	usage = meter.Usage()
+	assert.Equal(t, 63, usage.Units)
+	assert.Equal(t, map[QuotaMethod]int{QuotaListMessages: 2, QuotaInsertMessage: 1, QuotaListBroadcasts: 3}, usage.Calls)
+
+	// 保存に失敗した分は次のFlushで保存する
+	repo.fail = true
+	meter.Record(QuotaInsertBan)
+	assert.Error(t, meter.Flush(ctx))
+	assert.Equal(t, 113, meter.Usage().Units)
+	repo.fail = false
+	require.NoError(t, meter.Flush(ctx))
+	stored, err := repo.ReadYoutubeAPIQuotaUsage(ctx, "2026-08-02")
+	require.NoError(t, err)
+	assert.Equal(t, 113, stored.Units)
+
+	// 日付が変わる前の呼び出しは前日の使用量として保存する
+	meter.Record(QuotaListMessages)
+	now = now.Add(12 * time.Hour)
+	meter.Record(QuotaListMessages)
+	assert.Equal(t, 5, meter.Usage().Units, "yesterday's usage is not counted")
+	require.NoError(t, meter.Flush(ctx))
+	stored, err = repo.ReadYoutubeAPIQuotaUsage(ctx, "2026-08-02")
+	require.NoError(t, err)
+	assert.Equal(t, 118, stored.Units)
+	assert.Equal(t, 5, meter.Usage().Units)
+}
+
+func TestQuotaMeter_Nil(t *testing.T) {
+	var meter *QuotaMeter
+	meter.Record(QuotaListMessages)
+	assert.NoError(t, meter.Flush(context.Background()))
+	assert.Zero(t, meter.Usage().Units)
+}
+
+func TestQuotaUsage_ThrottledPollingInterval(t *testing.T) {
+	usage := QuotaUsage{Units: 5000, ProjectedUnits: 12000, UntilReset: 10 * time.Hour}
+
+	assert.Zero(t, usage.ThrottledPollingInterval(0), "no budget")
+	assert.Zero(t, usage.ThrottledPollingInterval(20000), "projected usage is within the budget")
+	// 残り5000 unitsはlist 1000回分なので、10時間に1000回
+	assert.Equal(t, 36*time.Second, usage.ThrottledPollingInterval(10000))
+	assert.Equal(t, MaxThrottledPollingInterval, usage.ThrottledPollingInterval(5000), "budget is exhausted")
+	assert.Equal(t, MaxThrottledPollingInterval, usage.ThrottledPollingInterval(5050), "too few calls left")
+}
@@ -0,0 +1,233 @@
+package youtubebot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/kr/pretty"
+
+	"app.modules/core/repository"
+)
+
+// errNotRewardedFanFundingEvent 特典の対象でない支援のイベントをownerに知らせるときのエラー
+var errNotRewardedFanFundingEvent = errors.New("fan funding event is not eligible for rewards")
+
+const (
+	// MinimumTryTimesToNotify 連続して何回失敗したらownerに通知するか
+	MinimumTryTimesToNotify = 2
+
+	MaxRetryIntervalSeconds      = 300
+	RetryIntervalCalculationBase = 1.2
+
+	// streamRetryIntervalAfterFallback ポーリングに切り替えた後、再びストリーミングを試すまでの間隔
+	streamRetryIntervalAfterFallback = 30 * time.Minute
+)
+
+// LiveChatReceiver チャットを受信し、ChatPageにしてチャネルに流す。
+// botがLiveChatStreamerならstreamListで受信し、使えない場合はListMessagesのポーリングに切り替える。
+// 受信したページのNextPageTokenは、チャネルに流したページのDoneが呼ばれてからUpdateNextPageTokenで保存し、再接続はその続きから行う。
+// 処理の途中で止まった場合は、次の起動時に同じページを受信し直す。
+type LiveChatReceiver struct {
+	bot  LiveChatBot
+	repo repository.Repository
+	// notify 失敗が続いたときにownerへ知らせる
+	notify func(ctx context.Context, message string, err error)
+
+	minPollingIntervalMilli atomic.Int64
+
+	retryInterval func(numContinuousFailed int) time.Duration
+}
+
+func NewLiveChatReceiver(bot LiveChatBot, repo repository.Repository,
+	notify func(ctx context.Context, message string, err error),
+) *LiveChatReceiver {
+	return &LiveChatReceiver{
+		bot:           bot,
+		repo:          repo,
+		notify:        notify,
+		retryInterval: calculateRetryInterval,
+	}
+}
+
+// SetMinPollingInterval ポーリングするときの最小の間隔を設定する。受信中に変更してよい。
+func (r *LiveChatReceiver) SetMinPollingInterval(interval time.Duration) {
+	r.minPollingIntervalMilli.Store(interval.Milliseconds())
+}
+
+// Run ctxがキャンセルされるまでチャットを受信してoutに送る。テキストのメッセージも支援のイベントもないページは送らない。
+func (r *LiveChatReceiver) Run(ctx context.Context, out chan<- ChatPage) error {
+	pageToken, err := r.loadPageToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	streamer, streamable := r.bot.(LiveChatStreamer)
+	var streamRetryAt time.Time
+	numContinuousFailed := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if streamable && !time.Now().Before(streamRetryAt) {
+			received := false
+			err := streamer.StreamMessages(ctx, pageToken, func(page LiveChatPage) error {
+				received = true
+				numContinuousFailed = 0
+				return r.deliver(ctx, &pageToken, page, out)
+			})
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if errors.Is(err, ErrStreamingUnavailable) {
+				slog.Warn("live chat streaming is unavailable; falling back to polling", "err", err)
+				streamRetryAt = time.Now().Add(streamRetryIntervalAfterFallback)
+				continue
+			}
+			if err == nil && received {
+				// サーバーが閉じただけなので、すぐに続きから再接続する
+				continue
+			}
+			if err == nil {
+				err = errors.New("live chat stream closed without any response")
+			}
+			numContinuousFailed++
+			r.notifyFailure(ctx, numContinuousFailed, "failed to stream chat messages", err)
+			if err := sleepContext(ctx, r.retryInterval(numContinuousFailed)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		fetchedAt := time.Now()
+		messages, nextPageToken, pollingIntervalMillis, err := r.bot.ListMessages(ctx, pageToken)
+		if err != nil {
+			numContinuousFailed++
+			r.notifyFailure(ctx, numContinuousFailed, "failed to retrieve chat messages", err)
+			if err := sleepContext(ctx, r.retryInterval(numContinuousFailed)); err != nil {
+				return err
+			}
+			continue
+		}
+		numContinuousFailed = 0
+		if err := r.deliver(ctx, &pageToken, LiveChatPage{Messages: messages, NextPageToken: nextPageToken}, out); err != nil {
+			return err
+		}
+
+		interval := max(time.Duration(pollingIntervalMillis)*time.Millisecond,
+			time.Duration(r.minPollingIntervalMilli.Load())*time.Millisecond)
+		wait := max(interval-time.Since(fetchedAt), 0)
+		slog.Info(fmt.Sprintf("waiting for %.2f seconds...\n\n", wait.Seconds()))
+		if err := sleepContext(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// loadPageToken 保存されているpage tokenを読む。読めるまで間隔を空けて再試行する
+func (r *LiveChatReceiver) loadPageToken(ctx context.Context) (string, error) {
+	for numContinuousFailed := 1; ; numContinuousFailed++ {
+		pageToken, err := r.repo.ReadNextPageToken(ctx, nil)
+		if err == nil {
+			return pageToken, nil
+		}
+		r.notifyFailure(ctx, numContinuousFailed, "failed to retrieve next page token", err)
+		if err := sleepContext(ctx, r.retryInterval(numContinuousFailed)); err != nil {
+			return "", err
+		}
+	}
+}
+
+// deliver ページをChatPageにしてoutに送り、処理し終えてからpage tokenを保存する。保存に失敗しても受信は止めない
+func (r *LiveChatReceiver) deliver(ctx context.Context, pageToken *string, page LiveChatPage, out chan<- ChatPage) error {
+	chatPage := r.toChatPage(ctx, page)
+	if len(chatPage.Messages) > 0 || len(chatPage.FanFundingEvents) > 0 {
+		chatPage.processed = make(chan struct{}, 1)
+		select {
+		case out <- chatPage:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		// 処理し終える前に止まった場合はトークンを保存せず、次の起動時に受信し直す
+		select {
+		case <-chatPage.processed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	// 空のトークンで続けると最近のチャットを再取得してしまうため、前のトークンを使い続ける
+	if page.NextPageToken == "" || page.NextPageToken == *pageToken {
+		return nil
+	}
+	if err := r.repo.UpdateNextPageToken(ctx, page.NextPageToken); err != nil {
+		r.notify(ctx, "(1回目) failed to save next page token", err)
+		// 少し待ってから再試行
+		if err := sleepContext(ctx, 3*time.Second); err != nil {
+			return err
+		}
+		if err2 := r.repo.UpdateNextPageToken(ctx, page.NextPageToken); err2 != nil {
+			r.notify(ctx, "(2回目) failed to save next page token", err2)
+			// pass
+		}
+	}
+	*pageToken = page.NextPageToken
+	return nil
+}
+
+// toChatPage 受信したチャットを、投稿者によるテキストのメッセージと特典の対象になる支援のイベントに分ける。
+// 対象でない支援のイベントや解析できなかったイベントはownerに知らせる
+func (r *LiveChatReceiver) toChatPage(ctx context.Context, page LiveChatPage) ChatPage {
+	var chatPage ChatPage
+	for _, liveChatMessage := range page.Messages {
+		if liveChatMessage.Snippet == nil || liveChatMessage.AuthorDetails == nil {
+			continue
+		}
+		if IsFanFundingEvent(liveChatMessage) {
+			event, ok, err := ParseFanFundingEvent(liveChatMessage)
+			if err != nil {
+				r.notify(ctx, "failed to ParseFanFundingEvent", err)
+			} else if ok {
+				chatPage.FanFundingEvents = append(chatPage.FanFundingEvents, event)
+			} else {
+				r.notify(ctx, fmt.Sprintf("Fan funding event:\n```%# v```", pretty.Formatter(liveChatMessage)), errNotRewardedFanFundingEvent)
+			}
+		}
+		// スーパーチャットなどのコメントもコマンドとして扱う
+		if message, ok := toChatMessage(liveChatMessage); ok {
+			chatPage.Messages = append(chatPage.Messages, message)
+		}
+	}
+	return chatPage
+}
+
+func (r *LiveChatReceiver) notifyFailure(ctx context.Context, numContinuousFailed int, message string, err error) {
+	slog.Error(message, "err", err, "numContinuousFailed", numContinuousFailed)
+	if numContinuousFailed >= MinimumTryTimesToNotify {
+		r.notify(ctx, "（"+strconv.Itoa(numContinuousFailed)+"回目） "+message, err)
+	}
+}
+
+func CalculateRetryIntervalSec(base float64, numContinuousFailed int) float64 {
+	return math.Min(MaxRetryIntervalSeconds, math.Pow(base, float64(numContinuousFailed)))
+}
+
+func calculateRetryInterval(numContinuousFailed int) time.Duration {
+	return time.Duration(CalculateRetryIntervalSec(RetryIntervalCalculationBase, numContinuousFailed) * float64(time.Second))
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
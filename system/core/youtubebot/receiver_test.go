@@ -0,0 +1,298 @@
+package youtubebot
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/youtube/v3"
+
+	"app.modules/core/repository"
+)
+
+func TestCalculateRetryIntervalSec(t *testing.T) {
+	tests := []struct {
+		name                string
+		numContinuousFailed int
+		want                float64
+	}{
+		{
+			name:                "zero_failures",
+			numContinuousFailed: 0,
+			want:                1,
+		},
+		{
+			name:                "one_failure",
+			numContinuousFailed: 1,
+			want:                1.2,
+		},
+		{
+			name:                "two_failures",
+			numContinuousFailed: 2,
+			want:                1.44,
+		},
+		{
+			name:                "three_failures",
+			numContinuousFailed: 3,
+			want:                1.728,
+		},
+		{
+			name:                "four_failures",
+			numContinuousFailed: 4,
+			want:                2.0736,
+		},
+		{
+			name:                "five_failures",
+			numContinuousFailed: 5,
+			want:                2.48832,
+		},
+		{
+			name:                "ten_failures",
+			numContinuousFailed: 10,
+			want:                6.191736422,
+		},
+		{
+			name:                "twenty_failures",
+			numContinuousFailed: 20,
+			want:                38.337599924474700,
+		},
+		{ // 単純に計算すると300を超えるが、最大値は300
+			name:                "caps_at_300_seconds",
+			numContinuousFailed: 50,
+			want:                300,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDeltaf(
+				t,
+				tt.want,
+				CalculateRetryIntervalSec(RetryIntervalCalculationBase, tt.numContinuousFailed),
+				0.1,
+				"CalculateRetryIntervalSec(%v)",
+				tt.numContinuousFailed,
+			)
+		})
+	}
+}
+
+// fakeStreamingBot streamsに書いた順にStreamMessagesの結果を返し、使い切ったらストリーミングできないことにする
+type fakeStreamingBot struct {
+	streams      []func(onPage func(LiveChatPage) error) error
+	streamTokens []string
+	listTokens   []string
+	list         func(call int) ([]*youtube.LiveChatMessage, string, int, error)
+}
+
+func (b *fakeStreamingBot) StreamMessages(_ context.Context, nextPageToken string, onPage func(LiveChatPage) error) error {
+	b.streamTokens = append(b.streamTokens, nextPageToken)
+	if len(b.streams) == 0 {
+		return ErrStreamingUnavailable
+	}
+	stream := b.streams[0]
+	b.streams = b.streams[1:]
+	return stream(onPage)
+}
+
+func (b *fakeStreamingBot) ListMessages(_ context.Context, nextPageToken string) ([]*youtube.LiveChatMessage, string, int, error) {
+	b.listTokens = append(b.listTokens, nextPageToken)
+	return b.list(len(b.listTokens))
+}
+
+func (b *fakeStreamingBot) PostMessage(context.Context, string) error { return nil }
+
+func (b *fakeStreamingBot) BanUser(context.Context, string) (string, error) { return "", nil }
+
+func (b *fakeStreamingBot) TimeOut(context.Context, string, time.Duration) (string, error) {
+	return "", nil
+}
+
+func (b *fakeStreamingBot) UnbanUser(context.Context, string) error { return nil }
+
+func chatMessage(id string) *youtube.LiveChatMessage {
+	return &youtube.LiveChatMessage{
+		Id: id,
+		Snippet: &youtube.LiveChatMessageSnippet{
+			Type:               TextMessageEvent,
+			TextMessageDetails: &youtube.LiveChatTextMessageDetails{MessageText: "!in"},
+		},
+		AuthorDetails: &youtube.LiveChatMessageAuthorDetails{ChannelId: "UCxxxx"},
+	}
+}
+
+func newTestReceiver(t *testing.T, bot LiveChatBot) (*LiveChatReceiver, *repository.InMemoryRepository, *[]string) {
+	repo := repository.NewInMemoryRepository()
+	require.NoError(t, repo.SetCredentialsConfig(repository.CredentialsConfigDoc{YoutubeLiveChatNextPageToken: "saved"}))
+	var notified []string
+	receiver := NewLiveChatReceiver(bot, repo, func(_ context.Context, message string, _ error) {
+		notified = append(notified, message)
+	})
+	receiver.retryInterval = func(int) time.Duration { return time.Millisecond }
+	return receiver, repo, &notified
+}
+
+func TestLiveChatReceiver_ReconnectsAndFallsBackToPolling(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bot := &fakeStreamingBot{
+		streams: []func(onPage func(LiveChatPage) error) error{
+			func(onPage func(LiveChatPage) error) error {
+				if err := onPage(LiveChatPage{Messages: []*youtube.LiveChatMessage{chatMessage("m1")}, NextPageToken: "t1"}); err != nil {
+					return err
+				}
+				return errors.New("connection reset")
+			},
+			func(onPage func(LiveChatPage) error) error {
+				// メッセージのないレスポンスはトークンだけ保存して流さない
+				if err := onPage(LiveChatPage{NextPageToken: "t2"}); err != nil {
+					return err
+				}
+				return onPage(LiveChatPage{Messages: []*youtube.LiveChatMessage{chatMessage("m2")}, NextPageToken: "t3"})
+			},
+		},
+		list: func(call int) ([]*youtube.LiveChatMessage, string, int, error) {
+			if call == 1 {
+				return []*youtube.LiveChatMessage{chatMessage("m3")}, "t4", 0, nil
+			}
+			cancel()
+			return nil, "", 0, nil
+		},
+	}
+	receiver, repo, notified := newTestReceiver(t, bot)
+
+	pages := make(chan ChatPage)
+	processed := make(chan []string)
+	go func() {
+		var ids []string
+		for page := range pages {
+			for _, message := range page.Messages {
+				ids = append(ids, message.ID)
+			}
+			page.Done()
+		}
+		processed <- ids
+	}()
+	err := receiver.Run(ctx, pages)
+	require.ErrorIs(t, err, context.Canceled)
+	close(pages)
+
+	ids := <-processed
+	assert.Equal(t, []string{"m1", "m2", "m3"}, ids)
+	assert.Equal(t, []string{"saved", "t1", "t3"}, bot.streamTokens, "reconnects from the last received page token")
+	assert.Equal(t, []string{"t3", "t4"}, bot.listTokens, "an empty next page token must not reset the position")
+	assert.Empty(t, *notified, "a single failure is not notified")
+
+	pageToken, err := repo.ReadNextPageToken(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "t4", pageToken)
+}
+
+func TestLiveChatReceiver_SavesPageTokenAfterProcessing(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bot := &fakeStreamingBot{
+		list: func(call int) ([]*youtube.LiveChatMessage, string, int, error) {
+			if call == 1 {
+				return []*youtube.LiveChatMessage{chatMessage("m1")}, "t1", 0, nil
+			}
+			cancel()
+			return nil, "", 0, nil
+		},
+	}
+	receiver, repo, _ := newTestReceiver(t, bot)
+
+	pages := make(chan ChatPage)
+	stopped := make(chan error, 1)
+	go func() { stopped <- receiver.Run(ctx, pages) }()
+
+	page := <-pages
+	pageToken, err := repo.ReadNextPageToken(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "saved", pageToken, "the page token must not be saved while the page is being processed")
+
+	page.Done()
+	require.ErrorIs(t, <-stopped, context.Canceled)
+	pageToken, err = repo.ReadNextPageToken(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "t1", pageToken)
+}
+
+func TestLiveChatReceiver_DoesNotSavePageTokenOfUnprocessedPage(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bot := &fakeStreamingBot{
+		list: func(int) ([]*youtube.LiveChatMessage, string, int, error) {
+			return []*youtube.LiveChatMessage{chatMessage("m1")}, "t1", 0, nil
+		},
+	}
+	receiver, repo, _ := newTestReceiver(t, bot)
+
+	pages := make(chan ChatPage)
+	stopped := make(chan error, 1)
+	go func() { stopped <- receiver.Run(ctx, pages) }()
+
+	<-pages
+	cancel() // 処理中に停止した
+	require.ErrorIs(t, <-stopped, context.Canceled)
+	pageToken, err := repo.ReadNextPageToken(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "saved", pageToken, "the page is received again on the next start")
+}
+
+func TestLiveChatReceiver_NotifiesContinuousFailures(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	failure := func(func(LiveChatPage) error) error { return errors.New("unavailable") }
+	bot := &fakeStreamingBot{
+		streams: []func(onPage func(LiveChatPage) error) error{failure, failure, failure},
+		list: func(int) ([]*youtube.LiveChatMessage, string, int, error) {
+			cancel()
+			return nil, "", 0, nil
+		},
+	}
+	receiver, _, notified := newTestReceiver(t, bot)
+
+	err := receiver.Run(ctx, make(chan ChatPage))
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, []string{"（2回目） failed to stream chat messages", "（3回目） failed to stream chat messages"}, *notified)
+}
+
+func TestLiveChatReceiver_ToChatPage(t *testing.T) {
+	receiver, _, notified := newTestReceiver(t, &fakeStreamingBot{})
+	author := &youtube.LiveChatMessageAuthorDetails{ChannelId: "UCxxxx", DisplayName: "@user"}
+
+	page := receiver.toChatPage(context.Background(), LiveChatPage{Messages: []*youtube.LiveChatMessage{
+		chatMessage("text"),
+		{
+			Id: "super-chat",
+			Snippet: &youtube.LiveChatMessageSnippet{
+				Type:             SuperChatEvent,
+				PublishedAt:      "2026-01-01T01:00:00Z",
+				SuperChatDetails: &youtube.LiveChatSuperChatDetails{AmountMicros: 500000000, Currency: "JPY", UserComment: "!rank"},
+			},
+			AuthorDetails: author,
+		},
+		{
+			Id:            "milestone",
+			Snippet:       &youtube.LiveChatMessageSnippet{Type: MemberMilestoneChatEvent},
+			AuthorDetails: author,
+		},
+	}})
+
+	var ids []string
+	for _, message := range page.Messages {
+		ids = append(ids, message.ID)
+	}
+	assert.Equal(t, []string{"text", "super-chat"}, ids, "the comment of a super chat is also a command")
+	require.Len(t, page.FanFundingEvents, 1)
+	assert.Equal(t, "super-chat", page.FanFundingEvents[0].MessageID)
+	require.Len(t, *notified, 1, "a fan funding event without rewards is notified")
+	assert.Contains(t, (*notified)[0], "Fan funding event")
+}
@@ -0,0 +1,39 @@
+package youtubebot
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang.org/x/oauth2"
+	"google.golang.org/api/option"
+	"google.golang.org/api/youtube/v3"
+)
+
+// APIEndpointEnv YouTube Data APIの接続先を差し替える環境変数。ローカルのシミュレーターに向けて動かすときに設定する
+const APIEndpointEnv = "YOUTUBE_API_ENDPOINT"
+
+// NewYoutubeService endpointが空ならtokenSourceで認証して本物のYouTube Data APIに接続する。
+// 空でなければ認証せずにendpoint（例: http://localhost:8089/）に接続する。
+// streamListのように生成済みのクライアントにないAPIを呼ぶため、同じ接続先・認証のHTTPクライアントも返す。
+func NewYoutubeService(ctx context.Context, endpoint string, tokenSource oauth2.TokenSource) (*youtube.Service, *http.Client, error) {
+	if endpoint == "" {
+		client := oauth2.NewClient(ctx, tokenSource)
+		service, err := youtube.NewService(ctx, option.WithHTTPClient(client))
+		if err != nil {
+			return nil, nil, fmt.Errorf("in youtube.NewService(): %w", err)
+		}
+		return service, client, nil
+	}
+
+	if !strings.HasSuffix(endpoint, "/") {
+		endpoint += "/"
+	}
+	client := &http.Client{}
+	service, err := youtube.NewService(ctx, option.WithEndpoint(endpoint), option.WithHTTPClient(client))
+	if err != nil {
+		return nil, nil, fmt.Errorf("in youtube.NewService(): %w", err)
+	}
+	return service, client, nil
+}
@@ -2,21 +2,66 @@ package youtubebot
 
 import (
 	"context"
+	"net/http"
+	"sync"
+	"time"
 
 	"google.golang.org/api/youtube/v3"
 
+	"app.modules/core/chat"
 	"app.modules/core/repository"
 )
 
 type LiveChatBot interface {
 	ListMessages(ctx context.Context, nextPageToken string) ([]*youtube.LiveChatMessage, string, int, error)
 	PostMessage(ctx context.Context, message string) error
-	BanUser(ctx context.Context, userID string) error
+	// BanUser userIDのユーザーを無期限でブロックし、UnbanUserで解除するときに使うブロックのIDを返す
+	BanUser(ctx context.Context, userID string) (string, error)
+	// TimeOut userIDのユーザーをdurationの間だけブロックし、ブロックのIDを返す
+	TimeOut(ctx context.Context, userID string, duration time.Duration) (string, error)
+	UnbanUser(ctx context.Context, banID string) error
+}
+
+// LiveChatStreamer liveChatMessages.streamListでチャットを受信できるLiveChatBot。
+type LiveChatStreamer interface {
+	// StreamMessages nextPageTokenの続きからチャットを受信し、レスポンスを受け取るたびにonPageを呼ぶ。
+	// サーバーがストリームを閉じるとnilを返す。streamListが使えない場合はErrStreamingUnavailableを返す。
+	StreamMessages(ctx context.Context, nextPageToken string, onPage func(LiveChatPage) error) error
+}
+
+// LiveChatPage ストリームの1レスポンス、またはポーリング1回分で受信したチャット
+type LiveChatPage struct {
+	Messages      []*youtube.LiveChatMessage
+	NextPageToken string
+}
+
+// ChatPage LiveChatReceiverが受信した1ページ分のチャットを、配信サービスに依存しない形にしたもの
+type ChatPage struct {
+	Messages         []chat.ChatMessage // 投稿者によるテキストのメッセージ
+	FanFundingEvents []FanFundingEvent
+
+	processed chan struct{}
+}
+
+// Done ページを処理し終えたことをLiveChatReceiverに知らせる。LiveChatReceiverはこれを待ってからpage tokenを保存する
+func (p ChatPage) Done() {
+	if p.processed == nil {
+		return
+	}
+	select {
+	case p.processed <- struct{}{}:
+	default: // 2回目以降は何もしない
+	}
 }
 
 type YoutubeLiveChatBot struct {
 	LiveChatID            string
 	ChannelYoutubeService *youtube.Service
 	BotYoutubeService     *youtube.Service
+	BotHTTPClient         *http.Client // streamListの呼び出しに使う。BotYoutubeServiceと同じ認証情報
 	FirestoreController   repository.Repository
+	QuotaMeter            *QuotaMeter // nilなら使用量を記録しない
+
+	// 受信とメッセージ送信が別のgoroutineから呼ばれるため、LiveChatIDの読み書きを保護する
+	liveChatIDMu sync.RWMutex
 }
@@ -2,8 +2,11 @@ package youtubebot
 
 import (
 	"strings"
+	"time"
 
 	"google.golang.org/api/youtube/v3"
+
+	"app.modules/core/chat"
 )
 
 const (
@@ -76,3 +79,30 @@ func ExtractAuthorDisplayName(chat *youtube.LiveChatMessage) string {
 func ExtractAuthorProfileImageURL(chat *youtube.LiveChatMessage) string {
 	return chat.AuthorDetails.ProfileImageUrl
 }
+
+// toChatMessage 投稿者によるテキストのメッセージを、配信サービスに依存しないChatMessageにする。
+// テキストのメッセージがなければfalseを返す。
+func toChatMessage(chatMessage *youtube.LiveChatMessage) (chat.ChatMessage, bool) {
+	if !HasTextMessageByAuthor(chatMessage) {
+		return chat.ChatMessage{}, false
+	}
+	// example of publishedAt: "2021-11-13T07:21:30.486982+00:00"。解析できなければゼロ値のままにする
+	publishedAt, _ := time.Parse(time.RFC3339Nano, chatMessage.Snippet.PublishedAt)
+	isOwner := IsChatMessageByOwner(chatMessage)
+	return chat.ChatMessage{
+		ID:       chat.ID(chat.YouTube, chatMessage.Id),
+		Platform: chat.YouTube,
+		ChatID:   chatMessage.Snippet.LiveChatId,
+		Type:     chatMessage.Snippet.Type,
+		Author: chat.Author{
+			ID:              chat.ID(chat.YouTube, ExtractAuthorChannelID(chatMessage)),
+			DisplayName:     ExtractAuthorDisplayName(chatMessage),
+			ProfileImageURL: ExtractAuthorProfileImageURL(chatMessage),
+			IsModerator:     IsChatMessageByModerator(chatMessage),
+			IsOwner:         isOwner,
+			IsMember:        isOwner || IsChatMessageByMember(chatMessage),
+		},
+		Text:        ExtractTextMessageByAuthor(chatMessage),
+		PublishedAt: publishedAt,
+	}, true
+}
@@ -2,10 +2,13 @@ package youtubebot
 
 import (
 	"testing"
+	"time"
 
 	"google.golang.org/api/youtube/v3"
 
 	"github.com/stretchr/testify/assert"
+
+	"app.modules/core/chat"
 )
 
 func TestExtractAuthorDisplayName(t *testing.T) {
@@ -59,3 +62,43 @@ func TestExtractAuthorDisplayName(t *testing.T) {
 		})
 	}
 }
+
+func TestToChatMessage(t *testing.T) {
+	message, ok := toChatMessage(&youtube.LiveChatMessage{
+		Id: "message-id",
+		Snippet: &youtube.LiveChatMessageSnippet{
+			LiveChatId:         "live-chat-id",
+			Type:               TextMessageEvent,
+			PublishedAt:        "2026-01-01T01:00:00Z",
+			TextMessageDetails: &youtube.LiveChatTextMessageDetails{MessageText: "!in"},
+		},
+		AuthorDetails: &youtube.LiveChatMessageAuthorDetails{
+			ChannelId:       "UCxxxx",
+			DisplayName:     "@user",
+			ProfileImageUrl: "https://example.com/user.png",
+			IsChatOwner:     true,
+		},
+	})
+	assert.True(t, ok)
+	assert.Equal(t, chat.ChatMessage{
+		ID:       "message-id",
+		Platform: chat.YouTube,
+		ChatID:   "live-chat-id",
+		Type:     TextMessageEvent,
+		Author: chat.Author{
+			ID:              "UCxxxx",
+			DisplayName:     "user",
+			ProfileImageURL: "https://example.com/user.png",
+			IsOwner:         true,
+			IsMember:        true,
+		},
+		Text:        "!in",
+		PublishedAt: time.Date(2026, time.January, 1, 1, 0, 0, 0, time.UTC),
+	}, message)
+
+	_, ok = toChatMessage(&youtube.LiveChatMessage{
+		Snippet:       &youtube.LiveChatMessageSnippet{Type: NewSponsorEvent},
+		AuthorDetails: &youtube.LiveChatMessageAuthorDetails{},
+	})
+	assert.False(t, ok)
+}
@@ -0,0 +1,60 @@
+package adminops
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"google.golang.org/api/option"
+
+	"app.modules/core/migrations"
+	"app.modules/core/repository"
+)
+
+// MigrateFirestoreSchema 未適用のスキーママイグレーションを適用する。
+// 先にdry-runで変更されるドキュメント数を表示し、dryRunでなければ確認の上で適用する。中断した場合は再実行すれば続きから再開する。
+func MigrateFirestoreSchema(ctx context.Context, clientOption option.ClientOption, dryRun bool) {
+	controller, err := repository.NewFirestoreController(ctx, clientOption)
+	if err != nil {
+		panic(err)
+	}
+	defer func() {
+		if err := controller.Close(); err != nil {
+			slog.Error("failed to close firestore client", "error", err)
+		}
+	}()
+
+	runner, err := migrations.NewRunner(controller.FirestoreClient(), migrations.All(), os.Stdout)
+	if err != nil {
+		panic(err)
+	}
+	pending, err := runner.Pending(ctx)
+	if err != nil {
+		panic(err)
+	}
+	if len(pending) == 0 {
+		slog.Info("no pending migrations.")
+		return
+	}
+
+	if err := runner.Run(ctx, true); err != nil {
+		panic(err)
+	}
+	if dryRun {
+		return
+	}
+
+	fmt.Printf("%d件のマイグレーションを適用します。よろしいですか？(yes / no)\n", len(pending))
+	var s string
+	if _, err := fmt.Scanln(&s); err != nil {
+		panic(err)
+	}
+	if s != "yes" {
+		return
+	}
+	if err := runner.Run(ctx, false); err != nil {
+		panic(err)
+	}
+	slog.Info("finished migrations.")
+}
@@ -0,0 +1,71 @@
+package adminops
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"google.golang.org/api/option"
+
+	"app.modules/core/timeutil"
+	"app.modules/core/twitchbot"
+	"app.modules/core/workspaceapp"
+)
+
+// ExportUserModerationHistoryJSON userIDのユーザーに対するキック・ブロック・NGワードによる自動ブロックの履歴を表示し、jsonファイルに書き出す。
+func ExportUserModerationHistoryJSON(ctx context.Context, userID string, clientOption option.ClientOption) {
+	app, err := workspaceapp.NewWorkspaceApp(ctx, true, clientOption)
+	if err != nil {
+		panic(err)
+	}
+
+	actions, err := app.Repository.ReadModerationActionsByTargetUserID(ctx, userID)
+	if err != nil {
+		panic(err)
+	}
+	for _, action := range actions {
+		fmt.Printf("%s\t%s\tactor: %s (%s)\tseat: %d (member: %t)\tregex: %q\tmessage: %q\tban: %s (timeout: %ds)\n",
+			action.TakenAt.In(timeutil.JapanLocation()).Format("2006-01-02 15:04:05"), action.ActionType,
+			action.ActorDisplayName, action.ActorUserID, action.SeatID, action.IsMemberSeat, action.MatchedRegex,
+			action.MessageText, action.BanID, action.TimeoutDurationSec)
+	}
+
+	dateString := timeutil.JstNow().Format("2006-01-02_15-04-05")
+	f, err := os.Create("./" + dateString + "_" + userID + "_moderation-history.json")
+	if err != nil {
+		panic(err)
+	}
+	defer func() {
+		if cerr := f.Close(); cerr != nil {
+			slog.Error("failed to close exported json file", "error", cerr)
+		}
+	}()
+
+	if err := json.NewEncoder(f).Encode(actions); err != nil {
+		panic(err)
+	}
+	slog.Info("finished exporting moderation history.", "userID", userID, "count", len(actions))
+}
+
+// UnbanUser userIDのユーザーに対する最新のブロック（!block、!timeout、NGワードによる自動ブロック）を解除する。
+func UnbanUser(ctx context.Context, userID string, clientOption option.ClientOption) {
+	app, err := workspaceapp.NewWorkspaceApp(ctx, true, clientOption)
+	if err != nil {
+		panic(err)
+	}
+
+	// Twitchのユーザーのブロックを解除するため。Helix APIのみ使うので接続はしない
+	if twitchConfig, ok := twitchbot.ConfigFromEnv(); ok {
+		app.AddChatSource(twitchbot.NewTwitchChat(twitchConfig, app.MessageToOwnerWithError))
+	}
+
+	app.MessageToOwner(ctx, "direct op: UnbanUser")
+
+	ban, err := app.UnbanUser(ctx, userID)
+	if err != nil {
+		panic(err)
+	}
+	slog.Info("finished unbanning user.", "userID", userID, "banID", ban.BanID, "actionType", ban.ActionType)
+}
@@ -2,19 +2,16 @@ package adminops
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log/slog"
 	"math"
-	"os"
 
 	"app.modules/core/workspaceapp"
 
-	"cloud.google.com/go/firestore"
 	"google.golang.org/api/option"
 
 	"app.modules/core/timeutil"
-	"app.modules/core/utils"
+	"app.modules/core/userexport"
 )
 
 func ExitAllUsersInRoom(ctx context.Context, clientOption option.ClientOption) {
@@ -60,45 +57,11 @@ func ExitSpecificUser(ctx context.Context, userID string, clientOption option.Cl
 	}
 }
 
+// ExportUsersCollectionJSON 全ユーザーの累計作業時間をJSON Linesで書き出す。
 func ExportUsersCollectionJSON(ctx context.Context, clientOption option.ClientOption) {
-	app, err := workspaceapp.NewWorkspaceApp(ctx, true, clientOption)
-	if err != nil {
-		panic(err)
-	}
-
-	app.MessageToOwner(ctx, "direct op: ExportUsersCollectionJSON")
-
-	var allUsersTotalStudySecList []utils.UserIDTotalStudySecSet
-	txErr := app.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
-		var err error
-		allUsersTotalStudySecList, err = app.GetAllUsersTotalStudySecList(ctx)
-		if err != nil {
-			panic(err)
-		}
-		return nil
-	})
-	if txErr != nil {
-		panic(txErr)
-	}
-
-	now := timeutil.JstNow()
-	dateString := now.Format("2006-01-02_15-04-05")
-	f, err := os.Create("./" + dateString + "_user-total-study-sec-list.json")
-	if err != nil {
-		panic(err)
-	}
-	defer func() {
-		if cerr := f.Close(); cerr != nil {
-			slog.Error("failed to close exported json file", "error", cerr)
-		}
-	}()
-
-	jsonEnc := json.NewEncoder(f)
-	// jsonEnc.SetIndent("", "\t")
-	if err := jsonEnc.Encode(allUsersTotalStudySecList); err != nil {
-		panic(err)
-	}
-	slog.Info("finished exporting json.")
+	dateString := timeutil.JstNow().Format("2006-01-02_15-04-05")
+	ExportUsers(ctx, clientOption, "./"+dateString+"_user-total-study-sec-list.jsonl",
+		string(userexport.FormatJSONLines), string(userexport.FieldTotalStudySec))
 }
 
 func UpdateUsersRP(ctx context.Context, clientOption option.ClientOption) {
@@ -0,0 +1,119 @@
+package adminops
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+
+	"google.golang.org/api/option"
+
+	"app.modules/core/userexport"
+	"app.modules/core/workspaceapp"
+)
+
+// ExportUsers 全ユーザーをoutputPathにJSON Lines（format: "jsonl"）またはCSV（format: "csv"）で書き出す。
+// fieldsはカンマ区切りの項目名（total_study_sec, rank_point, registration_date, last_entered）で、空なら全項目。
+// 進捗は outputPath + ".cursor" に記録され、中断した場合は同じ引数で再実行すれば続きから書き出す。
+func ExportUsers(ctx context.Context, clientOption option.ClientOption, outputPath string, format string, fields string) {
+	parsedFields, err := userexport.ParseFields(fields)
+	if err != nil {
+		panic(err)
+	}
+	opts := userexport.Options{Format: userexport.Format(format), Fields: parsedFields}
+
+	app, err := workspaceapp.NewWorkspaceApp(ctx, true, clientOption)
+	if err != nil {
+		panic(err)
+	}
+
+	app.MessageToOwner(ctx, "direct op: ExportUsers")
+
+	cursorPath := outputPath + ".cursor"
+	from, err := readExportCursor(cursorPath)
+	if err != nil {
+		panic(err)
+	}
+	f, err := openExportOutput(outputPath, from.Offset)
+	if err != nil {
+		panic(err)
+	}
+	defer func() {
+		if cerr := f.Close(); cerr != nil {
+			slog.Error("failed to close exported file", "error", cerr)
+		}
+	}()
+	if from.LastUserID != "" {
+		slog.Info("resuming user export.", "after", from.LastUserID, "exported", from.Exported)
+	}
+
+	last, err := userexport.Export(ctx, app.Repository, f, opts, from, func(c userexport.Checkpoint) error {
+		slog.Info("exported users.", "count", c.Exported, "last user id", c.LastUserID)
+		return writeExportCursor(cursorPath, c)
+	})
+	if err != nil {
+		panic(err)
+	}
+	if err := os.Remove(cursorPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		panic(err)
+	}
+	slog.Info("finished exporting users.", "path", outputPath, "count", last.Exported)
+}
+
+func readExportCursor(path string) (userexport.Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return userexport.Checkpoint{}, nil
+	}
+	if err != nil {
+		return userexport.Checkpoint{}, fmt.Errorf("read export cursor: %w", err)
+	}
+	var checkpoint userexport.Checkpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return userexport.Checkpoint{}, fmt.Errorf("parse export cursor %s: %w", path, err)
+	}
+	return checkpoint, nil
+}
+
+// writeExportCursor 書きかけのファイルが残らないように、一時ファイルに書いてから置き換える。
+func writeExportCursor(path string, checkpoint userexport.Checkpoint) error {
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return fmt.Errorf("in json.Marshal(): %w", err)
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o644); err != nil {
+		return fmt.Errorf("write export cursor: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("replace export cursor: %w", err)
+	}
+	return nil
+}
+
+// openExportOutput offsetバイト目から書き込めるように出力ファイルを開く。中断時に書きかけだった部分は切り捨てる。
+func openExportOutput(path string, offset int64) (*os.File, error) {
+	if offset == 0 {
+		f, err := os.Create(path)
+		if err != nil {
+			return nil, fmt.Errorf("create export file: %w", err)
+		}
+		return f, nil
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open export file to resume: %w", err)
+	}
+	if err := f.Truncate(offset); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("truncate export file: %w", err)
+	}
+	if _, err := f.Seek(offset, 0); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("seek export file: %w", err)
+	}
+	return f, nil
+}
@@ -91,7 +91,7 @@ func NewFirestoreController(t *testing.T) *repository.FirestoreControllerImpleme
 		t.Fatalf("create Firestore emulator client: %v", err)
 	}
 	t.Cleanup(func() {
-		if err := client.FirestoreClient().Close(); err != nil {
+		if err := client.Close(); err != nil {
 			t.Errorf("close Firestore emulator client: %v", err)
 		}
 	})
@@ -0,0 +1,892 @@
+// Package repositorytest はrepository.Repositoryの各実装が同じ振る舞いをすることを確かめるテストをまとめる。
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"app.modules/core/repository"
+)
+
+// Fixture はテスト対象のリポジトリと、Repositoryに作成する操作のないドキュメントを投入する手段
+type Fixture struct {
+	Repository  repository.Repository
+	SeedConfigs func(t *testing.T, constants repository.ConstantsConfigDoc, credentials repository.CredentialsConfigDoc)
+	SeedMenus   func(t *testing.T, menus []repository.MenuDoc)
+}
+
+// RunConformance はサブテストごとにnewFixtureで空のリポジトリを用意して実行する。
+// Firestoreでは時刻がUTCで返るため、ここで扱う時刻はすべてUTCとする。
+func RunConformance(t *testing.T, newFixture func(t *testing.T) Fixture) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, f Fixture)
+	}{
+		{"SeatCRUD", testSeatCRUD},
+		{"SeatQueries", testSeatQueries},
+		{"UserUpdates", testUserUpdates},
+		{"UserIterators", testUserIterators},
+		{"TransactionCommit", testTransactionCommit},
+		{"TransactionRollback", testTransactionRollback},
+		{"TransactionReadAfterWrite", testTransactionReadAfterWrite},
+		{"Configs", testConfigs},
+		{"HistoryIterators", testHistoryIterators},
+		{"UserActivityQueries", testUserActivityQueries},
+		{"WorkSegments", testWorkSegments},
+		{"DailyUserWorkHistory", testDailyUserWorkHistory},
+		{"UndoableExit", testUndoableExit},
+		{"SeatReservations", testSeatReservations},
+		{"SeatLimits", testSeatLimits},
+		{"MenuAndOrders", testMenuAndOrders},
+		{"ModerationActions", testModerationActions},
+		{"YoutubeAPIQuotaUsage", testYoutubeAPIQuotaUsage},
+		{"FanFundingHistory", testFanFundingHistory},
+		{"ProcessedLiveChatMessages", testProcessedLiveChatMessages},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, newFixture(t))
+		})
+	}
+}
+
+var baseTime = time.Date(2026, 8, 2, 3, 0, 0, 0, time.UTC)
+
+func newSeat(seatID int, userID string) repository.SeatDoc {
+	return repository.SeatDoc{
+		SeatID:          seatID,
+		UserID:          userID,
+		SessionID:       "session-" + userID,
+		UserDisplayName: "テストユーザー",
+		WorkName:        "作業",
+		EnteredAt:       baseTime,
+		Until:           baseTime.Add(time.Hour),
+		Appearance: repository.SeatAppearance{
+			ColorCode1: "#112233",
+			ColorCode2: "#445566",
+			NumStars:   2,
+		},
+		State:                   repository.WorkState,
+		CurrentStateStartedAt:   baseTime,
+		CurrentStateUntil:       baseTime.Add(time.Hour),
+		CurrentSegmentStartedAt: baseTime,
+	}
+}
+
+func runTransaction(t *testing.T, repo repository.Repository, fn func(context.Context, repository.Transaction) error) {
+	t.Helper()
+	require.NoError(t, repo.RunTransaction(context.Background(), fn))
+}
+
+func iteratorDocIDs(t *testing.T, iter repository.DocumentIterator) []string {
+	t.Helper()
+	defer iter.Stop()
+	ids := make([]string, 0)
+	for {
+		doc, err := iter.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		require.NoError(t, err)
+		ids = append(ids, doc.ID)
+	}
+	return ids
+}
+
+func seatIDs(seats []repository.SeatDoc) []int {
+	ids := make([]int, 0, len(seats))
+	for _, seat := range seats {
+		ids = append(ids, seat.SeatID)
+	}
+	return ids
+}
+
+func testSeatCRUD(t *testing.T, f Fixture) {
+	repo := f.Repository
+	ctx := context.Background()
+	seat := newSeat(3, "user-a")
+
+	runTransaction(t, repo, func(_ context.Context, tx repository.Transaction) error {
+		return repo.CreateSeat(tx, seat, false)
+	})
+	got, err := repo.ReadSeat(ctx, nil, seat.SeatID, false)
+	require.NoError(t, err)
+	assert.Equal(t, seat, got)
+
+	// 同じ席番号では作成できない
+	err = repo.RunTransaction(ctx, func(_ context.Context, tx repository.Transaction) error {
+		return repo.CreateSeat(tx, newSeat(3, "user-b"), false)
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	seat.WorkName = "更新後"
+	require.NoError(t, repo.UpdateSeat(ctx, nil, seat, false))
+	got, err = repo.ReadSeat(ctx, nil, seat.SeatID, false)
+	require.NoError(t, err)
+	assert.Equal(t, "更新後", got.WorkName)
+
+	// メンバー席は別のコレクション
+	_, err = repo.ReadSeat(ctx, nil, seat.SeatID, true)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	require.NoError(t, repo.DeleteSeat(ctx, nil, seat.SeatID, false))
+	_, err = repo.ReadSeat(ctx, nil, seat.SeatID, false)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	// 存在しない席の削除はエラーにならない
+	require.NoError(t, repo.DeleteSeat(ctx, nil, seat.SeatID, false))
+}
+
+func testSeatQueries(t *testing.T, f Fixture) {
+	repo := f.Repository
+	ctx := context.Background()
+
+	expired := newSeat(1, "user-expired")
+	expired.Until = baseTime.Add(-time.Minute)
+	onBreak := newSeat(2, "user-break")
+	onBreak.State = repository.BreakState
+	onBreak.CurrentStateUntil = baseTime.Add(-time.Minute)
+	working := newSeat(10, "user-working")
+	working.CurrentStateUntil = baseTime.Add(-time.Minute)
+	noWorkName := newSeat(11, "user-no-work-name")
+	noWorkName.WorkName = ""
+	member := newSeat(1, "user-member")
+	member.Until = baseTime.Add(-time.Minute)
+
+	runTransaction(t, repo, func(_ context.Context, tx repository.Transaction) error {
+		for _, seat := range []repository.SeatDoc{expired, onBreak, working, noWorkName} {
+			if err := repo.CreateSeat(tx, seat, false); err != nil {
+				return err
+			}
+		}
+		return repo.CreateSeat(tx, member, true)
+	})
+
+	generalSeats, err := repo.ReadGeneralSeats(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []int{1, 2, 10, 11}, seatIDs(generalSeats))
+	memberSeats, err := repo.ReadMemberSeats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []repository.SeatDoc{member}, memberSeats)
+
+	got, err := repo.ReadSeatsExpiredUntil(ctx, baseTime, false)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []int{1}, seatIDs(got))
+	got, err = repo.ReadSeatsExpiredBreakUntil(ctx, baseTime, false)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []int{2}, seatIDs(got))
+	got, err = repo.ReadSeatsExpiredWorkUntil(ctx, baseTime, false)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []int{10}, seatIDs(got))
+	got, err = repo.ReadActiveWorkNameSeats(ctx, false)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []int{1, 2, 10}, seatIDs(got))
+
+	seat, err := repo.ReadSeatWithUserID(ctx, "user-break", false)
+	require.NoError(t, err)
+	assert.Equal(t, onBreak, seat)
+	_, err = repo.ReadSeatWithUserID(ctx, "user-member", false)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func testUserUpdates(t *testing.T, f Fixture) {
+	repo := f.Repository
+	ctx := context.Background()
+	userID := "user-updates"
+	require.NoError(t, repo.CreateUser(ctx, nil, userID, repository.UserDoc{
+		RegistrationDate:  baseTime,
+		DailyGoalAchieved: true,
+	}))
+	require.Error(t, repo.CreateUser(ctx, nil, userID, repository.UserDoc{}))
+
+	runTransaction(t, repo, func(ctx context.Context, tx repository.Transaction) error {
+		return errors.Join(
+			repo.UpdateUserLastEnteredDate(tx, userID, baseTime.Add(time.Hour)),
+			repo.UpdateUserLastExitedDate(tx, userID, baseTime.Add(2*time.Hour)),
+			repo.UpdateUserRankVisible(tx, userID, true),
+			repo.UpdateUserDefaultStudyMin(tx, userID, 45),
+			repo.UpdateUserFavoriteColor(tx, userID, "#abcdef"),
+			repo.UpdateUserDailyGoalMin(tx, userID, 120),
+			repo.UpdateUserTotalTime(tx, userID, 7200, 3600),
+			repo.UpdateUserLastRPProcessed(tx, userID, baseTime),
+			repo.UpdateUserIsContinuousActiveAndCurrentActivityStateStarted(ctx, tx, userID, true, baseTime.AddDate(0, 0, -3)),
+			repo.UpdateUserRPAndLastPenaltyImposedDays(ctx, tx, userID, 500, 2),
+			repo.UpdateUserBestStreakDays(ctx, tx, userID, 4),
+			repo.UpdateUserSupporterAppearanceUntil(tx, userID, baseTime.Add(20*time.Hour)),
+		)
+	})
+
+	got, err := repo.ReadUser(ctx, nil, userID)
+	require.NoError(t, err)
+	assert.Equal(t, repository.UserDoc{
+		DailyTotalStudySec:          3600,
+		TotalStudySec:               7200,
+		RegistrationDate:            baseTime,
+		LastEntered:                 baseTime.Add(time.Hour),
+		LastExited:                  baseTime.Add(2 * time.Hour),
+		RankVisible:                 true,
+		DefaultStudyMin:             45,
+		RankPoint:                   500,
+		LastRPProcessed:             baseTime,
+		LastPenaltyImposedDays:      2,
+		IsContinuousActive:          true,
+		CurrentActivityStateStarted: baseTime.AddDate(0, 0, -3),
+		FavoriteColor:               "#abcdef",
+		DailyGoalMin:                120,
+		DailyGoalAchieved:           false, // 目標の変更でリセットされる
+		BestStreakDays:              4,
+		SupporterAppearanceUntil:    baseTime.Add(20 * time.Hour),
+	}, got)
+
+	// 存在しないユーザーの更新はコミット時に失敗する
+	err = repo.RunTransaction(ctx, func(_ context.Context, tx repository.Transaction) error {
+		return repo.UpdateUserRankPoint(tx, "user-missing", 10)
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func testUserIterators(t *testing.T, f Fixture) {
+	repo := f.Repository
+	ctx := context.Background()
+	users := map[string]repository.UserDoc{
+		"user-1": {LastEntered: baseTime, DailyTotalStudySec: 60, DailyGoalAchieved: true},
+		"user-2": {LastEntered: baseTime.AddDate(0, 0, -40), DailyTotalStudySec: 0},
+		"user-3": {LastEntered: baseTime.AddDate(0, 0, -1), DailyTotalStudySec: 30},
+	}
+	for userID, user := range users {
+		require.NoError(t, repo.CreateUser(ctx, nil, userID, user))
+	}
+
+	assert.ElementsMatch(t, []string{"user-1", "user-3"}, iteratorDocIDs(t, repo.GetUsersActiveAfterDate(ctx, baseTime.AddDate(0, 0, -31))))
+
+	refs, err := repo.GetAllUserDocRefs(ctx)
+	require.NoError(t, err)
+	var refIDs []string
+	for _, ref := range refs {
+		refIDs = append(refIDs, ref.ID)
+	}
+	assert.ElementsMatch(t, []string{"user-1", "user-2", "user-3"}, refIDs)
+
+	page, err := repo.ReadUsersPage(ctx, "", 2)
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	assert.Equal(t, "user-1", page[0].UserID)
+	assert.Equal(t, 60, page[0].DailyTotalStudySec)
+	assert.Equal(t, "user-2", page[1].UserID)
+	page, err = repo.ReadUsersPage(ctx, "user-2", 2)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, "user-3", page[0].UserID)
+	assert.True(t, baseTime.AddDate(0, 0, -1).Equal(page[0].LastEntered))
+
+	// イテレーターの返すRefでリセットできる
+	iter := repo.GetAllNonDailyZeroUserDocs(ctx)
+	count := 0
+	for {
+		doc, err := iter.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		require.NoError(t, err)
+		require.NoError(t, repo.ResetDailyTotalStudyTime(ctx, doc.ID))
+		count++
+	}
+	assert.Equal(t, 2, count)
+	assert.Empty(t, iteratorDocIDs(t, repo.GetAllNonDailyZeroUserDocs(ctx)))
+
+	iter = repo.GetAllDailyGoalAchievedUserDocs(ctx)
+	doc, err := iter.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", doc.ID)
+	require.NoError(t, repo.ResetDailyGoalAchieved(ctx, doc.ID))
+	_, err = iter.Next()
+	assert.ErrorIs(t, err, iterator.Done)
+
+	got, err := repo.ReadUser(ctx, nil, "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, 0, got.DailyTotalStudySec)
+	assert.False(t, got.DailyGoalAchieved)
+	assert.True(t, baseTime.Equal(got.LastEntered))
+}
+
+func testTransactionCommit(t *testing.T, f Fixture) {
+	repo := f.Repository
+	ctx := context.Background()
+	userID := "user-tx-commit"
+	seat := newSeat(5, userID)
+	require.NoError(t, repo.CreateUser(ctx, nil, userID, repository.UserDoc{TotalStudySec: 100}))
+
+	runTransaction(t, repo, func(ctx context.Context, tx repository.Transaction) error {
+		user, err := repo.ReadUser(ctx, tx, userID)
+		if err != nil {
+			return err
+		}
+		// 以下書き込みのみ
+		if err := repo.CreateSeat(tx, seat, false); err != nil {
+			return err
+		}
+		if err := repo.UpdateUserTotalTime(tx, userID, user.TotalStudySec+60, 60); err != nil {
+			return err
+		}
+		// コミットまでは反映されない
+		if _, err := repo.ReadSeat(ctx, nil, seat.SeatID, false); status.Code(err) != codes.NotFound {
+			return errors.New("the seat is visible before commit")
+		}
+		return nil
+	})
+
+	_, err := repo.ReadSeat(ctx, nil, seat.SeatID, false)
+	require.NoError(t, err)
+	got, err := repo.ReadUser(ctx, nil, userID)
+	require.NoError(t, err)
+	assert.Equal(t, 160, got.TotalStudySec)
+}
+
+func testTransactionRollback(t *testing.T, f Fixture) {
+	repo := f.Repository
+	ctx := context.Background()
+	userID := "user-tx-rollback"
+	seat := newSeat(6, userID)
+	require.NoError(t, repo.CreateUser(ctx, nil, userID, repository.UserDoc{TotalStudySec: 100}))
+	sentinelErr := errors.New("rollback transaction sentinel")
+
+	err := repo.RunTransaction(ctx, func(ctx context.Context, tx repository.Transaction) error {
+		if err := repo.CreateSeat(tx, seat, false); err != nil {
+			return err
+		}
+		if err := repo.CreateUserActivityDoc(ctx, tx, repository.UserActivityDoc{UserID: userID, SeatID: seat.SeatID, TakenAt: baseTime}); err != nil {
+			return err
+		}
+		if err := repo.UpdateUserTotalTime(tx, userID, 9999, 9999); err != nil {
+			return err
+		}
+		return sentinelErr
+	})
+	require.ErrorIs(t, err, sentinelErr)
+
+	_, err = repo.ReadSeat(ctx, nil, seat.SeatID, false)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Empty(t, iteratorDocIDs(t, repo.GetAllUserActivityDocIDsAfterDate(ctx, baseTime.Add(-time.Hour))))
+	got, err := repo.ReadUser(ctx, nil, userID)
+	require.NoError(t, err)
+	assert.Equal(t, 100, got.TotalStudySec)
+}
+
+func testTransactionReadAfterWrite(t *testing.T, f Fixture) {
+	repo := f.Repository
+	ctx := context.Background()
+	userID := "user-read-after-write"
+	require.NoError(t, repo.CreateUser(ctx, nil, userID, repository.UserDoc{}))
+
+	err := repo.RunTransaction(ctx, func(ctx context.Context, tx repository.Transaction) error {
+		if err := repo.UpdateUserRankPoint(tx, userID, 10); err != nil {
+			return err
+		}
+		_, _ = repo.ReadUser(ctx, tx, userID) // エラーを無視してもトランザクションは失敗する
+		return nil
+	})
+	require.Error(t, err)
+
+	got, err := repo.ReadUser(ctx, nil, userID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, got.RankPoint)
+}
+
+func testConfigs(t *testing.T, f Fixture) {
+	repo := f.Repository
+	ctx := context.Background()
+
+	// 設定ドキュメントがなければ更新できない
+	require.Error(t, repo.UpdateLastResetDailyTotalStudyTime(ctx, baseTime))
+
+	f.SeedConfigs(t, repository.ConstantsConfigDoc{MaxSeats: 10, DefaultWorkTimeMin: 60}, repository.CredentialsConfigDoc{
+		YoutubeLiveChatID:            "live-chat-initial",
+		YoutubeLiveChatNextPageToken: "token-initial",
+	})
+
+	runTransaction(t, repo, func(ctx context.Context, tx repository.Transaction) error {
+		return errors.Join(
+			repo.UpdateMaxSeats(ctx, tx, 20),
+			repo.UpdateMemberMaxSeats(ctx, tx, 5),
+			repo.UpdateDesiredMaxSeats(ctx, tx, 25),
+			repo.UpdateDesiredMemberMaxSeats(ctx, tx, 6),
+			repo.UpdateLiveChatID(ctx, tx, "live-chat-updated"),
+			repo.UpdateAccessTokenOfBotCredential(ctx, tx, "token", baseTime),
+			repo.UpdateAccessTokenOfChannelCredential(ctx, tx, "token", baseTime),
+		)
+	})
+	require.NoError(t, repo.UpdateNextPageToken(ctx, "token-updated"))
+	require.NoError(t, repo.UpdateLastResetDailyTotalStudyTime(ctx, baseTime))
+	require.NoError(t, repo.UpdateLastTransferCollectionHistoryBigquery(ctx, baseTime.Add(time.Hour)))
+	require.NoError(t, repo.UpdateLastLongTimeSittingChecked(ctx, baseTime))
+
+	constants, err := repo.ReadSystemConstantsConfig(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, repository.ConstantsConfigDoc{
+		DefaultWorkTimeMin:                    60,
+		MaxSeats:                              20,
+		MemberMaxSeats:                        5,
+		DesiredMaxSeats:                       25,
+		DesiredMemberMaxSeats:                 6,
+		LastResetDailyTotalStudySec:           baseTime,
+		LastTransferCollectionHistoryBigquery: baseTime.Add(time.Hour),
+	}, constants)
+
+	liveChatID, err := repo.ReadLiveChatID(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "live-chat-updated", liveChatID)
+	nextPageToken, err := repo.ReadNextPageToken(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "token-updated", nextPageToken)
+	credentials, err := repo.ReadCredentialsConfig(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "live-chat-updated", credentials.YoutubeLiveChatID)
+
+	require.NoError(t, repo.UpdateWorkNameTrend(ctx, nil, repository.WorkNameTrendDoc{
+		Ranking:  []repository.WorkNameTrendRanking{{Rank: 1, Genre: "勉強", Count: 3, Examples: []string{"数学"}}},
+		RankedAt: baseTime,
+	}))
+}
+
+// testHistoryIterators はDeleteIteratorDocsと同じく、イテレーターの返すRefをトランザクション内で削除する。
+func testHistoryIterators(t *testing.T, f Fixture) {
+	repo := f.Repository
+	ctx := context.Background()
+	for i := range 3 {
+		at := baseTime.Add(time.Duration(i-2) * time.Hour) // 2件がbaseTimeより前
+		require.NoError(t, repo.CreateLiveChatHistoryDoc(ctx, nil, repository.LiveChatHistoryDoc{MessageText: "!in", PublishedAt: at}))
+		require.NoError(t, repo.CreateUserActivityDoc(ctx, nil, repository.UserActivityDoc{UserID: "user-history", TakenAt: at}))
+		require.NoError(t, repo.CreateOrderHistoryDoc(ctx, nil, repository.OrderHistoryDoc{UserID: "user-history", OrderedAt: at}))
+	}
+
+	deleteAll := func(iter repository.DocumentIterator) int {
+		count := 0
+		runTransaction(t, repo, func(ctx context.Context, tx repository.Transaction) error {
+			for {
+				doc, err := iter.Next()
+				if errors.Is(err, iterator.Done) {
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+				count++
+				if err := repo.DeleteDocRef(ctx, tx, doc); err != nil {
+					return err
+				}
+			}
+		})
+		return count
+	}
+
+	assert.Len(t, iteratorDocIDs(t, repo.GetAllUserActivityDocIDsAfterDate(ctx, baseTime)), 1)
+
+	assert.Equal(t, 2, deleteAll(repo.Get500LiveChatHistoryDocIDsBeforeDate(ctx, baseTime)))
+	assert.Equal(t, 0, deleteAll(repo.Get500LiveChatHistoryDocIDsBeforeDate(ctx, baseTime)))
+	assert.Equal(t, 2, deleteAll(repo.Get500UserActivityDocIDsBeforeDate(ctx, baseTime)))
+	assert.Equal(t, 2, deleteAll(repo.Get500OrderHistoryDocIDsBeforeDate(ctx, baseTime)))
+
+	assert.Len(t, iteratorDocIDs(t, repo.GetAllUserActivityDocIDsAfterDate(ctx, baseTime.Add(-24*time.Hour))), 1)
+	orderCount, err := repo.CountUserOrdersOfTheDay(ctx, "user-history", baseTime)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), orderCount)
+}
+
+func testUserActivityQueries(t *testing.T, f Fixture) {
+	repo := f.Repository
+	ctx := context.Background()
+	userID := "user-activity"
+	activities := []repository.UserActivityDoc{
+		{UserID: userID, ActivityType: repository.EnterRoomActivity, SeatID: 3, TakenAt: baseTime.Add(2 * time.Hour)},
+		{UserID: userID, ActivityType: repository.EnterRoomActivity, SeatID: 3, TakenAt: baseTime},
+		{UserID: userID, ActivityType: repository.ExitRoomActivity, SeatID: 3, TakenAt: baseTime.Add(time.Hour)},
+		{UserID: userID, ActivityType: repository.EnterRoomActivity, SeatID: 3, IsMemberSeat: true, TakenAt: baseTime},
+		{UserID: userID, ActivityType: repository.EnterRoomActivity, SeatID: 4, TakenAt: baseTime},
+		{UserID: "other-user", ActivityType: repository.EnterRoomActivity, SeatID: 3, TakenAt: baseTime},
+		{UserID: userID, ActivityType: repository.EnterRoomActivity, SeatID: 3, TakenAt: baseTime.Add(-time.Hour)},
+	}
+	runTransaction(t, repo, func(ctx context.Context, tx repository.Transaction) error {
+		for _, activity := range activities {
+			if err := repo.CreateUserActivityDoc(ctx, tx, activity); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	enters, err := repo.GetEnterRoomUserActivityDocIDsAfterDateForUserAndSeat(ctx, baseTime, userID, 3, false)
+	require.NoError(t, err)
+	assert.Equal(t, []repository.UserActivityDoc{activities[1], activities[0]}, enters) // 古い順
+	exits, err := repo.GetExitRoomUserActivityDocIDsAfterDateForUserAndSeat(ctx, baseTime, userID, 3, false)
+	require.NoError(t, err)
+	assert.Equal(t, []repository.UserActivityDoc{activities[2]}, exits)
+	enters, err = repo.GetEnterRoomUserActivityDocIDsAfterDateForUserAndSeat(ctx, baseTime, userID, 3, true)
+	require.NoError(t, err)
+	assert.Equal(t, []repository.UserActivityDoc{activities[3]}, enters)
+}
+
+func testWorkSegments(t *testing.T, f Fixture) {
+	repo := f.Repository
+	ctx := context.Background()
+	segments := []repository.WorkSegmentDoc{
+		{UserID: "user-a", SessionID: "session-a", SegmentType: repository.WorkState, StartedAt: baseTime, EndedAt: baseTime.Add(time.Hour), DurationSec: 3600},
+		{UserID: "user-a", SessionID: "session-a", SegmentType: repository.BreakState, StartedAt: baseTime.Add(time.Hour), EndedAt: baseTime.Add(70 * time.Minute), DurationSec: 600},
+		{UserID: "user-a", SessionID: "session-a", SegmentType: repository.WorkState, StartedAt: baseTime.Add(70 * time.Minute), EndedAt: baseTime.Add(2 * time.Hour), DurationSec: 3000},
+		{UserID: "user-b", SessionID: "session-b", SegmentType: repository.WorkState, StartedAt: baseTime.Add(30 * time.Minute), EndedAt: baseTime.Add(time.Hour), DurationSec: 1800},
+		{UserID: "user-a", SessionID: "session-c", SegmentType: repository.WorkState, StartedAt: baseTime.Add(24 * time.Hour), EndedAt: baseTime.Add(25 * time.Hour), DurationSec: 3600},
+	}
+	for _, segment := range segments {
+		require.NoError(t, repo.CreateWorkSegmentDoc(ctx, nil, segment))
+	}
+
+	got, err := repo.ReadWorkStateSegmentsBySessionID(ctx, "session-a")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []repository.WorkSegmentDoc{segments[0], segments[2]}, got)
+
+	got, err = repo.ReadWorkSegmentsByUserIDAndTimeRange(ctx, "user-a", baseTime, baseTime.Add(24*time.Hour), 0)
+	require.NoError(t, err)
+	assert.Equal(t, []repository.WorkSegmentDoc{segments[2], segments[1], segments[0]}, got) // 新しい順
+
+	got, err = repo.ReadWorkSegmentsByUserIDAndTimeRange(ctx, "user-a", baseTime, baseTime.Add(24*time.Hour), 2)
+	require.NoError(t, err)
+	assert.Equal(t, []repository.WorkSegmentDoc{segments[2], segments[1]}, got)
+
+	// 1件ずつページングしても、開始日時順にすべて読める
+	var paged []repository.WorkSegmentDoc
+	var cursor repository.WorkSegmentDocWithID
+	for {
+		page, err := repo.ReadWorkSegmentsPageByTimeRange(ctx, baseTime.Add(time.Minute), baseTime.Add(24*time.Hour), cursor, 1)
+		require.NoError(t, err)
+		if len(page) == 0 {
+			break
+		}
+		require.Len(t, page, 1)
+		assert.NotEmpty(t, page[0].SegmentID)
+		paged = append(paged, page[0].WorkSegmentDoc)
+		cursor = page[0]
+	}
+	assert.Equal(t, []repository.WorkSegmentDoc{segments[3], segments[1], segments[2]}, paged)
+}
+
+func testDailyUserWorkHistory(t *testing.T, f Fixture) {
+	repo := f.Repository
+	ctx := context.Background()
+	userID := "user-daily"
+
+	_, err := repo.ReadDailyUserWorkHistory(ctx, nil, userID, baseTime)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	require.NoError(t, repo.AddDailyUserWorkHistory(ctx, nil, userID, baseTime, 600, 60))
+	runTransaction(t, repo, func(ctx context.Context, tx repository.Transaction) error {
+		return repo.AddDailyUserWorkHistory(ctx, tx, userID, baseTime.Add(time.Hour), 300, 30)
+	})
+	got, err := repo.ReadDailyUserWorkHistory(ctx, nil, userID, baseTime)
+	require.NoError(t, err)
+	assert.Equal(t, userID, got.UserID)
+	assert.Equal(t, 900, got.TotalStudySec)
+	assert.Equal(t, 90, got.TotalBreakSec)
+	assert.Equal(t, "Asia/Tokyo", got.TimezoneName)
+
+	// JSTで翌日になる時刻は別のドキュメント
+	nextDay := time.Date(2026, 8, 2, 15, 0, 0, 0, time.UTC)
+	_, err = repo.ReadDailyUserWorkHistory(ctx, nil, userID, nextDay)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	require.NoError(t, repo.SetDailyUserWorkHistory(ctx, nil, repository.DailyUserWorkHistoryDoc{
+		UserID:        userID,
+		Date:          got.Date,
+		TotalStudySec: 100,
+	}))
+	got, err = repo.ReadDailyUserWorkHistory(ctx, nil, userID, baseTime)
+	require.NoError(t, err)
+	assert.Equal(t, 100, got.TotalStudySec)
+	assert.Equal(t, 0, got.TotalBreakSec)
+}
+
+func testUndoableExit(t *testing.T, f Fixture) {
+	repo := f.Repository
+	ctx := context.Background()
+	undoableExit := repository.UndoableExitDoc{
+		UserID:            "user-undo",
+		Seat:              newSeat(8, "user-undo"),
+		ExitedAt:          baseTime,
+		AddedWorkSec:      600,
+		AddedDailyHistory: []repository.UndoableDailyWorkHistory{{Date: baseTime, WorkSec: 600}},
+	}
+	require.NoError(t, repo.SetUndoableExit(ctx, nil, undoableExit))
+
+	got, err := repo.ReadUndoableExit(ctx, nil, undoableExit.UserID)
+	require.NoError(t, err)
+	assert.Equal(t, undoableExit, got)
+
+	recentExit := repository.UndoableExitDoc{
+		UserID:   "user-undo-recent",
+		Seat:     newSeat(9, "user-undo-recent"),
+		ExitedAt: baseTime.Add(time.Hour),
+	}
+	require.NoError(t, repo.SetUndoableExit(ctx, nil, recentExit))
+	assert.Equal(t, []string{undoableExit.UserID}, iteratorDocIDs(t, repo.Get500UndoableExitDocIDsBeforeDate(ctx, baseTime.Add(time.Minute))))
+
+	runTransaction(t, repo, func(ctx context.Context, tx repository.Transaction) error {
+		return repo.DeleteUndoableExit(ctx, tx, undoableExit.UserID)
+	})
+	_, err = repo.ReadUndoableExit(ctx, nil, undoableExit.UserID)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func testSeatReservations(t *testing.T, f Fixture) {
+	repo := f.Repository
+	ctx := context.Background()
+	reservation := repository.SeatReservationDoc{
+		UserID:  "user-reserve",
+		SeatID:  5,
+		StartAt: baseTime,
+		Until:   baseTime.Add(time.Hour),
+	}
+	runTransaction(t, repo, func(ctx context.Context, tx repository.Transaction) error {
+		return repo.CreateSeatReservation(ctx, tx, reservation, true)
+	})
+	require.NoError(t, repo.CreateSeatReservation(ctx, nil, reservation, false))
+
+	got, err := repo.ReadSeatReservationsWithUserID(ctx, nil, reservation.UserID, true)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.NotEmpty(t, got[0].ReservationID)
+	reservation.ReservationID = got[0].ReservationID
+	assert.Equal(t, reservation, got[0])
+
+	got, err = repo.ReadSeatReservationsWithSeatID(ctx, nil, 5, true)
+	require.NoError(t, err)
+	assert.Len(t, got, 1)
+	runTransaction(t, repo, func(ctx context.Context, tx repository.Transaction) error {
+		got, err := repo.ReadSeatReservationsWithSeatID(ctx, tx, 5, true)
+		require.NoError(t, err)
+		assert.Equal(t, []repository.SeatReservationDoc{reservation}, got)
+		got, err = repo.ReadSeatReservationsWithUserID(ctx, tx, reservation.UserID, true)
+		require.NoError(t, err)
+		assert.Equal(t, []repository.SeatReservationDoc{reservation}, got)
+		return nil
+	})
+	got, err = repo.ReadSeatReservationsStartBefore(ctx, baseTime, true, 0)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+	got, err = repo.ReadSeatReservationsStartBefore(ctx, baseTime.Add(time.Minute), true, 0)
+	require.NoError(t, err)
+	assert.Len(t, got, 1)
+
+	// 上限を指定すると開始時刻の早い順に返す
+	earlier := repository.SeatReservationDoc{
+		UserID:  "user-reserve-earlier",
+		SeatID:  6,
+		StartAt: baseTime.Add(-time.Hour),
+		Until:   baseTime,
+	}
+	require.NoError(t, repo.CreateSeatReservation(ctx, nil, earlier, true))
+	got, err = repo.ReadSeatReservationsStartBefore(ctx, baseTime.Add(time.Minute), true, 0)
+	require.NoError(t, err)
+	assert.Len(t, got, 2)
+	got, err = repo.ReadSeatReservationsStartBefore(ctx, baseTime.Add(time.Minute), true, 1)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, earlier.SeatID, got[0].SeatID)
+	require.NoError(t, repo.DeleteSeatReservation(ctx, nil, got[0].ReservationID, true))
+
+	require.NoError(t, repo.DeleteSeatReservation(ctx, nil, reservation.ReservationID, true))
+	got, err = repo.ReadSeatReservationsWithUserID(ctx, nil, reservation.UserID, true)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+	got, err = repo.ReadSeatReservationsWithUserID(ctx, nil, reservation.UserID, false)
+	require.NoError(t, err)
+	assert.Len(t, got, 1)
+}
+
+func testSeatLimits(t *testing.T, f Fixture) {
+	repo := f.Repository
+	ctx := context.Background()
+	userID := "user-limit"
+	require.NoError(t, repo.CreateSeatLimitInWHITEList(ctx, 3, userID, baseTime, baseTime.Add(time.Hour), false))
+	require.NoError(t, repo.CreateSeatLimitInBLACKList(ctx, 3, userID, baseTime, baseTime.Add(-time.Minute), false))
+	require.NoError(t, repo.CreateSeatLimitInBLACKList(ctx, 3, userID, baseTime, baseTime.Add(-time.Minute), true))
+
+	white, err := repo.ReadSeatLimitsWHITEListWithSeatIDAndUserID(ctx, 3, userID, false)
+	require.NoError(t, err)
+	assert.Equal(t, []repository.SeatLimitDoc{{SeatID: 3, UserID: userID, CreatedAt: baseTime, Until: baseTime.Add(time.Hour)}}, white)
+	black, err := repo.ReadSeatLimitsBLACKListWithSeatIDAndUserID(ctx, 3, userID, false)
+	require.NoError(t, err)
+	assert.Len(t, black, 1)
+	black, err = repo.ReadSeatLimitsBLACKListWithSeatIDAndUserID(ctx, 4, userID, false)
+	require.NoError(t, err)
+	assert.Empty(t, black)
+
+	// untilがthresholdTimeより前のものが対象
+	assert.Empty(t, iteratorDocIDs(t, repo.Get500SeatLimitsAfterUntilInWHITEList(ctx, baseTime, false)))
+	whiteIDs := iteratorDocIDs(t, repo.Get500SeatLimitsAfterUntilInWHITEList(ctx, baseTime.Add(2*time.Hour), false))
+	require.Len(t, whiteIDs, 1)
+	blackIDs := iteratorDocIDs(t, repo.Get500SeatLimitsAfterUntilInBLACKList(ctx, baseTime, false))
+	require.Len(t, blackIDs, 1)
+
+	require.NoError(t, repo.DeleteSeatLimitInWHITEList(ctx, whiteIDs[0], false))
+	require.NoError(t, repo.DeleteSeatLimitInBLACKList(ctx, blackIDs[0], false))
+	white, err = repo.ReadSeatLimitsWHITEListWithSeatIDAndUserID(ctx, 3, userID, false)
+	require.NoError(t, err)
+	assert.Empty(t, white)
+	black, err = repo.ReadSeatLimitsBLACKListWithSeatIDAndUserID(ctx, 3, userID, true)
+	require.NoError(t, err)
+	assert.Len(t, black, 1)
+}
+
+func testMenuAndOrders(t *testing.T, f Fixture) {
+	repo := f.Repository
+	ctx := context.Background()
+	f.SeedMenus(t, []repository.MenuDoc{{Code: "coffee", Name: "コーヒー"}, {Code: "bread", Name: "パン"}, {Code: "tea", Name: "紅茶"}})
+
+	menus, err := repo.ReadAllMenuDocsOrderByCode(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []repository.MenuDoc{{Code: "bread", Name: "パン"}, {Code: "coffee", Name: "コーヒー"}, {Code: "tea", Name: "紅茶"}}, menus)
+
+	// 日付の区切りはFirestoreの実装と同じくtime.Localで判定される
+	day := time.Date(2026, 8, 2, 12, 0, 0, 0, time.Local)
+	runTransaction(t, repo, func(ctx context.Context, tx repository.Transaction) error {
+		return errors.Join(
+			repo.CreateOrderHistoryDoc(ctx, tx, repository.OrderHistoryDoc{UserID: "user-order", MenuCode: "coffee", OrderedAt: day}),
+			repo.CreateOrderHistoryDoc(ctx, tx, repository.OrderHistoryDoc{UserID: "user-order", MenuCode: "tea", OrderedAt: day.Add(time.Hour)}),
+			repo.CreateOrderHistoryDoc(ctx, tx, repository.OrderHistoryDoc{UserID: "user-order", MenuCode: "tea", OrderedAt: day.AddDate(0, 0, 1)}),
+			repo.CreateOrderHistoryDoc(ctx, tx, repository.OrderHistoryDoc{UserID: "other-user", MenuCode: "tea", OrderedAt: day}),
+		)
+	})
+	count, err := repo.CountUserOrdersOfTheDay(ctx, "user-order", day)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+}
+
+func testModerationActions(t *testing.T, f Fixture) {
+	repo := f.Repository
+	ctx := context.Background()
+	targetUserID := "user-target"
+	actions := []repository.ModerationActionDoc{
+		{ActionType: repository.BlockModerationAction, ActorUserID: "moderator", ActorDisplayName: "モデレーター",
+			TargetUserID: targetUserID, TargetDisplayName: "対象", SeatID: 3, BanID: "ban-1", TakenAt: baseTime.Add(time.Hour)},
+		{ActionType: repository.NGWordTimeoutModerationAction, TargetUserID: targetUserID, TargetDisplayName: "対象",
+			MatchedRegex: "spam.*", MessageText: "spam message", BanID: "ban-2", TimeoutDurationSec: 600,
+			TakenAt: baseTime.Add(2 * time.Hour)},
+		{ActionType: repository.KickModerationAction, ActorUserID: "moderator", ActorDisplayName: "モデレーター",
+			TargetUserID: targetUserID, TargetDisplayName: "対象", SeatID: 5, IsMemberSeat: true, TakenAt: baseTime},
+		{ActionType: repository.KickModerationAction, ActorUserID: "moderator", TargetUserID: "other-user", TakenAt: baseTime},
+	}
+	runTransaction(t, repo, func(ctx context.Context, tx repository.Transaction) error {
+		return errors.Join(
+			repo.CreateModerationActionDoc(ctx, tx, actions[0]),
+			repo.CreateModerationActionDoc(ctx, tx, actions[1]),
+		)
+	})
+	require.NoError(t, repo.CreateModerationActionDoc(ctx, nil, actions[2]))
+	require.NoError(t, repo.CreateModerationActionDoc(ctx, nil, actions[3]))
+
+	history, err := repo.ReadModerationActionsByTargetUserID(ctx, targetUserID)
+	require.NoError(t, err)
+	assert.Equal(t, []repository.ModerationActionDoc{actions[2], actions[0], actions[1]}, history) // 古い順
+
+	history, err = repo.ReadModerationActionsByTargetUserID(ctx, "unknown-user")
+	require.NoError(t, err)
+	assert.Empty(t, history)
+}
+
+func testYoutubeAPIQuotaUsage(t *testing.T, f Fixture) {
+	repo := f.Repository
+	ctx := context.Background()
+
+	_, err := repo.ReadYoutubeAPIQuotaUsage(ctx, "2026-08-02")
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// 存在しなければ作成し、2回目以降は加算する
+	require.NoError(t, repo.AddYoutubeAPIQuotaUsage(ctx, "2026-08-02", map[string]int{"list-messages": 2}, 10))
+	require.NoError(t, repo.AddYoutubeAPIQuotaUsage(ctx, "2026-08-02",
+		map[string]int{"list-messages": 1, "insert-message": 1}, 55))
+	require.NoError(t, repo.AddYoutubeAPIQuotaUsage(ctx, "2026-08-03", map[string]int{"list-broadcasts": 1}, 1))
+
+	usage, err := repo.ReadYoutubeAPIQuotaUsage(ctx, "2026-08-02")
+	require.NoError(t, err)
+	assert.Equal(t, repository.YoutubeAPIQuotaUsageDoc{
+		Date:  "2026-08-02",
+		Units: 65,
+		Calls: map[string]int{"list-messages": 3, "insert-message": 1},
+	}, usage)
+
+	usage, err = repo.ReadYoutubeAPIQuotaUsage(ctx, "2026-08-03")
+	require.NoError(t, err)
+	assert.Equal(t, 1, usage.Units)
+}
+
+func testFanFundingHistory(t *testing.T, f Fixture) {
+	repo := f.Repository
+	ctx := context.Background()
+	histories := []repository.FanFundingHistoryDoc{
+		{EventType: repository.SuperChatFanFundingEvent, UserID: "user-a", UserDisplayName: "支援者",
+			AmountMicros: 500_000_000, Currency: "JPY", AmountDisplayString: "￥500", RewardRP: 100,
+			RewardAppearanceUntil: baseTime.Add(12 * time.Hour), PublishedAt: baseTime.Add(time.Hour)},
+		{EventType: repository.MembershipGiftFanFundingEvent, UserID: "user-a", UserDisplayName: "支援者",
+			MembershipLevelName: "メンバー", GiftCount: 5, RewardExtendedMin: 30, PublishedAt: baseTime},
+		{EventType: repository.NewMembershipFanFundingEvent, UserID: "user-b", PublishedAt: baseTime},
+	}
+	runTransaction(t, repo, func(ctx context.Context, tx repository.Transaction) error {
+		return repo.CreateFanFundingHistoryDoc(ctx, tx, "message-1", histories[0])
+	})
+	require.NoError(t, repo.CreateFanFundingHistoryDoc(ctx, nil, "message-2", histories[1]))
+	require.NoError(t, repo.CreateFanFundingHistoryDoc(ctx, nil, "message-3", histories[2]))
+
+	// 同じメッセージのイベントは2回記録できない
+	err := repo.RunTransaction(ctx, func(ctx context.Context, tx repository.Transaction) error {
+		return repo.CreateFanFundingHistoryDoc(ctx, tx, "message-1", histories[0])
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	got, err := repo.ReadFanFundingHistoryByUserID(ctx, "user-a")
+	require.NoError(t, err)
+	assert.Equal(t, []repository.FanFundingHistoryDoc{histories[1], histories[0]}, got) // 古い順
+
+	got, err = repo.ReadFanFundingHistoryByUserID(ctx, "unknown-user")
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func testProcessedLiveChatMessages(t *testing.T, f Fixture) {
+	repo := f.Repository
+	ctx := context.Background()
+
+	_, err := repo.ReadProcessedLiveChatMessage(ctx, nil, "message-1")
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	processed := repository.ProcessedLiveChatMessageDoc{UserID: "user-a", ProcessedAt: baseTime, ExpireAt: baseTime.Add(24 * time.Hour)}
+	runTransaction(t, repo, func(ctx context.Context, tx repository.Transaction) error {
+		if _, err := repo.ReadProcessedLiveChatMessage(ctx, tx, "message-1"); status.Code(err) != codes.NotFound {
+			return errors.Join(errors.New("expected NotFound"), err)
+		}
+		return repo.CreateProcessedLiveChatMessage(ctx, tx, "message-1", processed)
+	})
+
+	got, err := repo.ReadProcessedLiveChatMessage(ctx, nil, "message-1")
+	require.NoError(t, err)
+	assert.Equal(t, processed, got)
+
+	// 同じメッセージは2回記録できず、トランザクション内の他の書き込みも反映されない
+	err = repo.RunTransaction(ctx, func(ctx context.Context, tx repository.Transaction) error {
+		if err := repo.CreateUser(ctx, tx, "user-a", repository.UserDoc{}); err != nil {
+			return err
+		}
+		return repo.CreateProcessedLiveChatMessage(ctx, tx, "message-1", processed)
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+	_, err = repo.ReadUser(ctx, nil, "user-a")
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
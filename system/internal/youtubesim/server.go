@@ -0,0 +1,402 @@
+// Package youtubesim は本物の配信なしでBotを動かすための、YouTube Data APIの偽サーバー。
+// liveChatMessages.list/insert、liveChatBans.insert/delete、liveBroadcasts.list、liveStreams.listに対応し、
+// テストからはInjectMessageなどで、スクリプトからは /sim/ 以下のHTTP APIでチャットを投入・確認できる。
+// 認証は行わないので、youtubebot.APIEndpointEnvでこのサーバーを指定して使う。
+package youtubesim
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"google.golang.org/api/youtube/v3"
+)
+
+// Role チャットの投稿者の種類
+type Role string
+
+const (
+	RoleViewer    Role = "viewer"
+	RoleMember    Role = "member"
+	RoleModerator Role = "moderator"
+	RoleOwner     Role = "owner"
+)
+
+// Author 投入するチャットの投稿者
+type Author struct {
+	ChannelID   string `json:"channel_id"`
+	DisplayName string `json:"display_name"`
+	Role        Role   `json:"role"`
+}
+
+// Config サーバーの初期状態
+type Config struct {
+	LiveChatID            string // 空なら "sim-live-chat-1"
+	BotChannelID          string // Botが投稿したチャットの投稿者。空なら "sim-bot"
+	PollingIntervalMillis int64  // liveChatMessages.listが返すpollingIntervalMillis
+}
+
+// Server 1つの配信とそのライブチャットを模倣する。
+type Server struct {
+	mu sync.Mutex
+
+	liveChatID            string
+	liveChatSeq           int
+	endedLiveChatIDs      map[string]bool
+	botChannelID          string
+	pollingIntervalMillis int64
+
+	messages     map[string][]*youtube.LiveChatMessage // live chat idごとのチャット
+	posted       []string                              // Botが投稿したチャットの本文
+	bans         []*youtube.LiveChatBan
+	streamStatus string
+	healthStatus string
+	idSeq        int
+
+	now func() time.Time
+}
+
+const (
+	broadcastID = "sim-broadcast"
+	streamID    = "sim-stream"
+)
+
+func NewServer(config Config) *Server {
+	s := &Server{
+		liveChatID:            config.LiveChatID,
+		liveChatSeq:           1,
+		endedLiveChatIDs:      make(map[string]bool),
+		botChannelID:          config.BotChannelID,
+		pollingIntervalMillis: config.PollingIntervalMillis,
+		messages:              make(map[string][]*youtube.LiveChatMessage),
+		streamStatus:          "active",
+		healthStatus:          "good",
+		now:                   time.Now,
+	}
+	if s.liveChatID == "" {
+		s.liveChatID = "sim-live-chat-1"
+	}
+	if s.botChannelID == "" {
+		s.botChannelID = "sim-bot"
+	}
+	return s
+}
+
+// LiveChatID 現在の配信のlive chat id
+func (s *Server) LiveChatID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.liveChatID
+}
+
+// InjectMessage authorのテキストチャットを現在のライブチャットに投稿する。
+func (s *Server) InjectMessage(author Author, text string) *youtube.LiveChatMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	message := s.newTextMessage(author, text)
+	s.messages[s.liveChatID] = append(s.messages[s.liveChatID], message)
+	return message
+}
+
+// PostedMessages Botが投稿したチャットの本文（全ライブチャット分、投稿順）
+func (s *Server) PostedMessages() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.posted)
+}
+
+// Bans 解除されていないブロック（ブロックされた順）
+func (s *Server) Bans() []youtube.LiveChatBan {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	bans := make([]youtube.LiveChatBan, 0, len(s.bans))
+	for _, ban := range s.bans {
+		bans = append(bans, *ban)
+	}
+	return bans
+}
+
+// BannedChannelIDs ブロックされ、解除されていないチャンネルのID（ブロックされた順）
+func (s *Server) BannedChannelIDs() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids := make([]string, 0, len(s.bans))
+	for _, ban := range s.bans {
+		ids = append(ids, ban.Snippet.BannedUserDetails.ChannelId)
+	}
+	return ids
+}
+
+// RestartBroadcast 現在のライブチャットを終了し、新しいlive chat idで配信し直す。
+// 古いlive chat idへのリクエストはliveChatEndedになる。
+func (s *Server) RestartBroadcast() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.endedLiveChatIDs[s.liveChatID] = true
+	s.liveChatSeq++
+	s.liveChatID = "sim-live-chat-" + strconv.Itoa(s.liveChatSeq)
+	return s.liveChatID
+}
+
+// SetStreamStatus liveStreams.listが返す配信の状態を変える。
+func (s *Server) SetStreamStatus(streamStatus string, healthStatus string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.streamStatus = streamStatus
+	s.healthStatus = healthStatus
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == "/youtube/v3/liveChat/messages" && r.Method == http.MethodGet:
+		s.listMessages(w, r)
+	case r.URL.Path == "/youtube/v3/liveChat/messages" && r.Method == http.MethodPost:
+		s.insertMessage(w, r)
+	case r.URL.Path == "/youtube/v3/liveChat/bans" && r.Method == http.MethodPost:
+		s.insertBan(w, r)
+	case r.URL.Path == "/youtube/v3/liveChat/bans" && r.Method == http.MethodDelete:
+		s.deleteBan(w, r)
+	case r.URL.Path == "/youtube/v3/liveBroadcasts" && r.Method == http.MethodGet:
+		s.listBroadcasts(w, r)
+	case r.URL.Path == "/youtube/v3/liveStreams" && r.Method == http.MethodGet:
+		s.listStreams(w)
+	case r.URL.Path == "/sim/messages" && r.Method == http.MethodPost:
+		s.handleInjectMessage(w, r)
+	case r.URL.Path == "/sim/posted" && r.Method == http.MethodGet:
+		writeJSON(w, s.PostedMessages())
+	case r.URL.Path == "/sim/bans" && r.Method == http.MethodGet:
+		writeJSON(w, s.BannedChannelIDs())
+	case r.URL.Path == "/sim/restart-broadcast" && r.Method == http.MethodPost:
+		writeJSON(w, map[string]string{"live_chat_id": s.RestartBroadcast()})
+	case r.URL.Path == "/sim/stream-status" && r.Method == http.MethodPost:
+		s.handleSetStreamStatus(w, r)
+	default:
+		// streamListなど未対応のAPIは、エンドポイントがないものとして扱われるよう理由なしの404を返す
+		http.NotFound(w, r)
+	}
+}
+
+// listMessages pageTokenはそのライブチャットで次に返すチャットの番号
+func (s *Server) listMessages(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	liveChatID := r.URL.Query().Get("liveChatId")
+	if !s.checkLiveChat(w, liveChatID) {
+		return
+	}
+	messages := s.messages[liveChatID]
+	start := 0
+	if pageToken := r.URL.Query().Get("pageToken"); pageToken != "" {
+		n, err := strconv.Atoi(pageToken)
+		if err != nil || n < 0 || len(messages) < n {
+			writeError(w, http.StatusBadRequest, "pageTokenInvalid", "invalid page token: "+pageToken)
+			return
+		}
+		start = n
+	}
+	writeJSON(w, youtube.LiveChatMessageListResponse{
+		Kind:                  "youtube#liveChatMessageListResponse",
+		Items:                 messages[start:],
+		NextPageToken:         strconv.Itoa(len(messages)),
+		PollingIntervalMillis: s.pollingIntervalMillis,
+	})
+}
+
+func (s *Server) insertMessage(w http.ResponseWriter, r *http.Request) {
+	var request youtube.LiveChatMessage
+	if !decodeBody(w, r, &request) {
+		return
+	}
+	if request.Snippet == nil || request.Snippet.TextMessageDetails == nil {
+		writeError(w, http.StatusBadRequest, "invalidValue", "snippet.textMessageDetails is required")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	liveChatID := request.Snippet.LiveChatId
+	if !s.checkLiveChat(w, liveChatID) {
+		return
+	}
+	message := s.newTextMessage(Author{ChannelID: s.botChannelID, DisplayName: "bot", Role: RoleModerator},
+		request.Snippet.TextMessageDetails.MessageText)
+	message.Snippet.LiveChatId = liveChatID
+	s.messages[liveChatID] = append(s.messages[liveChatID], message)
+	s.posted = append(s.posted, message.Snippet.DisplayMessage)
+	writeJSON(w, message)
+}
+
+func (s *Server) insertBan(w http.ResponseWriter, r *http.Request) {
+	var ban youtube.LiveChatBan
+	if !decodeBody(w, r, &ban) {
+		return
+	}
+	if ban.Snippet == nil || ban.Snippet.BannedUserDetails == nil {
+		writeError(w, http.StatusBadRequest, "invalidValue", "snippet.bannedUserDetails is required")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.checkLiveChat(w, ban.Snippet.LiveChatId) {
+		return
+	}
+	ban.Id = s.nextID("ban")
+	s.bans = append(s.bans, &ban)
+	writeJSON(w, ban)
+}
+
+func (s *Server) deleteBan(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	index := slices.IndexFunc(s.bans, func(ban *youtube.LiveChatBan) bool { return ban.Id == id })
+	if index < 0 {
+		writeError(w, http.StatusNotFound, "liveChatBanNotFound", "the ban does not exist")
+		return
+	}
+	s.bans = slices.Delete(s.bans, index, index+1)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listBroadcasts(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	response := youtube.LiveBroadcastListResponse{Kind: "youtube#liveBroadcastListResponse"}
+	if status := r.URL.Query().Get("broadcastStatus"); status == "" || status == "active" || status == "all" {
+		response.Items = []*youtube.LiveBroadcast{{
+			Id: broadcastID,
+			Snippet: &youtube.LiveBroadcastSnippet{
+				Title:      "simulated broadcast",
+				LiveChatId: s.liveChatID,
+			},
+			ContentDetails: &youtube.LiveBroadcastContentDetails{BoundStreamId: streamID},
+		}}
+	}
+	writeJSON(w, response)
+}
+
+func (s *Server) listStreams(w http.ResponseWriter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, youtube.LiveStreamListResponse{
+		Kind: "youtube#liveStreamListResponse",
+		Items: []*youtube.LiveStream{{
+			Id: streamID,
+			Status: &youtube.LiveStreamStatus{
+				StreamStatus: s.streamStatus,
+				HealthStatus: &youtube.LiveStreamHealthStatus{Status: s.healthStatus},
+			},
+		}},
+	})
+}
+
+type injectMessageRequest struct {
+	Author Author `json:"author"`
+	Text   string `json:"text"`
+}
+
+func (s *Server) handleInjectMessage(w http.ResponseWriter, r *http.Request) {
+	var request injectMessageRequest
+	if !decodeBody(w, r, &request) {
+		return
+	}
+	if request.Author.ChannelID == "" || request.Text == "" {
+		writeError(w, http.StatusBadRequest, "invalidValue", "author.channel_id and text are required")
+		return
+	}
+	writeJSON(w, s.InjectMessage(request.Author, request.Text))
+}
+
+type streamStatusRequest struct {
+	StreamStatus string `json:"stream_status"`
+	HealthStatus string `json:"health_status"`
+}
+
+func (s *Server) handleSetStreamStatus(w http.ResponseWriter, r *http.Request) {
+	var request streamStatusRequest
+	if !decodeBody(w, r, &request) {
+		return
+	}
+	s.SetStreamStatus(request.StreamStatus, request.HealthStatus)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// checkLiveChat 本物のAPIと同じく、終了したライブチャットはliveChatEnded、知らないものはliveChatNotFoundにする。s.muを取ってから呼ぶ
+func (s *Server) checkLiveChat(w http.ResponseWriter, liveChatID string) bool {
+	if s.endedLiveChatIDs[liveChatID] {
+		writeError(w, http.StatusForbidden, "liveChatEnded", "The live chat is no longer live.")
+		return false
+	}
+	if liveChatID != s.liveChatID {
+		writeError(w, http.StatusNotFound, "liveChatNotFound", "The live chat that you are trying to retrieve cannot be found.")
+		return false
+	}
+	return true
+}
+
+// newTextMessage s.muを取ってから呼ぶ
+func (s *Server) newTextMessage(author Author, text string) *youtube.LiveChatMessage {
+	displayName := author.DisplayName
+	if displayName == "" {
+		displayName = author.ChannelID
+	}
+	return &youtube.LiveChatMessage{
+		Kind: "youtube#liveChatMessage",
+		Id:   s.nextID("message"),
+		Snippet: &youtube.LiveChatMessageSnippet{
+			Type:               "textMessageEvent",
+			LiveChatId:         s.liveChatID,
+			AuthorChannelId:    author.ChannelID,
+			PublishedAt:        s.now().UTC().Format(time.RFC3339Nano),
+			HasDisplayContent:  true,
+			DisplayMessage:     text,
+			TextMessageDetails: &youtube.LiveChatTextMessageDetails{MessageText: text},
+		},
+		AuthorDetails: &youtube.LiveChatMessageAuthorDetails{
+			ChannelId:       author.ChannelID,
+			ChannelUrl:      "https://www.youtube.com/channel/" + author.ChannelID,
+			DisplayName:     displayName,
+			ProfileImageUrl: "https://example.com/" + author.ChannelID + ".png",
+			IsChatModerator: author.Role == RoleModerator,
+			IsChatOwner:     author.Role == RoleOwner,
+			IsChatSponsor:   author.Role == RoleMember,
+		},
+	}
+}
+
+func (s *Server) nextID(prefix string) string {
+	s.idSeq++
+	return fmt.Sprintf("sim-%s-%d", prefix, s.idSeq)
+}
+
+func decodeBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "parseError", err.Error())
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError googleapi.CheckResponseが解釈できる形式でエラーを返す
+func writeError(w http.ResponseWriter, code int, reason string, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"error": map[string]any{
+			"code":    code,
+			"message": message,
+			"errors":  []map[string]string{{"reason": reason, "message": message}},
+		},
+	})
+}
@@ -0,0 +1,160 @@
+package youtubesim
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"app.modules/core/guardians"
+	mock_moderatorbot "app.modules/core/moderatorbot/mocks"
+	"app.modules/core/repository"
+	"app.modules/core/youtubebot"
+)
+
+func newTestBot(t *testing.T, sim *Server) (youtubebot.LiveChatBot, *repository.InMemoryRepository, *httptest.Server) {
+	server := httptest.NewServer(sim)
+	t.Cleanup(server.Close)
+
+	repo := repository.NewInMemoryRepository()
+	require.NoError(t, repo.SetCredentialsConfig(repository.CredentialsConfigDoc{YoutubeLiveChatID: sim.LiveChatID()}))
+	bot, err := youtubebot.NewYoutubeLiveChatBot(sim.LiveChatID(), repo, server.URL, nil, context.Background())
+	require.NoError(t, err)
+	return bot, repo, server
+}
+
+func TestLiveChatBotAgainstSimulator(t *testing.T) {
+	ctx := context.Background()
+	sim := NewServer(Config{PollingIntervalMillis: 500})
+	bot, repo, _ := newTestBot(t, sim)
+
+	sim.InjectMessage(Author{ChannelID: "member", DisplayName: "@member", Role: RoleMember}, "!in")
+	sim.InjectMessage(Author{ChannelID: "moderator", Role: RoleModerator}, "!kick 1")
+	sim.InjectMessage(Author{ChannelID: "owner", Role: RoleOwner}, "hello")
+
+	messages, nextPageToken, pollingIntervalMillis, err := bot.ListMessages(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, messages, 3)
+	assert.Equal(t, 500, pollingIntervalMillis)
+	assert.Equal(t, "!in", youtubebot.ExtractTextMessageByAuthor(messages[0]))
+	assert.Equal(t, "member", youtubebot.ExtractAuthorDisplayName(messages[0]))
+	assert.True(t, youtubebot.IsChatMessageByMember(messages[0]))
+	assert.True(t, youtubebot.IsChatMessageByModerator(messages[1]))
+	assert.True(t, youtubebot.IsChatMessageByOwner(messages[2]))
+
+	// 続きからは新しいチャットだけが返る
+	require.NoError(t, bot.PostMessage(ctx, "入室しました"))
+	banID, err := bot.BanUser(ctx, "spammer")
+	require.NoError(t, err)
+	assert.NotEmpty(t, banID)
+	messages, _, _, err = bot.ListMessages(ctx, nextPageToken)
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, "sim-bot", youtubebot.ExtractAuthorChannelID(messages[0]))
+	assert.Equal(t, []string{"入室しました"}, sim.PostedMessages())
+	assert.Equal(t, []string{"spammer"}, sim.BannedChannelIDs())
+
+	// 配信し直した後は、終了したライブチャットへの投稿は諦め、次の取得でlive chat idを取り直す
+	newLiveChatID := sim.RestartBroadcast()
+	require.NoError(t, bot.PostMessage(ctx, "終了したライブチャットへの投稿"))
+	_, _, _, err = bot.ListMessages(ctx, "")
+	require.NoError(t, err)
+	require.NoError(t, bot.PostMessage(ctx, "再開しました"))
+	assert.Equal(t, []string{"入室しました", "再開しました"}, sim.PostedMessages())
+	credentials, err := repo.ReadCredentialsConfig(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, newLiveChatID, credentials.YoutubeLiveChatID)
+}
+
+func TestLiveChatBotTimesOutAndUnbansOnSimulator(t *testing.T) {
+	ctx := context.Background()
+	sim := NewServer(Config{})
+	bot, _, _ := newTestBot(t, sim)
+
+	timeoutBanID, err := bot.TimeOut(ctx, "noisy", 10*time.Minute)
+	require.NoError(t, err)
+	permanentBanID, err := bot.BanUser(ctx, "spammer")
+	require.NoError(t, err)
+
+	bans := sim.Bans()
+	require.Len(t, bans, 2)
+	assert.Equal(t, "temporary", bans[0].Snippet.Type)
+	assert.Equal(t, uint64(600), bans[0].Snippet.BanDurationSeconds)
+	assert.Equal(t, "permanent", bans[1].Snippet.Type)
+
+	require.NoError(t, bot.UnbanUser(ctx, timeoutBanID))
+	assert.Equal(t, []string{"spammer"}, sim.BannedChannelIDs())
+	assert.Error(t, bot.UnbanUser(ctx, timeoutBanID), "解除済みのブロックは見つからない")
+	require.NoError(t, bot.UnbanUser(ctx, permanentBanID))
+	assert.Empty(t, sim.BannedChannelIDs())
+}
+
+func TestLiveChatReceiverFallsBackToPollingOnSimulator(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sim := NewServer(Config{})
+	bot, repo, _ := newTestBot(t, sim)
+	sim.InjectMessage(Author{ChannelID: "viewer", Role: RoleViewer}, "!in")
+
+	// シミュレーターはstreamListに対応していないので、ポーリングで受信する
+	receiver := youtubebot.NewLiveChatReceiver(bot, repo, func(context.Context, string, error) {})
+	pages := make(chan youtubebot.ChatPage)
+	go func() { _ = receiver.Run(ctx, pages) }()
+
+	page := <-pages
+	require.Len(t, page.Messages, 1)
+	assert.Equal(t, "viewer", page.Messages[0].Author.ID)
+	assert.Equal(t, "!in", page.Messages[0].Text)
+}
+
+func TestLiveStreamCheckerAgainstSimulator(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	sim := NewServer(Config{})
+	_, repo, server := newTestBot(t, sim)
+
+	ownerBot := mock_moderatorbot.NewMockMessageBot(ctrl)
+	ownerBot.EXPECT().SendMessage(gomock.Any(), "stream HEALTH status is now : bad").Return(nil).Times(1)
+	quotaMeter := youtubebot.NewQuotaMeter(repo)
+	checker := guardians.NewLiveStreamChecker(repo, nil, ownerBot, server.URL, quotaMeter)
+
+	require.NoError(t, checker.Check(context.Background()))
+
+	sim.SetStreamStatus("active", "bad")
+	require.NoError(t, checker.Check(context.Background()))
+
+	// 呼び出したAPIの使用量が保存される
+	require.NoError(t, quotaMeter.Flush(context.Background()))
+	usage := quotaMeter.Usage()
+	assert.Equal(t, 4, usage.Units)
+	assert.Equal(t, map[youtubebot.QuotaMethod]int{youtubebot.QuotaListBroadcasts: 2, youtubebot.QuotaListStreams: 2}, usage.Calls)
+}
+
+func TestInjectMessageOverHTTP(t *testing.T) {
+	sim := NewServer(Config{})
+	server := httptest.NewServer(sim)
+	defer server.Close()
+
+	body := `{"author": {"channel_id": "moderator", "display_name": "mod", "role": "moderator"}, "text": "!block 3"}`
+	resp, err := http.Post(server.URL+"/sim/messages", "application/json", bytes.NewBufferString(body))
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = http.Post(server.URL+"/sim/messages", "application/json", bytes.NewBufferString(`{"text": "no author"}`))
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	messages := sim.messages[sim.liveChatID]
+	require.Len(t, messages, 1)
+	assert.True(t, messages[0].AuthorDetails.IsChatModerator)
+	assert.Equal(t, "!block 3", messages[0].Snippet.TextMessageDetails.MessageText)
+}